@@ -0,0 +1,144 @@
+package semscholar
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// decreaseCooldown is the minimum time between two reductions of an
+// AdaptiveLimiter, so a burst of 429s from requests sent together counts once.
+const decreaseCooldown = time.Second
+
+// AdaptiveLimiter bounds the number of requests in flight and tunes that bound
+// from rate-limit feedback, additive-increase/multiplicative-decrease style:
+// every successful request raises the limit by 1/limit, so it grows by about
+// one per round of requests, and a 429 halves it. Jobs sharing one limiter
+// settle near whatever rate the account actually allows. It is safe for
+// concurrent use.
+type AdaptiveLimiter struct {
+	// Clock, if set, replaces the system clock for the cooldown between
+	// decreases. Set it before the limiter is shared.
+	Clock Clock
+
+	mu           sync.Mutex
+	min, max     float64
+	limit        float64
+	inflight     int
+	waiters      []chan struct{}
+	lastDecrease time.Time
+}
+
+// NewAdaptiveLimiter creates an AdaptiveLimiter starting at initial concurrent
+// requests and staying within [min, max]. min is raised to 1.
+func NewAdaptiveLimiter(initial, min, max int) *AdaptiveLimiter {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	initial = clampInt(initial, min, max)
+	return &AdaptiveLimiter{min: float64(min), max: float64(max), limit: float64(initial)}
+}
+
+// Limit returns the current concurrency limit.
+func (l *AdaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// Acquire blocks until a request may start or ctx is done. Every successful
+// Acquire must be paired with a Release.
+func (l *AdaptiveLimiter) Acquire(ctx context.Context) error {
+	l.mu.Lock()
+	if l.inflight < int(l.limit) && len(l.waiters) == 0 {
+		l.inflight++
+		l.mu.Unlock()
+		return nil
+	}
+	ch := make(chan struct{})
+	l.waiters = append(l.waiters, ch)
+	l.mu.Unlock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		for i, w := range l.waiters {
+			if w == ch {
+				l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+				l.mu.Unlock()
+				return ctx.Err()
+			}
+		}
+		// The slot was granted while ctx ended; hand it on.
+		l.inflight--
+		l.wake()
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Release ends a request started with Acquire. throttled reports whether the
+// API answered 429; ok reports whether it succeeded. Failures of other kinds
+// leave the limit unchanged.
+func (l *AdaptiveLimiter) Release(throttled, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight--
+	switch {
+	case throttled:
+		if now := clockOr(l.Clock).Now(); now.Sub(l.lastDecrease) >= decreaseCooldown {
+			l.limit = max(l.min, l.limit/2)
+			l.lastDecrease = now
+		}
+	case ok:
+		l.limit = min(l.max, l.limit+1/l.limit)
+	}
+	l.wake()
+}
+
+// wake grants free slots to waiters in arrival order. l.mu is held.
+func (l *AdaptiveLimiter) wake() {
+	for len(l.waiters) > 0 && l.inflight < int(l.limit) {
+		ch := l.waiters[0]
+		l.waiters = l.waiters[1:]
+		l.inflight++
+		close(ch)
+	}
+}
+
+func clampInt(v, lo, hi int) int {
+	return max(lo, min(hi, v))
+}
+
+// adaptiveClient is an HTTPClient that gates requests through an AdaptiveLimiter.
+type adaptiveClient struct {
+	next    HTTPClient
+	limiter *AdaptiveLimiter
+}
+
+// NewAdaptiveClient wraps next so that requests pass through limiter and feed
+// their outcome back to it. Helpers that fan out work, such as Prime, are then
+// throttled to the concurrency the API tolerates.
+func NewAdaptiveClient(next HTTPClient, limiter *AdaptiveLimiter) HTTPClient {
+	return &adaptiveClient{next: next, limiter: limiter}
+}
+
+// Do implements HTTPClient.
+func (c *adaptiveClient) Do(req *http.Request) (*http.Response, error) {
+	if err := c.limiter.Acquire(req.Context()); err != nil {
+		return nil, err
+	}
+	resp, err := c.next.Do(req)
+	if err != nil {
+		c.limiter.Release(false, false)
+		return nil, err
+	}
+	c.limiter.Release(resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode < 400)
+	return resp, nil
+}
@@ -0,0 +1,100 @@
+package semscholar_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestAdaptiveLimiterIncrease checks that successes raise the limit by about
+// one a round of requests, up to the maximum.
+func TestAdaptiveLimiterIncrease(t *testing.T) {
+	l := semscholar.NewAdaptiveLimiter(2, 1, 4)
+	l.Clock = semscholartest.NewFakeClock(time.Time{})
+	release := func(n int) {
+		for range n {
+			if err := l.Acquire(t.Context()); err != nil {
+				t.Fatal(err)
+			}
+			l.Release(false, true)
+		}
+	}
+	// 2 + 1/2 + 1/2.5 reaches 2.9, then 3.24.
+	release(2)
+	if l.Limit() != 2 {
+		t.Errorf("limit %d after two successes, want 2", l.Limit())
+	}
+	release(1)
+	if l.Limit() != 3 {
+		t.Errorf("limit %d after three successes, want 3", l.Limit())
+	}
+	release(100)
+	if l.Limit() != 4 {
+		t.Errorf("limit %d after many successes, want the maximum 4", l.Limit())
+	}
+	// Failures other than 429s leave the limit alone.
+	l.Acquire(t.Context())
+	l.Release(false, false)
+	if l.Limit() != 4 {
+		t.Errorf("limit %d after a failure, want 4", l.Limit())
+	}
+}
+
+// TestAdaptiveLimiterDecrease checks that each 429 past the cooldown halves
+// the limit, and that it never drops below the minimum.
+func TestAdaptiveLimiterDecrease(t *testing.T) {
+	clock := semscholartest.NewFakeClock(time.Time{})
+	l := semscholar.NewAdaptiveLimiter(16, 3, 16)
+	l.Clock = clock
+	for _, want := range []int{8, 4, 3, 3} {
+		l.Acquire(t.Context())
+		l.Release(true, false)
+		if l.Limit() != want {
+			t.Errorf("limit %d after a 429, want %d", l.Limit(), want)
+		}
+		clock.Advance(time.Second)
+	}
+}
+
+// TestAdaptiveLimiterAcquire checks that Acquire waits for a free slot and
+// gives up when its context ends.
+func TestAdaptiveLimiterAcquire(t *testing.T) {
+	l := semscholar.NewAdaptiveLimiter(1, 1, 1)
+	if err := l.Acquire(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Acquire at the limit = %v, want DeadlineExceeded", err)
+	}
+	acquired := make(chan error)
+	go func() { acquired <- l.Acquire(t.Context()) }()
+	l.Release(false, true)
+	if err := <-acquired; err != nil {
+		t.Errorf("Acquire after a Release = %v", err)
+	}
+}
+
+// TestAdaptiveClient checks that a client wrapped by NewAdaptiveClient feeds
+// the API's 429s back to its limiter.
+func TestAdaptiveClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+	l := semscholar.NewAdaptiveLimiter(8, 1, 8)
+	l.Clock = semscholartest.NewFakeClock(time.Time{})
+	client := semscholar.NewClient(srv.URL, semscholar.NewAdaptiveClient(srv.Client(), l))
+	if _, err := client.GetPaper("x", ""); !semscholar.IsThrottle(err) {
+		t.Fatalf("GetPaper = %v, want a 429", err)
+	}
+	if l.Limit() != 4 {
+		t.Errorf("limit %d after a 429, want 4", l.Limit())
+	}
+}
@@ -0,0 +1,16 @@
+package semscholar_test
+
+import (
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// BenchmarkUnmarshal measures decoding batch responses and bulk search pages
+// with encoding/json, the baseline for comparing other Unmarshalers. Run it
+// with
+//
+//	go test -run '^$' -bench Unmarshal .
+func BenchmarkUnmarshal(b *testing.B) {
+	semscholartest.BenchmarkUnmarshal(b, nil)
+}
@@ -0,0 +1,169 @@
+package semscholar
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the API while a CircuitBreaker
+// is open.
+var ErrCircuitOpen = errors.New("semscholar: circuit open")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through and counts consecutive failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects requests with ErrCircuitOpen until the cooldown ends.
+	CircuitOpen
+	// CircuitHalfOpen lets a single trial request through after the cooldown;
+	// its outcome closes the circuit or opens it again.
+	CircuitHalfOpen
+)
+
+// String returns the name of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreaker stops requests to an API that keeps failing. After threshold
+// consecutive failures it opens, and for the cooldown that follows requests
+// fail at once with ErrCircuitOpen instead of piling up on a service that is
+// down. Then one trial request is let through: if it succeeds the circuit
+// closes, otherwise it stays open for another cooldown. It is safe for
+// concurrent use, and one breaker may be shared by several clients.
+type CircuitBreaker struct {
+	// Clock, if set, replaces the system clock for the cooldown. Set it
+	// before the breaker is shared.
+	Clock Clock
+
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+	trial     bool
+}
+
+// NewCircuitBreaker creates a closed CircuitBreaker that opens after threshold
+// consecutive failures, raised to 1, and stays open for cooldown.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{threshold: max(threshold, 1), cooldown: cooldown}
+}
+
+// State returns the current state. An open circuit whose cooldown has ended
+// reports CircuitHalfOpen.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && clockOr(b.Clock).Now().Sub(b.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// Allow reports whether a request may be sent, returning ErrCircuitOpen if
+// not. Every nil return must be followed by a call to Record.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		if clockOr(b.Clock).Now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+	case CircuitHalfOpen:
+		if b.trial {
+			return ErrCircuitOpen
+		}
+	default:
+		return nil
+	}
+	b.trial = true
+	return nil
+}
+
+// Record reports the outcome of a request that Allow let through.
+func (b *CircuitBreaker) Record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitHalfOpen:
+		b.trial = false
+		if failed {
+			b.open()
+		} else {
+			b.state = CircuitClosed
+			b.failures = 0
+		}
+	case CircuitClosed:
+		if !failed {
+			b.failures = 0
+		} else if b.failures++; b.failures >= b.threshold {
+			b.open()
+		}
+	}
+}
+
+// abandon gives up a request that Allow let through without an outcome, such
+// as one whose context was cancelled, freeing the trial slot if it held it.
+func (b *CircuitBreaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitHalfOpen {
+		b.trial = false
+	}
+}
+
+// open opens the circuit. b.mu is held.
+func (b *CircuitBreaker) open() {
+	b.state = CircuitOpen
+	b.openedAt = clockOr(b.Clock).Now()
+	b.failures = 0
+}
+
+// breakerClient is an HTTPClient that sends requests through a CircuitBreaker.
+type breakerClient struct {
+	next    HTTPClient
+	breaker *CircuitBreaker
+}
+
+// NewCircuitBreakerClient wraps next so that requests pass through breaker.
+// Network errors and 5xx responses count as failures; other responses,
+// including 429, count as successes, and requests ended by their context are
+// not counted. With a RetryPolicy, each attempt is counted, and an open
+// circuit ends the retries at once.
+func NewCircuitBreakerClient(next HTTPClient, breaker *CircuitBreaker) HTTPClient {
+	return &breakerClient{next: next, breaker: breaker}
+}
+
+// Do implements HTTPClient.
+func (c *breakerClient) Do(req *http.Request) (*http.Response, error) {
+	if err := c.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	resp, err := c.next.Do(req)
+	if err != nil {
+		if req.Context().Err() != nil {
+			c.breaker.abandon()
+		} else {
+			c.breaker.Record(true)
+		}
+		return nil, err
+	}
+	c.breaker.Record(resp.StatusCode >= 500)
+	return resp, nil
+}
@@ -0,0 +1,108 @@
+package semscholar_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestCircuitBreakerStates walks a breaker from closed to open, through a
+// half-open trial back to closed, and checks that a failed trial reopens it.
+func TestCircuitBreakerStates(t *testing.T) {
+	clock := semscholartest.NewFakeClock(time.Time{})
+	b := semscholar.NewCircuitBreaker(2, time.Minute)
+	b.Clock = clock
+	record := func(failed bool) {
+		t.Helper()
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow = %v in state %v", err, b.State())
+		}
+		b.Record(failed)
+	}
+	wantState := func(step string, want semscholar.CircuitState) {
+		t.Helper()
+		if got := b.State(); got != want {
+			t.Errorf("%s: state %v, want %v", step, got, want)
+		}
+	}
+
+	record(true)
+	record(false)
+	record(true)
+	wantState("failures broken by a success", semscholar.CircuitClosed)
+	record(true)
+	wantState("two failures in a row", semscholar.CircuitOpen)
+	if err := b.Allow(); !errors.Is(err, semscholar.ErrCircuitOpen) {
+		t.Errorf("Allow while open = %v, want ErrCircuitOpen", err)
+	}
+
+	clock.Advance(time.Minute)
+	wantState("after the cooldown", semscholar.CircuitHalfOpen)
+	record(true)
+	wantState("failed trial", semscholar.CircuitOpen)
+	clock.Advance(time.Minute - time.Second)
+	if err := b.Allow(); !errors.Is(err, semscholar.ErrCircuitOpen) {
+		t.Errorf("Allow before the new cooldown ends = %v, want ErrCircuitOpen", err)
+	}
+
+	clock.Advance(time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow for the trial = %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, semscholar.ErrCircuitOpen) {
+		t.Errorf("Allow during the trial = %v, want ErrCircuitOpen", err)
+	}
+	b.Record(false)
+	wantState("successful trial", semscholar.CircuitClosed)
+	record(true)
+	wantState("one failure after closing", semscholar.CircuitClosed)
+}
+
+// TestCircuitBreakerClient checks that a client behind an open breaker fails
+// fast without contacting the API, and recovers once a trial succeeds.
+func TestCircuitBreakerClient(t *testing.T) {
+	var requests atomic.Int32
+	var down atomic.Bool
+	down.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"paperId":"a"}`))
+	}))
+	defer srv.Close()
+	clock := semscholartest.NewFakeClock(time.Time{})
+	b := semscholar.NewCircuitBreaker(3, time.Minute)
+	b.Clock = clock
+	client := semscholar.NewClient(srv.URL, semscholar.NewCircuitBreakerClient(srv.Client(), b))
+
+	for range 3 {
+		if _, err := client.GetPaper("a", ""); !errors.Is(err, semscholar.ErrServerError) {
+			t.Fatalf("GetPaper = %v, want a 500", err)
+		}
+	}
+	if _, err := client.GetPaper("a", ""); !errors.Is(err, semscholar.ErrCircuitOpen) {
+		t.Errorf("GetPaper with the circuit open = %v, want ErrCircuitOpen", err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("%d requests, want 3 before the circuit opened", n)
+	}
+
+	down.Store(false)
+	clock.Advance(time.Minute)
+	if _, err := client.GetPaper("a", ""); err != nil {
+		t.Errorf("GetPaper after the cooldown = %v", err)
+	}
+	if b.State() != semscholar.CircuitClosed {
+		t.Errorf("state %v after a successful trial, want closed", b.State())
+	}
+}
@@ -0,0 +1,482 @@
+package semscholar
+
+import (
+	"bytes"
+	"compress/gzip"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores encoded responses for the client. Implementations must be safe
+// for concurrent use. The client treats the cache as best-effort: a failed Get is
+// a miss and a failed Set is ignored, so an unavailable cache server degrades to
+// uncached behavior instead of failing calls. Keys follow the scheme described
+// at CacheKey, so several processes can share one cache.
+type Cache interface {
+	// Get returns the value stored under key and whether it was found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl. A zero ttl never expires.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key; deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// CacheTTLs sets how long cached responses stay fresh for each kind of endpoint.
+// A negative duration disables caching for that kind.
+type CacheTTLs struct {
+	// Paper covers single papers, paper batches, citations and references.
+	Paper time.Duration
+	// Author covers single authors, author batches and author paper lists.
+	Author time.Duration
+	// Search covers relevance, bulk and match search, autocomplete and author search.
+	Search time.Duration
+	// Default covers everything else, such as recommendations and datasets.
+	Default time.Duration
+}
+
+// DefaultCacheTTLs is used for any CacheTTLs field left at zero. Entity lookups
+// change slowly; search results are kept for less time.
+var DefaultCacheTTLs = CacheTTLs{
+	Paper:   24 * time.Hour,
+	Author:  24 * time.Hour,
+	Search:  time.Hour,
+	Default: time.Hour,
+}
+
+// ttlFor returns the freshness lifetime for a request to path.
+func (t CacheTTLs) ttlFor(path string) time.Duration {
+	pick := func(v, def time.Duration) time.Duration {
+		if v == 0 {
+			return def
+		}
+		return v
+	}
+	for _, seg := range strings.Split(path, "/") {
+		switch seg {
+		case "paper", "author":
+			if strings.Contains(path, "/search") || strings.HasSuffix(path, "/autocomplete") {
+				return pick(t.Search, DefaultCacheTTLs.Search)
+			}
+			if seg == "author" {
+				return pick(t.Author, DefaultCacheTTLs.Author)
+			}
+			return pick(t.Paper, DefaultCacheTTLs.Paper)
+		}
+	}
+	return pick(t.Default, DefaultCacheTTLs.Default)
+}
+
+// CacheKey returns the key under which the response to req is cached:
+//
+//	semscholar:<METHOD>:<host><path>?<query sorted by name>[#<sha256 of body>]
+//
+// The query includes fields, offsets and filters, so differently shaped
+// requests for the same entity are cached separately. POST bodies (batch IDs,
+// recommendation seeds) are folded in as a hash.
+func CacheKey(req *http.Request) (string, error) {
+	var b strings.Builder
+	b.WriteString("semscholar:")
+	b.WriteString(req.Method)
+	b.WriteByte(':')
+	b.WriteString(req.URL.Host)
+	b.WriteString(req.URL.Path)
+	if q := req.URL.Query(); len(q) > 0 {
+		b.WriteByte('?')
+		b.WriteString(q.Encode())
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, body); err != nil {
+			return "", err
+		}
+		b.WriteByte('#')
+		b.WriteString(hex.EncodeToString(h.Sum(nil)))
+	}
+	return b.String(), nil
+}
+
+// revalidateWindow is how long past its freshness lifetime an entry carrying an
+// ETag or Last-Modified validator is kept, so it can be revalidated with a
+// conditional request instead of downloaded again.
+const revalidateWindow = 7 * 24 * time.Hour
+
+// do sends req, bounded by c.Timeout if set. The body is read in full before
+// do returns. Identical requests already in flight are coalesced: callers
+// with the same CacheKey wait for the first one and each receive a copy of its
+// response, so concurrent goroutines asking for the same paper cost one API
+// call. The shared request belongs to no one caller: it keeps the values of
+// the context of the caller that started it but not its cancellation, and is
+// bounded by the c.Timeout of that caller. Each caller stops waiting when its
+// own context ends or its own c.Timeout passes, without affecting the others.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	req = withRequestID(req)
+	key, err := CacheKey(req)
+	if err != nil {
+		return nil, err
+	}
+	fetch := func(ctx context.Context) (any, error) {
+		if c.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+			defer cancel()
+		}
+		resp, err := c.doCached(req.WithContext(ctx), key)
+		if err != nil {
+			return nil, err
+		}
+		defer closeBody(resp.Body)
+		r := io.Reader(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			// Only the start of an error body is reported; see Client.newAPIError.
+			r = io.LimitReader(r, maxErrorBody)
+		}
+		body, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return &sharedResponse{resp: resp, body: body}, nil
+	}
+	ctx := req.Context()
+	if c.inflight == nil {
+		shared, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return shared.(*sharedResponse).copy(req), nil
+	}
+	detached := context.WithoutCancel(ctx)
+	ch := c.inflight.DoChan(key, func() (any, error) {
+		return fetch(detached)
+	})
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*sharedResponse).copy(req), nil
+	}
+}
+
+// sharedResponse is a response whose body has been read so that every
+// coalesced caller can be handed its own copy.
+type sharedResponse struct {
+	resp *http.Response
+	body []byte
+}
+
+func (s *sharedResponse) copy(req *http.Request) *http.Response {
+	resp := *s.resp
+	resp.Header = s.resp.Header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(s.body))
+	resp.ContentLength = int64(len(s.body))
+	resp.Request = req
+	return &resp
+}
+
+// doCached sends req, consulting c.Cache first when one is configured. Every
+// endpoint the client exposes is a read, so both GET and POST responses are
+// cacheable. Stale entries with validators are revalidated with If-None-Match
+// or If-Modified-Since, and a 304 is served from the cache.
+func (c *Client) doCached(req *http.Request, key string) (*http.Response, error) {
+	if c.Cache == nil {
+		return c.send(req)
+	}
+	ttl := c.CacheTTLs.ttlFor(req.URL.Path)
+	if ttl < 0 {
+		return c.send(req)
+	}
+	ctx := req.Context()
+	var stale *cacheEntry
+	if raw, ok, err := c.Cache.Get(ctx, key); err == nil && ok {
+		if entry, ok := decodeCacheEntry(raw); ok {
+			if clockOr(c.Clock).Now().Before(entry.fresh) {
+				return cachedResponse(req, entry), nil
+			}
+			if entry.etag != "" || entry.lastModified != "" {
+				stale = entry
+				req = req.Clone(ctx)
+				if entry.etag != "" {
+					req.Header.Set("If-None-Match", entry.etag)
+				}
+				if entry.lastModified != "" {
+					req.Header.Set("If-Modified-Since", entry.lastModified)
+				}
+			}
+		}
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && stale != nil {
+		closeBody(resp.Body)
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			stale.etag = etag
+		}
+		c.storeEntry(ctx, key, stale, ttl)
+		return cachedResponse(req, stale), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	c.storeEntry(ctx, key, &cacheEntry{
+		body:         body,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}, ttl)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// storeEntry marks entry fresh for ttl and writes it to the cache. Entries with
+// validators outlive their freshness by revalidateWindow.
+func (c *Client) storeEntry(ctx context.Context, key string, entry *cacheEntry, ttl time.Duration) {
+	entry.fresh = clockOr(c.Clock).Now().Add(ttl)
+	keep := ttl
+	if entry.etag != "" || entry.lastModified != "" {
+		keep += revalidateWindow
+	}
+	_ = c.Cache.Set(ctx, key, entry.encode(), keep)
+}
+
+// cacheEntry is the value the client stores in a Cache: a response body, the
+// time it stops being fresh and the validators needed to revalidate it.
+type cacheEntry struct {
+	body         []byte
+	fresh        time.Time
+	etag         string
+	lastModified string
+}
+
+// cacheEntryMagic starts every encoded cacheEntry.
+const cacheEntryMagic = "s2cache/1"
+
+// compressThreshold is the body size above which cached entries are stored
+// gzip-compressed. JSON responses typically shrink to a fifth of their size.
+const compressThreshold = 1 << 10
+
+// encode lays the entry out as a header line with the magic, the freshness
+// deadline in Unix nanoseconds and, for large bodies, a "gzip" marker; one
+// line each for the ETag and Last-Modified values; then the body.
+func (e *cacheEntry) encode() []byte {
+	var b bytes.Buffer
+	b.Grow(len(e.body) + 64)
+	fmt.Fprintf(&b, "%s %d", cacheEntryMagic, e.fresh.UnixNano())
+	if len(e.body) > compressThreshold {
+		b.WriteString(" gzip")
+	}
+	fmt.Fprintf(&b, "\n%s\n%s\n", e.etag, e.lastModified)
+	if len(e.body) > compressThreshold {
+		zw, _ := gzip.NewWriterLevel(&b, gzip.BestSpeed)
+		zw.Write(e.body)
+		zw.Close()
+		return b.Bytes()
+	}
+	b.Write(e.body)
+	return b.Bytes()
+}
+
+func decodeCacheEntry(raw []byte) (*cacheEntry, bool) {
+	var lines [3]string
+	for i := range lines {
+		n := bytes.IndexByte(raw, '\n')
+		if n < 0 {
+			return nil, false
+		}
+		lines[i], raw = string(raw[:n]), raw[n+1:]
+	}
+	header := strings.Fields(lines[0])
+	if len(header) < 2 || header[0] != cacheEntryMagic {
+		return nil, false
+	}
+	nanos, err := strconv.ParseInt(header[1], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	if len(header) > 2 && header[2] == "gzip" {
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, false
+		}
+		if raw, err = io.ReadAll(zr); err != nil {
+			return nil, false
+		}
+	}
+	return &cacheEntry{body: raw, fresh: time.Unix(0, nanos), etag: lines[1], lastModified: lines[2]}, true
+}
+
+// cachedResponse synthesizes a 200 response carrying the entry's body.
+func cachedResponse(req *http.Request, entry *cacheEntry) *http.Response {
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	if entry.etag != "" {
+		header.Set("ETag", entry.etag)
+	}
+	if entry.lastModified != "" {
+		header.Set("Last-Modified", entry.lastModified)
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.body)),
+		ContentLength: int64(len(entry.body)),
+		Request:       req,
+	}
+}
+
+// LRUCache is an in-memory Cache with a fixed number of entries and per-entry
+// expiry. When full, the least recently used entry is evicted. It is safe for
+// concurrent use.
+type LRUCache struct {
+	// OnEvict, if set, is called with the key of every entry removed and why.
+	// It runs after the cache's lock is released, so it may use the cache.
+	// Set it before the cache is shared.
+	OnEvict func(key string, reason EvictReason)
+	// Clock, if set, replaces the system clock for expiry. Set it before the
+	// cache is shared.
+	Clock Clock
+
+	mu    sync.Mutex
+	max   int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRUCache creates an LRUCache holding at most maxEntries responses.
+func NewLRUCache(maxEntries int) *LRUCache {
+	return &LRUCache{
+		max:   maxEntries,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Get implements Cache. Expired entries are reported as missing.
+func (c *LRUCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	el, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		return nil, false, nil
+	}
+	entry := el.Value.(*lruEntry)
+	if !entry.expires.IsZero() && clockOr(c.Clock).Now().After(entry.expires) {
+		c.removeElement(el)
+		c.mu.Unlock()
+		c.evicted(EvictExpired, key)
+		return nil, false, nil
+	}
+	c.ll.MoveToFront(el)
+	c.mu.Unlock()
+	return entry.value, true, nil
+}
+
+// Set implements Cache.
+func (c *LRUCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	var expires time.Time
+	if ttl > 0 {
+		expires = clockOr(c.Clock).Now().Add(ttl)
+	}
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.value, entry.expires = value, expires
+		c.ll.MoveToFront(el)
+		c.mu.Unlock()
+		return nil
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	var dropped []string
+	for c.max > 0 && c.ll.Len() > c.max {
+		dropped = append(dropped, c.removeElement(c.ll.Back()))
+	}
+	c.mu.Unlock()
+	c.evicted(EvictCapacity, dropped...)
+	return nil
+}
+
+// Delete implements Cache.
+func (c *LRUCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	el, ok := c.items[key]
+	if ok {
+		c.removeElement(el)
+	}
+	c.mu.Unlock()
+	if ok {
+		c.evicted(EvictDeleted, key)
+	}
+	return nil
+}
+
+// DeletePrefix implements PrefixDeleter.
+func (c *LRUCache) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	c.mu.Lock()
+	var dropped []string
+	for key, el := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			dropped = append(dropped, c.removeElement(el))
+		}
+	}
+	c.mu.Unlock()
+	c.evicted(EvictDeleted, dropped...)
+	return len(dropped), nil
+}
+
+// Len returns the number of entries, including expired ones not yet evicted.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *LRUCache) removeElement(el *list.Element) string {
+	c.ll.Remove(el)
+	key := el.Value.(*lruEntry).key
+	delete(c.items, key)
+	return key
+}
+
+func (c *LRUCache) evicted(reason EvictReason, keys ...string) {
+	if c.OnEvict == nil {
+		return
+	}
+	for _, key := range keys {
+		c.OnEvict(key, reason)
+	}
+}
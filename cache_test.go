@@ -0,0 +1,269 @@
+package semscholar_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestCoalescedCancel checks that a caller giving up on a request other
+// callers are waiting for leaves the shared request running for them.
+func TestCoalescedCancel(t *testing.T) {
+	var requests atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"paperId":"a"}`))
+	}))
+	defer srv.Close()
+	c := semscholar.NewClient(srv.URL, srv.Client())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := c.GetPaperContext(ctx, "a", "")
+		first <- err
+	}()
+	<-started
+	second := make(chan error, 1)
+	go func() {
+		_, err := c.GetPaperContext(context.Background(), "a", "")
+		second <- err
+	}()
+	time.Sleep(50 * time.Millisecond) // let the second caller join
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("first caller got %v, want context.Canceled", err)
+	}
+	close(release)
+	if err := <-second; err != nil {
+		t.Errorf("second caller got %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("server saw %d requests, want 1", n)
+	}
+}
+
+// TestLRUCacheEviction checks that a full LRUCache evicts its least recently
+// used entry, counting Gets and Sets as uses, and reports why.
+func TestLRUCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	c := semscholar.NewLRUCache(2)
+	var evicted []string
+	c.OnEvict = func(key string, reason semscholar.EvictReason) {
+		evicted = append(evicted, key+":"+reason.String())
+	}
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "b", []byte("2"), 0)
+	c.Get(ctx, "a")
+	c.Set(ctx, "c", []byte("3"), 0) // evicts b
+	c.Set(ctx, "a", []byte("4"), 0)
+	c.Set(ctx, "d", []byte("5"), 0) // evicts c
+	c.Delete(ctx, "a")
+
+	if want := []string{"b:capacity", "c:capacity", "a:deleted"}; !slices.Equal(evicted, want) {
+		t.Errorf("evicted %v, want %v", evicted, want)
+	}
+	if v, ok, _ := c.Get(ctx, "d"); !ok || string(v) != "5" {
+		t.Errorf("Get(d) = %q, %v; want 5", v, ok)
+	}
+	if n := c.Len(); n != 1 {
+		t.Errorf("Len = %d, want 1", n)
+	}
+}
+
+// TestLRUCacheExpiry checks that LRUCache entries expire after their TTL by
+// the cache's clock, and that entries without one do not.
+func TestLRUCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	clock := semscholartest.NewFakeClock(time.Time{})
+	c := semscholar.NewLRUCache(10)
+	c.Clock = clock
+	var expired []string
+	c.OnEvict = func(key string, reason semscholar.EvictReason) {
+		if reason == semscholar.EvictExpired {
+			expired = append(expired, key)
+		}
+	}
+	c.Set(ctx, "short", []byte("1"), time.Minute)
+	c.Set(ctx, "long", []byte("2"), time.Hour)
+	c.Set(ctx, "forever", []byte("3"), 0)
+
+	clock.Advance(time.Minute)
+	if _, ok, _ := c.Get(ctx, "short"); !ok {
+		t.Error("entry expired at its TTL, want it kept until after")
+	}
+	clock.Advance(time.Second)
+	if _, ok, _ := c.Get(ctx, "short"); ok {
+		t.Error("entry found after its TTL")
+	}
+	if _, ok, _ := c.Get(ctx, "long"); !ok {
+		t.Error("entry with a longer TTL expired early")
+	}
+	clock.Advance(24 * time.Hour)
+	for key, want := range map[string]bool{"long": false, "forever": true} {
+		if _, ok, _ := c.Get(ctx, key); ok != want {
+			t.Errorf("Get(%s) after a day found %v, want %v", key, ok, want)
+		}
+	}
+	if want := []string{"short", "long"}; !slices.Equal(expired, want) {
+		t.Errorf("expired %v, want %v", expired, want)
+	}
+}
+
+// TestClientCacheTTLs checks that the client serves repeated calls from its
+// cache until the TTL for the kind of endpoint passes.
+func TestClientCacheTTLs(t *testing.T) {
+	srv := semscholartest.NewServer(nil)
+	defer srv.Close()
+	clock := semscholartest.NewFakeClock(time.Time{})
+	c := srv.Client()
+	c.Clock = clock
+	c.Cache = semscholar.NewLRUCache(100)
+	c.CacheTTLs = semscholar.CacheTTLs{Paper: time.Hour, Search: time.Minute}
+
+	call := func() {
+		if _, err := c.GetPaper(semscholartest.BERTPaperID, "title"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.SearchPapers("bert", 0, 10, "title", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	call()
+	call()
+	if n := len(srv.Requests()); n != 2 {
+		t.Errorf("%d requests for repeated calls, want 2", n)
+	}
+	clock.Advance(2 * time.Minute)
+	call()
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("%d requests once the search expired, want 3", n)
+	}
+	clock.Advance(time.Hour)
+	call()
+	if n := len(srv.Requests()); n != 5 {
+		t.Errorf("%d requests once both expired, want 5", n)
+	}
+}
+
+// TestCacheRevalidation checks that a stale entry with an ETag is
+// revalidated with If-None-Match, served from the cache on a 304, and
+// replaced on a 200.
+func TestCacheRevalidation(t *testing.T) {
+	var (
+		etag       = `"v1"`
+		title      = "First"
+		conditions []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditions = append(conditions, r.Header.Get("If-None-Match"))
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"paperId":"a","title":%q}`, title)
+	}))
+	defer srv.Close()
+	clock := semscholartest.NewFakeClock(time.Time{})
+	c := semscholar.NewClient(srv.URL, srv.Client())
+	c.Clock = clock
+	c.Cache = semscholar.NewLRUCache(10)
+	c.CacheTTLs = semscholar.CacheTTLs{Paper: time.Minute}
+
+	get := func(want string) {
+		t.Helper()
+		p, err := c.GetPaper("a", "title")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Title != want {
+			t.Errorf("title = %q, want %q", p.Title, want)
+		}
+	}
+	get("First")
+	get("First") // fresh: not sent
+	clock.Advance(2 * time.Minute)
+	get("First") // stale: revalidated, 304
+	get("First") // fresh again after the 304
+	clock.Advance(2 * time.Minute)
+	etag, title = `"v2"`, "Second"
+	get("Second") // stale: revalidated, 200 replaces the entry
+	get("Second")
+
+	if want := []string{"", `"v1"`, `"v1"`}; !slices.Equal(conditions, want) {
+		t.Errorf("requests sent If-None-Match %q, want %q", conditions, want)
+	}
+}
+
+// recordingCache is an LRUCache that keeps the last value set.
+type recordingCache struct {
+	*semscholar.LRUCache
+	last []byte
+}
+
+func (c *recordingCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.last = value
+	return c.LRUCache.Set(ctx, key, value, ttl)
+}
+
+// TestCacheCompression checks that large responses are stored compressed and
+// served from the cache unchanged, and that small ones are stored as they are.
+func TestCacheCompression(t *testing.T) {
+	abstract := strings.Repeat("Transformers attend to every token. ", 200)
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/paper/big" {
+			fmt.Fprintf(w, `{"paperId":"big","abstract":%q}`, abstract)
+			return
+		}
+		w.Write([]byte(`{"paperId":"small"}`))
+	}))
+	defer srv.Close()
+	cache := &recordingCache{LRUCache: semscholar.NewLRUCache(10)}
+	c := semscholar.NewClient(srv.URL, srv.Client())
+	c.Cache = cache
+
+	for range 2 {
+		p, err := c.GetPaper("big", "abstract")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Abstract != abstract {
+			t.Errorf("abstract of %d bytes, want %d", len(p.Abstract), len(abstract))
+		}
+	}
+	if requests != 1 {
+		t.Errorf("%d requests, want 1", requests)
+	}
+	header, _, _ := bytes.Cut(cache.last, []byte("\n"))
+	if !bytes.HasSuffix(header, []byte(" gzip")) || len(cache.last) > len(abstract)/4 {
+		t.Errorf("stored %d bytes with header %q, want a compressed entry", len(cache.last), header)
+	}
+
+	if _, err := c.GetPaper("small", ""); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasSuffix(cache.last, []byte(`{"paperId":"small"}`)) {
+		t.Errorf("stored %q, want the body uncompressed", cache.last)
+	}
+}
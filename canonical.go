@@ -0,0 +1,137 @@
+package semscholar
+
+import (
+	"fmt"
+	"strings"
+)
+
+// preprintVenues lists venue names that indicate a record has not been formally published.
+var preprintVenues = map[string]bool{
+	"arxiv":    true,
+	"biorxiv":  true,
+	"medrxiv":  true,
+	"ssrn":     true,
+	"chemrxiv": true,
+	"psyarxiv": true,
+}
+
+// CanonicalCandidate is the assessment of one duplicate record during canonical selection.
+type CanonicalCandidate struct {
+	PaperID       string
+	HasDOI        bool
+	Published     bool
+	Completeness  int
+	CitationCount int
+}
+
+// CanonicalChoice records which of several duplicate records was selected and why.
+type CanonicalChoice struct {
+	// Index is the position of the selected record in the candidates.
+	Index int
+	// Candidates holds the assessment of every record, in input order.
+	Candidates []CanonicalCandidate
+	// Reason names the criterion that separated the winner from the runner-up.
+	Reason string
+}
+
+// DuplicateGroup is a set of records that Dedupe considers the same work.
+type DuplicateGroup struct {
+	// Canonical is the selected record with gaps filled and citation counts merged.
+	Canonical Paper
+	// Members are the original records, in the order they were first seen.
+	Members []Paper
+	Choice  CanonicalChoice
+}
+
+// SelectCanonical picks the record that should represent a set of duplicates.
+// Records are ranked by, in order: having a DOI, appearing in a published
+// (non-preprint) venue, completeness of descriptive fields, citation count, and
+// finally input order.
+func SelectCanonical(candidates []Paper) CanonicalChoice {
+	choice := CanonicalChoice{Candidates: make([]CanonicalCandidate, len(candidates))}
+	for i, p := range candidates {
+		choice.Candidates[i] = assessCandidate(p)
+	}
+	if len(candidates) == 0 {
+		choice.Index = -1
+		return choice
+	}
+	if len(candidates) == 1 {
+		choice.Reason = "only record"
+		return choice
+	}
+	runnerUp := -1
+	for i := 1; i < len(candidates); i++ {
+		if compareCandidates(choice.Candidates[i], choice.Candidates[choice.Index]) > 0 {
+			runnerUp = choice.Index
+			choice.Index = i
+		} else if runnerUp < 0 || compareCandidates(choice.Candidates[i], choice.Candidates[runnerUp]) > 0 {
+			runnerUp = i
+		}
+	}
+	choice.Reason = canonicalReason(choice.Candidates[choice.Index], choice.Candidates[runnerUp])
+	return choice
+}
+
+func assessCandidate(p Paper) CanonicalCandidate {
+	filled := 0
+	for _, ok := range []bool{
+		p.Title != "",
+		p.Abstract != "",
+		len(p.Authors) > 0,
+		p.PublishedYear() != 0,
+		p.Venue != "",
+		p.URL != "",
+		p.ExternalIDs.DOI != "",
+		p.ExternalIDs.ArXiv != "",
+		len(p.FieldsOfStudy) > 0,
+	} {
+		if ok {
+			filled++
+		}
+	}
+	return CanonicalCandidate{
+		PaperID:       p.PaperID,
+		HasDOI:        p.ExternalIDs.DOI != "",
+		Published:     p.Venue != "" && !preprintVenues[strings.ToLower(strings.TrimSpace(p.Venue))],
+		Completeness:  filled,
+		CitationCount: p.CitationCount.Int,
+	}
+}
+
+// compareCandidates returns a positive number if a ranks above b, negative if
+// below, and zero if they are indistinguishable.
+func compareCandidates(a, b CanonicalCandidate) int {
+	switch {
+	case a.HasDOI != b.HasDOI:
+		return boolRank(a.HasDOI)
+	case a.Published != b.Published:
+		return boolRank(a.Published)
+	case a.Completeness != b.Completeness:
+		return a.Completeness - b.Completeness
+	default:
+		return a.CitationCount - b.CitationCount
+	}
+}
+
+func canonicalReason(winner, runnerUp CanonicalCandidate) string {
+	switch {
+	case winner.HasDOI != runnerUp.HasDOI:
+		return "has a DOI"
+	case winner.Published != runnerUp.Published:
+		return "published venue rather than preprint"
+	case winner.Completeness != runnerUp.Completeness:
+		return fmt.Sprintf("more complete record (%d vs %d fields)", winner.Completeness, runnerUp.Completeness)
+	case winner.CitationCount != runnerUp.CitationCount:
+		return fmt.Sprintf("higher citation count (%d vs %d)", winner.CitationCount, runnerUp.CitationCount)
+	default:
+		return "first seen"
+	}
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,79 @@
+package semscholar_test
+
+import (
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// TestSelectCanonical checks the order SelectCanonical ranks duplicate records
+// in, and the reason it gives for its choice.
+func TestSelectCanonical(t *testing.T) {
+	doi := semscholar.ExternalIDs{DOI: "10.1000/a"}
+	tests := []struct {
+		name       string
+		candidates []semscholar.Paper
+		index      int
+		reason     string
+	}{
+		{"none", nil, -1, ""},
+		{"one", []semscholar.Paper{{PaperID: "a"}}, 0, "only record"},
+		{
+			"DOI present",
+			[]semscholar.Paper{
+				{PaperID: "a", Venue: "Nature", Abstract: "x", CitationCount: semscholar.NewNullInt(100)},
+				{PaperID: "b", ExternalIDs: doi},
+			},
+			1, "has a DOI",
+		},
+		{
+			"published over preprint",
+			[]semscholar.Paper{
+				{PaperID: "a", Venue: "arXiv", CitationCount: semscholar.NewNullInt(100)},
+				{PaperID: "b", Venue: "NeurIPS"},
+			},
+			1, "published venue rather than preprint",
+		},
+		{
+			"preprint venue ignoring case and space",
+			[]semscholar.Paper{
+				{PaperID: "a", Venue: " bioRxiv "},
+				{PaperID: "b", Venue: "Cell"},
+			},
+			1, "published venue rather than preprint",
+		},
+		{
+			"more complete",
+			[]semscholar.Paper{
+				{PaperID: "a", Title: "T", CitationCount: semscholar.NewNullInt(100)},
+				{PaperID: "b", Title: "T", Abstract: "x"},
+			},
+			1, "more complete record (2 vs 1 fields)",
+		},
+		{
+			"more citations",
+			[]semscholar.Paper{
+				{PaperID: "a", Title: "T", CitationCount: semscholar.NewNullInt(3)},
+				{PaperID: "b", Title: "T", CitationCount: semscholar.NewNullInt(5)},
+				{PaperID: "c", Title: "T", CitationCount: semscholar.NewNullInt(4)},
+			},
+			1, "higher citation count (5 vs 4)",
+		},
+		{
+			"first seen",
+			[]semscholar.Paper{{PaperID: "a", Title: "T"}, {PaperID: "b", Title: "T"}},
+			0, "first seen",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := semscholar.SelectCanonical(tt.candidates)
+			if c.Index != tt.index || c.Reason != tt.reason {
+				t.Errorf("SelectCanonical = index %d, reason %q; want %d, %q", c.Index, c.Reason, tt.index, tt.reason)
+			}
+			if len(c.Candidates) != len(tt.candidates) {
+				t.Errorf("%d candidates assessed, want %d", len(c.Candidates), len(tt.candidates))
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package semscholar
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// FilterCitations yields the citations of seq that keep accepts, and every
+// error of seq. It applies to the citations or references of any
+// PaperService:
+//
+//	seq := semscholar.FilterCitations(svc.GetPaperCitationsSeq(ctx, id, "intents,title"),
+//		semscholar.HasIntent(semscholar.IntentMethodology))
+func FilterCitations(seq iter.Seq2[Citation, error], keep func(Citation) bool) iter.Seq2[Citation, error] {
+	return func(yield func(Citation, error) bool) {
+		for c, err := range seq {
+			if err != nil || keep(c) {
+				if !yield(c, err) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// IsInfluentialCitation accepts the citations Semantic Scholar judges
+// influential, for FilterCitations. The citations must be fetched with the
+// isInfluential field.
+func IsInfluentialCitation(c Citation) bool {
+	return c.IsInfluential
+}
+
+// HasIntent returns a filter accepting the citations with any of intents,
+// such as IntentMethodology, for FilterCitations. The citations must be
+// fetched with the intents field.
+func HasIntent(intents ...string) func(Citation) bool {
+	return func(c Citation) bool {
+		return slices.ContainsFunc(c.Intents, func(i string) bool { return slices.Contains(intents, i) })
+	}
+}
+
+// GetInfluentialCitationsSeq is like GetPaperCitationsSeq but yields only
+// the influential citations, usually the few worth reading. The
+// isInfluential field is asked for if fields lack it. Every citation is
+// still listed, so it costs as many requests as listing them all.
+func (c *Client) GetInfluentialCitationsSeq(ctx context.Context, paperID, fields string) iter.Seq2[Citation, error] {
+	return FilterCitations(c.GetPaperCitationsSeq(ctx, paperID, withField(fields, CitationFieldIsInfluential)), IsInfluentialCitation)
+}
+
+// GetCitationsWithIntentSeq is like GetPaperCitationsSeq but yields only the
+// citations with any of intents: IntentMethodology, say, for the papers
+// using a paper's methods. The intents field is asked for if fields lack
+// it.
+func (c *Client) GetCitationsWithIntentSeq(ctx context.Context, paperID, fields string, intents ...string) iter.Seq2[Citation, error] {
+	return FilterCitations(c.GetPaperCitationsSeq(ctx, paperID, withField(fields, CitationFieldIntents)), HasIntent(intents...))
+}
+
+// withField returns the fields parameter fields with field added, if it is
+// not already among them.
+func withField(fields, field string) string {
+	if fields == "" {
+		return field
+	}
+	if slices.Contains(strings.Split(fields, ","), field) {
+		return fields
+	}
+	return fields + "," + field
+}
@@ -0,0 +1,118 @@
+package semscholar_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// edges returns a sequence of citations from papers "a" to "d", "b" and "d"
+// influential, and an error after them.
+func edges(err error) func(func(semscholar.Citation, error) bool) {
+	cites := []semscholar.Citation{
+		{CitingPaper: semscholar.Paper{PaperID: "a"}, Intents: []string{semscholar.IntentBackground}},
+		{CitingPaper: semscholar.Paper{PaperID: "b"}, IsInfluential: true, Intents: []string{semscholar.IntentMethodology}},
+		{CitingPaper: semscholar.Paper{PaperID: "c"}, Intents: []string{semscholar.IntentResult, semscholar.IntentMethodology}},
+		{CitingPaper: semscholar.Paper{PaperID: "d"}, IsInfluential: true},
+	}
+	return func(yield func(semscholar.Citation, error) bool) {
+		for _, c := range cites {
+			if !yield(c, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(semscholar.Citation{}, err)
+		}
+	}
+}
+
+// TestFilterCitations checks the citations each filter keeps, and that
+// errors pass through.
+func TestFilterCitations(t *testing.T) {
+	errBoom := errors.New("boom")
+	for _, tt := range []struct {
+		name string
+		keep func(semscholar.Citation) bool
+		want []string
+	}{
+		{"influential", semscholar.IsInfluentialCitation, []string{"b", "d"}},
+		{"methodology", semscholar.HasIntent(semscholar.IntentMethodology), []string{"b", "c"}},
+		{"background or result", semscholar.HasIntent(semscholar.IntentBackground, semscholar.IntentResult), []string{"a", "c"}},
+		{"no intents", semscholar.HasIntent(), nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			var last error
+			for c, err := range semscholar.FilterCitations(edges(errBoom), tt.keep) {
+				if err != nil {
+					last = err
+					continue
+				}
+				got = append(got, c.CitingPaper.PaperID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("kept %q, want %q", got, tt.want)
+			}
+			if last != errBoom {
+				t.Errorf("error %v, want it passed through", last)
+			}
+		})
+	}
+
+	n := 0
+	for range semscholar.FilterCitations(edges(nil), semscholar.IsInfluentialCitation) {
+		if n++; n == 1 {
+			break
+		}
+	}
+	if n != 1 {
+		t.Errorf("FilterCitations yielded %d citations after a break", n)
+	}
+}
+
+// TestFilteredCitationSeqs checks that GetInfluentialCitationsSeq and
+// GetCitationsWithIntentSeq add the field they filter by to those asked
+// for, once, and keep only the matching citations.
+func TestFilteredCitationSeqs(t *testing.T) {
+	var fields []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fields = append(fields, r.URL.Query().Get("fields"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"offset":0,"data":[
+			{"isInfluential":true,"intents":["background"],"citingPaper":{"paperId":"a"}},
+			{"isInfluential":false,"intents":["methodology"],"citingPaper":{"paperId":"b"}}
+		]}`))
+	}))
+	defer srv.Close()
+	client := semscholar.NewClient(srv.URL, srv.Client())
+	ctx := context.Background()
+	ids := func(seq func(func(semscholar.Citation, error) bool)) []string {
+		var got []string
+		for c, err := range seq {
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, c.CitingPaper.PaperID)
+		}
+		return got
+	}
+
+	if got := ids(client.GetInfluentialCitationsSeq(ctx, "p", "title")); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("influential citations %q, want a", got)
+	}
+	if got := ids(client.GetCitationsWithIntentSeq(ctx, "p", "", semscholar.IntentMethodology)); !slices.Equal(got, []string{"b"}) {
+		t.Errorf("methodology citations %q, want b", got)
+	}
+	if got := ids(client.GetCitationsWithIntentSeq(ctx, "p", "title,intents", semscholar.IntentBackground)); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("background citations %q, want a", got)
+	}
+	if want := []string{"title,isInfluential", "intents", "title,intents"}; !slices.Equal(fields, want) {
+		t.Errorf("requested fields %q, want %q", fields, want)
+	}
+}
@@ -0,0 +1,43 @@
+package semscholar
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time for retry backoff, rate limiting, cache expiry
+// and the cooldowns of circuit breakers, failovers and adaptive limiters.
+// Tests can substitute a fake, such as semscholartest.FakeClock, so that
+// throttling, expiry and recovery run instantly and deterministically.
+type Clock interface {
+	Now() time.Time
+	// Sleep waits for d or until ctx is done, returning ctx.Err() in the
+	// latter case.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// SystemClock is the real Clock, used wherever none is set.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// clockOr returns c, or SystemClock if c is nil.
+func clockOr(c Clock) Clock {
+	if c == nil {
+		return SystemClock
+	}
+	return c
+}
@@ -0,0 +1,86 @@
+package semscholar_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestClockCooldowns checks that circuit breakers, failovers and adaptive
+// limiters time their cooldowns with their Clock rather than the system's.
+func TestClockCooldowns(t *testing.T) {
+	clock := semscholartest.NewFakeClock(time.Time{})
+
+	b := semscholar.NewCircuitBreaker(1, time.Minute)
+	b.Clock = clock
+	if err := b.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	b.Record(true)
+	if b.State() != semscholar.CircuitOpen {
+		t.Fatalf("breaker %v after a failure, want open", b.State())
+	}
+	clock.Advance(time.Minute)
+	if b.State() != semscholar.CircuitHalfOpen || b.Allow() != nil {
+		t.Errorf("breaker %v after its cooldown, want a half-open trial", b.State())
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	f := semscholar.NewFailover(time.Minute, srv.URL+"/a", srv.URL+"/b")
+	f.Clock = clock
+	client := semscholar.NewClient(srv.URL+"/a", semscholar.NewFailoverClient(srv.Client(), f))
+	if _, err := client.GetPaper("x", ""); err == nil {
+		t.Fatal("GetPaper succeeded against a failing server")
+	}
+	if got := f.Healthy(); len(got) != 0 {
+		t.Errorf("healthy %v after both failed, want none", got)
+	}
+	clock.Advance(time.Minute)
+	if got := f.Healthy(); len(got) != 2 {
+		t.Errorf("healthy %v after the cooldown, want both", got)
+	}
+
+	l := semscholar.NewAdaptiveLimiter(8, 1, 8)
+	l.Clock = clock
+	for range 2 {
+		l.Acquire(t.Context())
+		l.Release(true, false)
+	}
+	if l.Limit() != 4 {
+		t.Errorf("limit %d after two 429s within the cooldown, want 4", l.Limit())
+	}
+	clock.Advance(time.Second)
+	l.Acquire(t.Context())
+	l.Release(true, false)
+	if l.Limit() != 2 {
+		t.Errorf("limit %d after a 429 past the cooldown, want 2", l.Limit())
+	}
+}
+
+// TestClockRetryAfter checks that an HTTP-date Retry-After is measured from
+// the client's Clock.
+func TestClockRetryAfter(t *testing.T) {
+	clock := semscholartest.NewFakeClock(time.Time{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", clock.Now().Add(30*time.Second).UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+	client := semscholar.NewClient(srv.URL, srv.Client())
+	client.Clock = clock
+	_, err := client.GetPaper("x", "")
+	apiErr, ok := err.(*semscholar.APIError)
+	if !ok {
+		t.Fatalf("GetPaper returned %v, want an APIError", err)
+	}
+	if apiErr.RetryAfter != 30*time.Second {
+		t.Errorf("RetryAfter = %v, want 30s", apiErr.RetryAfter)
+	}
+}
@@ -0,0 +1,388 @@
+// Package cluster groups papers into themes by their embeddings, such as
+// those of the SPECTER model, and labels each group with the terms that
+// distinguish its titles.
+package cluster
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// Method is a clustering algorithm.
+type Method int
+
+const (
+	// KMeans is spherical k-means, with k-means++ seeding: papers are
+	// assigned to the centroid their embedding is most similar to.
+	KMeans Method = iota
+	// Agglomerative is average-linkage agglomerative clustering, which
+	// merges the two most similar clusters until k are left. It takes time
+	// cubic in the number of papers, so suits sets of a thousand or two.
+	Agglomerative
+)
+
+func (m Method) String() string {
+	switch m {
+	case KMeans:
+		return "kmeans"
+	case Agglomerative:
+		return "agglomerative"
+	}
+	return fmt.Sprintf("Method(%d)", int(m))
+}
+
+// DefaultMaxK is the largest number of clusters tried without Options.MaxK.
+const DefaultMaxK = 10
+
+// Options configure Papers.
+type Options struct {
+	Method Method
+	// K is the number of clusters. Zero chooses it: every k from 2 to MaxK
+	// is tried and the one with the best silhouette kept.
+	K    int
+	MaxK int
+	// Seed seeds k-means, whose result depends on it.
+	Seed uint64
+	// LabelTerms is the number of title terms in a label; zero means 3.
+	LabelTerms int
+}
+
+// Cluster is a group of papers with similar embeddings.
+type Cluster struct {
+	// Label is the Terms joined by commas.
+	Label string `json:"label"`
+	// Terms are the words most distinctive of the titles of the papers,
+	// against those of all the papers clustered.
+	Terms  []string           `json:"terms"`
+	Papers []semscholar.Paper `json:"papers"`
+	// Centroid is the normalized mean of the embeddings of the papers.
+	Centroid []float64 `json:"-"`
+}
+
+// ErrNoEmbeddings is returned by Papers when too few papers have
+// embeddings to cluster.
+var ErrNoEmbeddings = errors.New("cluster: too few papers with embeddings")
+
+// Papers clusters the papers that have embeddings, largest cluster first,
+// and returns those without apart. The embeddings must all be of one length.
+func Papers(papers []semscholar.Paper, opts Options) (clusters []Cluster, unembedded []semscholar.Paper, err error) {
+	var embedded []semscholar.Paper
+	var vectors [][]float64
+	for _, p := range papers {
+		if p.Embedding == nil || len(p.Embedding.Vector) == 0 {
+			unembedded = append(unembedded, p)
+			continue
+		}
+		if len(vectors) > 0 && len(p.Embedding.Vector) != len(vectors[0]) {
+			return nil, nil, fmt.Errorf("cluster: paper %s has an embedding of length %d, not %d", p.PaperID, len(p.Embedding.Vector), len(vectors[0]))
+		}
+		embedded = append(embedded, p)
+		vectors = append(vectors, normalize(p.Embedding.Vector))
+	}
+	if len(vectors) < 2 || opts.K > len(vectors) {
+		return nil, unembedded, ErrNoEmbeddings
+	}
+
+	var assign []int
+	if k := opts.K; k > 0 {
+		assign = opts.assign(vectors, k)
+	} else {
+		best := math.Inf(-1)
+		for k := 2; k <= min(cmp.Or(opts.MaxK, DefaultMaxK), len(vectors)-1); k++ {
+			a := opts.assign(vectors, k)
+			if s := Silhouette(vectors, a); s > best {
+				best, assign = s, a
+			}
+		}
+		if assign == nil {
+			assign = make([]int, len(vectors))
+		}
+	}
+
+	n := slices.Max(assign) + 1
+	clusters = make([]Cluster, n)
+	for i, c := range assign {
+		clusters[c].Papers = append(clusters[c].Papers, embedded[i])
+		clusters[c].Centroid = add(clusters[c].Centroid, vectors[i])
+	}
+	clusters = slices.DeleteFunc(clusters, func(c Cluster) bool { return len(c.Papers) == 0 })
+	slices.SortStableFunc(clusters, func(a, b Cluster) int { return len(b.Papers) - len(a.Papers) })
+	terms := cmp.Or(opts.LabelTerms, 3)
+	for i := range clusters {
+		clusters[i].Centroid = normalize(clusters[i].Centroid)
+		clusters[i].Terms = LabelTerms(clusters[i].Papers, embedded, terms)
+		clusters[i].Label = strings.Join(clusters[i].Terms, ", ")
+	}
+	return clusters, unembedded, nil
+}
+
+func (o Options) assign(vectors [][]float64, k int) []int {
+	if o.Method == Agglomerative {
+		return agglomerative(vectors, k)
+	}
+	return kmeans(vectors, k, o.Seed)
+}
+
+// kmeansRounds bounds the rounds of k-means, which usually settles sooner.
+const kmeansRounds = 100
+
+// kmeans returns the cluster of each of vectors, which are normalized.
+func kmeans(vectors [][]float64, k int, seed uint64) []int {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	// k-means++: each further centroid is drawn with probability
+	// proportional to the squared distance to the nearest one so far.
+	centroids := [][]float64{vectors[rng.IntN(len(vectors))]}
+	dist := make([]float64, len(vectors))
+	for len(centroids) < k {
+		total := 0.0
+		for i, v := range vectors {
+			d := math.Inf(1)
+			for _, c := range centroids {
+				d = min(d, 1-dot(v, c))
+			}
+			dist[i] = d * d
+			total += dist[i]
+		}
+		next := len(vectors) - 1
+		if total > 0 {
+			r := rng.Float64() * total
+			for i, d := range dist {
+				if r -= d; r < 0 {
+					next = i
+					break
+				}
+			}
+		}
+		centroids = append(centroids, vectors[next])
+	}
+
+	assign := make([]int, len(vectors))
+	for round := range kmeansRounds {
+		changed := round == 0
+		for i, v := range vectors {
+			best, bestSim := 0, math.Inf(-1)
+			for c, centroid := range centroids {
+				if s := dot(v, centroid); s > bestSim {
+					best, bestSim = c, s
+				}
+			}
+			if assign[i] != best {
+				assign[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+		sums := make([][]float64, k)
+		for i, c := range assign {
+			sums[c] = add(sums[c], vectors[i])
+		}
+		for c := range centroids {
+			// A cluster left empty keeps its centroid.
+			if sums[c] != nil {
+				centroids[c] = normalize(sums[c])
+			}
+		}
+	}
+	return assign
+}
+
+// agglomerative returns the cluster of each of vectors, which are
+// normalized, merging by average similarity until k clusters are left.
+func agglomerative(vectors [][]float64, k int) []int {
+	n := len(vectors)
+	sim := make([][]float64, n)
+	for i := range sim {
+		sim[i] = make([]float64, n)
+		for j := range i {
+			sim[i][j] = dot(vectors[i], vectors[j])
+			sim[j][i] = sim[i][j]
+		}
+	}
+	size := make([]int, n)
+	members := make([][]int, n)
+	for i := range n {
+		size[i] = 1
+		members[i] = []int{i}
+	}
+	for live := n; live > k; live-- {
+		a, b, best := -1, -1, math.Inf(-1)
+		for i := range n {
+			if size[i] == 0 {
+				continue
+			}
+			for j := i + 1; j < n; j++ {
+				if size[j] > 0 && sim[i][j] > best {
+					a, b, best = i, j, sim[i][j]
+				}
+			}
+		}
+		// sim holds average similarities between clusters; merging b into
+		// a weights the averages by the sizes of the two.
+		for j := range n {
+			if size[j] > 0 && j != a && j != b {
+				sim[a][j] = (sim[a][j]*float64(size[a]) + sim[b][j]*float64(size[b])) / float64(size[a]+size[b])
+				sim[j][a] = sim[a][j]
+			}
+		}
+		size[a] += size[b]
+		size[b] = 0
+		members[a] = append(members[a], members[b]...)
+		members[b] = nil
+	}
+	assign := make([]int, n)
+	c := 0
+	for i := range n {
+		if size[i] == 0 {
+			continue
+		}
+		for _, m := range members[i] {
+			assign[m] = c
+		}
+		c++
+	}
+	return assign
+}
+
+// Silhouette returns the mean silhouette of the clustering of vectors,
+// which are normalized, with cosine distance: from -1 to 1, higher when
+// clusters are tight and apart. Papers alone in their cluster count zero.
+func Silhouette(vectors [][]float64, assign []int) float64 {
+	k := slices.Max(assign) + 1
+	total := 0.0
+	for i, v := range vectors {
+		sums := make([]float64, k)
+		counts := make([]int, k)
+		for j, w := range vectors {
+			if i != j {
+				sums[assign[j]] += 1 - dot(v, w)
+				counts[assign[j]]++
+			}
+		}
+		own := assign[i]
+		if counts[own] == 0 {
+			continue
+		}
+		a := sums[own] / float64(counts[own])
+		b := math.Inf(1)
+		for c := range k {
+			if c != own && counts[c] > 0 {
+				b = min(b, sums[c]/float64(counts[c]))
+			}
+		}
+		if math.IsInf(b, 1) {
+			continue
+		}
+		if m := max(a, b); m > 0 {
+			total += (b - a) / m
+		}
+	}
+	return total / float64(len(vectors))
+}
+
+// stopwords are the words left out of labels.
+var stopwords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`a an and are as at based be by for from how in into is it its of on or over the their this to toward towards under using via we what when with without
+		approach analysis method methods new novel paper study results`) {
+		stopwords[w] = true
+	}
+}
+
+// titleTerms returns the distinct words of the title of p, lower case,
+// without stopwords, numbers or single letters.
+func titleTerms(p semscholar.Paper) []string {
+	words := strings.FieldsFunc(strings.ToLower(p.Title), func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '-' || r > 0x7f)
+	})
+	var terms []string
+	for _, w := range words {
+		w = strings.Trim(w, "-")
+		if len(w) < 2 || stopwords[w] || strings.Trim(w, "0123456789") == "" {
+			continue
+		}
+		if !slices.Contains(terms, w) {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+// LabelTerms returns the n title terms most distinctive of papers among
+// all: those in the most of their titles, weighed down by how common they
+// are in the titles of all.
+func LabelTerms(papers, all []semscholar.Paper, n int) []string {
+	df := make(map[string]int)
+	for _, p := range all {
+		for _, t := range titleTerms(p) {
+			df[t]++
+		}
+	}
+	tf := make(map[string]int)
+	for _, p := range papers {
+		for _, t := range titleTerms(p) {
+			tf[t]++
+		}
+	}
+	type scored struct {
+		term  string
+		score float64
+	}
+	var terms []scored
+	for t, c := range tf {
+		if c < 2 && len(papers) > 2 {
+			continue
+		}
+		idf := math.Log(float64(len(all)+1) / float64(df[t]+1))
+		terms = append(terms, scored{t, float64(c) * (1 + idf)})
+	}
+	slices.SortFunc(terms, func(a, b scored) int {
+		return cmp.Or(cmp.Compare(b.score, a.score), strings.Compare(a.term, b.term))
+	})
+	labels := make([]string, 0, n)
+	for _, t := range terms[:min(n, len(terms))] {
+		labels = append(labels, t.term)
+	}
+	return labels
+}
+
+func dot(a, b []float64) float64 {
+	s := 0.0
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+// add adds v to sum, allocating sum if it is nil.
+func add(sum, v []float64) []float64 {
+	if sum == nil {
+		sum = make([]float64, len(v))
+	}
+	for i := range v {
+		sum[i] += v[i]
+	}
+	return sum
+}
+
+// normalize returns v scaled to unit length, or a copy of v if it is zero.
+func normalize(v []float64) []float64 {
+	norm := math.Sqrt(dot(v, v))
+	u := make([]float64, len(v))
+	for i := range v {
+		u[i] = v[i]
+		if norm > 0 {
+			u[i] /= norm
+		}
+	}
+	return u
+}
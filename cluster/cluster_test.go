@@ -0,0 +1,148 @@
+package cluster_test
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/cluster"
+)
+
+// embedded returns a paper titled title with the embedding vector, or none
+// if vector is empty.
+func embedded(id, title string, vector ...float64) semscholar.Paper {
+	p := semscholar.Paper{PaperID: id, Title: title}
+	if len(vector) > 0 {
+		p.Embedding = &semscholar.Embedding{Model: "specter_v2", Vector: vector}
+	}
+	return p
+}
+
+// themes returns three papers on graphs and two on proteins, with
+// embeddings near two orthogonal axes, and one paper without an embedding.
+func themes() []semscholar.Paper {
+	return []semscholar.Paper{
+		embedded("g1", "Graph neural networks", 1, 0.1, 0),
+		embedded("p1", "Protein folding", 0, 1, 0.1),
+		embedded("g2", "Graph attention networks", 1, 0, 0.1),
+		embedded("none", "Graph of proteins"),
+		embedded("g3", "Graph convolution", 0.9, 0.1, 0.1),
+		embedded("p2", "Protein structure", 0.1, 1, 0),
+	}
+}
+
+func ids(papers []semscholar.Paper) []string {
+	var ids []string
+	for _, p := range papers {
+		ids = append(ids, p.PaperID)
+	}
+	return ids
+}
+
+// TestPapers checks that both methods, with k given or chosen, find the two
+// themes, largest first, with their labels, and return the paper without an
+// embedding apart.
+func TestPapers(t *testing.T) {
+	for _, opts := range []cluster.Options{
+		{Method: cluster.KMeans},
+		{Method: cluster.KMeans, K: 2, Seed: 7},
+		{Method: cluster.Agglomerative},
+		{Method: cluster.Agglomerative, K: 2},
+	} {
+		t.Run(opts.Method.String(), func(t *testing.T) {
+			clusters, unembedded, err := cluster.Papers(themes(), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(unembedded); !slices.Equal(got, []string{"none"}) {
+				t.Errorf("unembedded %v, want [none]", got)
+			}
+			if len(clusters) != 2 {
+				t.Fatalf("%d clusters, want 2", len(clusters))
+			}
+			if got := ids(clusters[0].Papers); !slices.Equal(got, []string{"g1", "g2", "g3"}) {
+				t.Errorf("first cluster %v, want the graph papers", got)
+			}
+			if got := ids(clusters[1].Papers); !slices.Equal(got, []string{"p1", "p2"}) {
+				t.Errorf("second cluster %v, want the protein papers", got)
+			}
+			if clusters[0].Label != "graph, networks" || clusters[1].Label != "protein, folding, structure" {
+				t.Errorf("labels %q and %q", clusters[0].Label, clusters[1].Label)
+			}
+			if n := math.Hypot(math.Hypot(clusters[0].Centroid[0], clusters[0].Centroid[1]), clusters[0].Centroid[2]); math.Abs(n-1) > 1e-9 {
+				t.Errorf("centroid of length %v, want 1", n)
+			}
+		})
+	}
+}
+
+// TestPapersErrors checks embeddings of different lengths and too few
+// embedded papers for the clusters asked for.
+func TestPapersErrors(t *testing.T) {
+	mixed := []semscholar.Paper{embedded("a", "A", 1, 0), embedded("b", "B", 1, 0, 0)}
+	if _, _, err := cluster.Papers(mixed, cluster.Options{}); err == nil || errors.Is(err, cluster.ErrNoEmbeddings) {
+		t.Errorf("embeddings of different lengths: %v", err)
+	}
+	one := []semscholar.Paper{embedded("a", "A", 1, 0), embedded("b", "B")}
+	if _, unembedded, err := cluster.Papers(one, cluster.Options{}); !errors.Is(err, cluster.ErrNoEmbeddings) || len(unembedded) != 1 {
+		t.Errorf("one embedded paper: %v with %d unembedded, want ErrNoEmbeddings with 1", err, len(unembedded))
+	}
+	if _, _, err := cluster.Papers(themes(), cluster.Options{K: 6}); !errors.Is(err, cluster.ErrNoEmbeddings) {
+		t.Errorf("more clusters than embedded papers: %v, want ErrNoEmbeddings", err)
+	}
+}
+
+// TestPapersTwo checks that two papers, too few to compare values of k,
+// form one cluster.
+func TestPapersTwo(t *testing.T) {
+	clusters, _, err := cluster.Papers([]semscholar.Paper{embedded("a", "A", 1, 0), embedded("b", "B", 0, 1)}, cluster.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 1 || len(clusters[0].Papers) != 2 {
+		t.Errorf("clusters %+v, want one of both papers", clusters)
+	}
+}
+
+// TestSilhouette checks perfectly separated clusters and papers alone in
+// their cluster, which count zero.
+func TestSilhouette(t *testing.T) {
+	x, y := []float64{1, 0}, []float64{0, 1}
+	for _, tc := range []struct {
+		name    string
+		vectors [][]float64
+		assign  []int
+		want    float64
+	}{
+		{"separated", [][]float64{x, x, y, y}, []int{0, 0, 1, 1}, 1},
+		{"singleton", [][]float64{x, y, y}, []int{0, 1, 1}, 2.0 / 3},
+		{"mixed", [][]float64{x, y, x, y}, []int{0, 0, 1, 1}, -0.5},
+		{"one cluster", [][]float64{x, y}, []int{0, 0}, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cluster.Silhouette(tc.vectors, tc.assign); math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("Silhouette = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestLabelTerms checks that stopwords, numbers and single letters are left
+// out, that terms in one title of a large cluster are skipped, and that
+// rarer terms rank first among those in as many titles.
+func TestLabelTerms(t *testing.T) {
+	papers := []semscholar.Paper{
+		{Title: "A study of 3D graph models"},
+		{Title: "Graph models for X"},
+		{Title: "Graph kernels"},
+	}
+	all := append(slices.Clone(papers), semscholar.Paper{Title: "Models of proteins"})
+	if got, want := cluster.LabelTerms(papers, all, 5), []string{"graph", "models"}; !slices.Equal(got, want) {
+		t.Errorf("LabelTerms = %v, want %v", got, want)
+	}
+	if got, want := cluster.LabelTerms(papers[2:], all, 5), []string{"kernels", "graph"}; !slices.Equal(got, want) {
+		t.Errorf("LabelTerms of one paper = %v, want %v", got, want)
+	}
+}
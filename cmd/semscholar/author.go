@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/spf13/cobra"
+)
+
+// defaultAuthorFields are the fields authors are requested with.
+const defaultAuthorFields = "name,url,affiliations,hIndex,paperCount"
+
+// Columns authors are shown with, alone and in lists.
+const (
+	detailAuthorColumns  = "id,name,affiliations,hindex,papers,url"
+	defaultAuthorColumns = "id,name,hindex,papers,affiliations"
+)
+
+func newAuthorGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get id",
+		Short: "Look up an author's metrics and affiliations",
+	}
+	fs := cmd.Flags()
+	fields := fs.String("fields", defaultAuthorFields, "comma-separated author fields to request")
+	addOutputFlags(fs, recordFormats)
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return usageError("want one author ID")
+		}
+		a, err := newClient(graphURL).GetAuthor(authorID(args[0]), *fields)
+		if err != nil {
+			return err
+		}
+		return writeRecord(*a, authorColumns, detailAuthorColumns)
+	}
+	return cmd
+}
+
+func newAuthorSearchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search name",
+		Short: "Search for authors by name",
+	}
+	fs := cmd.Flags()
+	fields := fs.String("fields", defaultAuthorFields, "comma-separated author fields to request")
+	limit := fs.Int("limit", 10, "number of results, at most 1000")
+	offset := fs.Int("offset", 0, "number of results to skip")
+	addOutputFlags(fs, recordFormats)
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return usageError("missing name")
+		}
+		resp, err := newClient(graphURL).SearchAuthors(strings.Join(args, " "), *offset, *limit, *fields)
+		if err != nil {
+			return err
+		}
+		if err := writeRecords(resp.Data, authorColumns, defaultAuthorColumns); err != nil {
+			return err
+		}
+		reportPage(resp.Offset, len(resp.Data), resp.Total)
+		return nil
+	}
+	return cmd
+}
+
+func newAuthorPapersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "papers id",
+		Short: "List an author's papers",
+	}
+	fs := cmd.Flags()
+	fields := fs.String("fields", paperFields(), "comma-separated paper fields to request")
+	limit := fs.Int("limit", 100, "number of papers, at most 1000")
+	offset := fs.Int("offset", 0, "number of papers to skip")
+	all := fs.Bool("all", false, "list every paper, paging through them all")
+	addOutputFlags(fs, recordFormats)
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return usageError("want one author ID")
+		}
+		client := newClient(graphURL)
+		id := authorID(args[0])
+
+		if *all {
+			papers, err := semscholar.Collect(client.GetAuthorPapersSeq(context.Background(), id, *fields))
+			if err != nil {
+				return err
+			}
+			if err := writeRecords(papers, paperColumns, defaultPaperColumns); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "%d papers in all\n", len(papers))
+			return nil
+		}
+
+		resp, err := client.GetAuthorPapers(id, *offset, *limit, *fields)
+		if err != nil {
+			return err
+		}
+		if err := writeRecords(resp.Data, paperColumns, defaultPaperColumns); err != nil {
+			return err
+		}
+		if len(resp.Data) == 0 {
+			fmt.Fprintln(os.Stderr, "no papers")
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "papers %d-%d", resp.Offset+1, resp.Offset+len(resp.Data))
+		if resp.Next > 0 {
+			fmt.Fprintf(os.Stderr, "; more with --offset %d, or all with --all", resp.Next)
+		}
+		fmt.Fprintln(os.Stderr)
+		return nil
+	}
+	return cmd
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestAuthorCommands checks the output of author get, search and papers.
+func TestAuthorCommands(t *testing.T) {
+	mockAPI(t)
+	he, resnet := semscholartest.HeAuthorID, semscholartest.ResNetPaperID
+	for _, tt := range []struct {
+		args         []string
+		stdout, note string
+	}{
+		{
+			[]string{"author", "get", semscholartest.VaswaniAuthorID},
+			"id            40348417\nname          Ashish Vaswani\naffiliations  Google Brain\nhindex        1\npapers        1\nurl           https://www.semanticscholar.org/author/40348417\n",
+			"",
+		},
+		{
+			[]string{"author", "get", "https://www.semanticscholar.org/author/Kaiming-He/" + he, "--output", "csv"},
+			"id,name,affiliations,hindex,papers,url\n" + he + ",Kaiming He,MIT,1,1,https://www.semanticscholar.org/author/" + he + "\n",
+			"",
+		},
+		{
+			[]string{"author", "search", "he"},
+			"ID        NAME        HINDEX  PAPERS  AFFILIATIONS\n" + he + "  Kaiming He  1       1       MIT\n",
+			"\n1-1 of 1 results\n",
+		},
+		{
+			[]string{"author", "papers", he, "--output", "csv", "--columns", "id,title"},
+			"id,title\n" + resnet + ",Deep Residual Learning for Image Recognition\n",
+			"papers 1-1\n",
+		},
+		{
+			[]string{"author", "papers", he, "--all", "--output", "jsonl", "--fields", "year"},
+			`{"paperId":"` + resnet + `","title":"","year":2015}` + "\n",
+			"1 papers in all\n",
+		},
+	} {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			status, stdout, stderr := runCLI(t, "", tt.args...)
+			if status != 0 {
+				t.Fatalf("status = %d, want 0; stderr %q", status, stderr)
+			}
+			if stdout != tt.stdout {
+				t.Errorf("stdout = %q, want %q", stdout, tt.stdout)
+			}
+			if stderr != tt.note {
+				t.Errorf("stderr = %q, want %q", stderr, tt.note)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/export"
+	"github.com/spf13/cobra"
+)
+
+// newBibEnrichCmd returns the bib enrich command, which enriches a BibTeX file,
+// writing the result to standard output, another file or, with --write, the
+// file itself, and reports the entries changed, flagged or left unresolved on
+// standard error. Entries not filled in are written as they were.
+func newBibEnrichCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enrich file.bib",
+		Short: "Resolve the entries of a BibTeX file, fill in their missing DOIs, URLs and abstracts, and flag retracted and duplicate entries",
+	}
+	fs := cmd.Flags()
+	fields := fs.String("fields", strings.Join(export.DefaultEnrichFields, ","), "comma-separated fields to fill in where missing, of "+strings.Join(export.EnrichableFields, ", "))
+	threshold := fs.Float64("threshold", semscholar.DefaultMatchThreshold, "lowest score, from 0 to 1, at which a title matches")
+	out := fs.StringP("out", "o", "", "file to write (default standard output)")
+	inPlace := fs.BoolP("write", "w", false, "write the result to the file itself")
+	report := fs.String("report", "", "file to write the outcome for each entry to, as JSON")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return usageError("want one BibTeX file")
+		}
+		if *inPlace && *out != "" {
+			return usageError("--write and --out are exclusive")
+		}
+		opts := export.EnrichOptions{Threshold: *threshold}
+		for _, f := range strings.Split(*fields, ",") {
+			f = strings.ToLower(strings.TrimSpace(f))
+			if !slices.Contains(export.EnrichableFields, f) {
+				return usageError(fmt.Sprintf("cannot fill in %q; want %s", f, strings.Join(export.EnrichableFields, ", ")))
+			}
+			opts.Fields = append(opts.Fields, f)
+		}
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		entries, err := export.ReadBibTeX(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		updates, err := export.EnrichBibTeX(ctx, newClient(graphURL), entries, opts)
+		if err != nil {
+			return err
+		}
+
+		var b bytes.Buffer
+		if err := export.WriteBibEntries(&b, entries); err != nil {
+			return err
+		}
+		switch {
+		case *inPlace:
+			// By way of a temporary file, so an interrupted write leaves the
+			// file whole.
+			tmp := args[0] + ".tmp"
+			if err := os.WriteFile(tmp, b.Bytes(), 0o644); err != nil {
+				return err
+			}
+			if err := os.Rename(tmp, args[0]); err != nil {
+				return err
+			}
+		case *out != "":
+			if err := os.WriteFile(*out, b.Bytes(), 0o644); err != nil {
+				return err
+			}
+		default:
+			if _, err := os.Stdout.Write(b.Bytes()); err != nil {
+				return err
+			}
+		}
+		if *report != "" {
+			data, err := json.MarshalIndent(updates, "", "  ")
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(*report, append(data, '\n'), 0o644); err != nil {
+				return err
+			}
+		}
+		printBibUpdates(updates)
+		return nil
+	}
+	return cmd
+}
+
+// printBibUpdates lists on standard error the entries filled in, flagged or
+// unresolved, and counts them.
+func printBibUpdates(updates []export.BibUpdate) {
+	tw := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	var enriched, flagged, unresolved int
+	for _, u := range updates {
+		var notes []string
+		if len(u.Added) > 0 {
+			enriched++
+			notes = append(notes, "added "+strings.Join(u.Added, ", "))
+		}
+		if u.Retracted {
+			notes = append(notes, "RETRACTED")
+		}
+		if u.DuplicateOf != "" {
+			notes = append(notes, "duplicate of "+u.DuplicateOf)
+		}
+		if u.Retracted || u.DuplicateOf != "" {
+			flagged++
+		}
+		if u.PaperID == "" {
+			unresolved++
+			notes = append(notes, "unresolved: "+u.Error)
+		}
+		if len(notes) > 0 {
+			fmt.Fprintf(tw, "%s\t%s\n", u.Key, strings.Join(notes, "; "))
+		}
+	}
+	tw.Flush()
+	fmt.Fprintf(os.Stderr, "%d entries: %d enriched, %d flagged, %d unresolved\n", len(updates), enriched, flagged, unresolved)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/spf13/pflag"
+)
+
+// Base URLs of the APIs.
+const (
+	graphURL           = "https://api.semanticscholar.org/graph/v1"
+	recommendationsURL = "https://api.semanticscholar.org/recommendations/v1"
+	datasetsURL        = "https://api.semanticscholar.org/datasets/v1"
+)
+
+// Environment variables the commands read.
+const (
+	apiKeyEnv  = "SEMSCHOLAR_API_KEY"
+	baseURLEnv = "SEMSCHOLAR_BASE_URL"
+)
+
+// newClient returns a client of the API at baseURL, or at the base URL of
+// the environment or configuration, sending the API key if there is one and
+// retrying throttled calls. It paces requests through the shared limiters
+// and caches responses as the configuration asks.
+func newClient(baseURL string) *semscholar.Client {
+	baseURL = cmp.Or(os.Getenv(baseURLEnv), cfg.get("base_url"), baseURL)
+	var hc semscholar.HTTPClient = semscholar.NewHTTPClient(semscholar.DefaultTimeout, semscholar.TransportOptions{})
+	if key := cmp.Or(os.Getenv(apiKeyEnv), cfg.get("api_key")); key != "" {
+		hc = &keyClient{next: hc, key: key}
+	}
+	rate, inflight := sharedLimiters()
+	if rate != nil {
+		hc = semscholar.NewRateLimitedClient(hc, rate)
+	}
+	if inflight != nil {
+		hc = semscholar.NewAdaptiveClient(hc, inflight)
+	}
+	c := semscholar.NewClient(baseURL, hc)
+	c.Retry = semscholar.RetryPolicy{MaxRetries: 3}
+	if dir := cfg.get("cache_dir"); dir != "" {
+		cache, err := semscholar.NewDiskCache(dir, 0)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "semscholar: not caching: %v\n", err)
+		} else {
+			c.Cache = cache
+		}
+	}
+	return c
+}
+
+// limits are the global --rps and --concurrency flags, overriding the rps
+// and concurrency settings; zero leaves them to the settings.
+var limits struct {
+	rps         float64
+	concurrency int
+}
+
+// addLimitFlags adds --rps and --concurrency to fs.
+func addLimitFlags(fs *pflag.FlagSet) {
+	fs.Float64Var(&limits.rps, "rps", 0, "most requests per second, shared by every request the command makes, downloads included (default the rps setting, or 1 without an API key)")
+	fs.IntVar(&limits.concurrency, "concurrency", 0, "most API requests in flight at once, and files downloaded at once (default the concurrency setting, else unbounded)")
+}
+
+// checkLimitFlags returns a usage error if --rps or --concurrency is given
+// and out of range.
+func checkLimitFlags(fs *pflag.FlagSet) error {
+	for _, f := range []struct {
+		name  string
+		check func(string) error
+	}{{"rps", checkRPS}, {"concurrency", checkConcurrency}} {
+		if flag := fs.Lookup(f.name); flag != nil && flag.Changed {
+			if err := f.check(flag.Value.String()); err != nil {
+				return usageError(err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// shared holds the limiters every request of a command goes through.
+var shared struct {
+	once     sync.Once
+	rate     *semscholar.RateLimiter
+	inflight *semscholar.AdaptiveLimiter
+}
+
+// sharedLimiters returns the limiters of the --rps and --concurrency flags, or
+// of the settings, nil where there is no limit. Without an API key, calls
+// to the Semantic Scholar API are held to its unauthenticated limit of 1 a
+// second; another base URL, such as that of semscholar mock serve, has no
+// limit by default.
+func sharedLimiters() (*semscholar.RateLimiter, *semscholar.AdaptiveLimiter) {
+	shared.once.Do(func() {
+		rps := limits.rps
+		if rps == 0 {
+			rps, _ = strconv.ParseFloat(cfg.get("rps"), 64)
+		}
+		anonymous := cmp.Or(os.Getenv(apiKeyEnv), cfg.get("api_key")) == ""
+		if rps == 0 && anonymous && cmp.Or(os.Getenv(baseURLEnv), cfg.get("base_url")) == "" {
+			rps = 1
+		}
+		if rps > 0 {
+			shared.rate = semscholar.NewRateLimiter(rps)
+		}
+		n := limits.concurrency
+		if n == 0 {
+			n, _ = strconv.Atoi(cfg.get("concurrency"))
+		}
+		if n > 0 {
+			// An adaptive limiter held at n is a fixed bound on requests in
+			// flight.
+			shared.inflight = semscholar.NewAdaptiveLimiter(n, n, n)
+		}
+	})
+	return shared.rate, shared.inflight
+}
+
+// downloadClient returns an HTTP client for downloading files, paced by the
+// shared rate limiter. Files can take longer than any timeout the API calls
+// use, so it has none.
+func downloadClient() semscholar.HTTPClient {
+	var hc semscholar.HTTPClient = semscholar.NewHTTPClient(0, semscholar.TransportOptions{})
+	if rate, _ := sharedLimiters(); rate != nil {
+		hc = semscholar.NewRateLimitedClient(hc, rate)
+	}
+	return hc
+}
+
+// downloadParallelism returns how many files to download at once: the
+// --concurrency flag or setting, else def.
+func downloadParallelism(def int) int {
+	if _, inflight := sharedLimiters(); inflight != nil {
+		return inflight.Limit()
+	}
+	return def
+}
+
+// paperFields returns the paper fields tables are shown with: those of the
+// configuration, or defaultPaperFields.
+func paperFields() string {
+	return cmp.Or(cfg.get("fields"), defaultPaperFields)
+}
+
+// keyClient is an HTTPClient that sends an API key with every request.
+type keyClient struct {
+	next semscholar.HTTPClient
+	key  string
+}
+
+func (c *keyClient) Do(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("x-api-key", c.key)
+	return c.next.Do(req)
+}
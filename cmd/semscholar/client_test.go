@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// resetLimiters forgets the limiters of earlier command lines, so the next
+// one makes its own, and again when t ends.
+func resetLimiters(t *testing.T) {
+	t.Helper()
+	shared.once, shared.rate, shared.inflight = sync.Once{}, nil, nil
+	t.Cleanup(func() { shared.once, shared.rate, shared.inflight = sync.Once{}, nil, nil })
+}
+
+// TestSharedLimiters checks which limits the flags, settings and
+// environment set, and that the flags take precedence.
+func TestSharedLimiters(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		flags       []string
+		settings    map[string]string
+		env         map[string]string
+		rate        bool
+		concurrency int
+	}{
+		{name: "anonymous", rate: true},
+		{name: "API key", env: map[string]string{apiKeyEnv: "key"}},
+		{name: "API key setting", settings: map[string]string{"api_key": "key"}},
+		{name: "base URL", env: map[string]string{baseURLEnv: "http://localhost:8080"}},
+		{name: "settings", settings: map[string]string{"api_key": "key", "rps": "5", "concurrency": "3"}, rate: true, concurrency: 3},
+		{name: "flags", flags: []string{"--rps", "5", "--concurrency", "2"}, env: map[string]string{apiKeyEnv: "key"}, settings: map[string]string{"concurrency": "3"}, rate: true, concurrency: 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			resetLimiters(t)
+			t.Setenv(apiKeyEnv, "")
+			t.Setenv(baseURLEnv, "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			saved := cfg
+			cfg = &config{values: tt.settings}
+			t.Cleanup(func() { cfg = saved })
+			root := newRootCmd()
+			if err := root.PersistentFlags().Parse(tt.flags); err != nil {
+				t.Fatal(err)
+			}
+
+			rate, inflight := sharedLimiters()
+			if (rate != nil) != tt.rate {
+				t.Errorf("rate limited %v, want %v", rate != nil, tt.rate)
+			}
+			n := 0
+			if inflight != nil {
+				n = inflight.Limit()
+			}
+			if n != tt.concurrency {
+				t.Errorf("concurrency %d, want %d", n, tt.concurrency)
+			}
+			want := 4
+			if tt.concurrency > 0 {
+				want = tt.concurrency
+			}
+			if got := downloadParallelism(4); got != want {
+				t.Errorf("downloadParallelism(4) = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+// TestRPSFlag checks that --rps paces every request of a command.
+func TestRPSFlag(t *testing.T) {
+	srv := mockAPI(t)
+	resetLimiters(t)
+	titles := "First title\nSecond title\nThird title\nFourth title\nFifth title\n"
+	start := time.Now()
+	status, _, stderr := runCLI(t, titles, "--rps", "20", "resolve")
+	elapsed := time.Since(start)
+	if status != 0 || stderr != "0 resolved, 5 not found, 0 failed\n" {
+		t.Fatalf("status = %d, stderr %q; want 0 and 5 not found", status, stderr)
+	}
+	n := 0
+	for _, r := range srv.Requests() {
+		if strings.Contains(r, "/paper/search") {
+			n++
+		}
+	}
+	// 20 a second is one every 50ms.
+	if least := time.Duration(n-1) * 50 * time.Millisecond; n < 5 || elapsed < least {
+		t.Errorf("%d searches took %v, want at least 5 taking %v", n, elapsed, least)
+	}
+}
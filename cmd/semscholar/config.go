@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// configEnv names a configuration file to use instead of the default one.
+const configEnv = "SEMSCHOLAR_CONFIG"
+
+// configKeys are the settings of the configuration file, in the order they
+// are written.
+var configKeys = []struct {
+	name, help string
+	check      func(string) error
+}{
+	{"api_key", "API key sent with every request, unless SEMSCHOLAR_API_KEY is set", nil},
+	{"base_url", "base URL replacing those of all the APIs, unless SEMSCHOLAR_BASE_URL is set", nil},
+	{"fields", "paper fields requested for tables by default", nil},
+	{"rps", "most requests per second to send", checkRPS},
+	{"concurrency", "most API requests in flight at once", checkConcurrency},
+	{"cache_dir", "directory to cache responses in", nil},
+	{"library", "file the reading lists of semscholar list are kept in", nil},
+}
+
+func checkRPS(s string) error {
+	if rps, err := strconv.ParseFloat(s, 64); err != nil || rps <= 0 {
+		return fmt.Errorf("rps must be a positive number")
+	}
+	return nil
+}
+
+func checkConcurrency(s string) error {
+	if n, err := strconv.Atoi(s); err != nil || n <= 0 {
+		return fmt.Errorf("concurrency must be a positive integer")
+	}
+	return nil
+}
+
+// config is the configuration file, a YAML mapping of settings to strings.
+type config struct {
+	path   string
+	values map[string]string
+}
+
+// cfg is the configuration the commands run with.
+var cfg = &config{values: make(map[string]string)}
+
+// get returns the named setting, or "" if it is unset.
+func (c *config) get(key string) string {
+	return c.values[key]
+}
+
+// configPath returns the path of the configuration file: the one named by
+// SEMSCHOLAR_CONFIG, or semscholar/config.yaml in $XDG_CONFIG_HOME,
+// ~/.config by default.
+func configPath() (string, error) {
+	if p := os.Getenv(configEnv); p != "" {
+		return p, nil
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "semscholar", "config.yaml"), nil
+}
+
+// loadConfig reads the configuration file at path. A missing file is an
+// empty configuration.
+func loadConfig(path string) (*config, error) {
+	c := &config{path: path, values: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return c, nil
+	}
+	m := doc.Content[0]
+	if m.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s:%d: want a mapping of settings to values", path, m.Line)
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		key, value := m.Content[i], m.Content[i+1]
+		if value.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("%s:%d: %s is not a single value", path, value.Line, key.Value)
+		}
+		if err := checkSetting(key.Value, value.Value); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, key.Line, err)
+		}
+		// A null, such as a key with no value, leaves the setting unset.
+		if value.Value != "" && value.ShortTag() != "!!null" {
+			c.values[key.Value] = value.Value
+		}
+	}
+	return c, nil
+}
+
+// checkSetting reports whether key is a setting and value suits it.
+func checkSetting(key, value string) error {
+	for _, k := range configKeys {
+		if k.name != key {
+			continue
+		}
+		if k.check != nil && value != "" {
+			return k.check(value)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown setting %q", key)
+}
+
+// save writes c to its file, readable only by its owner since it may hold
+// the API key.
+func (c *config) save() error {
+	m := &yaml.Node{Kind: yaml.MappingNode, HeadComment: "semscholar configuration; see semscholar config get -h"}
+	for _, k := range configKeys {
+		if v, ok := c.values[k.name]; ok {
+			var value yaml.Node
+			value.SetString(v)
+			m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: k.name}, &value)
+		}
+	}
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// configSettings describes the settings, for the help of the config
+// commands.
+func configSettings() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The settings, kept in %s, are:\n\n", cfg.path)
+	for _, k := range configKeys {
+		fmt.Fprintf(&b, "  %-11s  %s\n", k.name, k.help)
+	}
+	return b.String()
+}
+
+func newConfigGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get [key]",
+		Short: "Show the configuration, or one setting of it",
+		Long:  "Show the configuration, or one setting of it.\n\n" + configSettings(),
+	}
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		switch len(args) {
+		case 0:
+			// The API key is masked in the listing, as it may be on screen.
+			for _, k := range configKeys {
+				if v, ok := cfg.values[k.name]; ok {
+					if k.name == "api_key" && len(v) > 4 {
+						v = strings.Repeat("*", len(v)-4) + v[len(v)-4:]
+					}
+					fmt.Printf("%s: %s\n", k.name, v)
+				}
+			}
+			return nil
+		case 1:
+			if err := checkSetting(args[0], ""); err != nil {
+				return usageError(err.Error())
+			}
+			v, ok := cfg.values[args[0]]
+			if !ok {
+				return fmt.Errorf("%s is not set", args[0])
+			}
+			fmt.Println(v)
+			return nil
+		}
+		return usageError("want at most one setting")
+	}
+	return cmd
+}
+
+func newConfigSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set key [value]",
+		Short: "Change a setting; with no value, read it from standard input",
+		Long:  "Change a setting; with no value, read it from standard input.\n\n" + configSettings(),
+	}
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 || len(args) > 2 {
+			return usageError("want a setting and a value")
+		}
+		key := args[0]
+		if err := checkSetting(key, ""); err != nil {
+			return usageError(err.Error())
+		}
+		var value string
+		if len(args) == 2 {
+			value = args[1]
+		} else {
+			// Reading the value keeps secrets such as the API key out of the
+			// shell's history.
+			if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+				fmt.Fprintf(os.Stderr, "%s: ", key)
+			}
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				return fmt.Errorf("reading %s: %v", key, err)
+			}
+			value = strings.TrimSpace(line)
+		}
+		if err := checkSetting(key, value); err != nil {
+			return usageError(err.Error())
+		}
+		if value == "" {
+			delete(cfg.values, key)
+		} else {
+			cfg.values[key] = value
+		}
+		return cfg.save()
+	}
+	return cmd
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestConfigSave checks that a saved configuration reads back the same,
+// whatever its values look like to YAML, and is private to its owner.
+func TestConfigSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "semscholar", "config.yaml")
+	c := &config{path: path, values: map[string]string{
+		"api_key":  "#secret: key",
+		"base_url": "http://localhost:8080",
+		"fields":   "title,year",
+		"rps":      "5",
+		"library":  "yes",
+	}}
+	if err := c.save(); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0o600 {
+		t.Errorf("file mode = %v, want 0600", perm)
+	}
+	got, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.values) != len(c.values) {
+		t.Errorf("read %d settings, want %d", len(got.values), len(c.values))
+	}
+	for k, want := range c.values {
+		if v := got.get(k); v != want {
+			t.Errorf("%s = %q, want %q", k, v, want)
+		}
+	}
+}
+
+// TestLoadConfig checks that loadConfig reads settings written by hand,
+// leaves empty ones unset, and reports mistakes with their line.
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	load := func(t *testing.T, data string) (*config, error) {
+		t.Helper()
+		path := filepath.Join(dir, "config.yaml")
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return loadConfig(path)
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		c, err := load(t, "# limits\nrps: 2.5 # per second\nconcurrency: 4\ncache_dir:\nfields: ~\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.get("rps") != "2.5" || c.get("concurrency") != "4" {
+			t.Errorf("rps = %q, concurrency = %q, want 2.5 and 4", c.get("rps"), c.get("concurrency"))
+		}
+		for _, k := range []string{"cache_dir", "fields"} {
+			if _, ok := c.values[k]; ok {
+				t.Errorf("%s set to %q, want it unset", k, c.get(k))
+			}
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		c, err := loadConfig(filepath.Join(dir, "none.yaml"))
+		if err != nil || len(c.values) != 0 {
+			t.Errorf("loadConfig = %v, %v; want an empty configuration", c.values, err)
+		}
+	})
+
+	for _, tt := range []struct {
+		name, data, want string
+	}{
+		{"unknown", "rps: 1\ncolour: blue\n", `config.yaml:2: unknown setting "colour"`},
+		{"invalid", "concurrency: 0\n", "config.yaml:1: concurrency must be a positive integer"},
+		{"nested", "fields:\n  - title\n", "config.yaml:2: fields is not a single value"},
+		{"list", "- rps\n", "config.yaml:1: want a mapping of settings to values"},
+		{"syntax", "rps: [1\n", "config.yaml: yaml:"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(t, tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestConfigCommands checks that config set and get change and show the
+// settings, and that commands use them, the environment taking precedence.
+func TestConfigCommands(t *testing.T) {
+	srv := mockAPI(t)
+	t.Setenv(baseURLEnv, "")
+	for _, args := range [][]string{
+		{"config", "set", "base_url", srv.URL},
+		{"config", "set", "fields", "year"},
+	} {
+		if status, _, stderr := runCLI(t, "", args...); status != 0 {
+			t.Fatalf("%s: status = %d; stderr %q", strings.Join(args, " "), status, stderr)
+		}
+	}
+	// Without a value, the setting is read from standard input.
+	if status, _, stderr := runCLI(t, "abcd-secret-1234\n", "config", "set", "api_key"); status != 0 {
+		t.Fatalf("config set api_key: status = %d; stderr %q", status, stderr)
+	}
+
+	_, stdout, _ := runCLI(t, "", "config", "get")
+	want := "api_key: ************1234\nbase_url: " + srv.URL + "\nfields: year\n"
+	if stdout != want {
+		t.Errorf("config get: stdout = %q, want %q", stdout, want)
+	}
+	if _, stdout, _ := runCLI(t, "", "config", "get", "api_key"); stdout != "abcd-secret-1234\n" {
+		t.Errorf("config get api_key: stdout = %q, want the key", stdout)
+	}
+	status, _, stderr := runCLI(t, "", "config", "set", "colour", "red")
+	if status != 2 || !strings.Contains(stderr, `"colour"`) {
+		t.Errorf("config set colour: status = %d, stderr %q; want 2 and the unknown setting", status, stderr)
+	}
+
+	// The search runs against base_url with the fields setting.
+	_, stdout, stderr = runCLI(t, "", "search", "residual", "--output", "jsonl")
+	if want := `{"paperId":"2c03df8b48bf3fa39054345bafabfeff15bfd11d","title":"","year":2015}` + "\n"; stdout != want {
+		t.Errorf("search: stdout = %q, want %q; stderr %q", stdout, want, stderr)
+	}
+	t.Setenv(baseURLEnv, "http://127.0.0.1:1")
+	if status, _, _ := runCLI(t, "", "search", "residual"); status != 1 {
+		t.Errorf("search with %s unreachable: status = %d, want 1", baseURLEnv, status)
+	}
+}
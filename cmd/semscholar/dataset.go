@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// newDatasetDownloadCmd returns the dataset download command, which mirrors a
+// dataset into dir/name, recording the release and a SHA-256 of every file in
+// the manifest there.
+func newDatasetDownloadCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "download release name",
+		Short: "Download the files of a dataset release",
+	}
+	fs := cmd.Flags()
+	dir := fs.String("dir", "data", "directory to download into; the files go in a directory named after the dataset")
+	parallel := fs.Int("parallel", 0, "number of files to download at once (default --concurrency, else 4)")
+	resume := fs.Bool("resume", false, "continue partial downloads and skip files already downloaded")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return usageError("want a release ID, or latest, and a dataset name")
+		}
+		if *parallel == 0 {
+			*parallel = downloadParallelism(4)
+		}
+		if *parallel < 1 {
+			return usageError("--parallel must be at least 1")
+		}
+		client := newClient(datasetsURL)
+		rel, err := client.GetRelease(args[0])
+		if err != nil {
+			return err
+		}
+		meta, err := client.GetDataset(rel.ReleaseID, args[1])
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(*dir, meta.Name)
+		if err := os.MkdirAll(dest, 0o755); err != nil {
+			return err
+		}
+		man, err := readManifest(dest)
+		if err != nil {
+			return err
+		}
+		if man.Release != rel.ReleaseID || !*resume {
+			// Files of another release, or ones not to be trusted, are fetched
+			// afresh.
+			man.Files = make(map[string]fileRecord)
+		}
+		man.Release, man.Dataset = rel.ReleaseID, meta.Name
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		d := &downloader{
+			hc:       downloadClient(),
+			dir:      dest,
+			resume:   *resume,
+			progress: newProgress(os.Stderr, len(meta.Files)),
+		}
+		failed, err := d.fetchAll(ctx, meta.Files, man, *parallel)
+		d.progress.close()
+		if err != nil {
+			return err
+		}
+		if err := man.write(dest); err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d files failed; run again with --resume to retry them", failed, len(meta.Files))
+		}
+		fmt.Fprintf(os.Stderr, "%s %s: %d files in %s\n", man.Release, man.Dataset, len(meta.Files), dest)
+		return nil
+	}
+	return cmd
+}
+
+// fetchAll downloads the files at urls, parallel at a time, skipping those
+// man records as already downloaded, and records the ones fetched in man. It
+// returns the number that failed, each of which is reported as it fails.
+func (d *downloader) fetchAll(ctx context.Context, urls []string, man *manifest, parallel int) (failed int, err error) {
+	var (
+		g  errgroup.Group
+		mu sync.Mutex
+	)
+	g.SetLimit(parallel)
+	for _, u := range urls {
+		name, err := fileName(u)
+		if err != nil {
+			return 0, err
+		}
+		// Downloads already started record their files in man as they end.
+		mu.Lock()
+		rec, ok := man.Files[name]
+		mu.Unlock()
+		if ok && sizeIs(filepath.Join(d.dir, name), rec.Size) {
+			d.progress.finish(name, "already downloaded")
+			continue
+		}
+		g.Go(func() error {
+			rec, err := d.fetch(ctx, u, name)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				delete(man.Files, name)
+				d.progress.finish(name, err.Error())
+				return nil
+			}
+			man.Files[name] = rec
+			d.progress.finish(name, humanBytes(rec.Size)+", checksum ok")
+			return nil
+		})
+	}
+	g.Wait()
+	return failed, nil
+}
+
+// sizeIs reports whether the file at name exists with size bytes.
+func sizeIs(name string, size int64) bool {
+	fi, err := os.Stat(name)
+	return err == nil && fi.Size() == size
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDatasetDownload checks that dataset download mirrors a release's files
+// with a manifest, and with --resume fetches only the files not already
+// downloaded whole.
+func TestDatasetDownload(t *testing.T) {
+	mockAPI(t)
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "papers")
+	download := []string{"dataset", "download", "2024-11-05", "papers", "--dir", dir, "--parallel", "1"}
+
+	status, _, stderr := runCLI(t, "", download...)
+	if status != 0 {
+		t.Fatalf("status = %d, want 0; stderr %q", status, stderr)
+	}
+	if want := "2024-11-05 papers: 2 files in " + dest + "\n"; !strings.HasSuffix(stderr, want) {
+		t.Errorf("stderr = %q, want it to end %q", stderr, want)
+	}
+	for _, name := range []string{"papers-part0.jsonl.gz", "papers-part1.jsonl.gz", manifestName} {
+		if _, err := os.Stat(filepath.Join(dest, name)); err != nil {
+			t.Error(err)
+		}
+	}
+	man, err := readManifest(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if man.Release != "2024-11-05" || man.Dataset != "papers" || len(man.Files) != 2 {
+		t.Errorf("manifest = %+v, want release 2024-11-05 of papers with 2 files", man)
+	}
+
+	if err := os.Truncate(filepath.Join(dest, "papers-part1.jsonl.gz"), 10); err != nil {
+		t.Fatal(err)
+	}
+	status, _, stderr = runCLI(t, "", append(download, "--resume")...)
+	if status != 0 {
+		t.Fatalf("--resume: status = %d, want 0; stderr %q", status, stderr)
+	}
+	lines := strings.Split(stderr, "\n")
+	if lines[0] != "papers-part0.jsonl.gz: already downloaded" || !strings.HasSuffix(lines[1], ", checksum ok") {
+		t.Errorf("--resume: stderr = %q, want part0 kept and part1 fetched again", stderr)
+	}
+
+	status, _, stderr = runCLI(t, "", "dataset", "download", "2000-01-01", "papers", "--dir", dir)
+	if status != 1 || !strings.Contains(stderr, "Release 2000-01-01 not found") {
+		t.Errorf("unknown release: status = %d, stderr %q; want 1 and the API's error", status, stderr)
+	}
+}
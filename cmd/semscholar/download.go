@@ -0,0 +1,298 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// manifestName is the file in a dataset directory recording what was
+// downloaded into it.
+const manifestName = "manifest.json"
+
+// manifest records the release a dataset directory holds and the files
+// downloaded into it, so later runs can skip them and sync can update them.
+type manifest struct {
+	Release string                `json:"release"`
+	Dataset string                `json:"dataset"`
+	Files   map[string]fileRecord `json:"files"`
+}
+
+// fileRecord describes a downloaded file.
+type fileRecord struct {
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// readManifest reads the manifest of dir, returning an empty one if there
+// is none.
+func readManifest(dir string) (*manifest, error) {
+	m := &manifest{Files: make(map[string]fileRecord)}
+	b, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("%s: %v", filepath.Join(dir, manifestName), err)
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]fileRecord)
+	}
+	return m, nil
+}
+
+// write writes m to dir, replacing the manifest there in one step.
+func (m *manifest) write(dir string) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, manifestName+".tmp")
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, manifestName))
+}
+
+// fileName returns the name of the file a dataset file URL points to. The
+// URLs are presigned, so the name is the last element of the path.
+func fileName(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(u.Path)
+	if name == "/" || name == "." {
+		return "", fmt.Errorf("no file name in %s", u.Redacted())
+	}
+	return name, nil
+}
+
+// md5ETag matches an ETag that is the MD5 of the whole file, as S3 gives
+// objects uploaded in one part; multipart ETags end in "-parts" instead.
+var md5ETag = regexp.MustCompile(`^"?([0-9a-f]{32})"?$`)
+
+// downloader fetches dataset files into a directory, resuming partial files
+// and checking what it fetched.
+type downloader struct {
+	hc       semscholar.HTTPClient
+	dir      string
+	resume   bool
+	progress *progress
+}
+
+// fetch downloads rawURL to the file name in d.dir. It writes to name.part
+// and renames that into place only once the content is checked against the
+// server's MD5, when it gives one, and gzip files against their own CRC, so
+// a file under its final name is always complete. With d.resume a part left
+// by an earlier run is continued rather than started over.
+func (d *downloader) fetch(ctx context.Context, rawURL, name string) (fileRecord, error) {
+	dest := filepath.Join(d.dir, name)
+	part := dest + ".part"
+	sum, md := sha256.New(), md5.New()
+	hashes := io.MultiWriter(sum, md)
+
+	var offset int64
+	if d.resume {
+		if f, err := os.Open(part); err == nil {
+			offset, err = io.Copy(hashes, f)
+			f.Close()
+			if err != nil {
+				return fileRecord{}, err
+			}
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fileRecord{}, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := d.hc.Do(req)
+	if err != nil {
+		return fileRecord{}, err
+	}
+	defer resp.Body.Close()
+
+	flag := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The part is already the whole file; it only needs checking.
+		resp.Body = http.NoBody
+	case resp.StatusCode == http.StatusOK:
+		// The server ignored the range, so the file starts over.
+		offset = 0
+		sum.Reset()
+		md.Reset()
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	default:
+		return fileRecord{}, fmt.Errorf("%s", resp.Status)
+	}
+	if resp.ContentLength >= 0 && resp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		d.progress.expect(offset + resp.ContentLength)
+	}
+	d.progress.resumed(offset)
+
+	f, err := os.OpenFile(part, flag, 0o644)
+	if err != nil {
+		return fileRecord{}, err
+	}
+	n, err := io.Copy(io.MultiWriter(f, hashes, d.progress), resp.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fileRecord{}, err
+	}
+	if err := verify(part, md, resp.Header.Get("ETag")); err != nil {
+		// A corrupt part would only be resumed into a corrupt file.
+		os.Remove(part)
+		return fileRecord{}, err
+	}
+	if err := os.Rename(part, dest); err != nil {
+		return fileRecord{}, err
+	}
+	return fileRecord{Size: offset + n, SHA256: hex.EncodeToString(sum.Sum(nil))}, nil
+}
+
+// verify checks the downloaded file at name against the MD5 the server gave
+// as etag, if it is one, and a gzip file against its checksum.
+func verify(name string, md hash.Hash, etag string) error {
+	if m := md5ETag.FindStringSubmatch(etag); m != nil {
+		if got := hex.EncodeToString(md.Sum(nil)); got != m[1] {
+			return fmt.Errorf("checksum mismatch: MD5 %s, server has %s", got, m[1])
+		}
+	}
+	if !strings.HasSuffix(strings.TrimSuffix(name, ".part"), ".gz") {
+		return nil
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("corrupt gzip: %v", err)
+	}
+	if _, err := io.Copy(io.Discard, zr); err != nil {
+		return fmt.Errorf("corrupt gzip: %v", err)
+	}
+	return nil
+}
+
+// progress reports the progress of downloads to a terminal as a bar
+// redrawn in place, and elsewhere as a line per finished file.
+type progress struct {
+	mu           sync.Mutex
+	w            io.Writer
+	tty          bool
+	files, done  int
+	bytes, total int64
+	fetched      int64
+	start, drawn time.Time
+}
+
+func newProgress(w *os.File, files int) *progress {
+	fi, err := w.Stat()
+	return &progress{w: w, tty: err == nil && fi.Mode()&os.ModeCharDevice != 0, files: files, start: time.Now()}
+}
+
+// expect adds n bytes to the total expected.
+func (p *progress) expect(n int64) {
+	p.mu.Lock()
+	p.total += n
+	p.mu.Unlock()
+}
+
+// resumed counts n bytes already on disk as done.
+func (p *progress) resumed(n int64) {
+	p.mu.Lock()
+	p.bytes += n
+	p.mu.Unlock()
+}
+
+// Write counts the bytes of p as downloaded.
+func (p *progress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bytes += int64(len(b))
+	p.fetched += int64(len(b))
+	if p.tty && time.Since(p.drawn) > 100*time.Millisecond {
+		p.draw()
+	}
+	return len(b), nil
+}
+
+// finish reports that the named file is done, or failed with err.
+func (p *progress) finish(name, status string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if p.tty {
+		fmt.Fprint(p.w, "\r\033[K")
+	}
+	fmt.Fprintf(p.w, "%s: %s\n", name, status)
+	if p.tty {
+		p.draw()
+	}
+}
+
+// close ends the bar, leaving the cursor on a fresh line.
+func (p *progress) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tty {
+		fmt.Fprint(p.w, "\r\033[K")
+	}
+}
+
+func (p *progress) draw() {
+	const width = 30
+	filled := 0
+	if p.total > 0 {
+		filled = int(min(p.bytes, p.total) * width / p.total)
+	}
+	rate := float64(p.fetched) / max(time.Since(p.start).Seconds(), 1e-3)
+	fmt.Fprintf(p.w, "\r\033[K[%s%s] %d/%d files  %s of %s  %s/s",
+		strings.Repeat("#", filled), strings.Repeat("-", width-filled),
+		p.done, p.files, humanBytes(p.bytes), humanBytes(p.total), humanBytes(int64(rate)))
+	p.drawn = time.Now()
+}
+
+// humanBytes formats n in binary units, as in "1.5 GiB".
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
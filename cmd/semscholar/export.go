@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/export"
+	"github.com/spf13/cobra"
+)
+
+// exportPaperFields are the fields bibliography entries are written from.
+const exportPaperFields = "title,authors,venue,year,publicationDate,externalIds,url,abstract"
+
+// vaultPaperFields are the fields notes of an Obsidian vault are written
+// from, and vaultReferenceFields those of the references they link to.
+const (
+	vaultPaperFields     = exportPaperFields + ",citationCount,fieldsOfStudy,tldr"
+	vaultReferenceFields = "title,year,publicationDate"
+)
+
+// exportFormats maps each format to the file extensions it is inferred from
+// and the function writing it.
+var exportFormats = map[string]struct {
+	exts  []string
+	write func(io.Writer, []semscholar.Paper) error
+}{
+	"bibtex": {[]string{".bib", ".bibtex"}, export.WriteBibTeX},
+	"ris":    {[]string{".ris"}, export.WriteRIS},
+	"csl":    {[]string{".json", ".csl"}, export.WriteCSL},
+}
+
+func newExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [id ...]",
+		Short: "Write a bibliography file, or an Obsidian vault of notes, of papers by S2 ID, DOI, arXiv ID or URL",
+	}
+	fs := cmd.Flags()
+	idsFile := fs.String("ids-file", "", "file of identifiers, one per line, or - for standard input")
+	format := fs.String("format", "", "bibliography format: bibtex, ris or csl, or obsidian for a vault of Markdown notes (default from the --out extension, else bibtex)")
+	out := fs.StringP("out", "o", "", "file to write (default standard output), or vault directory for --format obsidian")
+	references := fs.Bool("references", false, "with --format obsidian, fetch the references of each paper and link them from its note")
+	overwrite := fs.Bool("overwrite", false, "with --format obsidian, replace notes already in the vault")
+	var tags []string
+	fs.StringArrayVar(&tags, "tag", nil, "with --format obsidian, tag to add to every paper note (repeatable)")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ids := args
+		if *idsFile != "" {
+			fileIDs, err := readIDs(*idsFile)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, fileIDs...)
+		}
+		if len(ids) == 0 {
+			return usageError("no identifiers; give them as arguments or with --ids-file")
+		}
+		if *format == "" {
+			*format = formatForFile(*out)
+		}
+		if err := checkFormat(*format, "bibtex", "ris", "csl", "obsidian"); err != nil {
+			return err
+		}
+		fields := exportPaperFields
+		if *format == "obsidian" {
+			if *out == "" {
+				return usageError("--format obsidian needs the vault directory as --out")
+			}
+			fields = vaultPaperFields
+		}
+
+		resolved := make([]string, len(ids))
+		for i, id := range ids {
+			resolved[i] = paperID(id)
+		}
+		client := newClient(graphURL)
+		papers, err := client.GetPapersBatchAll(context.Background(), resolved, fields)
+		var partial *semscholar.PartialError
+		if err != nil && !errors.As(err, &partial) {
+			return err
+		}
+		// Papers that were not found, or were in a failed batch, are left out and
+		// reported, so one bad line does not cost the whole bibliography.
+		found := papers[:0]
+		missing := 0
+		for i, p := range papers {
+			if p.PaperID == "" {
+				fmt.Fprintf(os.Stderr, "not found: %s\n", ids[i])
+				missing++
+				continue
+			}
+			found = append(found, p)
+		}
+
+		if *format == "obsidian" {
+			opts := export.VaultOptions{Tags: tags, Overwrite: *overwrite}
+			if *references {
+				if opts.References, err = fetchReferences(client, found); err != nil {
+					return err
+				}
+			}
+			res, err := export.WriteVault(*out, found, opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "wrote %d paper and %d author notes to %s", res.Papers, res.Authors, *out)
+			if res.Kept > 0 {
+				fmt.Fprintf(os.Stderr, ", keeping %d already there", res.Kept)
+			}
+			fmt.Fprintln(os.Stderr)
+		} else if err := writeBibliography(*out, *format, found); err != nil {
+			return err
+		} else if *out != "" {
+			fmt.Fprintf(os.Stderr, "wrote %d of %d papers to %s\n", len(found), len(ids), *out)
+		}
+		if partial != nil {
+			return partial
+		}
+		if missing > 0 {
+			return fmt.Errorf("%d of %d identifiers not found", missing, len(ids))
+		}
+		return nil
+	}
+	return cmd
+}
+
+// fetchReferences returns the papers each of papers cites, by paper ID.
+func fetchReferences(client *semscholar.Client, papers []semscholar.Paper) (map[string][]semscholar.Paper, error) {
+	refs := make(map[string][]semscholar.Paper, len(papers))
+	for _, p := range papers {
+		for c, err := range client.GetPaperReferencesSeq(context.Background(), p.PaperID, vaultReferenceFields) {
+			if err != nil {
+				return nil, fmt.Errorf("references of %s: %w", p.PaperID, err)
+			}
+			if c.CitedPaper.PaperID != "" || c.CitedPaper.Title != "" {
+				refs[p.PaperID] = append(refs[p.PaperID], c.CitedPaper)
+			}
+		}
+	}
+	return refs, nil
+}
+
+// writeBibliography writes papers in format to the named file, or standard
+// output.
+func writeBibliography(name, format string, papers []semscholar.Paper) error {
+	w := io.Writer(os.Stdout)
+	if name != "" {
+		f, err := os.Create(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	if err := exportFormats[format].write(bw, papers); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// readIDs reads the identifiers in the named file, one per line, skipping
+// blank lines and those starting with #.
+func readIDs(name string) ([]string, error) {
+	r := io.Reader(os.Stdin)
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var ids []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			ids = append(ids, line)
+		}
+	}
+	return ids, sc.Err()
+}
+
+// formatForFile returns the format the extension of name suggests.
+func formatForFile(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	for format, f := range exportFormats {
+		for _, e := range f.exts {
+			if e == ext {
+				return format
+			}
+		}
+	}
+	return "bibtex"
+}
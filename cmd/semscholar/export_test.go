@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestExport checks that export writes the papers it finds in the format
+// asked for, or that of the --out extension, and reports those not found.
+func TestExport(t *testing.T) {
+	mockAPI(t)
+	dir := t.TempDir()
+
+	status, stdout, stderr := runCLI(t, "", "export", "arxiv:1810.04805", "--format", "ris")
+	if status != 0 {
+		t.Fatalf("export --format ris: status = %d, want 0; stderr %q", status, stderr)
+	}
+	if !strings.HasPrefix(stdout, "TY  - JOUR\nID  - devlin2019bert\n") || !strings.HasSuffix(stdout, "ER  - \n\n") {
+		t.Errorf("export --format ris: stdout = %q, want an RIS record", stdout)
+	}
+
+	// Identifiers are read from standard input too, and those not found left
+	// out of the file.
+	name := filepath.Join(dir, "refs.json")
+	status, stdout, stderr = runCLI(t, "1512.03385\nDOI:10.0000/none\n", "export", "arxiv:1810.04805", "--ids-file", "-", "-o", name)
+	if status != 1 || stdout != "" {
+		t.Errorf("export -o: status = %d, stdout %q; want 1 and nothing", status, stdout)
+	}
+	want := "not found: DOI:10.0000/none\nwrote 2 of 3 papers to " + name + "\nsemscholar export: 1 of 3 identifiers not found\n"
+	if stderr != want {
+		t.Errorf("export -o: stderr = %q, want %q", stderr, want)
+	}
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); !strings.HasPrefix(s, "[\n") || !strings.Contains(s, `"id": "devlin2019bert"`) || !strings.Contains(s, `"id": "he2015deep"`) {
+		t.Errorf("%s = %q, want CSL JSON of both papers", name, s)
+	}
+}
+
+// TestExportVault checks that export --format obsidian writes a note for
+// each paper and author.
+func TestExportVault(t *testing.T) {
+	mockAPI(t)
+	vault := filepath.Join(t.TempDir(), "vault")
+	status, _, stderr := runCLI(t, "", "export", "1512.03385", "--format", "obsidian", "-o", vault, "--tag", "to-read")
+	if status != 0 {
+		t.Fatalf("status = %d, want 0; stderr %q", status, stderr)
+	}
+	if want := "wrote 1 paper and 2 author notes to " + vault + "\n"; stderr != want {
+		t.Errorf("stderr = %q, want %q", stderr, want)
+	}
+	notes, err := filepath.Glob(filepath.Join(vault, "*", "*.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 3 {
+		t.Errorf("notes %q, want 3", notes)
+	}
+
+	status, _, stderr = runCLI(t, "", "export", "1512.03385", "--format", "obsidian")
+	if status != 2 || !strings.Contains(stderr, "--format obsidian needs the vault directory as --out") {
+		t.Errorf("without --out: status = %d, stderr %q; want 2 and a usage error", status, stderr)
+	}
+}
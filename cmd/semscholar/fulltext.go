@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/s2orc"
+	"github.com/spf13/cobra"
+)
+
+// newFulltextCmd returns the fulltext command, which looks up papers in the
+// s2orc dataset files downloaded into --dir and prints their text as JSON
+// lines: a line per section, or with --format chunks a line per chunk of at
+// most --max characters, each with the paper's ID. Papers are found by corpus
+// ID, resolved through the Graph API unless given as CorpusId:N.
+func newFulltextCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fulltext id ...",
+		Short: "Print the full text of papers from a downloaded s2orc dataset, as sections or chunks",
+	}
+	fs := cmd.Flags()
+	dir := fs.String("dir", filepath.Join("data", s2orc.DatasetName), "directory of the dataset files, as dataset download writes them")
+	format := fs.String("format", "sections", "output format: sections or chunks")
+	maxChars := fs.Int("max", 2000, "most characters of a chunk")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return usageError("no identifiers")
+		}
+		if err := checkFormat(*format, "sections", "chunks"); err != nil {
+			return err
+		}
+		if *maxChars < 1 {
+			return usageError("--max must be at least 1")
+		}
+		paths, err := datasetFiles(*dir)
+		if err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		corpusIDs, err := resolveCorpusIDs(ctx, args)
+		if err != nil {
+			return err
+		}
+		var want []int
+		for _, id := range corpusIDs {
+			if id != 0 {
+				want = append(want, id)
+			}
+		}
+		if len(want) == 0 {
+			return errors.New("none of the papers was found")
+		}
+		records, err := s2orc.Find(ctx, paths, want...)
+		if err != nil && !errors.Is(err, s2orc.ErrNotFound) {
+			return err
+		}
+
+		w := bufio.NewWriter(os.Stdout)
+		enc := json.NewEncoder(w)
+		for i, id := range corpusIDs {
+			rec := records[id]
+			if rec == nil {
+				fmt.Fprintf(os.Stderr, "no full text: %s\n", args[i])
+				continue
+			}
+			sections, err := rec.Sections()
+			if err != nil {
+				return err
+			}
+			if *format == "sections" {
+				for _, s := range sections {
+					if err := enc.Encode(struct {
+						ID string `json:"id"`
+						s2orc.Section
+					}{args[i], s}); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			for _, c := range s2orc.Chunks(sections, *maxChars) {
+				if err := enc.Encode(struct {
+					ID string `json:"id"`
+					s2orc.Chunk
+				}{args[i], c}); err != nil {
+					return err
+				}
+			}
+		}
+		return w.Flush()
+	}
+	return cmd
+}
+
+// datasetFiles returns the dataset files in dir, leaving out the manifest
+// and partial downloads.
+func datasetFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == manifestName || strings.HasSuffix(e.Name(), ".part") {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, e.Name()))
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("%s: no dataset files", dir)
+	}
+	return paths, nil
+}
+
+// resolveCorpusIDs returns the corpus ID of each paper of ids, or 0 for
+// those not found. IDs of the form CorpusId:N are taken as they are.
+func resolveCorpusIDs(ctx context.Context, ids []string) ([]int, error) {
+	corpusIDs := make([]int, len(ids))
+	var lookup []string
+	var at []int
+	for i, id := range ids {
+		if n, ok := strings.CutPrefix(id, "CorpusId:"); ok {
+			if c, err := strconv.Atoi(n); err == nil {
+				corpusIDs[i] = c
+				continue
+			}
+		}
+		lookup = append(lookup, paperID(id))
+		at = append(at, i)
+	}
+	if len(lookup) == 0 {
+		return corpusIDs, nil
+	}
+	papers, err := newClient(graphURL).GetPapersBatchAll(ctx, lookup, "corpusId")
+	var partial *semscholar.PartialError
+	if err != nil && !errors.As(err, &partial) {
+		return nil, err
+	}
+	for j, p := range papers {
+		if p.PaperID == "" {
+			fmt.Fprintf(os.Stderr, "not found: %s\n", ids[at[j]])
+			continue
+		}
+		corpusIDs[at[j]] = p.CorpusID
+	}
+	return corpusIDs, nil
+}
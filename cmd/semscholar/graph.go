@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/jmwalsh91/semscholar-go/graph"
+	"github.com/spf13/cobra"
+)
+
+// graphFormats maps the file extensions a graph format is inferred from to
+// the format.
+var graphFormats = map[string]string{
+	".graphml": "graphml",
+	".dot":     "dot",
+	".gv":      "dot",
+	".gexf":    "gexf",
+	".gz":      "save",
+}
+
+var crawlDirections = map[string]graph.Direction{
+	"both":       graph.Both,
+	"citations":  graph.Citations,
+	"references": graph.References,
+}
+
+// newGraphCrawlCmd returns the graph crawl command, which crawls from the seeds
+// and writes the graph. Interrupting the crawl writes the graph crawled so far.
+func newGraphCrawlCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "crawl [id ...]",
+		Short: "Crawl the citation graph around papers and write it as GraphML, DOT or GEXF",
+	}
+	fs := cmd.Flags()
+	var seeds []string
+	fs.StringArrayVar(&seeds, "seed", nil, "paper to start from, by S2 ID, DOI, arXiv ID or URL; repeatable")
+	depth := fs.Int("depth", 1, "number of hops from the seeds")
+	direction := fs.String("direction", "both", "edges to follow: citations, references or both")
+	maxNodes := fs.Int("max-nodes", graph.DefaultMaxNodes, "most papers in the graph")
+	maxEdges := fs.Int("max-edges", 100, "most citations and references fetched per paper and direction")
+	format := fs.String("format", "", "output format: graphml, dot, gexf or save, the gzip JSON graph.Load reads (default from the --out extension, else graphml)")
+	out := fs.StringP("out", "o", "", "file to write (default standard output)")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		seeds = append(seeds, args...)
+		if len(seeds) == 0 {
+			return usageError("no seeds; give them with --seed or as arguments")
+		}
+		dir, ok := crawlDirections[*direction]
+		if !ok {
+			return usageError(fmt.Sprintf("unknown direction %q; want citations, references or both", *direction))
+		}
+		if *format == "" {
+			*format = cmp.Or(graphFormats[strings.ToLower(filepath.Ext(*out))], "graphml")
+		}
+		if err := checkFormat(*format, "graphml", "dot", "gexf", "save"); err != nil {
+			return err
+		}
+		ids := make([]string, len(seeds))
+		for i, s := range seeds {
+			ids[i] = paperID(s)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		g, err := graph.Crawl(ctx, newClient(graphURL), ids, *depth, graph.Options{
+			Direction:       dir,
+			MaxNodes:        *maxNodes,
+			MaxEdgesPerNode: *maxEdges,
+		})
+		switch {
+		case errors.Is(err, context.Canceled):
+			fmt.Fprintln(os.Stderr, "interrupted; writing the graph crawled so far")
+		case err != nil:
+			return err
+		}
+		if g.NumNodes() == 0 {
+			return fmt.Errorf("none of the seeds were found")
+		}
+
+		if *format == "save" {
+			if *out == "" {
+				return g.Save(os.Stdout)
+			}
+			if err := g.SaveFile(*out); err != nil {
+				return err
+			}
+		} else if err := writeGraph(*out, *format, g); err != nil {
+			return err
+		}
+		msg := fmt.Sprintf("%d papers, %d citations", g.NumNodes(), g.NumEdges())
+		if g.Meta.Truncated {
+			msg += fmt.Sprintf("; stopped at --max-nodes %d", *maxNodes)
+		}
+		fmt.Fprintln(os.Stderr, msg)
+		return nil
+	}
+	return cmd
+}
+
+// writeGraph writes g in format to the named file, or standard output.
+func writeGraph(name, format string, g *graph.Graph) error {
+	w := io.Writer(os.Stdout)
+	if name != "" {
+		f, err := os.Create(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	var err error
+	switch format {
+	case "dot":
+		err = graph.WriteDOT(bw, g, graph.DOTOptions{SizeByCitations: true})
+	case "gexf":
+		err = graph.WriteGEXF(bw, g)
+	default:
+		err = graph.WriteGraphML(bw, g)
+	}
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
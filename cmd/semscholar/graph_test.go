@@ -0,0 +1,58 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/graph"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestGraphCrawl checks the graphs graph crawl writes in each format, and
+// the papers and citations it reports.
+func TestGraphCrawl(t *testing.T) {
+	mockAPI(t)
+	bert := semscholartest.BERTPaperID
+
+	status, stdout, stderr := runCLI(t, "", "graph", "crawl", "--seed", "arxiv:1810.04805", "--format", "dot")
+	if status != 0 {
+		t.Fatalf("status = %d, want 0; stderr %q", status, stderr)
+	}
+	if stderr != "4 papers, 3 citations\n" {
+		t.Errorf("stderr = %q, want 4 papers, 3 citations", stderr)
+	}
+	for _, edge := range []string{
+		`"` + semscholartest.GPT3PaperID + `" -> "` + bert + `";`,
+		`"` + bert + `" -> "` + semscholartest.AttentionPaperID + `";`,
+		`"` + bert + `" -> "` + semscholartest.AdamPaperID + `";`,
+	} {
+		if !strings.Contains(stdout, edge) {
+			t.Errorf("stdout = %q, want the edge %s", stdout, edge)
+		}
+	}
+
+	status, stdout, stderr = runCLI(t, "", "graph", "crawl", bert, "--direction", "references", "--format", "gexf")
+	if status != 0 || stderr != "3 papers, 2 citations\n" || !strings.HasPrefix(stdout, "<?xml") || !strings.Contains(stdout, "<gexf") {
+		t.Errorf("--direction references --format gexf: status = %d, stderr %q, stdout %q; want a GEXF graph of 3 papers", status, stderr, stdout)
+	}
+
+	// The format follows the --out extension.
+	name := filepath.Join(t.TempDir(), "bert.graph.gz")
+	status, _, stderr = runCLI(t, "", "graph", "crawl", bert, "--max-nodes", "2", "-o", name)
+	if status != 0 || stderr != "2 papers, 1 citations; stopped at --max-nodes 2\n" {
+		t.Errorf("--max-nodes 2: status = %d, stderr %q; want 0 and the crawl stopped", status, stderr)
+	}
+	g, err := graph.LoadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.NumNodes() != 2 || !g.Meta.Truncated {
+		t.Errorf("saved graph has %d papers, truncated %v; want 2, truncated", g.NumNodes(), g.Meta.Truncated)
+	}
+
+	status, _, stderr = runCLI(t, "", "graph", "crawl", "arxiv:0000.00000")
+	if status != 1 || !strings.Contains(stderr, "none of the seeds were found") {
+		t.Errorf("unknown seed: status = %d, stderr %q; want 1 and none found", status, stderr)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+var (
+	arxivPattern    = regexp.MustCompile(`(?i)^(?:arxiv:|https?://arxiv\.org/(?:abs|pdf)/)?(\d{4}\.\d{4,5}|[a-z-]+(?:\.[a-z]{2})?/\d{7})(?:v\d+)?(?:\.pdf)?$`)
+	s2URLPattern    = regexp.MustCompile(`^https?://(?:www\.)?semanticscholar\.org/paper/(?:[^/]+/)?([0-9a-f]{40})$`)
+	s2PaperIDFormat = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	s2AuthorURL     = regexp.MustCompile(`^https?://(?:www\.)?semanticscholar\.org/author/(?:[^/]+/)?(\d+)$`)
+)
+
+// paperID turns an identifier as a user would paste it into one the API
+// accepts: a DOI, bare or as a doi.org URL, becomes "DOI:...", an arXiv ID or
+// URL becomes "ARXIV:..." without its version, and a Semantic Scholar URL
+// becomes its paper ID. Anything else, such as a paper ID or an ID already
+// prefixed like "CorpusId:215416146", is passed through.
+func paperID(s string) string {
+	s = strings.TrimSpace(s)
+	if s2PaperIDFormat.MatchString(s) {
+		return s
+	}
+	if m := s2URLPattern.FindStringSubmatch(s); m != nil {
+		return m[1]
+	}
+	if m := arxivPattern.FindStringSubmatch(s); m != nil {
+		return "ARXIV:" + m[1]
+	}
+	if doi, err := semscholar.NormalizeDOI(s); err == nil {
+		return "DOI:" + doi
+	}
+	return s
+}
+
+// authorID turns a Semantic Scholar author URL into the author ID, and passes
+// anything else through.
+func authorID(s string) string {
+	s = strings.TrimSpace(s)
+	if m := s2AuthorURL.FindStringSubmatch(s); m != nil {
+		return m[1]
+	}
+	return s
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// library is the local store of reading lists: named lists of papers, kept
+// with the fields they are shown and exported with so that neither needs
+// the API.
+type library struct {
+	path  string
+	Lists map[string][]listEntry `json:"lists"`
+}
+
+// listEntry is a paper on a reading list.
+type listEntry struct {
+	Paper semscholar.Paper `json:"paper"`
+	Added time.Time        `json:"added"`
+}
+
+// libraryPath returns the path of the library: the file of the library
+// setting, or semscholar/library.json in $XDG_DATA_HOME, ~/.local/share by
+// default.
+func libraryPath() (string, error) {
+	if p := cfg.get("library"); p != "" {
+		return p, nil
+	}
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "semscholar", "library.json"), nil
+}
+
+// openLibrary reads the library at path. A missing file is an empty library.
+func openLibrary(path string) (*library, error) {
+	lib := &library{path: path, Lists: make(map[string][]listEntry)}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return lib, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, lib); err != nil {
+		return nil, err
+	}
+	if lib.Lists == nil {
+		lib.Lists = make(map[string][]listEntry)
+	}
+	return lib, nil
+}
+
+// save writes the library to its file, by way of a temporary file so that
+// an interrupted write leaves the old library whole.
+func (lib *library) save() error {
+	b, err := json.MarshalIndent(lib, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(lib.path), 0o755); err != nil {
+		return err
+	}
+	tmp := lib.path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, lib.path)
+}
+
+// find returns the index of the paper in list that id identifies, or -1.
+// id is an S2 ID, DOI, arXiv ID or URL as paperID understands them, or a
+// CorpusId: ID, so papers can be found without asking the API.
+func (lib *library) find(list, id string) int {
+	id = paperID(id)
+	for i, e := range lib.Lists[list] {
+		if matchesID(e.Paper, id) {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchesID reports whether p is the paper id, an ID as paperID returns it,
+// identifies.
+func matchesID(p semscholar.Paper, id string) bool {
+	prefix, value, ok := strings.Cut(id, ":")
+	if !ok {
+		return p.PaperID == id
+	}
+	switch strings.ToUpper(prefix) {
+	case "DOI":
+		return p.ExternalIDs.DOI != "" && strings.EqualFold(p.ExternalIDs.DOI, value)
+	case "ARXIV":
+		return p.ExternalIDs.ArXiv != "" && p.ExternalIDs.ArXiv == value
+	case "CORPUSID":
+		return p.CorpusID != 0 && strconv.Itoa(p.CorpusID) == value
+	}
+	return false
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/export"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// defaultList is the reading list the list commands use without --list.
+const defaultList = "reading"
+
+// listFlag adds the --list flag to fs.
+func listFlag(fs *pflag.FlagSet) *string {
+	return fs.String("list", defaultList, "name of the reading list")
+}
+
+// loadLibrary opens the library the configuration names.
+func loadLibrary() (*library, error) {
+	path, err := libraryPath()
+	if err != nil {
+		return nil, err
+	}
+	return openLibrary(path)
+}
+
+// entries returns the papers of the named list, or an error naming the
+// lists there are if it does not exist.
+func (lib *library) entries(list string) ([]listEntry, error) {
+	entries, ok := lib.Lists[list]
+	if !ok {
+		names := slices.Sorted(maps.Keys(lib.Lists))
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no list %q; add papers with semscholar list add", list)
+		}
+		return nil, fmt.Errorf("no list %q; the lists are %s", list, strings.Join(names, ", "))
+	}
+	return entries, nil
+}
+
+// newListAddCmd returns the list add command, which looks the papers up and
+// adds them to the list, with the fields they are shown and exported with.
+// Papers already on the list are left where they are.
+func newListAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add id ...",
+		Short: "Add papers to a reading list",
+	}
+	fs := cmd.Flags()
+	list := listFlag(fs)
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return usageError("no papers; give their S2 IDs, DOIs, arXiv IDs or URLs")
+		}
+		lib, err := loadLibrary()
+		if err != nil {
+			return err
+		}
+		var ids, inputs []string
+		for _, a := range args {
+			if lib.find(*list, a) >= 0 {
+				fmt.Fprintf(os.Stderr, "already on %s: %s\n", *list, a)
+				continue
+			}
+			ids, inputs = append(ids, paperID(a)), append(inputs, a)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		papers, err := newClient(graphURL).GetPapersBatchAll(context.Background(), ids, exportPaperFields+",corpusId")
+		var partial *semscholar.PartialError
+		if err != nil && !errors.As(err, &partial) {
+			return err
+		}
+		now := time.Now().UTC().Truncate(time.Second)
+		missing := 0
+		for i, p := range papers {
+			switch {
+			case p.PaperID == "":
+				fmt.Fprintf(os.Stderr, "not found: %s\n", inputs[i])
+				missing++
+			case lib.find(*list, p.PaperID) >= 0:
+				// The same paper given twice, by different IDs.
+			default:
+				lib.Lists[*list] = append(lib.Lists[*list], listEntry{Paper: p, Added: now})
+				fmt.Fprintf(os.Stderr, "added to %s: %s\n", *list, truncate(p.Title, 70))
+			}
+		}
+		if err := lib.save(); err != nil {
+			return err
+		}
+		if partial != nil {
+			return partial
+		}
+		if missing > 0 {
+			return fmt.Errorf("%d of %d papers not found", missing, len(ids))
+		}
+		return nil
+	}
+	return cmd
+}
+
+// newListRemoveCmd returns the list remove command, which removes the papers
+// from the list, finding them by the IDs kept with them rather than asking the
+// API. A list left empty is deleted.
+func newListRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove id ...",
+		Short: "Remove papers from a reading list",
+	}
+	fs := cmd.Flags()
+	list := listFlag(fs)
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return usageError("no papers; give their S2 IDs, DOIs, arXiv IDs or URLs")
+		}
+		lib, err := loadLibrary()
+		if err != nil {
+			return err
+		}
+		if _, err := lib.entries(*list); err != nil {
+			return err
+		}
+		missing := 0
+		for _, a := range args {
+			i := lib.find(*list, a)
+			if i < 0 {
+				fmt.Fprintf(os.Stderr, "not on %s: %s\n", *list, a)
+				missing++
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "removed from %s: %s\n", *list, truncate(lib.Lists[*list][i].Paper.Title, 70))
+			lib.Lists[*list] = slices.Delete(lib.Lists[*list], i, i+1)
+		}
+		if len(lib.Lists[*list]) == 0 {
+			delete(lib.Lists, *list)
+		}
+		if err := lib.save(); err != nil {
+			return err
+		}
+		if missing > 0 {
+			return fmt.Errorf("%d of %d papers not on %s", missing, len(args), *list)
+		}
+		return nil
+	}
+	return cmd
+}
+
+// listColumns are the columns reading lists can be shown with: when each
+// paper was added, its citation key, and the columns of papers.
+var listColumns = append([]column[listEntry]{
+	{"added", 0, func(e listEntry) string { return e.Added.Local().Format(time.DateOnly) }},
+	{"key", 0, func(e listEntry) string { return export.CitationKey(e.Paper) }},
+}, paperListColumns()...)
+
+// paperListColumns returns paperColumns as columns of list entries.
+func paperListColumns() []column[listEntry] {
+	cols := make([]column[listEntry], len(paperColumns))
+	for i, c := range paperColumns {
+		cols[i] = column[listEntry]{c.name, c.width, func(e listEntry) string { return c.value(e.Paper) }}
+	}
+	return cols
+}
+
+// listSummary is a reading list as --lists shows it.
+type listSummary struct {
+	Name   string    `json:"name"`
+	Papers int       `json:"papers"`
+	Added  time.Time `json:"lastAdded"`
+}
+
+var listSummaryColumns = []column[listSummary]{
+	{"name", 0, func(s listSummary) string { return s.Name }},
+	{"papers", 0, func(s listSummary) string { return fmt.Sprint(s.Papers) }},
+	{"added", 0, func(s listSummary) string { return s.Added.Local().Format(time.DateOnly) }},
+}
+
+func newListShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the papers of a reading list, oldest first, or with --lists the lists",
+	}
+	fs := cmd.Flags()
+	list := listFlag(fs)
+	lists := fs.Bool("lists", false, "show the reading lists rather than the papers of one")
+	addOutputFlags(fs, recordFormats)
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			return usageError("unexpected arguments; name the list with --list")
+		}
+		lib, err := loadLibrary()
+		if err != nil {
+			return err
+		}
+		if *lists {
+			var summaries []listSummary
+			for _, name := range slices.Sorted(maps.Keys(lib.Lists)) {
+				entries := lib.Lists[name]
+				s := listSummary{Name: name, Papers: len(entries)}
+				for _, e := range entries {
+					if e.Added.After(s.Added) {
+						s.Added = e.Added
+					}
+				}
+				summaries = append(summaries, s)
+			}
+			return writeRecords(summaries, listSummaryColumns, "name,papers,added")
+		}
+		entries, err := lib.entries(*list)
+		if err != nil {
+			return err
+		}
+		return writeRecords(entries, listColumns, "added,key,year,title,authors")
+	}
+	return cmd
+}
+
+func newListExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write a reading list as a bibliography file",
+	}
+	fs := cmd.Flags()
+	list := listFlag(fs)
+	format := fs.String("format", "", "bibliography format: bibtex, ris or csl (default from the --out extension, else bibtex)")
+	out := fs.StringP("out", "o", "", "file to write (default standard output)")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			return usageError("unexpected arguments; name the list with --list")
+		}
+		if *format == "" {
+			*format = formatForFile(*out)
+		}
+		if err := checkFormat(*format, "bibtex", "ris", "csl"); err != nil {
+			return err
+		}
+		lib, err := loadLibrary()
+		if err != nil {
+			return err
+		}
+		entries, err := lib.entries(*list)
+		if err != nil {
+			return err
+		}
+		papers := make([]semscholar.Paper, len(entries))
+		for i, e := range entries {
+			papers[i] = e.Paper
+		}
+		if err := writeBibliography(*out, *format, papers); err != nil {
+			return err
+		}
+		if *out != "" {
+			fmt.Fprintf(os.Stderr, "wrote %d papers to %s\n", len(papers), *out)
+		}
+		return nil
+	}
+	return cmd
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestListCommands checks adding papers to reading lists, showing,
+// exporting and removing them.
+func TestListCommands(t *testing.T) {
+	srv := mockAPI(t)
+	bert, resnet := semscholartest.BERTPaperID, semscholartest.ResNetPaperID
+	steps := []struct {
+		args   []string
+		status int
+		stdout string
+		stderr string
+	}{
+		{
+			[]string{"list", "add", "arxiv:1810.04805", "10.1109/CVPR.2016.90", "arxiv:0000.00000"}, 1, "",
+			"added to reading: BERT: Pre-training of Deep Bidirectional Transformers for Language Un…\n" +
+				"added to reading: Deep Residual Learning for Image Recognition\n" +
+				"not found: arxiv:0000.00000\n" +
+				"semscholar list add: 1 of 3 papers not found\n",
+		},
+		// Papers already on the list are found by any of their IDs.
+		{[]string{"list", "add", bert, "1512.03385"}, 0, "", "already on reading: " + bert + "\nalready on reading: 1512.03385\n"},
+		{[]string{"list", "add", "--list", "vision", resnet}, 0, "", "added to vision: Deep Residual Learning for Image Recognition\n"},
+		{[]string{"list", "show", "--output", "csv", "--columns", "key,id,year"}, 0, "key,id,year\ndevlin2019bert," + bert + ",2019\nhe2015deep," + resnet + ",2015\n", ""},
+		{[]string{"list", "show", "--lists", "--output", "csv", "--columns", "name,papers"}, 0, "name,papers\nreading,2\nvision,1\n", ""},
+		{[]string{"list", "export", "--list", "vision", "--format", "ris"}, 0, "TY  - JOUR\nID  - he2015deep\n", ""},
+		{[]string{"list", "remove", "DOI:10.18653/v1/N19-1423", "1706.03762"}, 1, "", "removed from reading: BERT: Pre-training of Deep Bidirectional Transformers for Language Un…\nnot on reading: 1706.03762\nsemscholar list remove: 1 of 2 papers not on reading\n"},
+		{[]string{"list", "remove", "--list", "vision", resnet}, 0, "", "removed from vision: Deep Residual Learning for Image Recognition\n"},
+		// A list left empty is deleted.
+		{[]string{"list", "show", "--list", "vision"}, 1, "", `semscholar list show: no list "vision"; the lists are reading` + "\n"},
+	}
+	for _, tt := range steps {
+		name := strings.Join(tt.args, " ")
+		before := len(srv.Requests())
+		status, stdout, stderr := runCLI(t, "", tt.args...)
+		if status != tt.status {
+			t.Errorf("%s: status = %d, want %d; stderr %q", name, status, tt.status, stderr)
+		}
+		if !strings.HasPrefix(stdout, tt.stdout) || tt.stdout == "" && stdout != "" {
+			t.Errorf("%s: stdout = %q, want %q", name, stdout, tt.stdout)
+		}
+		if stderr != tt.stderr {
+			t.Errorf("%s: stderr = %q, want %q", name, stderr, tt.stderr)
+		}
+		// Only adding papers asks the API.
+		if n := len(srv.Requests()) - before; n > 0 && tt.args[1] != "add" {
+			t.Errorf("%s made %d requests, want none", name, n)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Command semscholar is a terminal interface to the Semantic Scholar APIs.
+//
+// Usage:
+//
+//	semscholar <command> [flags] [arguments]
+//
+// Run semscholar help for the list of commands, and semscholar <command> -h
+// for the flags of one. The command line is parsed with Cobra: flags take
+// two dashes, as in --limit 5, and some have a one-letter form, as in -o
+// out.bib. Flags may come before or after positional arguments.
+//
+// Commands printing papers or authors print them as a table by default, or
+// in the format of the --output flag: json, jsonl, csv or yaml. The
+// --columns flag chooses the columns of tables and CSV. Both flags may also
+// come before the command name, as in semscholar --output csv search
+// "query".
+//
+// Every command takes the --rps and --concurrency flags, before or after its
+// name, which bound the requests per second and the API requests in flight
+// of all it does, from batch lookups to crawls and downloads. Without them
+// or an API key, requests to Semantic Scholar are held to 1 a second, its
+// limit for unauthenticated use.
+//
+// The API key in SEMSCHOLAR_API_KEY, if set, is sent with every request.
+// SEMSCHOLAR_BASE_URL, if set, replaces the base URLs of all the APIs, as
+// when developing against semscholar mock serve.
+//
+// Settings that apply to every command, such as the API key, the default
+// paper fields, rate and concurrency limits and a cache directory, are kept in
+// ~/.config/semscholar/config.yaml, or the file SEMSCHOLAR_CONFIG names, and
+// managed with semscholar config get and semscholar config set. The
+// environment variables take precedence over the file.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// newRootCmd returns the semscholar command with all its subcommands. The
+// configuration must be loaded first, as it sets the defaults of flags.
+func newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "semscholar",
+		Short: "semscholar is a terminal interface to the Semantic Scholar APIs",
+		Long:  "semscholar is a terminal interface to the Semantic Scholar APIs.",
+		Args:  cobra.ArbitraryArgs,
+		RunE:  unknownCommand,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return checkLimitFlags(cmd.Flags())
+		},
+		SilenceErrors:              true,
+		SilenceUsage:               true,
+		SuggestionsMinimumDistance: 2,
+		CompletionOptions:          cobra.CompletionOptions{DisableDefaultCmd: true},
+	}
+	// The root and the groups run only to report a missing or unknown
+	// command, so their usage shows only how to name one.
+	root.SetUsageTemplate(strings.Replace(root.UsageTemplate(), "{{if .Runnable}}", "{{if and .Runnable (not .HasAvailableSubCommands)}}", 1))
+	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError(err.Error())
+	})
+	addLimitFlags(root.PersistentFlags())
+	root.AddCommand(
+		newSearchCmd(),
+		group("paper", "Look up papers", newPaperGetCmd()),
+		group("author", "Look up authors, their papers and their metrics",
+			newAuthorGetCmd(), newAuthorSearchCmd(), newAuthorPapersCmd(), newAuthorReportCmd()),
+		newPickCmd(),
+		newExportCmd(),
+		group("bib", "Work with BibTeX files", newBibEnrichCmd()),
+		newFulltextCmd(),
+		newResolveCmd(),
+		newPDFCmd(),
+		newTldrCmd(),
+		group("list", "Keep reading lists of papers",
+			newListAddCmd(), newListRemoveCmd(), newListShowCmd(), newListExportCmd()),
+		group("dataset", "Mirror the Semantic Scholar datasets", newDatasetDownloadCmd(), newDatasetSyncCmd()),
+		group("graph", "Build citation graphs", newGraphCrawlCmd()),
+		group("venue", "Report on venues", newVenueStatsCmd()),
+		group("metrics", "Check papers and authors against metric thresholds", newMetricsCheckCmd()),
+		group("config", "Show and change the configuration", newConfigGetCmd(), newConfigSetCmd()),
+		group("mock", "Run a mock of the API", newMockServeCmd()),
+	)
+	return root
+}
+
+// group returns a command named name that only groups the subcommands subs.
+func group(name, short string, subs ...*cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                        name,
+		Short:                      short,
+		Args:                       cobra.ArbitraryArgs,
+		RunE:                       unknownCommand,
+		SuggestionsMinimumDistance: 2,
+	}
+	cmd.AddCommand(subs...)
+	return cmd
+}
+
+// unknownCommand reports that the subcommand of cmd named by args is
+// missing or unknown.
+func unknownCommand(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return usageError("missing command")
+	}
+	msg := fmt.Sprintf("unknown command %q", args[0])
+	if s := cmd.SuggestionsFor(args[0]); len(s) > 0 {
+		msg += fmt.Sprintf("; did you mean %s?", strings.Join(s, " or "))
+	}
+	return usageError(msg)
+}
+
+// usageError is a mistake in the command line; it is reported with the usage
+// of the command and exit status 2.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stderr))
+}
+
+// run runs the command line args and returns the exit status.
+func run(args []string, stderr io.Writer) int {
+	path, err := configPath()
+	if err == nil {
+		cfg, err = loadConfig(path)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "semscholar: reading configuration: %v\n", err)
+		return 1
+	}
+	root := newRootCmd()
+	root.SetArgs(args)
+	root.SetErr(stderr)
+	cmd, err := root.ExecuteC()
+	var uerr usageError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &uerr):
+		fmt.Fprintf(stderr, "%s: %v\n", cmd.CommandPath(), err)
+		fmt.Fprint(stderr, cmd.UsageString())
+		return 2
+	case errors.As(err, new(checkFailed)):
+		fmt.Fprintf(stderr, "%s: %v\n", cmd.CommandPath(), err)
+		return 3
+	}
+	fmt.Fprintf(stderr, "%s: %v\n", cmd.CommandPath(), err)
+	return 1
+}
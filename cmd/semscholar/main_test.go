@@ -0,0 +1,104 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestRun checks the exit status and message of command lines that fail
+// before any request is made.
+func TestRun(t *testing.T) {
+	t.Setenv(configEnv, filepath.Join(t.TempDir(), "config.yaml"))
+	for _, tt := range []struct {
+		args   []string
+		status int
+		want   string
+	}{
+		{nil, 2, "semscholar: missing command"},
+		{[]string{"autor"}, 2, `semscholar: unknown command "autor"; did you mean author?`},
+		{[]string{"author"}, 2, "semscholar author: missing command"},
+		{[]string{"author", "gte", "x"}, 2, `semscholar author: unknown command "gte"; did you mean get?`},
+		{[]string{"tldr", "x", "--chars", "1"}, 2, "semscholar tldr: --chars must be at least 2"},
+		{[]string{"search", "q", "--output", "xml"}, 2, `unknown format "xml"`},
+		{[]string{"--output", "csv", "tldr", "x"}, 2, "semscholar tldr: unknown flag: --output"},
+		{[]string{"--rps", "0", "tldr", "x"}, 2, "semscholar tldr: rps must be a positive number"},
+		{[]string{"bib", "enrich", "-w", "-o", "out.bib", "in.bib"}, 2, "--write and --out are exclusive"},
+		{[]string{"config", "get", "rps"}, 1, "semscholar config get: rps is not set"},
+	} {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			var stderr strings.Builder
+			if status := run(tt.args, &stderr); status != tt.status {
+				t.Errorf("status = %d, want %d", status, tt.status)
+			}
+			if !strings.Contains(stderr.String(), tt.want) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), tt.want)
+			}
+			if tt.status == 2 && !strings.Contains(stderr.String(), "Usage:") {
+				t.Errorf("stderr = %q, want the usage", stderr.String())
+			}
+		})
+	}
+}
+
+// mockAPI serves the mock API with its fixtures for the command lines of t,
+// with a configuration and library of their own.
+func mockAPI(t *testing.T) *semscholartest.Server {
+	t.Helper()
+	srv := semscholartest.NewServer(nil)
+	t.Cleanup(srv.Close)
+	useMock(t, srv)
+	return srv
+}
+
+// useMock points the command lines of t at srv, with a configuration and
+// library of their own.
+func useMock(t *testing.T, srv *semscholartest.Server) {
+	t.Helper()
+	t.Setenv(baseURLEnv, srv.URL)
+	t.Setenv(apiKeyEnv, "")
+	t.Setenv(configEnv, filepath.Join(t.TempDir(), "config.yaml"))
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+}
+
+// runCLI runs the command line args with stdin as standard input, returning
+// the exit status and what was written to standard output and error.
+func runCLI(t *testing.T, stdin string, args ...string) (status int, stdout, stderr string) {
+	t.Helper()
+	dir := t.TempDir()
+	files := make([]*os.File, 3)
+	for i, name := range []string{"stdin", "stdout", "stderr"} {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		files[i] = f
+	}
+	if _, err := io.WriteString(files[0], stdin); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := files[0].Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	saved := []*os.File{os.Stdin, os.Stdout, os.Stderr}
+	os.Stdin, os.Stdout, os.Stderr = files[0], files[1], files[2]
+	// The output flags are kept in a variable, not reset by each run.
+	out = outputOptions{format: "table"}
+	status = run(args, os.Stderr)
+	os.Stdin, os.Stdout, os.Stderr = saved[0], saved[1], saved[2]
+
+	text := make([]string, 2)
+	for i, f := range files[1:] {
+		b, err := os.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		text[i] = string(b)
+	}
+	return status, text[0], text[1]
+}
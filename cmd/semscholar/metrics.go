@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/spf13/cobra"
+)
+
+// checkFailed is the error of a check that ran but whose thresholds were not
+// met; it exits with status 3, apart from the 1 of errors that kept the
+// check from running and the 2 of usage errors.
+type checkFailed string
+
+func (e checkFailed) Error() string { return string(e) }
+
+// metric is a number checked against a threshold.
+type metric[T any] struct {
+	name  string
+	min   *int
+	value func(T) int
+}
+
+// newMetricsCheckCmd returns the metrics check command, which checks every
+// paper and author named against each threshold set, printing a line per check,
+// so that scheduled jobs and CI can alert on metrics as in
+//
+// semscholar metrics check --paper arxiv:1706.03762 --min-citations 1000
+//
+// The exit status is 0 if every check passed, 3 if some did not, and 1 if some
+// paper or author could not be checked.
+func newMetricsCheckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check papers' and authors' metrics against thresholds, failing with exit status 3 if any falls short",
+	}
+	fs := cmd.Flags()
+	var papers, authors []string
+	fs.StringArrayVar(&papers, "paper", nil, "paper to check, by S2 ID, DOI, arXiv ID or URL; repeatable")
+	fs.StringArrayVar(&authors, "author", nil, "author to check, by S2 ID or URL; repeatable")
+	paperMetrics := []metric[semscholar.Paper]{
+		{"citations", fs.Int("min-citations", 0, "fewest citations each paper must have"), func(p semscholar.Paper) int { return p.CitationCount.Int }},
+		{"influential citations", fs.Int("min-influential", 0, "fewest influential citations each paper must have"), func(p semscholar.Paper) int { return p.InfluentialCitationCount.Int }},
+		{"references", fs.Int("min-references", 0, "fewest references each paper must have"), func(p semscholar.Paper) int { return p.ReferenceCount.Int }},
+	}
+	authorMetrics := []metric[semscholar.Author]{
+		{"h-index", fs.Int("min-hindex", 0, "lowest h-index each author must have"), func(a semscholar.Author) int { return a.HIndex.Int }},
+		{"papers", fs.Int("min-papers", 0, "fewest papers each author must have"), func(a semscholar.Author) int { return a.PaperCount.Int }},
+	}
+	quiet := fs.BoolP("quiet", "q", false, "print only the checks that fail")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			return usageError("unexpected arguments; name papers with --paper and authors with --author")
+		}
+		if len(papers) == 0 && len(authors) == 0 {
+			return usageError("nothing to check; name papers with --paper and authors with --author")
+		}
+		set := func(n *int) bool { return *n > 0 }
+		if len(papers) > 0 && !anyMetric(paperMetrics, set) {
+			return usageError("no paper thresholds; set --min-citations, --min-influential or --min-references")
+		}
+		if len(authors) > 0 && !anyMetric(authorMetrics, set) {
+			return usageError("no author thresholds; set --min-hindex or --min-papers")
+		}
+
+		client := newClient(graphURL)
+		c := &checker{quiet: *quiet}
+		if len(papers) > 0 {
+			ids := make([]string, len(papers))
+			for i, p := range papers {
+				ids[i] = paperID(p)
+			}
+			found, err := client.GetPapersBatchAll(context.Background(), ids, "title,citationCount,influentialCitationCount,referenceCount")
+			var partial *semscholar.PartialError
+			if err != nil && !errors.As(err, &partial) {
+				return err
+			}
+			for i, p := range found {
+				if p.PaperID == "" {
+					c.unchecked(papers[i])
+					continue
+				}
+				checkMetrics(c, paperMetrics, p, truncate(p.Title, 60))
+			}
+		}
+		if len(authors) > 0 {
+			ids := make([]string, len(authors))
+			for i, a := range authors {
+				ids[i] = authorID(a)
+			}
+			found, err := client.GetAuthorsBatch(ids, "name,hIndex,paperCount")
+			if err != nil {
+				return err
+			}
+			for i, a := range found {
+				if a.AuthorID == "" {
+					c.unchecked(authors[i])
+					continue
+				}
+				checkMetrics(c, authorMetrics, a, a.Name)
+			}
+		}
+
+		switch {
+		case c.missing > 0:
+			return fmt.Errorf("%d not found", c.missing)
+		case c.failed > 0:
+			return checkFailed(fmt.Sprintf("%d of %d checks failed", c.failed, c.checks))
+		}
+		return nil
+	}
+	return cmd
+}
+
+func anyMetric[T any](metrics []metric[T], f func(*int) bool) bool {
+	for _, m := range metrics {
+		if f(m.min) {
+			return true
+		}
+	}
+	return false
+}
+
+// checker prints the outcome of checks and counts them.
+type checker struct {
+	quiet                   bool
+	checks, failed, missing int
+}
+
+// checkMetrics checks v, called name, against the thresholds of metrics
+// that are set.
+func checkMetrics[T any](c *checker, metrics []metric[T], v T, name string) {
+	for _, m := range metrics {
+		if *m.min <= 0 {
+			continue
+		}
+		c.checks++
+		got := m.value(v)
+		if got >= *m.min {
+			if !c.quiet {
+				fmt.Printf("ok    %s: %s %d >= %d\n", name, m.name, got, *m.min)
+			}
+			continue
+		}
+		c.failed++
+		fmt.Printf("FAIL  %s: %s %d < %d\n", name, m.name, got, *m.min)
+	}
+}
+
+// unchecked reports that id was not found.
+func (c *checker) unchecked(id string) {
+	c.missing++
+	fmt.Fprintf(os.Stderr, "not found: %s\n", id)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestMetricsCheck checks the lines metrics check prints and its exit
+// status: 0 if every check passed, 3 if some failed, 1 if something could
+// not be checked and 2 for usage errors.
+func TestMetricsCheck(t *testing.T) {
+	mockAPI(t)
+	bert := "BERT: Pre-training of Deep Bidirectional Transformers for L…"
+	for _, tt := range []struct {
+		args   []string
+		status int
+		stdout string
+		stderr string
+	}{
+		{
+			[]string{"--paper", "arxiv:1810.04805", "--author", semscholartest.HeAuthorID, "--min-citations", "10", "--min-hindex", "1"},
+			0,
+			"ok    " + bert + ": citations 83213 >= 10\nok    Kaiming He: h-index 1 >= 1\n",
+			"",
+		},
+		{
+			[]string{"--paper", "arxiv:1810.04805", "--min-citations", "100000", "--min-references", "10"},
+			3,
+			"FAIL  " + bert + ": citations 83213 < 100000\nok    " + bert + ": references 63 >= 10\n",
+			"semscholar metrics check: 1 of 2 checks failed\n",
+		},
+		{
+			[]string{"-q", "--paper", "arxiv:1810.04805", "--paper", "1512.03385", "--min-citations", "100000", "--min-influential", "1"},
+			3,
+			"FAIL  " + bert + ": citations 83213 < 100000\n",
+			"semscholar metrics check: 1 of 4 checks failed\n",
+		},
+		{
+			[]string{"--author", semscholartest.HeAuthorID, "--author", semscholartest.VaswaniAuthorID, "--min-papers", "2", "-q"},
+			3,
+			"FAIL  Kaiming He: papers 1 < 2\nFAIL  Ashish Vaswani: papers 1 < 2\n",
+			"semscholar metrics check: 2 of 2 checks failed\n",
+		},
+		// Papers not found fail the check with 1, whether or not the rest pass.
+		{
+			[]string{"--paper", "arxiv:0000.00000", "--paper", "arxiv:1810.04805", "--min-citations", "100000"},
+			1,
+			"FAIL  " + bert + ": citations 83213 < 100000\n",
+			"not found: arxiv:0000.00000\nsemscholar metrics check: 1 not found\n",
+		},
+		{[]string{"--paper", "arxiv:1810.04805"}, 2, "", "semscholar metrics check: no paper thresholds; set --min-citations, --min-influential or --min-references\n"},
+		{[]string{"--min-citations", "1"}, 2, "", "semscholar metrics check: nothing to check"},
+	} {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			status, stdout, stderr := runCLI(t, "", append([]string{"metrics", "check"}, tt.args...)...)
+			if status != tt.status {
+				t.Errorf("status = %d, want %d", status, tt.status)
+			}
+			if stdout != tt.stdout {
+				t.Errorf("stdout = %q, want %q", stdout, tt.stdout)
+			}
+			if !strings.HasPrefix(stderr, tt.stderr) || tt.status != 2 && stderr != tt.stderr {
+				t.Errorf("stderr = %q, want %q", stderr, tt.stderr)
+			}
+		})
+	}
+}
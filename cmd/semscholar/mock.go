@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+	"github.com/spf13/cobra"
+)
+
+// newMockServeCmd returns the mock serve command, which serves the
+// semscholartest mock API until interrupted. Every API is served from the one
+// base URL, so a client needs only that.
+func newMockServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the mock Graph, Recommendations and Datasets APIs on localhost",
+	}
+	fs := cmd.Flags()
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	dataPath := fs.String("data", "", "JSON file of a semscholartest.Dataset to serve instead of the fixtures")
+	latency := fs.Duration("latency", 0, "delay every response by this long")
+	bulkPageSize := fs.Int("bulk-page-size", 1000, "papers per bulk search page")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			return usageError("unexpected arguments")
+		}
+		var data *semscholartest.Dataset
+		if *dataPath != "" {
+			b, err := os.ReadFile(*dataPath)
+			if err != nil {
+				return err
+			}
+			data = semscholartest.NewDataset()
+			if err := json.Unmarshal(b, data); err != nil {
+				return fmt.Errorf("%s: %v", *dataPath, err)
+			}
+		}
+
+		srv, err := semscholartest.Listen(*addr, data)
+		if err != nil {
+			return err
+		}
+		defer srv.Close()
+		srv.SetLatency(*latency)
+		srv.SetBulkPageSize(*bulkPageSize)
+		fmt.Fprintf(os.Stderr, "serving the mock API at %s; use it as the client's base URL, or run\n\n\texport SEMSCHOLAR_BASE_URL=%s\n\nto point the other commands at it\n", srv.URL, srv.URL)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		<-ctx.Done()
+		return nil
+	}
+	return cmd
+}
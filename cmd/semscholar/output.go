@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/spf13/pflag"
+)
+
+// recordFormats are the output formats of every command that prints papers
+// or authors.
+var recordFormats = []string{"table", "json", "jsonl", "csv", "yaml"}
+
+// outputOptions are the --output and --columns flags of commands printing
+// records.
+type outputOptions struct {
+	format  string
+	columns string
+}
+
+// out is the output the commands print records in.
+var out = outputOptions{format: "table"}
+
+// addOutputFlags adds --output, accepting formats, and --columns to fs.
+func addOutputFlags(fs *pflag.FlagSet, formats []string) {
+	fs.Var(formatFlag(formats), "output", "output format: "+strings.Join(formats, ", "))
+	fs.StringVar(&out.columns, "columns", out.columns, "comma-separated columns of table and csv output; --columns help lists them")
+}
+
+// formatFlag is the --output flag, accepting the formats it lists.
+type formatFlag []string
+
+func (f formatFlag) String() string { return out.format }
+
+func (f formatFlag) Set(s string) error {
+	if err := checkFormat(s, f...); err != nil {
+		return err
+	}
+	out.format = s
+	return nil
+}
+
+func (f formatFlag) Type() string { return "format" }
+
+// column is a column of table and csv output: a name and the text of a
+// record's cell. Table cells are cut to width runes, if it is set.
+type column[T any] struct {
+	name  string
+	width int
+	value func(T) string
+}
+
+// paperColumns are the columns papers can be shown with.
+var paperColumns = []column[semscholar.Paper]{
+	{"id", 0, func(p semscholar.Paper) string { return p.PaperID }},
+	{"title", 70, func(p semscholar.Paper) string { return p.Title }},
+	{"authors", 40, func(p semscholar.Paper) string { return authorNames(p.Authors) }},
+	{"year", 0, func(p semscholar.Paper) string { return year(p) }},
+	{"date", 0, func(p semscholar.Paper) string { return p.PublishedDate().String() }},
+	{"venue", 40, func(p semscholar.Paper) string { return p.Venue }},
+	{"cited", 0, func(p semscholar.Paper) string { return p.CitationCount.String() }},
+	{"influential", 0, func(p semscholar.Paper) string { return p.InfluentialCitationCount.String() }},
+	{"references", 0, func(p semscholar.Paper) string { return p.ReferenceCount.String() }},
+	{"fields", 40, func(p semscholar.Paper) string { return strings.Join(p.FieldsOfStudy, "; ") }},
+	{"doi", 0, func(p semscholar.Paper) string { return p.ExternalIDs.DOI }},
+	{"arxiv", 0, func(p semscholar.Paper) string { return p.ExternalIDs.ArXiv }},
+	{"corpusid", 0, func(p semscholar.Paper) string { return strconv.Itoa(p.CorpusID) }},
+	{"url", 0, func(p semscholar.Paper) string { return p.URL }},
+	{"pdf", 0, func(p semscholar.Paper) string { s, _ := p.OpenAccessPdf["url"].(string); return s }},
+	{"abstract", 70, func(p semscholar.Paper) string { return p.Abstract }},
+	{"tldr", 70, func(p semscholar.Paper) string {
+		if p.Tldr == nil {
+			return ""
+		}
+		return p.Tldr.Text
+	}},
+}
+
+// authorColumns are the columns authors can be shown with.
+var authorColumns = []column[semscholar.Author]{
+	{"id", 0, func(a semscholar.Author) string { return a.AuthorID }},
+	{"name", 0, func(a semscholar.Author) string { return a.Name }},
+	{"hindex", 0, func(a semscholar.Author) string { return a.HIndex.String() }},
+	{"papers", 0, func(a semscholar.Author) string { return a.PaperCount.String() }},
+	{"influential", 0, func(a semscholar.Author) string { return a.InfluentialCitationCount.String() }},
+	{"affiliations", 50, func(a semscholar.Author) string { return strings.Join(a.Affiliations, "; ") }},
+	{"url", 0, func(a semscholar.Author) string { return a.URL }},
+}
+
+// selectColumns returns the columns named by --columns, or by defaults if it
+// is empty.
+func selectColumns[T any](all []column[T], defaults string) ([]column[T], error) {
+	names := out.columns
+	if names == "" {
+		names = defaults
+	}
+	var cols []column[T]
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		i := -1
+		for j, c := range all {
+			if c.name == name {
+				i = j
+			}
+		}
+		if i < 0 {
+			avail := make([]string, len(all))
+			for j, c := range all {
+				avail[j] = c.name
+			}
+			msg := fmt.Sprintf("columns are %s", strings.Join(avail, ", "))
+			if name != "help" {
+				msg = fmt.Sprintf("unknown column %q; %s", name, msg)
+			}
+			return nil, usageError(msg)
+		}
+		cols = append(cols, all[i])
+	}
+	return cols, nil
+}
+
+// writeRecords writes records to standard output in the --output format:
+// for table and csv one per row, in the columns chosen, or those of
+// defaults; for json as an array; for jsonl one per line; and for yaml as a
+// list.
+func writeRecords[T any](records []T, all []column[T], defaults string) error {
+	cols, err := selectColumns(all, defaults)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(os.Stdout)
+	switch out.format {
+	case "json":
+		if records == nil {
+			records = []T{}
+		}
+		err = writeJSON(w, records)
+	case "jsonl":
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		for _, r := range records {
+			if err = enc.Encode(r); err != nil {
+				break
+			}
+		}
+	case "yaml":
+		err = writeYAML(w, records)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(columnNames(cols))
+		for _, r := range records {
+			row := make([]string, len(cols))
+			for i, c := range cols {
+				row[i] = c.value(r)
+			}
+			cw.Write(row)
+		}
+		cw.Flush()
+		err = cw.Error()
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(columnNames(cols), "\t")))
+		for _, r := range records {
+			for i, c := range cols {
+				if i > 0 {
+					tw.Write([]byte{'\t'})
+				}
+				tw.Write([]byte(tableCell(c, r)))
+			}
+			tw.Write([]byte{'\n'})
+		}
+		err = tw.Flush()
+	}
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// writeRecord writes one record to standard output in the --output format:
+// for table as a list of the chosen columns that are not empty, one per
+// line; for csv as one row; and otherwise as writeRecords would write it
+// alone, but not in a list.
+func writeRecord[T any](record T, all []column[T], defaults string) error {
+	switch out.format {
+	case "json":
+		return writeJSON(os.Stdout, record)
+	case "jsonl":
+		return writeRecords([]T{record}, all, defaults)
+	case "yaml":
+		return writeYAML(os.Stdout, record)
+	case "csv":
+		return writeRecords([]T{record}, all, defaults)
+	}
+	cols, err := selectColumns(all, defaults)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, c := range cols {
+		v := strings.Join(strings.Fields(c.value(record)), " ")
+		if v == "" || v == "0" {
+			continue
+		}
+		// Long values, such as abstracts, are wrapped rather than cut,
+		// continuing under their first line.
+		v = strings.ReplaceAll(wrap(v, 80), "\n", "\n\t")
+		fmt.Fprintf(tw, "%s\t%s\n", c.name, v)
+	}
+	return tw.Flush()
+}
+
+// reportPage reports on standard error which results of total a page of n
+// from offset holds.
+func reportPage(offset, n, total int) {
+	if n == 0 {
+		fmt.Fprintln(os.Stderr, "no results")
+		return
+	}
+	if out.format == "table" {
+		// A blank line sets the report apart from the table.
+		fmt.Fprintln(os.Stderr)
+	}
+	fmt.Fprintf(os.Stderr, "%d-%d of %d results\n", offset+1, offset+n, total)
+}
+
+func columnNames[T any](cols []column[T]) []string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.name
+	}
+	return names
+}
+
+// tableCell returns the text of r's cell in column c of a table, cut to its
+// width, or "-" if it is empty.
+func tableCell[T any](c column[T], r T) string {
+	v := c.value(r)
+	if c.width > 0 {
+		v = truncate(v, c.width)
+	}
+	if v == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(v, "\t", " ")
+}
+
+// writeJSON writes v to w as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// year returns the year p was published, or "" if it is not known.
+func year(p semscholar.Paper) string {
+	if y := p.PublishedYear(); y != 0 {
+		return strconv.Itoa(y)
+	}
+	return ""
+}
+
+// authorNames lists the names of authors.
+func authorNames(authors []semscholar.Author) string {
+	names := make([]string, len(authors))
+	for i, a := range authors {
+		names[i] = a.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// truncate shortens s to n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:n-1]) + "…"
+}
+
+// wrap breaks s into lines of at most width columns, between words.
+func wrap(s string, width int) string {
+	var b strings.Builder
+	n := 0
+	for _, word := range strings.Fields(s) {
+		if n > 0 && n+1+len([]rune(word)) > width {
+			b.WriteByte('\n')
+			n = 0
+		} else if n > 0 {
+			b.WriteByte(' ')
+			n++
+		}
+		b.WriteString(word)
+		n += len([]rune(word))
+	}
+	return b.String()
+}
+
+// checkFormat returns a usage error unless format is one of allowed.
+func checkFormat(format string, allowed ...string) error {
+	for _, a := range allowed {
+		if format == a {
+			return nil
+		}
+	}
+	return usageError(fmt.Sprintf("unknown format %q; want %s", format, strings.Join(allowed, ", ")))
+}
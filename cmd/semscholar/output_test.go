@@ -0,0 +1,122 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestOutputFormats checks the records search prints in each --output
+// format, and the report of the results on standard error.
+func TestOutputFormats(t *testing.T) {
+	mockAPI(t)
+	resnet, bert := semscholartest.ResNetPaperID, semscholartest.BERTPaperID
+	for _, tt := range []struct {
+		args           []string
+		stdout, stderr string
+	}{
+		{
+			[]string{"--fields", "title,year", "--columns", "id,year,venue"},
+			"ID                                        YEAR  VENUE\n" +
+				resnet + "  2015  -\n" +
+				bert + "  2019  -\n",
+			"\n1-2 of 2 results\n",
+		},
+		{
+			[]string{"--output", "csv", "--fields", "title,year"},
+			"id,year,cited,title,authors\n" +
+				resnet + ",2015,,Deep Residual Learning for Image Recognition,\n" +
+				bert + ",2019,,BERT: Pre-training of Deep Bidirectional Transformers for Language Understanding,\n",
+			"1-2 of 2 results\n",
+		},
+		{
+			[]string{"--output", "json", "--fields", "year"},
+			"[\n  {\n    \"paperId\": \"" + resnet + "\",\n    \"title\": \"\",\n    \"year\": 2015\n  },\n" +
+				"  {\n    \"paperId\": \"" + bert + "\",\n    \"title\": \"\",\n    \"year\": 2019\n  }\n]\n",
+			"1-2 of 2 results\n",
+		},
+		{
+			[]string{"--output", "jsonl", "--fields", "year"},
+			`{"paperId":"` + resnet + `","title":"","year":2015}` + "\n" +
+				`{"paperId":"` + bert + `","title":"","year":2019}` + "\n",
+			"1-2 of 2 results\n",
+		},
+		{
+			[]string{"--output", "yaml", "--fields", "title,year"},
+			"- paperId: " + resnet + "\n  title: Deep Residual Learning for Image Recognition\n  year: 2015\n" +
+				"- paperId: " + bert + "\n  title: 'BERT: Pre-training of Deep Bidirectional Transformers for Language Understanding'\n  year: 2019\n",
+			"1-2 of 2 results\n",
+		},
+		{
+			[]string{"--output", "csv", "--offset", "1", "--limit", "1", "--fields", "year", "--columns", "id"},
+			"id\n" + bert + "\n",
+			"2-2 of 2 results\n",
+		},
+	} {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			status, stdout, stderr := runCLI(t, "", append([]string{"search", "deep"}, tt.args...)...)
+			if status != 0 {
+				t.Fatalf("status = %d, want 0; stderr %q", status, stderr)
+			}
+			if stdout != tt.stdout {
+				t.Errorf("stdout = %q, want %q", stdout, tt.stdout)
+			}
+			if stderr != tt.stderr {
+				t.Errorf("stderr = %q, want %q", stderr, tt.stderr)
+			}
+		})
+	}
+}
+
+// TestOutputNoResults checks that no results are printed as an empty list
+// in JSON, and as only the header in CSV.
+func TestOutputNoResults(t *testing.T) {
+	mockAPI(t)
+	for format, want := range map[string]string{
+		"json":  "[]\n",
+		"jsonl": "",
+		"csv":   "id,year,cited,title,authors\n",
+		"table": "ID  YEAR  CITED  TITLE  AUTHORS\n",
+	} {
+		status, stdout, stderr := runCLI(t, "", "search", "nothing matches this", "--output", format)
+		if status != 0 || stdout != want || stderr != "no results\n" {
+			t.Errorf("--output %s: status = %d, stdout %q, stderr %q; want 0, %q and no results", format, status, stdout, stderr, want)
+		}
+	}
+}
+
+// TestColumns checks that --columns chooses and orders the columns of table
+// and CSV output, lists them for help, and rejects those it does not know,
+// before or after the command name.
+func TestColumns(t *testing.T) {
+	mockAPI(t)
+	for _, tt := range []struct {
+		args   []string
+		status int
+		stdout string
+		stderr string
+	}{
+		{[]string{"search", "residual", "--output", "csv", "--columns", "year, cited ,id"}, 0, "year,cited,id\n2015,168944," + semscholartest.ResNetPaperID + "\n", ""},
+		{[]string{"--columns", "title", "--output", "csv", "search", "residual"}, 0, "title\nDeep Residual Learning for Image Recognition\n", ""},
+		// Table cells are cut to their width, and empty ones marked.
+		{[]string{"search", "bidirectional", "--columns", "venue,pdf"}, 0, "VENUE                                     PDF\nNorth American Chapter of the Associati…  -\n", ""},
+		// JSON has every field, whatever the columns.
+		{[]string{"search", "residual", "--output", "jsonl", "--fields", "year", "--columns", "id"}, 0, `{"paperId":"` + semscholartest.ResNetPaperID + `","title":"","year":2015}` + "\n", ""},
+		{[]string{"author", "search", "he", "--columns", "help"}, 2, "", "semscholar author search: columns are id, name, hindex, papers, influential, affiliations, url\n"},
+		{[]string{"search", "residual", "--columns", "id,colour"}, 2, "", `semscholar search: unknown column "colour"; columns are id, title, authors, year, date, venue, cited,`},
+	} {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			status, stdout, stderr := runCLI(t, "", tt.args...)
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d; stderr %q", status, tt.status, stderr)
+			}
+			if stdout != tt.stdout {
+				t.Errorf("stdout = %q, want %q", stdout, tt.stdout)
+			}
+			if !strings.HasPrefix(stderr, tt.stderr) && !strings.Contains(stderr, "\n"+tt.stderr) {
+				t.Errorf("stderr = %q, want %q", stderr, tt.stderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/export"
+	"github.com/spf13/cobra"
+)
+
+// detailPaperFields are the fields a single paper is shown with.
+const detailPaperFields = "title,authors,venue,year,publicationDate,citationCount,referenceCount,externalIds,url,abstract,fieldsOfStudy,isOpenAccess,openAccessPdf,tldr"
+
+// detailPaperColumns are the columns a single paper is shown with.
+const detailPaperColumns = "id,title,authors,venue,date,cited,references,fields,doi,arxiv,url,pdf,tldr,abstract"
+
+func newPaperGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get id",
+		Short: "Look up a paper by S2 ID, DOI, arXiv ID or URL",
+	}
+	fs := cmd.Flags()
+	fields := fs.String("fields", detailPaperFields, "comma-separated paper fields to request")
+	addOutputFlags(fs, append(recordFormats, "bibtex"))
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return usageError("want one paper ID")
+		}
+		p, err := newClient(graphURL).GetPaper(paperID(args[0]), *fields)
+		if err != nil {
+			return err
+		}
+		if out.format == "bibtex" {
+			return export.WriteBibTeX(os.Stdout, []semscholar.Paper{*p})
+		}
+		return writeRecord(*p, paperColumns, detailPaperColumns)
+	}
+	return cmd
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+	"gopkg.in/yaml.v3"
+)
+
+// TestPaperGet checks the paper get command in each of its output formats.
+func TestPaperGet(t *testing.T) {
+	mockAPI(t)
+	bert := semscholartest.BERTPaperID
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{nil, "id          " + bert + "\ntitle       BERT: Pre-training of Deep Bidirectional Transformers for Language Understanding\n"},
+		{[]string{"--columns", "title,cited,tldr"}, "title  BERT: Pre-training of Deep Bidirectional Transformers for Language Understanding\ncited  83213\n"},
+		{[]string{"--output", "csv", "--columns", "id,year,doi"}, "id,year,doi\n" + bert + ",2019,10.18653/v1/N19-1423\n"},
+		{[]string{"--output", "jsonl", "--fields", "title,year"}, `{"paperId":"` + bert + `","title":"BERT: Pre-training of Deep Bidirectional Transformers for Language Understanding","year":2019}` + "\n"},
+		{[]string{"--output", "bibtex", "--fields", "title,year"}, "@misc{2019bert,\n  title = {{BERT: Pre-training of Deep Bidirectional Transformers for Language Understanding}},\n  year = {2019}\n}\n"},
+	} {
+		t.Run(strings.Join(append([]string{"paper get"}, tt.args...), " "), func(t *testing.T) {
+			status, stdout, stderr := runCLI(t, "", append([]string{"paper", "get", "arxiv:1810.04805"}, tt.args...)...)
+			if status != 0 {
+				t.Fatalf("status = %d, want 0; stderr %q", status, stderr)
+			}
+			if !strings.HasPrefix(stdout, tt.want) {
+				t.Errorf("stdout = %q, want it to start %q", stdout, tt.want)
+			}
+		})
+	}
+
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			status, stdout, stderr := runCLI(t, "", "paper", "get", "DOI:10.18653/v1/N19-1423", "--output", format, "--fields", "title,year")
+			if status != 0 {
+				t.Fatalf("status = %d, want 0; stderr %q", status, stderr)
+			}
+			var p struct {
+				PaperID string `json:"paperId" yaml:"paperId"`
+				Title   string `json:"title" yaml:"title"`
+				Year    int    `json:"year" yaml:"year"`
+			}
+			unmarshal := json.Unmarshal
+			if format == "yaml" {
+				unmarshal = yaml.Unmarshal
+			}
+			if err := unmarshal([]byte(stdout), &p); err != nil {
+				t.Fatalf("stdout %q: %v", stdout, err)
+			}
+			if p.PaperID != bert || p.Year != 2019 || !strings.HasPrefix(p.Title, "BERT") {
+				t.Errorf("paper = %+v, want BERT of 2019", p)
+			}
+		})
+	}
+}
+
+// TestPaperGetNotFound checks that a paper the API does not know fails the
+// command.
+func TestPaperGetNotFound(t *testing.T) {
+	mockAPI(t)
+	status, stdout, stderr := runCLI(t, "", "paper", "get", "arxiv:0000.00000")
+	if status != 1 || stdout != "" {
+		t.Errorf("status = %d, stdout %q; want 1 and nothing", status, stdout)
+	}
+	if !strings.Contains(stderr, "semscholar paper get: ") || !strings.Contains(stderr, "404") {
+		t.Errorf("stderr = %q, want the 404", stderr)
+	}
+}
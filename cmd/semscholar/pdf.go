@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/export"
+	"github.com/jmwalsh91/semscholar-go/pdf"
+	"github.com/spf13/cobra"
+)
+
+// unpaywallURL is the base URL of the Unpaywall API, asked for PDFs of
+// papers Semantic Scholar knows none for.
+const unpaywallURL = "https://api.unpaywall.org/v2"
+
+// pdfPaperFields are the fields PDFs are found and named by.
+const pdfPaperFields = "title,authors,year,publicationDate,externalIds,openAccessPdf"
+
+// newPDFCmd returns the pdf command, which downloads the PDF of each paper to
+// dir/key.pdf, key being the paper's citation key as export writes it, or
+// with --store into a pdf.Store. Either way the PDFs are fetched by a store,
+// several at once with each host paced on its own, retried when hosts are
+// throttling, and checked to be PDFs of a bounded size. Papers without an
+// open-access PDF, or whose PDF fails, are reported and the rest downloaded.
+func newPDFCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pdf id ...",
+		Short: "Download the open-access PDFs of papers, named by citation key or into a corpus store",
+	}
+	fs := cmd.Flags()
+	dir := fs.StringP("out", "o", ".", "directory to write the PDFs to")
+	email := fs.String("unpaywall", "", "email `address` to ask Unpaywall with for PDFs Semantic Scholar lacks; Unpaywall requires one")
+	force := fs.Bool("force", false, "download PDFs already in the directory again")
+	store := fs.String("store", "", "`directory` of a corpus store to download into instead, by checksum with a manifest")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return usageError("no papers; give their S2 IDs, DOIs, arXiv IDs or URLs")
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		ids := make([]string, len(args))
+		for i, a := range args {
+			ids[i] = paperID(a)
+		}
+		papers, err := newClient(graphURL).GetPapersBatchAll(ctx, ids, pdfPaperFields)
+		var partial *semscholar.PartialError
+		if err != nil && !errors.As(err, &partial) {
+			return err
+		}
+		if *store != "" {
+			return downloadToStore(ctx, *store, args, papers, *email, *force)
+		}
+		if err := os.MkdirAll(*dir, 0o755); err != nil {
+			return err
+		}
+		return downloadToDir(ctx, *dir, args, papers, *email, *force)
+	}
+	return cmd
+}
+
+// openStore opens the store in dir, downloading as the command line asks.
+func openStore(dir, email string, force bool) (*pdf.Store, error) {
+	s, err := pdf.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	s.HTTPClient = downloadClient()
+	s.Retry = semscholar.RetryPolicy{MaxRetries: 3}
+	s.Concurrency = downloadParallelism(pdf.DefaultConcurrency)
+	s.Force = force
+	if email != "" {
+		s.UserAgent = "semscholar (mailto:" + email + ")"
+	}
+	return s, nil
+}
+
+// downloadToDir downloads the PDFs of papers, found for args, to
+// dir/key.pdf. They are fetched into a store in a temporary directory in
+// dir and moved out of it under their names.
+func downloadToDir(ctx context.Context, dir string, args []string, papers []semscholar.Paper, email string, force bool) error {
+	tmp, err := os.MkdirTemp(dir, ".pdf-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	s, err := openStore(tmp, email, false)
+	if err != nil {
+		return err
+	}
+	keys := export.NewKeys()
+	var found []semscholar.Paper
+	var names []string
+	failed := 0
+	for i, p := range papers {
+		if p.PaperID == "" {
+			fmt.Fprintf(os.Stderr, "not found: %s\n", args[i])
+			failed++
+			continue
+		}
+		name := filepath.Join(dir, keys.Key(p)+".pdf")
+		if _, err := os.Stat(name); err == nil && !force {
+			fmt.Fprintf(os.Stderr, "%s: already downloaded\n", name)
+			continue
+		}
+		found = append(found, p)
+		names = append(names, name)
+	}
+	results, err := downloadAll(ctx, s, found, email)
+	// Papers with the same PDF share one file in the store, moved to the
+	// first paper's name and copied to the others'.
+	moved := make(map[string]string)
+	for i, r := range results {
+		if r.Err == nil {
+			if first, ok := moved[r.Entry.SHA256]; ok {
+				r.Err = copyFile(first, names[i])
+			} else if r.Err = os.Rename(filepath.Join(tmp, r.Entry.Path), names[i]); r.Err == nil {
+				moved[r.Entry.SHA256] = names[i]
+			}
+		}
+		if r.Err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", names[i], r.Err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s\n", names[i], r.Entry.URL)
+	}
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d PDFs not downloaded", failed, len(papers))
+	}
+	return nil
+}
+
+// downloadToStore downloads the PDFs of papers, found for args, into the
+// store in dir.
+func downloadToStore(ctx context.Context, dir string, args []string, papers []semscholar.Paper, email string, force bool) error {
+	s, err := openStore(dir, email, force)
+	if err != nil {
+		return err
+	}
+	var found []semscholar.Paper
+	var foundArgs []string
+	failed := 0
+	for i, p := range papers {
+		if p.PaperID == "" {
+			fmt.Fprintf(os.Stderr, "not found: %s\n", args[i])
+			failed++
+			continue
+		}
+		found = append(found, p)
+		foundArgs = append(foundArgs, args[i])
+	}
+	results, err := downloadAll(ctx, s, found, email)
+	for i, r := range results {
+		switch {
+		case r.Err != nil:
+			fmt.Fprintf(os.Stderr, "%s: %v\n", foundArgs[i], r.Err)
+			failed++
+		case r.Cached:
+			fmt.Fprintf(os.Stderr, "%s: already in the store\n", foundArgs[i])
+		case r.Duplicate:
+			fmt.Fprintf(os.Stderr, "%s: %s, the same as another paper's\n", foundArgs[i], filepath.Join(dir, r.Entry.Path))
+		default:
+			fmt.Fprintf(os.Stderr, "%s: %s\n", foundArgs[i], filepath.Join(dir, r.Entry.Path))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d PDFs not downloaded", failed, len(papers))
+	}
+	return nil
+}
+
+// downloadAll downloads the PDFs of papers into s and saves its manifest.
+// With an email address, Unpaywall is asked for the PDF of each paper with
+// a DOI whose download failed, as publishers' links often do, or that
+// Semantic Scholar knows no PDF of.
+func downloadAll(ctx context.Context, s *pdf.Store, papers []semscholar.Paper, email string) ([]pdf.Result, error) {
+	results, err := s.DownloadAll(ctx, papers)
+	if err != nil || email == "" {
+		return results, err
+	}
+	hc := downloadClient()
+	for i, r := range results {
+		p := papers[i]
+		if r.Err == nil || p.ExternalIDs.DOI == "" || ctx.Err() != nil {
+			continue
+		}
+		src, _ := p.OpenAccessPdf["url"].(string)
+		alt, err := unpaywall(ctx, hc, email, p.ExternalIDs.DOI)
+		if err != nil || alt == src {
+			// The first failure is the one worth reporting.
+			continue
+		}
+		p.OpenAccessPdf = map[string]any{"url": alt}
+		if ar := s.Download(ctx, p); ar.Err == nil {
+			results[i] = ar
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+	return results, s.Save()
+}
+
+// unpaywall returns the URL of the best PDF Unpaywall knows of for doi.
+func unpaywall(ctx context.Context, hc semscholar.HTTPClient, email, doi string) (string, error) {
+	u := unpaywallURL + "/" + url.PathEscape(doi) + "?email=" + url.QueryEscape(email)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", pdf.ErrNoPDF
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Unpaywall: %s", resp.Status)
+	}
+	type location struct {
+		URLForPDF string `json:"url_for_pdf"`
+	}
+	var record struct {
+		Best      *location  `json:"best_oa_location"`
+		Locations []location `json:"oa_locations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&record); err != nil {
+		return "", fmt.Errorf("Unpaywall: %v", err)
+	}
+	if record.Best != nil && record.Best.URLForPDF != "" {
+		return record.Best.URLForPDF, nil
+	}
+	for _, l := range record.Locations {
+		if l.URLForPDF != "" {
+			return l.URLForPDF, nil
+		}
+	}
+	return "", pdf.ErrNoPDF
+}
+
+// copyFile copies the file src to dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
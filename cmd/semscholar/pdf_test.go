@@ -0,0 +1,118 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/pdf"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// pdfPapers serves two papers, one whose PDF downloads and one whose PDF is
+// gone, and returns their IDs. The PDFs are on hosts of their own, as the
+// downloads to each host are paced.
+func pdfPapers(t *testing.T) (ok, gone string) {
+	t.Helper()
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.4 sparse"))
+	}))
+	t.Cleanup(files.Close)
+	lost := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(lost.Close)
+	ok, gone = strings.Repeat("a", 40), strings.Repeat("b", 40)
+	d := semscholartest.NewDataset()
+	d.AddPaper(semscholar.Paper{
+		PaperID:       ok,
+		Title:         "Sparse Attention",
+		Year:          2020,
+		Authors:       []semscholar.Author{{Name: "Ada Lovelace"}},
+		OpenAccessPdf: map[string]any{"url": files.URL + "/sparse.pdf"},
+	})
+	d.AddPaper(semscholar.Paper{
+		PaperID:       gone,
+		Title:         "Lost Results",
+		Year:          2021,
+		Authors:       []semscholar.Author{{Name: "Alan Turing"}},
+		OpenAccessPdf: map[string]any{"url": lost.URL + "/lost.pdf"},
+	})
+	srv := semscholartest.NewServer(d)
+	t.Cleanup(srv.Close)
+	useMock(t, srv)
+	return ok, gone
+}
+
+// TestPDF checks that pdf downloads each paper's PDF named by its citation
+// key, reporting those it cannot, and skips those already downloaded.
+func TestPDF(t *testing.T) {
+	ok, gone := pdfPapers(t)
+	dir := filepath.Join(t.TempDir(), "pdfs")
+	name := filepath.Join(dir, "lovelace2020sparse.pdf")
+
+	status, _, stderr := runCLI(t, "", "pdf", ok, gone, strings.Repeat("c", 40), "-o", dir)
+	if status != 1 {
+		t.Errorf("status = %d, want 1", status)
+	}
+	for _, want := range []string{
+		"not found: " + strings.Repeat("c", 40) + "\n",
+		name + ": http://",
+		filepath.Join(dir, "turing2021lost.pdf") + ": ",
+		"semscholar pdf: 2 of 3 PDFs not downloaded\n",
+	} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("stderr = %q, want it to report %q", stderr, want)
+		}
+	}
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "%PDF-1.4 sparse" {
+		t.Errorf("%s = %q, want the PDF served", name, b)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("%s holds %d files, want only the PDF", dir, len(entries))
+	}
+
+	status, _, stderr = runCLI(t, "", "pdf", ok, "-o", dir)
+	if want := name + ": already downloaded\n"; status != 0 || stderr != want {
+		t.Errorf("again: status = %d, stderr %q; want 0 and %q", status, stderr, want)
+	}
+}
+
+// TestPDFStore checks that pdf --store downloads into a store, recording
+// the PDF in its manifest.
+func TestPDFStore(t *testing.T) {
+	ok, _ := pdfPapers(t)
+	dir := t.TempDir()
+
+	status, _, stderr := runCLI(t, "", "pdf", ok, "--store", dir)
+	if status != 0 {
+		t.Fatalf("status = %d, want 0; stderr %q", status, stderr)
+	}
+	s, err := pdf.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, found := s.Lookup(ok)
+	if !found {
+		t.Fatalf("store has no entry for %s", ok)
+	}
+	if want := ok + ": " + filepath.Join(dir, e.Path) + "\n"; stderr != want {
+		t.Errorf("stderr = %q, want %q", stderr, want)
+	}
+
+	status, _, stderr = runCLI(t, "", "pdf", ok, "--store", dir)
+	if want := ok + ": already in the store\n"; status != 0 || stderr != want {
+		t.Errorf("again: status = %d, stderr %q; want 0 and %q", status, stderr, want)
+	}
+}
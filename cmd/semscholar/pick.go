@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/spf13/cobra"
+)
+
+// errCancelled is returned when the user leaves the picker without picking.
+var errCancelled = errors.New("cancelled")
+
+// newPickCmd returns the pick command, which shows papers matching what the
+// user types, as the API completes it, and prints the ID of the one picked, so
+// it composes with other commands as in semscholar paper get "$(semscholar pick
+// attention)". The picker is drawn on the terminal rather than standard output,
+// which carries only the ID.
+func newPickCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pick [partial title]",
+		Short: "Pick a paper interactively by title and print its ID",
+	}
+	fs := cmd.Flags()
+	first := fs.Bool("first", false, "print the best match without asking")
+	height := fs.IntP("height", "n", 10, "number of matches shown")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		query := strings.Join(args, " ")
+		client := newClient(graphURL)
+
+		tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+		if *first || err != nil {
+			// Without a terminal to ask on, the best match is the pick.
+			if query == "" {
+				return usageError("missing title")
+			}
+			papers, err := client.AutocompletePaper(query)
+			if err != nil {
+				return err
+			}
+			// The API may match on more than the title's letters, so its own
+			// order stands when no title matches them.
+			if ranked := rankMatches(query, papers); len(ranked) > 0 {
+				papers = ranked
+			}
+			if len(papers) == 0 {
+				return fmt.Errorf("no paper matches %q", query)
+			}
+			fmt.Println(papers[0].PaperID)
+			return nil
+		}
+		defer tty.Close()
+
+		restore, err := rawMode(tty)
+		if err != nil {
+			return err
+		}
+		defer restore()
+		p := &picker{client: client, tty: tty, query: []rune(query), height: max(*height, 1), width: termWidth(tty)}
+		id, err := p.run()
+		if err != nil {
+			return err
+		}
+		fmt.Println(id)
+		return nil
+	}
+	return cmd
+}
+
+// rawMode puts the terminal into raw mode, so keys arrive as they are
+// pressed and unechoed, and returns a function restoring its previous mode.
+func rawMode(tty *os.File) (restore func(), err error) {
+	stty := func(args ...string) ([]byte, error) {
+		cmd := exec.Command("stty", args...)
+		cmd.Stdin = tty
+		return cmd.Output()
+	}
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("setting up the terminal: %v", err)
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, fmt.Errorf("setting up the terminal: %v", err)
+	}
+	return func() { stty(strings.TrimSpace(string(saved))) }, nil
+}
+
+// termWidth returns the width of the terminal, or 80 if it is unknown.
+func termWidth(tty *os.File) int {
+	cmd := exec.Command("stty", "size")
+	cmd.Stdin = tty
+	b, err := cmd.Output()
+	var rows, cols int
+	if err != nil || len(b) == 0 {
+		return 80
+	}
+	if _, err := fmt.Sscan(string(b), &rows, &cols); err != nil || cols < 20 {
+		return 80
+	}
+	return cols
+}
+
+// picker is the state of the interactive picker.
+type picker struct {
+	client *semscholar.Client
+	tty    *os.File
+	query  []rune
+	height int
+	width  int
+
+	// seen holds every paper the API has suggested, so earlier suggestions
+	// still match as the query is refined.
+	seen    []semscholar.Paper
+	matches []semscholar.Paper
+	cursor  int
+}
+
+// completion is the API's answer to a query.
+type completion struct {
+	papers []semscholar.Paper
+	err    error
+}
+
+// pickDelay is how long typing must pause before the API is asked.
+const pickDelay = 150 * time.Millisecond
+
+func (p *picker) run() (string, error) {
+	keys := make(chan []byte)
+	go func() {
+		r := bufio.NewReader(p.tty)
+		buf := make([]byte, 64)
+		for {
+			n, err := r.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- append([]byte(nil), buf[:n]...)
+		}
+	}()
+	results := make(chan completion, 1)
+	timer := time.NewTimer(0)
+	var status string
+	defer p.clear()
+
+	for {
+		p.draw(status)
+		select {
+		case b, ok := <-keys:
+			if !ok {
+				return "", errCancelled
+			}
+			before := string(p.query)
+			if id, done, err := p.key(b); done {
+				return id, err
+			}
+			if string(p.query) != before {
+				p.rank()
+				timer.Reset(pickDelay)
+			}
+		case <-timer.C:
+			q := string(p.query)
+			if strings.TrimSpace(q) == "" {
+				continue
+			}
+			status = "…"
+			go func() {
+				papers, err := p.client.AutocompletePaper(q)
+				results <- completion{papers, err}
+			}()
+		case c := <-results:
+			status = ""
+			if c.err != nil {
+				status = c.err.Error()
+			}
+			for _, paper := range c.papers {
+				if !slices.ContainsFunc(p.seen, func(s semscholar.Paper) bool { return s.PaperID == paper.PaperID }) {
+					p.seen = append(p.seen, paper)
+				}
+			}
+			p.rank()
+		}
+	}
+}
+
+// key handles the keys in b. It reports whether the picker is done, and
+// with which paper ID or error.
+func (p *picker) key(b []byte) (id string, done bool, err error) {
+	switch {
+	case string(b) == "\x1b[A" || string(b) == "\x1bOA" || b[0] == 0x10 || b[0] == 0x0b: // up, ^P, ^K
+		p.cursor = max(p.cursor-1, 0)
+	case string(b) == "\x1b[B" || string(b) == "\x1bOB" || b[0] == 0x0e || b[0] == 0x0a: // down, ^N, ^J
+		p.cursor = min(p.cursor+1, max(len(p.matches)-1, 0))
+	case b[0] == '\r':
+		if len(p.matches) == 0 {
+			return "", false, nil
+		}
+		return p.matches[p.cursor].PaperID, true, nil
+	case string(b) == "\x1b" || b[0] == 0x03 || b[0] == 0x04 && len(p.query) == 0: // Esc, ^C, ^D
+		return "", true, errCancelled
+	case b[0] == 0x7f || b[0] == 0x08: // backspace
+		if len(p.query) > 0 {
+			p.query = p.query[:len(p.query)-1]
+		}
+	case b[0] == 0x15: // ^U
+		p.query = p.query[:0]
+	case b[0] == 0x17: // ^W
+		q := strings.TrimRightFunc(string(p.query), unicode.IsSpace)
+		i := strings.LastIndexFunc(q, unicode.IsSpace)
+		p.query = []rune(q[:i+1])
+	case b[0] >= 0x20 && utf8.Valid(b):
+		p.query = append(p.query, []rune(string(b))...)
+	}
+	return "", false, nil
+}
+
+// rank orders the papers seen by how well they match the query.
+func (p *picker) rank() {
+	p.matches = rankMatches(string(p.query), p.seen)
+	p.cursor = min(p.cursor, max(len(p.matches)-1, 0))
+}
+
+// rankMatches returns the papers whose titles hold the letters of query in
+// order, best matches first: those with the letters together and at the
+// starts of words. Papers matching equally keep their order.
+func rankMatches(query string, papers []semscholar.Paper) []semscholar.Paper {
+	type scored struct {
+		paper semscholar.Paper
+		score int
+	}
+	var ranked []scored
+	for _, paper := range papers {
+		if s, ok := fuzzyScore(query, paper.Title); ok {
+			ranked = append(ranked, scored{paper, s})
+		}
+	}
+	slices.SortStableFunc(ranked, func(a, b scored) int { return b.score - a.score })
+	out := make([]semscholar.Paper, len(ranked))
+	for i, r := range ranked {
+		out[i] = r.paper
+	}
+	return out
+}
+
+// fuzzyScore reports whether the non-space letters of query appear in s in
+// order, ignoring case, and scores the match: a point for each letter,
+// more for letters following the last matched one or starting a word.
+func fuzzyScore(query, s string) (int, bool) {
+	text := []rune(strings.ToLower(s))
+	score, i, last := 0, 0, -2
+	for _, q := range strings.ToLower(query) {
+		if unicode.IsSpace(q) {
+			continue
+		}
+		for i < len(text) && text[i] != q {
+			i++
+		}
+		if i == len(text) {
+			return 0, false
+		}
+		score++
+		if i == last+1 {
+			score += 3
+		}
+		if i == 0 || !unicode.IsLetter(text[i-1]) && !unicode.IsDigit(text[i-1]) {
+			score += 2
+		}
+		last = i
+		i++
+	}
+	return score, true
+}
+
+// draw redraws the prompt and matches in place, leaving the cursor after
+// the query.
+func (p *picker) draw(status string) {
+	var b strings.Builder
+	b.WriteString("\r\x1b[J> ")
+	b.WriteString(string(p.query))
+	if status != "" {
+		fmt.Fprintf(&b, "  \x1b[2m%s\x1b[0m", truncate(status, p.width/2))
+	}
+	// The matches shown scroll to keep the cursor among them.
+	start := max(p.cursor-p.height+1, 0)
+	end := min(start+p.height, len(p.matches))
+	lines := 0
+	for i := start; i < end; i++ {
+		m := p.matches[i]
+		line := truncate(strings.TrimSpace(m.Title+"  "+year(m)), p.width-3)
+		if i == p.cursor {
+			fmt.Fprintf(&b, "\r\n\x1b[7m> %s\x1b[0m", line)
+		} else {
+			fmt.Fprintf(&b, "\r\n  %s", line)
+		}
+		lines++
+	}
+	if more := len(p.matches) - (end - start); more > 0 {
+		fmt.Fprintf(&b, "\r\n  \x1b[2m%d more\x1b[0m", more)
+		lines++
+	}
+	if lines > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", lines)
+	}
+	fmt.Fprintf(&b, "\r\x1b[%dC", 2+len(p.query))
+	io.WriteString(p.tty, b.String())
+}
+
+// clear erases the picker from the terminal.
+func (p *picker) clear() {
+	io.WriteString(p.tty, "\r\x1b[J")
+}
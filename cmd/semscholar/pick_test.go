@@ -0,0 +1,106 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestPickFirst checks that pick --first prints the ID of the best match
+// of the papers the API completes the title to.
+func TestPickFirst(t *testing.T) {
+	mockAPI(t)
+	for _, tt := range []struct {
+		args   []string
+		status int
+		stdout string
+		stderr string
+	}{
+		{[]string{"residual"}, 0, semscholartest.ResNetPaperID + "\n", ""},
+		{[]string{"Deep", "Bidirectional"}, 0, semscholartest.BERTPaperID + "\n", ""},
+		{[]string{"zzzz"}, 1, "", `semscholar pick: no paper matches "zzzz"`},
+		{nil, 2, "", "semscholar pick: missing title"},
+	} {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			status, stdout, stderr := runCLI(t, "", append([]string{"pick", "--first"}, tt.args...)...)
+			if status != tt.status || stdout != tt.stdout {
+				t.Errorf("status = %d, stdout %q; want %d, %q", status, stdout, tt.status, tt.stdout)
+			}
+			if !strings.HasPrefix(stderr, tt.stderr) {
+				t.Errorf("stderr = %q, want %q", stderr, tt.stderr)
+			}
+		})
+	}
+}
+
+// TestRankMatches checks that titles holding the letters of the query in
+// order are ranked by how closely, and the rest left out.
+func TestRankMatches(t *testing.T) {
+	papers := []semscholar.Paper{
+		{PaperID: "a", Title: "Adam: A Method for Stochastic Optimization"},
+		{PaperID: "b", Title: "Attention is All you Need"},
+		{PaperID: "c", Title: "Deep Residual Learning for Image Recognition"},
+		{PaperID: "d", Title: "Language Models are Few-Shot Learners"},
+	}
+	for _, tt := range []struct {
+		query string
+		want  []string
+	}{
+		{"att", []string{"b", "a"}},
+		{"ALL you", []string{"b"}},
+		{"dl", []string{"c", "d"}},
+		{"learn", []string{"c", "d"}},
+		{"xyz", nil},
+		{"", []string{"a", "b", "c", "d"}},
+	} {
+		var got []string
+		for _, p := range rankMatches(tt.query, papers) {
+			got = append(got, p.PaperID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("rankMatches(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+// TestPickerKey checks the editing and moving keys of the picker, and the
+// keys ending it.
+func TestPickerKey(t *testing.T) {
+	matches := []semscholar.Paper{{PaperID: "a"}, {PaperID: "b"}, {PaperID: "c"}}
+	for _, tt := range []struct {
+		name   string
+		keys   []string
+		query  string
+		cursor int
+		id     string
+		err    error
+	}{
+		{"typing", []string{"a", "b", " ", "é"}, "deepab é", 0, "", nil},
+		{"backspace", []string{"\x7f", "\x08"}, "de", 0, "", nil},
+		{"^U", []string{"\x15", "x"}, "x", 0, "", nil},
+		{"^W", []string{" ", "\x17"}, "", 0, "", nil},
+		{"down", []string{"\x1b[B", "\x0e", "\x1b[B"}, "deep", 2, "", nil},
+		{"up", []string{"\x1b[B", "\x1b[B", "\x1b[A", "\x10", "\x10"}, "deep", 0, "", nil},
+		{"enter", []string{"\x1b[B", "\r"}, "deep", 1, "b", nil},
+		{"esc", []string{"\x1b"}, "deep", 0, "", errCancelled},
+		{"^C", []string{"\x03"}, "deep", 0, "", errCancelled},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &picker{query: []rune("deep"), matches: matches}
+			var id string
+			var err error
+			for _, k := range tt.keys {
+				var done bool
+				if id, done, err = p.key([]byte(k)); done {
+					break
+				}
+			}
+			if string(p.query) != tt.query || p.cursor != tt.cursor || id != tt.id || err != tt.err {
+				t.Errorf("query %q, cursor %d, picked %q, %v; want %q, %d, %q, %v", string(p.query), p.cursor, id, err, tt.query, tt.cursor, tt.id, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/metrics"
+	"github.com/spf13/cobra"
+)
+
+// authorReport is the report on an author and their papers.
+type authorReport struct {
+	AuthorID     string   `json:"authorId"`
+	Name         string   `json:"name"`
+	Affiliations []string `json:"affiliations,omitempty"`
+	URL          string   `json:"url,omitempty"`
+	Papers       int      `json:"papers"`
+	Citations    int      `json:"citations"`
+	// HIndex is Semantic Scholar's h-index of the author; I10Index, the
+	// number of papers cited at least 10 times, is counted from the papers.
+	HIndex        int           `json:"hIndex"`
+	I10Index      int           `json:"i10Index"`
+	ByYear        []yearStats   `json:"byYear"`
+	TopPapers     []citedPaper  `json:"topPapers"`
+	TopVenues     []venueCount  `json:"topVenues"`
+	Collaborators []authorStats `json:"collaborators"`
+}
+
+type venueCount struct {
+	Venue     string `json:"venue"`
+	Papers    int    `json:"papers"`
+	Citations int    `json:"citations"`
+}
+
+// reportPaperFields are the fields of the papers a report is made from.
+const reportPaperFields = "title,venue,year,publicationDate,citationCount,authors"
+
+// newAuthorReportCmd returns the author report command, which fetches an author
+// and all their papers and reports on them. Collaborators are co-authors,
+// ranked by the papers they share.
+func newAuthorReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report id",
+		Short: "Report on an author's output, top papers, venues and collaborators, as Markdown or JSON",
+	}
+	fs := cmd.Flags()
+	format := fs.String("format", "markdown", "output format: markdown or json")
+	top := fs.Int("top", 10, "number of top papers, venues and collaborators listed")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return usageError("want one author ID")
+		}
+		if err := checkFormat(*format, "markdown", "json"); err != nil {
+			return err
+		}
+		if *top < 0 {
+			return usageError("--top must not be negative")
+		}
+		client := newClient(graphURL)
+		id := authorID(args[0])
+		a, err := client.GetAuthor(id, "name,affiliations,url,hIndex,paperCount")
+		if err != nil {
+			return err
+		}
+		papers, err := semscholar.Collect(client.GetAuthorPapersSeq(context.Background(), id, reportPaperFields))
+		if err != nil {
+			return err
+		}
+		r := newAuthorReport(*a, papers, *top)
+
+		w := bufio.NewWriter(os.Stdout)
+		if *format == "json" {
+			err = writeJSON(w, r)
+		} else {
+			err = r.writeMarkdown(w)
+		}
+		if err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+	return cmd
+}
+
+// newAuthorReport reports on a and their papers, listing top entries.
+func newAuthorReport(a semscholar.Author, papers []semscholar.Paper, top int) *authorReport {
+	r := &authorReport{
+		AuthorID:     a.AuthorID,
+		Name:         a.Name,
+		Affiliations: a.Affiliations,
+		URL:          a.URL,
+		Papers:       len(papers),
+		HIndex:       a.HIndex.Int,
+		I10Index:     metrics.I10Index(papers),
+	}
+	byYear := make(map[string][]int)
+	venues := make(map[string]*venueCount)
+	coauthors := make(map[string]*authorStats)
+	for _, p := range papers {
+		r.Citations += p.CitationCount.Int
+		y := cmp.Or(year(p), "unknown")
+		byYear[y] = append(byYear[y], p.CitationCount.Int)
+		if p.Venue != "" {
+			v, ok := venues[p.Venue]
+			if !ok {
+				v = &venueCount{Venue: p.Venue}
+				venues[p.Venue] = v
+			}
+			v.Papers++
+			v.Citations += p.CitationCount.Int
+		}
+		for _, c := range p.Authors {
+			if c.AuthorID == a.AuthorID || c.AuthorID == "" {
+				continue
+			}
+			s, ok := coauthors[c.AuthorID]
+			if !ok {
+				s = &authorStats{AuthorID: c.AuthorID, Name: c.Name}
+				coauthors[c.AuthorID] = s
+			}
+			s.Papers++
+			s.Citations += p.CitationCount.Int
+		}
+	}
+	for _, y := range slices.Sorted(maps.Keys(byYear)) {
+		s := summarize(byYear[y])
+		r.ByYear = append(r.ByYear, yearStats{Year: y, Papers: len(byYear[y]), Citations: s.Total, Median: s.Median})
+	}
+
+	cited := slices.Clone(papers)
+	slices.SortStableFunc(cited, func(a, b semscholar.Paper) int { return b.CitationCount.Int - a.CitationCount.Int })
+	for _, p := range cited[:min(top, len(cited))] {
+		r.TopPapers = append(r.TopPapers, citedPaper{PaperID: p.PaperID, Title: p.Title, Year: year(p), Citations: p.CitationCount.Int})
+	}
+
+	var vs []venueCount
+	for _, v := range venues {
+		vs = append(vs, *v)
+	}
+	slices.SortFunc(vs, func(a, b venueCount) int {
+		return cmp.Or(b.Papers-a.Papers, b.Citations-a.Citations, strings.Compare(a.Venue, b.Venue))
+	})
+	r.TopVenues = vs[:min(top, len(vs))]
+
+	var cs []authorStats
+	for _, c := range coauthors {
+		cs = append(cs, *c)
+	}
+	slices.SortFunc(cs, func(a, b authorStats) int {
+		return cmp.Or(b.Papers-a.Papers, b.Citations-a.Citations, strings.Compare(a.Name, b.Name))
+	})
+	r.Collaborators = cs[:min(top, len(cs))]
+	return r
+}
+
+// writeMarkdown writes the report as a Markdown document.
+func (r *authorReport) writeMarkdown(w io.Writer) error {
+	fmt.Fprintf(w, "# %s\n\n", mdEscape(r.Name))
+	if len(r.Affiliations) > 0 {
+		fmt.Fprintf(w, "%s\n\n", mdEscape(strings.Join(r.Affiliations, "; ")))
+	}
+	fmt.Fprintf(w, "| Papers | Citations | h-index | i10-index |\n|---:|---:|---:|---:|\n")
+	fmt.Fprintf(w, "| %d | %d | %d | %d |\n", r.Papers, r.Citations, r.HIndex, r.I10Index)
+	if r.URL != "" {
+		fmt.Fprintf(w, "\n[Semantic Scholar profile](%s)\n", r.URL)
+	}
+
+	if len(r.ByYear) > 0 {
+		fmt.Fprintf(w, "\n## Output by year\n\n| Year | Papers | Citations |\n|---|---:|---:|\n")
+		for _, y := range r.ByYear {
+			fmt.Fprintf(w, "| %s | %d | %d |\n", y.Year, y.Papers, y.Citations)
+		}
+	}
+	if len(r.TopPapers) > 0 {
+		fmt.Fprintf(w, "\n## Most cited papers\n\n| Citations | Year | Title |\n|---:|---|---|\n")
+		for _, p := range r.TopPapers {
+			fmt.Fprintf(w, "| %d | %s | [%s](https://www.semanticscholar.org/paper/%s) |\n", p.Citations, p.Year, mdEscape(p.Title), p.PaperID)
+		}
+	}
+	if len(r.TopVenues) > 0 {
+		fmt.Fprintf(w, "\n## Venues\n\n| Venue | Papers | Citations |\n|---|---:|---:|\n")
+		for _, v := range r.TopVenues {
+			fmt.Fprintf(w, "| %s | %d | %d |\n", mdEscape(v.Venue), v.Papers, v.Citations)
+		}
+	}
+	if len(r.Collaborators) > 0 {
+		fmt.Fprintf(w, "\n## Frequent collaborators\n\n| Co-author | Joint papers | Citations |\n|---|---:|---:|\n")
+		for _, c := range r.Collaborators {
+			fmt.Fprintf(w, "| [%s](https://www.semanticscholar.org/author/%s) | %d | %d |\n", mdEscape(c.Name), c.AuthorID, c.Papers, c.Citations)
+		}
+	}
+	return nil
+}
+
+// mdEscape escapes the characters of s that Markdown tables and links
+// would otherwise take as syntax.
+var mdEscape = strings.NewReplacer(`\`, `\\`, "|", `\|`, "[", `\[`, "]", `\]`, "*", `\*`, "_", `\_`, "`", "\\`", "\n", " ").Replace
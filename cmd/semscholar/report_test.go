@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestAuthorReport checks the report author report makes of an author's
+// papers, in JSON and Markdown.
+func TestAuthorReport(t *testing.T) {
+	venuePapers(t)
+	status, stdout, stderr := runCLI(t, "", "author", "report", "1", "--format", "json", "--top", "1")
+	if status != 0 {
+		t.Fatalf("status = %d, want 0; stderr %q", status, stderr)
+	}
+	var r authorReport
+	if err := json.Unmarshal([]byte(stdout), &r); err != nil {
+		t.Fatal(err)
+	}
+	want := authorReport{
+		AuthorID:      "1",
+		Name:          "Ada Lovelace",
+		Papers:        3,
+		Citations:     14,
+		HIndex:        2,
+		ByYear:        []yearStats{{"2020", 1, 0, 0}, {"2021", 2, 14, 9}},
+		TopPapers:     []citedPaper{{"p4", "Elsewhere", "2021", 9}},
+		TopVenues:     []venueCount{{"Tests", 2, 5}},
+		Collaborators: []authorStats{{"2", "Alan Turing", 1, 5}},
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("report = %+v, want %+v", r, want)
+	}
+
+	status, stdout, stderr = runCLI(t, "", "author", "report", "https://www.semanticscholar.org/author/1")
+	if status != 0 {
+		t.Fatalf("markdown: status = %d, want 0; stderr %q", status, stderr)
+	}
+	for _, part := range []string{
+		"# Ada Lovelace\n\n| Papers | Citations | h-index | i10-index |\n|---:|---:|---:|---:|\n| 3 | 14 | 2 | 0 |\n",
+		"\n| 9 | 2021 | [Elsewhere](https://www.semanticscholar.org/paper/p4) |\n| 5 | 2021 | [Second](https://www.semanticscholar.org/paper/p2) |\n",
+		"\n| Tests | 2 | 5 |\n| Other | 1 | 9 |\n",
+		"\n| [Alan Turing](https://www.semanticscholar.org/author/2) | 1 | 5 |\n",
+	} {
+		if !strings.Contains(stdout, part) {
+			t.Errorf("markdown: stdout = %q, want it to contain %q", stdout, part)
+		}
+	}
+
+	status, _, stderr = runCLI(t, "", "author", "report", "3")
+	if status != 1 || !strings.Contains(stderr, "404") {
+		t.Errorf("unknown author: status = %d, stderr %q; want 1 and the 404", status, stderr)
+	}
+}
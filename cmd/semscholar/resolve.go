@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/spf13/cobra"
+)
+
+// resolveFields are the fields resolved papers are written with.
+const resolveFields = "title,authors,year,publicationDate,venue,externalIds,url"
+
+// resolution is the line written for each identifier or title resolved.
+type resolution struct {
+	Line   int    `json:"line"`
+	Input  string `json:"input"`
+	Status string `json:"status"`
+	// Method is how the paper was found: "id", "doi", "arxiv", or "match"
+	// or "search" for titles.
+	Method string            `json:"method,omitempty"`
+	Score  float64           `json:"score,omitempty"`
+	Paper  *semscholar.Paper `json:"paper,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// Statuses of a resolution.
+const (
+	statusResolved = "resolved"
+	statusNotFound = "not_found"
+	statusError    = "error"
+)
+
+// resolveChunk is how many lines are resolved together, bounding the memory
+// a long input takes and how long output waits.
+const resolveChunk = semscholar.MaxBatchSize
+
+// newResolveCmd returns the resolve command, which reads one identifier or
+// title per line and writes a line of JSON for each, in input order, saying
+// whether and how it was resolved. Identifiers are looked up in batches and
+// titles matched, as semscholar.ReferenceMatcher does. Blank lines and lines
+// starting with # are skipped. The exit status is 1 if some line failed with an
+// error other than not being found.
+func newResolveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resolve",
+		Short: "Resolve DOIs, arXiv IDs, paper IDs and titles read from standard input, as JSON Lines",
+	}
+	fs := cmd.Flags()
+	fields := fs.String("fields", resolveFields, "comma-separated fields of the resolved papers")
+	threshold := fs.Float64("threshold", semscholar.DefaultMatchThreshold, "lowest score, from 0 to 1, at which a title matches")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			return usageError("unexpected arguments; the input is read from standard input")
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		client := newClient(graphURL)
+		matcher := &semscholar.ReferenceMatcher{Client: client, Threshold: *threshold, Fields: *fields}
+
+		w := bufio.NewWriter(os.Stdout)
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		counts := make(map[string]int)
+		var chunk []resolution
+		flush := func() error {
+			if err := resolveLines(ctx, client, matcher, *fields, chunk); err != nil {
+				return err
+			}
+			for _, r := range chunk {
+				counts[r.Status]++
+				if err := enc.Encode(r); err != nil {
+					return err
+				}
+			}
+			chunk = chunk[:0]
+			return w.Flush()
+		}
+
+		sc := bufio.NewScanner(os.Stdin)
+		for n := 1; sc.Scan(); n++ {
+			line := strings.TrimSpace(sc.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			chunk = append(chunk, resolution{Line: n, Input: line})
+			if len(chunk) == resolveChunk {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return err
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%d resolved, %d not found, %d failed\n", counts[statusResolved], counts[statusNotFound], counts[statusError])
+		if counts[statusError] > 0 {
+			return fmt.Errorf("%d lines failed", counts[statusError])
+		}
+		return nil
+	}
+	return cmd
+}
+
+// knownIDPrefixes are the prefixes of IDs the API looks papers up by
+// directly, besides DOIs and arXiv IDs.
+var knownIDPrefixes = []string{"corpusid:", "mag:", "acl:", "pmid:", "pmcid:", "url:"}
+
+// resolveLines resolves the inputs of rs, filling in the rest of each.
+// Paper IDs and prefixed IDs are looked up in one batch; DOIs, arXiv IDs and
+// titles go through the matcher. DOIs and arXiv IDs not found are not
+// searched for as titles. Errors failing only some lines are recorded on
+// them; others, such as the context's, are returned.
+func resolveLines(ctx context.Context, client *semscholar.Client, matcher *semscholar.ReferenceMatcher, fields string, rs []resolution) error {
+	var direct, matched []int
+	var ids []string
+	var refs []semscholar.Reference
+	for i, r := range rs {
+		id := paperID(r.Input)
+		lower := strings.ToLower(id)
+		switch {
+		case strings.HasPrefix(id, "DOI:"):
+			refs = append(refs, semscholar.Reference{DOI: id[len("DOI:"):]})
+			matched = append(matched, i)
+		case strings.HasPrefix(id, "ARXIV:"):
+			refs = append(refs, semscholar.Reference{ArXivID: id[len("ARXIV:"):]})
+			matched = append(matched, i)
+		case s2PaperIDFormat.MatchString(id) || hasAnyPrefix(lower, knownIDPrefixes):
+			ids = append(ids, id)
+			direct = append(direct, i)
+		default:
+			refs = append(refs, semscholar.Reference{Raw: r.Input, Title: r.Input})
+			matched = append(matched, i)
+		}
+	}
+
+	if len(ids) > 0 {
+		papers, err := client.GetPapersBatchAll(ctx, ids, fields)
+		var partial *semscholar.PartialError
+		if err != nil && !errors.As(err, &partial) {
+			if ctx.Err() != nil {
+				return err
+			}
+			// Every chunk failed, which is the failure of every line.
+			partial = &semscholar.PartialError{Failures: []semscholar.ChunkError{{Start: 0, End: len(ids), Err: err}}}
+		}
+		for j, i := range direct {
+			switch {
+			case failedAt(partial, j) != nil:
+				rs[i].fail(failedAt(partial, j))
+			case papers[j].PaperID == "":
+				rs[i].fail(semscholar.ErrNotFound)
+			default:
+				p := papers[j]
+				rs[i].Status, rs[i].Method, rs[i].Score, rs[i].Paper = statusResolved, "id", 1, &p
+			}
+		}
+	}
+
+	if len(refs) > 0 {
+		bib, err := matcher.Match(ctx, refs)
+		if err != nil {
+			return err
+		}
+		for j, i := range matched {
+			e := bib.Entries[j]
+			if e.Paper == nil {
+				rs[i].fail(e.Err)
+				continue
+			}
+			rs[i].Status, rs[i].Method, rs[i].Score, rs[i].Paper = statusResolved, e.Method, e.Score, e.Paper
+		}
+	}
+	return nil
+}
+
+// fail records err as the outcome of r.
+func (r *resolution) fail(err error) {
+	r.Status = statusError
+	if errors.Is(err, semscholar.ErrNotFound) {
+		r.Status = statusNotFound
+	}
+	r.Error = err.Error()
+}
+
+// failedAt returns the error of the failed chunk of partial holding input
+// position i, or nil.
+func failedAt(partial *semscholar.PartialError, i int) error {
+	if partial == nil {
+		return nil
+	}
+	for _, f := range partial.Failures {
+		if i >= f.Start && i < f.End {
+			return f.Err
+		}
+	}
+	return nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// resolveInput has a comment, a blank line, each kind of identifier, a
+// title, and a DOI and a title that resolve to nothing.
+const resolveInput = `# papers to resolve
+10.18653/v1/N19-1423
+
+arXiv:1512.03385
+CorpusId:206594692
+Deep Residual Learning for Image Recognition
+10.0000/nothing
+A title nobody wrote
+`
+
+// TestResolve checks the line resolve writes for each input line, in order,
+// and the summary it ends with.
+func TestResolve(t *testing.T) {
+	mockAPI(t)
+	status, stdout, stderr := runCLI(t, resolveInput, "resolve", "--fields", "title")
+	if status != 0 {
+		t.Fatalf("status = %d, want 0; stderr %q", status, stderr)
+	}
+	if want := "4 resolved, 2 not found, 0 failed\n"; stderr != want {
+		t.Errorf("stderr = %q, want %q", stderr, want)
+	}
+	bert, resnet := semscholartest.BERTPaperID, semscholartest.ResNetPaperID
+	want := []resolution{
+		{Line: 2, Input: "10.18653/v1/N19-1423", Status: statusResolved, Method: "doi", Score: 1},
+		{Line: 4, Input: "arXiv:1512.03385", Status: statusResolved, Method: "arxiv", Score: 1},
+		{Line: 5, Input: "CorpusId:206594692", Status: statusResolved, Method: "id", Score: 1},
+		{Line: 6, Input: "Deep Residual Learning for Image Recognition", Status: statusResolved, Method: "match", Score: 1},
+		{Line: 7, Input: "10.0000/nothing", Status: statusNotFound},
+		{Line: 8, Input: "A title nobody wrote", Status: statusNotFound},
+	}
+	wantIDs := []string{bert, resnet, resnet, resnet, "", ""}
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("stdout = %q, want %d lines", stdout, len(want))
+	}
+	for i, line := range lines {
+		var r resolution
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		var id string
+		if r.Paper != nil {
+			id = r.Paper.PaperID
+		}
+		if id != wantIDs[i] {
+			t.Errorf("line %d resolved to %q, want %q", r.Line, id, wantIDs[i])
+		}
+		if (r.Status == statusNotFound) != (r.Error != "") {
+			t.Errorf("line %d has status %s and error %q", r.Line, r.Status, r.Error)
+		}
+		r.Paper, r.Error = nil, ""
+		if r != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i+1, r, want[i])
+		}
+	}
+}
+
+// TestResolveFailed checks that lines failing with errors other than not
+// being found, here the titles, are counted in the summary and fail the
+// command.
+func TestResolveFailed(t *testing.T) {
+	srv := mockAPI(t)
+	srv.Inject(semscholartest.Fault{Path: "/paper/search/match", Status: http.StatusBadRequest})
+	status, stdout, stderr := runCLI(t, resolveInput, "resolve")
+	if status != 1 {
+		t.Errorf("status = %d, want 1", status)
+	}
+	if want := "3 resolved, 1 not found, 2 failed\nsemscholar resolve: 2 lines failed\n"; stderr != want {
+		t.Errorf("stderr = %q, want %q", stderr, want)
+	}
+	if want := `{"line":6,"input":"Deep Residual Learning for Image Recognition","status":"error","error":`; !strings.Contains(stdout, want) {
+		t.Errorf("stdout = %q, want a line starting %s", stdout, want)
+	}
+}
+
+// TestResolveEmpty checks the summary of input with nothing to resolve.
+func TestResolveEmpty(t *testing.T) {
+	mockAPI(t)
+	status, stdout, stderr := runCLI(t, "# nothing\n\n", "resolve")
+	if status != 0 || stdout != "" || stderr != "0 resolved, 0 not found, 0 failed\n" {
+		t.Errorf("status = %d, stdout %q, stderr %q; want 0, nothing and the summary", status, stdout, stderr)
+	}
+}
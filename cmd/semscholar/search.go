@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultPaperFields are the fields papers are requested with for lists.
+const defaultPaperFields = "title,authors,year,publicationDate,citationCount,venue"
+
+// defaultPaperColumns are the columns of lists of papers.
+const defaultPaperColumns = "id,year,cited,title,authors"
+
+// filterFlag collects repeated --filter key=value flags.
+type filterFlag map[string]string
+
+func (f filterFlag) String() string {
+	var parts []string
+	for k, v := range f {
+		parts = append(parts, k+"="+v)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (f filterFlag) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("want key=value")
+	}
+	f[k] = v
+	return nil
+}
+
+func (f filterFlag) Type() string { return "key=value" }
+
+func newSearchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search query",
+		Short: "Search for papers by relevance",
+	}
+	fs := cmd.Flags()
+	fields := fs.String("fields", paperFields(), "comma-separated paper fields to request")
+	limit := fs.Int("limit", 10, "number of results, at most 100")
+	offset := fs.Int("offset", 0, "number of results to skip")
+	year := fs.String("year", "", "restrict to a year or range of years, such as 2019 or 2016-2020")
+	filters := filterFlag{}
+	fs.Var(filters, "filter", "search filter as key=value, such as venue=Nature; repeatable")
+	addOutputFlags(fs, recordFormats)
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return usageError("missing query")
+		}
+		if *year != "" {
+			filters["year"] = *year
+		}
+
+		query := strings.Join(args, " ")
+		resp, err := newClient(graphURL).SearchPapers(query, *offset, *limit, *fields, filters)
+		if err != nil {
+			return err
+		}
+		if err := writeRecords(resp.Data, paperColumns, defaultPaperColumns); err != nil {
+			return err
+		}
+		reportPage(resp.Offset, len(resp.Data), resp.Total)
+		return nil
+	}
+	return cmd
+}
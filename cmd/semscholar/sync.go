@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"syscall"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/spf13/cobra"
+)
+
+// newDatasetSyncCmd returns the dataset sync command, which brings a mirror
+// made by dataset download up to a later release one diff at a time, recording
+// each release reached in the manifest, so an interrupted sync continues from
+// where it stopped.
+func newDatasetSyncCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync name",
+		Short: "Update a downloaded dataset to a later release by applying diffs",
+	}
+	fs := cmd.Flags()
+	dir := fs.String("dir", "data", "directory the dataset was downloaded into")
+	from := fs.String("from", "", "release the mirror holds (default the one its manifest records)")
+	to := fs.String("to", "latest", "release to update to")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return usageError("want a dataset name")
+		}
+		dest := filepath.Join(*dir, args[0])
+		man, err := readManifest(dest)
+		if err != nil {
+			return err
+		}
+		if man.Release == "" {
+			return fmt.Errorf("%s holds no downloaded dataset; run semscholar dataset download first", dest)
+		}
+		if *from == "" {
+			*from = man.Release
+		} else if *from != man.Release {
+			return fmt.Errorf("%s holds release %s, not %s", dest, man.Release, *from)
+		}
+		list, err := newClient(datasetsURL).GetDatasetDiffs(*from, *to, args[0])
+		if err != nil {
+			return err
+		}
+		if len(list.Diffs) == 0 {
+			fmt.Printf("%s is up to date at release %s\n", args[0], man.Release)
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		var total syncStats
+		for _, diff := range list.Diffs {
+			if diff.FromRelease != man.Release {
+				return fmt.Errorf("diff from %s does not apply to release %s", diff.FromRelease, man.Release)
+			}
+			st, err := applyDiff(ctx, dest, man, diff)
+			if err != nil {
+				return fmt.Errorf("%s to %s: %w", diff.FromRelease, diff.ToRelease, err)
+			}
+			fmt.Fprintf(os.Stderr, "%s to %s: %v\n", diff.FromRelease, diff.ToRelease, st)
+			total.add(st)
+		}
+		fmt.Printf("synced %s from %s to %s in %d diffs: %v\n", args[0], *from, man.Release, len(list.Diffs), total)
+		return nil
+	}
+	return cmd
+}
+
+// syncStats counts what applying diffs did to a mirror.
+type syncStats struct {
+	added, updated, deleted int // files
+	upserts, deletes        int // records
+}
+
+func (s *syncStats) add(o syncStats) {
+	s.added += o.added
+	s.updated += o.updated
+	s.deleted += o.deleted
+	s.upserts += o.upserts
+	s.deletes += o.deletes
+}
+
+func (s syncStats) String() string {
+	return fmt.Sprintf("%d files added, %d updated, %d deleted; %d records upserted, %d deleted",
+		s.added, s.updated, s.deleted, s.upserts, s.deletes)
+}
+
+// applyDiff applies one diff to the mirror in dest. The diff's update files
+// hold records to insert or replace and its delete files records to remove,
+// both identified by primary key. The records they name are dropped from
+// the files of the mirror, which are rewritten, or deleted once empty, and
+// the update files are added to the mirror as they are.
+func applyDiff(ctx context.Context, dest string, man *manifest, diff semscholar.DatasetDiff) (syncStats, error) {
+	var st syncStats
+	tmp := filepath.Join(dest, ".sync-"+diff.ToRelease)
+	updates, err := fetchDiffFiles(ctx, filepath.Join(tmp, "updates"), diff.UpdateFiles)
+	if err != nil {
+		return st, err
+	}
+	deletes, err := fetchDiffFiles(ctx, filepath.Join(tmp, "deletes"), diff.DeleteFiles)
+	if err != nil {
+		return st, err
+	}
+
+	gone := make(map[string]bool)
+	for name := range deletes.Files {
+		if st.deletes, err = readKeys(filepath.Join(tmp, "deletes", name), gone, st.deletes); err != nil {
+			return st, err
+		}
+	}
+	for name := range updates.Files {
+		if st.upserts, err = readKeys(filepath.Join(tmp, "updates", name), gone, st.upserts); err != nil {
+			return st, err
+		}
+	}
+
+	names := make([]string, 0, len(man.Files))
+	for name := range man.Files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		kept, dropped, rec, err := dropRecords(filepath.Join(dest, name), gone)
+		switch {
+		case err != nil:
+			return st, fmt.Errorf("%s: %w", name, err)
+		case dropped == 0:
+		case kept == 0:
+			if err := os.Remove(filepath.Join(dest, name)); err != nil {
+				return st, err
+			}
+			delete(man.Files, name)
+			st.deleted++
+		default:
+			man.Files[name] = rec
+			st.updated++
+		}
+	}
+	for name, rec := range updates.Files {
+		// Diff files of different releases can share names.
+		added := diff.ToRelease + "-" + name
+		if err := os.Rename(filepath.Join(tmp, "updates", name), filepath.Join(dest, added)); err != nil {
+			return st, err
+		}
+		man.Files[added] = rec
+		st.added++
+	}
+	man.Release = diff.ToRelease
+	if err := man.write(dest); err != nil {
+		return st, err
+	}
+	return st, os.RemoveAll(tmp)
+}
+
+// fetchDiffFiles downloads the diff files at urls into dir, continuing any
+// left partly downloaded by an interrupted sync.
+func fetchDiffFiles(ctx context.Context, dir string, urls []string) (*manifest, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	d := &downloader{
+		hc:       downloadClient(),
+		dir:      dir,
+		resume:   true,
+		progress: newProgress(os.Stderr, len(urls)),
+	}
+	files := &manifest{Files: make(map[string]fileRecord)}
+	failed, err := d.fetchAll(ctx, urls, files, 4)
+	d.progress.close()
+	if err != nil {
+		return nil, err
+	}
+	if failed > 0 {
+		return nil, fmt.Errorf("%d of %d diff files failed; run sync again to retry them", failed, len(urls))
+	}
+	return files, nil
+}
+
+// recordKey returns the primary key of a dataset record: its corpus ID in
+// datasets about papers, its citation or author ID in those datasets, and
+// the whole record in others.
+func recordKey(line []byte) (string, error) {
+	var rec map[string]json.RawMessage
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return "", err
+	}
+	for _, k := range []string{"corpusid", "corpusId", "citationid", "authorid", "authorId"} {
+		if v, ok := rec[k]; ok {
+			return string(v), nil
+		}
+	}
+	return string(bytes.TrimSpace(line)), nil
+}
+
+// eachRecord calls fn with each line of the gzip JSON Lines file at name.
+func eachRecord(name string, fn func(line []byte) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	// Lines are read whole, however long; an abstract or embedding can
+	// exceed any fixed buffer.
+	br := bufio.NewReader(zr)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if ferr := fn(line); ferr != nil {
+				return ferr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readKeys adds the keys of the records in the file at name to keys,
+// returning n plus the number of records.
+func readKeys(name string, keys map[string]bool, n int) (int, error) {
+	err := eachRecord(name, func(line []byte) error {
+		key, err := recordKey(line)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(name), err)
+		}
+		keys[key] = true
+		n++
+		return nil
+	})
+	return n, err
+}
+
+// dropRecords rewrites the file at name without the records whose keys are
+// in gone, and returns the numbers of records kept and dropped and the
+// record of the rewritten file. The file is only replaced if records were
+// dropped and some were kept; what to do with a file left empty is up to the
+// caller.
+func dropRecords(name string, gone map[string]bool) (kept, dropped int, rec fileRecord, err error) {
+	tmp := name + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return 0, 0, rec, err
+	}
+	defer os.Remove(tmp)
+	sum := sha256.New()
+	cw := &countWriter{w: io.MultiWriter(f, sum)}
+	zw := gzip.NewWriter(cw)
+	err = eachRecord(name, func(line []byte) error {
+		key, err := recordKey(line)
+		if err != nil {
+			return err
+		}
+		if gone[key] {
+			dropped++
+			return nil
+		}
+		kept++
+		_, err = zw.Write(line)
+		return err
+	})
+	if zerr := zw.Close(); err == nil {
+		err = zerr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil || dropped == 0 || kept == 0 {
+		return kept, dropped, rec, err
+	}
+	rec = fileRecord{Size: cw.n, SHA256: hex.EncodeToString(sum.Sum(nil))}
+	return kept, dropped, rec, os.Rename(tmp, name)
+}
+
+// countWriter counts the bytes written through it.
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDatasetSync checks that dataset sync applies each diff from the
+// release downloaded to the latest, recording the release reached.
+func TestDatasetSync(t *testing.T) {
+	mockAPI(t)
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "papers")
+
+	status, _, stderr := runCLI(t, "", "dataset", "sync", "papers", "--dir", dir)
+	if want := "holds no downloaded dataset"; status != 1 || !strings.Contains(stderr, want) {
+		t.Errorf("before download: status = %d, stderr %q; want 1 and %q", status, stderr, want)
+	}
+	if status, _, stderr := runCLI(t, "", "dataset", "download", "2024-11-05", "papers", "--dir", dir); status != 0 {
+		t.Fatalf("download: status = %d; stderr %q", status, stderr)
+	}
+	status, _, stderr = runCLI(t, "", "dataset", "sync", "papers", "--dir", dir, "--from", "2024-11-12")
+	if want := dest + " holds release 2024-11-05, not 2024-11-12"; status != 1 || !strings.Contains(stderr, want) {
+		t.Errorf("--from another release: status = %d, stderr %q; want 1 and %q", status, stderr, want)
+	}
+
+	status, stdout, stderr := runCLI(t, "", "dataset", "sync", "papers", "--dir", dir)
+	if status != 0 {
+		t.Fatalf("status = %d, want 0; stderr %q", status, stderr)
+	}
+	if want := "synced papers from 2024-11-05 to 2024-11-19 in 2 diffs: "; !strings.HasPrefix(stdout, want) {
+		t.Errorf("stdout = %q, want it to start %q", stdout, want)
+	}
+	for _, diff := range []string{"\n2024-11-05 to 2024-11-12: ", "\n2024-11-12 to 2024-11-19: "} {
+		if !strings.Contains(stderr, diff) {
+			t.Errorf("stderr = %q, want it to report %q", stderr, diff[1:])
+		}
+	}
+	man, err := readManifest(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if man.Release != "2024-11-19" {
+		t.Errorf("manifest release %s, want 2024-11-19", man.Release)
+	}
+
+	status, stdout, _ = runCLI(t, "", "dataset", "sync", "papers", "--dir", dir)
+	if want := "papers is up to date at release 2024-11-19\n"; status != 0 || stdout != want {
+		t.Errorf("again: status = %d, stdout %q; want 0 and %q", status, stdout, want)
+	}
+}
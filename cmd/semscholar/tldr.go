@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/spf13/cobra"
+)
+
+// tldrPaperFields are the fields summaries are printed with.
+const tldrPaperFields = "title,year,publicationDate,tldr,abstract"
+
+// newTldrCmd returns the tldr command, which prints each paper's title and year
+// followed by its TLDR, the one-sentence summary Semantic Scholar generates, or
+// for papers without one the abstract cut to --chars characters, to triage a
+// list of papers at a glance.
+func newTldrCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tldr id ...",
+		Short: "Print the TLDR summaries of papers, or the start of their abstracts",
+	}
+	fs := cmd.Flags()
+	idsFile := fs.String("ids-file", "", "file of identifiers, one per line, or - for standard input")
+	n := fs.IntP("chars", "n", 300, "most characters of an abstract printed in place of a TLDR")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ids := args
+		if *idsFile != "" {
+			fileIDs, err := readIDs(*idsFile)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, fileIDs...)
+		}
+		if len(ids) == 0 {
+			return usageError("no identifiers; give them as arguments or with --ids-file")
+		}
+		if *n < 2 {
+			return usageError("--chars must be at least 2")
+		}
+		resolved := make([]string, len(ids))
+		for i, id := range ids {
+			resolved[i] = paperID(id)
+		}
+		papers, err := newClient(graphURL).GetPapersBatchAll(context.Background(), resolved, tldrPaperFields)
+		var partial *semscholar.PartialError
+		if err != nil && !errors.As(err, &partial) {
+			return err
+		}
+
+		w := bufio.NewWriter(os.Stdout)
+		missing, printed := 0, 0
+		for i, p := range papers {
+			if p.PaperID == "" {
+				fmt.Fprintf(os.Stderr, "not found: %s\n", ids[i])
+				missing++
+				continue
+			}
+			if printed > 0 {
+				fmt.Fprintln(w)
+			}
+			printed++
+			fmt.Fprintf(w, "%s  %s\n", p.PaperID, strings.TrimSpace(p.Title+"  "+year(p)))
+			var summary string
+			switch {
+			case p.Tldr != nil && p.Tldr.Text != "":
+				summary = "TLDR: " + p.Tldr.Text
+			case p.Abstract != "":
+				summary = "Abstract: " + truncate(p.Abstract, *n)
+			default:
+				summary = "(no TLDR or abstract)"
+			}
+			fmt.Fprintf(w, "    %s\n", strings.ReplaceAll(wrap(summary, 76), "\n", "\n    "))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if partial != nil {
+			return partial
+		}
+		if missing > 0 {
+			return fmt.Errorf("%d of %d identifiers not found", missing, len(ids))
+		}
+		return nil
+	}
+	return cmd
+}
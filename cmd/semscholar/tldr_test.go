@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestTldr checks that tldr prints each paper's TLDR, or the start of its
+// abstract, and reports the identifiers not found.
+func TestTldr(t *testing.T) {
+	mockAPI(t)
+	status, stdout, stderr := runCLI(t, "1706.03762\n\n", "tldr", "arxiv:1810.04805", "nope", "--ids-file", "-", "-n", "40")
+	if status != 1 {
+		t.Errorf("status = %d, want 1", status)
+	}
+	want := semscholartest.BERTPaperID + "  BERT: Pre-training of Deep Bidirectional Transformers for Language Understanding  2019\n" +
+		"    Abstract: We introduce a new language representat…\n" +
+		"\n" +
+		semscholartest.AttentionPaperID + "  Attention is All you Need  2017\n" +
+		"    TLDR: A new simple network architecture, the Transformer, based solely on\n" +
+		"    attention mechanisms, dispensing with recurrence and convolutions entirely\n" +
+		"    is proposed.\n"
+	if stdout != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+	if want := "not found: nope\nsemscholar tldr: 1 of 3 identifiers not found\n"; stderr != want {
+		t.Errorf("stderr = %q, want %q", stderr, want)
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/metrics"
+	"github.com/spf13/cobra"
+)
+
+// venueReport is the statistics of the papers of a venue.
+type venueReport struct {
+	Venue     string        `json:"venue"`
+	Years     string        `json:"years,omitempty"`
+	Papers    int           `json:"papers"`
+	Truncated bool          `json:"truncated,omitempty"`
+	Citations citationStats `json:"citations"`
+	// Buckets counts papers by citations: none, 1-9, 10-99, 100-999 and 1000
+	// or more.
+	Buckets    [5]int               `json:"citationBuckets"`
+	OpenAccess float64              `json:"openAccessShare"`
+	Fields     []metrics.FieldCount `json:"fieldsOfStudy"`
+	ByYear     []yearStats          `json:"byYear"`
+	TopAuthors []authorStats        `json:"topAuthors"`
+	MostCited  []citedPaper         `json:"mostCited"`
+}
+
+// citationStats summarizes the citation counts of papers.
+type citationStats struct {
+	Total  int     `json:"total"`
+	Mean   float64 `json:"mean"`
+	Median int     `json:"median"`
+	P90    int     `json:"p90"`
+	Max    int     `json:"max"`
+}
+
+type yearStats struct {
+	Year      string `json:"year"`
+	Papers    int    `json:"papers"`
+	Citations int    `json:"citations"`
+	Median    int    `json:"median"`
+}
+
+type authorStats struct {
+	AuthorID  string `json:"authorId"`
+	Name      string `json:"name"`
+	Papers    int    `json:"papers"`
+	Citations int    `json:"citations"`
+}
+
+type citedPaper struct {
+	PaperID   string `json:"paperId"`
+	Title     string `json:"title"`
+	Year      string `json:"year"`
+	Citations int    `json:"citations"`
+}
+
+// bucketNames name the citation buckets of a venueReport.
+var bucketNames = [5]string{"0", "1-9", "10-99", "100-999", "1000+"}
+
+// newVenueStatsCmd returns the venue stats command, which bulk-searches the
+// papers of a venue, in the years of --years, and reports on them. --max bounds
+// how many papers are fetched; the report says when it cut the search short.
+func newVenueStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats venue",
+		Short: "Report paper counts, citations, open access, fields and top authors of a venue",
+	}
+	fs := cmd.Flags()
+	years := fs.String("years", "", "restrict to a year or range of years, such as 2020 or 2018-2024")
+	query := fs.String("query", "", "restrict to papers matching a query")
+	top := fs.Int("top", 10, "number of top authors and most cited papers listed")
+	maxPapers := fs.Int("max", 10000, "most papers fetched")
+	format := fs.String("format", "table", "output format: table or json")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return usageError("want one venue, such as \"Nature\"")
+		}
+		if err := checkFormat(*format, "table", "json"); err != nil {
+			return err
+		}
+		if *maxPapers < 1 || *top < 0 {
+			return usageError("--max must be positive and --top not negative")
+		}
+		filters := map[string]string{"venue": args[0]}
+		if *years != "" {
+			filters["year"] = *years
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		var papers []semscholar.Paper
+		truncated := false
+		seq := newClient(graphURL).BulkSearchPapersSeq(ctx, *query, "title,authors,"+metrics.VenueFields, "", "", filters)
+		for p, err := range seq {
+			if err != nil {
+				return err
+			}
+			if len(papers) == *maxPapers {
+				truncated = true
+				break
+			}
+			papers = append(papers, p)
+		}
+		if len(papers) == 0 {
+			return fmt.Errorf("no papers of %q found", args[0])
+		}
+		r := venueStats(papers, *top)
+		r.Venue, r.Years, r.Truncated = args[0], *years, truncated
+
+		w := bufio.NewWriter(os.Stdout)
+		var err error
+		if *format == "json" {
+			err = writeJSON(w, r)
+		} else {
+			err = r.writeTable(w)
+		}
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			return err
+		}
+		if truncated {
+			fmt.Fprintf(os.Stderr, "stopped at --max %d papers; the statistics cover those\n", *maxPapers)
+		}
+		return nil
+	}
+	return cmd
+}
+
+// venueStats computes the statistics of papers, listing top authors, papers
+// and fields of study.
+func venueStats(papers []semscholar.Paper, top int) *venueReport {
+	vs := metrics.NewVenueStats(papers)
+	r := &venueReport{Papers: len(papers), OpenAccess: vs.OpenAccessShare, Fields: vs.Fields[:min(top, len(vs.Fields))]}
+	counts := make([]int, len(papers))
+	for i, p := range papers {
+		counts[i] = p.CitationCount.Int
+	}
+	r.Citations = summarize(counts)
+	for _, c := range counts {
+		switch {
+		case c == 0:
+			r.Buckets[0]++
+		case c < 10:
+			r.Buckets[1]++
+		case c < 100:
+			r.Buckets[2]++
+		case c < 1000:
+			r.Buckets[3]++
+		default:
+			r.Buckets[4]++
+		}
+	}
+
+	byYear := make(map[string][]int)
+	for _, p := range papers {
+		y := cmp.Or(year(p), "unknown")
+		byYear[y] = append(byYear[y], p.CitationCount.Int)
+	}
+	for _, y := range slices.Sorted(maps.Keys(byYear)) {
+		s := summarize(byYear[y])
+		r.ByYear = append(r.ByYear, yearStats{Year: y, Papers: len(byYear[y]), Citations: s.Total, Median: s.Median})
+	}
+
+	authors := make(map[string]*authorStats)
+	for _, p := range papers {
+		for _, a := range p.Authors {
+			key := cmp.Or(a.AuthorID, a.Name)
+			if key == "" {
+				continue
+			}
+			s, ok := authors[key]
+			if !ok {
+				s = &authorStats{AuthorID: a.AuthorID, Name: a.Name}
+				authors[key] = s
+			}
+			s.Papers++
+			s.Citations += p.CitationCount.Int
+		}
+	}
+	var ranked []authorStats
+	for _, s := range authors {
+		ranked = append(ranked, *s)
+	}
+	slices.SortFunc(ranked, func(a, b authorStats) int {
+		return cmp.Or(b.Papers-a.Papers, b.Citations-a.Citations, strings.Compare(a.Name, b.Name))
+	})
+	r.TopAuthors = ranked[:min(top, len(ranked))]
+
+	cited := slices.Clone(papers)
+	slices.SortStableFunc(cited, func(a, b semscholar.Paper) int { return b.CitationCount.Int - a.CitationCount.Int })
+	for _, p := range cited[:min(top, len(cited))] {
+		r.MostCited = append(r.MostCited, citedPaper{PaperID: p.PaperID, Title: p.Title, Year: year(p), Citations: p.CitationCount.Int})
+	}
+	return r
+}
+
+// summarize returns the statistics of citation counts, which it sorts.
+func summarize(counts []int) citationStats {
+	if len(counts) == 0 {
+		return citationStats{}
+	}
+	slices.Sort(counts)
+	var s citationStats
+	for _, c := range counts {
+		s.Total += c
+	}
+	s.Mean = float64(s.Total) / float64(len(counts))
+	s.Median = counts[len(counts)/2]
+	s.P90 = counts[min(len(counts)*9/10, len(counts)-1)]
+	s.Max = counts[len(counts)-1]
+	return s
+}
+
+// writeTable writes the report as a series of tables.
+func (r *venueReport) writeTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	title := r.Venue
+	if r.Years != "" {
+		title += ", " + r.Years
+	}
+	fmt.Fprintf(tw, "%s\n\n", title)
+	c := r.Citations
+	fmt.Fprintf(tw, "papers\t%d\ncitations\t%d\nmean\t%.1f\nmedian\t%d\n90th percentile\t%d\nmost\t%d\n", r.Papers, c.Total, c.Mean, c.Median, c.P90, c.Max)
+	fmt.Fprintf(tw, "open access\t%.0f%%\n", 100*r.OpenAccess)
+
+	fmt.Fprintf(tw, "\nCITATIONS\tPAPERS\n")
+	for i, n := range r.Buckets {
+		fmt.Fprintf(tw, "%s\t%d\n", bucketNames[i], n)
+	}
+	if len(r.Fields) > 0 {
+		fmt.Fprintf(tw, "\nFIELD\tPAPERS\n")
+		for _, f := range r.Fields {
+			fmt.Fprintf(tw, "%s\t%d\n", f.Field, f.Papers)
+		}
+	}
+	fmt.Fprintf(tw, "\nYEAR\tPAPERS\tCITATIONS\tMEDIAN\n")
+	for _, y := range r.ByYear {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", y.Year, y.Papers, y.Citations, y.Median)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.TopAuthors) > 0 {
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "\nAUTHOR\tPAPERS\tCITATIONS\n")
+		for _, a := range r.TopAuthors {
+			fmt.Fprintf(tw, "%s\t%d\t%d\n", truncate(a.Name, 40), a.Papers, a.Citations)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(r.MostCited) > 0 {
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "\nCITED\tYEAR\tTITLE\n")
+		for _, p := range r.MostCited {
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", p.Citations, cmp.Or(p.Year, "-"), truncate(p.Title, 70))
+		}
+		return tw.Flush()
+	}
+	return nil
+}
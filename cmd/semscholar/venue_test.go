@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/metrics"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// venuePapers serves three papers of the venue Tests and one of another.
+func venuePapers(t *testing.T) {
+	t.Helper()
+	ada := semscholar.Author{AuthorID: "1", Name: "Ada Lovelace"}
+	alan := semscholar.Author{AuthorID: "2", Name: "Alan Turing"}
+	d := semscholartest.NewDataset()
+	for _, p := range []semscholar.Paper{
+		{PaperID: "p1", Title: "First", Venue: "Tests", Year: 2020, CitationCount: semscholar.NewNullInt(0), Authors: []semscholar.Author{ada}, FieldsOfStudy: []string{"Mathematics"}},
+		{PaperID: "p2", Title: "Second", Venue: "Tests", Year: 2021, CitationCount: semscholar.NewNullInt(5), Authors: []semscholar.Author{ada, alan}, IsOpenAccess: true, FieldsOfStudy: []string{"Mathematics", "Computer Science"}},
+		{PaperID: "p3", Title: "Third", Venue: "Tests", Year: 2021, CitationCount: semscholar.NewNullInt(1500), Authors: []semscholar.Author{alan}, FieldsOfStudy: []string{"Computer Science"}},
+		{PaperID: "p4", Title: "Elsewhere", Venue: "Other", Year: 2021, CitationCount: semscholar.NewNullInt(9), Authors: []semscholar.Author{ada}},
+	} {
+		d.AddPaper(p)
+	}
+	srv := semscholartest.NewServer(d)
+	t.Cleanup(srv.Close)
+	useMock(t, srv)
+}
+
+// TestVenueStats checks the statistics venue stats reports of a venue's
+// papers.
+func TestVenueStats(t *testing.T) {
+	venuePapers(t)
+	status, stdout, stderr := runCLI(t, "", "venue", "stats", "Tests", "--format", "json", "--top", "1")
+	if status != 0 {
+		t.Fatalf("status = %d, want 0; stderr %q", status, stderr)
+	}
+	var r venueReport
+	if err := json.Unmarshal([]byte(stdout), &r); err != nil {
+		t.Fatal(err)
+	}
+	want := venueReport{
+		Venue:      "Tests",
+		Papers:     3,
+		Citations:  citationStats{Total: 1505, Mean: 1505.0 / 3, Median: 5, P90: 1500, Max: 1500},
+		Buckets:    [5]int{1, 1, 0, 0, 1},
+		OpenAccess: 1.0 / 3,
+		Fields:     []metrics.FieldCount{{Field: "Computer Science", Papers: 2}},
+		ByYear:     []yearStats{{"2020", 1, 0, 0}, {"2021", 2, 1505, 1500}},
+		TopAuthors: []authorStats{{"2", "Alan Turing", 2, 1505}},
+		MostCited:  []citedPaper{{"p3", "Third", "2021", 1500}},
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("report = %+v, want %+v", r, want)
+	}
+
+	status, stdout, stderr = runCLI(t, "", "venue", "stats", "Tests", "--years", "2021", "--max", "1")
+	if status != 0 {
+		t.Fatalf("--max 1: status = %d, want 0; stderr %q", status, stderr)
+	}
+	for _, line := range []string{"Tests, 2021\n", "\npapers           1\n", "\nopen access      "} {
+		if !strings.Contains(stdout, line) {
+			t.Errorf("--max 1: stdout = %q, want the line %q", stdout, strings.TrimSpace(line))
+		}
+	}
+	if want := "stopped at --max 1 papers; the statistics cover those\n"; stderr != want {
+		t.Errorf("--max 1: stderr = %q, want %q", stderr, want)
+	}
+
+	status, _, stderr = runCLI(t, "", "venue", "stats", "Nowhere")
+	if want := `semscholar venue stats: no papers of "Nowhere" found` + "\n"; status != 1 || stderr != want {
+		t.Errorf("unknown venue: status = %d, stderr %q; want 1 and %q", status, stderr, want)
+	}
+}
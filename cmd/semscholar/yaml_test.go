@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// TestWriteYAML checks that writeYAML writes the fields of the JSON output in
+// block style, quoting strings that would read back as other types.
+func TestWriteYAML(t *testing.T) {
+	papers := []semscholar.Paper{{
+		PaperID:       "a",
+		Title:         "Attention: all you need",
+		Year:          2017,
+		Authors:       []semscholar.Author{{AuthorID: "123", Name: "A. Vaswani"}},
+		FieldsOfStudy: []string{"Computer Science"},
+	}}
+	var b strings.Builder
+	if err := writeYAML(&b, papers); err != nil {
+		t.Fatal(err)
+	}
+	want := `- paperId: a
+  title: 'Attention: all you need'
+  year: 2017
+  authors:
+    - authorId: "123"
+      name: A. Vaswani
+  fieldsOfStudy:
+    - Computer Science
+`
+	if got := b.String(); got != want {
+		t.Errorf("writeYAML wrote\n%s\nwant\n%s", got, want)
+	}
+
+	b.Reset()
+	if err := writeYAML(&b, []semscholar.Paper{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "[]\n" {
+		t.Errorf("writeYAML wrote %q for no papers, want an empty list", got)
+	}
+}
@@ -0,0 +1,187 @@
+package semscholar
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// DatePrecision is how much of a Date is known.
+type DatePrecision int
+
+// Precisions of a Date. The API gives a publication date as a day when it
+// knows it, and some sources only as a month or a year.
+const (
+	DateYear DatePrecision = iota + 1
+	DateMonth
+	DateDay
+)
+
+// dateLayouts are the layouts of dates of each precision.
+var dateLayouts = map[DatePrecision]string{
+	DateYear:  "2006",
+	DateMonth: "2006-01",
+	DateDay:   "2006-01-02",
+}
+
+// Date is a calendar date that may be known only to the month or year, such
+// as a paper's publication date. Time is midnight UTC of its first day, so
+// the year 2017 is 2017-01-01. The zero Date is null: the date is not known
+// or was not requested. In JSON a Date is a string as the API writes it,
+// "2017-06-12", "2017-06" or "2017", or null; a bare year number is also read.
+type Date struct {
+	Time      time.Time
+	Precision DatePrecision
+}
+
+// NewDate returns the date of year, month and day. A zero day makes a date
+// known to the month, and a zero month one known to the year.
+func NewDate(year int, month time.Month, day int) Date {
+	switch {
+	case month == 0:
+		return Date{time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC), DateYear}
+	case day == 0:
+		return Date{time.Date(year, month, 1, 0, 0, 0, 0, time.UTC), DateMonth}
+	}
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC), DateDay}
+}
+
+// ParseDate parses a date as the API writes it: "2017-06-12", "2017-06" or
+// "2017". An empty string is the zero Date.
+func ParseDate(s string) (Date, error) {
+	if s == "" {
+		return Date{}, nil
+	}
+	for p := DateDay; p >= DateYear; p-- {
+		if len(s) == len(dateLayouts[p]) {
+			t, err := time.Parse(dateLayouts[p], s)
+			if err != nil {
+				break
+			}
+			return Date{t, p}, nil
+		}
+	}
+	return Date{}, fmt.Errorf("semscholar: invalid date %q", s)
+}
+
+// IsZero reports whether d is null.
+func (d Date) IsZero() bool {
+	return d.Precision == 0
+}
+
+// Year returns the year of d, or zero if d is null.
+func (d Date) Year() int {
+	if d.IsZero() {
+		return 0
+	}
+	return d.Time.Year()
+}
+
+// Month returns the month of d, or zero if d is null or known only to the
+// year.
+func (d Date) Month() time.Month {
+	if d.Precision < DateMonth {
+		return 0
+	}
+	return d.Time.Month()
+}
+
+// String returns d as the API writes it, or "" if d is null.
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Time.Format(dateLayouts[d.Precision])
+}
+
+// Compare returns -1, 0 or +1 as d is before, the same as or after e. Null
+// dates sort first, and a date known only to the year or month before the
+// days in it.
+func (d Date) Compare(e Date) int {
+	if d.IsZero() || e.IsZero() {
+		return cmp.Compare(d.Precision, e.Precision) // null is 0
+	}
+	return cmp.Or(d.Time.Compare(e.Time), cmp.Compare(d.Precision, e.Precision))
+}
+
+// MarshalJSON encodes d as a string, or null if d is null.
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes a date as the API writes it, a bare year number as
+// some records give it, or null. Any other value is rejected with a
+// *json.UnmarshalTypeError, which Client decoding turns into a null date
+// unless StrictDecoding is set.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*d = Date{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var year json.Number
+		if json.Unmarshal(data, &year) != nil {
+			return &json.UnmarshalTypeError{Value: "non-string", Type: dateType}
+		}
+		y, err := year.Int64()
+		if err != nil || y < 1 || y > 9999 {
+			return &json.UnmarshalTypeError{Value: "number " + year.String(), Type: dateType}
+		}
+		*d = NewDate(int(y), 0, 0)
+		return nil
+	}
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return &json.UnmarshalTypeError{Value: "string " + strconv.Quote(s), Type: dateType}
+	}
+	*d = parsed
+	return nil
+}
+
+var dateType = reflect.TypeFor[Date]()
+
+// PublishedYear returns the year p was published: its year, or failing that
+// the year of its publication date, or zero if neither is known. Many
+// records carry only one of the two. Where both are known and differ, as
+// when the date is that of a preprint and the year that of the venue, the
+// year is taken.
+func (p *Paper) PublishedYear() int {
+	if p.Year != 0 {
+		return p.Year
+	}
+	return p.PublicationDate.Year()
+}
+
+// PublishedDate returns the date p was published: its publication date if
+// it falls in the year PublishedYear gives, else that year as a date known
+// to the year, or the zero Date if neither is known.
+func (p *Paper) PublishedDate() Date {
+	y := p.PublishedYear()
+	switch {
+	case y == 0:
+		return Date{}
+	case p.PublicationDate.Year() == y:
+		return p.PublicationDate
+	}
+	return NewDate(y, 0, 0)
+}
+
+// ReconcileDates fills in whichever of p's year and publication date is
+// missing from the other. A date filled in from the year is known to the
+// year only. Values already set are kept, even where they differ.
+func (p *Paper) ReconcileDates() {
+	if p.Year == 0 {
+		p.Year = p.PublicationDate.Year()
+	}
+	if p.PublicationDate.IsZero() && p.Year != 0 {
+		p.PublicationDate = NewDate(p.Year, 0, 0)
+	}
+}
@@ -0,0 +1,76 @@
+package semscholar_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// TestDateOmitted checks that a Paper leaves a null publication date out of
+// its JSON, which needs the omitzero option of Go 1.24, and round-trips dates
+// of each precision.
+func TestDateOmitted(t *testing.T) {
+	data, err := json.Marshal(semscholar.Paper{PaperID: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"publicationDate"`) {
+		t.Errorf("encoded %s, want no publicationDate", data)
+	}
+	for _, d := range []semscholar.Date{
+		semscholar.NewDate(2017, 6, 12),
+		semscholar.NewDate(2017, 6, 0),
+		semscholar.NewDate(2017, 0, 0),
+	} {
+		data, err := json.Marshal(semscholar.Paper{PaperID: "a", PublicationDate: d})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p semscholar.Paper
+		if err := json.Unmarshal(data, &p); err != nil {
+			t.Fatal(err)
+		}
+		if p.PublicationDate != d {
+			t.Errorf("%s decoded as %v", data, p.PublicationDate)
+		}
+	}
+}
+
+// TestDateUnmarshal checks that a Date decodes the API's strings, a bare
+// year and null, and rejects anything else with a *json.UnmarshalTypeError.
+func TestDateUnmarshal(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		want semscholar.Date
+		ok   bool
+	}{
+		{`"2017-06-12"`, semscholar.NewDate(2017, 6, 12), true},
+		{`"2017-06"`, semscholar.NewDate(2017, 6, 0), true},
+		{`"2017"`, semscholar.NewDate(2017, 0, 0), true},
+		{`2017`, semscholar.NewDate(2017, 0, 0), true},
+		{`null`, semscholar.Date{}, true},
+		{`""`, semscholar.Date{}, true},
+		{`"2017-13-45"`, semscholar.Date{}, false},
+		{`"Jan 2017"`, semscholar.Date{}, false},
+		{`2017.5`, semscholar.Date{}, false},
+		{`0`, semscholar.Date{}, false},
+		{`true`, semscholar.Date{}, false},
+		{`{"year":2017}`, semscholar.Date{}, false},
+	} {
+		var d semscholar.Date
+		err := d.UnmarshalJSON([]byte(tc.data))
+		if !tc.ok {
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				t.Errorf("%s: got error %v, want a *json.UnmarshalTypeError", tc.data, err)
+			}
+			continue
+		}
+		if err != nil || d != tc.want {
+			t.Errorf("%s: got %v, %v, want %v", tc.data, d, err, tc.want)
+		}
+	}
+}
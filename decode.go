@@ -0,0 +1,349 @@
+package semscholar
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Unmarshaler decodes JSON response bodies. Setting Client.Unmarshaler swaps
+// encoding/json for a faster implementation, which pays off when decoding
+// millions of bulk search or batch records dominates CPU time. Any function
+// with the signature of json.Unmarshal can be used via UnmarshalFunc:
+//
+//	client.Unmarshaler = semscholar.UnmarshalFunc(sonic.Unmarshal)
+type Unmarshaler interface {
+	Unmarshal(data []byte, v any) error
+}
+
+// UnmarshalFunc adapts a function to the Unmarshaler interface.
+type UnmarshalFunc func(data []byte, v any) error
+
+// Unmarshal calls f(data, v).
+func (f UnmarshalFunc) Unmarshal(data []byte, v any) error {
+	return f(data, v)
+}
+
+// DecodeError reports a response body that could not be decoded.
+type DecodeError struct {
+	// Field is the dotted path of the offending field, such as
+	// "data.authors.hIndex", or empty if the error is not tied to one.
+	Field string
+	Err   error
+}
+
+// Error implements the error interface.
+func (e *DecodeError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("semscholar: decoding field %q: %v", e.Field, e.Err)
+	}
+	return fmt.Sprintf("semscholar: decoding response: %v", e.Err)
+}
+
+// Unwrap returns the underlying decoder error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func newDecodeError(err error) *DecodeError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &DecodeError{Field: typeErr.Field, Err: err}
+	}
+	if _, name, ok := strings.Cut(err.Error(), "json: unknown field "); ok {
+		if field, uerr := strconv.Unquote(name); uerr == nil {
+			return &DecodeError{Field: field, Err: err}
+		}
+	}
+	return &DecodeError{Err: err}
+}
+
+// decode reads a JSON value from r into v; see unmarshal.
+func (c *Client) decode(r io.Reader, v any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return c.unmarshal(data, v)
+}
+
+// unmarshal decodes data into v. With StrictDecoding, encoding/json rejects
+// fields v does not declare. Otherwise c.Unmarshaler or encoding/json is used,
+// and if it fails on a value of the wrong JSON type, such as a count sent as
+// "12" or an ID sent as a bare number, the value is coerced to the type of its
+// field and decoding tried again. Failures are reported as a *DecodeError.
+func (c *Client) unmarshal(data []byte, v any) error {
+	var err error
+	switch {
+	case c.StrictDecoding:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(v)
+	case c.Unmarshaler != nil:
+		err = c.Unmarshaler.Unmarshal(data, v)
+	default:
+		err = json.Unmarshal(data, v)
+	}
+	if err == nil {
+		return nil
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		return newDecodeError(err)
+	}
+	if !c.StrictDecoding && unmarshalLenient(data, v) == nil {
+		return nil
+	}
+	derr := newDecodeError(err)
+	if derr.Field == "" {
+		derr.Field = rejectedField(data, v)
+	}
+	return derr
+}
+
+// decodeNext decodes the next value from dec into v with c.unmarshal.
+func (c *Client) decodeNext(dec *json.Decoder, v any) error {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	return c.unmarshal(raw, v)
+}
+
+// unmarshalLenient decodes data generically, coerces every value to the type
+// of the field it lands in, and decodes the result into v.
+func unmarshalLenient(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return err
+	}
+	fixed, err := json.Marshal(coerce(generic, reflect.TypeOf(v)))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(fixed, v)
+}
+
+var jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+var nullIntType = reflect.TypeFor[NullInt]()
+
+// coerce converts val, as decoded with UseNumber, towards what encoding/json
+// expects for type t: numeric strings for numbers, numbers and booleans for
+// strings, and "true"/"false" or 0/1 for booleans. Values it cannot convert
+// are left for encoding/json to reject. A NullInt is coerced as an int, and a
+// Date that cannot be read, such as "2017-13-45" or "Jan 2017", becomes null,
+// so that one bad date does not fail a whole batch.
+func coerce(val any, t reflect.Type) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nullIntType {
+		t = reflect.TypeFor[int]()
+	}
+	if t == dateType {
+		data, err := json.Marshal(val)
+		if err != nil || new(Date).UnmarshalJSON(data) != nil {
+			return nil
+		}
+		return val
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return val
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var s string
+		switch x := val.(type) {
+		case string:
+			s = strings.TrimSpace(x)
+			if s == "" {
+				return nil
+			}
+		case json.Number:
+			s = string(x)
+		default:
+			return val
+		}
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return json.Number(s)
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil && f == float64(int64(f)) {
+			return json.Number(strconv.FormatInt(int64(f), 10))
+		}
+	case reflect.Float32, reflect.Float64:
+		if x, ok := val.(string); ok {
+			if s := strings.TrimSpace(x); s == "" {
+				return nil
+			} else if _, err := strconv.ParseFloat(s, 64); err == nil {
+				return json.Number(s)
+			}
+		}
+	case reflect.String:
+		switch x := val.(type) {
+		case json.Number:
+			return string(x)
+		case bool:
+			return strconv.FormatBool(x)
+		}
+	case reflect.Bool:
+		switch x := val.(type) {
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(x)); err == nil {
+				return b
+			}
+		case json.Number:
+			if x == "0" || x == "1" {
+				return x == "1"
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if arr, ok := val.([]any); ok {
+			for i := range arr {
+				arr[i] = coerce(arr[i], t.Elem())
+			}
+		}
+	case reflect.Map:
+		if m, ok := val.(map[string]any); ok {
+			for k := range m {
+				m[k] = coerce(m[k], t.Elem())
+			}
+		}
+	case reflect.Struct:
+		if m, ok := val.(map[string]any); ok {
+			fields := jsonFields(t)
+			for k := range m {
+				ft, ok := fields[k]
+				if !ok {
+					ft, ok = fields[strings.ToLower(k)]
+				}
+				if ok {
+					m[k] = coerce(m[k], ft)
+				}
+			}
+		}
+	}
+	return val
+}
+
+// rejectedField returns the dotted path of a field of v whose value in data
+// its type's UnmarshalJSON method rejects, or "" if there is none. Errors
+// returned by those methods reach the caller of json.Unmarshal without the
+// path of the field.
+func rejectedField(data []byte, v any) string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return ""
+	}
+	path, _ := rejected(generic, reflect.TypeOf(v))
+	return path
+}
+
+// rejected walks val, as decoded with UseNumber, alongside type t and reports
+// whether a value in it is rejected by the UnmarshalJSON method of its type,
+// and the dotted path to that value.
+func rejected(val any, t reflect.Type) (string, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		data, err := json.Marshal(val)
+		if err != nil {
+			return "", false
+		}
+		return "", reflect.New(t).Interface().(json.Unmarshaler).UnmarshalJSON(data) != nil
+	}
+	join := func(key, path string) string {
+		if path == "" {
+			return key
+		}
+		return key + "." + path
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if arr, ok := val.([]any); ok {
+			for _, elem := range arr {
+				if path, ok := rejected(elem, t.Elem()); ok {
+					return path, true
+				}
+			}
+		}
+	case reflect.Map:
+		if m, ok := val.(map[string]any); ok {
+			for k, elem := range m {
+				if path, ok := rejected(elem, t.Elem()); ok {
+					return join(k, path), true
+				}
+			}
+		}
+	case reflect.Struct:
+		if m, ok := val.(map[string]any); ok {
+			fields := jsonFields(t)
+			for k, elem := range m {
+				ft, ok := fields[k]
+				if !ok {
+					ft, ok = fields[strings.ToLower(k)]
+				}
+				if !ok {
+					continue
+				}
+				if path, ok := rejected(elem, ft); ok {
+					return join(k, path), true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+var jsonFieldCache sync.Map // reflect.Type -> map[string]reflect.Type
+
+// jsonFields maps the JSON names of t's fields, exactly and lowercased, to
+// their types, including fields promoted from embedded structs.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	if cached, ok := jsonFieldCache.Load(t); ok {
+		return cached.(map[string]reflect.Type)
+	}
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, v := range jsonFields(ft) {
+					if _, ok := fields[k]; !ok {
+						fields[k] = v
+					}
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+		fields[strings.ToLower(name)] = f.Type
+	}
+	jsonFieldCache.Store(t, fields)
+	return fields
+}
@@ -0,0 +1,170 @@
+package semscholar_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestDecoders decodes the golden responses semscholartest embeds from its
+// golden directory through the endpoints they were recorded from, with the
+// default decoder and with one plugged in as an Unmarshaler.
+func TestDecoders(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		semscholartest.TestDecoders(t, nil)
+	})
+	t.Run("Unmarshaler", func(t *testing.T) {
+		semscholartest.TestDecoders(t, semscholar.UnmarshalFunc(json.Unmarshal))
+	})
+}
+
+// TestLenientCounts decodes counts sent as strings and as whole floats into
+// their NullInt fields, and checks that a count that is no number at all is
+// reported with its field.
+func TestLenientCounts(t *testing.T) {
+	for _, tc := range []struct {
+		body  string
+		want  semscholar.NullInt
+		field string
+	}{
+		{`{"paperId":"a","citationCount":"12"}`, semscholar.NewNullInt(12), ""},
+		{`{"paperId":"a","citationCount":12.0}`, semscholar.NewNullInt(12), ""},
+		{`{"paperId":"a","citationCount":" 7 "}`, semscholar.NewNullInt(7), ""},
+		{`{"paperId":"a","citationCount":null}`, semscholar.NullInt{}, ""},
+		{`{"paperId":"a","citationCount":0}`, semscholar.NewNullInt(0), ""},
+		{`{"paperId":"a","citationCount":"many"}`, semscholar.NullInt{}, "citationCount"},
+		{`{"paperId":"a","citationCount":1.5}`, semscholar.NullInt{}, "citationCount"},
+		{`{"paperId":"a","authors":[{"authorId":"1","hIndex":"h"}]}`, semscholar.NullInt{}, "authors.hIndex"},
+	} {
+		t.Run(tc.body, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tc.body))
+			}))
+			defer srv.Close()
+			p, err := semscholar.NewClient(srv.URL, srv.Client()).GetPaper("a", "citationCount")
+			if tc.field != "" {
+				var derr *semscholar.DecodeError
+				if !errors.As(err, &derr) || derr.Field != tc.field {
+					t.Fatalf("got error %v, want a DecodeError on field %q", err, tc.field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.CitationCount != tc.want {
+				t.Errorf("CitationCount = %+v, want %+v", p.CitationCount, tc.want)
+			}
+		})
+	}
+}
+
+// TestLenientInfluentialCounts decodes the influential citation counts of a
+// paper and an author sent as a string and as a whole float.
+func TestLenientInfluentialCounts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(r.URL.Path, "/author/") {
+			w.Write([]byte(`{"authorId":"1","influentialCitationCount":"3"}`))
+			return
+		}
+		w.Write([]byte(`{"paperId":"a","influentialCitationCount":2.0}`))
+	}))
+	defer srv.Close()
+	c := semscholar.NewClient(srv.URL, srv.Client())
+	p, err := c.GetPaper("a", "influentialCitationCount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.InfluentialCitationCount != semscholar.NewNullInt(2) {
+		t.Errorf("paper InfluentialCitationCount = %+v, want 2", p.InfluentialCitationCount)
+	}
+	a, err := c.GetAuthor("1", "influentialCitationCount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.InfluentialCitationCount != semscholar.NewNullInt(3) {
+		t.Errorf("author InfluentialCitationCount = %+v, want 3", a.InfluentialCitationCount)
+	}
+}
+
+// TestLenientDates decodes a batch in which one paper has a date that cannot
+// be read, which leaves that date null rather than failing the batch, and
+// checks that StrictDecoding reports the date's field instead.
+func TestLenientDates(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"paperId":"a","publicationDate":"2017-13-45"},{"paperId":"b","publicationDate":"2017-06"},{"paperId":"c","publicationDate":2017},{"paperId":"d","publicationDate":"Jan 2017"},null]`))
+	}))
+	defer srv.Close()
+	c := semscholar.NewClient(srv.URL, srv.Client())
+	papers, err := c.GetPapersBatch([]string{"a", "b", "c", "d", "e"}, "publicationDate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []semscholar.Date{{}, semscholar.NewDate(2017, 6, 0), semscholar.NewDate(2017, 0, 0), {}, {}}
+	if len(papers) != len(want) {
+		t.Fatalf("got %d papers, want %d", len(papers), len(want))
+	}
+	for i, p := range papers {
+		if p.PublicationDate != want[i] {
+			t.Errorf("paper %d PublicationDate = %v, want %v", i, p.PublicationDate, want[i])
+		}
+	}
+
+	c.StrictDecoding = true
+	_, err = c.GetPapersBatch([]string{"a", "b", "c", "d", "e"}, "publicationDate")
+	var derr *semscholar.DecodeError
+	if !errors.As(err, &derr) || derr.Field != "publicationDate" {
+		t.Errorf("strict decoding gave %v, want a DecodeError on field publicationDate", err)
+	}
+}
+
+// TestStrictDecoding checks that StrictDecoding rejects fields the types do
+// not declare and values of the wrong JSON type, both of which the default
+// lenient decoding accepts.
+func TestStrictDecoding(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		body    string
+		strict  bool
+		wantErr bool
+	}{
+		{"lenient unknown field", `{"paperId":"a","newField":1}`, false, false},
+		{"strict unknown field", `{"paperId":"a","newField":1}`, true, true},
+		{"lenient string count", `{"paperId":"a","citationCount":"12"}`, false, false},
+		{"strict string count", `{"paperId":"a","citationCount":"12"}`, true, true},
+		{"strict", `{"paperId":"a","citationCount":12}`, true, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tc.body))
+			}))
+			defer srv.Close()
+			client := semscholar.NewClient(srv.URL, srv.Client())
+			client.StrictDecoding = tc.strict
+			p, err := client.GetPaper("a", "citationCount")
+			if tc.wantErr {
+				var derr *semscholar.DecodeError
+				if !errors.As(err, &derr) {
+					t.Fatalf("got error %v, want a DecodeError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.PaperID != "a" {
+				t.Errorf("PaperID = %q, want a", p.PaperID)
+			}
+		})
+	}
+}
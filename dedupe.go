@@ -0,0 +1,241 @@
+package semscholar
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DedupeTitleThreshold is the TitleSimilarity at or above which two papers are treated as the same work.
+const DedupeTitleThreshold = 0.95
+
+var arxivVersion = regexp.MustCompile(`v\d+$`)
+
+// Dedupe collapses papers that describe the same work, which is common when
+// results from several searches are combined. Two records are duplicates if they
+// share a paperId, a DOI, an arXiv ID, or near-identical normalized titles with
+// publication years no more than one apart. DOIs are compared as NormalizeDOI
+// leaves them, ignoring case, so https://doi.org/10.1000/X matches 10.1000/x.
+// For each group a canonical record is kept (see SelectCanonical) and gaps in
+// it are filled from the others. Citation counts of distinct S2 records are
+// summed; repeated copies of the same record are counted once. The result
+// preserves the order in which each group was first seen.
+func Dedupe(papers []Paper) []Paper {
+	groups := DedupeGroups(papers)
+	out := make([]Paper, len(groups))
+	for i, g := range groups {
+		out[i] = g.Canonical
+	}
+	return out
+}
+
+// DedupeGroups is like Dedupe but returns every group with its members and the
+// rationale for the canonical choice, for callers that need to audit the merge.
+func DedupeGroups(papers []Paper) []DuplicateGroup {
+	indices := duplicateGroups(papers)
+	groups := make([]DuplicateGroup, len(indices))
+	for i, group := range indices {
+		members := make([]Paper, len(group))
+		for j, idx := range group {
+			members[j] = papers[idx]
+		}
+		choice := SelectCanonical(members)
+		groups[i] = DuplicateGroup{
+			Canonical: mergeDuplicates(members, choice.Index),
+			Members:   members,
+			Choice:    choice,
+		}
+	}
+	return groups
+}
+
+// duplicateGroups partitions the indices of papers into groups of duplicates,
+// ordered by the first index of each group.
+func duplicateGroups(papers []Paper) [][]int {
+	parent := make([]int, len(papers))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(a, b int) {
+		ra, rb := find(a), find(b)
+		if ra == rb {
+			return
+		}
+		if ra < rb {
+			parent[rb] = ra
+		} else {
+			parent[ra] = rb
+		}
+	}
+
+	byKey := make(map[string]int)
+	link := func(key string, i int) {
+		if j, ok := byKey[key]; ok {
+			union(i, j)
+			return
+		}
+		byKey[key] = i
+	}
+	blocks := make(map[string][]int)
+	titles := make([]string, len(papers))
+	for i, p := range papers {
+		if p.PaperID != "" {
+			link("s2:"+p.PaperID, i)
+		}
+		if doi, err := NormalizeDOI(p.ExternalIDs.DOI); err == nil {
+			link("doi:"+strings.ToLower(doi), i)
+		}
+		if arxiv := normalizeArXivID(p.ExternalIDs.ArXiv); arxiv != "" {
+			link("arxiv:"+arxiv, i)
+		}
+		titles[i] = NormalizeTitle(p.Title)
+		if key := blockKey(titles[i]); key != "" {
+			blocks[key] = append(blocks[key], i)
+		}
+	}
+	// Fuzzy title comparison is quadratic, so it only runs within blocks of
+	// titles that share a first word other than a stop word.
+	for _, block := range blocks {
+		for x := 0; x < len(block); x++ {
+			for y := x + 1; y < len(block); y++ {
+				i, j := block[x], block[y]
+				if find(i) == find(j) || !yearsCompatible(papers[i], papers[j]) {
+					continue
+				}
+				if titles[i] == titles[j] || TitleSimilarity(titles[i], titles[j]) >= DedupeTitleThreshold {
+					union(i, j)
+				}
+			}
+		}
+	}
+
+	index := make(map[int]int)
+	var groups [][]int
+	for i := range papers {
+		root := find(i)
+		g, ok := index[root]
+		if !ok {
+			g = len(groups)
+			index[root] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
+
+// blockStopWords are skipped in finding the word a title is blocked by, so
+// that "The Origin of Species" and "Origin of Species" are compared.
+var blockStopWords = map[string]bool{
+	"a":   true,
+	"an":  true,
+	"and": true,
+	"for": true,
+	"in":  true,
+	"of":  true,
+	"on":  true,
+	"the": true,
+	"to":  true,
+}
+
+// blockKey returns the first word of the normalized title that is not a stop
+// word, or its first word if all are.
+func blockKey(title string) string {
+	words := strings.Fields(title)
+	for _, w := range words {
+		if !blockStopWords[w] {
+			return w
+		}
+	}
+	if len(words) == 0 {
+		return ""
+	}
+	return words[0]
+}
+
+// mergeDuplicates folds a group of duplicate records into members[index].
+func mergeDuplicates(members []Paper, index int) Paper {
+	canonical := members[index]
+	for _, p := range members {
+		fillMissing(&canonical, p)
+	}
+	canonical.CitationCount = sumCounts(members, func(p Paper) NullInt { return p.CitationCount })
+	canonical.InfluentialCitationCount = sumCounts(members, func(p Paper) NullInt { return p.InfluentialCitationCount })
+	return canonical
+}
+
+// sumCounts adds up a count of the members of a group of duplicates, counting
+// each paper ID once, at its highest, and each record without an ID. It is
+// null if no member has the count.
+func sumCounts(members []Paper, count func(Paper) NullInt) NullInt {
+	counts := make(map[string]int)
+	var total NullInt
+	for _, p := range members {
+		n, ok := count(p).Get()
+		if !ok {
+			continue
+		}
+		total.Valid = true
+		if p.PaperID == "" {
+			total.Int += n
+			continue
+		}
+		counts[p.PaperID] = max(counts[p.PaperID], n)
+	}
+	for _, n := range counts {
+		total.Int += n
+	}
+	return total
+}
+
+// fillMissing copies descriptive fields from src into dst where dst has none.
+func fillMissing(dst *Paper, src Paper) {
+	if dst.Abstract == "" {
+		dst.Abstract = src.Abstract
+	}
+	if dst.URL == "" {
+		dst.URL = src.URL
+	}
+	if dst.Venue == "" {
+		dst.Venue = src.Venue
+	}
+	if dst.Year == 0 {
+		dst.Year = src.Year
+	}
+	if dst.PublicationDate.IsZero() {
+		dst.PublicationDate = src.PublicationDate
+	}
+	if len(dst.Authors) == 0 {
+		dst.Authors = src.Authors
+	}
+	if dst.ExternalIDs.DOI == "" {
+		dst.ExternalIDs.DOI = src.ExternalIDs.DOI
+	}
+	if dst.ExternalIDs.ArXiv == "" {
+		dst.ExternalIDs.ArXiv = src.ExternalIDs.ArXiv
+	}
+	if src.ReferenceCount.Valid && src.ReferenceCount.Int >= dst.ReferenceCount.Int {
+		dst.ReferenceCount = src.ReferenceCount
+	}
+}
+
+func yearsCompatible(a, b Paper) bool {
+	ya, yb := a.PublishedYear(), b.PublishedYear()
+	if ya == 0 || yb == 0 {
+		return true
+	}
+	return ya-yb <= 1 && yb-ya <= 1
+}
+
+// normalizeArXivID lowercases an arXiv identifier and drops its version suffix.
+func normalizeArXivID(id string) string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	id = strings.TrimPrefix(id, "arxiv:")
+	return arxivVersion.ReplaceAllString(id, "")
+}
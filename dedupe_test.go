@@ -0,0 +1,44 @@
+package semscholar_test
+
+import (
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// TestDedupeDOIForms checks that papers whose DOIs differ only in URL or
+// "doi:" prefix, case and surrounding space are merged.
+func TestDedupeDOIForms(t *testing.T) {
+	var papers []semscholar.Paper
+	for i, doi := range []string{"10.1000/ABC", "https://doi.org/10.1000/abc", " doi:10.1000/Abc ", "http://dx.doi.org/10.1000/abc"} {
+		p := semscholar.Paper{PaperID: string(rune('a' + i)), Title: "Title " + string(rune('a'+i))}
+		p.ExternalIDs.DOI = doi
+		papers = append(papers, p)
+	}
+	if got := semscholar.Dedupe(papers); len(got) != 1 {
+		t.Errorf("Dedupe kept %d papers, want 1", len(got))
+	}
+}
+
+// TestDedupeLeadingStopWords checks that titles differing only in a leading
+// article are compared and merged, while titles of different works sharing
+// one are not.
+func TestDedupeLeadingStopWords(t *testing.T) {
+	const title = "Unreasonable Effectiveness of Recurrent Neural Networks for Character-Level Language Modelling"
+	papers := []semscholar.Paper{
+		{PaperID: "a", Title: "The " + title, Year: 2015},
+		{PaperID: "b", Title: title, Year: 2015},
+		{PaperID: "c", Title: "A " + title, Year: 2016},
+		{PaperID: "d", Title: "The Origin of Species", Year: 1859},
+	}
+	groups := semscholar.DedupeGroups(papers)
+	if len(groups) != 2 {
+		t.Fatalf("Dedupe kept %d papers, want 2", len(groups))
+	}
+	if n := len(groups[0].Members); n != 3 {
+		t.Errorf("first group has %d members, want 3", n)
+	}
+	if id := groups[1].Canonical.PaperID; id != "d" {
+		t.Errorf("second group is %s, want d", id)
+	}
+}
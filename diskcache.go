@@ -0,0 +1,294 @@
+package semscholar
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// diskMagic prefixes every cache file so foreign or truncated files are ignored.
+var diskMagic = []byte("S2C1")
+
+// DiskCache is a Cache that stores each entry in its own file under a directory,
+// named by the SHA-256 of its key. Entries survive process restarts, so CLI runs
+// and batch jobs on one machine can reuse earlier responses. When the total size
+// exceeds the configured limit, the least recently used files are removed.
+// Several processes may share a directory.
+type DiskCache struct {
+	// OnEvict, if set, is called with the key of every entry this process
+	// removes and why. It runs without the cache's lock held. Set it before the
+	// cache is shared.
+	OnEvict func(key string, reason EvictReason)
+	// Clock, if set, replaces the system clock for expiry and recency. Set it
+	// before the cache is shared.
+	Clock Clock
+
+	dir      string
+	maxBytes int64
+
+	mu    sync.Mutex
+	size  int64
+	sized bool
+}
+
+// NewDiskCache creates a DiskCache in dir, creating it if necessary. maxBytes
+// bounds the total size of the cache files; zero means unbounded.
+func NewDiskCache(dir string, maxBytes int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir, maxBytes: maxBytes}, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, name[:2], name)
+}
+
+// Get implements Cache. Expired and corrupt entries are removed and reported as missing.
+func (c *DiskCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	storedKey, value, expires, ok := decodeDiskEntry(data)
+	if !ok || storedKey != key {
+		c.remove(path)
+		return nil, false, nil
+	}
+	now := clockOr(c.Clock).Now()
+	if !expires.IsZero() && now.After(expires) {
+		if c.remove(path) {
+			c.evicted(EvictExpired, key)
+		}
+		return nil, false, nil
+	}
+	_ = os.Chtimes(path, now, now)
+	return value, true, nil
+}
+
+// Set implements Cache.
+func (c *DiskCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	now := clockOr(c.Clock).Now()
+	var expires time.Time
+	if ttl > 0 {
+		expires = now.Add(ttl)
+	}
+	data := encodeDiskEntry(key, value, expires)
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	_ = os.Chtimes(tmp.Name(), now, now)
+	var old int64
+	if info, err := os.Stat(path); err == nil {
+		old = info.Size()
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	if !c.sized {
+		c.size = c.scanSize()
+		c.sized = true
+	} else {
+		c.size += int64(len(data)) - old
+	}
+	var dropped []string
+	if c.maxBytes > 0 && c.size > c.maxBytes {
+		dropped = c.evict()
+	}
+	c.mu.Unlock()
+	c.evicted(EvictCapacity, dropped...)
+	return nil
+}
+
+// Delete implements Cache.
+func (c *DiskCache) Delete(ctx context.Context, key string) error {
+	if c.remove(c.path(key)) {
+		c.evicted(EvictDeleted, key)
+	}
+	return nil
+}
+
+// DeletePrefix implements PrefixDeleter. Every file's key is read to find the
+// matches, so it takes time proportional to the number of entries.
+func (c *DiskCache) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	var dropped []string
+	for _, f := range c.files() {
+		if err := ctx.Err(); err != nil {
+			c.evicted(EvictDeleted, dropped...)
+			return len(dropped), err
+		}
+		key, ok := readDiskKey(f.path)
+		if ok && strings.HasPrefix(key, prefix) && c.remove(f.path) {
+			dropped = append(dropped, key)
+		}
+	}
+	c.evicted(EvictDeleted, dropped...)
+	return len(dropped), nil
+}
+
+// remove deletes the file at path and reports whether it did.
+func (c *DiskCache) remove(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if os.Remove(path) != nil {
+		return false
+	}
+	c.mu.Lock()
+	c.size -= info.Size()
+	c.mu.Unlock()
+	return true
+}
+
+func (c *DiskCache) evicted(reason EvictReason, keys ...string) {
+	if c.OnEvict == nil {
+		return
+	}
+	for _, key := range keys {
+		c.OnEvict(key, reason)
+	}
+}
+
+type diskFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func (c *DiskCache) files() []diskFile {
+	var files []diskFile
+	_ = filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files = append(files, diskFile{path: path, size: info.Size(), modTime: info.ModTime()})
+		}
+		return nil
+	})
+	return files
+}
+
+func (c *DiskCache) scanSize() int64 {
+	var total int64
+	for _, f := range c.files() {
+		total += f.size
+	}
+	return total
+}
+
+// evict removes the least recently used files until the cache is at 90% of its
+// limit, leaving headroom so eviction does not run on every write. c.mu is held.
+// The keys of removed files are returned when OnEvict needs them.
+func (c *DiskCache) evict() []string {
+	files := c.files()
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	target := c.maxBytes / 10 * 9
+	var dropped []string
+	for _, f := range files {
+		if total <= target {
+			break
+		}
+		var key string
+		if c.OnEvict != nil {
+			key, _ = readDiskKey(f.path)
+		}
+		if os.Remove(f.path) == nil {
+			total -= f.size
+			if key != "" {
+				dropped = append(dropped, key)
+			}
+		}
+	}
+	c.size = total
+	return dropped
+}
+
+// readDiskKey reads the key stored in the cache file at path without reading its value.
+func readDiskKey(path string) (string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	header := make([]byte, len(diskMagic)+12)
+	if _, err := io.ReadFull(f, header); err != nil || string(header[:len(diskMagic)]) != string(diskMagic) {
+		return "", false
+	}
+	n := binary.BigEndian.Uint32(header[len(diskMagic)+8:])
+	if n > 1<<16 {
+		return "", false
+	}
+	key := make([]byte, n)
+	if _, err := io.ReadFull(f, key); err != nil {
+		return "", false
+	}
+	return string(key), true
+}
+
+// encodeDiskEntry lays out an entry as magic, expiry (Unix nanoseconds, 0 for
+// never), key length, key and value.
+func encodeDiskEntry(key string, value []byte, expires time.Time) []byte {
+	buf := make([]byte, 0, len(diskMagic)+12+len(key)+len(value))
+	buf = append(buf, diskMagic...)
+	var exp int64
+	if !expires.IsZero() {
+		exp = expires.UnixNano()
+	}
+	buf = binary.BigEndian.AppendUint64(buf, uint64(exp))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(key)))
+	buf = append(buf, key...)
+	return append(buf, value...)
+}
+
+func decodeDiskEntry(data []byte) (key string, value []byte, expires time.Time, ok bool) {
+	if len(data) < len(diskMagic)+12 || string(data[:len(diskMagic)]) != string(diskMagic) {
+		return "", nil, time.Time{}, false
+	}
+	data = data[len(diskMagic):]
+	if exp := int64(binary.BigEndian.Uint64(data)); exp != 0 {
+		expires = time.Unix(0, exp)
+	}
+	n := binary.BigEndian.Uint32(data[8:])
+	data = data[12:]
+	if uint64(n) > uint64(len(data)) {
+		return "", nil, time.Time{}, false
+	}
+	return string(data[:n]), data[n:], expires, true
+}
@@ -0,0 +1,97 @@
+package semscholar_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestDiskCacheEviction checks that a DiskCache over its size limit removes
+// its least recently used entries, by the cache's clock, until it is back
+// under the limit.
+func TestDiskCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	clock := semscholartest.NewFakeClock(time.Time{})
+	value := bytes.Repeat([]byte("x"), 1000)
+	// Room for three entries of a little over 1000 bytes each.
+	c, err := semscholar.NewDiskCache(t.TempDir(), 3500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Clock = clock
+	var evicted []string
+	c.OnEvict = func(key string, reason semscholar.EvictReason) {
+		if reason == semscholar.EvictCapacity {
+			evicted = append(evicted, key)
+		}
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if err := c.Set(ctx, key, value, 0); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(time.Minute)
+	}
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatal("entry missing before the cache is full")
+	}
+	clock.Advance(time.Minute)
+	c.Set(ctx, "d", value, 0) // evicts b, the least recently used
+
+	if want := []string{"b"}; !slices.Equal(evicted, want) {
+		t.Errorf("evicted %v, want %v", evicted, want)
+	}
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true, "d": true} {
+		if _, ok, _ := c.Get(ctx, key); ok != want {
+			t.Errorf("Get(%s) found %v, want %v", key, ok, want)
+		}
+	}
+}
+
+// TestDiskCacheExpiry checks that DiskCache entries expire after their TTL,
+// are kept across instances on the same directory, and that corrupt files
+// read as misses.
+func TestDiskCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	clock := semscholartest.NewFakeClock(time.Time{})
+	dir := t.TempDir()
+	c, err := semscholar.NewDiskCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Clock = clock
+	c.Set(ctx, "short", []byte("1"), time.Minute)
+	c.Set(ctx, "forever", []byte("2"), 0)
+
+	reopened, err := semscholar.NewDiskCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reopened.Clock = clock
+	if v, ok, _ := reopened.Get(ctx, "short"); !ok || string(v) != "1" {
+		t.Errorf("Get(short) from another instance = %q, %v; want 1", v, ok)
+	}
+	clock.Advance(time.Minute + time.Second)
+	if _, ok, _ := reopened.Get(ctx, "short"); ok {
+		t.Error("entry found after its TTL")
+	}
+	if _, ok, _ := reopened.Get(ctx, "forever"); !ok {
+		t.Error("entry without a TTL expired")
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*"))
+	for _, f := range files {
+		if err := os.WriteFile(f, []byte("garbage"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok, err := reopened.Get(ctx, "forever"); ok || err != nil {
+		t.Errorf("Get of a corrupt entry = %v, %v; want a miss", ok, err)
+	}
+}
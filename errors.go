@@ -0,0 +1,127 @@
+package semscholar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound indicates that the requested entity does not exist.
+	ErrNotFound = errors.New("semscholar: not found")
+	// ErrRateLimited indicates that the API rejected the request with 429 Too Many Requests.
+	ErrRateLimited = errors.New("semscholar: rate limited")
+	// ErrBadRequest indicates that the API rejected the request as malformed,
+	// for example an unknown field name or an out-of-range offset.
+	ErrBadRequest = errors.New("semscholar: bad request")
+	// ErrForbidden indicates that the API refused the request with 401 or 403,
+	// usually because the API key is missing, invalid or lacks access.
+	ErrForbidden = errors.New("semscholar: forbidden")
+	// ErrServerError indicates that the API failed with a 5xx status.
+	ErrServerError = errors.New("semscholar: server error")
+)
+
+// APIError is returned when the API responds with an unexpected status code.
+type APIError struct {
+	Op         string
+	StatusCode int
+	Body       string
+	// RetryAfter is how long the API asked callers to wait, from the
+	// Retry-After header of a 429 or 503 response; zero if it gave none.
+	RetryAfter time.Duration
+	// RequestID is the ID the call was sent with; see RequestIDHeader.
+	RequestID string
+}
+
+// newAPIError builds the APIError for an unsuccessful response to op, keeping
+// the first maxErrorBody bytes of its body, which usually explain the failure.
+func (c *Client) newAPIError(op string, resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	apiErr := &APIError{
+		Op:         op,
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+		RetryAfter: retryAfter(resp, clockOr(c.Clock).Now()),
+	}
+	if resp.Request != nil {
+		apiErr.RequestID = resp.Request.Header.Get(RequestIDHeader)
+	}
+	return apiErr
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s: unexpected status code %d", e.Op, e.StatusCode)
+	if e.RequestID != "" {
+		msg += " (request " + e.RequestID + ")"
+	}
+	if e.Body != "" {
+		msg += ", body: " + e.Body
+	}
+	return msg
+}
+
+// Is reports whether the error matches one of the package's sentinel errors,
+// so callers can branch on the class of failure with errors.Is.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrForbidden:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrServerError:
+		return e.StatusCode >= 500 && e.StatusCode <= 599
+	}
+	return false
+}
+
+// IsThrottle reports whether err is the API rejecting a call with 429 Too Many
+// Requests. Throttled calls are retryable, after the APIError's RetryAfter if
+// it gives one.
+func IsThrottle(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsRetryable reports whether repeating the call that failed with err may
+// succeed: a response with a status the client's RetryPolicy retries (429,
+// 502, 503 or 504), an open circuit, or a network failure such as a reset
+// connection, a timeout or a body cut off mid-response. Errors from the
+// caller's own context are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return retryable(apiErr.StatusCode)
+	}
+	if errors.Is(err, ErrCircuitOpen) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// IsPermanent reports whether err will recur however often the call is
+// repeated: a 4xx response other than 408 and 429, such as a bad field name,
+// a missing entity or a rejected API key, or a response that could not be
+// decoded or failed validation. Errors that are neither permanent nor
+// retryable, such as a 500, may or may not clear up.
+func IsPermanent(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		s := apiErr.StatusCode
+		return s >= 400 && s < 500 && s != http.StatusRequestTimeout && s != http.StatusTooManyRequests
+	}
+	var decodeErr *DecodeError
+	return errors.As(err, &decodeErr) || errors.Is(err, ErrInvalidResponse)
+}
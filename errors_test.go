@@ -0,0 +1,106 @@
+package semscholar_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestSentinelErrors checks that errors.Is matches each status to its
+// sentinel error and no other, and that errors.As finds the APIError through
+// wrapping.
+func TestSentinelErrors(t *testing.T) {
+	sentinels := []error{
+		semscholar.ErrNotFound,
+		semscholar.ErrRateLimited,
+		semscholar.ErrBadRequest,
+		semscholar.ErrForbidden,
+		semscholar.ErrServerError,
+	}
+	for _, tt := range []struct {
+		status int
+		want   error
+	}{
+		{http.StatusNotFound, semscholar.ErrNotFound},
+		{http.StatusTooManyRequests, semscholar.ErrRateLimited},
+		{http.StatusBadRequest, semscholar.ErrBadRequest},
+		{http.StatusUnauthorized, semscholar.ErrForbidden},
+		{http.StatusForbidden, semscholar.ErrForbidden},
+		{http.StatusInternalServerError, semscholar.ErrServerError},
+		{http.StatusServiceUnavailable, semscholar.ErrServerError},
+		{http.StatusConflict, nil},
+	} {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv := semscholartest.NewServer(nil)
+			defer srv.Close()
+			srv.Inject(semscholartest.Fault{Status: tt.status})
+			_, err := srv.Client().GetPaper(semscholartest.BERTPaperID, "title")
+			if err == nil {
+				t.Fatal("GetPaper succeeded against a failing server")
+			}
+			err = fmt.Errorf("wrapped: %w", err)
+			for _, s := range sentinels {
+				if got := errors.Is(err, s); got != (s == tt.want) {
+					t.Errorf("errors.Is(err, %v) = %v, want %v", s, got, !got)
+				}
+			}
+			var apiErr *semscholar.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("errors.As found no APIError in %v", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Op == "" {
+				t.Errorf("APIError = %+v, want status %d and an Op", apiErr, tt.status)
+			}
+		})
+	}
+}
+
+// TestErrorClassification checks how IsRetryable, IsPermanent and
+// IsThrottle classify each kind of failure, wrapped or not.
+func TestErrorClassification(t *testing.T) {
+	status := func(code int) error { return &semscholar.APIError{Op: "GetPaper", StatusCode: code} }
+	for _, tt := range []struct {
+		name                           string
+		err                            error
+		retryable, permanent, throttle bool
+	}{
+		{"nil", nil, false, false, false},
+		{"400", status(http.StatusBadRequest), false, true, false},
+		{"401", status(http.StatusUnauthorized), false, true, false},
+		{"404", status(http.StatusNotFound), false, true, false},
+		{"408", status(http.StatusRequestTimeout), false, false, false},
+		{"429", status(http.StatusTooManyRequests), true, false, true},
+		{"wrapped 429", fmt.Errorf("crawl: %w", status(http.StatusTooManyRequests)), true, false, true},
+		{"500", status(http.StatusInternalServerError), false, false, false},
+		{"502", status(http.StatusBadGateway), true, false, false},
+		{"503", status(http.StatusServiceUnavailable), true, false, false},
+		{"504", status(http.StatusGatewayTimeout), true, false, false},
+		{"circuit open", semscholar.ErrCircuitOpen, true, false, false},
+		{"body cut off", fmt.Errorf("reading: %w", io.ErrUnexpectedEOF), true, false, false},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true, false, false},
+		{"canceled", context.Canceled, false, false, false},
+		{"deadline", fmt.Errorf("GetPaper: %w", context.DeadlineExceeded), false, false, false},
+		{"decode", &semscholar.DecodeError{Err: errors.New("bad JSON")}, false, true, false},
+		{"invalid", &semscholar.ValidationError{Op: "GetPaper", Diagnostics: []semscholar.Diagnostic{{Path: "paperId", Problem: "missing"}}}, false, true, false},
+		{"other", errors.New("other"), false, false, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := semscholar.IsRetryable(tt.err); got != tt.retryable {
+				t.Errorf("IsRetryable = %v, want %v", got, tt.retryable)
+			}
+			if got := semscholar.IsPermanent(tt.err); got != tt.permanent {
+				t.Errorf("IsPermanent = %v, want %v", got, tt.permanent)
+			}
+			if got := semscholar.IsThrottle(tt.err); got != tt.throttle {
+				t.Errorf("IsThrottle = %v, want %v", got, tt.throttle)
+			}
+		})
+	}
+}
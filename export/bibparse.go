@@ -0,0 +1,275 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// BibEntry is an entry of a BibTeX file, as ReadBibTeX reads it. @string,
+// @preamble and @comment entries have their Type but no Key or Fields, and
+// the text between entries is kept as entries with no Type, so that a file
+// written back with WriteBibEntries loses nothing.
+type BibEntry struct {
+	// Type is the entry type, lower case, such as "article".
+	Type   string
+	Key    string
+	Fields []BibField
+	// Raw is the entry as read. WriteBibEntries writes it unchanged unless
+	// a field was set.
+	Raw     string
+	changed bool
+}
+
+// BibField is a field of a BibTeX entry. Value is as written, with its
+// braces or quotes, as in "{Attention Is All You Need}".
+type BibField struct {
+	Name  string
+	Value string
+}
+
+// Get returns the value of the field named name, without the braces or
+// quotes around it, or "" if the entry has no such field. Names are matched
+// ignoring case. Values that concatenate strings with # are returned as
+// written.
+func (e *BibEntry) Get(name string) string {
+	for _, f := range e.Fields {
+		if strings.EqualFold(f.Name, name) {
+			return unquoteBibValue(f.Value)
+		}
+	}
+	return ""
+}
+
+// Set sets the field named name to value, escaped for LaTeX, adding it
+// after the others if the entry has no such field.
+func (e *BibEntry) Set(name, value string) {
+	v := "{" + bibtexEscape(value) + "}"
+	e.changed = true
+	for i, f := range e.Fields {
+		if strings.EqualFold(f.Name, name) {
+			e.Fields[i].Value = v
+			return
+		}
+	}
+	e.Fields = append(e.Fields, BibField{strings.ToLower(name), v})
+}
+
+// String returns the entry as BibTeX: as read, or with one field per line
+// if a field was set.
+func (e *BibEntry) String() string {
+	if !e.changed {
+		return e.Raw
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "@%s{%s,\n", e.Type, e.Key)
+	for i, f := range e.Fields {
+		fmt.Fprintf(&b, "  %s = %s", f.Name, f.Value)
+		if i < len(e.Fields)-1 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// Reference returns the entry as a reference to resolve with a
+// semscholar.ReferenceMatcher: its title and authors, with LaTeX markup
+// stripped, year, DOI and arXiv ID.
+func (e *BibEntry) Reference() semscholar.Reference {
+	ref := semscholar.Reference{
+		Title: plainBibText(e.Get("title")),
+		DOI:   e.Get("doi"),
+	}
+	if a := plainBibText(e.Get("author")); a != "" {
+		for _, name := range strings.Split(a, " and ") {
+			ref.Authors = append(ref.Authors, strings.TrimSpace(name))
+		}
+	}
+	if y, err := strconv.Atoi(strings.TrimSpace(e.Get("year"))); err == nil {
+		ref.Year = y
+	}
+	if strings.EqualFold(e.Get("archiveprefix"), "arxiv") || strings.EqualFold(e.Get("eprinttype"), "arxiv") {
+		ref.ArXivID = e.Get("eprint")
+	}
+	return ref
+}
+
+// ReadBibTeX reads the entries of the BibTeX file r.
+func ReadBibTeX(r io.Reader) ([]*BibEntry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &bibParser{src: string(data)}
+	var entries []*BibEntry
+	for p.pos < len(p.src) {
+		at := strings.IndexByte(p.src[p.pos:], '@')
+		if at < 0 {
+			entries = append(entries, &BibEntry{Raw: p.src[p.pos:]})
+			break
+		}
+		if at > 0 {
+			entries = append(entries, &BibEntry{Raw: p.src[p.pos : p.pos+at]})
+		}
+		p.pos += at
+		e, err := p.entry()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// WriteBibEntries writes entries to w, as ReadBibTeX read them but for the
+// fields set since.
+func WriteBibEntries(w io.Writer, entries []*BibEntry) error {
+	for _, e := range entries {
+		if _, err := io.WriteString(w, e.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type bibParser struct {
+	src string
+	pos int
+}
+
+// errorf returns an error at the line of the parser's position.
+func (p *bibParser) errorf(format string, args ...any) error {
+	line := 1 + strings.Count(p.src[:p.pos], "\n")
+	return fmt.Errorf("bibtex: line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *bibParser) skipSpace() {
+	for p.pos < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// ident reads a name: an entry type, key or field name.
+func (p *bibParser) ident(stop string) string {
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune(stop, rune(p.src[p.pos])) && strings.IndexByte(" \t\r\n", p.src[p.pos]) < 0 {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// entry reads the entry at the parser's position, an @.
+func (p *bibParser) entry() (*BibEntry, error) {
+	start := p.pos
+	p.pos++
+	p.skipSpace()
+	e := &BibEntry{Type: strings.ToLower(p.ident("{("))}
+	p.skipSpace()
+	if p.pos == len(p.src) || (p.src[p.pos] != '{' && p.src[p.pos] != '(') {
+		return nil, p.errorf("@%s not followed by { or (", e.Type)
+	}
+	closing := byte('}')
+	if p.src[p.pos] == '(' {
+		closing = ')'
+	}
+	p.pos++
+
+	switch e.Type {
+	case "comment", "preamble", "string":
+		// Their bodies are kept in Raw only.
+		if err := p.value(closing); err != nil {
+			return nil, err
+		}
+		if p.pos == len(p.src) || p.src[p.pos] != closing {
+			return nil, p.errorf("@%s not closed", e.Type)
+		}
+		p.pos++
+		e.Raw = p.src[start:p.pos]
+		return e, nil
+	}
+
+	p.skipSpace()
+	e.Key = p.ident(",}")
+	p.skipSpace()
+	for {
+		if p.pos == len(p.src) {
+			return nil, p.errorf("entry %q not closed", e.Key)
+		}
+		if p.src[p.pos] == closing {
+			p.pos++
+			break
+		}
+		if p.src[p.pos] == ',' {
+			p.pos++
+			p.skipSpace()
+			continue
+		}
+		name := p.ident("=,}" + string(closing))
+		p.skipSpace()
+		if name == "" || p.pos == len(p.src) || p.src[p.pos] != '=' {
+			return nil, p.errorf("entry %q: want a field name and =", e.Key)
+		}
+		p.pos++
+		p.skipSpace()
+		vstart := p.pos
+		if err := p.value(closing); err != nil {
+			return nil, err
+		}
+		e.Fields = append(e.Fields, BibField{strings.ToLower(name), strings.TrimSpace(p.src[vstart:p.pos])})
+	}
+	e.Raw = p.src[start:p.pos]
+	return e, nil
+}
+
+// value reads up to the comma ending a field value, or the closing
+// delimiter of the entry, outside braces and quotes.
+func (p *bibParser) value(closing byte) error {
+	depth, quoted := 0, false
+	for ; p.pos < len(p.src); p.pos++ {
+		switch c := p.src[p.pos]; {
+		case c == '\\':
+			p.pos++
+		case c == '{':
+			depth++
+		case c == '}' && depth > 0:
+			depth--
+		case c == '"' && depth == 0:
+			quoted = !quoted
+		case depth == 0 && !quoted && (c == ',' || c == closing):
+			return nil
+		}
+	}
+	return p.errorf("value not closed")
+}
+
+// unquoteBibValue strips the braces or quotes around v, if they enclose it
+// whole.
+func unquoteBibValue(v string) string {
+	if len(v) >= 2 && (v[0] == '{' && v[len(v)-1] == '}' || v[0] == '"' && v[len(v)-1] == '"') {
+		depth := 0
+		for i := 1; i < len(v)-1; i++ {
+			switch v[i] {
+			case '{':
+				depth++
+			case '}':
+				if depth--; depth < 0 && v[0] == '{' {
+					return v // as in "{a} # {b}"
+				}
+			}
+		}
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// plainBibText strips the braces and escapes of LaTeX from s and collapses
+// its whitespace.
+func plainBibText(s string) string {
+	s = strings.NewReplacer("{", "", "}", "", `\&`, "&", `\%`, "%", `\$`, "$", `\#`, "#", `\_`, "_", "~", " ").Replace(s)
+	return strings.Join(strings.Fields(s), " ")
+}
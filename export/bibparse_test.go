@@ -0,0 +1,161 @@
+package export_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/export"
+)
+
+// bibFile has nested braces, quoted values, escapes, @string macros used
+// bare and concatenated, @comment and @preamble entries, and text between
+// entries, in brace- and parenthesis-delimited entries.
+const bibFile = `% Bibliography of the thesis.
+@string{nips = "Advances in Neural Information Processing Systems"}
+@preamble{"\newcommand{\noop}[1]{}"}
+
+@article{vaswani2017attention,
+  title = {Attention Is {All} You {Need, {Really}}},
+  author = "Vaswani, Ashish and Shazeer, Noam",
+  journal = nips,
+  booktitle = nips # " 30",
+  year = 2017,
+  note = {100\% \{braces\} \& more},
+}
+
+@comment{an entry kept out: @misc{x, title = {X}}}
+
+@misc(devlin2019bert,
+  title = {{BERT}: Pre-training of Deep Bidirectional Transformers},
+  author = {Devlin, Jacob and Chang, Ming{-}Wei},
+  eprint = {1810.04805}, archivePrefix = {arXiv}
+)
+trailing text
+`
+
+// TestReadBibTeX checks the entries and fields ReadBibTeX reads.
+func TestReadBibTeX(t *testing.T) {
+	entries, err := export.ReadBibTeX(strings.NewReader(bibFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types, keys []string
+	for _, e := range entries {
+		types = append(types, e.Type)
+		keys = append(keys, e.Key)
+	}
+	wantTypes := []string{"", "string", "", "preamble", "", "article", "", "comment", "", "misc", ""}
+	if !slices.Equal(types, wantTypes) {
+		t.Fatalf("types %q, want %q", types, wantTypes)
+	}
+	if want := []string{"", "", "", "", "", "vaswani2017attention", "", "", "", "devlin2019bert", ""}; !slices.Equal(keys, want) {
+		t.Errorf("keys %q, want %q", keys, want)
+	}
+	if s := entries[1]; s.Fields != nil || s.Raw != `@string{nips = "Advances in Neural Information Processing Systems"}` {
+		t.Errorf("@string entry = %+v, want only its Raw", s)
+	}
+	if c := entries[7]; c.Raw != "@comment{an entry kept out: @misc{x, title = {X}}}" {
+		t.Errorf("@comment Raw = %q", c.Raw)
+	}
+
+	a := entries[5]
+	for _, tt := range []struct{ field, want string }{
+		{"title", "Attention Is {All} You {Need, {Really}}"},
+		{"TITLE", "Attention Is {All} You {Need, {Really}}"},
+		{"author", "Vaswani, Ashish and Shazeer, Noam"},
+		// Macros are not expanded; they are returned as written.
+		{"journal", "nips"},
+		{"booktitle", `nips # " 30"`},
+		{"year", "2017"},
+		{"note", `100\% \{braces\} \& more`},
+		{"doi", ""},
+	} {
+		if got := a.Get(tt.field); got != tt.want {
+			t.Errorf("Get(%q) = %q, want %q", tt.field, got, tt.want)
+		}
+	}
+	b := entries[9]
+	if got := b.Get("archiveprefix"); got != "arXiv" {
+		t.Errorf("Get(archiveprefix) = %q, want arXiv", got)
+	}
+	if b.Raw[len(b.Raw)-1] != ')' {
+		t.Errorf("entry in parentheses ends %q, want )", b.Raw[len(b.Raw)-1:])
+	}
+}
+
+// TestWriteBibEntries checks that a file is written back as read, and that
+// setting a field rewrites only its entry.
+func TestWriteBibEntries(t *testing.T) {
+	entries, err := export.ReadBibTeX(strings.NewReader(bibFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := export.WriteBibEntries(&b, entries); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != bibFile {
+		t.Errorf("written back as:\n%s\nwant:\n%s", b.String(), bibFile)
+	}
+
+	entries[9].Set("doi", "10.18653/v1/N19-1423")
+	entries[9].Set("Title", "BERT & friends")
+	b.Reset()
+	if err := export.WriteBibEntries(&b, entries); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(bibFile, `@misc(devlin2019bert,
+  title = {{BERT}: Pre-training of Deep Bidirectional Transformers},
+  author = {Devlin, Jacob and Chang, Ming{-}Wei},
+  eprint = {1810.04805}, archivePrefix = {arXiv}
+)`, `@misc{devlin2019bert,
+  title = {BERT \& friends},
+  author = {Devlin, Jacob and Chang, Ming{-}Wei},
+  eprint = {1810.04805},
+  archiveprefix = {arXiv},
+  doi = {10.18653/v1/N19-1423}
+}`, 1)
+	if b.String() != want {
+		t.Errorf("written with fields set as:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+// TestBibEntryReference checks the reference an entry is resolved by, with
+// LaTeX markup stripped.
+func TestBibEntryReference(t *testing.T) {
+	entries, err := export.ReadBibTeX(strings.NewReader(bibFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := entries[9].Reference()
+	want := semscholar.Reference{
+		Title:   "BERT: Pre-training of Deep Bidirectional Transformers",
+		Authors: []string{"Devlin, Jacob", "Chang, Ming-Wei"},
+		ArXivID: "1810.04805",
+	}
+	if got.Title != want.Title || !slices.Equal(got.Authors, want.Authors) || got.ArXivID != want.ArXivID || got.Year != 0 {
+		t.Errorf("Reference = %+v, want %+v", got, want)
+	}
+	if y := entries[5].Reference().Year; y != 2017 {
+		t.Errorf("Reference year = %d, want 2017", y)
+	}
+}
+
+// TestReadBibTeXErrors checks that malformed entries are reported with their
+// line.
+func TestReadBibTeXErrors(t *testing.T) {
+	for _, tt := range []struct{ src, want string }{
+		{"@article", "line 1: @article not followed by { or ("},
+		{"\n@article{a, title = {open", "line 2: value not closed"},
+		{"@article{a, title}", `line 1: entry "a": want a field name and =`},
+		{"@article{a, title = {T},", `line 1: entry "a" not closed`},
+		{"@comment{open", "line 1: value not closed"},
+	} {
+		_, err := export.ReadBibTeX(strings.NewReader(tt.src))
+		if err == nil || !strings.HasSuffix(err.Error(), tt.want) {
+			t.Errorf("ReadBibTeX(%q) = %v, want an error ending %q", tt.src, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package export
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// EnrichableFields are the BibTeX fields EnrichBibTeX can fill in, and
+// DefaultEnrichFields those it fills in without EnrichOptions.Fields.
+var (
+	EnrichableFields    = []string{"doi", "url", "abstract", "year", "eprint"}
+	DefaultEnrichFields = []string{"doi", "url", "abstract"}
+)
+
+// enrichPaperFields are the fields of the papers entries are enriched from.
+const enrichPaperFields = "title,externalIds,url,abstract,year,publicationDate"
+
+// EnrichOptions configure EnrichBibTeX.
+type EnrichOptions struct {
+	// Fields are the fields filled in where entries lack them, of
+	// EnrichableFields; nil means DefaultEnrichFields. The arXiv ID is
+	// filled in as eprint, with archiveprefix.
+	Fields []string
+	// Threshold is the least score of a title match; zero means
+	// semscholar.DefaultMatchThreshold.
+	Threshold float64
+}
+
+// BibUpdate is what EnrichBibTeX found of an entry and did to it.
+type BibUpdate struct {
+	Key string `json:"key"`
+	// PaperID is the paper the entry resolved to, if any, Method how, as a
+	// semscholar.ReferenceMatcher reports it, and Score how well.
+	PaperID string  `json:"paperId,omitempty"`
+	Method  string  `json:"method,omitempty"`
+	Score   float64 `json:"score,omitempty"`
+	// Added are the fields filled in.
+	Added []string `json:"added,omitempty"`
+	// Retracted is set if the entry or its paper is titled as retracted, or
+	// as a retraction notice.
+	Retracted bool `json:"retracted,omitempty"`
+	// DuplicateOf is the key of an earlier entry of the same paper or DOI.
+	DuplicateOf string `json:"duplicateOf,omitempty"`
+	// Error is why the entry did not resolve.
+	Error string `json:"error,omitempty"`
+}
+
+// retractedTitle matches the titles publishers give retracted papers and
+// retraction notices. The API has no retraction status, so these are
+// flagged by title.
+var retractedTitle = regexp.MustCompile(`(?i)^\s*(retracted(\s+article)?|retraction(\s+notice|\s+note)?(\s+(to|of|for))?|withdrawn)\b\s*[:.-]?`)
+
+// EnrichBibTeX resolves the entries of a BibTeX file against Semantic
+// Scholar, by DOI, arXiv ID or title, and fills in the fields of
+// opts.Fields that they lack; fields already set are never changed. It
+// returns an update for each entry with a key, in order, flagging those
+// retracted and those duplicating an earlier entry. The error is only
+// non-nil if ctx ends first.
+func EnrichBibTeX(ctx context.Context, c *semscholar.Client, entries []*BibEntry, opts EnrichOptions) ([]BibUpdate, error) {
+	fields := opts.Fields
+	if fields == nil {
+		fields = DefaultEnrichFields
+	}
+	var keyed []*BibEntry
+	var refs []semscholar.Reference
+	for _, e := range entries {
+		if e.Key != "" {
+			keyed = append(keyed, e)
+			refs = append(refs, e.Reference())
+		}
+	}
+	m := &semscholar.ReferenceMatcher{Client: c, Threshold: opts.Threshold, Fields: enrichPaperFields}
+	bib, err := m.Match(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make([]BibUpdate, len(keyed))
+	seen := make(map[string]string) // paper ID or DOI to the first key
+	for i, e := range keyed {
+		u := &updates[i]
+		u.Key = e.Key
+		u.Retracted = retractedTitle.MatchString(refs[i].Title)
+		r := bib.Entries[i]
+		if r.Paper == nil {
+			if r.Err != nil {
+				u.Error = r.Err.Error()
+			}
+		} else {
+			p := r.Paper
+			u.PaperID, u.Method, u.Score = p.PaperID, r.Method, r.Score
+			u.Retracted = u.Retracted || retractedTitle.MatchString(p.Title)
+			for _, f := range fields {
+				if e.Get(f) == "" {
+					if v := enrichValue(p, f); v != "" {
+						e.Set(f, v)
+						u.Added = append(u.Added, f)
+						if f == "eprint" && e.Get("archiveprefix") == "" {
+							e.Set("archiveprefix", "arXiv")
+						}
+					}
+				}
+			}
+		}
+		var ids []string
+		if u.PaperID != "" {
+			ids = append(ids, u.PaperID)
+		}
+		if doi, err := semscholar.NormalizeDOI(e.Get("doi")); err == nil {
+			ids = append(ids, "DOI:"+strings.ToLower(doi))
+		}
+		for _, id := range ids {
+			if first, ok := seen[id]; ok && u.DuplicateOf == "" {
+				u.DuplicateOf = first
+			}
+		}
+		for _, id := range ids {
+			if _, ok := seen[id]; !ok {
+				seen[id] = e.Key
+			}
+		}
+	}
+	return updates, nil
+}
+
+// enrichValue returns the value of the BibTeX field named field for p.
+func enrichValue(p *semscholar.Paper, field string) string {
+	switch field {
+	case "doi":
+		return p.ExternalIDs.DOI
+	case "url":
+		return p.URL
+	case "abstract":
+		return p.Abstract
+	case "year":
+		if y := p.PublishedYear(); y != 0 {
+			return strconv.Itoa(y)
+		}
+	case "eprint":
+		return p.ExternalIDs.ArXiv
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+package export_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/export"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// enrichFile has an entry resolving by DOI, one by title, a duplicate of the
+// first, a retraction notice and an entry that resolves to nothing.
+const enrichFile = `@string{naacl = "NAACL"}
+
+@inproceedings{devlin2019,
+  title = {{BERT}: Pre-training of Deep Bidirectional Transformers for Language Understanding},
+  doi = {10.18653/v1/N19-1423},
+  booktitle = naacl,
+}
+
+@inproceedings{he2016,
+  title = {Deep Residual Learning for Image Recognition},
+  author = {He, Kaiming},
+  url = {https://example.org/resnet},
+}
+
+@misc{bert-again, doi = {https://doi.org/10.18653/V1/N19-1423}, note = {kept as written}}
+
+@article{notice, title = {Retraction Notice: Not a Paper Anyone Wrote}}
+`
+
+// TestEnrichBibTeX checks the fields EnrichBibTeX fills in, the entries it
+// flags, and that only the entries it changes are rewritten.
+func TestEnrichBibTeX(t *testing.T) {
+	srv := semscholartest.NewServer(nil)
+	defer srv.Close()
+
+	entries, err := export.ReadBibTeX(strings.NewReader(enrichFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates, err := export.EnrichBibTeX(context.Background(), srv.Client(), entries, export.EnrichOptions{Fields: []string{"doi", "url", "eprint"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bert, resnet := semscholartest.BERTPaperID, semscholartest.ResNetPaperID
+	want := []export.BibUpdate{
+		{Key: "devlin2019", PaperID: bert, Method: "doi", Score: 1, Added: []string{"url", "eprint"}},
+		{Key: "he2016", PaperID: resnet, Method: "match", Score: 1, Added: []string{"doi", "eprint"}},
+		{Key: "bert-again", PaperID: bert, Method: "doi", Score: 1, Added: []string{"url", "eprint"}, DuplicateOf: "devlin2019"},
+		{Key: "notice", Retracted: true},
+	}
+	if updates[3].Error == "" {
+		t.Errorf("unresolved entry has no error")
+	}
+	updates[3].Error = ""
+	if !reflect.DeepEqual(updates, want) {
+		t.Errorf("updates = %+v, want %+v", updates, want)
+	}
+
+	var b strings.Builder
+	if err := export.WriteBibEntries(&b, entries); err != nil {
+		t.Fatal(err)
+	}
+	// The fields already set, the value given as a macro among them, are
+	// kept; the unchanged entry and the text between entries are as read.
+	wantFile := `@string{naacl = "NAACL"}
+
+@inproceedings{devlin2019,
+  title = {{BERT}: Pre-training of Deep Bidirectional Transformers for Language Understanding},
+  doi = {10.18653/v1/N19-1423},
+  booktitle = naacl,
+  url = {https://www.semanticscholar.org/paper/` + bert + `},
+  eprint = {1810.04805},
+  archiveprefix = {arXiv}
+}
+
+@inproceedings{he2016,
+  title = {Deep Residual Learning for Image Recognition},
+  author = {He, Kaiming},
+  url = {https://example.org/resnet},
+  doi = {10.1109/CVPR.2016.90},
+  eprint = {1512.03385},
+  archiveprefix = {arXiv}
+}
+
+@misc{bert-again,
+  doi = {https://doi.org/10.18653/V1/N19-1423},
+  note = {kept as written},
+  url = {https://www.semanticscholar.org/paper/` + bert + `},
+  eprint = {1810.04805},
+  archiveprefix = {arXiv}
+}
+
+@article{notice, title = {Retraction Notice: Not a Paper Anyone Wrote}}
+`
+	if b.String() != wantFile {
+		t.Errorf("enriched file:\n%s\nwant:\n%s", b.String(), wantFile)
+	}
+}
+
+// TestEnrichBibTeXDefaultFields checks that without EnrichOptions.Fields only
+// DefaultEnrichFields are filled in, and that an enriched file is left as it
+// is when enriched again.
+func TestEnrichBibTeXDefaultFields(t *testing.T) {
+	srv := semscholartest.NewServer(nil)
+	defer srv.Close()
+	entries, err := export.ReadBibTeX(strings.NewReader(enrichFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates, err := export.EnrichBibTeX(context.Background(), srv.Client(), entries, export.EnrichOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := updates[1].Added, []string{"doi", "abstract"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("added %q, want %q", got, want)
+	}
+	var first strings.Builder
+	if err := export.WriteBibEntries(&first, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err = export.ReadBibTeX(strings.NewReader(first.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates, err = export.EnrichBibTeX(context.Background(), srv.Client(), entries, export.EnrichOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range updates {
+		if u.Added != nil {
+			t.Errorf("%s: added %q enriching again", u.Key, u.Added)
+		}
+	}
+	var second strings.Builder
+	if err := export.WriteBibEntries(&second, entries); err != nil {
+		t.Fatal(err)
+	}
+	if second.String() != first.String() {
+		t.Errorf("enriching again changed the file:\n%s\nwant:\n%s", second.String(), first.String())
+	}
+}
@@ -0,0 +1,181 @@
+// Package export writes papers as bibliography files and notes for reference
+// managers and knowledge bases.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// stopwords are skipped when picking the title word of a citation key.
+var stopwords = map[string]bool{
+	"a": true, "an": true, "the": true, "on": true, "of": true, "in": true, "for": true, "and": true,
+	"to": true, "with": true, "from": true, "by": true, "is": true, "are": true, "towards": true, "toward": true,
+}
+
+// CitationKey returns the key p is cited by, in the style of Google Scholar:
+// the first author's family name, the year and the first significant word
+// of the title, folded to lowercase ASCII, as in "vaswani2017attention".
+// Missing parts are left out; a paper with none of them is keyed by its ID.
+func CitationKey(p semscholar.Paper) string {
+	var b strings.Builder
+	if len(p.Authors) > 0 {
+		_, family := splitName(p.Authors[0].Name)
+		b.WriteString(keyPart(family))
+	}
+	if y := p.PublishedYear(); y != 0 {
+		b.WriteString(strconv.Itoa(y))
+	}
+	for _, w := range strings.Fields(p.Title) {
+		if w = keyPart(w); w != "" && !stopwords[w] {
+			b.WriteString(w)
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return p.PaperID
+	}
+	return b.String()
+}
+
+// keyPart folds s to lowercase ASCII letters and digits.
+func keyPart(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if f, ok := folds[r]; ok {
+			b.WriteString(f)
+		} else if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// folds spells the accented letters common in author names in ASCII.
+var folds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ą': "a", 'æ': "ae",
+	'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e", 'ğ': "g",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ı': "i", 'ł': "l", 'ľ': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ő': "o", 'œ': "oe",
+	'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ß': "ss", 'ť': "t", 'ţ': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ů': "u", 'ű': "u", 'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// WriteBibTeX writes papers to w as BibTeX entries, keyed by CitationKey
+// with a letter appended to keys that repeat, as in "he2016deep" and
+// "he2016deepb". Papers with a venue become @article entries with the venue
+// as journal, and others @misc.
+func WriteBibTeX(w io.Writer, papers []semscholar.Paper) error {
+	keys := NewKeys()
+	for i, p := range papers {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, bibtexEntry(p, keys.Key(p))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Keys hands out citation keys that are unique among those it has handed
+// out, for writers of several entries.
+type Keys struct {
+	used map[string]bool
+}
+
+// NewKeys returns an empty set of keys.
+func NewKeys() *Keys {
+	return &Keys{used: make(map[string]bool)}
+}
+
+// Key returns the CitationKey of p, made unique by the first letter from
+// "b" to "z" that does so, or failing those a number.
+func (k *Keys) Key(p semscholar.Paper) string {
+	base := CitationKey(p)
+	key := base
+	for n := 1; k.used[key]; n++ {
+		if n < 26 {
+			key = base + string(rune('a'+n))
+		} else {
+			key = fmt.Sprintf("%s%d", base, n+1)
+		}
+	}
+	k.used[key] = true
+	return key
+}
+
+func bibtexEntry(p semscholar.Paper, key string) string {
+	var fields [][2]string
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, [2]string{name, value})
+		}
+	}
+	names := make([]string, len(p.Authors))
+	for i, a := range p.Authors {
+		names[i] = a.Name
+	}
+	if p.Title != "" {
+		// Double braces keep the title's capitalization from the style.
+		add("title", "{"+bibtexEscape(p.Title)+"}")
+	}
+	add("author", bibtexEscape(strings.Join(names, " and ")))
+	if y := p.PublishedYear(); y != 0 {
+		add("year", strconv.Itoa(y))
+	}
+	kind := "misc"
+	if p.Venue != "" {
+		kind = "article"
+		add("journal", bibtexEscape(p.Venue))
+	}
+	add("doi", p.ExternalIDs.DOI)
+	if p.ExternalIDs.ArXiv != "" {
+		add("eprint", p.ExternalIDs.ArXiv)
+		add("archiveprefix", "arXiv")
+	}
+	add("url", p.URL)
+	add("abstract", bibtexEscape(p.Abstract))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "@%s{%s,\n", kind, key)
+	for i, f := range fields {
+		fmt.Fprintf(&b, "  %s = {%s}", f[0], f[1])
+		if i < len(fields)-1 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// bibtexEscape escapes the characters LaTeX treats specially and collapses
+// whitespace, so text from the API survives a LaTeX run.
+func bibtexEscape(s string) string {
+	var b strings.Builder
+	for _, r := range strings.Join(strings.Fields(s), " ") {
+		switch r {
+		case '&', '%', '$', '#', '_', '{', '}':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '~':
+			b.WriteString(`\textasciitilde{}`)
+		case '^':
+			b.WriteString(`\textasciicircum{}`)
+		case '\\':
+			b.WriteString(`\textbackslash{}`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
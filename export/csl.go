@@ -0,0 +1,76 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// cslItem is an item of CSL JSON, the input format of citeproc processors
+// such as Pandoc's.
+type cslItem struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	Title          string    `json:"title,omitempty"`
+	Author         []cslName `json:"author,omitempty"`
+	Issued         *cslDate  `json:"issued,omitempty"`
+	ContainerTitle string    `json:"container-title,omitempty"`
+	DOI            string    `json:"DOI,omitempty"`
+	URL            string    `json:"URL,omitempty"`
+	Abstract       string    `json:"abstract,omitempty"`
+	Number         string    `json:"number,omitempty"`
+}
+
+type cslName struct {
+	Family string `json:"family,omitempty"`
+	Given  string `json:"given,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// WriteCSL writes papers to w as a CSL JSON array, with the same keys as
+// WriteBibTeX as item IDs. Papers with a venue become "article-journal"
+// items with the venue as container title, and others "article".
+func WriteCSL(w io.Writer, papers []semscholar.Paper) error {
+	keys := NewKeys()
+	items := make([]cslItem, len(papers))
+	for i, p := range papers {
+		items[i] = cslFor(p, keys.Key(p))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(items)
+}
+
+func cslFor(p semscholar.Paper, key string) cslItem {
+	item := cslItem{
+		ID:             key,
+		Type:           "article",
+		Title:          p.Title,
+		ContainerTitle: p.Venue,
+		DOI:            p.ExternalIDs.DOI,
+		URL:            p.URL,
+		Abstract:       p.Abstract,
+	}
+	if p.Venue != "" {
+		item.Type = "article-journal"
+	}
+	if p.ExternalIDs.ArXiv != "" {
+		item.Number = "arXiv:" + p.ExternalIDs.ArXiv
+	}
+	for _, a := range p.Authors {
+		given, family := splitName(a.Name)
+		item.Author = append(item.Author, cslName{Family: family, Given: given})
+	}
+	// Dates are given to the precision the API has them, from the year to
+	// the day.
+	if d := p.PublishedDate(); !d.IsZero() {
+		parts := []int{d.Year(), int(d.Time.Month()), d.Time.Day()}
+		item.Issued = &cslDate{DateParts: [][]int{parts[:d.Precision]}}
+	}
+	return item
+}
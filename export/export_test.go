@@ -0,0 +1,105 @@
+package export_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/export"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// bibPapers returns papers covering what the writers must handle: text with
+// the characters LaTeX treats specially, names of one, two and more words,
+// dates to the day and to the year, repeated keys, and missing fields.
+func bibPapers() []semscholar.Paper {
+	full := semscholar.Paper{
+		PaperID:         "p1",
+		Title:           "Sets {A} & {B}: 100% of   the    cases",
+		Abstract:        "Costs $5 #1 under_score ~ ^ \\ and\nnewlines.",
+		URL:             "https://example.org/p1",
+		Venue:           "Journal of R&D",
+		Year:            2019,
+		PublicationDate: semscholar.NewDate(2019, 6, 2),
+		Authors:         []semscholar.Author{{Name: "Ludwig van Beethoven"}, {Name: "José Müller"}, {Name: "Plato"}},
+	}
+	full.ExternalIDs.DOI = "10.1000/r&d.1"
+	preprint := semscholar.Paper{
+		PaperID: "p2",
+		Title:   "The sets of things",
+		Year:    2019,
+		Authors: []semscholar.Author{{Name: "Ludwig van Beethoven"}},
+	}
+	preprint.ExternalIDs.ArXiv = "1901.00001"
+	return []semscholar.Paper{
+		full,
+		preprint,
+		{PaperID: "p3", Title: "Sets again", Year: 2019, Authors: []semscholar.Author{{Name: "Anna Beethoven"}}},
+		{PaperID: "p4"},
+	}
+}
+
+// checkGolden compares got with the golden file testdata/name, rewriting
+// it instead with -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// TestWriters checks the BibTeX, RIS and CSL JSON of bibPapers against
+// their golden files.
+func TestWriters(t *testing.T) {
+	for _, tt := range []struct {
+		golden string
+		write  func(*bytes.Buffer, []semscholar.Paper) error
+	}{
+		{"papers.bib", func(b *bytes.Buffer, p []semscholar.Paper) error { return export.WriteBibTeX(b, p) }},
+		{"papers.ris", func(b *bytes.Buffer, p []semscholar.Paper) error { return export.WriteRIS(b, p) }},
+		{"papers.json", func(b *bytes.Buffer, p []semscholar.Paper) error { return export.WriteCSL(b, p) }},
+	} {
+		t.Run(tt.golden, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := tt.write(&b, bibPapers()); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, tt.golden, b.Bytes())
+		})
+	}
+}
+
+// TestCitationKey checks keys made of each combination of parts, folded to
+// ASCII, and the paper ID for papers with none.
+func TestCitationKey(t *testing.T) {
+	for _, tt := range []struct {
+		paper semscholar.Paper
+		want  string
+	}{
+		{semscholar.Paper{Title: "Attention is All you Need", Year: 2017, Authors: []semscholar.Author{{Name: "Ashish Vaswani"}}}, "vaswani2017attention"},
+		{semscholar.Paper{Title: "On the Origin of Species", Year: 1859, Authors: []semscholar.Author{{Name: "Charles Darwin"}}}, "darwin1859origin"},
+		{semscholar.Paper{Title: "Über Flächen", Year: 1900, Authors: []semscholar.Author{{Name: "Łukasz Gödel-Żak"}}}, "godelzak1900uber"},
+		{semscholar.Paper{Title: "Untimed", Authors: []semscholar.Author{{Name: "Plato"}}}, "platountimed"},
+		{semscholar.Paper{Year: 2020}, "2020"},
+		{semscholar.Paper{PaperID: "abc", Title: "The"}, "abc"},
+	} {
+		if got := export.CitationKey(tt.paper); got != tt.want {
+			t.Errorf("CitationKey(%q) = %q, want %q", tt.paper.Title, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package export
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// Vault directories notes are written to, under the root of a vault.
+const (
+	PapersDir  = "Papers"
+	AuthorsDir = "Authors"
+)
+
+// VaultOptions configure WriteVault.
+type VaultOptions struct {
+	// References are the papers each paper cites, by paper ID, as
+	// GetPaperReferencesSeq yields them; each is listed as a wikilink.
+	References map[string][]semscholar.Paper
+	// Tags are added to the tags of every paper note, after "paper".
+	Tags []string
+	// NoAuthors leaves out the author notes.
+	NoAuthors bool
+	// Overwrite replaces notes already in the vault, which are otherwise
+	// kept, with any notes added to them by hand.
+	Overwrite bool
+}
+
+// VaultResult counts the notes WriteVault wrote and those it kept.
+type VaultResult struct {
+	Papers  int `json:"papers"`
+	Authors int `json:"authors"`
+	Kept    int `json:"kept"`
+}
+
+// WriteVault writes a Markdown note for each of papers under dir/Papers, in
+// the layout of an Obsidian vault, which Notion also imports. A note has
+// the paper's metadata as YAML frontmatter, then its TLDR, abstract and
+// references; authors and references are wikilinks, by title, so that those
+// also in the vault are linked to their notes. Each author gets a note under
+// dir/Authors listing their papers in the vault.
+func WriteVault(dir string, papers []semscholar.Paper, opts VaultOptions) (VaultResult, error) {
+	var res VaultResult
+	names := newNoteNames()
+	for _, p := range papers {
+		names.name(p)
+	}
+	for _, dirName := range []string{PapersDir, AuthorsDir} {
+		if dirName == AuthorsDir && opts.NoAuthors {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Join(dir, dirName), 0o755); err != nil {
+			return res, err
+		}
+	}
+
+	keys := NewKeys()
+	authors := make(map[string][]string) // author note name to paper note names
+	var authorOrder []string
+	for _, p := range papers {
+		name := names.name(p)
+		note := paperNote(p, name, keys.Key(p), names, opts)
+		wrote, err := writeNote(filepath.Join(dir, PapersDir, name+".md"), note, opts.Overwrite)
+		if err != nil {
+			return res, err
+		}
+		if wrote {
+			res.Papers++
+		} else {
+			res.Kept++
+		}
+		for _, a := range p.Authors {
+			an := noteName(a.Name)
+			if an == "" {
+				continue
+			}
+			if _, ok := authors[an]; !ok {
+				authorOrder = append(authorOrder, an)
+			}
+			authors[an] = append(authors[an], name)
+		}
+	}
+	if opts.NoAuthors {
+		return res, nil
+	}
+	for _, an := range authorOrder {
+		var b bytes.Buffer
+		b.WriteString("---\ntags: [author]\n---\n")
+		fmt.Fprintf(&b, "# %s\n\n## Papers\n\n", an)
+		for _, pn := range authors[an] {
+			fmt.Fprintf(&b, "- [[%s]]\n", pn)
+		}
+		wrote, err := writeNote(filepath.Join(dir, AuthorsDir, an+".md"), b.Bytes(), opts.Overwrite)
+		if err != nil {
+			return res, err
+		}
+		if wrote {
+			res.Authors++
+		} else {
+			res.Kept++
+		}
+	}
+	return res, nil
+}
+
+// paperNote returns the note on p, named name and cited by key.
+func paperNote(p semscholar.Paper, name, key string, names *noteNames, opts VaultOptions) []byte {
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	yamlField(&b, "title", p.Title)
+	fmt.Fprintf(&b, "aliases: [%s]\n", strconv.Quote(key))
+	if len(p.Authors) > 0 {
+		b.WriteString("authors:\n")
+		for _, a := range p.Authors {
+			if l := wikilink(a.Name); l != "" {
+				fmt.Fprintf(&b, "  - %s\n", strconv.Quote(l))
+			}
+		}
+	}
+	if d := p.PublishedDate(); !d.IsZero() {
+		fmt.Fprintf(&b, "year: %d\n", d.Year())
+		yamlField(&b, "published", d.String())
+	}
+	yamlField(&b, "venue", p.Venue)
+	yamlField(&b, "doi", p.ExternalIDs.DOI)
+	yamlField(&b, "arxiv", p.ExternalIDs.ArXiv)
+	yamlField(&b, "s2id", p.PaperID)
+	yamlField(&b, "url", p.URL)
+	if n, ok := p.CitationCount.Get(); ok {
+		fmt.Fprintf(&b, "citations: %d\n", n)
+	}
+	if len(p.FieldsOfStudy) > 0 {
+		b.WriteString("fields:\n")
+		for _, f := range p.FieldsOfStudy {
+			fmt.Fprintf(&b, "  - %s\n", strconv.Quote(f))
+		}
+	}
+	b.WriteString("tags:\n  - \"paper\"\n")
+	for _, t := range opts.Tags {
+		fmt.Fprintf(&b, "  - %s\n", strconv.Quote(t))
+	}
+	b.WriteString("---\n")
+
+	fmt.Fprintf(&b, "# %s\n", cmp.Or(p.Title, name))
+	if len(p.Authors) > 0 {
+		links := make([]string, 0, len(p.Authors))
+		for _, a := range p.Authors {
+			if l := wikilink(a.Name); l != "" {
+				links = append(links, l)
+			}
+		}
+		fmt.Fprintf(&b, "\n%s\n", strings.Join(links, ", "))
+	}
+	if p.Tldr != nil && p.Tldr.Text != "" {
+		fmt.Fprintf(&b, "\n## TLDR\n\n%s\n", p.Tldr.Text)
+	}
+	if p.Abstract != "" {
+		fmt.Fprintf(&b, "\n## Abstract\n\n%s\n", p.Abstract)
+	}
+	if refs := opts.References[p.PaperID]; len(refs) > 0 {
+		b.WriteString("\n## References\n\n")
+		for _, r := range refs {
+			if n := names.name(r); n != "" {
+				fmt.Fprintf(&b, "- [[%s]]\n", n)
+			}
+		}
+	}
+	return b.Bytes()
+}
+
+// writeNote writes note to the file at path, unless it exists and not
+// overwrite, reporting whether it wrote it.
+func writeNote(path string, note []byte, overwrite bool) (bool, error) {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flags |= os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flags, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if _, err := f.Write(note); err != nil {
+		f.Close()
+		return false, err
+	}
+	return true, f.Close()
+}
+
+// yamlField writes a YAML string field, unless value is empty. Go's quoting
+// is valid as a YAML double-quoted string.
+func yamlField(b *bytes.Buffer, key, value string) {
+	if value != "" {
+		fmt.Fprintf(b, "%s: %s\n", key, strconv.Quote(value))
+	}
+}
+
+// wikilink returns a link to the note named for name, showing name itself
+// if the note name differs.
+func wikilink(name string) string {
+	switch n := noteName(name); n {
+	case "":
+		return ""
+	case name:
+		return "[[" + n + "]]"
+	default:
+		return "[[" + n + "|" + strings.NewReplacer("[", "", "]", "", "|", "").Replace(name) + "]]"
+	}
+}
+
+// maxNoteName bounds the length of note names in bytes, well within the
+// file name limits of common file systems.
+const maxNoteName = 120
+
+// noteName returns s made fit for a note name: without the characters file
+// systems or wikilinks do not allow, with runs of space collapsed, and cut
+// to maxNoteName bytes.
+func noteName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '*', '"', '\\', '/', '<', '>', ':', '|', '?', '#', '^', '[', ']':
+			return ' '
+		}
+		if r < ' ' {
+			return ' '
+		}
+		return r
+	}, s)
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > maxNoteName {
+		s = s[:maxNoteName]
+		for !utf8.ValidString(s) {
+			s = s[:len(s)-1]
+		}
+		s = strings.TrimSpace(s)
+	}
+	return strings.TrimLeft(s, ".")
+}
+
+// noteNames names the notes of papers by title, telling apart papers of the
+// same title by year and then paper ID.
+type noteNames struct {
+	byID  map[string]string
+	taken map[string]bool
+}
+
+func newNoteNames() *noteNames {
+	return &noteNames{byID: make(map[string]string), taken: make(map[string]bool)}
+}
+
+// name returns the note name of p, the same each time for one paper ID.
+func (n *noteNames) name(p semscholar.Paper) string {
+	if name, ok := n.byID[p.PaperID]; ok && p.PaperID != "" {
+		return name
+	}
+	base := cmp.Or(noteName(p.Title), noteName(p.PaperID))
+	name := base
+	if y := p.PublishedYear(); n.taken[name] && y != 0 {
+		name = fmt.Sprintf("%s (%d)", base, y)
+	}
+	if n.taken[name] && p.PaperID != "" {
+		name = base + " (" + p.PaperID[:min(8, len(p.PaperID))] + ")"
+	}
+	for i := 2; n.taken[name]; i++ {
+		name = base + " (" + strconv.Itoa(i) + ")"
+	}
+	if name != "" {
+		n.taken[name] = true
+	}
+	if p.PaperID != "" {
+		n.byID[p.PaperID] = name
+	}
+	return name
+}
@@ -0,0 +1,158 @@
+package export_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/export"
+)
+
+// vaultPapers returns two papers of the same title and a third whose title
+// and author's name have characters note names cannot hold.
+func vaultPapers() []semscholar.Paper {
+	attention := semscholar.Paper{
+		PaperID:       "204e3073870fae3d05bcbc2f6a8e263d9b72e776",
+		Title:         "Attention is All you Need",
+		Year:          2017,
+		Venue:         "NeurIPS",
+		Abstract:      "The dominant sequence transduction models...",
+		Tldr:          &semscholar.Tldr{Text: "Transformers."},
+		Authors:       []semscholar.Author{{Name: "Ashish Vaswani"}, {Name: "Noam Shazeer"}},
+		CitationCount: semscholar.NewNullInt(100),
+		FieldsOfStudy: []string{"Computer Science"},
+	}
+	attention.ExternalIDs.ArXiv = "1706.03762"
+	return []semscholar.Paper{
+		attention,
+		{PaperID: "b2", Title: "Attention is All you Need", Year: 2021, Authors: []semscholar.Author{{Name: "Ashish Vaswani"}}},
+		{PaperID: "c3", Title: "BERT: Pre-training [of] deep transformers?", Year: 2019, Authors: []semscholar.Author{{Name: "J. Devlin | Google"}}},
+	}
+}
+
+func readNote(t *testing.T, path ...string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(path...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// TestWriteVault checks the notes of papers and authors, the names of notes
+// of papers of the same title or with characters note names cannot hold,
+// and links to references in and out of the vault.
+func TestWriteVault(t *testing.T) {
+	dir := t.TempDir()
+	papers := vaultPapers()
+	refs := map[string][]semscholar.Paper{papers[0].PaperID: {papers[2], {PaperID: "x", Title: "Neural machine translation"}}}
+	res, err := export.WriteVault(dir, papers, export.VaultOptions{References: refs, Tags: []string{"to read"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (export.VaultResult{Papers: 3, Authors: 3}); res != want {
+		t.Errorf("WriteVault = %+v, want %+v", res, want)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, export.PapersDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	want := []string{"Attention is All you Need (2021).md", "Attention is All you Need.md", "BERT Pre-training of deep transformers.md"}
+	if !slices.Equal(names, want) {
+		t.Errorf("paper notes %q, want %q", names, want)
+	}
+
+	wantNote := `---
+title: "Attention is All you Need"
+aliases: ["vaswani2017attention"]
+authors:
+  - "[[Ashish Vaswani]]"
+  - "[[Noam Shazeer]]"
+year: 2017
+published: "2017"
+venue: "NeurIPS"
+arxiv: "1706.03762"
+s2id: "204e3073870fae3d05bcbc2f6a8e263d9b72e776"
+citations: 100
+fields:
+  - "Computer Science"
+tags:
+  - "paper"
+  - "to read"
+---
+# Attention is All you Need
+
+[[Ashish Vaswani]], [[Noam Shazeer]]
+
+## TLDR
+
+Transformers.
+
+## Abstract
+
+The dominant sequence transduction models...
+
+## References
+
+- [[BERT Pre-training of deep transformers]]
+- [[Neural machine translation]]
+`
+	if got := readNote(t, dir, export.PapersDir, "Attention is All you Need.md"); got != wantNote {
+		t.Errorf("paper note:\n%s\nwant:\n%s", got, wantNote)
+	}
+	if got := readNote(t, dir, export.PapersDir, "BERT Pre-training of deep transformers.md"); !strings.Contains(got, `"[[J. Devlin Google|J. Devlin  Google]]"`) {
+		t.Errorf("note with an author whose name cannot be a note name:\n%s", got)
+	}
+
+	wantAuthor := "---\ntags: [author]\n---\n# Ashish Vaswani\n\n## Papers\n\n- [[Attention is All you Need]]\n- [[Attention is All you Need (2021)]]\n"
+	if got := readNote(t, dir, export.AuthorsDir, "Ashish Vaswani.md"); got != wantAuthor {
+		t.Errorf("author note:\n%s\nwant:\n%s", got, wantAuthor)
+	}
+}
+
+// TestWriteVaultAgain checks that notes already in a vault, perhaps edited,
+// are kept unless Overwrite, and that NoAuthors writes no author notes.
+func TestWriteVaultAgain(t *testing.T) {
+	dir := t.TempDir()
+	papers := vaultPapers()[:1]
+	if _, err := export.WriteVault(dir, papers, export.VaultOptions{NoAuthors: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, export.AuthorsDir)); !os.IsNotExist(err) {
+		t.Errorf("NoAuthors made the authors directory: %v", err)
+	}
+	path := filepath.Join(dir, export.PapersDir, "Attention is All you Need.md")
+	if err := os.WriteFile(path, []byte("my notes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := export.WriteVault(dir, papers, export.VaultOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (export.VaultResult{Authors: 2, Kept: 1}); res != want {
+		t.Errorf("WriteVault again = %+v, want %+v", res, want)
+	}
+	if got := readNote(t, path); got != "my notes" {
+		t.Errorf("kept note %q, want it unchanged", got)
+	}
+
+	res, err = export.WriteVault(dir, papers, export.VaultOptions{Overwrite: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (export.VaultResult{Papers: 1, Authors: 2}); res != want {
+		t.Errorf("WriteVault overwriting = %+v, want %+v", res, want)
+	}
+	if got := readNote(t, path); got == "my notes" {
+		t.Error("Overwrite kept the edited note")
+	}
+}
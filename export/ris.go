@@ -0,0 +1,68 @@
+package export
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// WriteRIS writes papers to w as RIS records, the tagged format EndNote,
+// Zotero and Mendeley import, with the same keys as WriteBibTeX in the ID
+// tag. Papers with a venue become journal articles (JOUR) with the venue as
+// journal, and others generic records (GEN).
+func WriteRIS(w io.Writer, papers []semscholar.Paper) error {
+	keys := NewKeys()
+	for _, p := range papers {
+		if _, err := io.WriteString(w, risRecord(p, keys.Key(p))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func risRecord(p semscholar.Paper, key string) string {
+	var b strings.Builder
+	add := func(tag, value string) {
+		// A value spans one line; RIS has no way to continue it.
+		if value = strings.Join(strings.Fields(value), " "); value != "" {
+			b.WriteString(tag + "  - " + value + "\n")
+		}
+	}
+	kind := "GEN"
+	if p.Venue != "" {
+		kind = "JOUR"
+	}
+	add("TY", kind)
+	add("ID", key)
+	add("TI", p.Title)
+	for _, a := range p.Authors {
+		given, family := splitName(a.Name)
+		if given != "" {
+			family += ", " + given
+		}
+		add("AU", family)
+	}
+	if d := p.PublishedDate(); !d.IsZero() {
+		add("PY", strconv.Itoa(d.Year()))
+		if d.Precision == semscholar.DateDay {
+			add("DA", d.Time.Format("2006/01/02"))
+		}
+	}
+	add("JO", p.Venue)
+	add("DO", p.ExternalIDs.DOI)
+	add("UR", p.URL)
+	add("AB", p.Abstract)
+	b.WriteString("ER  - \n\n")
+	return b.String()
+}
+
+// splitName splits a name given as "Given Family" at its last space.
+func splitName(name string) (given, family string) {
+	words := strings.Fields(name)
+	if len(words) == 0 {
+		return "", ""
+	}
+	return strings.Join(words[:len(words)-1], " "), words[len(words)-1]
+}
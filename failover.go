@@ -0,0 +1,159 @@
+package semscholar
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Failover tracks the health of several base URLs serving the same API, such
+// as a caching proxy in front of the public API followed by the API itself. A
+// base URL that fails with a network error or a 5xx response is skipped for
+// the cooldown; after that it is tried again. It is safe for concurrent use.
+type Failover struct {
+	// Clock, if set, replaces the system clock for the cooldown. Set it
+	// before the failover is shared.
+	Clock Clock
+
+	mu       sync.Mutex
+	bases    []string
+	cooldown time.Duration
+	downAt   []time.Time
+}
+
+// NewFailover creates a Failover over baseURLs, in order of preference, that
+// skips a failing base URL for cooldown.
+func NewFailover(cooldown time.Duration, baseURLs ...string) *Failover {
+	bases := make([]string, len(baseURLs))
+	for i, b := range baseURLs {
+		bases[i] = strings.TrimSuffix(b, "/")
+	}
+	return &Failover{bases: bases, cooldown: cooldown, downAt: make([]time.Time, len(bases))}
+}
+
+// Healthy returns the base URLs currently considered healthy, in order of
+// preference.
+func (f *Failover) Healthy() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var healthy []string
+	for i, b := range f.bases {
+		if f.up(i) {
+			healthy = append(healthy, b)
+		}
+	}
+	return healthy
+}
+
+// candidates returns the indexes of the base URLs to try, healthy ones first.
+// Unhealthy ones follow, so that a request is still attempted when all are down.
+func (f *Failover) candidates() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order := make([]int, 0, len(f.bases))
+	for i := range f.bases {
+		if f.up(i) {
+			order = append(order, i)
+		}
+	}
+	for i := range f.bases {
+		if !f.up(i) {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// up reports whether base URL i is healthy. f.mu is held.
+func (f *Failover) up(i int) bool {
+	return f.downAt[i].IsZero() || clockOr(f.Clock).Now().Sub(f.downAt[i]) >= f.cooldown
+}
+
+// mark records the outcome of a request to base URL i.
+func (f *Failover) mark(i int, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if ok {
+		f.downAt[i] = time.Time{}
+	} else {
+		f.downAt[i] = clockOr(f.Clock).Now()
+	}
+}
+
+// failoverClient is an HTTPClient that sends requests to the healthiest of
+// several base URLs.
+type failoverClient struct {
+	next     HTTPClient
+	failover *Failover
+}
+
+// NewFailoverClient wraps next so that requests go to the base URLs of
+// failover, moving on to the next one when a base URL fails. The client's
+// BaseURL must be the first of them; requests to other URLs pass through
+// unchanged. The response of the last base URL tried is returned, whatever
+// its status.
+//
+//	f := semscholar.NewFailover(time.Minute, "https://s2-proxy.internal/graph/v1", "https://api.semanticscholar.org/graph/v1")
+//	client := semscholar.NewClient("https://s2-proxy.internal/graph/v1", semscholar.NewFailoverClient(http.DefaultClient, f))
+func NewFailoverClient(next HTTPClient, failover *Failover) HTTPClient {
+	return &failoverClient{next: next, failover: failover}
+}
+
+// Do implements HTTPClient.
+func (c *failoverClient) Do(req *http.Request) (*http.Response, error) {
+	if len(c.failover.bases) == 0 {
+		return c.next.Do(req)
+	}
+	primary := c.failover.bases[0]
+	rest, ok := strings.CutPrefix(req.URL.String(), primary)
+	if !ok {
+		return c.next.Do(req)
+	}
+	var resp *http.Response
+	var err error
+	for n, i := range c.failover.candidates() {
+		if n > 0 {
+			if req.Body != nil && req.GetBody == nil {
+				// The body cannot be sent again.
+				break
+			}
+			if resp != nil {
+				closeBody(resp.Body)
+			}
+		}
+		r, rerr := c.rebase(req, c.failover.bases[i]+rest, n > 0)
+		if rerr != nil {
+			return nil, rerr
+		}
+		resp, err = c.next.Do(r)
+		if err == nil && resp.StatusCode < 500 {
+			c.failover.mark(i, true)
+			return resp, nil
+		}
+		if req.Context().Err() != nil {
+			return resp, err
+		}
+		c.failover.mark(i, false)
+	}
+	return resp, err
+}
+
+// rebase returns a copy of req sent to target, with its body rewound when
+// it is being resent.
+func (c *failoverClient) rebase(req *http.Request, target string, resend bool) (*http.Request, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.URL = u
+	r.Host = ""
+	if resend && req.GetBody != nil {
+		if r.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,59 @@
+package semscholar_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestFailover checks that requests move to the secondary base URL while
+// the primary fails, POST bodies included, and return to the primary once
+// it has recovered and its cooldown has passed.
+func TestFailover(t *testing.T) {
+	primary := semscholartest.NewServer(nil)
+	defer primary.Close()
+	secondary := semscholartest.NewServer(nil)
+	defer secondary.Close()
+	clock := semscholartest.NewFakeClock(time.Time{})
+	f := semscholar.NewFailover(time.Minute, primary.URL, secondary.URL)
+	f.Clock = clock
+	client := semscholar.NewClient(primary.URL, semscholar.NewFailoverClient(http.DefaultClient, f))
+
+	primary.Inject(semscholartest.Fault{Status: http.StatusServiceUnavailable})
+	if p, err := client.GetPaper(semscholartest.BERTPaperID, "title"); err != nil || p.PaperID != semscholartest.BERTPaperID {
+		t.Fatalf("GetPaper with the primary down = %+v, %v, want BERT from the secondary", p, err)
+	}
+	if got := f.Healthy(); !slices.Equal(got, []string{secondary.URL}) {
+		t.Errorf("healthy %v, want only the secondary", got)
+	}
+	papers, err := client.GetPapersBatchContext(context.Background(), []string{semscholartest.ResNetPaperID}, "title")
+	if err != nil || len(papers) != 1 || papers[0].PaperID != semscholartest.ResNetPaperID {
+		t.Fatalf("GetPapersBatch with the primary down = %+v, %v, want ResNet", papers, err)
+	}
+	if n := len(primary.Requests()); n != 1 {
+		t.Errorf("%d requests to the primary, want 1 before it was skipped", n)
+	}
+	if n := len(secondary.Requests()); n != 2 {
+		t.Errorf("%d requests to the secondary, want 2", n)
+	}
+
+	primary.ClearFaults()
+	clock.Advance(time.Minute)
+	if _, err := client.GetPaper(semscholartest.BERTPaperID, "year"); err != nil {
+		t.Fatalf("GetPaper after the primary recovered = %v", err)
+	}
+	if n := len(primary.Requests()); n != 2 {
+		t.Errorf("%d requests to the primary, want it tried again after the cooldown", n)
+	}
+	if n := len(secondary.Requests()); n != 2 {
+		t.Errorf("%d requests to the secondary, want no more once the primary recovered", n)
+	}
+	if got := f.Healthy(); len(got) != 2 {
+		t.Errorf("healthy %v, want both", got)
+	}
+}
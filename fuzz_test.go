@@ -0,0 +1,79 @@
+package semscholar_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// The seed corpora in testdata/fuzz add the inputs that found bugs to the
+// seeds the harnesses carry, so go test replays them on every run. Fuzz with
+//
+//	go test -run '^$' -fuzz FuzzDecoders -fuzzminimizetime 0 .
+//	go test -run '^$' -fuzz FuzzRequests -fuzzminimizetime 0 .
+
+func FuzzDecoders(f *testing.F) {
+	semscholartest.FuzzDecoders(f, nil)
+}
+
+func FuzzRequests(f *testing.F) {
+	semscholartest.FuzzRequests(f)
+}
+
+// TestStalledPaging replays the pages in testdata/fuzz/FuzzDecoders that
+// point back at themselves, which made the iterators request the same page
+// forever. Each must now end with an error after the first page.
+func TestStalledPaging(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body string
+		seq  func(c *semscholar.Client) func(func(any, error) bool)
+	}{
+		{"BulkSearchPapersSeq", `{"token":"t","data":[{"paperId":"a"}]}`, func(c *semscholar.Client) func(func(any, error) bool) {
+			return erase(c.BulkSearchPapersSeq(context.Background(), "q", "", "", "", nil))
+		}},
+		{"GetPaperCitationsSeq", `{"offset":0,"next":5,"data":[{"citingPaper":{"paperId":"a"}}]}`, func(c *semscholar.Client) func(func(any, error) bool) {
+			return erase(c.GetPaperCitationsSeq(context.Background(), "a", ""))
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			requests := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tc.body))
+			}))
+			defer srv.Close()
+			var last error
+			items := 0
+			for _, err := range tc.seq(semscholar.NewClient(srv.URL, srv.Client())) {
+				if err != nil {
+					last = err
+					continue
+				}
+				if items++; items > 10 {
+					t.Fatalf("still iterating after %d items and %d requests", items, requests)
+				}
+			}
+			if last == nil || requests != 2 {
+				t.Errorf("ended after %d requests with error %v, want an error after 2", requests, last)
+			}
+		})
+	}
+}
+
+// erase turns an iterator of any value into one of any, so the cases of a
+// table can share a type.
+func erase[T any](seq func(func(T, error) bool)) func(func(any, error) bool) {
+	return func(yield func(any, error) bool) {
+		for v, err := range seq {
+			if !yield(v, err) {
+				return
+			}
+		}
+	}
+}
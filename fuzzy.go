@@ -0,0 +1,193 @@
+package semscholar
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// DefaultMatchThreshold is the minimum score a candidate needs to be accepted by a TitleResolver.
+const DefaultMatchThreshold = 0.85
+
+// TitleQuery describes a citation to be matched against Semantic Scholar by title.
+// Year and FirstAuthor are optional and only refine the score when set.
+type TitleQuery struct {
+	Title       string
+	Year        int
+	FirstAuthor string
+}
+
+// TitleMatch is the best candidate found for a TitleQuery together with its score in [0, 1].
+type TitleMatch struct {
+	Paper Paper
+	Score float64
+}
+
+// TitleResolver matches free-text titles against relevance search results.
+type TitleResolver struct {
+	Client *Client
+	// Threshold is the minimum score for a match; zero means DefaultMatchThreshold.
+	Threshold float64
+	// Candidates is how many search results are scored; zero means 10.
+	Candidates int
+	// Fields is requested for each candidate; "title", "year", "publicationDate" and "authors" are always added.
+	Fields string
+}
+
+// NewTitleResolver creates a TitleResolver with default settings.
+func NewTitleResolver(c *Client) *TitleResolver {
+	return &TitleResolver{Client: c}
+}
+
+// Resolve searches for q.Title and returns the highest scoring candidate.
+// It returns ErrNotFound if no candidate reaches the resolver's threshold.
+func (r *TitleResolver) Resolve(ctx context.Context, q TitleQuery) (*TitleMatch, error) {
+	threshold := r.Threshold
+	if threshold == 0 {
+		threshold = DefaultMatchThreshold
+	}
+	limit := r.Candidates
+	if limit == 0 {
+		limit = 10
+	}
+	fields := "title,year,publicationDate,authors"
+	if r.Fields != "" {
+		fields += "," + r.Fields
+	}
+	result, err := r.Client.SearchPapersContext(ctx, q.Title, 0, limit, fields, nil)
+	if err != nil {
+		return nil, err
+	}
+	var best *TitleMatch
+	for _, p := range result.Data {
+		score := MatchScore(q, p)
+		if best == nil || score > best.Score {
+			best = &TitleMatch{Paper: p, Score: score}
+		}
+	}
+	if best == nil || best.Score < threshold {
+		return nil, ErrNotFound
+	}
+	return best, nil
+}
+
+// MatchScore scores how well p matches q. The title similarity is penalized when
+// the year or first author are given and disagree with the paper.
+func MatchScore(q TitleQuery, p Paper) float64 {
+	score := TitleSimilarity(q.Title, p.Title)
+	if q.Year != 0 {
+		if year := p.PublishedYear(); year != 0 {
+			switch diff := year - q.Year; {
+			case diff == 0:
+			case diff == 1 || diff == -1:
+				score *= 0.95
+			default:
+				score *= 0.8
+			}
+		}
+	}
+	if q.FirstAuthor != "" && len(p.Authors) > 0 {
+		if lastName(q.FirstAuthor) != lastName(p.Authors[0].Name) {
+			score *= 0.9
+		}
+	}
+	return score
+}
+
+// TitleSimilarity compares two titles after normalization and returns the larger of
+// their Levenshtein ratio and token Jaccard index, a value in [0, 1].
+func TitleSimilarity(a, b string) float64 {
+	na, nb := NormalizeTitle(a), NormalizeTitle(b)
+	if na == "" && nb == "" {
+		return 1
+	}
+	lev := levenshteinRatio(na, nb)
+	jac := jaccard(strings.Fields(na), strings.Fields(nb))
+	if jac > lev {
+		return jac
+	}
+	return lev
+}
+
+// NormalizeTitle lowercases s, replaces punctuation with spaces and collapses whitespace.
+func NormalizeTitle(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+			continue
+		}
+		space = true
+	}
+	return b.String()
+}
+
+func levenshteinRatio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	set := make(map[string]bool, len(a))
+	for _, t := range a {
+		set[t] = true
+	}
+	union := len(set)
+	inter := 0
+	seen := make(map[string]bool, len(b))
+	for _, t := range b {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		if set[t] {
+			inter++
+		} else {
+			union++
+		}
+	}
+	return float64(inter) / float64(union)
+}
+
+// lastName returns the normalized family name from either "First Last" or "Last, First".
+func lastName(name string) string {
+	if i := strings.Index(name, ","); i > 0 {
+		name = name[:i]
+	}
+	parts := strings.Fields(NormalizeTitle(name))
+	if len(parts) == 0 {
+		return ""
+	}
+	return parts[len(parts)-1]
+}
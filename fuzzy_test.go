@@ -0,0 +1,57 @@
+package semscholar_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestTitleResolverResolve checks the scores Resolve gives the ResNet paper,
+// published in 2015 by Kaiming He, and that candidates scoring below the
+// threshold, or no candidates at all, fail with ErrNotFound.
+func TestTitleResolverResolve(t *testing.T) {
+	srv := semscholartest.NewServer(nil)
+	defer srv.Close()
+	const title = "Deep Residual Learning for Image Recognition"
+	tests := []struct {
+		name      string
+		q         semscholar.TitleQuery
+		threshold float64
+		score     float64 // 0 for ErrNotFound
+	}{
+		{"exact", semscholar.TitleQuery{Title: title, Year: 2015, FirstAuthor: "Kaiming He"}, 0, 1},
+		{"case", semscholar.TitleQuery{Title: "DEEP residual learning for image recognition"}, 0, 1},
+		{"author as last, first", semscholar.TitleQuery{Title: title, FirstAuthor: "He, Kaiming"}, 0, 1},
+		{"year off by one", semscholar.TitleQuery{Title: title, Year: 2016}, 0, 0.95},
+		{"year off by more", semscholar.TitleQuery{Title: title, Year: 2019}, 0, 0},
+		{"year off by more, lower threshold", semscholar.TitleQuery{Title: title, Year: 2019}, 0.75, 0.8},
+		{"other first author", semscholar.TitleQuery{Title: title, FirstAuthor: "Jian Sun"}, 0, 0.9},
+		{"both penalties", semscholar.TitleQuery{Title: title, Year: 2019, FirstAuthor: "Jian Sun"}, 0.7, 0.72},
+		{"partial title", semscholar.TitleQuery{Title: "Deep Residual Learning"}, 0, 0},
+		{"partial title, lower threshold", semscholar.TitleQuery{Title: "Deep Residual Learning"}, 0.4, 0.5},
+		{"no candidates", semscholar.TitleQuery{Title: "Quantum gravity of lemurs"}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := semscholar.NewTitleResolver(srv.Client())
+			r.Threshold = tt.threshold
+			m, err := r.Resolve(context.Background(), tt.q)
+			if tt.score == 0 {
+				if !errors.Is(err, semscholar.ErrNotFound) {
+					t.Errorf("Resolve(%+v) = %+v, %v; want ErrNotFound", tt.q, m, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Paper.PaperID != semscholartest.ResNetPaperID || math.Abs(m.Score-tt.score) > 0.01 {
+				t.Errorf("Resolve(%+v) = %s scoring %.3f, want ResNet scoring %.3f", tt.q, m.Paper.Title, m.Score, tt.score)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package semscholar
+
+// The field constants in fields_gen.go are generated from the API's OpenAPI
+// spec, vendored in openapi/. To pick up a new spec, run
+// go run ./internal/s2gen -fetch, then go generate, and check the models
+// against it with go run ./internal/s2gen -check.
+
+//go:generate go run ./internal/s2gen -spec openapi/graph.json -out fields_gen.go
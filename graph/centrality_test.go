@@ -0,0 +1,120 @@
+package graph_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/graph"
+)
+
+const tolerance = 1e-6
+
+// TestPageRank checks that PageRank flows from citing to cited papers, sums
+// to 1, and treats symmetric graphs symmetrically.
+func TestPageRank(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		g     *graph.Graph
+		top   string
+		equal []string
+	}{
+		{"star", build("a>d", "b>d", "c>d"), "d", []string{"a", "b", "c"}},
+		{"chain", build("a>b", "b>c"), "c", nil},
+		{"cycle", build("a>b", "b>c", "c>a"), "", []string{"a", "b", "c"}},
+		{"coauthors", build("x-y", "y-z"), "y", []string{"x", "z"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			scores := tc.g.PageRank(0)
+			sum := 0.0
+			for _, s := range scores {
+				sum += s
+			}
+			if math.Abs(sum-1) > tolerance {
+				t.Errorf("scores sum to %v, want 1", sum)
+			}
+			if tc.top != "" {
+				if top := graph.Top(scores, 1); top[0].ID != tc.top {
+					t.Errorf("top node %s, want %s", top[0].ID, tc.top)
+				}
+			}
+			for _, id := range tc.equal {
+				if math.Abs(scores[id]-scores[tc.equal[0]]) > tolerance {
+					t.Errorf("score of %s is %v, want that of %s, %v", id, scores[id], tc.equal[0], scores[tc.equal[0]])
+				}
+			}
+		})
+	}
+	if got := graph.New().PageRank(0); len(got) != 0 {
+		t.Errorf("PageRank of an empty graph = %v, want none", got)
+	}
+}
+
+// TestEigenvectorCentrality checks that the hub of a co-authorship star
+// scores highest, that scores are L2-normalized, and that a graph without
+// edges gives every node the same score.
+func TestEigenvectorCentrality(t *testing.T) {
+	scores := build("h-a", "h-b", "h-c", "a-b").EigenvectorCentrality()
+	norm := 0.0
+	for _, s := range scores {
+		norm += s * s
+	}
+	if math.Abs(norm-1) > tolerance {
+		t.Errorf("squared scores sum to %v, want 1", norm)
+	}
+	if top := graph.Top(scores, 1); top[0].ID != "h" {
+		t.Errorf("top node %s, want h", top[0].ID)
+	}
+	if math.Abs(scores["a"]-scores["b"]) > tolerance || scores["c"] >= scores["a"] {
+		t.Errorf("scores %v, want a = b > c", scores)
+	}
+
+	g := graph.New()
+	g.AddNode(&graph.Node{ID: "a"})
+	g.AddNode(&graph.Node{ID: "b"})
+	scores = g.EigenvectorCentrality()
+	if math.Abs(scores["a"]-scores["b"]) > tolerance {
+		t.Errorf("scores without edges %v, want them equal", scores)
+	}
+}
+
+// TestDegreeCentrality checks degrees and their normalization by n-1,
+// including on a single node, where n-1 is zero.
+func TestDegreeCentrality(t *testing.T) {
+	g := build("a>c", "b>c")
+	in, out := g.DegreeCentrality()
+	if in["c"] != 1 || out["a"] != 0.5 || in["a"] != 0 {
+		t.Errorf("in %v, out %v", in, out)
+	}
+	if g.InDegree("c") != 2 || g.OutDegree("c") != 0 || g.Degree("a") != 1 {
+		t.Errorf("degrees of c: in %d out %d, of a: %d", g.InDegree("c"), g.OutDegree("c"), g.Degree("a"))
+	}
+
+	single := graph.New()
+	single.AddNode(&graph.Node{ID: "a"})
+	if in, _ := single.DegreeCentrality(); in["a"] != 0 {
+		t.Errorf("single node in-degree centrality %v, want 0", in["a"])
+	}
+}
+
+// TestTop checks that Top orders by score, breaks ties by ID and treats a
+// non-positive n as all.
+func TestTop(t *testing.T) {
+	scores := map[string]float64{"b": 1, "a": 1, "c": 2, "d": 0}
+	ids := func(top []graph.NodeScore) []string {
+		var out []string
+		for _, s := range top {
+			out = append(out, s.ID)
+		}
+		return out
+	}
+	if got := ids(graph.Top(scores, 3)); !slices.Equal(got, []string{"c", "a", "b"}) {
+		t.Errorf("Top 3 = %v, want [c a b]", got)
+	}
+	if got := graph.Top(scores, 0); len(got) != 4 {
+		t.Errorf("Top 0 returned %d entries, want 4", len(got))
+	}
+	if got := graph.Top(scores, 10); len(got) != 4 {
+		t.Errorf("Top 10 returned %d entries, want 4", len(got))
+	}
+}
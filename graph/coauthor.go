@@ -0,0 +1,62 @@
+package graph
+
+import (
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// Coauthorship builds an undirected co-authorship graph from papers. Every author
+// with an ID becomes a node, and each pair of authors on the same paper is joined
+// by a CoauthorEdge whose weight counts their joint papers. Each author node
+// collects the input papers it appears on in Author.Papers.
+func Coauthorship(papers []semscholar.Paper) *Graph {
+	g := New()
+	for _, p := range papers {
+		var ids []string
+		for _, a := range p.Authors {
+			if a.AuthorID == "" {
+				continue
+			}
+			node, _ := g.AddNode(&Node{ID: a.AuthorID, Kind: AuthorNode, Author: a})
+			if node.Author.Name == "" {
+				node.Author.Name = a.Name
+			}
+			paper := p
+			paper.Authors = nil
+			node.Author.Papers = append(node.Author.Papers, paper)
+			ids = append(ids, a.AuthorID)
+		}
+		for i := 0; i < len(ids); i++ {
+			for j := i + 1; j < len(ids); j++ {
+				g.AddEdge(Edge{From: ids[i], To: ids[j], Kind: CoauthorEdge})
+			}
+		}
+	}
+	return g
+}
+
+// CoCitation derives an undirected co-citation graph from the citation edges of g:
+// two papers are joined when another paper in g cites both, and the edge weight
+// counts how many papers do. Only papers cited at least once appear in the result.
+func CoCitation(g *Graph) *Graph {
+	cc := New()
+	for _, n := range g.Nodes() {
+		var cited []string
+		for _, e := range g.OutEdges(n.ID) {
+			if e.Kind == CitesEdge {
+				cited = append(cited, e.To)
+			}
+		}
+		for _, id := range cited {
+			if cc.Node(id) == nil {
+				orig := g.Node(id)
+				cc.AddNode(&Node{ID: id, Kind: orig.Kind, Paper: orig.Paper, Author: orig.Author, Depth: orig.Depth})
+			}
+		}
+		for i := 0; i < len(cited); i++ {
+			for j := i + 1; j < len(cited); j++ {
+				cc.AddEdge(Edge{From: cited[i], To: cited[j], Kind: CoCitationEdge})
+			}
+		}
+	}
+	return cc
+}
@@ -0,0 +1,358 @@
+package graph
+
+import (
+	"sort"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// CommunityMethod selects the community detection algorithm.
+type CommunityMethod int
+
+// Community detection algorithms.
+const (
+	// Louvain greedily optimizes modularity and then repeats on the graph of
+	// communities. It gives the better partition on most collaboration graphs.
+	Louvain CommunityMethod = iota
+	// LabelPropagation lets every node adopt the label most common among its
+	// neighbors until labels stabilize. It is faster but coarser.
+	LabelPropagation
+)
+
+const (
+	maxLouvainPasses   = 50
+	maxLabelIterations = 100
+	summaryTopN        = 5
+)
+
+// Count is a value together with how many times it occurred.
+type Count struct {
+	Value string
+	Count int
+}
+
+// Community is a cluster of nodes found by Communities, with summary statistics
+// computed from the papers of its members (the paper itself for paper nodes, and
+// Author.Papers for author nodes).
+type Community struct {
+	ID      int
+	Members []string
+	// InternalWeight is the total weight of edges with both ends in the community.
+	InternalWeight int
+	TopVenues      []Count
+	TopFields      []Count
+	MinYear        int
+	MaxYear        int
+	MedianYear     int
+}
+
+// Communities partitions g into communities, treating every edge as undirected
+// and weighted. Communities are returned largest first, with IDs matching their
+// position.
+func (g *Graph) Communities(method CommunityMethod) []Community {
+	pos := make(map[string]int, len(g.order))
+	adj := make([]map[int]float64, len(g.order))
+	for i, id := range g.order {
+		pos[id] = i
+		adj[i] = make(map[int]float64)
+	}
+	for _, e := range g.edges {
+		a, b, w := pos[e.From], pos[e.To], float64(e.Weight)
+		adj[a][b] += w
+		adj[b][a] += w
+	}
+
+	var labels []int
+	switch method {
+	case LabelPropagation:
+		labels = labelPropagation(adj)
+	default:
+		labels = louvain(adj)
+	}
+	return g.summarize(labels)
+}
+
+// Modularity returns the modularity of the partition described by communities,
+// treating edges as undirected and weighted. Values above roughly 0.3 indicate
+// meaningful community structure.
+func (g *Graph) Modularity(communities []Community) float64 {
+	label := make(map[string]int, len(g.order))
+	for _, c := range communities {
+		for _, id := range c.Members {
+			label[id] = c.ID
+		}
+	}
+	degree := make(map[string]float64)
+	total := 0.0
+	internal := make(map[int]float64)
+	for _, e := range g.edges {
+		w := float64(e.Weight)
+		degree[e.From] += w
+		degree[e.To] += w
+		total += w
+		if label[e.From] == label[e.To] {
+			internal[label[e.From]] += w
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	tot := make(map[int]float64)
+	for id, d := range degree {
+		tot[label[id]] += d
+	}
+	q := 0.0
+	for c, t := range tot {
+		q += internal[c]/total - (t/(2*total))*(t/(2*total))
+	}
+	return q
+}
+
+// louvain returns a community label for every node of the symmetric weighted
+// adjacency adj.
+func louvain(adj []map[int]float64) []int {
+	n := len(adj)
+	labels := make([]int, n)
+	for i := range labels {
+		labels[i] = i
+	}
+	loops := make([]float64, n)
+	for pass := 0; pass < maxLouvainPasses && len(adj) > 1; pass++ {
+		assign, moved := louvainLevel(adj, loops)
+		if !moved {
+			break
+		}
+		for i := range labels {
+			labels[i] = assign[labels[i]]
+		}
+		adj, loops = aggregate(adj, loops, assign)
+	}
+	return labels
+}
+
+// louvainLevel runs the local moving phase and returns compacted community
+// assignments and whether any node changed community.
+func louvainLevel(adj []map[int]float64, loops []float64) ([]int, bool) {
+	n := len(adj)
+	community := make([]int, n)
+	degree := make([]float64, n)
+	tot := make([]float64, n)
+	m2 := 0.0
+	for i := range adj {
+		community[i] = i
+		for _, w := range adj[i] {
+			degree[i] += w
+		}
+		degree[i] += 2 * loops[i]
+		tot[i] = degree[i]
+		m2 += degree[i]
+	}
+	if m2 == 0 {
+		return community, false
+	}
+	moved := false
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < n; i++ {
+			current := community[i]
+			weights := make(map[int]float64)
+			for j, w := range adj[i] {
+				weights[community[j]] += w
+			}
+			tot[current] -= degree[i]
+			best, bestGain := current, weights[current]-tot[current]*degree[i]/m2
+			for _, c := range sortedKeys(weights) {
+				if gain := weights[c] - tot[c]*degree[i]/m2; gain > bestGain {
+					best, bestGain = c, gain
+				}
+			}
+			tot[best] += degree[i]
+			if best != current {
+				community[i] = best
+				improved, moved = true, true
+			}
+		}
+	}
+	return compact(community), moved
+}
+
+// aggregate collapses each community into a single node.
+func aggregate(adj []map[int]float64, loops []float64, assign []int) ([]map[int]float64, []float64) {
+	size := 0
+	for _, c := range assign {
+		if c+1 > size {
+			size = c + 1
+		}
+	}
+	next := make([]map[int]float64, size)
+	for i := range next {
+		next[i] = make(map[int]float64)
+	}
+	nextLoops := make([]float64, size)
+	for i, row := range adj {
+		ci := assign[i]
+		nextLoops[ci] += loops[i]
+		for j, w := range row {
+			cj := assign[j]
+			if ci == cj {
+				nextLoops[ci] += w / 2
+			} else {
+				next[ci][cj] += w
+			}
+		}
+	}
+	return next, nextLoops
+}
+
+// labelPropagation returns a community label for every node of adj. Nodes are
+// visited in a fixed order and ties go to the smallest label, so the result is
+// deterministic.
+func labelPropagation(adj []map[int]float64) []int {
+	labels := make([]int, len(adj))
+	for i := range labels {
+		labels[i] = i
+	}
+	for iter := 0; iter < maxLabelIterations; iter++ {
+		changed := false
+		for i, row := range adj {
+			if len(row) == 0 {
+				continue
+			}
+			weights := make(map[int]float64)
+			for j, w := range row {
+				weights[labels[j]] += w
+			}
+			best, bestWeight := labels[i], weights[labels[i]]
+			for _, l := range sortedKeys(weights) {
+				if weights[l] > bestWeight {
+					best, bestWeight = l, weights[l]
+				}
+			}
+			if best != labels[i] {
+				labels[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return compact(labels)
+}
+
+// compact renumbers labels to 0..k-1 in order of first appearance.
+func compact(labels []int) []int {
+	ids := make(map[int]int)
+	out := make([]int, len(labels))
+	for i, l := range labels {
+		id, ok := ids[l]
+		if !ok {
+			id = len(ids)
+			ids[l] = id
+		}
+		out[i] = id
+	}
+	return out
+}
+
+func sortedKeys(m map[int]float64) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// summarize turns per-node labels into Community values with statistics.
+func (g *Graph) summarize(labels []int) []Community {
+	byLabel := make(map[int]*Community)
+	var order []int
+	for i, id := range g.order {
+		c, ok := byLabel[labels[i]]
+		if !ok {
+			c = &Community{}
+			byLabel[labels[i]] = c
+			order = append(order, labels[i])
+		}
+		c.Members = append(c.Members, id)
+	}
+	pos := make(map[string]int, len(g.order))
+	for i, id := range g.order {
+		pos[id] = i
+	}
+	for _, e := range g.edges {
+		if l := labels[pos[e.From]]; l == labels[pos[e.To]] {
+			byLabel[l].InternalWeight += e.Weight
+		}
+	}
+	out := make([]Community, 0, len(order))
+	for _, l := range order {
+		c := byLabel[l]
+		g.describe(c)
+		out = append(out, *c)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return len(out[i].Members) > len(out[j].Members) })
+	for i := range out {
+		out[i].ID = i
+	}
+	return out
+}
+
+// describe fills the venue, field and year statistics of c.
+func (g *Graph) describe(c *Community) {
+	venues := make(map[string]int)
+	fields := make(map[string]int)
+	var years []int
+	seen := make(map[string]bool)
+	add := func(p semscholar.Paper) {
+		if p.PaperID != "" {
+			if seen[p.PaperID] {
+				return
+			}
+			seen[p.PaperID] = true
+		}
+		if p.Venue != "" {
+			venues[p.Venue]++
+		}
+		for _, f := range p.FieldsOfStudy {
+			fields[f]++
+		}
+		if y := p.PublishedYear(); y != 0 {
+			years = append(years, y)
+		}
+	}
+	for _, id := range c.Members {
+		n := g.nodes[id]
+		if n.Kind == AuthorNode {
+			for _, p := range n.Author.Papers {
+				add(p)
+			}
+			continue
+		}
+		add(n.Paper)
+	}
+	c.TopVenues = topCounts(venues, summaryTopN)
+	c.TopFields = topCounts(fields, summaryTopN)
+	if len(years) > 0 {
+		sort.Ints(years)
+		c.MinYear, c.MaxYear, c.MedianYear = years[0], years[len(years)-1], years[len(years)/2]
+	}
+}
+
+func topCounts(m map[string]int, n int) []Count {
+	out := make([]Count, 0, len(m))
+	for v, c := range m {
+		out = append(out, Count{Value: v, Count: c})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Value < out[j].Value
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
@@ -0,0 +1,86 @@
+package graph_test
+
+import (
+	"slices"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/graph"
+)
+
+// members returns the sorted members of each community, in order.
+func members(communities []graph.Community) [][]string {
+	var out [][]string
+	for _, c := range communities {
+		out = append(out, slices.Sorted(slices.Values(c.Members)))
+	}
+	return out
+}
+
+// TestCommunities checks both methods on two triangles of co-authors with two
+// joint papers per pair, joined by a pair with one, a graph without edges,
+// and an empty graph.
+func TestCommunities(t *testing.T) {
+	triangles := []string{"a-b", "b-c", "c-a", "x-y", "y-z", "z-x"}
+	bridged := build(slices.Concat(triangles, triangles, []string{"c-x"})...)
+	isolated := graph.New()
+	for _, id := range []string{"a", "b"} {
+		isolated.AddNode(&graph.Node{ID: id, Kind: graph.AuthorNode})
+	}
+	for _, method := range []graph.CommunityMethod{graph.Louvain, graph.LabelPropagation} {
+		for _, tc := range []struct {
+			name string
+			g    *graph.Graph
+			want [][]string
+		}{
+			{"bridged triangles", bridged, [][]string{{"a", "b", "c"}, {"x", "y", "z"}}},
+			{"isolated nodes", isolated, [][]string{{"a"}, {"b"}}},
+			{"empty", graph.New(), nil},
+		} {
+			communities := tc.g.Communities(method)
+			if got := members(communities); !slices.EqualFunc(got, tc.want, slices.Equal) {
+				t.Errorf("method %d, %s: communities %v, want %v", method, tc.name, got, tc.want)
+			}
+			for i, c := range communities {
+				if c.ID != i {
+					t.Errorf("method %d, %s: community %d has ID %d", method, tc.name, i, c.ID)
+				}
+			}
+		}
+	}
+	communities := bridged.Communities(graph.Louvain)
+	if q := bridged.Modularity(communities); q < 0.3 {
+		t.Errorf("modularity %v, want above 0.3", q)
+	}
+	if communities[0].InternalWeight != 6 {
+		t.Errorf("internal weight %d, want 6", communities[0].InternalWeight)
+	}
+	if q := graph.New().Modularity(nil); q != 0 {
+		t.Errorf("modularity of an empty graph %v, want 0", q)
+	}
+}
+
+// TestCommunitySummary checks the venue, field and year statistics of a
+// community of authors, counting a paper they share once.
+func TestCommunitySummary(t *testing.T) {
+	shared := semscholar.Paper{PaperID: "p1", Venue: "ACL", Year: 2018, FieldsOfStudy: []string{"Linguistics"},
+		Authors: []semscholar.Author{{AuthorID: "1"}, {AuthorID: "2"}}}
+	solo := semscholar.Paper{PaperID: "p2", Venue: "EMNLP", Year: 2020, FieldsOfStudy: []string{"Linguistics", "Computer Science"},
+		Authors: []semscholar.Author{{AuthorID: "2"}}}
+	other := semscholar.Paper{PaperID: "p3", Venue: "ACL", Year: 2016,
+		Authors: []semscholar.Author{{AuthorID: "1"}, {AuthorID: "2"}}}
+	communities := graph.Coauthorship([]semscholar.Paper{shared, solo, other}).Communities(graph.Louvain)
+	if len(communities) != 1 {
+		t.Fatalf("got %d communities, want 1", len(communities))
+	}
+	c := communities[0]
+	if want := []graph.Count{{Value: "ACL", Count: 2}, {Value: "EMNLP", Count: 1}}; !slices.Equal(c.TopVenues, want) {
+		t.Errorf("TopVenues %v, want %v", c.TopVenues, want)
+	}
+	if want := []graph.Count{{Value: "Linguistics", Count: 2}, {Value: "Computer Science", Count: 1}}; !slices.Equal(c.TopFields, want) {
+		t.Errorf("TopFields %v, want %v", c.TopFields, want)
+	}
+	if c.MinYear != 2016 || c.MedianYear != 2018 || c.MaxYear != 2020 {
+		t.Errorf("years %d, %d, %d, want 2016, 2018, 2020", c.MinYear, c.MedianYear, c.MaxYear)
+	}
+}
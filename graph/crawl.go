@@ -0,0 +1,208 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// Direction selects which citation edges a crawl follows.
+type Direction int
+
+// Crawl directions.
+const (
+	// Citations follows edges to papers that cite the current paper (forward in time).
+	Citations Direction = 1 << iota
+	// References follows edges to papers the current paper cites (backward in time).
+	References
+	// Both follows citations and references.
+	Both = Citations | References
+)
+
+// Crawl defaults applied when the corresponding Options field is zero.
+const (
+	DefaultMaxNodes        = 1000
+	DefaultMaxEdgesPerNode = 1000
+	DefaultCrawlFields     = "title,year,publicationDate,venue,citationCount"
+	pageSize               = 1000
+)
+
+// Options tunes a crawl. Calls are paced and retried by the client: give it
+// an HTTP client from semscholar.NewRateLimitedClient to pace them, and a
+// Retry policy to retry throttled ones.
+type Options struct {
+	// Direction defaults to Both.
+	Direction Direction
+	// MaxNodes bounds the number of papers in the graph.
+	MaxNodes int
+	// MaxEdgesPerNode bounds how many citations and references are fetched per paper and direction.
+	MaxEdgesPerNode int
+	// Fields is requested for every paper; it defaults to DefaultCrawlFields.
+	Fields string
+}
+
+func (o Options) withDefaults() Options {
+	if o.Direction == 0 {
+		o.Direction = Both
+	}
+	if o.MaxNodes == 0 {
+		o.MaxNodes = DefaultMaxNodes
+	}
+	if o.MaxEdgesPerNode == 0 {
+		o.MaxEdgesPerNode = DefaultMaxEdgesPerNode
+	}
+	if o.Fields == "" {
+		o.Fields = DefaultCrawlFields
+	}
+	return o
+}
+
+// Crawl expands the citation graph around seedIDs breadth-first, up to depth hops
+// and opts.MaxNodes papers. Papers reached by several paths are stored once, and
+// edges are only recorded between papers that made it into the graph. Seeds that
+// cannot be found are skipped. If ctx ends mid-crawl, the partial graph is
+// returned along with the context's error.
+func Crawl(ctx context.Context, client *semscholar.Client, seedIDs []string, depth int, opts Options) (*Graph, error) {
+	opts = opts.withDefaults()
+	c := &crawler{client: client, opts: opts, g: New()}
+	c.g.Meta = Metadata{
+		Seeds:     append([]string(nil), seedIDs...),
+		Depth:     depth,
+		Direction: opts.Direction,
+		Fields:    opts.Fields,
+		MaxNodes:  opts.MaxNodes,
+		CrawledAt: time.Now().UTC(),
+		BaseURL:   client.BaseURL,
+	}
+
+	seeds, err := c.fetch(ctx, seedIDs)
+	if err != nil {
+		return c.g, err
+	}
+	var queue []string
+	for _, p := range seeds {
+		if p.PaperID == "" {
+			continue
+		}
+		if c.g.NumNodes() >= opts.MaxNodes {
+			c.g.Meta.Truncated = true
+			continue
+		}
+		if _, added := c.g.AddNode(&Node{ID: p.PaperID, Kind: PaperNode, Paper: p}); added {
+			queue = append(queue, p.PaperID)
+		}
+	}
+	return c.g, c.bfs(ctx, queue, depth)
+}
+
+type crawler struct {
+	client *semscholar.Client
+	opts   Options
+	g      *Graph
+}
+
+// fetch hydrates ids with the crawl fields in batches. Unknown IDs come back
+// as zero-valued papers.
+func (c *crawler) fetch(ctx context.Context, ids []string) ([]semscholar.Paper, error) {
+	out := make([]semscholar.Paper, 0, len(ids))
+	for start := 0; start < len(ids); start += semscholar.MaxBatchSize {
+		end := min(start+semscholar.MaxBatchSize, len(ids))
+		papers, err := c.client.GetPapersBatchContext(ctx, ids[start:end], c.opts.Fields)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, papers...)
+	}
+	return out, nil
+}
+
+// bfs expands the queued nodes in order, appending newly discovered papers,
+// until every node shallower than maxDepth has been expanded. Each node is
+// marked Expanded once both of its directions have been paged.
+func (c *crawler) bfs(ctx context.Context, queue []string, maxDepth int) error {
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		n := c.g.Node(id)
+		depth := n.Depth
+		if depth >= maxDepth {
+			continue
+		}
+		if c.opts.Direction&Citations != 0 {
+			found, err := c.expand(ctx, id, depth+1, true)
+			queue = append(queue, found...)
+			if err != nil {
+				return err
+			}
+		}
+		if c.opts.Direction&References != 0 {
+			found, err := c.expand(ctx, id, depth+1, false)
+			queue = append(queue, found...)
+			if err != nil {
+				return err
+			}
+		}
+		n.Expanded = true
+	}
+	return nil
+}
+
+// expand pages through the citations (or references) of id, adding new papers at
+// the given depth while the node budget allows. It returns the IDs it added.
+func (c *crawler) expand(ctx context.Context, id string, depth int, citations bool) ([]string, error) {
+	var added []string
+	fields := c.opts.Fields + ",intents,isInfluential"
+	for offset := 0; offset < c.opts.MaxEdgesPerNode; {
+		limit := min(pageSize, c.opts.MaxEdgesPerNode-offset)
+		page, err := c.page(ctx, id, offset, limit, fields, citations)
+		if err != nil {
+			if errors.Is(err, semscholar.ErrNotFound) {
+				return added, nil
+			}
+			return added, err
+		}
+		for _, edge := range page.Data {
+			other := edge.CitedPaper
+			if citations {
+				other = edge.CitingPaper
+			}
+			if other.PaperID == "" {
+				continue
+			}
+			if c.g.Node(other.PaperID) == nil {
+				if c.g.NumNodes() >= c.opts.MaxNodes {
+					c.g.Meta.Truncated = true
+					continue
+				}
+				c.g.AddNode(&Node{ID: other.PaperID, Kind: PaperNode, Paper: other, Depth: depth})
+				added = append(added, other.PaperID)
+			}
+			e := Edge{From: id, To: other.PaperID, Kind: CitesEdge, Intents: edge.Intents, IsInfluential: edge.IsInfluential}
+			if citations {
+				e.From, e.To = other.PaperID, id
+			}
+			if !c.g.HasEdge(e.From, e.To, CitesEdge) {
+				c.g.AddEdge(e)
+			}
+		}
+		if page.Next == 0 || len(page.Data) == 0 {
+			break
+		}
+		if page.Next <= offset {
+			return added, fmt.Errorf("graph: paging the edges of %s did not advance past offset %d", id, offset)
+		}
+		offset = page.Next
+	}
+	return added, nil
+}
+
+// page fetches a page of the citations (or references) of id.
+func (c *crawler) page(ctx context.Context, id string, offset, limit int, fields string, citations bool) (*semscholar.CitationsResponse, error) {
+	if citations {
+		return c.client.GetPaperCitationsContext(ctx, id, offset, limit, fields)
+	}
+	return c.client.GetPaperReferencesContext(ctx, id, offset, limit, fields)
+}
@@ -0,0 +1,154 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// ErrNoPath is returned by CollaborationDistance when no chain of co-authors was
+// found within the search budget.
+var ErrNoPath = errors.New("graph: no collaboration path within budget")
+
+// DistanceOptions bounds a collaboration distance search. Zero fields use defaults.
+type DistanceOptions struct {
+	// MaxDistance is the longest chain considered; it defaults to 6.
+	MaxDistance int
+	// MaxAuthors bounds how many authors have their papers fetched; it defaults to 200.
+	MaxAuthors int
+	// MaxPapersPerAuthor bounds how many papers are read per author; it defaults to 1000.
+	MaxPapersPerAuthor int
+}
+
+// CollaborationPath is a chain of co-authorship linking two authors.
+// Papers[i] was written by both Authors[i] and Authors[i+1].
+type CollaborationPath struct {
+	Authors []semscholar.Author
+	Papers  []semscholar.Paper
+}
+
+// Distance returns the number of collaboration hops in the path.
+func (p *CollaborationPath) Distance() int {
+	return len(p.Papers)
+}
+
+type hop struct {
+	author string
+	paper  semscholar.Paper
+}
+
+// CollaborationDistance finds a shortest co-authorship chain between two authors,
+// in the manner of an Erdős number. It searches outward from both authors at once,
+// always expanding the smaller frontier, and gives up with ErrNoPath once
+// MaxAuthors authors have been expanded or MaxDistance is exceeded.
+func CollaborationDistance(ctx context.Context, client *semscholar.Client, fromID, toID string, opts DistanceOptions) (*CollaborationPath, error) {
+	if opts.MaxDistance == 0 {
+		opts.MaxDistance = 6
+	}
+	if opts.MaxAuthors == 0 {
+		opts.MaxAuthors = 200
+	}
+	if opts.MaxPapersPerAuthor == 0 {
+		opts.MaxPapersPerAuthor = 1000
+	}
+	c := &crawler{client: client, opts: Options{}.withDefaults()}
+
+	names := map[string]semscholar.Author{fromID: {AuthorID: fromID}, toID: {AuthorID: toID}}
+	if fromID == toID {
+		return &CollaborationPath{Authors: []semscholar.Author{names[fromID]}}, nil
+	}
+	// parents[0] holds hops back towards fromID, parents[1] towards toID.
+	parents := [2]map[string]hop{{fromID: {}}, {toID: {}}}
+	frontiers := [2][]string{{fromID}, {toID}}
+	expanded := 0
+	for distance := 0; distance < opts.MaxDistance; distance++ {
+		side := 0
+		if len(frontiers[1]) < len(frontiers[0]) {
+			side = 1
+		}
+		if len(frontiers[side]) == 0 {
+			break
+		}
+		var next []string
+		for _, id := range frontiers[side] {
+			if expanded >= opts.MaxAuthors {
+				return nil, ErrNoPath
+			}
+			expanded++
+			papers, err := c.authorPapers(ctx, id, opts.MaxPapersPerAuthor)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range papers {
+				for _, a := range p.Authors {
+					if a.AuthorID == "" || a.AuthorID == id {
+						continue
+					}
+					if names[a.AuthorID].Name == "" {
+						names[a.AuthorID] = a
+					}
+					if _, seen := parents[side][a.AuthorID]; seen {
+						continue
+					}
+					parents[side][a.AuthorID] = hop{author: id, paper: p}
+					if _, met := parents[1-side][a.AuthorID]; met {
+						return buildPath(a.AuthorID, parents, names), nil
+					}
+					next = append(next, a.AuthorID)
+				}
+			}
+		}
+		frontiers[side] = next
+	}
+	return nil, ErrNoPath
+}
+
+// authorPapers pages through an author's papers with their author lists.
+func (c *crawler) authorPapers(ctx context.Context, authorID string, max int) ([]semscholar.Paper, error) {
+	var out []semscholar.Paper
+	for offset := 0; offset < max; {
+		page, err := c.client.GetAuthorPapersContext(ctx, authorID, offset, min(pageSize, max-offset), "title,year,publicationDate,venue,authors")
+		if err != nil {
+			if errors.Is(err, semscholar.ErrNotFound) {
+				return out, nil
+			}
+			return nil, err
+		}
+		out = append(out, page.Data...)
+		if page.Next == 0 || len(page.Data) == 0 {
+			break
+		}
+		offset = page.Next
+	}
+	return out, nil
+}
+
+// buildPath joins the two half-paths that meet at the given author.
+func buildPath(meet string, parents [2]map[string]hop, names map[string]semscholar.Author) *CollaborationPath {
+	var authors []string
+	var papers []semscholar.Paper
+	for id := meet; ; {
+		h := parents[0][id]
+		authors = append([]string{id}, authors...)
+		if h.author == "" {
+			break
+		}
+		papers = append([]semscholar.Paper{h.paper}, papers...)
+		id = h.author
+	}
+	for id := meet; ; {
+		h := parents[1][id]
+		if h.author == "" {
+			break
+		}
+		authors = append(authors, h.author)
+		papers = append(papers, h.paper)
+		id = h.author
+	}
+	path := &CollaborationPath{Papers: papers}
+	for _, id := range authors {
+		path.Authors = append(path.Authors, names[id])
+	}
+	return path
+}
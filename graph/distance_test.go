@@ -0,0 +1,64 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/graph"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestCollaborationDistance searches a chain of co-authors 1-2-3-4, with 5
+// writing alone, for paths of each length and for authors out of reach.
+func TestCollaborationDistance(t *testing.T) {
+	d := semscholartest.NewDataset()
+	for i, pair := range [][]string{{"1", "2"}, {"2", "3"}, {"3", "4"}, {"5"}} {
+		p := semscholar.Paper{PaperID: "p" + string(rune('1'+i)), Title: "Paper"}
+		for _, id := range pair {
+			p.Authors = append(p.Authors, semscholar.Author{AuthorID: id, Name: "Author " + id})
+		}
+		d.AddPaper(p)
+	}
+	srv := semscholartest.NewServer(d)
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name     string
+		from, to string
+		opts     graph.DistanceOptions
+		authors  []string
+		papers   []string
+		err      error
+	}{
+		{"self", "1", "1", graph.DistanceOptions{}, []string{"1"}, nil, nil},
+		{"coauthors", "1", "2", graph.DistanceOptions{}, []string{"1", "2"}, []string{"p1"}, nil},
+		{"chain", "1", "4", graph.DistanceOptions{}, []string{"1", "2", "3", "4"}, []string{"p1", "p2", "p3"}, nil},
+		{"reversed", "4", "1", graph.DistanceOptions{}, []string{"4", "3", "2", "1"}, []string{"p3", "p2", "p1"}, nil},
+		{"unreachable", "1", "5", graph.DistanceOptions{}, nil, nil, graph.ErrNoPath},
+		{"too far", "1", "4", graph.DistanceOptions{MaxDistance: 2}, nil, nil, graph.ErrNoPath},
+		{"too few authors", "1", "4", graph.DistanceOptions{MaxAuthors: 1}, nil, nil, graph.ErrNoPath},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path, err := graph.CollaborationDistance(context.Background(), srv.Client(), tc.from, tc.to, tc.opts)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got error %v, want %v", err, tc.err)
+			}
+			if err != nil {
+				return
+			}
+			var authors, papers []string
+			for _, a := range path.Authors {
+				authors = append(authors, a.AuthorID)
+			}
+			for _, p := range path.Papers {
+				papers = append(papers, p.PaperID)
+			}
+			if !slices.Equal(authors, tc.authors) || !slices.Equal(papers, tc.papers) || path.Distance() != len(tc.papers) {
+				t.Errorf("path through %v by %v, distance %d, want %v by %v", authors, papers, path.Distance(), tc.authors, tc.papers)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package graph_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/graph"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// Titles with the characters XML and DOT must escape.
+const (
+	quotedTitle = `"Attention" Is <All> You Need`
+	ampTitle    = `Deep Learning & Graphs: a < b`
+)
+
+// exportGraph returns a small graph of two papers, one citing the other,
+// and their co-authors.
+func exportGraph() *graph.Graph {
+	g := graph.New()
+	g.AddNode(&graph.Node{ID: "p1", Kind: graph.PaperNode, Paper: semscholar.Paper{
+		PaperID:       "p1",
+		Title:         quotedTitle,
+		Venue:         "NeurIPS",
+		Year:          2017,
+		CitationCount: semscholar.NewNullInt(100),
+	}})
+	g.AddNode(&graph.Node{ID: "p2", Kind: graph.PaperNode, Depth: 1, Paper: semscholar.Paper{
+		PaperID: "p2",
+		Title:   ampTitle,
+		Year:    2019,
+	}})
+	g.AddNode(&graph.Node{ID: "a1", Kind: graph.AuthorNode, Author: semscholar.Author{AuthorID: "a1", Name: "Ada O'Brien", HIndex: semscholar.NewNullInt(12)}})
+	g.AddNode(&graph.Node{ID: "a2", Kind: graph.AuthorNode, Author: semscholar.Author{AuthorID: "a2", Name: "Bo <Li>"}})
+	g.AddEdge(graph.Edge{From: "p2", To: "p1", Kind: graph.CitesEdge, Intents: []string{graph.IntentMethodology}, IsInfluential: true})
+	g.AddEdge(graph.Edge{From: "a1", To: "a2", Kind: graph.CoauthorEdge})
+	return g
+}
+
+// checkGolden compares got with the golden file name in testdata, or
+// rewrites the file with -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s; run go test -update to see the change\ngot:\n%s", path, got)
+	}
+}
+
+// TestWriteGraphML checks WriteGraphML against its golden file, and that the
+// titles and names it escapes read back unchanged.
+func TestWriteGraphML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := graph.WriteGraphML(&buf, exportGraph()); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "graph.graphml", buf.Bytes())
+
+	var doc struct {
+		Nodes []struct {
+			ID   string `xml:"id,attr"`
+			Data []struct {
+				Key   string `xml:"key,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"data"`
+		} `xml:"graph>node"`
+		Edges []struct {
+			Source string `xml:"source,attr"`
+		} `xml:"graph>edge"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	labels := make(map[string]string)
+	for _, n := range doc.Nodes {
+		for _, d := range n.Data {
+			if d.Key == "label" {
+				labels[n.ID] = d.Value
+			}
+		}
+	}
+	want := map[string]string{"p1": quotedTitle, "p2": ampTitle, "a1": "Ada O'Brien", "a2": "Bo <Li>"}
+	for id, label := range want {
+		if labels[id] != label {
+			t.Errorf("label of %s read back as %q, want %q", id, labels[id], label)
+		}
+	}
+	if len(doc.Edges) != 2 {
+		t.Errorf("read back %d edges, want 2", len(doc.Edges))
+	}
+}
+
+// TestWriteGEXF checks WriteGEXF against its golden file, and that the
+// labels it escapes read back unchanged.
+func TestWriteGEXF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := graph.WriteGEXF(&buf, exportGraph()); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "graph.gexf", buf.Bytes())
+
+	var doc struct {
+		Nodes []struct {
+			ID    string `xml:"id,attr"`
+			Label string `xml:"label,attr"`
+			Start string `xml:"start,attr"`
+		} `xml:"graph>nodes>node"`
+		Edges []struct {
+			Start string `xml:"start,attr"`
+		} `xml:"graph>edges>edge"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"p1": quotedTitle, "p2": ampTitle, "a1": "Ada O'Brien", "a2": "Bo <Li>"}
+	for _, n := range doc.Nodes {
+		if n.Label != want[n.ID] {
+			t.Errorf("label of %s read back as %q, want %q", n.ID, n.Label, want[n.ID])
+		}
+	}
+	if len(doc.Edges) != 2 || doc.Edges[0].Start != "2019" {
+		t.Errorf("read back edges %+v, want 2 with the citation starting in 2019", doc.Edges)
+	}
+}
+
+// TestWriteDOT checks WriteDOT against its golden files, with the default
+// options and with nodes sized by citations.
+func TestWriteDOT(t *testing.T) {
+	tests := []struct {
+		golden string
+		opts   graph.DOTOptions
+	}{
+		{"graph.dot", graph.DOTOptions{}},
+		{"graph_sized.dot", graph.DOTOptions{SizeByCitations: true, MaxLabelLength: 12}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := graph.WriteDOT(&buf, exportGraph(), tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
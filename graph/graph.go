@@ -0,0 +1,186 @@
+// Package graph builds and analyzes citation and co-authorship graphs from
+// Semantic Scholar data.
+package graph
+
+import semscholar "github.com/jmwalsh91/semscholar-go"
+
+// NodeKind distinguishes the entities a Graph can hold.
+type NodeKind string
+
+// Node kinds.
+const (
+	PaperNode  NodeKind = "paper"
+	AuthorNode NodeKind = "author"
+)
+
+// EdgeKind distinguishes the relationships a Graph can hold.
+type EdgeKind string
+
+// Edge kinds.
+const (
+	// CitesEdge points from a citing paper to the paper it cites.
+	CitesEdge EdgeKind = "cites"
+	// CoauthorEdge joins two authors who wrote a paper together.
+	CoauthorEdge EdgeKind = "coauthor"
+	// CoCitationEdge joins two papers that are cited together by another paper.
+	CoCitationEdge EdgeKind = "cocitation"
+)
+
+// Undirected reports whether edges of kind k are symmetric relationships.
+func (k EdgeKind) Undirected() bool {
+	return k == CoauthorEdge || k == CoCitationEdge
+}
+
+// Node is a paper or author in a Graph.
+type Node struct {
+	ID     string            `json:"id"`
+	Kind   NodeKind          `json:"kind"`
+	Paper  semscholar.Paper  `json:"paper,omitempty"`
+	Author semscholar.Author `json:"author,omitempty"`
+	// Depth is the number of hops from the nearest crawl seed.
+	Depth int `json:"depth"`
+	// Expanded is set once a crawl has paged through the citations and
+	// references of the paper. Papers inside the crawl depth left unexpanded
+	// by an interrupted crawl are expanded by Update.
+	Expanded bool `json:"expanded,omitempty"`
+}
+
+// Label returns the paper title or author name of the node.
+func (n *Node) Label() string {
+	if n.Kind == AuthorNode {
+		return n.Author.Name
+	}
+	return n.Paper.Title
+}
+
+// Edge is a relationship between two nodes. Undirected edges are stored once,
+// from the lexically smaller node ID to the larger one.
+type Edge struct {
+	From          string   `json:"from"`
+	To            string   `json:"to"`
+	Kind          EdgeKind `json:"kind"`
+	Weight        int      `json:"weight"`
+	Intents       []string `json:"intents,omitempty"`
+	IsInfluential bool     `json:"isInfluential,omitempty"`
+}
+
+type edgeKey struct {
+	from, to string
+	kind     EdgeKind
+}
+
+// Graph is an in-memory directed multigraph of papers and authors.
+// Nodes and edges are kept in insertion order so output is deterministic.
+type Graph struct {
+	// Meta describes how the graph was produced. Crawl fills it in.
+	Meta Metadata
+
+	nodes map[string]*Node
+	order []string
+	edges []Edge
+	index map[edgeKey]int
+	out   map[string][]int
+	in    map[string][]int
+}
+
+// New creates an empty Graph.
+func New() *Graph {
+	return &Graph{
+		nodes: make(map[string]*Node),
+		index: make(map[edgeKey]int),
+		out:   make(map[string][]int),
+		in:    make(map[string][]int),
+	}
+}
+
+// AddNode inserts n, or returns the existing node with the same ID.
+// The boolean reports whether n was newly added.
+func (g *Graph) AddNode(n *Node) (*Node, bool) {
+	if existing, ok := g.nodes[n.ID]; ok {
+		return existing, false
+	}
+	g.nodes[n.ID] = n
+	g.order = append(g.order, n.ID)
+	return n, true
+}
+
+// Node returns the node with the given ID, or nil.
+func (g *Graph) Node(id string) *Node {
+	return g.nodes[id]
+}
+
+// Nodes returns all nodes in insertion order.
+func (g *Graph) Nodes() []*Node {
+	out := make([]*Node, len(g.order))
+	for i, id := range g.order {
+		out[i] = g.nodes[id]
+	}
+	return out
+}
+
+// NumNodes returns the number of nodes.
+func (g *Graph) NumNodes() int {
+	return len(g.order)
+}
+
+// AddEdge inserts e if both endpoints exist. Adding an edge that is already
+// present increments its weight instead. It reports whether the graph changed.
+func (g *Graph) AddEdge(e Edge) bool {
+	if g.nodes[e.From] == nil || g.nodes[e.To] == nil || e.From == e.To {
+		return false
+	}
+	if e.Kind.Undirected() && e.To < e.From {
+		e.From, e.To = e.To, e.From
+	}
+	if e.Weight == 0 {
+		e.Weight = 1
+	}
+	key := edgeKey{e.From, e.To, e.Kind}
+	if i, ok := g.index[key]; ok {
+		g.edges[i].Weight += e.Weight
+		return true
+	}
+	i := len(g.edges)
+	g.edges = append(g.edges, e)
+	g.index[key] = i
+	g.out[e.From] = append(g.out[e.From], i)
+	g.in[e.To] = append(g.in[e.To], i)
+	return true
+}
+
+// HasEdge reports whether an edge of the given kind exists between from and to.
+func (g *Graph) HasEdge(from, to string, kind EdgeKind) bool {
+	if kind.Undirected() && to < from {
+		from, to = to, from
+	}
+	_, ok := g.index[edgeKey{from, to, kind}]
+	return ok
+}
+
+// Edges returns all edges in insertion order.
+func (g *Graph) Edges() []Edge {
+	return append([]Edge(nil), g.edges...)
+}
+
+// NumEdges returns the number of distinct edges.
+func (g *Graph) NumEdges() int {
+	return len(g.edges)
+}
+
+// OutEdges returns the edges leaving id.
+func (g *Graph) OutEdges(id string) []Edge {
+	return g.collect(g.out[id])
+}
+
+// InEdges returns the edges arriving at id.
+func (g *Graph) InEdges(id string) []Edge {
+	return g.collect(g.in[id])
+}
+
+func (g *Graph) collect(indices []int) []Edge {
+	out := make([]Edge, len(indices))
+	for i, idx := range indices {
+		out[i] = g.edges[idx]
+	}
+	return out
+}
@@ -0,0 +1,159 @@
+package graph_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/graph"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// build returns a graph of the nodes named in edges, each written "from>to"
+// for a CitesEdge or "from-to" for a CoauthorEdge.
+func build(edges ...string) *graph.Graph {
+	g := graph.New()
+	for _, e := range edges {
+		kind := graph.CitesEdge
+		from, to, ok := strings.Cut(e, ">")
+		if !ok {
+			kind = graph.CoauthorEdge
+			from, to, _ = strings.Cut(e, "-")
+		}
+		for _, id := range []string{from, to} {
+			nodeKind := graph.PaperNode
+			if kind == graph.CoauthorEdge {
+				nodeKind = graph.AuthorNode
+			}
+			g.AddNode(&graph.Node{ID: id, Kind: nodeKind, Paper: semscholar.Paper{PaperID: id, Title: id}})
+		}
+		g.AddEdge(graph.Edge{From: from, To: to, Kind: kind})
+	}
+	return g
+}
+
+// TestAddEdge checks that repeated edges add weight, undirected edges are
+// stored once whichever way they are given, and edges to missing nodes or
+// from a node to itself are refused.
+func TestAddEdge(t *testing.T) {
+	g := build("a>b", "a>b", "b>a", "x-y", "y-x")
+	if g.NumEdges() != 3 {
+		t.Errorf("NumEdges = %d, want 3", g.NumEdges())
+	}
+	for _, tc := range []struct {
+		from, to string
+		kind     graph.EdgeKind
+		weight   int
+	}{
+		{"a", "b", graph.CitesEdge, 2},
+		{"b", "a", graph.CitesEdge, 1},
+		{"x", "y", graph.CoauthorEdge, 2},
+	} {
+		var weight int
+		for _, e := range g.OutEdges(tc.from) {
+			if e.To == tc.to && e.Kind == tc.kind {
+				weight = e.Weight
+			}
+		}
+		if weight != tc.weight {
+			t.Errorf("%s -> %s has weight %d, want %d", tc.from, tc.to, weight, tc.weight)
+		}
+	}
+	if !g.HasEdge("y", "x", graph.CoauthorEdge) {
+		t.Error("HasEdge(y, x) = false for an undirected edge stored as x-y")
+	}
+	if g.AddEdge(graph.Edge{From: "a", To: "missing", Kind: graph.CitesEdge}) || g.AddEdge(graph.Edge{From: "a", To: "a", Kind: graph.CitesEdge}) {
+		t.Error("AddEdge accepted an edge to a missing node or a self-loop")
+	}
+}
+
+// TestCrawl crawls a small citation neighborhood and checks the nodes and
+// edges each direction, depth and node budget reach.
+func TestCrawl(t *testing.T) {
+	d := semscholartest.NewDataset()
+	for _, id := range []string{"s", "a", "b", "c", "r"} {
+		d.AddPaper(semscholar.Paper{PaperID: id, Title: "Paper " + id})
+	}
+	d.AddCitation("a", "s")
+	d.AddCitation("b", "s")
+	d.AddCitation("c", "a")
+	d.AddCitation("s", "r")
+	srv := semscholartest.NewServer(d)
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name      string
+		seeds     []string
+		depth     int
+		opts      graph.Options
+		nodes     []string
+		edges     int
+		truncated bool
+	}{
+		{"both", []string{"s"}, 1, graph.Options{}, []string{"s", "a", "b", "r"}, 3, false},
+		{"citations", []string{"s"}, 2, graph.Options{Direction: graph.Citations}, []string{"s", "a", "b", "c"}, 3, false},
+		{"references", []string{"s"}, 2, graph.Options{Direction: graph.References}, []string{"s", "r"}, 1, false},
+		{"depth zero", []string{"s"}, 0, graph.Options{}, []string{"s"}, 0, false},
+		{"budget", []string{"s"}, 2, graph.Options{Direction: graph.Citations, MaxNodes: 2}, nil, 1, true},
+		{"unknown seed", []string{"s", "missing"}, 0, graph.Options{}, []string{"s"}, 0, false},
+		{"duplicate seed", []string{"s", "s"}, 0, graph.Options{}, []string{"s"}, 0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := graph.Crawl(context.Background(), srv.Client(), tc.seeds, tc.depth, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, n := range g.Nodes() {
+				ids = append(ids, n.ID)
+			}
+			if tc.nodes != nil && !slices.Equal(ids, tc.nodes) {
+				t.Errorf("nodes %v, want %v", ids, tc.nodes)
+			}
+			if tc.truncated && g.NumNodes() != tc.opts.MaxNodes {
+				t.Errorf("%d nodes, want the budget of %d", g.NumNodes(), tc.opts.MaxNodes)
+			}
+			if g.NumEdges() != tc.edges || g.Meta.Truncated != tc.truncated {
+				t.Errorf("%d edges and Truncated %v, want %d and %v", g.NumEdges(), g.Meta.Truncated, tc.edges, tc.truncated)
+			}
+		})
+	}
+}
+
+// TestCrawlStalled checks that Crawl fails, rather than paging forever, when
+// a page of citations points back at its own offset.
+func TestCrawlStalled(t *testing.T) {
+	srv := semscholartest.NewServer(citedBy("a", "b", "c"))
+	defer srv.Close()
+	// The proxy answers as the mock does but gives every page of citations
+	// the next offset 1, which the page at offset 1 does not move past.
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, r)
+		if !strings.HasSuffix(r.URL.Path, "/citations") || rec.Code != http.StatusOK {
+			w.WriteHeader(rec.Code)
+			w.Write(rec.Body.Bytes())
+			return
+		}
+		var page map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Error(err)
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		page["next"] = max(offset, 1)
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer proxy.Close()
+
+	client := semscholar.NewClient(proxy.URL, proxy.Client())
+	_, err := graph.Crawl(context.Background(), client, []string{"s"}, 1, graph.Options{Direction: graph.Citations})
+	if err == nil || !strings.Contains(err.Error(), "did not advance") {
+		t.Errorf("Crawl error = %v, want paging that did not advance", err)
+	}
+}
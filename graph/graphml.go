@@ -0,0 +1,132 @@
+package graph
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type graphmlDoc struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphmlKey `xml:"key"`
+	Graph   graphmlGraph `xml:"graph"`
+}
+
+type graphmlKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphmlGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphmlNode `xml:"node"`
+	Edges       []graphmlEdge `xml:"edge"`
+}
+
+type graphmlNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphmlData `xml:"data"`
+}
+
+type graphmlEdge struct {
+	Source   string        `xml:"source,attr"`
+	Target   string        `xml:"target,attr"`
+	Directed string        `xml:"directed,attr,omitempty"`
+	Data     []graphmlData `xml:"data"`
+}
+
+type graphmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+var graphmlKeys = []graphmlKey{
+	{ID: "kind", For: "node", AttrName: "kind", AttrType: "string"},
+	{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+	{ID: "title", For: "node", AttrName: "title", AttrType: "string"},
+	{ID: "year", For: "node", AttrName: "year", AttrType: "int"},
+	{ID: "venue", For: "node", AttrName: "venue", AttrType: "string"},
+	{ID: "citationCount", For: "node", AttrName: "citationCount", AttrType: "int"},
+	{ID: "paperCount", For: "node", AttrName: "paperCount", AttrType: "int"},
+	{ID: "hIndex", For: "node", AttrName: "hIndex", AttrType: "int"},
+	{ID: "depth", For: "node", AttrName: "depth", AttrType: "int"},
+	{ID: "edgeKind", For: "edge", AttrName: "kind", AttrType: "string"},
+	{ID: "weight", For: "edge", AttrName: "weight", AttrType: "int"},
+	{ID: "intents", For: "edge", AttrName: "intents", AttrType: "string"},
+	{ID: "isInfluential", For: "edge", AttrName: "isInfluential", AttrType: "boolean"},
+}
+
+// WriteGraphML serializes g as GraphML, readable by Gephi, yEd and most graph
+// libraries. Papers carry title, year, venue and citation count attributes;
+// authors carry paper count and h-index. Co-authorship and co-citation edges
+// are undirected.
+func WriteGraphML(w io.Writer, g *Graph) error {
+	doc := graphmlDoc{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  graphmlKeys,
+		Graph: graphmlGraph{ID: "G", EdgeDefault: "directed"},
+	}
+	for _, n := range g.Nodes() {
+		node := graphmlNode{ID: n.ID}
+		add := func(key, value string) {
+			node.Data = append(node.Data, graphmlData{Key: key, Value: value})
+		}
+		add("kind", string(n.Kind))
+		add("label", n.Label())
+		add("depth", strconv.Itoa(n.Depth))
+		switch n.Kind {
+		case AuthorNode:
+			if n.Author.PaperCount.Valid {
+				add("paperCount", n.Author.PaperCount.String())
+			}
+			if n.Author.HIndex.Valid {
+				add("hIndex", n.Author.HIndex.String())
+			}
+		default:
+			add("title", n.Paper.Title)
+			if year := n.Paper.PublishedYear(); year != 0 {
+				add("year", strconv.Itoa(year))
+			}
+			if n.Paper.Venue != "" {
+				add("venue", n.Paper.Venue)
+			}
+			if n.Paper.CitationCount.Valid {
+				add("citationCount", n.Paper.CitationCount.String())
+			}
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	for _, e := range g.Edges() {
+		edge := graphmlEdge{Source: e.From, Target: e.To}
+		if e.Kind.Undirected() {
+			edge.Directed = "false"
+		}
+		edge.Data = append(edge.Data,
+			graphmlData{Key: "edgeKind", Value: string(e.Kind)},
+			graphmlData{Key: "weight", Value: strconv.Itoa(e.Weight)},
+		)
+		if len(e.Intents) > 0 {
+			edge.Data = append(edge.Data, graphmlData{Key: "intents", Value: strings.Join(e.Intents, ",")})
+		}
+		if e.Kind == CitesEdge {
+			edge.Data = append(edge.Data, graphmlData{Key: "isInfluential", Value: strconv.FormatBool(e.IsInfluential)})
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
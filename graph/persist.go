@@ -0,0 +1,112 @@
+package graph
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// snapshotVersion is bumped whenever the on-disk format changes incompatibly.
+const snapshotVersion = 1
+
+// Metadata records how a graph was produced so saved crawls can be interpreted
+// and refreshed later.
+type Metadata struct {
+	Seeds     []string  `json:"seeds,omitempty"`
+	Depth     int       `json:"depth"`
+	Direction Direction `json:"direction"`
+	Fields    string    `json:"fields,omitempty"`
+	MaxNodes  int       `json:"maxNodes,omitempty"`
+	CrawledAt time.Time `json:"crawledAt"`
+	// UpdatedAt is set by Update.
+	UpdatedAt time.Time `json:"updatedAt,omitzero"`
+	BaseURL   string    `json:"baseUrl,omitempty"`
+	// APISnapshot optionally labels the API state the crawl saw, e.g. the
+	// dataset release ID that was current at the time.
+	APISnapshot string `json:"apiSnapshot,omitempty"`
+	// Truncated is set when the node budget stopped the crawl from adding papers.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+type snapshot struct {
+	Version int      `json:"version"`
+	Meta    Metadata `json:"meta"`
+	Nodes   []*Node  `json:"nodes"`
+	Edges   []Edge   `json:"edges"`
+}
+
+// Save writes g, including its metadata, to w as gzip-compressed JSON.
+func (g *Graph) Save(w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	snap := snapshot{Version: snapshotVersion, Meta: g.Meta, Nodes: g.Nodes(), Edges: g.edges}
+	if err := json.NewEncoder(zw).Encode(snap); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// Load reads a graph written by Save.
+func Load(r io.Reader) (*Graph, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	var snap snapshot
+	if err := json.NewDecoder(zr).Decode(&snap); err != nil {
+		return nil, err
+	}
+	// The checksum at the end of the stream is only checked once it is read.
+	if _, err := io.Copy(io.Discard, zr); err != nil {
+		return nil, err
+	}
+	if snap.Version != snapshotVersion {
+		return nil, fmt.Errorf("graph: unsupported snapshot version %d", snap.Version)
+	}
+	g := New()
+	g.Meta = snap.Meta
+	for _, n := range snap.Nodes {
+		if n == nil || n.ID == "" {
+			return nil, fmt.Errorf("graph: snapshot contains a node without an ID")
+		}
+		g.AddNode(n)
+	}
+	for _, e := range snap.Edges {
+		if !g.AddEdge(e) {
+			return nil, fmt.Errorf("graph: snapshot edge %s -> %s references a missing node", e.From, e.To)
+		}
+	}
+	return g, nil
+}
+
+// SaveFile writes g to the named file, replacing it atomically.
+func (g *Graph) SaveFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".graph-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := g.Save(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadFile reads a graph from the named file.
+func LoadFile(path string) (*Graph, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
@@ -0,0 +1,78 @@
+package graph_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jmwalsh91/semscholar-go/graph"
+)
+
+// TestSaveLoad checks that a graph saved to a file loads back with the same
+// metadata, nodes and edges.
+func TestSaveLoad(t *testing.T) {
+	g := exportGraph()
+	g.Meta = graph.Metadata{
+		Seeds:     []string{"p1"},
+		Depth:     2,
+		Direction: graph.Both,
+		Fields:    "title,year",
+		CrawledAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Truncated: true,
+	}
+	path := filepath.Join(t.TempDir(), "graph.json.gz")
+	if err := g.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := graph.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Meta, g.Meta) {
+		t.Errorf("metadata = %+v, want %+v", got.Meta, g.Meta)
+	}
+	if !reflect.DeepEqual(got.Nodes(), g.Nodes()) {
+		t.Errorf("nodes = %+v, want %+v", got.Nodes(), g.Nodes())
+	}
+	if !reflect.DeepEqual(got.Edges(), g.Edges()) {
+		t.Errorf("edges = %+v, want %+v", got.Edges(), g.Edges())
+	}
+}
+
+// TestLoadCorrupt checks that Load fails on truncated, corrupt and
+// inconsistent snapshots.
+func TestLoadCorrupt(t *testing.T) {
+	var saved bytes.Buffer
+	if err := exportGraph().Save(&saved); err != nil {
+		t.Fatal(err)
+	}
+	gzipped := func(s string) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(s))
+		zw.Close()
+		return buf.Bytes()
+	}
+	flipped := bytes.Clone(saved.Bytes())
+	flipped[len(flipped)/2] ^= 0xff
+	tests := map[string][]byte{
+		"empty":             nil,
+		"not gzip":          []byte(`{"version":1}`),
+		"truncated":         saved.Bytes()[:saved.Len()/2],
+		"flipped byte":      flipped,
+		"not JSON":          gzipped("graph"),
+		"version":           gzipped(`{"version":99,"nodes":[],"edges":[]}`),
+		"node without ID":   gzipped(`{"version":1,"nodes":[{"kind":"paper"}],"edges":[]}`),
+		"edge without node": gzipped(`{"version":1,"nodes":[{"id":"a","kind":"paper"}],"edges":[{"from":"a","to":"b","kind":"cites"}]}`),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if g, err := graph.Load(bytes.NewReader(data)); err == nil {
+				t.Errorf("Load = %d nodes, want an error", len(g.Nodes()))
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	reportTopN = 10
+	// maxPathSources bounds the BFS sources used for AveragePathLength so
+	// reports on large crawls stay fast.
+	maxPathSources = 1000
+)
+
+// DegreeBucket is one bar of a degree histogram.
+type DegreeBucket struct {
+	Degree int `json:"degree"`
+	Count  int `json:"count"`
+}
+
+// RankedNode is a node listed in a report.
+type RankedNode struct {
+	ID            string `json:"id"`
+	Label         string `json:"label"`
+	CitationCount int    `json:"citationCount"`
+	InDegree      int    `json:"inDegree"`
+}
+
+// Report summarizes the structure of a graph.
+type Report struct {
+	Meta              Metadata       `json:"meta"`
+	Nodes             int            `json:"nodes"`
+	Edges             int            `json:"edges"`
+	InDegree          []DegreeBucket `json:"inDegree"`
+	OutDegree         []DegreeBucket `json:"outDegree"`
+	MostCited         []RankedNode   `json:"mostCited"`
+	MostCitedInGraph  []RankedNode   `json:"mostCitedInGraph"`
+	LargestSCC        []string       `json:"largestScc"`
+	AveragePathLength float64        `json:"averagePathLength"`
+	PathLengthSampled bool           `json:"pathLengthSampled,omitempty"`
+}
+
+// Report computes degree distributions, the most cited papers (globally and
+// within the graph), the largest strongly connected component and the average
+// shortest path length between connected pairs. Undirected edges count in both
+// directions. On graphs with more than 1000 nodes, the path length is estimated
+// from an evenly spaced sample of source nodes.
+func (g *Graph) Report() Report {
+	r := Report{Meta: g.Meta, Nodes: g.NumNodes(), Edges: g.NumEdges()}
+	adj := g.adjacency()
+	in := make([]int, len(g.order))
+	for _, targets := range adj {
+		for _, t := range targets {
+			in[t]++
+		}
+	}
+	inHist := make(map[int]int)
+	outHist := make(map[int]int)
+	ranked := make([]RankedNode, len(g.order))
+	for i, id := range g.order {
+		inHist[in[i]]++
+		outHist[len(adj[i])]++
+		n := g.nodes[id]
+		ranked[i] = RankedNode{ID: id, Label: n.Label(), CitationCount: n.Paper.CitationCount.Int, InDegree: in[i]}
+	}
+	r.InDegree = histogram(inHist)
+	r.OutDegree = histogram(outHist)
+
+	r.MostCited = topRanked(ranked, func(a, b RankedNode) bool { return a.CitationCount > b.CitationCount })
+	r.MostCitedInGraph = topRanked(ranked, func(a, b RankedNode) bool { return a.InDegree > b.InDegree })
+
+	for _, comp := range stronglyConnected(adj) {
+		if len(comp) > len(r.LargestSCC) {
+			r.LargestSCC = r.LargestSCC[:0]
+			for _, i := range comp {
+				r.LargestSCC = append(r.LargestSCC, g.order[i])
+			}
+		}
+	}
+	r.AveragePathLength, r.PathLengthSampled = averagePathLength(adj)
+	return r
+}
+
+// JSON renders the report as indented JSON.
+func (r Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// Markdown renders the report as a Markdown document.
+func (r Report) Markdown() string {
+	var b strings.Builder
+	b.WriteString("# Graph report\n\n")
+	if len(r.Meta.Seeds) > 0 {
+		fmt.Fprintf(&b, "Crawled from %d seed(s) to depth %d on %s.\n\n", len(r.Meta.Seeds), r.Meta.Depth, r.Meta.CrawledAt.Format("2006-01-02"))
+	}
+	fmt.Fprintf(&b, "- Nodes: %d\n- Edges: %d\n- Largest strongly connected component: %d nodes\n", r.Nodes, r.Edges, len(r.LargestSCC))
+	fmt.Fprintf(&b, "- Average path length: %.2f", r.AveragePathLength)
+	if r.PathLengthSampled {
+		b.WriteString(" (sampled)")
+	}
+	b.WriteString("\n\n")
+
+	writeRanked := func(title string, nodes []RankedNode) {
+		fmt.Fprintf(&b, "## %s\n\n| # | Paper | Citations | Cited in graph |\n|---|---|---|---|\n", title)
+		for i, n := range nodes {
+			fmt.Fprintf(&b, "| %d | %s | %d | %d |\n", i+1, markdownCell(n.Label), n.CitationCount, n.InDegree)
+		}
+		b.WriteString("\n")
+	}
+	writeRanked("Most cited", r.MostCited)
+	writeRanked("Most cited within the graph", r.MostCitedInGraph)
+
+	writeHist := func(title string, buckets []DegreeBucket) {
+		fmt.Fprintf(&b, "## %s\n\n| Degree | Nodes |\n|---|---|\n", title)
+		for _, bucket := range buckets {
+			fmt.Fprintf(&b, "| %d | %d |\n", bucket.Degree, bucket.Count)
+		}
+		b.WriteString("\n")
+	}
+	writeHist("In-degree distribution", r.InDegree)
+	writeHist("Out-degree distribution", r.OutDegree)
+	return b.String()
+}
+
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// adjacency returns successor lists by node position, with undirected edges in both directions.
+func (g *Graph) adjacency() [][]int {
+	pos, links := g.weightedLinks()
+	adj := make([][]int, len(pos))
+	for _, l := range links {
+		adj[l.from] = append(adj[l.from], l.to)
+	}
+	return adj
+}
+
+func histogram(m map[int]int) []DegreeBucket {
+	out := make([]DegreeBucket, 0, len(m))
+	for d, c := range m {
+		out = append(out, DegreeBucket{Degree: d, Count: c})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Degree < out[j].Degree })
+	return out
+}
+
+func topRanked(nodes []RankedNode, less func(a, b RankedNode) bool) []RankedNode {
+	sorted := append([]RankedNode(nil), nodes...)
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	if len(sorted) > reportTopN {
+		sorted = sorted[:reportTopN]
+	}
+	return sorted
+}
+
+// stronglyConnected returns the strongly connected components of adj using an
+// iterative version of Tarjan's algorithm.
+func stronglyConnected(adj [][]int) [][]int {
+	n := len(adj)
+	index := make([]int, n)
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+	var stack, comps [][]int
+	var sccStack []int
+	next := 0
+	for root := 0; root < n; root++ {
+		if index[root] >= 0 {
+			continue
+		}
+		stack = append(stack[:0], []int{root, 0})
+		index[root], low[root] = next, next
+		next++
+		sccStack = append(sccStack, root)
+		onStack[root] = true
+		for len(stack) > 0 {
+			frame := stack[len(stack)-1]
+			v, i := frame[0], frame[1]
+			if i < len(adj[v]) {
+				frame[1]++
+				w := adj[v][i]
+				if index[w] < 0 {
+					index[w], low[w] = next, next
+					next++
+					sccStack = append(sccStack, w)
+					onStack[w] = true
+					stack = append(stack, []int{w, 0})
+				} else if onStack[w] && index[w] < low[v] {
+					low[v] = index[w]
+				}
+				continue
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1][0]
+				if low[v] < low[parent] {
+					low[parent] = low[v]
+				}
+			}
+			if low[v] == index[v] {
+				var comp []int
+				for {
+					w := sccStack[len(sccStack)-1]
+					sccStack = sccStack[:len(sccStack)-1]
+					onStack[w] = false
+					comp = append(comp, w)
+					if w == v {
+						break
+					}
+				}
+				comps = append(comps, comp)
+			}
+		}
+	}
+	return comps
+}
+
+// averagePathLength returns the mean BFS distance over all ordered pairs of
+// distinct nodes connected by a path, and whether sources were sampled.
+func averagePathLength(adj [][]int) (float64, bool) {
+	n := len(adj)
+	step, sampled := 1, false
+	if n > maxPathSources {
+		step, sampled = (n+maxPathSources-1)/maxPathSources, true
+	}
+	dist := make([]int, n)
+	total, pairs := 0, 0
+	queue := make([]int, 0, n)
+	for src := 0; src < n; src += step {
+		for i := range dist {
+			dist[i] = -1
+		}
+		dist[src] = 0
+		queue = append(queue[:0], src)
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			for _, w := range adj[v] {
+				if dist[w] < 0 {
+					dist[w] = dist[v] + 1
+					total += dist[w]
+					pairs++
+					queue = append(queue, w)
+				}
+			}
+		}
+	}
+	if pairs == 0 {
+		return 0, sampled
+	}
+	return float64(total) / float64(pairs), sampled
+}
@@ -0,0 +1,69 @@
+package graph_test
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/graph"
+)
+
+// TestReport checks the strongly connected components, path lengths and
+// degree histograms of small graphs.
+func TestReport(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		g        *graph.Graph
+		scc      []string
+		avgPath  float64
+		inDegree []graph.DegreeBucket
+	}{
+		{"empty", graph.New(), nil, 0, nil},
+		{"chain", build("a>b", "b>c"), nil, 4.0 / 3, []graph.DegreeBucket{{0, 1}, {1, 2}}},
+		{"cycle with tail", build("d>a", "a>b", "b>c", "c>a"), []string{"a", "b", "c"}, 15.0 / 9, []graph.DegreeBucket{{0, 1}, {1, 2}, {2, 1}}},
+		{"two cycles", build("a>b", "b>a", "c>d", "d>e", "e>c", "b>c"), []string{"c", "d", "e"}, 26.0 / 14, nil},
+		{"coauthors", build("x-y", "y-z"), []string{"x", "y", "z"}, 8.0 / 6, []graph.DegreeBucket{{1, 2}, {2, 1}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := tc.g.Report()
+			if r.Nodes != tc.g.NumNodes() || r.Edges != tc.g.NumEdges() {
+				t.Errorf("report counts %d nodes and %d edges, want %d and %d", r.Nodes, r.Edges, tc.g.NumNodes(), tc.g.NumEdges())
+			}
+			// Without a cycle every component is a single node.
+			if tc.scc == nil && tc.g.NumNodes() > 0 {
+				if len(r.LargestSCC) != 1 {
+					t.Errorf("LargestSCC %v, want a single node", r.LargestSCC)
+				}
+			} else if got := slices.Sorted(slices.Values(r.LargestSCC)); !slices.Equal(got, tc.scc) {
+				t.Errorf("LargestSCC %v, want %v", got, tc.scc)
+			}
+			if math.Abs(r.AveragePathLength-tc.avgPath) > 1e-9 || r.PathLengthSampled {
+				t.Errorf("AveragePathLength %v (sampled %v), want %v", r.AveragePathLength, r.PathLengthSampled, tc.avgPath)
+			}
+			if tc.inDegree != nil && !slices.Equal(r.InDegree, tc.inDegree) {
+				t.Errorf("InDegree %v, want %v", r.InDegree, tc.inDegree)
+			}
+		})
+	}
+}
+
+// TestReportRendering checks that the JSON rendering decodes back and the
+// Markdown escapes table cells.
+func TestReportRendering(t *testing.T) {
+	g := build("a>b")
+	g.Node("b").Paper.Title = "Pipes | and\nnewlines"
+	r := g.Report()
+	data, err := r.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back graph.Report
+	if err := json.Unmarshal(data, &back); err != nil || back.Nodes != 2 || back.MostCitedInGraph[0].ID != "b" {
+		t.Errorf("JSON decoded to %+v, %v", back, err)
+	}
+	if md := r.Markdown(); !strings.Contains(md, `Pipes \| and newlines`) {
+		t.Errorf("Markdown does not escape the title:\n%s", md)
+	}
+}
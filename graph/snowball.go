@@ -0,0 +1,229 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// DefaultSnowballFields is requested for every paper considered by Snowball.
+const DefaultSnowballFields = "title,year,publicationDate,venue,fieldsOfStudy,citationCount"
+
+// Predicate decides whether a paper belongs in a candidate pool.
+type Predicate func(semscholar.Paper) bool
+
+// YearBetween accepts papers published in [from, to]. A zero bound is open, and
+// papers without a known year are accepted.
+func YearBetween(from, to int) Predicate {
+	return func(p semscholar.Paper) bool {
+		year := p.PublishedYear()
+		if year == 0 {
+			return true
+		}
+		return (from == 0 || year >= from) && (to == 0 || year <= to)
+	}
+}
+
+// VenueIn accepts papers whose venue matches one of venues, ignoring case.
+func VenueIn(venues ...string) Predicate {
+	return func(p semscholar.Paper) bool {
+		for _, v := range venues {
+			if strings.EqualFold(strings.TrimSpace(p.Venue), v) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FieldIn accepts papers tagged with at least one of fields, ignoring case.
+func FieldIn(fields ...string) Predicate {
+	return func(p semscholar.Paper) bool {
+		for _, have := range p.FieldsOfStudy {
+			for _, want := range fields {
+				if strings.EqualFold(have, want) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// All accepts papers accepted by every predicate.
+func All(preds ...Predicate) Predicate {
+	return func(p semscholar.Paper) bool {
+		for _, pred := range preds {
+			if !pred(p) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// SnowballOptions configures Snowball. Zero fields use defaults.
+type SnowballOptions struct {
+	// Direction selects forward (Citations) or backward (References) snowballing; it defaults to Both.
+	Direction Direction
+	// Include screens candidates; nil accepts everything.
+	Include Predicate
+	// MaxIterations bounds the number of snowballing rounds; it defaults to 5.
+	MaxIterations int
+	// MaxPapers bounds the size of the candidate pool, seeds included; it defaults to 1000.
+	MaxPapers int
+	// MaxEdgesPerPaper bounds citations and references read per paper and direction; it defaults to 1000.
+	MaxEdgesPerPaper int
+	// Fields is requested for every paper; it defaults to DefaultSnowballFields.
+	Fields string
+}
+
+// Reasons recorded on SnowballEntry.
+const (
+	ReasonSeed     = "seed"
+	ReasonIncluded = "included"
+	ReasonExcluded = "excluded by predicate"
+	ReasonBudget   = "pool budget exhausted"
+)
+
+// SnowballEntry records how a paper was encountered during snowballing.
+type SnowballEntry struct {
+	Paper semscholar.Paper
+	// Iteration is 0 for seeds and n for papers found in the n-th round.
+	Iteration int
+	// Via is the pool paper whose citations or references surfaced this one.
+	Via string
+	// Direction is Citations for forward and References for backward snowballing.
+	Direction Direction
+	Included  bool
+	Reason    string
+}
+
+// SnowballResult is the outcome of Snowball.
+type SnowballResult struct {
+	// Pool holds the included papers in the order they entered.
+	Pool []semscholar.Paper
+	// Log holds every distinct paper encountered, included or not, in order.
+	Log        []SnowballEntry
+	Iterations int
+	// Converged is true when a round found no new papers to include. It is
+	// false when the run stopped at MaxIterations or MaxPapers instead.
+	Converged bool
+	// BudgetExhausted is true when the pool reached MaxPapers and papers that
+	// passed screening were turned away; their log entries say so.
+	BudgetExhausted bool
+}
+
+// Snowball runs forward and/or backward snowballing from seedIDs, as used in
+// systematic literature reviews. Each round expands the papers included in the
+// previous round, screens every newly encountered paper with opts.Include, and
+// adds accepted ones to the pool. It stops when a round adds nothing, after
+// MaxIterations rounds, or when the pool reaches MaxPapers. If ctx ends early,
+// the partial result is returned with the context's error.
+func Snowball(ctx context.Context, client *semscholar.Client, seedIDs []string, opts SnowballOptions) (*SnowballResult, error) {
+	if opts.MaxIterations == 0 {
+		opts.MaxIterations = 5
+	}
+	if opts.MaxPapers == 0 {
+		opts.MaxPapers = 1000
+	}
+	if opts.Fields == "" {
+		opts.Fields = DefaultSnowballFields
+	}
+	include := opts.Include
+	if include == nil {
+		include = func(semscholar.Paper) bool { return true }
+	}
+	c := &crawler{client: client, opts: Options{
+		Direction:       opts.Direction,
+		MaxEdgesPerNode: opts.MaxEdgesPerPaper,
+		Fields:          opts.Fields,
+	}.withDefaults()}
+
+	result := &SnowballResult{}
+	seen := make(map[string]bool)
+	seeds, err := c.fetch(ctx, seedIDs)
+	if err != nil {
+		return result, err
+	}
+	var current []string
+	for _, p := range seeds {
+		if p.PaperID == "" || seen[p.PaperID] {
+			continue
+		}
+		seen[p.PaperID] = true
+		result.Pool = append(result.Pool, p)
+		result.Log = append(result.Log, SnowballEntry{Paper: p, Included: true, Reason: ReasonSeed})
+		current = append(current, p.PaperID)
+	}
+
+	for iter := 1; iter <= opts.MaxIterations && len(current) > 0; iter++ {
+		result.Iterations = iter
+		var next []string
+		for _, id := range current {
+			for _, dir := range []Direction{Citations, References} {
+				if c.opts.Direction&dir == 0 {
+					continue
+				}
+				neighbors, err := c.neighbors(ctx, id, dir == Citations)
+				if err != nil {
+					return result, err
+				}
+				for _, p := range neighbors {
+					if seen[p.PaperID] {
+						continue
+					}
+					seen[p.PaperID] = true
+					entry := SnowballEntry{Paper: p, Iteration: iter, Via: id, Direction: dir}
+					switch {
+					case !include(p):
+						entry.Reason = ReasonExcluded
+					case len(result.Pool) >= opts.MaxPapers:
+						entry.Reason = ReasonBudget
+						result.BudgetExhausted = true
+					default:
+						entry.Included = true
+						entry.Reason = ReasonIncluded
+						result.Pool = append(result.Pool, p)
+						next = append(next, p.PaperID)
+					}
+					result.Log = append(result.Log, entry)
+				}
+			}
+		}
+		current = next
+	}
+	result.Converged = len(current) == 0 && !result.BudgetExhausted
+	return result, nil
+}
+
+// neighbors pages through the citing (or cited) papers of id.
+func (c *crawler) neighbors(ctx context.Context, id string, citations bool) ([]semscholar.Paper, error) {
+	var out []semscholar.Paper
+	for offset := 0; offset < c.opts.MaxEdgesPerNode; {
+		limit := min(pageSize, c.opts.MaxEdgesPerNode-offset)
+		page, err := c.page(ctx, id, offset, limit, c.opts.Fields, citations)
+		if err != nil {
+			if errors.Is(err, semscholar.ErrNotFound) {
+				return out, nil
+			}
+			return out, err
+		}
+		for _, edge := range page.Data {
+			p := edge.CitedPaper
+			if citations {
+				p = edge.CitingPaper
+			}
+			if p.PaperID != "" {
+				out = append(out, p)
+			}
+		}
+		if page.Next == 0 || len(page.Data) == 0 {
+			break
+		}
+		offset = page.Next
+	}
+	return out, nil
+}
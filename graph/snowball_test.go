@@ -0,0 +1,81 @@
+package graph_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/graph"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestSnowball snowballs from a seed cited by a and by b, which the year
+// screen excludes, citing r and with a cited by c, and checks the pool, the
+// log and how each run stopped.
+func TestSnowball(t *testing.T) {
+	d := semscholartest.NewDataset()
+	for id, year := range map[string]int{"s": 2020, "a": 2021, "b": 2005, "c": 2022, "r": 2015} {
+		d.AddPaper(semscholar.Paper{PaperID: id, Title: "Paper " + id, Year: year})
+	}
+	d.AddCitation("a", "s")
+	d.AddCitation("b", "s")
+	d.AddCitation("c", "a")
+	d.AddCitation("s", "r")
+	srv := semscholartest.NewServer(d)
+	defer srv.Close()
+	include := graph.YearBetween(2010, 0)
+
+	for _, tc := range []struct {
+		name       string
+		opts       graph.SnowballOptions
+		pool       []string
+		iterations int
+		converged  bool
+		exhausted  bool
+	}{
+		{"converged", graph.SnowballOptions{Include: include}, []string{"s", "a", "r", "c"}, 3, true, false},
+		{"forward", graph.SnowballOptions{Include: include, Direction: graph.Citations}, []string{"s", "a", "c"}, 3, true, false},
+		{"iterations", graph.SnowballOptions{Include: include, MaxIterations: 1}, []string{"s", "a", "r"}, 1, false, false},
+		{"budget", graph.SnowballOptions{Include: include, MaxPapers: 2}, []string{"s", "a"}, 2, false, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := graph.Snowball(context.Background(), srv.Client(), []string{"s"}, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var pool []string
+			for _, p := range result.Pool {
+				pool = append(pool, p.PaperID)
+			}
+			if !slices.Equal(pool, tc.pool) {
+				t.Errorf("pool %v, want %v", pool, tc.pool)
+			}
+			if result.Iterations != tc.iterations || result.Converged != tc.converged || result.BudgetExhausted != tc.exhausted {
+				t.Errorf("%d iterations, Converged %v, BudgetExhausted %v, want %d, %v, %v",
+					result.Iterations, result.Converged, result.BudgetExhausted, tc.iterations, tc.converged, tc.exhausted)
+			}
+		})
+	}
+
+	result, err := graph.Snowball(context.Background(), srv.Client(), []string{"s"}, graph.SnowballOptions{Include: include, MaxPapers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reasons := make(map[string]graph.SnowballEntry)
+	for _, e := range result.Log {
+		reasons[e.Paper.PaperID] = e
+	}
+	for id, want := range map[string]graph.SnowballEntry{
+		"s": {Reason: graph.ReasonSeed, Included: true},
+		"a": {Reason: graph.ReasonIncluded, Included: true, Iteration: 1, Via: "s", Direction: graph.Citations},
+		"b": {Reason: graph.ReasonExcluded, Iteration: 1, Via: "s", Direction: graph.Citations},
+		"r": {Reason: graph.ReasonBudget, Iteration: 1, Via: "s", Direction: graph.References},
+	} {
+		got := reasons[id]
+		if got.Reason != want.Reason || got.Included != want.Included || got.Iteration != want.Iteration || got.Via != want.Via || got.Direction != want.Direction {
+			t.Errorf("log entry of %s is %q, included %v, round %d via %q in direction %d; want %q, %v, %d, %q, %d",
+				id, got.Reason, got.Included, got.Iteration, got.Via, got.Direction, want.Reason, want.Included, want.Iteration, want.Via, want.Direction)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// UpdateStats summarizes what an Update changed.
+type UpdateStats struct {
+	// Refreshed is the number of papers whose metadata was refetched.
+	Refreshed int
+	// Changed is the number of papers whose citation count moved.
+	Changed int
+	// Reexpanded is the number of papers whose citations were paged again.
+	Reexpanded int
+	// Resumed is the number of papers an interrupted crawl left unexpanded
+	// that were expanded.
+	Resumed  int
+	NewNodes int
+	NewEdges int
+}
+
+// Update refreshes a previously crawled graph instead of recrawling it. Only
+// papers inside the crawl depth have their metadata refetched, in batches,
+// since the count of a paper at the edge of the crawl decides nothing. Of
+// those, papers left unexpanded by an interrupted crawl are expanded, and
+// papers already expanded whose citation count changed have their citations
+// paged again; papers discovered either way are expanded as in the original
+// crawl. New nodes and edges are appended to g. The crawl direction, depth,
+// fields and node budget come from g.Meta unless set in opts.
+func Update(ctx context.Context, client *semscholar.Client, g *Graph, opts Options) (stats UpdateStats, err error) {
+	if opts.Direction == 0 {
+		opts.Direction = g.Meta.Direction
+	}
+	if opts.Fields == "" {
+		opts.Fields = g.Meta.Fields
+	}
+	if opts.MaxNodes == 0 {
+		opts.MaxNodes = g.Meta.MaxNodes
+	}
+	opts = opts.withDefaults()
+	c := &crawler{client: client, opts: opts, g: g}
+	nodesBefore, edgesBefore := g.NumNodes(), g.NumEdges()
+	defer func() {
+		stats.NewNodes = g.NumNodes() - nodesBefore
+		stats.NewEdges = g.NumEdges() - edgesBefore
+	}()
+
+	var ids, frontier []string
+	defer func() {
+		for _, id := range frontier {
+			if g.Node(id).Expanded {
+				stats.Resumed++
+			}
+		}
+	}()
+	for _, n := range g.Nodes() {
+		if n.Kind != PaperNode || n.Depth >= g.Meta.Depth {
+			continue
+		}
+		ids = append(ids, n.ID)
+		if !n.Expanded {
+			frontier = append(frontier, n.ID)
+		}
+	}
+	papers, err := c.fetch(ctx, ids)
+	if err != nil {
+		return stats, err
+	}
+	var changed []string
+	for i, p := range papers {
+		if p.PaperID == "" {
+			continue
+		}
+		n := g.Node(ids[i])
+		stats.Refreshed++
+		if p.CitationCount != n.Paper.CitationCount {
+			stats.Changed++
+			if n.Expanded {
+				changed = append(changed, n.ID)
+			}
+		}
+		n.Paper = p
+	}
+
+	// Citations only ever accrue, so a changed count is what warrants a refetch;
+	// reference lists of expanded papers are treated as fixed.
+	queue := frontier
+	if opts.Direction&Citations != 0 {
+		for _, id := range changed {
+			found, err := c.expand(ctx, id, g.Node(id).Depth+1, true)
+			queue = append(queue, found...)
+			stats.Reexpanded++
+			if err != nil {
+				return stats, err
+			}
+		}
+	}
+	if err := c.bfs(ctx, queue, g.Meta.Depth); err != nil {
+		return stats, err
+	}
+	g.Meta.UpdatedAt = time.Now().UTC()
+	return stats, nil
+}
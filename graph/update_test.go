@@ -0,0 +1,94 @@
+package graph_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/graph"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// citedBy returns a dataset in which the papers citing cite the paper "s",
+// whose citation count is theirs.
+func citedBy(citing ...string) *semscholartest.Dataset {
+	d := semscholartest.NewDataset()
+	d.AddPaper(semscholar.Paper{PaperID: "s", Title: "Seed", CitationCount: semscholar.NewNullInt(len(citing))})
+	for _, id := range citing {
+		d.AddPaper(semscholar.Paper{PaperID: id, Title: "Paper " + id})
+		d.AddCitation(id, "s")
+	}
+	return d
+}
+
+// TestUpdate checks that Update refreshes only papers inside the crawl depth,
+// pages again the citations of expanded papers whose count changed, and
+// expands papers an interrupted crawl left unexpanded, also after a snapshot
+// round trip.
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	opts := graph.Options{Direction: graph.Citations}
+
+	t.Run("changed", func(t *testing.T) {
+		srv := semscholartest.NewServer(citedBy("a"))
+		defer srv.Close()
+		g, err := graph.Crawl(ctx, srv.Client(), []string{"s"}, 1, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.NumNodes() != 2 || !g.Node("s").Expanded || g.Node("a").Expanded {
+			t.Fatalf("crawl gave %d nodes, s expanded %v, a expanded %v", g.NumNodes(), g.Node("s").Expanded, g.Node("a").Expanded)
+		}
+		srv.Update(func(d *semscholartest.Dataset) {
+			*d = *citedBy("a", "b")
+		})
+		stats, err := graph.Update(ctx, srv.Client(), g, graph.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := graph.UpdateStats{Refreshed: 1, Changed: 1, Reexpanded: 1, NewNodes: 1, NewEdges: 1}
+		if stats != want {
+			t.Errorf("stats = %+v, want %+v", stats, want)
+		}
+		if !g.HasEdge("b", "s", graph.CitesEdge) || g.Meta.UpdatedAt.IsZero() {
+			t.Errorf("b -> s recorded %v, UpdatedAt %v", g.HasEdge("b", "s", graph.CitesEdge), g.Meta.UpdatedAt)
+		}
+
+		stats, err = graph.Update(ctx, srv.Client(), g, graph.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (graph.UpdateStats{Refreshed: 1}); stats != want {
+			t.Errorf("unchanged update stats = %+v, want %+v", stats, want)
+		}
+	})
+
+	t.Run("resumed", func(t *testing.T) {
+		srv := semscholartest.NewServer(citedBy("a", "b"))
+		defer srv.Close()
+		g := graph.New()
+		g.Meta = graph.Metadata{Seeds: []string{"s"}, Depth: 1, Direction: graph.Citations}
+		g.AddNode(&graph.Node{ID: "s", Kind: graph.PaperNode, Paper: semscholar.Paper{PaperID: "s"}})
+
+		var buf bytes.Buffer
+		if err := g.Save(&buf); err != nil {
+			t.Fatal(err)
+		}
+		g, err := graph.Load(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats, err := graph.Update(ctx, srv.Client(), g, graph.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := graph.UpdateStats{Refreshed: 1, Changed: 1, Resumed: 1, NewNodes: 2, NewEdges: 2}
+		if stats != want {
+			t.Errorf("stats = %+v, want %+v", stats, want)
+		}
+		if !g.Node("s").Expanded {
+			t.Error("s left unexpanded")
+		}
+	})
+}
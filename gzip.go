@@ -0,0 +1,58 @@
+package semscholar
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// sendOnce passes req to c.HTTPClient, asking for a gzip-compressed response and
+// decompressing it transparently; batch responses and bulk search pages with
+// abstracts shrink severalfold. Responses from clients that decompress on
+// their own arrive without Content-Encoding and pass through unchanged, as do
+// requests that already set Accept-Encoding.
+func (c *Client) sendOnce(req *http.Request) (*http.Response, error) {
+	if c.DisableCompression || req.Header.Get("Accept-Encoding") != "" {
+		return c.HTTPClient.Do(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return resp, nil
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		if err == io.EOF {
+			// An empty body is a valid, if unusual, gzip response.
+			resp.Header.Del("Content-Encoding")
+			return resp, nil
+		}
+		closeBody(resp.Body)
+		return nil, err
+	}
+	resp.Body = &gzipBody{Reader: zr, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// gzipBody decompresses a response body and closes the underlying one.
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+// Close closes the decompressor and drains and closes the underlying body, so
+// the compressed bytes a reader stopped short of do not cost the connection.
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	io.Copy(io.Discard, io.LimitReader(b.body, maxDrain))
+	return b.body.Close()
+}
@@ -0,0 +1,79 @@
+package semscholar_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// gzipped returns s gzip-compressed.
+func gzipped(s string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(s))
+	zw.Close()
+	return buf.Bytes()
+}
+
+// TestGzipResponse checks that the client asks for gzip unless compression
+// is disabled, and decodes compressed and plain responses alike.
+func TestGzipResponse(t *testing.T) {
+	const body = `{"paperId":"a","title":"Compressed"}`
+	var accepted string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accepted = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Type", "application/json")
+		if accepted == "gzip" {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(gzipped(body))
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	// The transport would otherwise ask for gzip itself.
+	hc := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	for _, disable := range []bool{false, true} {
+		c := semscholar.NewClient(srv.URL, hc)
+		c.DisableCompression = disable
+		p, err := c.GetPaper("a", "title")
+		if err != nil {
+			t.Fatalf("DisableCompression %v: %v", disable, err)
+		}
+		if p.Title != "Compressed" {
+			t.Errorf("DisableCompression %v: title %q, want Compressed", disable, p.Title)
+		}
+		if want := map[bool]string{false: "gzip", true: ""}[disable]; accepted != want {
+			t.Errorf("DisableCompression %v: Accept-Encoding %q, want %q", disable, accepted, want)
+		}
+	}
+}
+
+// TestGzipCorrupt checks that a response claiming gzip encoding whose body
+// is not a valid gzip stream fails the call.
+func TestGzipCorrupt(t *testing.T) {
+	valid := gzipped(`{"paperId":"a","title":"Compressed"}`)
+	bodies := map[string][]byte{
+		"bad header": []byte(`{"paperId":"a"}`),
+		"truncated":  valid[:len(valid)-12],
+		"bad data":   append(bytes.Clone(valid[:10]), bytes.Repeat([]byte{0xff}, 20)...),
+	}
+	for name, body := range bodies {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Content-Encoding", "gzip")
+				w.Write(body)
+			}))
+			defer srv.Close()
+			c := semscholar.NewClient(srv.URL, srv.Client())
+			if p, err := c.GetPaper("a", ""); err == nil {
+				t.Errorf("GetPaper = %+v, want an error", p)
+			}
+		})
+	}
+}
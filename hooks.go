@@ -0,0 +1,40 @@
+package semscholar
+
+import (
+	"net/http"
+	"time"
+)
+
+// Hooks are callbacks through which an application can follow the health of
+// its Semantic Scholar integration, to count failures in its own metrics or
+// alert when the API degrades, without wrapping every call. Hooks may be
+// called from many goroutines at once, so they must be safe for concurrent
+// use and should return quickly. Nil hooks are skipped.
+//
+// OnError is called from the calling goroutine. OnRetry and OnRateLimited are
+// called from the goroutine sending the request. When identical requests are
+// coalesced into one, that is a goroutine of the client's rather than any
+// caller's: the hooks are called once for all the callers, and the request
+// they are passed keeps the values of the first caller's context but not its
+// cancellation.
+type Hooks struct {
+	// OnError is called with each error a method returns or an iterator
+	// yields, and the name of the method, such as "GetPaper". Errors from
+	// invalid arguments that fail before a request is built are not reported.
+	OnError func(op string, err error)
+	// OnRetry is called before each retry the RetryPolicy makes, with the
+	// request, the retry's number starting at 1, the status of the response
+	// being retried and the delay about to be waited.
+	OnRetry func(req *http.Request, attempt, status int, delay time.Duration)
+	// OnRateLimited is called for each 429 response, retried or not, with the
+	// request and the delay the API asked for in Retry-After, or zero.
+	OnRateLimited func(req *http.Request, retryAfter time.Duration)
+}
+
+// report passes a non-nil err to the OnError hook and returns it.
+func (c *Client) report(op string, err error) error {
+	if err != nil && c.Hooks.OnError != nil {
+		c.Hooks.OnError(op, err)
+	}
+	return err
+}
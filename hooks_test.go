@@ -0,0 +1,76 @@
+package semscholar_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestHooks checks that the hooks fire on every attempt, in order: a 429
+// is reported before its retry, which waits as Retry-After asks, and an
+// error once the call returns it.
+func TestHooks(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		fault semscholartest.Fault
+		want  []string
+	}{
+		{"retried to success", semscholartest.Fault{Status: http.StatusTooManyRequests, RetryAfter: 2 * time.Second, Times: 2}, []string{
+			"rate limited 2s",
+			"retry 1 after 429",
+			"rate limited 2s",
+			"retry 2 after 429",
+		}},
+		{"retries exhausted", semscholartest.Fault{Status: http.StatusTooManyRequests}, []string{
+			"rate limited 0s",
+			"retry 1 after 429",
+			"rate limited 0s",
+			"retry 2 after 429",
+			"rate limited 0s",
+			"error GetPaper 429",
+		}},
+		{"server error", semscholartest.Fault{Status: http.StatusServiceUnavailable, Times: 1}, []string{
+			"retry 1 after 503",
+		}},
+		{"not retried", semscholartest.Fault{Status: http.StatusNotFound}, []string{
+			"error GetPaper 404",
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := semscholartest.NewServer(nil)
+			defer srv.Close()
+			srv.Inject(tt.fault)
+			client := srv.Client()
+			client.Clock = semscholartest.NewFakeClock(time.Time{})
+			client.Retry = semscholar.RetryPolicy{MaxRetries: 2}
+			var events []string
+			client.Hooks = semscholar.Hooks{
+				OnError: func(op string, err error) {
+					var apiErr *semscholar.APIError
+					if errors.As(err, &apiErr) {
+						events = append(events, fmt.Sprintf("error %s %d", op, apiErr.StatusCode))
+					}
+				},
+				OnRetry: func(req *http.Request, attempt, status int, delay time.Duration) {
+					events = append(events, fmt.Sprintf("retry %d after %d", attempt, status))
+					if delay <= 0 || tt.fault.RetryAfter > 0 && delay != tt.fault.RetryAfter {
+						t.Errorf("retry %d in %v, want the Retry-After of %v", attempt, delay, tt.fault.RetryAfter)
+					}
+				},
+				OnRateLimited: func(req *http.Request, retryAfter time.Duration) {
+					events = append(events, fmt.Sprintf("rate limited %v", retryAfter))
+				},
+			}
+			client.GetPaper(semscholartest.BERTPaperID, "title")
+			if !slices.Equal(events, tt.want) {
+				t.Errorf("events %q, want %q", events, tt.want)
+			}
+		})
+	}
+}
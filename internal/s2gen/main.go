@@ -0,0 +1,287 @@
+// Command s2gen generates the field constants of the semscholar package from
+// the Academic Graph API's OpenAPI spec, and checks the hand-written models
+// against the spec's schemas.
+//
+// Usage:
+//
+//	go run ./internal/s2gen [-spec openapi/graph.json] [-out fields_gen.go]
+//	go run ./internal/s2gen -fetch      # refresh the spec from the API
+//	go run ./internal/s2gen -check      # report drift between models and spec
+//
+// The models themselves stay hand-written, since they carry decoding behavior
+// the spec cannot express. The check lists the spec's properties a model does
+// not declare, which is informational, and fails on JSON fields a model
+// declares that the spec lacks or types differently, which is drift.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// specURL is where the API publishes its spec.
+const specURL = "https://api.semanticscholar.org/graph/v1/swagger.json"
+
+// model pairs a hand-written type with the spec schemas it decodes.
+type model struct {
+	typ     reflect.Type
+	schemas []string
+	prefix  string
+	doc     string
+	// nested are properties holding the paper of an edge, which cannot be
+	// requested by name and get no constant.
+	nested []string
+}
+
+var models = []model{
+	{reflect.TypeFor[semscholar.Paper](), []string{"FullPaper"}, "PaperField", "Paper fields, for the fields parameter of the paper endpoints.", nil},
+	{reflect.TypeFor[semscholar.Author](), []string{"AuthorWithPapers"}, "AuthorField", "Author fields, for the fields parameter of the author endpoints.", nil},
+	{reflect.TypeFor[semscholar.Citation](), []string{"Citation", "Reference"}, "CitationField", "Citation fields, for the fields parameter of the citations and references\n// endpoints, alongside the paper fields of the citing or cited paper.", []string{"citingPaper", "citedPaper"}},
+}
+
+// property is a property of a schema, as far as s2gen needs it.
+type property struct {
+	Type string `json:"type"`
+	Ref  string `json:"$ref"`
+}
+
+// spec holds the schemas of a Swagger 2.0 or OpenAPI 3 document.
+type spec struct {
+	Definitions map[string]schema `json:"definitions"`
+	Components  struct {
+		Schemas map[string]schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type schema struct {
+	Properties map[string]property `json:"properties"`
+}
+
+func (s *spec) schema(name string) (schema, bool) {
+	if sc, ok := s.Definitions[name]; ok {
+		return sc, true
+	}
+	sc, ok := s.Components.Schemas[name]
+	return sc, ok
+}
+
+// properties returns the union of the properties of the schemas of m.
+func (s *spec) properties(m model) (map[string]property, error) {
+	props := make(map[string]property)
+	for _, name := range m.schemas {
+		sc, ok := s.schema(name)
+		if !ok {
+			return nil, fmt.Errorf("spec has no schema %s", name)
+		}
+		for k, p := range sc.Properties {
+			props[k] = p
+		}
+	}
+	return props, nil
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("s2gen: ")
+	specPath := flag.String("spec", "openapi/graph.json", "path of the spec")
+	out := flag.String("out", "fields_gen.go", "path of the generated file")
+	fetch := flag.Bool("fetch", false, "download the spec from "+specURL+" to -spec and exit")
+	check := flag.Bool("check", false, "report drift between the models and the spec instead of generating")
+	flag.Parse()
+
+	if *fetch {
+		if err := download(*specPath); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	data, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var s spec
+	if err := json.Unmarshal(data, &s); err != nil {
+		log.Fatalf("%s: %v", *specPath, err)
+	}
+	if *check {
+		if drift := checkModels(os.Stdout, &s); drift {
+			os.Exit(1)
+		}
+		return
+	}
+	src, err := generate(&s, *specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func download(path string) error {
+	resp, err := http.Get(specURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", specURL, resp.Status)
+	}
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, resp.Body); err != nil {
+		return err
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buf.Bytes(), "", "  "); err != nil {
+		return fmt.Errorf("%s: %v", specURL, err)
+	}
+	indented.WriteByte('\n')
+	return os.WriteFile(path, indented.Bytes(), 0o644)
+}
+
+// generate returns the source of the field constants.
+func generate(s *spec, specPath string) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by s2gen from %s; DO NOT EDIT.\n\npackage semscholar\n", specPath)
+	for _, m := range models {
+		props, err := s.properties(m)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "\n// %s\nconst (\n", m.doc)
+		for _, name := range sortedKeys(props) {
+			if !slices.Contains(m.nested, name) {
+				fmt.Fprintf(&b, "\t%s%s = %q\n", m.prefix, goName(name), name)
+			}
+		}
+		b.WriteString(")\n")
+	}
+	return format.Source(b.Bytes())
+}
+
+// checkModels writes the drift between each model and its schemas to w and
+// reports whether any model conflicts with the spec.
+func checkModels(w io.Writer, s *spec) bool {
+	drift := false
+	for _, m := range models {
+		props, err := s.properties(m)
+		if err != nil {
+			fmt.Fprintf(w, "%s: %v\n", m.typ.Name(), err)
+			drift = true
+			continue
+		}
+		declared := make(map[string]bool)
+		for i := 0; i < m.typ.NumField(); i++ {
+			f := m.typ.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "" || name == "-" {
+				continue
+			}
+			declared[name] = true
+			p, ok := props[name]
+			if !ok {
+				fmt.Fprintf(w, "%s.%s: field %q is not in the spec\n", m.typ.Name(), f.Name, name)
+				drift = true
+				continue
+			}
+			if want := p.kind(); !kindMatches(f.Type, want) {
+				fmt.Fprintf(w, "%s.%s: field %q is %s, the spec says %s\n", m.typ.Name(), f.Name, name, f.Type, want)
+				drift = true
+			}
+		}
+		var unmodeled []string
+		for _, name := range sortedKeys(props) {
+			if !declared[name] {
+				unmodeled = append(unmodeled, name)
+			}
+		}
+		if len(unmodeled) > 0 {
+			fmt.Fprintf(w, "%s: not modeled: %s\n", m.typ.Name(), strings.Join(unmodeled, ", "))
+		}
+	}
+	return drift
+}
+
+// kind returns the JSON type of p: string, integer, number, boolean, array or
+// object.
+func (p property) kind() string {
+	if p.Type == "" && p.Ref != "" {
+		return "object"
+	}
+	return p.Type
+}
+
+// kindMatches reports whether Go type t can hold a JSON value of kind. Types
+// implementing json.Unmarshaler, such as wrappers of nullable values, are
+// given the benefit of the doubt.
+func kindMatches(t reflect.Type, kind string) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(reflect.TypeFor[json.Unmarshaler]()) {
+		return true
+	}
+	switch k := t.Kind(); kind {
+	case "string":
+		return k == reflect.String
+	case "integer":
+		return k >= reflect.Int && k <= reflect.Int64
+	case "number":
+		return k >= reflect.Int && k <= reflect.Int64 || k == reflect.Float32 || k == reflect.Float64
+	case "boolean":
+		return k == reflect.Bool
+	case "array":
+		return k == reflect.Slice || k == reflect.Array
+	case "object":
+		return k == reflect.Struct || k == reflect.Map || k == reflect.Interface
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// initialisms are spelled in capitals in Go names, as the models do.
+var initialisms = map[string]string{"Id": "ID", "Ids": "IDs", "Url": "URL"}
+
+// goName converts a camelCase property name to the Go name used for it in
+// the models: "paperId" becomes "PaperID" and "externalIds" "ExternalIDs".
+func goName(prop string) string {
+	var words []string
+	start := 0
+	for i, r := range prop {
+		if i > 0 && unicode.IsUpper(r) {
+			words = append(words, prop[start:i])
+			start = i
+		}
+	}
+	words = append(words, prop[start:])
+	var b strings.Builder
+	for _, w := range words {
+		w = strings.ToUpper(w[:1]) + w[1:]
+		if s, ok := initialisms[w]; ok {
+			w = s
+		}
+		b.WriteString(w)
+	}
+	return b.String()
+}
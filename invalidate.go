@@ -0,0 +1,109 @@
+package semscholar
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+// PrefixDeleter is implemented by caches that can remove every key sharing a
+// prefix. Client.Invalidate and Client.InvalidateQuery require it.
+type PrefixDeleter interface {
+	// DeletePrefix removes every key beginning with prefix and reports how many were removed.
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+}
+
+// ErrInvalidateUnsupported is returned when the client's cache does not implement PrefixDeleter.
+var ErrInvalidateUnsupported = errors.New("semscholar: cache does not support prefix deletion")
+
+// EvictReason says why a cache entry was removed.
+type EvictReason int
+
+const (
+	// EvictCapacity means the entry was dropped to make room.
+	EvictCapacity EvictReason = iota
+	// EvictExpired means the entry was found past its TTL.
+	EvictExpired
+	// EvictDeleted means the entry was removed by Delete or DeletePrefix.
+	EvictDeleted
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictCapacity:
+		return "capacity"
+	case EvictExpired:
+		return "expired"
+	case EvictDeleted:
+		return "deleted"
+	}
+	return "unknown"
+}
+
+// Invalidate removes every cached response for the paper with the given ID,
+// whatever fields were requested: the paper itself and its citation and
+// reference pages. Use it when a paper is known to have changed, for example
+// after applying a dataset diff. The ID must be in the form used for the
+// lookups; responses cached under another alias of the paper, and batch or
+// search responses that include it, are left alone. It reports the number of
+// entries removed.
+func (c *Client) Invalidate(ctx context.Context, paperID string) (int, error) {
+	pd, base, err := c.invalidator()
+	if err != nil {
+		return 0, err
+	}
+	key := "semscholar:GET:" + base + "/paper/" + paperID
+	total := 0
+	if _, ok, _ := c.Cache.Get(ctx, key); ok {
+		total++
+	}
+	if err := c.Cache.Delete(ctx, key); err != nil {
+		return 0, err
+	}
+	for _, prefix := range []string{key + "?", key + "/"} {
+		n, err := pd.DeletePrefix(ctx, prefix)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// InvalidateQuery removes every cached response whose request, written as the
+// path below BaseURL followed by "?" and the query sorted by parameter name,
+// begins with prefix. For example, "/author/" drops all author lookups and
+// "/paper/search" all relevance, bulk and match searches. It reports the
+// number of entries removed.
+func (c *Client) InvalidateQuery(ctx context.Context, prefix string) (int, error) {
+	pd, base, err := c.invalidator()
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, method := range []string{"GET", "POST"} {
+		n, err := pd.DeletePrefix(ctx, "semscholar:"+method+":"+base+prefix)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// invalidator returns the cache as a PrefixDeleter and the host and path of
+// BaseURL as they appear in cache keys.
+func (c *Client) invalidator() (PrefixDeleter, string, error) {
+	if c.Cache == nil {
+		return nil, "", ErrNoCache
+	}
+	pd, ok := c.Cache.(PrefixDeleter)
+	if !ok {
+		return nil, "", ErrInvalidateUnsupported
+	}
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return nil, "", err
+	}
+	return pd, u.Host + u.Path, nil
+}
@@ -0,0 +1,96 @@
+package semscholar_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestInvalidate checks that Invalidate drops every cached response for a
+// paper and no other, and InvalidateQuery every response under a path, with
+// both caches that support prefix deletion.
+func TestInvalidate(t *testing.T) {
+	ctx := context.Background()
+	disk, err := semscholar.NewDiskCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caches := map[string]semscholar.Cache{
+		"LRUCache":  semscholar.NewLRUCache(100),
+		"DiskCache": disk,
+	}
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			srv := semscholartest.NewServer(nil)
+			defer srv.Close()
+			c := srv.Client()
+			c.Cache = cache
+			var deleted int
+			onEvict := func(key string, reason semscholar.EvictReason) {
+				if reason == semscholar.EvictDeleted {
+					deleted++
+				}
+			}
+			switch cache := cache.(type) {
+			case *semscholar.LRUCache:
+				cache.OnEvict = onEvict
+			case *semscholar.DiskCache:
+				cache.OnEvict = onEvict
+			}
+			fetch := func() {
+				c.GetPaper(semscholartest.BERTPaperID, "title")
+				c.GetPaper(semscholartest.BERTPaperID, "title,year")
+				c.GetPaperCitations(semscholartest.BERTPaperID, 0, 10, "title")
+				c.GetPaper(semscholartest.ResNetPaperID, "title")
+				c.SearchAuthors("he", 0, 10, "name")
+			}
+			fetch()
+			sent := len(srv.Requests())
+
+			n, err := c.Invalidate(ctx, semscholartest.BERTPaperID)
+			if err != nil || n != 3 {
+				t.Errorf("Invalidate = %d, %v; want 3", n, err)
+			}
+			if deleted != 3 {
+				t.Errorf("OnEvict reported %d deletions, want 3", deleted)
+			}
+			fetch()
+			if n := len(srv.Requests()) - sent; n != 3 {
+				t.Errorf("%d requests after invalidating the paper, want 3", n)
+			}
+
+			n, err = c.InvalidateQuery(ctx, "/author/")
+			if err != nil || n != 1 {
+				t.Errorf("InvalidateQuery(/author/) = %d, %v; want 1", n, err)
+			}
+			n, err = c.InvalidateQuery(ctx, "/paper/")
+			if err != nil || n != 4 {
+				t.Errorf("InvalidateQuery(/paper/) = %d, %v; want 4", n, err)
+			}
+		})
+	}
+}
+
+// plainCache is a Cache without prefix deletion.
+type plainCache struct{}
+
+func (plainCache) Get(context.Context, string) ([]byte, bool, error)        { return nil, false, nil }
+func (plainCache) Set(context.Context, string, []byte, time.Duration) error { return nil }
+func (plainCache) Delete(context.Context, string) error                     { return nil }
+
+// TestInvalidateErrors checks the errors Invalidate returns without a cache
+// and with one that cannot delete by prefix.
+func TestInvalidateErrors(t *testing.T) {
+	c := semscholar.NewClient("http://example.invalid", nil)
+	if _, err := c.Invalidate(context.Background(), "a"); !errors.Is(err, semscholar.ErrNoCache) {
+		t.Errorf("Invalidate without a cache = %v, want ErrNoCache", err)
+	}
+	c.Cache = plainCache{}
+	if _, err := c.InvalidateQuery(context.Background(), "/paper/"); !errors.Is(err, semscholar.ErrInvalidateUnsupported) {
+		t.Errorf("InvalidateQuery with a plain cache = %v, want ErrInvalidateUnsupported", err)
+	}
+}
@@ -0,0 +1,113 @@
+package semscholar
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+)
+
+// JSONLWriter writes values as JSON Lines, one value per line, optionally
+// gzip-compressed. Exports of thousands of papers with abstracts shrink to a
+// fraction of their size at little CPU cost.
+type JSONLWriter struct {
+	enc  *json.Encoder
+	zw   *gzip.Writer
+	file *os.File
+}
+
+// NewJSONLWriter returns a writer of JSON Lines to w, gzip-compressed when
+// compress is true. Close must be called to flush compressed output; it does
+// not close w.
+func NewJSONLWriter(w io.Writer, compress bool) *JSONLWriter {
+	jw := &JSONLWriter{}
+	if compress {
+		jw.zw = gzip.NewWriter(w)
+		w = jw.zw
+	}
+	jw.enc = json.NewEncoder(w)
+	return jw
+}
+
+// CreateJSONL creates the file at path and returns a writer to it. The output
+// is gzip-compressed when path ends in ".gz".
+func CreateJSONL(path string) (*JSONLWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	jw := NewJSONLWriter(f, strings.HasSuffix(path, ".gz"))
+	jw.file = f
+	return jw, nil
+}
+
+// Write appends v as one line.
+func (w *JSONLWriter) Write(v any) error {
+	return w.enc.Encode(v)
+}
+
+// Close flushes compressed output and closes the file opened by CreateJSONL.
+func (w *JSONLWriter) Close() error {
+	var err error
+	if w.zw != nil {
+		err = w.zw.Close()
+	}
+	if w.file != nil {
+		if cerr := w.file.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// JSONLReader reads JSON Lines, decompressing gzip input transparently.
+type JSONLReader struct {
+	dec    *json.Decoder
+	closer io.Closer
+}
+
+// NewJSONLReader returns a reader of the JSON Lines in r. Gzip-compressed input
+// is recognized by its magic bytes, whatever the file is called.
+func NewJSONLReader(r io.Reader) (*JSONLReader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return &JSONLReader{dec: json.NewDecoder(zr)}, nil
+	}
+	return &JSONLReader{dec: json.NewDecoder(br)}, nil
+}
+
+// OpenJSONL opens the JSON Lines file at path, compressed or not.
+func OpenJSONL(path string) (*JSONLReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	jr, err := NewJSONLReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	jr.closer = f
+	return jr, nil
+}
+
+// Read decodes the next line into v. It returns io.EOF after the last line.
+func (r *JSONLReader) Read(v any) error {
+	return r.dec.Decode(v)
+}
+
+// Close closes the file opened by OpenJSONL.
+func (r *JSONLReader) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package semscholar_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// TestJSONLRoundTrip checks that papers written as JSON Lines, plain or
+// compressed by the file's name, read back unchanged, and that compressed
+// input is recognized by content rather than name.
+func TestJSONLRoundTrip(t *testing.T) {
+	papers := []semscholar.Paper{
+		{PaperID: "a", Title: "First", Year: 2020, CitationCount: semscholar.NewNullInt(3)},
+		{PaperID: "b", Title: "Second\nwith a newline", Authors: []semscholar.Author{{AuthorID: "1", Name: "Ada"}}},
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"papers.jsonl", "papers.jsonl.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			w, err := semscholar.CreateJSONL(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range papers {
+				if err := w.Write(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			compressed := bytes.HasPrefix(data, []byte{0x1f, 0x8b})
+			if want := filepath.Ext(name) == ".gz"; compressed != want {
+				t.Errorf("compressed = %v, want %v", compressed, want)
+			}
+
+			// Read from a reader without the name to check the content is sniffed.
+			r, err := semscholar.NewJSONLReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []semscholar.Paper
+			for {
+				var p semscholar.Paper
+				err := r.Read(&p)
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, p)
+			}
+			if !reflect.DeepEqual(got, papers) {
+				t.Errorf("read back %+v, want %+v", got, papers)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package library
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/metrics"
+	bolt "go.etcd.io/bbolt"
+)
+
+// RecordCitations adds a sample of the citation count of each paper, as it
+// was at time at, to the citation history of the paper, so that
+// metrics.NewTrend can tell how fast it is being cited. Papers not in the
+// library are skipped, as are samples at the time of the last one.
+func (l *Library) RecordCitations(papers []semscholar.Paper, at time.Time) error {
+	at = at.UTC()
+	return l.db.Update(func(tx *bolt.Tx) error {
+		items := tx.Bucket(itemsBucket)
+		b := tx.Bucket(citationsBucket)
+		for _, p := range papers {
+			if p.PaperID == "" || items.Get([]byte(p.PaperID)) == nil {
+				continue
+			}
+			history, err := getHistory(b, p.PaperID)
+			if err != nil {
+				return err
+			}
+			if n := len(history); n > 0 && history[n-1].Time.Equal(at) {
+				continue
+			}
+			history = append(history, metrics.Sample{Time: at, Citations: p.CitationCount.Int})
+			v, err := json.Marshal(history)
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(p.PaperID), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CitationHistory returns the citation samples of the paper id identifies,
+// as Get finds it, oldest first.
+func (l *Library) CitationHistory(id string) ([]metrics.Sample, error) {
+	var history []metrics.Sample
+	err := l.db.View(func(tx *bolt.Tx) error {
+		it, err := getItem(tx, resolve(tx, id))
+		if err != nil {
+			return err
+		}
+		history, err = getHistory(tx.Bucket(citationsBucket), it.Paper.PaperID)
+		return err
+	})
+	return history, err
+}
+
+func getHistory(b *bolt.Bucket, paperID string) ([]metrics.Sample, error) {
+	v := b.Get([]byte(paperID))
+	if v == nil {
+		return nil, nil
+	}
+	var history []metrics.Sample
+	if err := json.Unmarshal(v, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// Refresh fetches every paper in the library again with fields, which
+// always include citationCount, replacing the details kept of them, and
+// records a sample of their citation counts. Run periodically, it builds
+// the citation histories of the library. It returns how many papers were
+// refreshed; if some batches failed, the error is a *semscholar.PartialError
+// and the other papers are refreshed.
+func (l *Library) Refresh(ctx context.Context, c *semscholar.Client, fields string) (int, error) {
+	items, err := l.Items(Query{})
+	if err != nil {
+		return 0, err
+	}
+	if len(items) == 0 {
+		return 0, nil
+	}
+	ids := make([]string, len(items))
+	for i, it := range items {
+		ids[i] = it.Paper.PaperID
+	}
+	if fields == "" {
+		fields = semscholar.PaperFieldCitationCount
+	} else if !slices.Contains(strings.Split(fields, ","), semscholar.PaperFieldCitationCount) {
+		fields += "," + semscholar.PaperFieldCitationCount
+	}
+	papers, err := c.GetPapersBatchAll(ctx, ids, fields)
+	var partial *semscholar.PartialError
+	if err != nil && !errors.As(err, &partial) {
+		return 0, err
+	}
+	now := l.now()
+	var found []semscholar.Paper
+	err = l.db.Update(func(tx *bolt.Tx) error {
+		for i, p := range papers {
+			if p.PaperID == "" {
+				continue
+			}
+			it, err := getItem(tx, ids[i])
+			if err != nil {
+				return err
+			}
+			// Papers merged into another by Semantic Scholar come back under
+			// its ID; the item keeps its own.
+			p.PaperID = ids[i]
+			it.Paper = p
+			it.Updated = now
+			if err := putItem(tx, it); err != nil {
+				return err
+			}
+			found = append(found, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := l.RecordCitations(found, now); err != nil {
+		return 0, err
+	}
+	if partial != nil {
+		return len(found), partial
+	}
+	return len(found), nil
+}
@@ -0,0 +1,100 @@
+package library_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/library"
+	"github.com/jmwalsh91/semscholar-go/metrics"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// open opens a library in a temporary directory, closed when the test ends.
+func open(t *testing.T) *library.Library {
+	t.Helper()
+	l, err := library.Open(filepath.Join(t.TempDir(), "library.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+// TestRecordCitations checks that samples are kept per paper in order, that
+// a second sample at the same time and papers not in the library are
+// skipped, and that removing a paper drops its history.
+func TestRecordCitations(t *testing.T) {
+	l := open(t)
+	p := semscholar.Paper{PaperID: "a"}
+	p.ExternalIDs.DOI = "10.1000/a"
+	if _, err := l.Add([]semscholar.Paper{p}); err != nil {
+		t.Fatal(err)
+	}
+	t1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.AddDate(1, 0, 0)
+	for _, s := range []struct {
+		at    time.Time
+		count int
+	}{{t1, 5}, {t1, 6}, {t2, 9}} {
+		p.CitationCount = semscholar.NewNullInt(s.count)
+		if err := l.RecordCitations([]semscholar.Paper{p, {PaperID: "other"}}, s.at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history, err := l.CitationHistory("DOI:10.1000/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []metrics.Sample{{Time: t1, Citations: 5}, {Time: t2, Citations: 9}}
+	if len(history) != len(want) || !history[0].Time.Equal(want[0].Time) || history[0].Citations != 5 || history[1].Citations != 9 {
+		t.Errorf("history %v, want %v", history, want)
+	}
+	if _, err := l.CitationHistory("other"); err != library.ErrNotFound {
+		t.Errorf("history of a paper not in the library: %v, want ErrNotFound", err)
+	}
+
+	if err := l.Remove("a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Add([]semscholar.Paper{p}); err != nil {
+		t.Fatal(err)
+	}
+	if history, err := l.CitationHistory("a"); err != nil || len(history) != 0 {
+		t.Errorf("history after removing and adding again: %v, %v, want none", history, err)
+	}
+}
+
+// TestRefresh checks that Refresh replaces the details of every paper,
+// keeping its ID when Semantic Scholar answers for a merged paper, and
+// samples its citation count.
+func TestRefresh(t *testing.T) {
+	d := semscholartest.NewDataset()
+	d.AddPaper(semscholar.Paper{PaperID: "a", Title: "Refreshed", CitationCount: semscholar.NewNullInt(12)})
+	srv := semscholartest.NewServer(d)
+	defer srv.Close()
+
+	l := open(t)
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	l.Now = func() time.Time { return now }
+	if _, err := l.Add([]semscholar.Paper{{PaperID: "a", Title: "Stale"}, {PaperID: "gone"}}); err != nil {
+		t.Fatal(err)
+	}
+	n, err := l.Refresh(context.Background(), srv.Client(), "title")
+	if err != nil || n != 1 {
+		t.Fatalf("Refresh = %d, %v, want 1 paper", n, err)
+	}
+	it, err := l.Get("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Paper.Title != "Refreshed" || it.Paper.CitationCount.Int != 12 || !it.Updated.Equal(now) {
+		t.Errorf("refreshed item %+v", it)
+	}
+	history, err := l.CitationHistory("a")
+	if err != nil || len(history) != 1 || history[0].Citations != 12 || !history[0].Time.Equal(now) {
+		t.Errorf("history %v, %v, want one sample of 12", history, err)
+	}
+}
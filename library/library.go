@@ -0,0 +1,476 @@
+// Package library is a local store of papers with tags, notes and reading
+// status, kept in a single bbolt file, for applications that keep their own
+// collection of Semantic Scholar papers.
+package library
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/export"
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrNotFound is returned for papers that are not in the library.
+var ErrNotFound = errors.New("library: paper not in library")
+
+// Status is how far a paper has been read.
+type Status string
+
+// Reading statuses.
+const (
+	Unread  Status = "unread"
+	Reading Status = "reading"
+	Read    Status = "read"
+)
+
+// Item is a paper in the library with what the user keeps with it.
+type Item struct {
+	Paper   semscholar.Paper `json:"paper"`
+	Tags    []string         `json:"tags,omitempty"`
+	Notes   string           `json:"notes,omitempty"`
+	Status  Status           `json:"status"`
+	Added   time.Time        `json:"added"`
+	Updated time.Time        `json:"updated"`
+}
+
+// HasTag reports whether the item is tagged tag.
+func (it *Item) HasTag(tag string) bool {
+	return slices.Contains(it.Tags, tag)
+}
+
+// Buckets of the library file. items maps paper IDs to items as JSON; ids
+// maps the external IDs of papers, as in "DOI:10.1109/cvpr.2016.90", to
+// their paper IDs; citations maps paper IDs to their citation histories.
+var (
+	itemsBucket     = []byte("items")
+	idsBucket       = []byte("ids")
+	citationsBucket = []byte("citations")
+)
+
+// Library is a collection of papers stored in a bbolt file. It is safe for
+// concurrent use, but only one process may have the file open at a time.
+type Library struct {
+	db *bolt.DB
+	// Now, if set, replaces time.Now for the times items are added and
+	// updated.
+	Now func() time.Time
+}
+
+// Open opens the library at path, creating it if it does not exist. It
+// waits at most a second for another process to close the file.
+func Open(path string) (*Library, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("library: opening %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{itemsBucket, idsBucket, citationsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Library{db: db}, nil
+}
+
+// Close closes the library file.
+func (l *Library) Close() error {
+	return l.db.Close()
+}
+
+func (l *Library) now() time.Time {
+	if l.Now != nil {
+		return l.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+// Add stores papers, tagged with tags, as unread. Papers already in the
+// library keep their notes and status; their details are replaced by those
+// given and the tags are added to theirs. It returns how many papers were
+// new. Papers without a paper ID are skipped.
+func (l *Library) Add(papers []semscholar.Paper, tags ...string) (added int, err error) {
+	now := l.now()
+	err = l.db.Update(func(tx *bolt.Tx) error {
+		for _, p := range papers {
+			if p.PaperID == "" {
+				continue
+			}
+			it, err := getItem(tx, p.PaperID)
+			switch {
+			case errors.Is(err, ErrNotFound):
+				it = &Item{Status: Unread, Added: now}
+				added++
+			case err != nil:
+				return err
+			}
+			it.Paper = p
+			it.Tags = addTags(it.Tags, tags)
+			it.Updated = now
+			if err := putItem(tx, it); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// Import adds the papers of seq, such as the results of
+// Client.BulkSearchPapersSeq, as Add does, committing them in batches of
+// MaxBatchSize. It returns how many papers were new; on an error from seq,
+// those imported before it stay imported.
+func (l *Library) Import(seq iter.Seq2[semscholar.Paper, error], tags ...string) (added int, err error) {
+	var batch []semscholar.Paper
+	flush := func() error {
+		n, err := l.Add(batch, tags...)
+		added += n
+		batch = batch[:0]
+		return err
+	}
+	for p, err := range seq {
+		if err != nil {
+			if ferr := flush(); ferr != nil {
+				return added, ferr
+			}
+			return added, err
+		}
+		if batch = append(batch, p); len(batch) == semscholar.MaxBatchSize {
+			if err := flush(); err != nil {
+				return added, err
+			}
+		}
+	}
+	return added, flush()
+}
+
+// Get returns the item of the paper id identifies: a paper ID, or an
+// external ID as in "DOI:10.1109/CVPR.2016.90", "ARXIV:1512.03385" or
+// "CorpusId:206594692".
+func (l *Library) Get(id string) (*Item, error) {
+	var it *Item
+	err := l.db.View(func(tx *bolt.Tx) error {
+		var err error
+		it, err = getItem(tx, resolve(tx, id))
+		return err
+	})
+	return it, err
+}
+
+// Update calls fn with the item of the paper id identifies, as Get finds
+// it, and stores the item fn leaves, unless fn returns an error.
+func (l *Library) Update(id string, fn func(*Item) error) error {
+	return l.db.Update(func(tx *bolt.Tx) error {
+		it, err := getItem(tx, resolve(tx, id))
+		if err != nil {
+			return err
+		}
+		paperID := it.Paper.PaperID
+		if err := fn(it); err != nil {
+			return err
+		}
+		if it.Paper.PaperID != paperID {
+			return fmt.Errorf("library: the paper ID of %s cannot change", paperID)
+		}
+		it.Updated = l.now()
+		return putItem(tx, it)
+	})
+}
+
+// Tag adds tags to the paper id identifies.
+func (l *Library) Tag(id string, tags ...string) error {
+	return l.Update(id, func(it *Item) error {
+		it.Tags = addTags(it.Tags, tags)
+		return nil
+	})
+}
+
+// Untag removes tags from the paper id identifies.
+func (l *Library) Untag(id string, tags ...string) error {
+	return l.Update(id, func(it *Item) error {
+		it.Tags = slices.DeleteFunc(it.Tags, func(t string) bool { return slices.Contains(tags, t) })
+		return nil
+	})
+}
+
+// SetNotes replaces the notes of the paper id identifies.
+func (l *Library) SetNotes(id, notes string) error {
+	return l.Update(id, func(it *Item) error {
+		it.Notes = notes
+		return nil
+	})
+}
+
+// SetStatus sets the reading status of the paper id identifies.
+func (l *Library) SetStatus(id string, s Status) error {
+	switch s {
+	case Unread, Reading, Read:
+	default:
+		return fmt.Errorf("library: unknown status %q", s)
+	}
+	return l.Update(id, func(it *Item) error {
+		it.Status = s
+		return nil
+	})
+}
+
+// Remove deletes the paper id identifies, and its citation history, from
+// the library.
+func (l *Library) Remove(id string) error {
+	return l.db.Update(func(tx *bolt.Tx) error {
+		it, err := getItem(tx, resolve(tx, id))
+		if err != nil {
+			return err
+		}
+		ids := tx.Bucket(idsBucket)
+		for _, key := range externalKeys(it.Paper) {
+			if err := ids.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		if err := tx.Bucket(citationsBucket).Delete([]byte(it.Paper.PaperID)); err != nil {
+			return err
+		}
+		return tx.Bucket(itemsBucket).Delete([]byte(it.Paper.PaperID))
+	})
+}
+
+// Query selects items. Zero fields select everything.
+type Query struct {
+	// Tags selects items having all of them.
+	Tags   []string
+	Status Status
+	// Text selects items whose title, abstract, notes or authors contain
+	// it, ignoring case.
+	Text string
+}
+
+func (q Query) matches(it *Item) bool {
+	for _, t := range q.Tags {
+		if !it.HasTag(t) {
+			return false
+		}
+	}
+	if q.Status != "" && it.Status != q.Status {
+		return false
+	}
+	if q.Text != "" {
+		text := []string{it.Paper.Title, it.Paper.Abstract, it.Notes}
+		for _, a := range it.Paper.Authors {
+			text = append(text, a.Name)
+		}
+		if !strings.Contains(strings.ToLower(strings.Join(text, "\n")), strings.ToLower(q.Text)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Items returns the items q selects, in the order they were added.
+func (l *Library) Items(q Query) ([]Item, error) {
+	var items []Item
+	err := l.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(itemsBucket).ForEach(func(_, v []byte) error {
+			var it Item
+			if err := json.Unmarshal(v, &it); err != nil {
+				return err
+			}
+			if q.matches(&it) {
+				items = append(items, it)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(items, func(a, b Item) int { return a.Added.Compare(b.Added) })
+	return items, nil
+}
+
+// Tags returns every tag in the library with the number of items having it.
+func (l *Library) Tags() (map[string]int, error) {
+	items, err := l.Items(Query{})
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]int)
+	for _, it := range items {
+		for _, t := range it.Tags {
+			tags[t]++
+		}
+	}
+	return tags, nil
+}
+
+// WriteBibTeX writes the papers of the items q selects to w as BibTeX, as
+// export.WriteBibTeX does.
+func (l *Library) WriteBibTeX(w io.Writer, q Query) error {
+	items, err := l.Items(q)
+	if err != nil {
+		return err
+	}
+	return export.WriteBibTeX(w, Papers(items))
+}
+
+// WriteJSONL writes the items q selects to w as JSON Lines, one item per
+// line, as ReadJSONL reads them.
+func (l *Library) WriteJSONL(w io.Writer, q Query) error {
+	items, err := l.Items(q)
+	if err != nil {
+		return err
+	}
+	jw := semscholar.NewJSONLWriter(w, false)
+	for _, it := range items {
+		if err := jw.Write(it); err != nil {
+			return err
+		}
+	}
+	return jw.Close()
+}
+
+// ReadJSONL stores the items in r, as WriteJSONL writes them, replacing
+// those of the same papers. It returns how many items it read.
+func (l *Library) ReadJSONL(r io.Reader) (int, error) {
+	jr, err := semscholar.NewJSONLReader(r)
+	if err != nil {
+		return 0, err
+	}
+	defer jr.Close()
+	n := 0
+	err = l.db.Update(func(tx *bolt.Tx) error {
+		for {
+			var it Item
+			err := jr.Read(&it)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("library: item %d: %w", n+1, err)
+			}
+			if it.Paper.PaperID == "" {
+				return fmt.Errorf("library: item %d has no paper ID", n+1)
+			}
+			if it.Status == "" {
+				it.Status = Unread
+			}
+			if err := putItem(tx, &it); err != nil {
+				return err
+			}
+			n++
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Papers returns the papers of items.
+func Papers(items []Item) []semscholar.Paper {
+	papers := make([]semscholar.Paper, len(items))
+	for i, it := range items {
+		papers[i] = it.Paper
+	}
+	return papers
+}
+
+func getItem(tx *bolt.Tx, paperID string) (*Item, error) {
+	v := tx.Bucket(itemsBucket).Get([]byte(paperID))
+	if v == nil {
+		return nil, ErrNotFound
+	}
+	var it Item
+	if err := json.Unmarshal(v, &it); err != nil {
+		return nil, fmt.Errorf("library: item %s: %w", paperID, err)
+	}
+	return &it, nil
+}
+
+// putItem stores it and indexes its external IDs.
+func putItem(tx *bolt.Tx, it *Item) error {
+	v, err := json.Marshal(it)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(itemsBucket).Put([]byte(it.Paper.PaperID), v); err != nil {
+		return err
+	}
+	ids := tx.Bucket(idsBucket)
+	for _, key := range externalKeys(it.Paper) {
+		if err := ids.Put([]byte(key), []byte(it.Paper.PaperID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve returns the paper ID that id, a paper ID or external ID, stands
+// for, or id itself if it is not an indexed external ID.
+func resolve(tx *bolt.Tx, id string) string {
+	if v := tx.Bucket(idsBucket).Get([]byte(externalKey(id))); v != nil {
+		return string(v)
+	}
+	return id
+}
+
+// externalKeys returns the keys p is indexed by in the ids bucket.
+func externalKeys(p semscholar.Paper) []string {
+	var keys []string
+	if p.ExternalIDs.DOI != "" {
+		keys = append(keys, externalKey("DOI:"+p.ExternalIDs.DOI))
+	}
+	if p.ExternalIDs.ArXiv != "" {
+		keys = append(keys, externalKey("ARXIV:"+p.ExternalIDs.ArXiv))
+	}
+	if id := cmp.Or(p.CorpusID, p.ExternalIDs.CorpusID); id != 0 {
+		keys = append(keys, externalKey("CorpusId:"+strconv.Itoa(id)))
+	}
+	return keys
+}
+
+// externalKey folds the prefix of an external ID, and DOIs, which are case
+// insensitive, to lower case.
+func externalKey(id string) string {
+	prefix, value, ok := strings.Cut(id, ":")
+	if !ok {
+		return id
+	}
+	prefix = strings.ToLower(prefix)
+	if prefix == "doi" {
+		if doi, err := semscholar.NormalizeDOI(value); err == nil {
+			value = doi
+		}
+		value = strings.ToLower(value)
+	}
+	return prefix + ":" + value
+}
+
+// addTags returns tags with the new ones appended, skipping empty and
+// repeated tags.
+func addTags(tags, more []string) []string {
+	for _, t := range more {
+		if t = strings.TrimSpace(t); t != "" && !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,241 @@
+package library_test
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/library"
+)
+
+// clock returns a clock for Library.Now starting at a fixed time and
+// advancing a minute at each reading.
+func clock() func() time.Time {
+	t := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		t = t.Add(time.Minute)
+		return t
+	}
+}
+
+// resnet is a paper with a DOI, an arXiv ID and a corpus ID.
+func resnet() semscholar.Paper {
+	p := semscholar.Paper{PaperID: "2c03df8b", CorpusID: 206594692, Title: "Deep Residual Learning"}
+	p.ExternalIDs.DOI = "10.1109/CVPR.2016.90"
+	p.ExternalIDs.ArXiv = "1512.03385"
+	return p
+}
+
+// TestAdd checks that adding a paper again replaces its details and adds
+// tags but keeps its notes, status and time added, and that papers without
+// an ID are skipped.
+func TestAdd(t *testing.T) {
+	l := open(t)
+	l.Now = clock()
+	n, err := l.Add([]semscholar.Paper{resnet(), {Title: "No ID"}}, "vision", "")
+	if err != nil || n != 1 {
+		t.Fatalf("Add = %d, %v, want 1 paper", n, err)
+	}
+	if err := l.SetNotes("2c03df8b", "skip connections"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetStatus("2c03df8b", library.Reading); err != nil {
+		t.Fatal(err)
+	}
+	first, err := l.Get("2c03df8b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := resnet()
+	p.Title = "Deep Residual Learning for Image Recognition"
+	n, err = l.Add([]semscholar.Paper{p}, "vision", "classics")
+	if err != nil || n != 0 {
+		t.Fatalf("Add again = %d, %v, want no new paper", n, err)
+	}
+	it, err := l.Get("2c03df8b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Paper.Title != p.Title || it.Notes != "skip connections" || it.Status != library.Reading {
+		t.Errorf("item %+v lost its notes or status, or kept its old title", it)
+	}
+	if want := []string{"vision", "classics"}; !slices.Equal(it.Tags, want) {
+		t.Errorf("tags %v, want %v", it.Tags, want)
+	}
+	if !it.Added.Equal(first.Added) || !it.Updated.After(first.Updated) {
+		t.Errorf("added %v and updated %v, first added %v and updated %v", it.Added, it.Updated, first.Added, first.Updated)
+	}
+}
+
+// TestGet checks that papers are found by paper ID and by external IDs,
+// whose prefixes and DOIs are matched ignoring case.
+func TestGet(t *testing.T) {
+	l := open(t)
+	if _, err := l.Add([]semscholar.Paper{resnet()}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"2c03df8b", "DOI:10.1109/CVPR.2016.90", "doi:10.1109/cvpr.2016.90", "ARXIV:1512.03385", "CorpusId:206594692"} {
+		if it, err := l.Get(id); err != nil || it.Paper.PaperID != "2c03df8b" {
+			t.Errorf("Get(%q) = %v, %v", id, it, err)
+		}
+	}
+	for _, id := range []string{"other", "DOI:10.1000/other", "ARXIV:1512.0338"} {
+		if _, err := l.Get(id); !errors.Is(err, library.ErrNotFound) {
+			t.Errorf("Get(%q): %v, want ErrNotFound", id, err)
+		}
+	}
+
+	if err := l.Remove("ARXIV:1512.03385"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Get("DOI:10.1109/CVPR.2016.90"); !errors.Is(err, library.ErrNotFound) {
+		t.Errorf("Get after Remove: %v, want ErrNotFound", err)
+	}
+}
+
+// TestUpdate checks that an error from the function or a changed paper ID
+// leaves the item unchanged, and that unknown statuses are refused.
+func TestUpdate(t *testing.T) {
+	l := open(t)
+	if _, err := l.Add([]semscholar.Paper{resnet()}, "a", "b", "c"); err != nil {
+		t.Fatal(err)
+	}
+	errStop := errors.New("stop")
+	if err := l.Update("2c03df8b", func(it *library.Item) error { it.Notes = "lost"; return errStop }); err != errStop {
+		t.Errorf("Update = %v, want the function's error", err)
+	}
+	if err := l.Update("2c03df8b", func(it *library.Item) error { it.Paper.PaperID = "other"; return nil }); err == nil {
+		t.Error("Update changed the paper ID")
+	}
+	if err := l.SetStatus("2c03df8b", "skimmed"); err == nil {
+		t.Error("SetStatus accepted an unknown status")
+	}
+	if err := l.Untag("2c03df8b", "b", "missing"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Tag("missing", "x"); !errors.Is(err, library.ErrNotFound) {
+		t.Errorf("Tag of a missing paper: %v, want ErrNotFound", err)
+	}
+	it, err := l.Get("2c03df8b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Notes != "" || it.Status != library.Unread || !slices.Equal(it.Tags, []string{"a", "c"}) {
+		t.Errorf("item %+v, want no notes, unread, tagged a and c", it)
+	}
+}
+
+// TestItems checks queries by tags, status and text, in the order the
+// items were added, and the tag counts.
+func TestItems(t *testing.T) {
+	l := open(t)
+	l.Now = clock()
+	for _, p := range []semscholar.Paper{
+		{PaperID: "c", Title: "Graph networks"},
+		{PaperID: "a", Title: "Attention", Authors: []semscholar.Author{{Name: "Ashish Vaswani"}}},
+		{PaperID: "b", Title: "Residual networks"},
+	} {
+		if _, err := l.Add([]semscholar.Paper{p}, "ml"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Tag("b", "vision"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetStatus("a", library.Read); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetNotes("c", "See the Vaswani paper"); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name string
+		q    library.Query
+		want []string
+	}{
+		{"all", library.Query{}, []string{"c", "a", "b"}},
+		{"tags", library.Query{Tags: []string{"ml", "vision"}}, []string{"b"}},
+		{"status", library.Query{Status: library.Unread}, []string{"c", "b"}},
+		{"text in authors and notes", library.Query{Text: "vaswani"}, []string{"c", "a"}},
+		{"none", library.Query{Tags: []string{"missing"}}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			items, err := l.Items(tc.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, it := range items {
+				got = append(got, it.Paper.PaperID)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("items %v, want %v", got, tc.want)
+			}
+		})
+	}
+	tags, err := l.Tags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"ml": 3, "vision": 1}; !maps.Equal(tags, want) {
+		t.Errorf("Tags = %v, want %v", tags, want)
+	}
+}
+
+// TestJSONL checks that items written as JSON Lines are read back whole
+// into another library, external IDs included.
+func TestJSONL(t *testing.T) {
+	l := open(t)
+	if _, err := l.Add([]semscholar.Paper{resnet(), {PaperID: "b"}}, "x"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetNotes("b", "notes"); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := l.WriteJSONL(&buf, library.Query{}); err != nil {
+		t.Fatal(err)
+	}
+
+	other := open(t)
+	n, err := other.ReadJSONL(&buf)
+	if err != nil || n != 2 {
+		t.Fatalf("ReadJSONL = %d, %v, want 2 items", n, err)
+	}
+	it, err := other.Get("b")
+	if err != nil || it.Notes != "notes" || !it.HasTag("x") {
+		t.Errorf("read item %+v, %v", it, err)
+	}
+	if _, err := other.Get("CorpusId:206594692"); err != nil {
+		t.Errorf("read item not found by corpus ID: %v", err)
+	}
+
+	if _, err := other.ReadJSONL(bytes.NewBufferString(`{"status":"read"}` + "\n")); err == nil {
+		t.Error("ReadJSONL accepted an item without a paper ID")
+	}
+}
+
+// TestImport checks that papers imported before an error in the sequence
+// stay imported.
+func TestImport(t *testing.T) {
+	l := open(t)
+	errBroken := errors.New("broken")
+	seq := func(yield func(semscholar.Paper, error) bool) {
+		_ = yield(semscholar.Paper{PaperID: "a"}, nil) &&
+			yield(semscholar.Paper{PaperID: "b"}, nil) &&
+			yield(semscholar.Paper{}, errBroken)
+	}
+	n, err := l.Import(seq, "imported")
+	if err != errBroken || n != 2 {
+		t.Errorf("Import = %d, %v, want 2 papers and the sequence's error", n, err)
+	}
+	items, err := l.Items(library.Query{Tags: []string{"imported"}})
+	if err != nil || len(items) != 2 {
+		t.Errorf("imported items %v, %v, want 2", items, err)
+	}
+}
@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// CollaborationFields are the paper fields NewCollaboration uses.
+const CollaborationFields = "title,venue,year,publicationDate,authors"
+
+// Collaboration is the relationship between two researchers, A and B, as
+// for conflict-of-interest screening.
+type Collaboration struct {
+	A     string `json:"a"`
+	AName string `json:"aName,omitempty"`
+	B     string `json:"b"`
+	BName string `json:"bName,omitempty"`
+	// JointPapers are the papers both wrote, oldest first.
+	JointPapers []semscholar.Paper `json:"jointPapers"`
+	// FirstYear and LastYear are the years of the first and last joint
+	// paper of known year, or zero if there is none.
+	FirstYear int `json:"firstYear,omitempty"`
+	LastYear  int `json:"lastYear,omitempty"`
+	// SharedCoauthors are the other authors both have written with, those
+	// with the most papers with either first.
+	SharedCoauthors []SharedCoauthor `json:"sharedCoauthors"`
+	// ACitesB is the number of citations from papers of A that B did not
+	// write to papers of B, and BCitesA the reverse. They are only counted
+	// by FetchCollaboration with citations asked for.
+	ACitesB int `json:"aCitesB"`
+	BCitesA int `json:"bCitesA"`
+}
+
+// SharedCoauthor is a co-author of both researchers of a Collaboration.
+type SharedCoauthor struct {
+	AuthorID    string `json:"authorId"`
+	Name        string `json:"name"`
+	PapersWithA int    `json:"papersWithA"`
+	PapersWithB int    `json:"papersWithB"`
+}
+
+// NewCollaboration finds the joint papers and shared co-authors of the
+// authors a and b from their papers, which should have the fields of
+// CollaborationFields.
+func NewCollaboration(a, b string, papersA, papersB []semscholar.Paper) *Collaboration {
+	c := &Collaboration{A: a, B: b}
+	c.AName = authorName(a, papersA)
+	c.BName = authorName(b, papersB)
+	for _, p := range papersA {
+		if hasAuthor(p, b) {
+			c.JointPapers = append(c.JointPapers, p)
+		}
+	}
+	slices.SortStableFunc(c.JointPapers, func(x, y semscholar.Paper) int { return cmp.Compare(x.PublishedYear(), y.PublishedYear()) })
+	for _, p := range c.JointPapers {
+		if y := p.PublishedYear(); y != 0 {
+			if c.FirstYear == 0 {
+				c.FirstYear = y
+			}
+			c.LastYear = y
+		}
+	}
+
+	withA := coauthorCounts(a, papersA)
+	withB := coauthorCounts(b, papersB)
+	for id, na := range withA {
+		if nb, ok := withB[id]; ok && id != a && id != b {
+			c.SharedCoauthors = append(c.SharedCoauthors, SharedCoauthor{AuthorID: id, Name: na.name, PapersWithA: na.papers, PapersWithB: nb.papers})
+		}
+	}
+	slices.SortFunc(c.SharedCoauthors, func(x, y SharedCoauthor) int {
+		return cmp.Or(y.PapersWithA+y.PapersWithB-x.PapersWithA-x.PapersWithB, strings.Compare(x.Name, y.Name))
+	})
+	return c
+}
+
+// FetchCollaboration fetches the papers of the authors a and b and finds
+// their collaboration. With citations it also counts the citations between
+// them, which costs a listing of the references of every paper of either.
+func FetchCollaboration(ctx context.Context, c *semscholar.Client, a, b string, citations bool) (*Collaboration, error) {
+	papersA, err := semscholar.Collect(c.GetAuthorPapersSeq(ctx, a, CollaborationFields))
+	if err != nil {
+		return nil, err
+	}
+	papersB, err := semscholar.Collect(c.GetAuthorPapersSeq(ctx, b, CollaborationFields))
+	if err != nil {
+		return nil, err
+	}
+	r := NewCollaboration(a, b, papersA, papersB)
+	if !citations {
+		return r, nil
+	}
+	if r.ACitesB, err = countCitations(ctx, c, papersA, papersB, b); err != nil {
+		return nil, err
+	}
+	if r.BCitesA, err = countCitations(ctx, c, papersB, papersA, a); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// countCitations counts the references from the papers of citing that the
+// author other did not write to the papers of cited.
+func countCitations(ctx context.Context, c *semscholar.Client, citing, cited []semscholar.Paper, other string) (int, error) {
+	ids := make(map[string]bool, len(cited))
+	for _, p := range cited {
+		ids[p.PaperID] = true
+	}
+	n := 0
+	for _, p := range citing {
+		if hasAuthor(p, other) {
+			continue
+		}
+		for ref, err := range c.GetPaperReferencesSeq(ctx, p.PaperID, "") {
+			if err != nil {
+				return 0, err
+			}
+			if ids[ref.CitedPaper.PaperID] {
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+
+func hasAuthor(p semscholar.Paper, id string) bool {
+	return slices.ContainsFunc(p.Authors, func(a semscholar.Author) bool { return a.AuthorID == id })
+}
+
+// authorName returns the name the author id has on the first of papers
+// that lists it.
+func authorName(id string, papers []semscholar.Paper) string {
+	for _, p := range papers {
+		for _, a := range p.Authors {
+			if a.AuthorID == id {
+				return a.Name
+			}
+		}
+	}
+	return ""
+}
+
+type coauthorCount struct {
+	name   string
+	papers int
+}
+
+// coauthorCounts counts the papers the author id wrote with each of their
+// co-authors, by ID.
+func coauthorCounts(id string, papers []semscholar.Paper) map[string]coauthorCount {
+	counts := make(map[string]coauthorCount)
+	for _, p := range papers {
+		for _, a := range p.Authors {
+			if a.AuthorID == "" || a.AuthorID == id {
+				continue
+			}
+			c := counts[a.AuthorID]
+			c.name = cmp.Or(c.name, a.Name)
+			c.papers++
+			counts[a.AuthorID] = c
+		}
+	}
+	return counts
+}
@@ -0,0 +1,88 @@
+package metrics_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/metrics"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// collaborators returns a dataset of papers of A and B: three written
+// together, one of unknown year, and others with co-authors C, D and F in
+// common.
+func collaborators() *semscholartest.Dataset {
+	d := semscholartest.NewDataset()
+	for _, p := range []semscholar.Paper{
+		by("j1", "A", "B", "C"),
+		by("j2", "A", "B"),
+		by("j3", "B", "A"),
+		by("a1", "A", "C", "D"),
+		by("a2", "A", "F"),
+		by("b1", "B", "C", "E"),
+		by("b2", "B", "D"),
+		by("b3", "F", "B"),
+	} {
+		p.Year = map[string]int{"j1": 2019, "j2": 2017, "a1": 2020, "b1": 2021}[p.PaperID]
+		d.AddPaper(p)
+	}
+	return d
+}
+
+// TestFetchCollaboration checks the joint papers, in year order with those
+// of unknown year first, the shared co-authors, ties broken by name, and the
+// citations between the authors, leaving out joint papers citing.
+func TestFetchCollaboration(t *testing.T) {
+	d := collaborators()
+	for _, c := range [][2]string{{"a1", "b1"}, {"j1", "b1"}, {"b1", "a1"}, {"b1", "j2"}} {
+		d.AddCitation(c[0], c[1])
+	}
+	srv := semscholartest.NewServer(d)
+	defer srv.Close()
+	ctx := context.Background()
+
+	c, err := metrics.FetchCollaboration(ctx, srv.Client(), "A", "B", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var joint []string
+	for _, p := range c.JointPapers {
+		joint = append(joint, p.PaperID)
+	}
+	if want := []string{"j3", "j2", "j1"}; !reflect.DeepEqual(joint, want) {
+		t.Errorf("joint papers %v, want %v", joint, want)
+	}
+	if c.AName != "Author A" || c.BName != "Author B" || c.FirstYear != 2017 || c.LastYear != 2019 {
+		t.Errorf("names %q and %q, years %d-%d", c.AName, c.BName, c.FirstYear, c.LastYear)
+	}
+	want := []metrics.SharedCoauthor{
+		{AuthorID: "C", Name: "Author C", PapersWithA: 2, PapersWithB: 2},
+		{AuthorID: "D", Name: "Author D", PapersWithA: 1, PapersWithB: 1},
+		{AuthorID: "F", Name: "Author F", PapersWithA: 1, PapersWithB: 1},
+	}
+	if !reflect.DeepEqual(c.SharedCoauthors, want) {
+		t.Errorf("shared co-authors %+v, want %+v", c.SharedCoauthors, want)
+	}
+	if c.ACitesB != 0 || c.BCitesA != 0 {
+		t.Errorf("citations counted without being asked for: %d, %d", c.ACitesB, c.BCitesA)
+	}
+
+	c, err = metrics.FetchCollaboration(ctx, srv.Client(), "A", "B", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ACitesB != 1 || c.BCitesA != 2 {
+		t.Errorf("A cites B %d times and B cites A %d times, want 1 and 2", c.ACitesB, c.BCitesA)
+	}
+}
+
+// TestNewCollaborationStrangers checks two authors with no joint paper or
+// co-author in common.
+func TestNewCollaborationStrangers(t *testing.T) {
+	c := metrics.NewCollaboration("A", "E", []semscholar.Paper{by("a", "A", "C")}, []semscholar.Paper{by("e", "E", "D")})
+	if c.JointPapers != nil || c.FirstYear != 0 || c.LastYear != 0 || c.SharedCoauthors != nil {
+		t.Errorf("collaboration of strangers = %+v", c)
+	}
+}
@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// IntentFields are the fields FetchIntentProfile lists citations with: the
+// intents and influence of each, and the date of the citing paper.
+const IntentFields = "intents,isInfluential,year,publicationDate"
+
+// IntentProfile is how a paper is cited: for background, for its methods or
+// for its results, and how often influentially.
+type IntentProfile struct {
+	PaperID string `json:"paperId"`
+	// Citations is the number of citations profiled, and Influential the
+	// number of them Semantic Scholar judges influential.
+	Citations        int     `json:"citations"`
+	Influential      int     `json:"influential"`
+	InfluentialShare float64 `json:"influentialShare"`
+	// Classified is the number of citations with an intent; the shares of
+	// Intents are of them. A citation may have several intents, or none.
+	Classified int           `json:"classified"`
+	Intents    []IntentCount `json:"intents"`
+	// ByYear profiles the citations by the year of the citing paper, those
+	// of unknown year left out.
+	ByYear []YearIntents `json:"byYear,omitempty"`
+}
+
+// IntentCount is the number of citations with an intent.
+type IntentCount struct {
+	Intent    string `json:"intent"`
+	Citations int    `json:"citations"`
+	// Influential is the number of those citations that are influential.
+	Influential int     `json:"influential"`
+	Share       float64 `json:"share"`
+}
+
+// YearIntents is the number of citations made in a year, in all and with
+// each intent.
+type YearIntents struct {
+	Year        int            `json:"year"`
+	Citations   int            `json:"citations"`
+	Influential int            `json:"influential"`
+	Intents     map[string]int `json:"intents,omitempty"`
+}
+
+// intentOrder is the order of the intents in a profile, others following
+// by count.
+var intentOrder = []string{semscholar.IntentBackground, semscholar.IntentMethodology, semscholar.IntentResult}
+
+// NewIntentProfile profiles the citations of the paper with the paper ID
+// paperID. The citations should have their intents and influence, and the
+// publication dates of their citing papers for ByYear. The three intents
+// Semantic Scholar reports are always in Intents, first.
+func NewIntentProfile(paperID string, citations []semscholar.Citation) IntentProfile {
+	ip := IntentProfile{PaperID: paperID, Citations: len(citations)}
+	counts := make(map[string]*IntentCount)
+	for _, intent := range intentOrder {
+		counts[intent] = &IntentCount{Intent: intent}
+	}
+	years := make(map[int]*YearIntents)
+	for _, c := range citations {
+		if c.IsInfluential {
+			ip.Influential++
+		}
+		if len(c.Intents) > 0 {
+			ip.Classified++
+		}
+		var y *YearIntents
+		if yr := c.CitingPaper.PublishedYear(); yr != 0 {
+			if y = years[yr]; y == nil {
+				y = &YearIntents{Year: yr, Intents: make(map[string]int)}
+				years[yr] = y
+			}
+			y.Citations++
+			if c.IsInfluential {
+				y.Influential++
+			}
+		}
+		for _, intent := range c.Intents {
+			ic := counts[intent]
+			if ic == nil {
+				ic = &IntentCount{Intent: intent}
+				counts[intent] = ic
+			}
+			ic.Citations++
+			if c.IsInfluential {
+				ic.Influential++
+			}
+			if y != nil {
+				y.Intents[intent]++
+			}
+		}
+	}
+	if ip.Citations > 0 {
+		ip.InfluentialShare = float64(ip.Influential) / float64(ip.Citations)
+	}
+	for _, ic := range counts {
+		if ip.Classified > 0 {
+			ic.Share = float64(ic.Citations) / float64(ip.Classified)
+		}
+		ip.Intents = append(ip.Intents, *ic)
+	}
+	slices.SortFunc(ip.Intents, func(a, b IntentCount) int {
+		return cmp.Or(cmp.Compare(intentRank(a.Intent), intentRank(b.Intent)),
+			cmp.Compare(b.Citations, a.Citations), cmp.Compare(a.Intent, b.Intent))
+	})
+	for _, y := range years {
+		ip.ByYear = append(ip.ByYear, *y)
+	}
+	slices.SortFunc(ip.ByYear, func(a, b YearIntents) int { return cmp.Compare(a.Year, b.Year) })
+	return ip
+}
+
+// intentRank is the place of intent in intentOrder, or after it.
+func intentRank(intent string) int {
+	if i := slices.Index(intentOrder, intent); i >= 0 {
+		return i
+	}
+	return len(intentOrder)
+}
+
+// FetchIntentProfile lists the citations of the paper with the paper ID
+// paperID, a request per thousand, and profiles them.
+func FetchIntentProfile(ctx context.Context, c *semscholar.Client, paperID string) (*IntentProfile, error) {
+	citations, err := semscholar.Collect(c.GetPaperCitationsSeq(ctx, paperID, IntentFields))
+	if err != nil {
+		return nil, err
+	}
+	ip := NewIntentProfile(paperID, citations)
+	return &ip, nil
+}
@@ -0,0 +1,80 @@
+package metrics_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/metrics"
+)
+
+// citation returns a citation from a paper of year with intents.
+func citation(year int, influential bool, intents ...string) semscholar.Citation {
+	return semscholar.Citation{CitingPaper: semscholar.Paper{Year: year}, IsInfluential: influential, Intents: intents}
+}
+
+// TestNewIntentProfile checks the counts and shares of a profile, that the
+// three intents come first even when unused, that unknown intents follow,
+// and that citations of unknown year are left out of ByYear.
+func TestNewIntentProfile(t *testing.T) {
+	citations := []semscholar.Citation{
+		citation(2020, true, semscholar.IntentMethodology),
+		citation(2020, false, semscholar.IntentBackground, semscholar.IntentMethodology),
+		citation(2021, false, semscholar.IntentBackground),
+		citation(2021, true, "extension"),
+		citation(0, false),
+	}
+	ip := metrics.NewIntentProfile("p1", citations)
+	if ip.PaperID != "p1" || ip.Citations != 5 || ip.Influential != 2 || ip.Classified != 4 || !near(ip.InfluentialShare, 0.4) {
+		t.Errorf("profile = %+v, want 5 citations, 2 influential and 4 classified", ip)
+	}
+	wantIntents := []metrics.IntentCount{
+		{Intent: semscholar.IntentBackground, Citations: 2, Share: 0.5},
+		{Intent: semscholar.IntentMethodology, Citations: 2, Influential: 1, Share: 0.5},
+		{Intent: semscholar.IntentResult},
+		{Intent: "extension", Citations: 1, Influential: 1, Share: 0.25},
+	}
+	if !reflect.DeepEqual(ip.Intents, wantIntents) {
+		t.Errorf("Intents = %+v, want %+v", ip.Intents, wantIntents)
+	}
+	wantYears := []metrics.YearIntents{
+		{Year: 2020, Citations: 2, Influential: 1, Intents: map[string]int{semscholar.IntentBackground: 1, semscholar.IntentMethodology: 2}},
+		{Year: 2021, Citations: 2, Influential: 1, Intents: map[string]int{semscholar.IntentBackground: 1, "extension": 1}},
+	}
+	if !reflect.DeepEqual(ip.ByYear, wantYears) {
+		t.Errorf("ByYear = %+v, want %+v", ip.ByYear, wantYears)
+	}
+
+	empty := metrics.NewIntentProfile("p2", nil)
+	if empty.Citations != 0 || empty.InfluentialShare != 0 || len(empty.Intents) != 3 || empty.ByYear != nil {
+		t.Errorf("profile of no citations = %+v", empty)
+	}
+}
+
+// TestFetchIntentProfile checks that FetchIntentProfile asks for the fields
+// it profiles by and profiles every page.
+func TestFetchIntentProfile(t *testing.T) {
+	var fields []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fields = append(fields, r.URL.Query().Get("fields"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"offset":0,"data":[
+			{"isInfluential":true,"intents":["methodology"],"citingPaper":{"paperId":"a","year":2020}},
+			{"isInfluential":false,"intents":[],"citingPaper":{"paperId":"b","publicationDate":"2021-03-01"}}
+		]}`))
+	}))
+	defer srv.Close()
+	ip, err := metrics.FetchIntentProfile(context.Background(), semscholar.NewClient(srv.URL, srv.Client()), "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 1 || fields[0] != metrics.IntentFields {
+		t.Errorf("requested fields %q, want %q", fields, metrics.IntentFields)
+	}
+	if ip.Citations != 2 || ip.Influential != 1 || ip.Classified != 1 || len(ip.ByYear) != 2 {
+		t.Errorf("profile = %+v, want 2 citations of 2020 and 2021, 1 influential and classified", ip)
+	}
+}
@@ -0,0 +1,146 @@
+// Package metrics computes bibliometric indicators, such as the h-index of
+// an author, from Semantic Scholar papers.
+package metrics
+
+import (
+	"slices"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// Years is a range of publication years. A zero bound leaves that end of the
+// range open, so the zero Years contains every year, and papers whose year
+// is unknown.
+type Years struct {
+	From int `json:"from,omitempty"`
+	To   int `json:"to,omitempty"`
+}
+
+// Contains reports whether year is in the range. An unknown year, zero, is
+// only in a range open at both ends.
+func (r Years) Contains(year int) bool {
+	if year == 0 {
+		return r.From == 0 && r.To == 0
+	}
+	return (r.From == 0 || year >= r.From) && (r.To == 0 || year <= r.To)
+}
+
+// Filter returns the papers published in the range.
+func (r Years) Filter(papers []semscholar.Paper) []semscholar.Paper {
+	var in []semscholar.Paper
+	for _, p := range papers {
+		if r.Contains(p.PublishedYear()) {
+			in = append(in, p)
+		}
+	}
+	return in
+}
+
+// citationCounts returns the citation counts of papers, most cited first.
+func citationCounts(papers []semscholar.Paper) []int {
+	counts := make([]int, len(papers))
+	for i, p := range papers {
+		counts[i] = p.CitationCount.Int
+	}
+	slices.Sort(counts)
+	slices.Reverse(counts)
+	return counts
+}
+
+// HIndex returns the largest h such that h of the papers are each cited at
+// least h times.
+func HIndex(papers []semscholar.Paper) int {
+	h := 0
+	for i, c := range citationCounts(papers) {
+		if c < i+1 {
+			break
+		}
+		h = i + 1
+	}
+	return h
+}
+
+// GIndex returns the largest g such that the g most cited papers are cited
+// at least g² times together. g is at most the number of papers.
+func GIndex(papers []semscholar.Paper) int {
+	g, total := 0, 0
+	for i, c := range citationCounts(papers) {
+		total += c
+		if total < (i+1)*(i+1) {
+			break
+		}
+		g = i + 1
+	}
+	return g
+}
+
+// I10Index returns the number of papers cited at least 10 times.
+func I10Index(papers []semscholar.Paper) int {
+	n := 0
+	for _, p := range papers {
+		if p.CitationCount.Int >= 10 {
+			n++
+		}
+	}
+	return n
+}
+
+// MQuotient returns the h-index of the papers divided by the number of
+// years from the first of them to year, counting both, or zero if no paper
+// has a known year at or before it.
+func MQuotient(papers []semscholar.Paper, year int) float64 {
+	first := 0
+	for _, p := range papers {
+		if y := p.PublishedYear(); y != 0 && y <= year && (first == 0 || y < first) {
+			first = y
+		}
+	}
+	if first == 0 {
+		return 0
+	}
+	return float64(HIndex(papers)) / float64(year-first+1)
+}
+
+// AuthorMetrics are the indicators of an author computed from their papers.
+type AuthorMetrics struct {
+	Papers    int     `json:"papers"`
+	Citations int     `json:"citations"`
+	HIndex    int     `json:"hIndex"`
+	GIndex    int     `json:"gIndex"`
+	I10Index  int     `json:"i10Index"`
+	MQuotient float64 `json:"mQuotient"`
+	// FirstYear is the year of the earliest paper, or zero if no paper has
+	// a known year.
+	FirstYear int `json:"firstYear,omitempty"`
+}
+
+// ForAuthor computes the metrics of the papers of an author published in
+// years, such as those of Client.GetAuthorPapersSeq with the citationCount,
+// year and publicationDate fields. The m-quotient runs to the end of the range,
+// or to the current year if it is open.
+//
+// Counts come from the papers as fetched, so they may differ from the
+// Author.HIndex of Semantic Scholar, which is computed over its whole
+// record of the author.
+func ForAuthor(papers []semscholar.Paper, years Years) AuthorMetrics {
+	papers = years.Filter(papers)
+	m := AuthorMetrics{
+		Papers:   len(papers),
+		HIndex:   HIndex(papers),
+		GIndex:   GIndex(papers),
+		I10Index: I10Index(papers),
+	}
+	for _, p := range papers {
+		m.Citations += p.CitationCount.Int
+		if y := p.PublishedYear(); y != 0 && (m.FirstYear == 0 || y < m.FirstYear) {
+			m.FirstYear = y
+		}
+	}
+	end := years.To
+	if end == 0 {
+		end = time.Now().Year()
+	}
+	m.MQuotient = MQuotient(papers, end)
+	return m
+}
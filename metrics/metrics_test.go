@@ -0,0 +1,110 @@
+package metrics_test
+
+import (
+	"math"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/metrics"
+)
+
+// cited returns papers with the given citation counts and no known year.
+func cited(counts ...int) []semscholar.Paper {
+	papers := make([]semscholar.Paper, len(counts))
+	for i, c := range counts {
+		papers[i] = semscholar.Paper{PaperID: string(rune('a' + i)), CitationCount: semscholar.NewNullInt(c)}
+	}
+	return papers
+}
+
+// TestIndexes checks the h-, g- and i10-indexes at their boundaries.
+func TestIndexes(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		counts    []int
+		h, g, i10 int
+	}{
+		{"empty", nil, 0, 0, 0},
+		{"uncited", []int{0, 0, 0}, 0, 0, 0},
+		{"one citation", []int{1}, 1, 1, 0},
+		{"ties at the threshold", []int{3, 3, 3, 3}, 3, 3, 0},
+		{"one short of the threshold", []int{3, 3, 2}, 2, 2, 0},
+		{"unsorted", []int{1, 10, 4, 0, 6}, 3, 4, 1},
+		{"g capped at the paper count", []int{100}, 1, 1, 1},
+		{"g above h", []int{20, 1, 1, 1, 1}, 1, 4, 1},
+		{"i10 boundary", []int{9, 10, 11}, 3, 3, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			papers := cited(tc.counts...)
+			if got := metrics.HIndex(papers); got != tc.h {
+				t.Errorf("HIndex = %d, want %d", got, tc.h)
+			}
+			if got := metrics.GIndex(papers); got != tc.g {
+				t.Errorf("GIndex = %d, want %d", got, tc.g)
+			}
+			if got := metrics.I10Index(papers); got != tc.i10 {
+				t.Errorf("I10Index = %d, want %d", got, tc.i10)
+			}
+		})
+	}
+}
+
+// TestMQuotient checks the m-quotient in the year of the first paper, later,
+// before any paper and without known years.
+func TestMQuotient(t *testing.T) {
+	papers := cited(5, 5, 5)
+	papers[0].Year, papers[1].Year = 2020, 2022
+	for _, tc := range []struct {
+		name   string
+		papers []semscholar.Paper
+		year   int
+		want   float64
+	}{
+		{"publication year", papers, 2020, 3},
+		{"later", papers, 2024, 3.0 / 5},
+		{"before the first paper", papers, 2019, 0},
+		{"unknown years", cited(5, 5), 2024, 0},
+		{"empty", nil, 2024, 0},
+	} {
+		if got := metrics.MQuotient(tc.papers, tc.year); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%s: MQuotient = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// TestForAuthor checks that ForAuthor keeps the papers in range and runs the
+// m-quotient to its end.
+func TestForAuthor(t *testing.T) {
+	papers := cited(12, 3, 1, 40)
+	papers[0].Year, papers[1].Year, papers[2].Year, papers[3].Year = 2018, 2019, 2021, 2010
+	got := metrics.ForAuthor(papers, metrics.Years{From: 2015, To: 2021})
+	want := metrics.AuthorMetrics{Papers: 3, Citations: 16, HIndex: 2, GIndex: 3, I10Index: 1, MQuotient: 0.5, FirstYear: 2018}
+	if got != want {
+		t.Errorf("ForAuthor = %+v, want %+v", got, want)
+	}
+	if got := metrics.ForAuthor(nil, metrics.Years{}); got != (metrics.AuthorMetrics{}) {
+		t.Errorf("ForAuthor of no papers = %+v, want zero", got)
+	}
+}
+
+// TestYears checks open and closed ranges and papers of unknown year.
+func TestYears(t *testing.T) {
+	for _, tc := range []struct {
+		r    metrics.Years
+		year int
+		want bool
+	}{
+		{metrics.Years{}, 0, true},
+		{metrics.Years{}, 1999, true},
+		{metrics.Years{From: 2000}, 0, false},
+		{metrics.Years{From: 2000}, 2000, true},
+		{metrics.Years{From: 2000}, 1999, false},
+		{metrics.Years{To: 2000}, 2000, true},
+		{metrics.Years{To: 2000}, 2001, false},
+		{metrics.Years{From: 2000, To: 2000}, 2000, true},
+	} {
+		if got := tc.r.Contains(tc.year); got != tc.want {
+			t.Errorf("%+v.Contains(%d) = %v, want %v", tc.r, tc.year, got, tc.want)
+		}
+	}
+}
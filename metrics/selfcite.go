@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// IsSelfCitation reports whether citing and cited share an author. Authors
+// are compared by ID, or by name, ignoring case, where either has no ID.
+func IsSelfCitation(citing, cited semscholar.Paper) bool {
+	for _, a := range citing.Authors {
+		for _, b := range cited.Authors {
+			if a.AuthorID != "" && b.AuthorID != "" {
+				if a.AuthorID == b.AuthorID {
+					return true
+				}
+			} else if a.Name != "" && strings.EqualFold(strings.TrimSpace(a.Name), strings.TrimSpace(b.Name)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SelfCitations counts the self-citations of a paper.
+type SelfCitations struct {
+	PaperID string `json:"paperId"`
+	// Citations is the citation count of the paper, and Self the number of
+	// the citations listed for it whose citing papers share an author with
+	// it. Citations the listing left out are taken not to be self-citations.
+	Citations int `json:"citations"`
+	Self      int `json:"self"`
+}
+
+// External returns the number of citations that are not self-citations.
+func (s SelfCitations) External() int {
+	return max(s.Citations-s.Self, 0)
+}
+
+// CountSelfCitations counts the citations of cited, which should have its
+// authors and citation count, that are self-citations. The citations should
+// have the authors of their citing papers.
+func CountSelfCitations(cited semscholar.Paper, citations []semscholar.Citation) SelfCitations {
+	s := SelfCitations{PaperID: cited.PaperID, Citations: max(cited.CitationCount.Int, len(citations))}
+	for _, c := range citations {
+		if IsSelfCitation(c.CitingPaper, cited) {
+			s.Self++
+		}
+	}
+	return s
+}
+
+// FetchSelfCitations lists the citations of each of papers, which should
+// have their authors and citation counts, and counts their self-citations.
+// Papers without citations cost no request.
+func FetchSelfCitations(ctx context.Context, c *semscholar.Client, papers []semscholar.Paper) ([]SelfCitations, error) {
+	counts := make([]SelfCitations, len(papers))
+	for i, p := range papers {
+		if p.CitationCount.Int == 0 {
+			counts[i] = SelfCitations{PaperID: p.PaperID}
+			continue
+		}
+		citations, err := semscholar.Collect(c.GetPaperCitationsSeq(ctx, p.PaperID, "authors"))
+		if err != nil {
+			return nil, err
+		}
+		counts[i] = CountSelfCitations(p, citations)
+	}
+	return counts, nil
+}
+
+// WithoutSelfCitations returns copies of papers whose citation counts leave
+// out the self-citations counted, in the same order, so that HIndex and the
+// other metrics can be computed from them.
+func WithoutSelfCitations(papers []semscholar.Paper, counts []SelfCitations) []semscholar.Paper {
+	self := make(map[string]int, len(counts))
+	for _, s := range counts {
+		self[s.PaperID] = s.Self
+	}
+	adjusted := make([]semscholar.Paper, len(papers))
+	for i, p := range papers {
+		p.CitationCount.Int = max(p.CitationCount.Int-self[p.PaperID], 0)
+		adjusted[i] = p
+	}
+	return adjusted
+}
+
+// AuthorSelfCitations compares the metrics of an author with and without
+// self-citations.
+type AuthorSelfCitations struct {
+	AuthorID string          `json:"authorId"`
+	Papers   []SelfCitations `json:"papers"`
+	// SelfCitations is the total of the self-citations of the papers, and
+	// SelfShare their share of all the citations.
+	SelfCitations int           `json:"selfCitations"`
+	SelfShare     float64       `json:"selfShare"`
+	All           AuthorMetrics `json:"all"`
+	External      AuthorMetrics `json:"external"`
+}
+
+// FetchAuthorSelfCitations fetches the papers of an author published in
+// years and the citations of each, and computes the author's metrics with
+// and without self-citations. It lists the citations of every cited paper,
+// so it costs a request or more per paper.
+func FetchAuthorSelfCitations(ctx context.Context, c *semscholar.Client, authorID string, years Years) (*AuthorSelfCitations, error) {
+	papers, err := semscholar.Collect(c.GetAuthorPapersSeq(ctx, authorID, "year,publicationDate,citationCount,authors"))
+	if err != nil {
+		return nil, err
+	}
+	papers = years.Filter(papers)
+	counts, err := FetchSelfCitations(ctx, c, papers)
+	if err != nil {
+		return nil, err
+	}
+	r := &AuthorSelfCitations{
+		AuthorID: authorID,
+		Papers:   counts,
+		All:      ForAuthor(papers, years),
+		External: ForAuthor(WithoutSelfCitations(papers, counts), years),
+	}
+	for _, s := range counts {
+		r.SelfCitations += s.Self
+	}
+	if r.All.Citations > 0 {
+		r.SelfShare = float64(r.SelfCitations) / float64(r.All.Citations)
+	}
+	return r, nil
+}
@@ -0,0 +1,113 @@
+package metrics_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/metrics"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// by returns a paper written by authors, given as IDs or, after "name:",
+// as names without IDs.
+func by(id string, authors ...string) semscholar.Paper {
+	p := semscholar.Paper{PaperID: id}
+	for _, a := range authors {
+		if name, ok := strings.CutPrefix(a, "name:"); ok {
+			p.Authors = append(p.Authors, semscholar.Author{Name: name})
+		} else {
+			p.Authors = append(p.Authors, semscholar.Author{AuthorID: a, Name: "Author " + a})
+		}
+	}
+	return p
+}
+
+// TestIsSelfCitation checks authors compared by ID, and by name where either
+// has no ID.
+func TestIsSelfCitation(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		citing, cited semscholar.Paper
+		want          bool
+	}{
+		{"shared ID", by("a", "1", "2"), by("b", "3", "2"), true},
+		{"no shared ID", by("a", "1"), by("b", "2"), false},
+		{"same name, different IDs", semscholar.Paper{Authors: []semscholar.Author{{AuthorID: "1", Name: "Ada"}}}, semscholar.Paper{Authors: []semscholar.Author{{AuthorID: "2", Name: "Ada"}}}, false},
+		{"name without ID", by("a", "name: ada lovelace"), semscholar.Paper{Authors: []semscholar.Author{{AuthorID: "1", Name: "Ada Lovelace"}}}, true},
+		{"empty names", by("a", "name:"), by("b", "name:"), false},
+		{"no authors", by("a"), by("b", "1"), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := metrics.IsSelfCitation(tc.citing, tc.cited); got != tc.want {
+				t.Errorf("IsSelfCitation = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestCountSelfCitations checks that the citation count is at least the
+// number of citations listed and that External never goes below zero.
+func TestCountSelfCitations(t *testing.T) {
+	cited := by("p", "1")
+	cited.CitationCount = semscholar.NewNullInt(1)
+	s := metrics.CountSelfCitations(cited, []semscholar.Citation{{CitingPaper: by("a", "1")}, {CitingPaper: by("b", "2")}})
+	if want := (metrics.SelfCitations{PaperID: "p", Citations: 2, Self: 1}); s != want {
+		t.Errorf("CountSelfCitations = %+v, want %+v", s, want)
+	}
+	if n := (metrics.SelfCitations{Citations: 1, Self: 3}).External(); n != 0 {
+		t.Errorf("External = %d, want 0", n)
+	}
+}
+
+// TestWithoutSelfCitations checks that counts are lowered by the paper's
+// self-citations, not below zero, leaving the papers passed unchanged.
+func TestWithoutSelfCitations(t *testing.T) {
+	papers := cited(5, 2, 3)
+	adjusted := metrics.WithoutSelfCitations(papers, []metrics.SelfCitations{{PaperID: "a", Self: 2}, {PaperID: "b", Self: 4}})
+	for i, want := range []int{3, 0, 3} {
+		if got := adjusted[i].CitationCount.Int; got != want {
+			t.Errorf("paper %s has %d citations, want %d", adjusted[i].PaperID, got, want)
+		}
+	}
+	if papers[0].CitationCount.Int != 5 {
+		t.Error("WithoutSelfCitations changed the papers passed")
+	}
+}
+
+// TestFetchAuthorSelfCitations checks the self-citations of an author's
+// papers, that uncited papers cost no request, and the metrics without them.
+func TestFetchAuthorSelfCitations(t *testing.T) {
+	d := semscholartest.NewDataset()
+	for _, p := range []semscholar.Paper{by("p1", "me"), by("p2", "me", "co"), by("p3", "me"), by("x", "other")} {
+		p.Year = 2020
+		d.AddPaper(p)
+	}
+	for _, c := range [][2]string{{"p2", "p1"}, {"p3", "p1"}, {"x", "p1"}, {"x", "p2"}} {
+		d.AddCitation(c[0], c[1])
+	}
+	for id, n := range map[string]int{"p1": 3, "p2": 1} {
+		p := d.Papers[id]
+		p.CitationCount = semscholar.NewNullInt(n)
+		d.Papers[id] = p
+	}
+	srv := semscholartest.NewServer(d)
+	defer srv.Close()
+
+	r, err := metrics.FetchAuthorSelfCitations(context.Background(), srv.Client(), "me", metrics.Years{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.SelfCitations != 2 || r.All.Citations != 4 || r.SelfShare != 0.5 {
+		t.Errorf("self-citations %d of %d, share %v, want 2 of 4, 0.5", r.SelfCitations, r.All.Citations, r.SelfShare)
+	}
+	if r.All.HIndex != 1 || r.External.Citations != 2 || r.External.HIndex != 1 {
+		t.Errorf("h-index %d, external citations %d and h-index %d, want 1, 2, 1", r.All.HIndex, r.External.Citations, r.External.HIndex)
+	}
+	for _, path := range srv.Requests() {
+		if strings.Contains(path, "p3/citations") {
+			t.Errorf("listed the citations of an uncited paper: %s", path)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package metrics
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// TopicFields are the paper fields NewTopicProfile uses.
+const TopicFields = "year,publicationDate,fieldsOfStudy"
+
+// TopicProfile is the distribution of the fields of study of a set of
+// papers. A paper in several fields counts as a share of a paper in each,
+// so the shares of a profile sum to one; papers with no field are left out.
+type TopicProfile struct {
+	// Papers is the number of papers with some field.
+	Papers int          `json:"papers"`
+	Fields []FieldShare `json:"fields"`
+	// ByYear is the profile of the papers of each year, oldest first,
+	// leaving out those of unknown year.
+	ByYear []YearProfile `json:"byYear,omitempty"`
+}
+
+// FieldShare is the part of a TopicProfile in a field of study. Papers is
+// the number of papers in the field, and Share the field's share of them.
+type FieldShare struct {
+	Field  string  `json:"field"`
+	Papers int     `json:"papers"`
+	Share  float64 `json:"share"`
+}
+
+// YearProfile is the topic profile of the papers of a year.
+type YearProfile struct {
+	Year   int          `json:"year"`
+	Papers int          `json:"papers"`
+	Fields []FieldShare `json:"fields"`
+}
+
+// NewTopicProfile computes the topic profile of papers, which should have
+// the fields of TopicFields.
+func NewTopicProfile(papers []semscholar.Paper) *TopicProfile {
+	byYear := make(map[int][]semscholar.Paper)
+	for _, p := range papers {
+		if y := p.PublishedYear(); y != 0 {
+			byYear[y] = append(byYear[y], p)
+		}
+	}
+	t := &TopicProfile{}
+	t.Papers, t.Fields = fieldShares(papers)
+	for _, y := range slices.Sorted(maps.Keys(byYear)) {
+		n, fields := fieldShares(byYear[y])
+		if n > 0 {
+			t.ByYear = append(t.ByYear, YearProfile{Year: y, Papers: n, Fields: fields})
+		}
+	}
+	return t
+}
+
+// fieldShares returns the number of papers with some field of study and
+// the shares of the fields, largest first.
+func fieldShares(papers []semscholar.Paper) (int, []FieldShare) {
+	weights := make(map[string]float64)
+	counts := make(map[string]int)
+	n := 0
+	for _, p := range papers {
+		fields := slices.Compact(slices.Sorted(slices.Values(p.FieldsOfStudy)))
+		if len(fields) == 0 {
+			continue
+		}
+		n++
+		for _, f := range fields {
+			weights[f] += 1 / float64(len(fields))
+			counts[f]++
+		}
+	}
+	var shares []FieldShare
+	for f, w := range weights {
+		shares = append(shares, FieldShare{Field: f, Papers: counts[f], Share: w / float64(n)})
+	}
+	slices.SortFunc(shares, func(a, b FieldShare) int {
+		return cmp.Or(cmp.Compare(b.Share, a.Share), strings.Compare(a.Field, b.Field))
+	})
+	return n, shares
+}
+
+// Similarity returns the cosine similarity of the field shares of two
+// profiles: one if they are spread over the fields alike, zero if they have
+// no field in common.
+func Similarity(a, b *TopicProfile) float64 {
+	shares := make(map[string]float64)
+	var na, nb, dot float64
+	for _, f := range a.Fields {
+		shares[f.Field] = f.Share
+		na += f.Share * f.Share
+	}
+	for _, f := range b.Fields {
+		dot += shares[f.Field] * f.Share
+		nb += f.Share * f.Share
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+// AuthorTopics is the topic profile of an author and of the co-authors
+// whose work is nearest to theirs.
+type AuthorTopics struct {
+	AuthorID string `json:"authorId"`
+	TopicProfile
+	// Neighbors are co-authors, most similar first.
+	Neighbors []TopicNeighbor `json:"neighbors"`
+}
+
+// TopicNeighbor is a co-author compared by topic.
+type TopicNeighbor struct {
+	AuthorID    string  `json:"authorId"`
+	Name        string  `json:"name"`
+	JointPapers int     `json:"jointPapers"`
+	Similarity  float64 `json:"similarity"`
+	// Fields are the co-author's fields of study, over all their papers.
+	Fields []FieldShare `json:"fields"`
+}
+
+// FetchAuthorTopics fetches the papers of an author and computes their
+// topic profile. It then fetches the papers of up to candidates co-authors,
+// those with the most joint papers first, and ranks them by the similarity
+// of their profiles to the author's. Each co-author costs a listing of
+// their papers; zero candidates skips them.
+func FetchAuthorTopics(ctx context.Context, c *semscholar.Client, authorID string, candidates int) (*AuthorTopics, error) {
+	papers, err := semscholar.Collect(c.GetAuthorPapersSeq(ctx, authorID, TopicFields+",authors"))
+	if err != nil {
+		return nil, err
+	}
+	t := &AuthorTopics{AuthorID: authorID, TopicProfile: *NewTopicProfile(papers)}
+	if candidates <= 0 {
+		return t, nil
+	}
+
+	joint := make(map[string]*TopicNeighbor)
+	for _, p := range papers {
+		for _, a := range p.Authors {
+			if a.AuthorID == "" || a.AuthorID == authorID {
+				continue
+			}
+			n, ok := joint[a.AuthorID]
+			if !ok {
+				n = &TopicNeighbor{AuthorID: a.AuthorID, Name: a.Name}
+				joint[a.AuthorID] = n
+			}
+			n.JointPapers++
+		}
+	}
+	coauthors := slices.SortedFunc(maps.Values(joint), func(a, b *TopicNeighbor) int {
+		return cmp.Or(b.JointPapers-a.JointPapers, strings.Compare(a.AuthorID, b.AuthorID))
+	})
+	for _, n := range coauthors[:min(candidates, len(coauthors))] {
+		theirs, err := semscholar.Collect(c.GetAuthorPapersSeq(ctx, n.AuthorID, TopicFields))
+		if err != nil {
+			return nil, err
+		}
+		profile := NewTopicProfile(theirs)
+		n.Fields = profile.Fields
+		n.Similarity = Similarity(&t.TopicProfile, profile)
+		t.Neighbors = append(t.Neighbors, *n)
+	}
+	slices.SortStableFunc(t.Neighbors, func(a, b TopicNeighbor) int { return cmp.Compare(b.Similarity, a.Similarity) })
+	return t, nil
+}
@@ -0,0 +1,125 @@
+package metrics_test
+
+import (
+	"context"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/metrics"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// inFields returns a paper of year in the given fields of study, written by
+// authors.
+func inFields(id string, year int, fields []string, authors ...string) semscholar.Paper {
+	p := semscholar.Paper{PaperID: id, Year: year, FieldsOfStudy: fields}
+	for _, a := range authors {
+		p.Authors = append(p.Authors, semscholar.Author{AuthorID: a, Name: "Author " + a})
+	}
+	return p
+}
+
+// TestNewTopicProfile checks that papers in several fields are shared between
+// them, that repeated fields count once, and that papers without fields are
+// left out of the profile and of their year.
+func TestNewTopicProfile(t *testing.T) {
+	p := metrics.NewTopicProfile([]semscholar.Paper{
+		inFields("a", 2020, []string{"Physics", "Biology", "Physics"}),
+		inFields("b", 2020, []string{"Physics"}),
+		inFields("c", 2021, nil),
+		inFields("d", 0, []string{"Art"}),
+	})
+	if p.Papers != 3 {
+		t.Errorf("Papers = %d, want 3", p.Papers)
+	}
+	want := []metrics.FieldShare{{Field: "Physics", Papers: 2, Share: 0.5}, {Field: "Art", Papers: 1, Share: 1.0 / 3}, {Field: "Biology", Papers: 1, Share: 1.0 / 6}}
+	if len(p.Fields) != len(want) {
+		t.Fatalf("Fields = %v, want %v", p.Fields, want)
+	}
+	sum := 0.0
+	for i, f := range p.Fields {
+		if f.Field != want[i].Field || f.Papers != want[i].Papers || !near(f.Share, want[i].Share) {
+			t.Errorf("Fields[%d] = %+v, want %+v", i, f, want[i])
+		}
+		sum += f.Share
+	}
+	if !near(sum, 1) {
+		t.Errorf("shares sum to %v, want 1", sum)
+	}
+	if len(p.ByYear) != 1 || p.ByYear[0].Year != 2020 || p.ByYear[0].Papers != 2 {
+		t.Errorf("ByYear = %+v, want only 2020 with 2 papers", p.ByYear)
+	}
+
+	if empty := metrics.NewTopicProfile(nil); empty.Papers != 0 || empty.Fields != nil || empty.ByYear != nil {
+		t.Errorf("profile of no papers = %+v, want zero", empty)
+	}
+}
+
+// TestSimilarity checks identical, disjoint, overlapping and empty profiles.
+func TestSimilarity(t *testing.T) {
+	profile := func(fields ...string) *metrics.TopicProfile {
+		var papers []semscholar.Paper
+		for _, f := range fields {
+			papers = append(papers, inFields(f, 2020, []string{f}))
+		}
+		return metrics.NewTopicProfile(papers)
+	}
+	for _, tc := range []struct {
+		name string
+		a, b *metrics.TopicProfile
+		want float64
+	}{
+		{"identical", profile("Physics", "Art"), profile("Art", "Physics"), 1},
+		{"disjoint", profile("Physics"), profile("Art"), 0},
+		{"overlapping", profile("Physics"), profile("Physics", "Art"), 1 / 1.4142135623730951},
+		{"empty", profile(), profile("Art"), 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := metrics.Similarity(tc.a, tc.b); !near(got, tc.want) {
+				t.Errorf("Similarity = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestFetchAuthorTopics checks that co-authors are chosen by joint papers,
+// bounded by candidates, and ranked by similarity.
+func TestFetchAuthorTopics(t *testing.T) {
+	d := semscholartest.NewDataset()
+	for _, p := range []semscholar.Paper{
+		inFields("a1", 2020, []string{"Physics"}, "me", "far", "near"),
+		inFields("a2", 2021, []string{"Physics"}, "me", "far"),
+		inFields("a3", 2021, []string{"Physics"}, "me", "rare"),
+		inFields("f1", 2019, []string{"Art"}, "far"),
+		inFields("f2", 2019, []string{"Art"}, "far"),
+		inFields("f3", 2019, []string{"Art"}, "far"),
+	} {
+		d.AddPaper(p)
+	}
+	srv := semscholartest.NewServer(d)
+	defer srv.Close()
+	ctx := context.Background()
+
+	topics, err := metrics.FetchAuthorTopics(ctx, srv.Client(), "me", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topics.Papers != 3 || len(topics.Fields) != 1 || topics.Neighbors != nil {
+		t.Errorf("topics without candidates = %+v", topics)
+	}
+
+	topics, err = metrics.FetchAuthorTopics(ctx, srv.Client(), "me", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topics.Neighbors) != 2 {
+		t.Fatalf("Neighbors = %+v, want far and one of near and rare", topics.Neighbors)
+	}
+	first, second := topics.Neighbors[0], topics.Neighbors[1]
+	if first.AuthorID != "near" || first.JointPapers != 1 || !near(first.Similarity, 1) {
+		t.Errorf("first neighbor = %+v, want near with similarity 1", first)
+	}
+	if second.AuthorID != "far" || second.JointPapers != 2 || second.Similarity >= first.Similarity {
+		t.Errorf("second neighbor = %+v, want far, less similar", second)
+	}
+}
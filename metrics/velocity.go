@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"slices"
+	"time"
+)
+
+// Sample is the citation count of a paper at some time.
+type Sample struct {
+	Time      time.Time `json:"time"`
+	Citations int       `json:"citations"`
+}
+
+// year is the length of a year in the rates of Trend.
+const year = 365.25 * 24 * time.Hour
+
+// Trend is how fast a paper is gaining citations, from a series of samples.
+// Rates are per year.
+type Trend struct {
+	Samples   int       `json:"samples"`
+	First     time.Time `json:"first"`
+	Last      time.Time `json:"last"`
+	Citations int       `json:"citations"`
+	// Gained is the number of citations gained from the first sample to the
+	// last.
+	Gained int `json:"gained"`
+	// Velocity is the slope of the least-squares line through the samples.
+	Velocity float64 `json:"velocity"`
+	// Acceleration is the change in velocity per year, from the
+	// least-squares parabola through the samples; it needs three samples
+	// at different times and is zero with fewer.
+	Acceleration float64 `json:"acceleration"`
+}
+
+// NewTrend computes the trend of samples, which need not be in order. With
+// fewer than two samples at different times the rates are zero.
+func NewTrend(samples []Sample) Trend {
+	if len(samples) == 0 {
+		return Trend{}
+	}
+	samples = sortedSamples(samples)
+	first, last := samples[0], samples[len(samples)-1]
+	t := Trend{
+		Samples:   len(samples),
+		First:     first.Time,
+		Last:      last.Time,
+		Citations: last.Citations,
+		Gained:    last.Citations - first.Citations,
+	}
+	xs, ys := points(samples)
+	t.Velocity, _ = fitLine(xs, ys)
+	if c, ok := fitParabola(xs, ys); ok {
+		t.Acceleration = 2 * c
+	}
+	return t
+}
+
+// Forecast returns the citation count the least-squares line through
+// samples predicts at time at, never less than the count of the last
+// sample. With fewer than two samples at different times it is the last
+// count.
+func Forecast(samples []Sample, at time.Time) int {
+	if len(samples) == 0 {
+		return 0
+	}
+	samples = sortedSamples(samples)
+	last := samples[len(samples)-1].Citations
+	xs, ys := points(samples)
+	slope, intercept := fitLine(xs, ys)
+	x := float64(at.Sub(samples[0].Time)) / float64(year)
+	return max(last, int(intercept+slope*x+0.5))
+}
+
+// Velocity returns the citations per year gained between the samples
+// nearest to since and the last, which can be used for the velocity over a
+// recent window, as in Velocity(samples, time.Now().AddDate(-1, 0, 0)).
+// It is zero if there are no two samples to compare.
+func Velocity(samples []Sample, since time.Time) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+	samples = sortedSamples(samples)
+	last := samples[len(samples)-1]
+	i, _ := slices.BinarySearchFunc(samples, since, func(s Sample, t time.Time) int { return s.Time.Compare(t) })
+	if i > 0 && (i == len(samples) || since.Sub(samples[i-1].Time) < samples[i].Time.Sub(since)) {
+		i--
+	}
+	from := samples[min(i, len(samples)-2)]
+	d := last.Time.Sub(from.Time)
+	if d <= 0 {
+		return 0
+	}
+	return float64(last.Citations-from.Citations) / (float64(d) / float64(year))
+}
+
+func sortedSamples(samples []Sample) []Sample {
+	return slices.SortedStableFunc(slices.Values(samples), func(a, b Sample) int { return a.Time.Compare(b.Time) })
+}
+
+// points returns the samples as years since the first and counts.
+func points(samples []Sample) (xs, ys []float64) {
+	xs = make([]float64, len(samples))
+	ys = make([]float64, len(samples))
+	for i, s := range samples {
+		xs[i] = float64(s.Time.Sub(samples[0].Time)) / float64(year)
+		ys[i] = float64(s.Citations)
+	}
+	return xs, ys
+}
+
+// fitLine returns the slope and intercept of the least-squares line through
+// the points, or zero and the mean of ys if the xs are all equal.
+func fitLine(xs, ys []float64) (slope, intercept float64) {
+	n := float64(len(xs))
+	var sx, sy, sxx, sxy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+		sxx += xs[i] * xs[i]
+		sxy += xs[i] * ys[i]
+	}
+	d := n*sxx - sx*sx
+	if d == 0 {
+		return 0, sy / n
+	}
+	slope = (n*sxy - sx*sy) / d
+	return slope, (sy - slope*sx) / n
+}
+
+// fitParabola returns the coefficient c of the least-squares parabola
+// a + bx + cx² through the points, solving the normal equations by
+// Cramer's rule. It reports false if they have no single solution, as with
+// fewer than three distinct xs.
+func fitParabola(xs, ys []float64) (float64, bool) {
+	var s [5]float64 // sums of x⁰ to x⁴
+	var t [3]float64 // sums of y, xy and x²y
+	for i, x := range xs {
+		p := 1.0
+		for k := range s {
+			s[k] += p
+			if k < 3 {
+				t[k] += p * ys[i]
+			}
+			p *= x
+		}
+	}
+	det := func(m [3][3]float64) float64 {
+		return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+			m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+			m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	}
+	m := [3][3]float64{{s[0], s[1], s[2]}, {s[1], s[2], s[3]}, {s[2], s[3], s[4]}}
+	d := det(m)
+	if d == 0 || len(slices.Compact(slices.Sorted(slices.Values(xs)))) < 3 {
+		return 0, false
+	}
+	for i := range 3 {
+		m[i][2] = t[i]
+	}
+	return det(m) / d, true
+}
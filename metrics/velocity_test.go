@@ -0,0 +1,101 @@
+package metrics_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jmwalsh91/semscholar-go/metrics"
+)
+
+var t0 = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// yearsLater returns the time y years of 365.25 days after t0.
+func yearsLater(y float64) time.Time {
+	return t0.Add(time.Duration(y * 365.25 * 24 * float64(time.Hour)))
+}
+
+// series returns samples of the counts at 0, 1, 2, ... years after t0.
+func series(counts ...int) []metrics.Sample {
+	samples := make([]metrics.Sample, len(counts))
+	for i, c := range counts {
+		samples[i] = metrics.Sample{Time: yearsLater(float64(i)), Citations: c}
+	}
+	return samples
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+// TestNewTrend checks the fitted velocity and acceleration of linear and
+// quadratic series, and of series too short to fit.
+func TestNewTrend(t *testing.T) {
+	for _, tc := range []struct {
+		name                   string
+		samples                []metrics.Sample
+		gained                 int
+		velocity, acceleration float64
+	}{
+		{"empty", nil, 0, 0, 0},
+		{"single", series(7), 0, 0, 0},
+		{"linear", series(10, 20, 30, 40), 30, 10, 0},
+		{"quadratic", series(0, 1, 4, 9, 16), 16, 4, 2},
+		{"unordered", []metrics.Sample{series(0, 10, 20)[2], series(0, 10, 20)[0], series(0, 10, 20)[1]}, 20, 10, 0},
+		{"same time", []metrics.Sample{{Time: t0, Citations: 3}, {Time: t0, Citations: 5}}, 2, 0, 0},
+		{"two samples", series(5, 8), 3, 3, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			trend := metrics.NewTrend(tc.samples)
+			if trend.Samples != len(tc.samples) || trend.Gained != tc.gained {
+				t.Errorf("%d samples gaining %d, want %d gaining %d", trend.Samples, trend.Gained, len(tc.samples), tc.gained)
+			}
+			if !near(trend.Velocity, tc.velocity) || !near(trend.Acceleration, tc.acceleration) {
+				t.Errorf("velocity %v and acceleration %v, want %v and %v", trend.Velocity, trend.Acceleration, tc.velocity, tc.acceleration)
+			}
+		})
+	}
+}
+
+// TestForecast checks that forecasts follow the fitted line but never fall
+// below the last count.
+func TestForecast(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		samples []metrics.Sample
+		at      time.Time
+		want    int
+	}{
+		{"empty", nil, t0, 0},
+		{"single", series(7), yearsLater(5), 7},
+		{"linear", series(10, 20, 30), yearsLater(4), 50},
+		{"falling line", series(30, 20, 25), yearsLater(3), 25},
+	} {
+		if got := metrics.Forecast(tc.samples, tc.at); got != tc.want {
+			t.Errorf("%s: Forecast = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+// TestVelocity checks the recent velocity from the sample nearest to since.
+func TestVelocity(t *testing.T) {
+	samples := series(0, 10, 30, 60)
+	for _, tc := range []struct {
+		name  string
+		since time.Time
+		want  float64
+	}{
+		{"whole series", t0.AddDate(-1, 0, 0), 20},
+		{"last year", yearsLater(2), 30},
+		{"nearest earlier", yearsLater(1.2), 25},
+		{"nearest later", yearsLater(1.8), 30},
+		{"after the last", yearsLater(10), 30},
+	} {
+		if got := metrics.Velocity(samples, tc.since); !near(got, tc.want) {
+			t.Errorf("%s: Velocity = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if got := metrics.Velocity(series(5), t0); got != 0 {
+		t.Errorf("Velocity of one sample = %v, want 0", got)
+	}
+}
@@ -0,0 +1,180 @@
+package metrics
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// VenueFields are the paper fields NewVenueStats uses.
+const VenueFields = "year,publicationDate,citationCount,isOpenAccess,fieldsOfStudy"
+
+// VenueQuery selects the papers of a venue for FetchVenueStats.
+type VenueQuery struct {
+	// Venue is the venue as Semantic Scholar names it, such as "Nature" or
+	// "Neural Information Processing Systems".
+	Venue string
+	Years Years
+	// Query, if set, restricts the papers to those matching it.
+	Query string
+	// MaxPapers, if positive, bounds the number of papers fetched.
+	MaxPapers int
+}
+
+// VenueStats describes the papers of a venue.
+type VenueStats struct {
+	Venue string `json:"venue"`
+	Years Years  `json:"years"`
+	// Truncated is set if the papers were cut short at VenueQuery.MaxPapers.
+	Truncated bool          `json:"truncated,omitempty"`
+	Papers    int           `json:"papers"`
+	Citations CitationStats `json:"citations"`
+	// OpenAccess is the number of open-access papers and OpenAccessShare
+	// their share of all the papers.
+	OpenAccess      int     `json:"openAccess"`
+	OpenAccessShare float64 `json:"openAccessShare"`
+	// Fields are the fields of study of the papers, most common first.
+	Fields []FieldCount `json:"fieldsOfStudy"`
+	// ByYear counts the papers of each year, oldest first, leaving out
+	// those of unknown year.
+	ByYear []YearCount `json:"byYear"`
+}
+
+// CitationStats summarizes the citation counts of papers. The percentiles
+// are nearest-rank: P90 is the count that 90% of the papers do not exceed.
+type CitationStats struct {
+	Total int     `json:"total"`
+	Mean  float64 `json:"mean"`
+	P25   int     `json:"p25"`
+	P50   int     `json:"median"`
+	P75   int     `json:"p75"`
+	P90   int     `json:"p90"`
+	P99   int     `json:"p99"`
+	Max   int     `json:"max"`
+}
+
+// FieldCount is the number of papers in a field of study.
+type FieldCount struct {
+	Field  string `json:"field"`
+	Papers int    `json:"papers"`
+}
+
+// YearCount is the number of papers of a year and their citations.
+type YearCount struct {
+	Year      int `json:"year"`
+	Papers    int `json:"papers"`
+	Citations int `json:"citations"`
+}
+
+// FetchVenueStats bulk-searches the papers q selects and computes their
+// statistics. A venue with no papers is not an error; its statistics are
+// all zero.
+func FetchVenueStats(ctx context.Context, c *semscholar.Client, q VenueQuery) (*VenueStats, error) {
+	filters := map[string]string{"venue": q.Venue}
+	if y := q.Years.filter(); y != "" {
+		filters["year"] = y
+	}
+	var papers []semscholar.Paper
+	truncated := false
+	for p, err := range c.BulkSearchPapersSeq(ctx, q.Query, VenueFields, "", "", filters) {
+		if err != nil {
+			return nil, err
+		}
+		if q.MaxPapers > 0 && len(papers) == q.MaxPapers {
+			truncated = true
+			break
+		}
+		papers = append(papers, p)
+	}
+	s := NewVenueStats(papers)
+	s.Venue, s.Years, s.Truncated = q.Venue, q.Years, truncated
+	return s, nil
+}
+
+// NewVenueStats computes the statistics of papers, which should have the
+// fields of VenueFields.
+func NewVenueStats(papers []semscholar.Paper) *VenueStats {
+	s := &VenueStats{Papers: len(papers)}
+	counts := make([]int, len(papers))
+	fields := make(map[string]int)
+	years := make(map[int]*YearCount)
+	for i, p := range papers {
+		counts[i] = p.CitationCount.Int
+		if p.IsOpenAccess {
+			s.OpenAccess++
+		}
+		for _, f := range p.FieldsOfStudy {
+			fields[f]++
+		}
+		if y := p.PublishedYear(); y != 0 {
+			yc, ok := years[y]
+			if !ok {
+				yc = &YearCount{Year: y}
+				years[y] = yc
+			}
+			yc.Papers++
+			yc.Citations += p.CitationCount.Int
+		}
+	}
+	s.Citations = NewCitationStats(counts)
+	if len(papers) > 0 {
+		s.OpenAccessShare = float64(s.OpenAccess) / float64(len(papers))
+	}
+	for f, n := range fields {
+		s.Fields = append(s.Fields, FieldCount{f, n})
+	}
+	slices.SortFunc(s.Fields, func(a, b FieldCount) int {
+		return cmp.Or(b.Papers-a.Papers, strings.Compare(a.Field, b.Field))
+	})
+	for _, y := range slices.Sorted(maps.Keys(years)) {
+		s.ByYear = append(s.ByYear, *years[y])
+	}
+	return s
+}
+
+// NewCitationStats summarizes citation counts, which it sorts.
+func NewCitationStats(counts []int) CitationStats {
+	if len(counts) == 0 {
+		return CitationStats{}
+	}
+	slices.Sort(counts)
+	var s CitationStats
+	for _, c := range counts {
+		s.Total += c
+	}
+	s.Mean = float64(s.Total) / float64(len(counts))
+	s.P25 = percentile(counts, 25)
+	s.P50 = percentile(counts, 50)
+	s.P75 = percentile(counts, 75)
+	s.P90 = percentile(counts, 90)
+	s.P99 = percentile(counts, 99)
+	s.Max = counts[len(counts)-1]
+	return s
+}
+
+// percentile returns the nearest-rank p'th percentile of sorted.
+func percentile(sorted []int, p int) int {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank-1, 0)]
+}
+
+// filter returns the range as the year filter of a search, such as
+// "2018-2024", "2018-" or "2020", or "" if it is open at both ends.
+func (r Years) filter() string {
+	switch {
+	case r.From == 0 && r.To == 0:
+		return ""
+	case r.From == r.To:
+		return strconv.Itoa(r.From)
+	case r.From == 0:
+		return "-" + strconv.Itoa(r.To)
+	case r.To == 0:
+		return strconv.Itoa(r.From) + "-"
+	}
+	return strconv.Itoa(r.From) + "-" + strconv.Itoa(r.To)
+}
@@ -0,0 +1,110 @@
+package metrics_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/metrics"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestNewCitationStats checks the nearest-rank percentiles of no counts, one
+// count and a hundred.
+func TestNewCitationStats(t *testing.T) {
+	hundred := make([]int, 100)
+	for i := range hundred {
+		hundred[i] = 100 - i
+	}
+	for _, tc := range []struct {
+		name   string
+		counts []int
+		want   metrics.CitationStats
+	}{
+		{"empty", nil, metrics.CitationStats{}},
+		{"one", []int{7}, metrics.CitationStats{Total: 7, Mean: 7, P25: 7, P50: 7, P75: 7, P90: 7, P99: 7, Max: 7}},
+		{"two", []int{10, 0}, metrics.CitationStats{Total: 10, Mean: 5, P25: 0, P50: 0, P75: 10, P90: 10, P99: 10, Max: 10}},
+		{"hundred", hundred, metrics.CitationStats{Total: 5050, Mean: 50.5, P25: 25, P50: 50, P75: 75, P90: 90, P99: 99, Max: 100}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := metrics.NewCitationStats(tc.counts); got != tc.want {
+				t.Errorf("NewCitationStats = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestNewVenueStats checks the open-access share, the order of fields with
+// tied counts and the yearly counts, which leave out papers of unknown year.
+func TestNewVenueStats(t *testing.T) {
+	if s := metrics.NewVenueStats(nil); s.Papers != 0 || s.OpenAccessShare != 0 || s.Fields != nil || s.ByYear != nil {
+		t.Errorf("stats of no papers = %+v, want zero", s)
+	}
+
+	papers := cited(4, 2, 0, 6)
+	papers[0].Year, papers[1].Year, papers[2].Year = 2021, 2020, 2021
+	papers[0].IsOpenAccess = true
+	papers[0].FieldsOfStudy = []string{"Physics", "Biology"}
+	papers[1].FieldsOfStudy = []string{"Physics"}
+	papers[3].FieldsOfStudy = []string{"Art"}
+	s := metrics.NewVenueStats(papers)
+	if s.Papers != 4 || s.OpenAccess != 1 || s.OpenAccessShare != 0.25 || s.Citations.Total != 12 {
+		t.Errorf("stats = %+v", s)
+	}
+	wantFields := []metrics.FieldCount{{Field: "Physics", Papers: 2}, {Field: "Art", Papers: 1}, {Field: "Biology", Papers: 1}}
+	if !reflect.DeepEqual(s.Fields, wantFields) {
+		t.Errorf("Fields = %v, want %v", s.Fields, wantFields)
+	}
+	wantYears := []metrics.YearCount{{Year: 2020, Papers: 1, Citations: 2}, {Year: 2021, Papers: 2, Citations: 4}}
+	if !reflect.DeepEqual(s.ByYear, wantYears) {
+		t.Errorf("ByYear = %v, want %v", s.ByYear, wantYears)
+	}
+}
+
+// TestFetchVenueStats checks that only the venue's papers in the years asked
+// for are counted, up to MaxPapers, and that an empty venue is not an error.
+func TestFetchVenueStats(t *testing.T) {
+	d := semscholartest.NewDataset()
+	for i, p := range []semscholar.Paper{
+		{Venue: "Nature", Year: 2019},
+		{Venue: "Nature", Year: 2020},
+		{Venue: "Nature", Year: 2021},
+		{Venue: "Science", Year: 2020},
+	} {
+		p.PaperID = string(rune('a' + i))
+		p.CitationCount = semscholar.NewNullInt(i + 1)
+		d.AddPaper(p)
+	}
+	srv := semscholartest.NewServer(d)
+	defer srv.Close()
+	ctx := context.Background()
+
+	s, err := metrics.FetchVenueStats(ctx, srv.Client(), metrics.VenueQuery{Venue: "Nature", Years: metrics.Years{From: 2020}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Venue != "Nature" || s.Papers != 2 || s.Citations.Total != 5 || s.Truncated {
+		t.Errorf("stats = %+v, want 2 papers with 5 citations", s)
+	}
+
+	s, err = metrics.FetchVenueStats(ctx, srv.Client(), metrics.VenueQuery{Venue: "Nature", MaxPapers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Papers != 2 || !s.Truncated {
+		t.Errorf("bounded stats have %d papers, truncated %v, want 2, true", s.Papers, s.Truncated)
+	}
+	s, err = metrics.FetchVenueStats(ctx, srv.Client(), metrics.VenueQuery{Venue: "Nature", MaxPapers: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Papers != 3 || s.Truncated {
+		t.Errorf("stats bounded at the paper count have %d papers, truncated %v, want 3, false", s.Papers, s.Truncated)
+	}
+
+	s, err = metrics.FetchVenueStats(ctx, srv.Client(), metrics.VenueQuery{Venue: "Cell"})
+	if err != nil || s.Papers != 0 {
+		t.Errorf("empty venue: %+v, %v", s, err)
+	}
+}
@@ -0,0 +1,69 @@
+package semscholar
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// NullInt is a number the API may leave out, such as a paper's citation
+// count, which it only returns when the field is requested. Valid tells a
+// count of zero from one that was not returned. The zero NullInt is null,
+// and in JSON a NullInt is a number or null.
+type NullInt struct {
+	Int   int
+	Valid bool
+}
+
+// NewNullInt returns a valid NullInt of n.
+func NewNullInt(n int) NullInt {
+	return NullInt{n, true}
+}
+
+// IsZero reports whether n is null.
+func (n NullInt) IsZero() bool {
+	return !n.Valid
+}
+
+// Get returns the number and whether it is valid.
+func (n NullInt) Get() (int, bool) {
+	return n.Int, n.Valid
+}
+
+// Or returns the number, or def if n is null.
+func (n NullInt) Or(def int) int {
+	if !n.Valid {
+		return def
+	}
+	return n.Int
+}
+
+// String returns the number in decimal, or "" if n is null.
+func (n NullInt) String() string {
+	if !n.Valid {
+		return ""
+	}
+	return strconv.Itoa(n.Int)
+}
+
+// MarshalJSON encodes n as a number, or null if n is null.
+func (n NullInt) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return strconv.AppendInt(nil, int64(n.Int), 10), nil
+}
+
+// UnmarshalJSON decodes a number or null.
+func (n *NullInt) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*n = NullInt{}
+		return nil
+	}
+	var v int
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*n = NewNullInt(v)
+	return nil
+}
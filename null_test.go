@@ -0,0 +1,40 @@
+package semscholar_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// TestNullIntOmitted checks that papers and authors leave null counts out of its JSON
+// and keeps counts of zero, which needs the omitzero option of Go 1.24.
+func TestNullIntOmitted(t *testing.T) {
+	data, err := json.Marshal(semscholar.Paper{PaperID: "a", ReferenceCount: semscholar.NewNullInt(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(data)
+	if strings.Contains(s, `"citationCount"`) || !strings.Contains(s, `"referenceCount":0`) {
+		t.Errorf("encoded %s, want referenceCount 0 and no citationCount", s)
+	}
+	var p semscholar.Paper
+	if err := json.Unmarshal(data, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.CitationCount.Valid || p.ReferenceCount != semscholar.NewNullInt(0) {
+		t.Errorf("decoded CitationCount %+v and ReferenceCount %+v", p.CitationCount, p.ReferenceCount)
+	}
+	if strings.Contains(s, `"influentialCitationCount"`) {
+		t.Errorf("encoded %s, want no influentialCitationCount", s)
+	}
+	data, err = json.Marshal(semscholar.Author{AuthorID: "1", HIndex: semscholar.NewNullInt(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = string(data)
+	if strings.Contains(s, `"influentialCitationCount"`) || strings.Contains(s, `"paperCount"`) || !strings.Contains(s, `"hIndex":0`) {
+		t.Errorf("encoded %s, want hIndex 0 and no paperCount or influentialCitationCount", s)
+	}
+}
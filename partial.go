@@ -0,0 +1,115 @@
+package semscholar
+
+import (
+	"context"
+	"fmt"
+	"iter"
+)
+
+// PartialError is returned alongside the results of a multi-request operation
+// when some of its requests failed. The results are complete and valid for
+// every part not listed in Failures, so callers can keep the finished work and
+// retry only what failed. errors.Is and errors.As see through it to the causes.
+type PartialError struct {
+	Op string
+	// Failures lists the failed parts in input order.
+	Failures []ChunkError
+}
+
+// ChunkError is one failed part of a multi-request operation. For batch
+// operations it covers input positions [Start, End). For paged iteration Start
+// is the number of items received before the failure and End is zero, as the
+// extent of what was missed is unknown.
+type ChunkError struct {
+	Start, End int
+	Err        error
+}
+
+// Error implements the error interface.
+func (e *ChunkError) Error() string {
+	if e.End == 0 {
+		return fmt.Sprintf("after item %d: %v", e.Start, e.Err)
+	}
+	return fmt.Sprintf("items %d-%d: %v", e.Start, e.End-1, e.Err)
+}
+
+// Unwrap returns the cause.
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// Error implements the error interface.
+func (e *PartialError) Error() string {
+	if len(e.Failures) == 1 {
+		return fmt.Sprintf("%s: partially failed: %v", e.Op, &e.Failures[0])
+	}
+	return fmt.Sprintf("%s: %d parts failed, first %v", e.Op, len(e.Failures), &e.Failures[0])
+}
+
+// Unwrap returns the failures, for errors.Is and errors.As.
+func (e *PartialError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i := range e.Failures {
+		errs[i] = &e.Failures[i]
+	}
+	return errs
+}
+
+// GetPapersBatchAll is like GetPapersBatchContext for any number of IDs,
+// sending them in chunks of MaxBatchSize. If some chunks fail, the papers of
+// the others are still returned, with a *PartialError naming the failed
+// ranges; positions in failed chunks hold zero-valued papers. If every chunk
+// fails, the first chunk's error is returned on its own.
+func (c *Client) GetPapersBatchAll(ctx context.Context, ids []string, fields string) ([]Paper, error) {
+	papers := make([]Paper, len(ids))
+	partial := &PartialError{Op: "GetPapersBatchAll"}
+	for start := 0; start < len(ids); start += MaxBatchSize {
+		end := min(start+MaxBatchSize, len(ids))
+		chunk, err := c.GetPapersBatchContext(ctx, ids[start:end], fields)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				for s := start; s < len(ids); s += MaxBatchSize {
+					partial.Failures = append(partial.Failures, ChunkError{Start: s, End: min(s+MaxBatchSize, len(ids)), Err: ctxErr})
+				}
+				break
+			}
+			partial.Failures = append(partial.Failures, ChunkError{Start: start, End: end, Err: err})
+			continue
+		}
+		copy(papers[start:end], chunk)
+	}
+	return papers, partial.result(len(ids))
+}
+
+// result returns nil when nothing failed, the sole cause when everything over
+// total items failed, and e otherwise.
+func (e *PartialError) result(total int) error {
+	if len(e.Failures) == 0 {
+		return nil
+	}
+	failed := 0
+	for _, f := range e.Failures {
+		failed += f.End - f.Start
+	}
+	if failed >= total {
+		return e.Failures[0].Err
+	}
+	return e
+}
+
+// Collect drains a paged iterator such as BulkSearchPapersSeq. When iteration
+// fails after some items were received, they are returned with a
+// *PartialError; a failure before the first item is returned as is.
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			if len(items) == 0 {
+				return nil, err
+			}
+			return items, &PartialError{Op: "Collect", Failures: []ChunkError{{Start: len(items), Err: err}}}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,92 @@
+package semscholar_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestGetPapersBatchAllPartial checks that when one chunk fails, the papers
+// of the others are returned with a PartialError naming the failed range.
+func TestGetPapersBatchAllPartial(t *testing.T) {
+	srv := semscholartest.NewServer(nil)
+	defer srv.Close()
+	srv.Inject(semscholartest.Fault{Path: "/paper/batch", Status: http.StatusInternalServerError, Times: 1})
+	ids := make([]string, semscholar.MaxBatchSize+1)
+	for i := range ids {
+		ids[i] = semscholartest.BERTPaperID
+	}
+	ids[len(ids)-1] = semscholartest.ResNetPaperID
+	papers, err := srv.Client().GetPapersBatchAll(context.Background(), ids, "title")
+
+	var partial *semscholar.PartialError
+	if !errors.As(err, &partial) {
+		t.Fatalf("GetPapersBatchAll = %v, want a PartialError", err)
+	}
+	if len(partial.Failures) != 1 || partial.Failures[0].Start != 0 || partial.Failures[0].End != semscholar.MaxBatchSize {
+		t.Errorf("Failures = %+v, want items 0-%d", partial.Failures, semscholar.MaxBatchSize-1)
+	}
+	if !errors.Is(err, semscholar.ErrServerError) {
+		t.Errorf("errors.Is(%v, ErrServerError) = false, want true", err)
+	}
+	if len(papers) != len(ids) {
+		t.Fatalf("%d papers for %d IDs", len(papers), len(ids))
+	}
+	if p := papers[0]; p.PaperID != "" {
+		t.Errorf("papers[0] = %+v from the failed chunk, want the zero Paper", p)
+	}
+	if p := papers[len(papers)-1]; p.PaperID != semscholartest.ResNetPaperID {
+		t.Errorf("last paper = %+v, want ResNet from the second chunk", p)
+	}
+}
+
+// TestGetPapersBatchAllFailed checks that when every chunk fails, the cause
+// is returned on its own.
+func TestGetPapersBatchAllFailed(t *testing.T) {
+	srv := semscholartest.NewServer(nil)
+	defer srv.Close()
+	srv.Inject(semscholartest.Fault{Path: "/paper/batch", Status: http.StatusNotFound})
+	_, err := srv.Client().GetPapersBatchAll(context.Background(), []string{semscholartest.BERTPaperID}, "title")
+	var partial *semscholar.PartialError
+	if errors.As(err, &partial) || !errors.Is(err, semscholar.ErrNotFound) {
+		t.Errorf("GetPapersBatchAll = %v, want ErrNotFound alone", err)
+	}
+}
+
+// TestCollectPartial checks that Collect returns the items received before
+// a failure with a PartialError, and a failure before any item as is.
+func TestCollectPartial(t *testing.T) {
+	errBoom := errors.New("boom")
+	seq := func(n int) func(func(int, error) bool) {
+		return func(yield func(int, error) bool) {
+			for i := range n {
+				if !yield(i, nil) {
+					return
+				}
+			}
+			yield(0, errBoom)
+		}
+	}
+
+	items, err := semscholar.Collect(seq(3))
+	var partial *semscholar.PartialError
+	if !errors.As(err, &partial) || !errors.Is(err, errBoom) {
+		t.Fatalf("Collect = %v, want a PartialError wrapping the failure", err)
+	}
+	if !slices.Equal(items, []int{0, 1, 2}) {
+		t.Errorf("items = %v, want [0 1 2]", items)
+	}
+	if f := partial.Failures; len(f) != 1 || f[0].Start != 3 || f[0].End != 0 {
+		t.Errorf("Failures = %+v, want one after item 3", f)
+	}
+
+	items, err = semscholar.Collect(seq(0))
+	if err != errBoom || items != nil {
+		t.Errorf("Collect = %v, %v, want nil and the failure itself", items, err)
+	}
+}
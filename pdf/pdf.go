@@ -0,0 +1,427 @@
+// Package pdf downloads the open-access PDFs of papers into a local store,
+// for building full-text corpora from the copies publishers and
+// repositories offer freely. Downloads are paced per host, retried when
+// hosts are throttling or briefly down, checked to be PDFs, and stored by
+// checksum, so the same file linked from several papers is kept once.
+//
+// A store is a directory of files named by their SHA-256, under a
+// subdirectory named by its first two hex digits, and a manifest,
+// manifest.json, mapping paper IDs to them:
+//
+//	corpus/
+//		manifest.json
+//		3f/3f9a...c1.pdf
+package pdf
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// Defaults of a Store.
+const (
+	// DefaultHostRate is the most requests a second made to one host: one
+	// every two seconds, gentle on the small repositories many PDFs are
+	// hosted by.
+	DefaultHostRate = 0.5
+	// DefaultConcurrency is the number of downloads in flight, each to a
+	// different host or waiting on its host's pace.
+	DefaultConcurrency = 4
+	// DefaultMaxSize bounds the size of a PDF, in bytes.
+	DefaultMaxSize = 100 << 20
+)
+
+// ManifestName is the name of the manifest of a store.
+const ManifestName = "manifest.json"
+
+// Errors of downloads.
+var (
+	// ErrNoPDF is the error for papers with no open-access PDF URL.
+	ErrNoPDF = errors.New("pdf: no open-access PDF")
+	// ErrNotPDF is the error for URLs answering with something other than
+	// a PDF, such as the landing pages some publishers serve instead.
+	ErrNotPDF = errors.New("pdf: not a PDF")
+	// ErrTooLarge is the error for PDFs larger than Store.MaxSize.
+	ErrTooLarge = errors.New("pdf: too large")
+)
+
+// Entry is the manifest entry of a paper's PDF.
+type Entry struct {
+	PaperID string `json:"paperId"`
+	URL     string `json:"url"`
+	SHA256  string `json:"sha256"`
+	Size    int64  `json:"size"`
+	// Path is the PDF's file, relative to the store's directory.
+	Path    string    `json:"path"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// Result is the outcome of downloading the PDF of a paper.
+type Result struct {
+	PaperID string
+	Entry   Entry
+	// Cached is set if the paper's PDF was in the store already and not
+	// downloaded again, and Duplicate if it was downloaded but another
+	// paper's PDF had the same content.
+	Cached    bool
+	Duplicate bool
+	Err       error
+}
+
+// Store is a directory of PDFs, opened with Open. Its fields are set before
+// first use; its methods are safe for concurrent use.
+type Store struct {
+	Dir string
+	// HTTPClient downloads the PDFs; nil means http.DefaultClient.
+	HTTPClient semscholar.HTTPClient
+	// HostRate is the most requests a second made to one host; zero means
+	// DefaultHostRate.
+	HostRate float64
+	// Retry retries downloads failing with a network error, or a 429, 502,
+	// 503 or 504, waiting as a response's Retry-After asks when it does.
+	// The zero value disables retries.
+	Retry semscholar.RetryPolicy
+	// Concurrency is the number of downloads DownloadAll runs at once;
+	// zero means DefaultConcurrency.
+	Concurrency int
+	// MaxSize bounds the size of a PDF in bytes; zero means DefaultMaxSize.
+	MaxSize int64
+	// UserAgent, if set, is sent with each request. Hosts prefer one with
+	// a way to reach whoever runs the downloads, as in
+	// "mycorpus (mailto:me@example.org)".
+	UserAgent string
+	// Force downloads PDFs already in the store again.
+	Force bool
+	// Clock, if set, replaces the system clock.
+	Clock semscholar.Clock
+
+	mu       sync.Mutex
+	manifest map[string]Entry
+	hosts    map[string]*semscholar.RateLimiter
+}
+
+// Open returns the store in dir, creating dir if need be and reading its
+// manifest if it has one.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	s := &Store{Dir: dir, manifest: make(map[string]Entry)}
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Join(dir, ManifestName), err)
+	}
+	for _, e := range entries {
+		s.manifest[e.PaperID] = e
+	}
+	return s, nil
+}
+
+// Lookup returns the manifest entry of the paper with the paper ID id.
+func (s *Store) Lookup(id string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.manifest[id]
+	return e, ok
+}
+
+// Entries returns the entries of the manifest, in order of paper ID.
+func (s *Store) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make([]Entry, 0, len(s.manifest))
+	for _, e := range s.manifest {
+		entries = append(entries, e)
+	}
+	slices.SortFunc(entries, func(a, b Entry) int { return strings.Compare(a.PaperID, b.PaperID) })
+	return entries
+}
+
+// Save writes the manifest, replacing it atomically.
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s.Entries(), "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.Dir, ManifestName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// DownloadAll downloads the open-access PDFs of papers, which should have
+// their openAccessPdf field, and saves the manifest. It returns a result
+// for each paper, in order; the error is that of saving the manifest, or
+// ctx's if it ends first.
+func (s *Store) DownloadAll(ctx context.Context, papers []semscholar.Paper) ([]Result, error) {
+	results := make([]Result, len(papers))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(cmp.Or(s.Concurrency, DefaultConcurrency), max(len(papers), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = s.Download(ctx, papers[i])
+			}
+		}()
+	}
+	for i := range papers {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(next)
+	wg.Wait()
+	if err := s.Save(); err != nil {
+		return results, err
+	}
+	return results, ctx.Err()
+}
+
+// Download downloads the open-access PDF of p to the store and adds it to
+// the manifest, which is not saved.
+func (s *Store) Download(ctx context.Context, p semscholar.Paper) Result {
+	r := Result{PaperID: p.PaperID}
+	src, _ := p.OpenAccessPdf["url"].(string)
+	if src == "" {
+		r.Err = ErrNoPDF
+		return r
+	}
+	if e, ok := s.Lookup(p.PaperID); ok && !s.Force {
+		if _, err := os.Stat(filepath.Join(s.Dir, e.Path)); err == nil {
+			r.Entry, r.Cached = e, true
+			return r
+		}
+	}
+	tmp, err := s.fetch(ctx, src)
+	if err != nil {
+		r.Err = fmt.Errorf("%s: %w", src, err)
+		return r
+	}
+	defer os.Remove(tmp.path)
+
+	e := Entry{
+		PaperID: p.PaperID,
+		URL:     src,
+		SHA256:  tmp.sum,
+		Size:    tmp.size,
+		Path:    filepath.Join(tmp.sum[:2], tmp.sum+".pdf"),
+		Fetched: s.now(),
+	}
+	dest := filepath.Join(s.Dir, e.Path)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := os.Stat(dest); err == nil {
+		r.Duplicate = s.sharedLocked(e)
+	} else {
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			r.Err = err
+			return r
+		}
+		if err := os.Rename(tmp.path, dest); err != nil {
+			r.Err = err
+			return r
+		}
+	}
+	s.manifest[p.PaperID] = e
+	r.Entry = e
+	return r
+}
+
+// sharedLocked reports whether a paper other than e's has e's PDF.
+func (s *Store) sharedLocked(e Entry) bool {
+	for _, other := range s.manifest {
+		if other.SHA256 == e.SHA256 && other.PaperID != e.PaperID {
+			return true
+		}
+	}
+	return false
+}
+
+// download is a PDF downloaded to a temporary file of the store.
+type download struct {
+	path string
+	sum  string
+	size int64
+}
+
+// fetch downloads the PDF at src, retrying as s.Retry says.
+func (s *Store) fetch(ctx context.Context, src string) (*download, error) {
+	u, err := url.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		if err := s.host(u.Host).Wait(ctx); err != nil {
+			return nil, err
+		}
+		d, resp, err := s.fetchOnce(ctx, src)
+		if err == nil {
+			return d, nil
+		}
+		if isNetError(err) {
+			// Failing to reach the host or to read its answer is retried
+			// as a throttled response without Retry-After would be.
+			resp = nil
+		} else if resp == nil {
+			return nil, err
+		}
+		delay, ok := s.Retry.Delay(attempt+1, resp, s.now())
+		if !ok || ctx.Err() != nil {
+			return nil, err
+		}
+		if err := s.clock().Sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// statusError is a download failing with an HTTP status.
+type statusError struct{ status string }
+
+func (e *statusError) Error() string { return e.status }
+
+// fetchOnce downloads the PDF at src to a temporary file. Failures with a
+// response return it, with its body closed.
+func (s *Store) fetchOnce(ctx context.Context, src string) (*download, *http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/pdf")
+	if s.UserAgent != "" {
+		req.Header.Set("User-Agent", s.UserAgent)
+	}
+	var hc semscholar.HTTPClient = http.DefaultClient
+	if s.HTTPClient != nil {
+		hc = s.HTTPClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, nil, netError{err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp, &statusError{resp.Status}
+	}
+	if ct := resp.Header.Get("Content-Type"); !pdfContentType(ct) {
+		return nil, resp, fmt.Errorf("%w but %s", ErrNotPDF, ct)
+	}
+
+	f, err := os.CreateTemp(s.Dir, ".download-*")
+	if err != nil {
+		return nil, resp, err
+	}
+	h := sha256.New()
+	maxSize := cmp.Or(s.MaxSize, DefaultMaxSize)
+	head := make([]byte, 5)
+	n, _ := io.ReadFull(resp.Body, head)
+	if string(head[:n]) != "%PDF-" {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, resp, fmt.Errorf("%w: content does not start with %%PDF-", ErrNotPDF)
+	}
+	body := io.MultiReader(bytes.NewReader(head[:n]), io.LimitReader(resp.Body, maxSize-int64(n)+1))
+	size, err := io.Copy(io.MultiWriter(f, h), body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && size > maxSize {
+		err = ErrTooLarge
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		if errors.Is(err, ErrTooLarge) {
+			return nil, resp, err
+		}
+		return nil, resp, netError{err}
+	}
+	return &download{path: f.Name(), sum: hex.EncodeToString(h.Sum(nil)), size: size}, resp, nil
+}
+
+// pdfContentType reports whether a response of the content type ct may be
+// a PDF: one declared so, one of the generic binary types some hosts serve
+// PDFs as, or one not declared; the content is checked as well.
+func pdfContentType(ct string) bool {
+	if ct == "" {
+		return true
+	}
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	switch mt {
+	case "application/pdf", "application/x-pdf", "application/octet-stream", "binary/octet-stream", "application/download", "application/force-download":
+		return true
+	}
+	return false
+}
+
+// netError marks failures to reach a host or read its response, which are
+// retried.
+type netError struct{ error }
+
+func (e netError) Unwrap() error { return e.error }
+
+func isNetError(err error) bool {
+	return errors.As(err, new(netError))
+}
+
+// host returns the rate limiter of host.
+func (s *Store) host(host string) *semscholar.RateLimiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.hosts == nil {
+		s.hosts = make(map[string]*semscholar.RateLimiter)
+	}
+	l := s.hosts[host]
+	if l == nil {
+		l = semscholar.NewRateLimiter(cmp.Or(s.HostRate, DefaultHostRate))
+		l.Clock = s.Clock
+		s.hosts[host] = l
+	}
+	return l
+}
+
+func (s *Store) clock() semscholar.Clock {
+	if s.Clock == nil {
+		return semscholar.SystemClock
+	}
+	return s.Clock
+}
+
+func (s *Store) now() time.Time {
+	return s.clock().Now()
+}
@@ -0,0 +1,217 @@
+package pdf_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/pdf"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// host is a server of PDFs and of the things hosts serve instead, which
+// records the paths and user agents of the requests it gets.
+type host struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []string
+	agents   []string
+	served   int
+}
+
+func newHost(t *testing.T) *host {
+	h := &host{}
+	h.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.mu.Lock()
+		h.requests = append(h.requests, r.URL.Path)
+		h.agents = append(h.agents, r.UserAgent())
+		h.served++
+		served := h.served
+		h.mu.Unlock()
+		switch r.URL.Path {
+		case "/a.pdf", "/copy-of-a.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.4 paper a"))
+		case "/b.pdf":
+			w.Write([]byte("%PDF-1.4 paper b"))
+		case "/landing":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html>"))
+		case "/binary":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte("<html>"))
+		case "/large.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.4 " + strings.Repeat("x", 100)))
+		case "/flaky.pdf":
+			if served == 1 {
+				w.Header().Set("Retry-After", "2")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte("%PDF-1.4 flaky"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(h.Close)
+	return h
+}
+
+// paper returns a paper whose open-access PDF is at path on h, or that has
+// none if path is empty.
+func (h *host) paper(id, path string) semscholar.Paper {
+	p := semscholar.Paper{PaperID: id}
+	if path != "" {
+		p.OpenAccessPdf = map[string]any{"url": h.URL + path}
+	}
+	return p
+}
+
+func (h *host) paths() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Clone(h.requests)
+}
+
+// TestDownloadAll checks the outcome of each kind of download, the files
+// and manifest stored, and that PDFs already stored are not downloaded
+// again unless forced.
+func TestDownloadAll(t *testing.T) {
+	h := newHost(t)
+	dir := t.TempDir()
+	s, err := pdf.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Clock = semscholartest.NewFakeClock(time.Time{})
+	s.Concurrency = 1
+	s.MaxSize = 50
+	s.UserAgent = "corpus (mailto:me@example.org)"
+	papers := []semscholar.Paper{
+		h.paper("a", "/a.pdf"),
+		h.paper("a2", "/copy-of-a.pdf"),
+		h.paper("b", "/b.pdf"),
+		h.paper("closed", ""),
+		h.paper("landing", "/landing"),
+		h.paper("binary", "/binary"),
+		h.paper("large", "/large.pdf"),
+		h.paper("gone", "/gone.pdf"),
+	}
+	results, err := s.DownloadAll(context.Background(), papers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []error{nil, nil, nil, pdf.ErrNoPDF, pdf.ErrNotPDF, pdf.ErrNotPDF, pdf.ErrTooLarge, nil} {
+		r := results[i]
+		if r.PaperID != papers[i].PaperID {
+			t.Errorf("result %d is of %s, want %s", i, r.PaperID, papers[i].PaperID)
+		}
+		if want != nil && !errors.Is(r.Err, want) {
+			t.Errorf("%s: %v, want %v", r.PaperID, r.Err, want)
+		}
+	}
+	if results[7].Err == nil {
+		t.Error("gone: downloaded a missing PDF")
+	}
+	for _, r := range results[:3] {
+		if r.Err != nil {
+			t.Fatalf("%s: %v", r.PaperID, r.Err)
+		}
+	}
+	a, a2, b := results[0], results[1], results[2]
+	if a.Duplicate || !a2.Duplicate || b.Duplicate || a.Entry.Path != a2.Entry.Path || a.Entry.Path == b.Entry.Path {
+		t.Errorf("a %+v, a2 %+v, b %+v: want a2 a duplicate of a", a, a2, b)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, a.Entry.Path))
+	if err != nil || string(data) != "%PDF-1.4 paper a" || a.Entry.Size != int64(len(data)) {
+		t.Errorf("stored %q, %v, entry %+v", data, err, a.Entry)
+	}
+	if a.Entry.Path != filepath.Join(a.Entry.SHA256[:2], a.Entry.SHA256+".pdf") {
+		t.Errorf("stored at %s, not by checksum", a.Entry.Path)
+	}
+	for _, agent := range h.agents {
+		if agent != s.UserAgent {
+			t.Errorf("request with user agent %q", agent)
+		}
+	}
+	temps, _ := filepath.Glob(filepath.Join(dir, ".download-*"))
+	if len(temps) != 0 {
+		t.Errorf("temporary files left: %v", temps)
+	}
+
+	reopened, err := pdf.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(reopened.Entries()); got != 3 {
+		t.Errorf("manifest has %d entries, want 3", got)
+	}
+	if e, ok := reopened.Lookup("a2"); !ok || e.SHA256 != a.Entry.SHA256 {
+		t.Errorf("Lookup(a2) = %+v, %v", e, ok)
+	}
+	reopened.Clock = s.Clock
+	before := len(h.paths())
+	if r := reopened.Download(context.Background(), papers[0]); !r.Cached || r.Err != nil {
+		t.Errorf("download of a stored PDF = %+v, want cached", r)
+	}
+	reopened.Force = true
+	if r := reopened.Download(context.Background(), papers[0]); r.Cached || r.Err != nil {
+		t.Errorf("forced download = %+v, want downloaded", r)
+	}
+	if got := len(h.paths()) - before; got != 1 {
+		t.Errorf("%d requests for a stored PDF and a forced download, want 1", got)
+	}
+}
+
+// TestDownloadPaced checks that downloads from one host wait on its rate,
+// and that a retry waits as the host's Retry-After asks.
+func TestDownloadPaced(t *testing.T) {
+	h := newHost(t)
+	s, err := pdf.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := semscholartest.NewFakeClock(time.Time{})
+	s.Clock = clock
+	for _, id := range []string{"a", "b"} {
+		if r := s.Download(context.Background(), h.paper(id, "/"+id+".pdf")); r.Err != nil {
+			t.Fatal(r.Err)
+		}
+	}
+	if got, want := clock.Sleeps(), []time.Duration{2 * time.Second}; !slices.Equal(got, want) {
+		t.Errorf("slept %v at the default rate, want %v", got, want)
+	}
+
+	for _, retry := range []semscholar.RetryPolicy{{}, {MaxRetries: 1}} {
+		h = newHost(t)
+		s, err = pdf.Open(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		clock = semscholartest.NewFakeClock(time.Time{})
+		s.Clock, s.HostRate, s.Retry = clock, 1000, retry
+		r := s.Download(context.Background(), h.paper("flaky", "/flaky.pdf"))
+		if retry.MaxRetries == 0 {
+			if r.Err == nil {
+				t.Error("downloaded through a 503 without retries")
+			}
+			continue
+		}
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		if got, want := clock.Sleeps(), []time.Duration{2 * time.Second}; !slices.Equal(got, want) {
+			t.Errorf("slept %v retrying, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package semscholar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// primeWorkers is the number of batch requests Prime keeps in flight. Requests
+// still pass through the client's HTTPClient, so a rate-limited client (see
+// NewRateLimitedClient) paces them.
+const primeWorkers = 4
+
+// ErrNoCache is returned by Prime when the client has no Cache to warm.
+var ErrNoCache = errors.New("semscholar: client has no cache")
+
+// Prime fetches paperIDs with the given fields purely to warm c.Cache ahead of
+// an interactive session, such as before rendering a reading list. IDs are
+// fetched in batches of MaxBatchSize, a few at a time, and each resolved paper
+// is also stored under the key GetPaper would use for the same ID and fields,
+// so later single-paper lookups are served from the cache. Unresolved IDs are
+// skipped. A failed batch does not stop the others: if only some fail, a
+// *PartialError names the ranges that were not primed.
+func (c *Client) Prime(ctx context.Context, paperIDs []string, fields string) error {
+	if c.Cache == nil {
+		return ErrNoCache
+	}
+	ttl := c.CacheTTLs.ttlFor("/paper/batch")
+	if ttl < 0 {
+		return nil
+	}
+	chunks := make(chan int)
+	var (
+		mu      sync.Mutex
+		partial = &PartialError{Op: "Prime"}
+		wg      sync.WaitGroup
+	)
+	for range min(primeWorkers, (len(paperIDs)+MaxBatchSize-1)/MaxBatchSize) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range chunks {
+				end := min(start+MaxBatchSize, len(paperIDs))
+				if err := c.primeChunk(ctx, paperIDs[start:end], fields, ttl); err != nil {
+					mu.Lock()
+					partial.Failures = append(partial.Failures, ChunkError{Start: start, End: end, Err: err})
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+send:
+	for start := 0; start < len(paperIDs); start += MaxBatchSize {
+		select {
+		case chunks <- start:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(chunks)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	sort.Slice(partial.Failures, func(i, j int) bool { return partial.Failures[i].Start < partial.Failures[j].Start })
+	return partial.result(len(paperIDs))
+}
+
+func (c *Client) primeChunk(ctx context.Context, ids []string, fields string, ttl time.Duration) error {
+	papers, err := c.GetPapersBatchContext(ctx, ids, fields)
+	if err != nil {
+		return err
+	}
+	for i, p := range papers {
+		if i >= len(ids) || p.PaperID == "" {
+			continue
+		}
+		req, err := c.newPaperRequest(ctx, ids[i], fields)
+		if err != nil {
+			return err
+		}
+		key, err := CacheKey(req)
+		if err != nil {
+			return err
+		}
+		body, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		c.storeEntry(ctx, key, &cacheEntry{body: body}, ttl)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package semscholar_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestPrime checks that Prime fetches papers in one batch and caches each
+// under the key GetPaper uses, so later lookups are not sent.
+func TestPrime(t *testing.T) {
+	srv := semscholartest.NewServer(nil)
+	defer srv.Close()
+	c := srv.Client()
+	c.Cache = semscholar.NewLRUCache(100)
+	ids := []string{semscholartest.BERTPaperID, semscholartest.ResNetPaperID, "missing"}
+	if err := c.Prime(context.Background(), ids, "title,year"); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("Prime sent %d requests, want 1", n)
+	}
+	for _, id := range ids[:2] {
+		p, err := c.GetPaper(id, "title,year")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.PaperID != id || p.Title == "" {
+			t.Errorf("GetPaper(%s) = %+v from the cache", id, p)
+		}
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("%d requests after looking up the primed papers, want 1", n)
+	}
+	if _, err := c.GetPaper(semscholartest.BERTPaperID, "title"); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(srv.Requests()); n != 2 {
+		t.Errorf("%d requests after a lookup with other fields, want 2", n)
+	}
+}
+
+// TestPrimeNoCache checks that Prime fails without a cache to warm.
+func TestPrimeNoCache(t *testing.T) {
+	srv := semscholartest.NewServer(nil)
+	defer srv.Close()
+	if err := srv.Client().Prime(context.Background(), []string{"a"}, ""); !errors.Is(err, semscholar.ErrNoCache) {
+		t.Errorf("Prime = %v, want ErrNoCache", err)
+	}
+}
@@ -0,0 +1,18 @@
+package semscholar_test
+
+import (
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestRequestEncoding checks that every request builder round-trips the IDs,
+// queries, fields and filters of generated argument sets through url.Parse.
+// A failure names the arguments; rerun with the same seed to reproduce it.
+func TestRequestEncoding(t *testing.T) {
+	n := 1000
+	if testing.Short() {
+		n = 100
+	}
+	semscholartest.TestRequestEncoding(t, 1, n)
+}
@@ -0,0 +1,69 @@
+package semscholar
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter spaces calls so that no more than a fixed number happen per second.
+// It is safe for concurrent use and can be shared between clients and helpers.
+type RateLimiter struct {
+	// Clock, if set, replaces the system clock. Set it before the limiter is
+	// shared.
+	Clock Clock
+
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// NewRateLimiter creates a RateLimiter allowing rps calls per second.
+// The unauthenticated Semantic Scholar limit is 1; API keys typically allow more.
+func NewRateLimiter(rps float64) *RateLimiter {
+	return &RateLimiter{interval: time.Duration(float64(time.Second) / rps)}
+}
+
+// Wait blocks until the next call is allowed or ctx is done.
+// A nil RateLimiter never blocks.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	clock := clockOr(l.Clock)
+	l.mu.Lock()
+	now := clock.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := at.Sub(now)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	return clock.Sleep(ctx, delay)
+}
+
+// rateLimitedClient is an HTTPClient that waits on a RateLimiter before each request.
+type rateLimitedClient struct {
+	next    HTTPClient
+	limiter *RateLimiter
+}
+
+// NewRateLimitedClient wraps next so every request first waits on limiter.
+// Passing the result to NewClient throttles all endpoints of that client.
+func NewRateLimitedClient(next HTTPClient, limiter *RateLimiter) HTTPClient {
+	return &rateLimitedClient{next: next, limiter: limiter}
+}
+
+// Do implements HTTPClient.
+func (c *rateLimitedClient) Do(req *http.Request) (*http.Response, error) {
+	if err := c.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return c.next.Do(req)
+}
@@ -0,0 +1,120 @@
+// Package rediscache provides a semscholar.Cache backed by Redis, so several
+// processes or hosts can share cached Semantic Scholar responses.
+//
+// Entries are stored as plain Redis strings under the key returned by
+// semscholar.CacheKey, optionally preceded by a namespace prefix:
+//
+//	[<prefix>]semscholar:<METHOD>:<host><path>?<sorted query>[#<sha256 of body>]
+//
+// Expiry is delegated to Redis via the TTL on SET. DeletePrefix scans with
+// SCAN MATCH, on every master in a cluster, so invalidation works without a
+// secondary index.
+package rediscache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Cache is a semscholar.Cache stored in Redis.
+type Cache struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// New creates a Cache using client. Keys are stored with prefix prepended, which
+// lets several environments share one Redis database; it may be empty.
+func New(client redis.UniversalClient, prefix string) *Cache {
+	return &Cache{client: client, prefix: prefix}
+}
+
+// Get implements semscholar.Cache.
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set implements semscholar.Cache.
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, c.prefix+key, value, ttl).Err()
+}
+
+// Delete implements semscholar.Cache.
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, c.prefix+key).Err()
+}
+
+// DeletePrefix implements semscholar.PrefixDeleter.
+func (c *Cache) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	pattern := globEscaper.Replace(c.prefix+prefix) + "*"
+	cluster, ok := c.client.(*redis.ClusterClient)
+	if !ok {
+		return deleteMatching(ctx, c.client, pattern)
+	}
+	var (
+		mu    sync.Mutex
+		total int
+	)
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		n, err := deleteMatching(ctx, node, pattern)
+		mu.Lock()
+		total += n
+		mu.Unlock()
+		return err
+	})
+	return total, err
+}
+
+// scanBatch is the SCAN COUNT hint and the number of deletes per pipeline.
+const scanBatch = 500
+
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+// deleteMatching deletes the keys matching pattern on a single node. Keys are
+// deleted one per command so that no command spans cluster slots.
+func deleteMatching(ctx context.Context, client redis.Cmdable, pattern string) (int, error) {
+	total := 0
+	flush := func(keys []string) error {
+		if len(keys) == 0 {
+			return nil
+		}
+		cmds, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.Del(ctx, key)
+			}
+			return nil
+		})
+		for _, cmd := range cmds {
+			if n, err := cmd.(*redis.IntCmd).Result(); err == nil {
+				total += int(n)
+			}
+		}
+		return err
+	}
+	var keys []string
+	iter := client.Scan(ctx, 0, pattern, scanBatch).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == scanBatch {
+			if err := flush(keys); err != nil {
+				return total, err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return total, err
+	}
+	return total, flush(keys)
+}
@@ -0,0 +1,78 @@
+package rediscache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jmwalsh91/semscholar-go/rediscache"
+	"github.com/redis/go-redis/v9"
+)
+
+// newCache returns a Cache with prefix over a fresh in-memory Redis server.
+func newCache(t *testing.T, prefix string) (*rediscache.Cache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return rediscache.New(client, prefix), mr
+}
+
+// TestCache checks that values are stored under the prefixed key, expire
+// after their TTL and can be deleted.
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newCache(t, "test:")
+	if _, ok, err := c.Get(ctx, "k"); ok || err != nil {
+		t.Fatalf("Get of a missing key = %v, %v; want a miss", ok, err)
+	}
+	if err := c.Set(ctx, "k", []byte("v"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := mr.Get("test:k"); err != nil || got != "v" {
+		t.Errorf("stored %q, %v under test:k, want v", got, err)
+	}
+	if v, ok, err := c.Get(ctx, "k"); !ok || err != nil || string(v) != "v" {
+		t.Errorf("Get = %q, %v, %v; want v", v, ok, err)
+	}
+	mr.FastForward(time.Minute + time.Second)
+	if _, ok, _ := c.Get(ctx, "k"); ok {
+		t.Error("entry found after its TTL")
+	}
+
+	c.Set(ctx, "k", []byte("v"), 0)
+	if err := c.Delete(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := c.Get(ctx, "k"); ok {
+		t.Error("entry found after Delete")
+	}
+	if err := c.Delete(ctx, "k"); err != nil {
+		t.Errorf("Delete of a missing key = %v", err)
+	}
+}
+
+// TestDeletePrefix checks that DeletePrefix removes the keys of its own
+// namespace beginning with the prefix, taking glob characters in it
+// literally.
+func TestDeletePrefix(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newCache(t, "a:")
+	for _, key := range []string{"paper/1?fields=*", "paper/1?fields=title", "paper/10", "author/1"} {
+		c.Set(ctx, key, []byte("v"), 0)
+	}
+	mr.Set("b:paper/1?fields=*", "v")
+
+	n, err := c.DeletePrefix(ctx, "paper/1?fields=*")
+	if err != nil || n != 1 {
+		t.Errorf("DeletePrefix(paper/1?fields=*) = %d, %v; want 1", n, err)
+	}
+	n, err = c.DeletePrefix(ctx, "paper/")
+	if err != nil || n != 2 {
+		t.Errorf("DeletePrefix(paper/) = %d, %v; want 2", n, err)
+	}
+	if keys := mr.Keys(); len(keys) != 2 || keys[0] != "a:author/1" || keys[1] != "b:paper/1?fields=*" {
+		t.Errorf("left keys %v, want a:author/1 and b:paper/1?fields=*", keys)
+	}
+}
@@ -0,0 +1,238 @@
+package semscholar
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Reference is one entry of a parsed reference list, e.g. the output of a PDF
+// extraction tool. Any field may be empty; Raw is used as the query when Title is.
+type Reference struct {
+	Raw     string
+	Title   string
+	Authors []string
+	Year    int
+	DOI     string
+	ArXivID string
+}
+
+// Resolution methods reported on a ResolvedReference.
+const (
+	ResolvedByDOI    = "doi"
+	ResolvedByArXiv  = "arxiv"
+	ResolvedByMatch  = "match"
+	ResolvedBySearch = "search"
+)
+
+// ResolvedReference is the outcome of matching a single Reference.
+// Paper is nil when the reference could not be resolved, in which case Err says why.
+type ResolvedReference struct {
+	Reference Reference
+	Paper     *Paper
+	// Score is the fuzzy match score; it is 1 for identifier lookups.
+	Score  float64
+	Method string
+	Err    error
+}
+
+// ResolutionStats summarizes how the entries of a reference list were resolved.
+type ResolutionStats struct {
+	Total      int
+	ByDOI      int
+	ByArXiv    int
+	ByMatch    int
+	BySearch   int
+	Unresolved int
+	Failed     int
+}
+
+// ResolutionRate returns the fraction of entries that were resolved.
+func (s ResolutionStats) ResolutionRate() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Total-s.Unresolved-s.Failed) / float64(s.Total)
+}
+
+// Bibliography is a reference list with every entry matched against Semantic Scholar.
+// Entries are in the same order as the input.
+type Bibliography struct {
+	Entries []ResolvedReference
+	Stats   ResolutionStats
+}
+
+// ReferenceMatcher resolves whole reference lists. Entries carrying a DOI or arXiv ID
+// are looked up in batches; the rest go through title match search and, failing
+// that, fuzzy scoring of relevance search results. All matches are then hydrated
+// with a batch call so every Paper carries the requested fields.
+type ReferenceMatcher struct {
+	Client *Client
+	// Threshold is the minimum fuzzy score for title matches; zero means DefaultMatchThreshold.
+	Threshold float64
+	// Fields is requested when hydrating the matched papers.
+	Fields string
+}
+
+// NewReferenceMatcher creates a ReferenceMatcher with default settings.
+func NewReferenceMatcher(c *Client, fields string) *ReferenceMatcher {
+	return &ReferenceMatcher{Client: c, Fields: fields}
+}
+
+// Match resolves refs. Per-entry problems are recorded on the entries; an
+// entry whose identifier lookup failed carries that error and is counted as
+// Failed, not matched by title. The returned error is only non-nil if ctx
+// ends before every entry was attempted.
+func (m *ReferenceMatcher) Match(ctx context.Context, refs []Reference) (*Bibliography, error) {
+	threshold := m.Threshold
+	if threshold == 0 {
+		threshold = DefaultMatchThreshold
+	}
+	entries := make([]ResolvedReference, len(refs))
+	matched := make([]string, len(refs))
+
+	// Identifier lookups.
+	var idx []int
+	var ids []string
+	for i, ref := range refs {
+		entries[i].Reference = ref
+		if doi, err := NormalizeDOI(ref.DOI); err == nil {
+			idx = append(idx, i)
+			ids = append(ids, "DOI:"+doi)
+			entries[i].Method = ResolvedByDOI
+		} else if arxiv := normalizeArXivID(ref.ArXivID); arxiv != "" {
+			idx = append(idx, i)
+			ids = append(ids, "ARXIV:"+arxiv)
+			entries[i].Method = ResolvedByArXiv
+		}
+	}
+	papers, errs, err := m.batch(ctx, ids, "paperId")
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range idx {
+		if errs[j] != nil {
+			entries[i].Err = errs[j]
+		} else if papers[j].PaperID != "" {
+			matched[i] = papers[j].PaperID
+			entries[i].Score = 1
+		} else {
+			entries[i].Method = ""
+		}
+	}
+
+	// Title matching for everything still unresolved whose lookup did not fail.
+	resolver := &TitleResolver{Client: m.Client, Threshold: threshold}
+	for i, ref := range refs {
+		if matched[i] != "" || entries[i].Err != nil {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		q := TitleQuery{Title: ref.Title, Year: ref.Year}
+		if q.Title == "" {
+			q.Title = ref.Raw
+		}
+		if len(ref.Authors) > 0 {
+			q.FirstAuthor = ref.Authors[0]
+		}
+		if strings.TrimSpace(q.Title) == "" {
+			entries[i].Err = ErrNotFound
+			continue
+		}
+		result, err := m.Client.MatchSearchPapersContext(ctx, q.Title, "title,year,publicationDate,authors", "", nil)
+		if err == nil && len(result.Data) > 0 {
+			if score := MatchScore(q, result.Data[0]); score >= threshold {
+				matched[i] = result.Data[0].PaperID
+				entries[i].Score = score
+				entries[i].Method = ResolvedByMatch
+				continue
+			}
+		} else if err != nil && !errors.Is(err, ErrNotFound) {
+			entries[i].Err = err
+			continue
+		}
+		best, err := resolver.Resolve(ctx, q)
+		if err != nil {
+			entries[i].Err = err
+			continue
+		}
+		matched[i] = best.Paper.PaperID
+		entries[i].Score = best.Score
+		entries[i].Method = ResolvedBySearch
+	}
+
+	// Hydration.
+	idx, ids = idx[:0], ids[:0]
+	for i, id := range matched {
+		if id != "" {
+			idx = append(idx, i)
+			ids = append(ids, id)
+		}
+	}
+	papers, errs, err = m.batch(ctx, ids, m.Fields)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range idx {
+		if errs[j] != nil {
+			entries[i].Err = errs[j]
+			continue
+		}
+		if papers[j].PaperID == "" {
+			entries[i].Err = ErrNotFound
+			continue
+		}
+		p := papers[j]
+		entries[i].Paper = &p
+	}
+
+	bib := &Bibliography{Entries: entries}
+	bib.Stats.Total = len(entries)
+	for i := range entries {
+		e := &entries[i]
+		switch {
+		case e.Paper == nil && e.Err != nil && !errors.Is(e.Err, ErrNotFound):
+			e.Method = ""
+			bib.Stats.Failed++
+		case e.Paper == nil:
+			e.Method = ""
+			if e.Err == nil {
+				e.Err = ErrNotFound
+			}
+			bib.Stats.Unresolved++
+		case e.Method == ResolvedByDOI:
+			bib.Stats.ByDOI++
+		case e.Method == ResolvedByArXiv:
+			bib.Stats.ByArXiv++
+		case e.Method == ResolvedByMatch:
+			bib.Stats.ByMatch++
+		case e.Method == ResolvedBySearch:
+			bib.Stats.BySearch++
+		}
+	}
+	return bib, nil
+}
+
+// batch fetches ids in chunks of MaxBatchSize. Entries of a failed chunk are left
+// zero-valued and carry the chunk's error; only a context error aborts.
+func (m *ReferenceMatcher) batch(ctx context.Context, ids []string, fields string) ([]Paper, []error, error) {
+	out := make([]Paper, len(ids))
+	errs := make([]error, len(ids))
+	for start := 0; start < len(ids); start += MaxBatchSize {
+		end := min(start+MaxBatchSize, len(ids))
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		papers, err := m.Client.GetPapersBatchContext(ctx, ids[start:end], fields)
+		if err != nil {
+			for i := start; i < end; i++ {
+				errs[i] = err
+			}
+			continue
+		}
+		copy(out[start:end], papers)
+	}
+	return out, errs, nil
+}
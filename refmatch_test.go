@@ -0,0 +1,39 @@
+package semscholar_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// TestMatchFailedLookup checks that a reference whose DOI lookup fails is
+// counted as failed with the lookup's error, not as unresolved by a title
+// search finding nothing.
+func TestMatchFailedLookup(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/paper/batch"):
+			http.Error(w, `{"message":"unavailable"}`, http.StatusInternalServerError)
+		case strings.HasSuffix(r.URL.Path, "/match"):
+			http.Error(w, `{"error":"Title match not found"}`, http.StatusNotFound)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"total":0,"data":[]}`))
+		}
+	}))
+	defer srv.Close()
+	m := semscholar.NewReferenceMatcher(semscholar.NewClient(srv.URL, srv.Client()), "title")
+	bib, err := m.Match(context.Background(), []semscholar.Reference{{DOI: "10.1000/xyz", Title: "A Paper"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := bib.Entries[0]
+	if bib.Stats.Failed != 1 || e.Err == nil || errors.Is(e.Err, semscholar.ErrNotFound) {
+		t.Errorf("stats %+v, entry error %v; want one failed entry with the lookup's error", bib.Stats, e.Err)
+	}
+}
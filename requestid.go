@@ -0,0 +1,51 @@
+package semscholar
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader is the header carrying the ID the client gives each call.
+const RequestIDHeader = "X-Request-Id"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying id, which calls made with it
+// send instead of a generated ID. Use it to carry an ID assigned further up,
+// such as that of the incoming request a service is handling.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx. The context of
+// every request the client passes to its HTTPClient carries one, so logging or
+// tracing middleware can record it alongside the request.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// withRequestID tags req with the ID from its context, or a new one, in the
+// RequestIDHeader header and the context. One call keeps one ID across its
+// retries, so they can be told apart from a new call in server logs.
+func withRequestID(req *http.Request) *http.Request {
+	if req.Header.Get(RequestIDHeader) != "" {
+		return req
+	}
+	id := RequestIDFromContext(req.Context())
+	if id == "" {
+		id = newRequestID()
+		req = req.WithContext(WithRequestID(req.Context(), id))
+	}
+	req.Header.Set(RequestIDHeader, id)
+	return req
+}
+
+// newRequestID returns 16 random bytes in hex.
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,87 @@
+package semscholar_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestRequestID checks that each call sends a new request ID, kept across
+// its retries and reported in its APIError, and that an ID carried by the
+// context is sent instead.
+func TestRequestID(t *testing.T) {
+	var mu sync.Mutex
+	var ids []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ids = append(ids, r.Header.Get(semscholar.RequestIDHeader))
+		mu.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	client := semscholar.NewClient(srv.URL, srv.Client())
+	client.Clock = semscholartest.NewFakeClock(time.Time{})
+	client.Retry = semscholar.RetryPolicy{MaxRetries: 1}
+
+	_, err1 := client.GetPaper("a", "")
+	_, err2 := client.GetPaper("a", "")
+	_, err3 := client.GetPaperContext(semscholar.WithRequestID(context.Background(), "incoming-42"), "a", "")
+
+	if len(ids) != 6 {
+		t.Fatalf("%d requests, want 3 calls of 2 attempts", len(ids))
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{32}$`).MatchString(ids[0]) {
+		t.Errorf("request ID %q, want 32 hex digits", ids[0])
+	}
+	if ids[0] != ids[1] || ids[2] != ids[3] {
+		t.Errorf("IDs %q, want each call's retry to keep its ID", ids)
+	}
+	if ids[0] == ids[2] {
+		t.Errorf("IDs %q, want a new ID for each call", ids)
+	}
+	if ids[4] != "incoming-42" || ids[5] != "incoming-42" {
+		t.Errorf("IDs %q, want the context's ID sent", ids[4:])
+	}
+	for i, err := range []error{err1, err2, err3} {
+		var apiErr *semscholar.APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("call %d = %v, want an APIError", i, err)
+		}
+		if want := ids[2*i]; apiErr.RequestID != want || !strings.Contains(err.Error(), "(request "+want+")") {
+			t.Errorf("call %d failed with %v, want request ID %s", i, err, want)
+		}
+	}
+}
+
+// TestRequestIDInContext checks that the request passed to the HTTPClient
+// carries its ID in its context as well as its header.
+func TestRequestIDInContext(t *testing.T) {
+	srv := semscholartest.NewServer(nil)
+	defer srv.Close()
+	var header, fromCtx string
+	hc := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		header = req.Header.Get(semscholar.RequestIDHeader)
+		fromCtx = semscholar.RequestIDFromContext(req.Context())
+		return http.DefaultClient.Do(req)
+	})
+	if _, err := semscholar.NewClient(srv.URL, hc).GetPaper(semscholartest.BERTPaperID, "title"); err != nil {
+		t.Fatal(err)
+	}
+	if header == "" || header != fromCtx {
+		t.Errorf("header %q and context %q, want the same ID", header, fromCtx)
+	}
+}
+
+// httpClientFunc adapts a function to semscholar.HTTPClient.
+type httpClientFunc func(*http.Request) (*http.Response, error)
+
+func (f httpClientFunc) Do(req *http.Request) (*http.Response, error) { return f(req) }
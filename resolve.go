@@ -0,0 +1,116 @@
+package semscholar
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MaxBatchSize is the largest number of IDs the batch endpoints accept in a single request.
+const MaxBatchSize = 500
+
+// ErrMalformedDOI is reported for inputs that do not look like a DOI.
+var ErrMalformedDOI = errors.New("semscholar: malformed DOI")
+
+var doiPattern = regexp.MustCompile(`^10\.\d{4,9}/\S+$`)
+
+// NormalizeDOI strips common URL and "doi:" prefixes and surrounding whitespace from doi.
+// It returns ErrMalformedDOI if what remains is not a syntactically valid DOI.
+func NormalizeDOI(doi string) (string, error) {
+	d := strings.TrimSpace(doi)
+	lower := strings.ToLower(d)
+	for _, prefix := range []string{"https://doi.org/", "http://doi.org/", "https://dx.doi.org/", "http://dx.doi.org/", "doi.org/", "doi:"} {
+		if strings.HasPrefix(lower, prefix) {
+			d = strings.TrimSpace(d[len(prefix):])
+			break
+		}
+	}
+	if !doiPattern.MatchString(d) {
+		return "", ErrMalformedDOI
+	}
+	return d, nil
+}
+
+// ResolveDOIs looks up many DOIs at once using the paper batch endpoint, splitting
+// the input into chunks of MaxBatchSize. Both returned maps are keyed by the DOI
+// exactly as it appeared in dois. Every input ends up in exactly one of them:
+// resolved papers in the first, and in the second ErrMalformedDOI, ErrNotFound,
+// or the error that failed its chunk (which matches ErrRateLimited on a 429).
+// With Validate set, a paper breaking an invariant gets a *ValidationError of
+// its own, and the rest of its chunk is resolved as usual.
+func (c *Client) ResolveDOIs(ctx context.Context, dois []string, fields string) (map[string]Paper, map[string]error) {
+	papers := make(map[string]Paper)
+	errs := make(map[string]error)
+
+	var inputs, ids []string
+	for _, doi := range dois {
+		normalized, err := NormalizeDOI(doi)
+		if err != nil {
+			errs[doi] = err
+			continue
+		}
+		inputs = append(inputs, doi)
+		ids = append(ids, "DOI:"+normalized)
+	}
+
+	for start := 0; start < len(ids); start += MaxBatchSize {
+		end := start + MaxBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		if err := ctx.Err(); err != nil {
+			for _, doi := range inputs[start:] {
+				errs[doi] = err
+			}
+			break
+		}
+		results, err := c.GetPapersBatchContext(ctx, ids[start:end], fields)
+		var invalid map[int]*ValidationError
+		var vErr *ValidationError
+		if errors.As(err, &vErr) && results != nil {
+			if invalid = splitValidation(vErr); invalid != nil {
+				err = nil
+			}
+		}
+		if err != nil {
+			for _, doi := range inputs[start:end] {
+				errs[doi] = err
+			}
+			continue
+		}
+		for i, doi := range inputs[start:end] {
+			if vErr := invalid[i]; vErr != nil {
+				errs[doi] = vErr
+				continue
+			}
+			if i >= len(results) || results[i].PaperID == "" {
+				errs[doi] = ErrNotFound
+				continue
+			}
+			papers[doi] = results[i]
+		}
+	}
+	return papers, errs
+}
+
+// splitValidation splits the diagnostics of a batch's ValidationError by the
+// index of the entry each concerns, with paths relative to the entry. It
+// returns nil if a diagnostic concerns the batch as a whole.
+func splitValidation(err *ValidationError) map[int]*ValidationError {
+	byEntry := make(map[int]*ValidationError)
+	for _, d := range err.Diagnostics {
+		rest, found := strings.CutPrefix(d.Path, "[")
+		index, path, ok := strings.Cut(rest, "].")
+		i, convErr := strconv.Atoi(index)
+		if !found || !ok || convErr != nil {
+			return nil
+		}
+		if byEntry[i] == nil {
+			byEntry[i] = &ValidationError{Op: err.Op}
+		}
+		byEntry[i].Diagnostics = append(byEntry[i].Diagnostics, Diagnostic{Path: path, Problem: d.Problem})
+	}
+	return byEntry
+}
@@ -0,0 +1,101 @@
+package semscholar_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+const (
+	bertDOI   = "10.18653/v1/N19-1423"
+	resnetDOI = "10.1109/CVPR.2016.90"
+)
+
+// TestResolveDOIs checks that every DOI ends up in exactly one of the maps
+// ResolveDOIs returns, keyed as given: resolved papers, ErrMalformedDOI and
+// ErrNotFound.
+func TestResolveDOIs(t *testing.T) {
+	srv := semscholartest.NewServer(nil)
+	defer srv.Close()
+	dois := []string{"https://doi.org/" + bertDOI, "doi:" + resnetDOI, "not a doi", "10.1000/missing"}
+	papers, errs := srv.Client().ResolveDOIs(context.Background(), dois, "title")
+	if p := papers[dois[0]]; p.PaperID != semscholartest.BERTPaperID {
+		t.Errorf("papers[%s] = %+v, want BERT", dois[0], p)
+	}
+	if p := papers[dois[1]]; p.PaperID != semscholartest.ResNetPaperID {
+		t.Errorf("papers[%s] = %+v, want ResNet", dois[1], p)
+	}
+	if err := errs[dois[2]]; !errors.Is(err, semscholar.ErrMalformedDOI) {
+		t.Errorf("errs[%s] = %v, want ErrMalformedDOI", dois[2], err)
+	}
+	if err := errs[dois[3]]; !errors.Is(err, semscholar.ErrNotFound) {
+		t.Errorf("errs[%s] = %v, want ErrNotFound", dois[3], err)
+	}
+	if len(papers)+len(errs) != len(dois) {
+		t.Errorf("%d papers and %d errors for %d DOIs", len(papers), len(errs), len(dois))
+	}
+}
+
+// TestResolveDOIsChunks checks that DOIs are looked up in chunks of
+// MaxBatchSize, and that a chunk failing with a 429 fails only its own DOIs.
+func TestResolveDOIsChunks(t *testing.T) {
+	srv := semscholartest.NewServer(nil)
+	defer srv.Close()
+	srv.Inject(semscholartest.Fault{Path: "/paper/batch", Status: http.StatusTooManyRequests, Times: 1})
+	dois := make([]string, semscholar.MaxBatchSize+1)
+	for i := range dois {
+		dois[i] = fmt.Sprintf("10.1000/%d", i)
+	}
+	dois[len(dois)-1] = bertDOI
+	papers, errs := srv.Client().ResolveDOIs(context.Background(), dois, "title")
+
+	var batches int
+	for _, r := range srv.Requests() {
+		if strings.HasPrefix(r, "POST /paper/batch") {
+			batches++
+		}
+	}
+	if batches != 2 {
+		t.Errorf("%d batch requests, want 2", batches)
+	}
+	for _, doi := range dois[:semscholar.MaxBatchSize] {
+		if err := errs[doi]; !errors.Is(err, semscholar.ErrRateLimited) {
+			t.Fatalf("errs[%s] = %v, want ErrRateLimited", doi, err)
+		}
+	}
+	if p := papers[bertDOI]; p.PaperID != semscholartest.BERTPaperID {
+		t.Errorf("papers[%s] = %+v, want BERT from the second chunk", bertDOI, p)
+	}
+}
+
+// TestResolveDOIsValidate checks that with Validate set, a paper breaking an
+// invariant gets a ValidationError of its own while the rest of its chunk is
+// resolved.
+func TestResolveDOIsValidate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"paperId":"a","title":"Good"},{"title":"No ID"},null]`))
+	}))
+	defer srv.Close()
+	c := semscholar.NewClient(srv.URL, srv.Client())
+	c.Validate = true
+	dois := []string{"10.1000/a", "10.1000/b", "10.1000/c"}
+	papers, errs := c.ResolveDOIs(context.Background(), dois, "title")
+	if p := papers[dois[0]]; p.PaperID != "a" {
+		t.Errorf("papers[%s] = %+v, want paper a", dois[0], p)
+	}
+	var vErr *semscholar.ValidationError
+	if err := errs[dois[1]]; !errors.As(err, &vErr) || vErr.Diagnostics[0].Path != "paperId" {
+		t.Errorf("errs[%s] = %v, want a ValidationError for its paperId", dois[1], err)
+	}
+	if err := errs[dois[2]]; !errors.Is(err, semscholar.ErrNotFound) {
+		t.Errorf("errs[%s] = %v, want ErrNotFound", dois[2], err)
+	}
+}
@@ -0,0 +1,53 @@
+package semscholar
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// maxErrorBody bounds how much of an unsuccessful response is kept in
+// APIError.Body.
+const maxErrorBody = 4 << 10
+
+// maxDrain bounds how much of an unread body is discarded before it is closed.
+// Reading the rest of a body lets the transport reuse the connection; past
+// this size, dropping the connection is cheaper than reading on.
+const maxDrain = 64 << 10
+
+// closeBody discards what is left of body, up to maxDrain bytes, and closes
+// it, so the connection goes back to the pool even when the response was an
+// error or was not read to the end.
+func closeBody(body io.ReadCloser) {
+	io.Copy(io.Discard, io.LimitReader(body, maxDrain))
+	body.Close()
+}
+
+type opKey struct{}
+
+// withOp returns req with the name of the method sending it in its context,
+// for layers below the method, such as the retry loop, that act on it.
+func withOp(req *http.Request, op string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), opKey{}, op))
+}
+
+// opFromContext returns the method name stored by withOp, or "".
+func opFromContext(ctx context.Context) string {
+	op, _ := ctx.Value(opKey{}).(string)
+	return op
+}
+
+// doJSON sends req and decodes a 200 response into v. Any other status is
+// returned as an APIError for op. The body is drained and closed in every case.
+// Errors are reported to the OnError hook.
+func (c *Client) doJSON(op string, req *http.Request, v any) error {
+	resp, err := c.do(withOp(req, op))
+	if err != nil {
+		return c.report(op, err)
+	}
+	defer closeBody(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return c.report(op, c.newAPIError(op, resp))
+	}
+	return c.report(op, c.decode(resp.Body, v))
+}
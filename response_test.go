@@ -0,0 +1,48 @@
+package semscholar_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// TestErrorBodyDrained checks that error responses have their bodies read
+// and closed, so every call reuses the one connection, including when the
+// body is longer than the part kept in APIError.Body and the response was
+// being streamed.
+func TestErrorBodyDrained(t *testing.T) {
+	var conns atomic.Int32
+	body := strings.Repeat("x", 60<<10)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(body))
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	client := semscholar.NewClient(srv.URL, srv.Client())
+	for range 5 {
+		if _, err := client.GetPaper("a", ""); err == nil {
+			t.Fatal("GetPaper succeeded against a 404")
+		}
+		for _, err := range client.GetPapersBatchSeq(context.Background(), []string{"a"}, "") {
+			if err == nil {
+				t.Fatal("GetPapersBatchSeq succeeded against a 404")
+			}
+		}
+	}
+	if n := conns.Load(); n != 1 {
+		t.Errorf("%d connections for 10 failed calls, half of them streamed, want 1", n)
+	}
+}
@@ -0,0 +1,15 @@
+module github.com/jmwalsh91/semscholar-go/retriever/langchaingo
+
+go 1.24
+
+require (
+	github.com/jmwalsh91/semscholar-go v0.0.0-20261016203010-5e6cd84158f3
+	github.com/tmc/langchaingo v0.1.13
+)
+
+require (
+	github.com/dlclark/regexp2 v1.10.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/pkoukk/tiktoken-go v0.1.6 // indirect
+	golang.org/x/sync v0.10.0 // indirect
+)
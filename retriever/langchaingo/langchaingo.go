@@ -0,0 +1,43 @@
+// Package langchaingo plugs the Semantic Scholar retriever into langchaingo,
+// as a schema.Retriever for its retrieval chains and agents.
+//
+// It is a module of its own, so that only programs importing it depend on
+// langchaingo:
+//
+//	r := langchaingo.New(&retriever.Retriever{Client: client, K: 8})
+//	chain := chains.NewRetrievalQAFromLLM(llm, r)
+package langchaingo
+
+import (
+	"context"
+
+	"github.com/jmwalsh91/semscholar-go/retriever"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// Retriever is a schema.Retriever of the documents its retriever.Retriever
+// finds.
+type Retriever struct {
+	R *retriever.Retriever
+}
+
+var _ schema.Retriever = Retriever{}
+
+// New returns a schema.Retriever of the documents r finds.
+func New(r *retriever.Retriever) Retriever {
+	return Retriever{R: r}
+}
+
+// GetRelevantDocuments returns the documents relevant to query, most
+// relevant first, as retriever.Retriever.GetRelevantDocuments does.
+func (r Retriever) GetRelevantDocuments(ctx context.Context, query string) ([]schema.Document, error) {
+	docs, err := r.R.GetRelevantDocuments(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]schema.Document, len(docs))
+	for i, d := range docs {
+		out[i] = schema.Document{PageContent: d.PageContent, Metadata: d.Metadata, Score: d.Score}
+	}
+	return out, nil
+}
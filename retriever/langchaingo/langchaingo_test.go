@@ -0,0 +1,41 @@
+package langchaingo_test
+
+import (
+	"context"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/retriever"
+	"github.com/jmwalsh91/semscholar-go/retriever/langchaingo"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+	"github.com/tmc/langchaingo/schema"
+)
+
+// TestGetRelevantDocuments checks that the adapter returns the documents of
+// the retriever it wraps, content, metadata and scores alike.
+func TestGetRelevantDocuments(t *testing.T) {
+	d := semscholartest.NewDataset()
+	d.AddPaper(semscholar.Paper{PaperID: "a", Title: "Graph attention networks", Abstract: "Attention over graphs."})
+	d.AddPaper(semscholar.Paper{PaperID: "b", Title: "Graph convolutional networks"})
+	srv := semscholartest.NewServer(d)
+	defer srv.Close()
+
+	r := &retriever.Retriever{Client: srv.Client(), K: 2}
+	want, err := r.GetRelevantDocuments(context.Background(), "graph")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lr schema.Retriever = langchaingo.New(r)
+	got, err := lr.GetRelevantDocuments(context.Background(), "graph")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || len(got) != len(want) {
+		t.Fatalf("got %d documents, want 2 as the retriever returns %d", len(got), len(want))
+	}
+	for i, doc := range got {
+		if doc.PageContent != want[i].PageContent || doc.Score != want[i].Score || doc.Metadata["paperId"] != want[i].Metadata["paperId"] {
+			t.Errorf("document %d = %+v, want %+v", i, doc, want[i])
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Package retriever serves Semantic Scholar as a knowledge source for
+// retrieval-augmented generation: a query returns documents, each text
+// with metadata, in the shape LLM frameworks such as langchaingo expect.
+//
+// Retriever has the GetRelevantDocuments method of langchaingo's
+// schema.Retriever, with Document mirroring schema.Document. The
+// retriever/langchaingo module adapts it to schema.Retriever itself; it is
+// a module of its own so that this one does not depend on langchaingo.
+package retriever
+
+import (
+	"cmp"
+	"context"
+	"strconv"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// Mode is what a Retriever searches.
+type Mode int
+
+const (
+	// Papers searches papers by relevance, making a document of the title,
+	// TLDR and abstract of each.
+	Papers Mode = iota
+	// Snippets searches passages of the text of papers, making a document
+	// of each passage.
+	Snippets
+)
+
+// DefaultK is the number of documents a Retriever returns without K.
+const DefaultK = 5
+
+// paperFields are the fields of the papers documents are made from.
+const paperFields = "title,abstract,tldr,venue,year,publicationDate,citationCount,authors,url,externalIds,corpusId"
+
+// Document is a text retrieved for a query, with metadata about where it
+// comes from. It mirrors langchaingo's schema.Document.
+type Document struct {
+	PageContent string
+	Metadata    map[string]any
+	Score       float32
+}
+
+// Retriever finds the documents relevant to a query.
+type Retriever struct {
+	Client *semscholar.Client
+	Mode   Mode
+	// K is the number of documents returned; zero means DefaultK.
+	K int
+	// Filters are the search filters of the queries, such as
+	// {"year": "2020-", "fieldsOfStudy": "Computer Science"}.
+	Filters map[string]string
+	// MinScore, if set, drops snippets scored below it. Paper search has no
+	// scores; its documents are scored by rank, from 1 down.
+	MinScore float64
+}
+
+// GetRelevantDocuments returns the documents relevant to query, most
+// relevant first. Papers with neither abstract nor TLDR make documents of
+// their titles alone.
+func (r *Retriever) GetRelevantDocuments(ctx context.Context, query string) ([]Document, error) {
+	k := cmp.Or(r.K, DefaultK)
+	if r.Mode == Snippets {
+		resp, err := r.Client.SnippetSearch(ctx, query, k, r.Filters)
+		if err != nil {
+			return nil, err
+		}
+		var docs []Document
+		for _, m := range resp.Data {
+			if m.Score < r.MinScore {
+				continue
+			}
+			docs = append(docs, snippetDocument(m))
+		}
+		return docs, nil
+	}
+	resp, err := r.Client.SearchPapersContext(ctx, query, 0, k, paperFields, r.Filters)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]Document, len(resp.Data))
+	for i, p := range resp.Data {
+		docs[i] = PaperDocument(p)
+		docs[i].Score = 1 - float32(i)/float32(len(resp.Data))
+	}
+	return docs, nil
+}
+
+// PaperDocument makes a document of the title, TLDR and abstract of p, with
+// its identifiers and bibliographic details as metadata.
+func PaperDocument(p semscholar.Paper) Document {
+	var b strings.Builder
+	b.WriteString(p.Title)
+	if p.Tldr != nil && p.Tldr.Text != "" {
+		b.WriteString("\n\nTLDR: " + p.Tldr.Text)
+	}
+	if p.Abstract != "" {
+		b.WriteString("\n\n" + p.Abstract)
+	}
+	meta := map[string]any{
+		"source":        "semanticscholar",
+		"paperId":       p.PaperID,
+		"title":         p.Title,
+		"citationCount": p.CitationCount.Int,
+	}
+	if p.URL != "" {
+		meta["url"] = p.URL
+	}
+	if p.Venue != "" {
+		meta["venue"] = p.Venue
+	}
+	if y := p.PublishedYear(); y != 0 {
+		meta["year"] = y
+	}
+	if len(p.Authors) > 0 {
+		meta["authors"] = authorNames(p.Authors)
+	}
+	if p.ExternalIDs.DOI != "" {
+		meta["doi"] = p.ExternalIDs.DOI
+	}
+	if p.CorpusID != 0 {
+		meta["corpusId"] = strconv.Itoa(p.CorpusID)
+	}
+	return Document{PageContent: b.String(), Metadata: meta}
+}
+
+func snippetDocument(m semscholar.SnippetMatch) Document {
+	meta := map[string]any{
+		"source":      "semanticscholar",
+		"corpusId":    m.Paper.CorpusID,
+		"title":       m.Paper.Title,
+		"snippetKind": m.Snippet.SnippetKind,
+	}
+	if m.Paper.CorpusID != "" {
+		meta["url"] = "https://www.semanticscholar.org/p/" + m.Paper.CorpusID
+	}
+	if m.Snippet.Section != "" {
+		meta["section"] = m.Snippet.Section
+	}
+	if len(m.Paper.Authors) > 0 {
+		meta["authors"] = m.Paper.Authors
+	}
+	return Document{PageContent: m.Snippet.Text, Metadata: meta, Score: float32(m.Score)}
+}
+
+func authorNames(authors []semscholar.Author) []string {
+	names := make([]string, len(authors))
+	for i, a := range authors {
+		names[i] = a.Name
+	}
+	return names
+}
@@ -0,0 +1,134 @@
+package retriever_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/retriever"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// gat is a paper with every field documents are made from.
+var gat = semscholar.Paper{
+	PaperID:       "gat",
+	CorpusID:      3292002,
+	Title:         "Graph attention networks",
+	Abstract:      "Graphs are everywhere. Attention helps.",
+	Tldr:          &semscholar.Tldr{Text: "Attention over graph neighbourhoods."},
+	Venue:         "ICLR",
+	Year:          2018,
+	CitationCount: semscholar.NewNullInt(9000),
+	URL:           "https://www.semanticscholar.org/paper/gat",
+	Authors:       []semscholar.Author{{Name: "P. Veličković"}, {Name: "G. Cucurull"}},
+}
+
+func corpus() *semscholartest.Dataset {
+	d := semscholartest.NewDataset()
+	p := gat
+	p.ExternalIDs.DOI = "10.48550/arXiv.1710.10903"
+	d.AddPaper(p)
+	d.AddPaper(semscholar.Paper{PaperID: "gcn", CorpusID: 1, Title: "Graph convolutional networks", CitationCount: semscholar.NewNullInt(100)})
+	return d
+}
+
+// TestGetRelevantDocumentsPapers checks that paper search makes a document of
+// each paper, scored by rank, and returns K of them.
+func TestGetRelevantDocumentsPapers(t *testing.T) {
+	srv := semscholartest.NewServer(corpus())
+	defer srv.Close()
+	r := &retriever.Retriever{Client: srv.Client(), Mode: retriever.Papers}
+	docs, err := r.GetRelevantDocuments(context.Background(), "graph networks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("got %d documents, want 2", len(docs))
+	}
+	wantContent := "Graph attention networks\n\nTLDR: Attention over graph neighbourhoods.\n\nGraphs are everywhere. Attention helps."
+	if docs[0].PageContent != wantContent {
+		t.Errorf("first document = %q, want %q", docs[0].PageContent, wantContent)
+	}
+	if docs[0].Score != 1 || docs[1].Score != 0.5 {
+		t.Errorf("scores = %v, %v, want 1 and 0.5 by rank", docs[0].Score, docs[1].Score)
+	}
+
+	r.K = 1
+	docs, err = r.GetRelevantDocuments(context.Background(), "graph networks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].Metadata["paperId"] != "gat" {
+		t.Errorf("with K 1 got %d documents, want only gat", len(docs))
+	}
+}
+
+// TestGetRelevantDocumentsSnippets checks that snippet search makes a
+// document of each passage, keeps the search's scores and drops those below
+// MinScore.
+func TestGetRelevantDocumentsSnippets(t *testing.T) {
+	srv := semscholartest.NewServer(corpus())
+	defer srv.Close()
+	r := &retriever.Retriever{Client: srv.Client(), Mode: retriever.Snippets, K: 10}
+	docs, err := r.GetRelevantDocuments(context.Background(), "graph attention")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 4 {
+		t.Fatalf("got %d documents, want the 4 passages mentioning graph or attention", len(docs))
+	}
+
+	r.MinScore = 0.6
+	docs, err = r.GetRelevantDocuments(context.Background(), "graph attention")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 {
+		t.Fatalf("with MinScore 0.6 got %d documents, want 1", len(docs))
+	}
+	want := retriever.Document{
+		PageContent: "Graph attention networks",
+		Metadata: map[string]any{
+			"source":      "semanticscholar",
+			"corpusId":    "3292002",
+			"title":       "Graph attention networks",
+			"snippetKind": "title",
+			"url":         "https://www.semanticscholar.org/p/3292002",
+			"authors":     []string{"P. Veličković", "G. Cucurull"},
+		},
+		Score: 1,
+	}
+	if !reflect.DeepEqual(docs[0], want) {
+		t.Errorf("document = %+v, want %+v", docs[0], want)
+	}
+}
+
+// TestPaperDocument checks the metadata of a paper's document, and that
+// fields the paper lacks are left out of it.
+func TestPaperDocument(t *testing.T) {
+	p := gat
+	p.ExternalIDs.DOI = "10.48550/arXiv.1710.10903"
+	got := retriever.PaperDocument(p).Metadata
+	want := map[string]any{
+		"source":        "semanticscholar",
+		"paperId":       "gat",
+		"title":         "Graph attention networks",
+		"citationCount": 9000,
+		"url":           "https://www.semanticscholar.org/paper/gat",
+		"venue":         "ICLR",
+		"year":          2018,
+		"authors":       []string{"P. Veličković", "G. Cucurull"},
+		"doi":           "10.48550/arXiv.1710.10903",
+		"corpusId":      "3292002",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("metadata = %v, want %v", got, want)
+	}
+
+	doc := retriever.PaperDocument(semscholar.Paper{PaperID: "x", Title: "Untitled"})
+	want = map[string]any{"source": "semanticscholar", "paperId": "x", "title": "Untitled", "citationCount": 0}
+	if doc.PageContent != "Untitled" || !reflect.DeepEqual(doc.Metadata, want) {
+		t.Errorf("bare paper document = %+v, want its title and %v", doc, want)
+	}
+}
@@ -0,0 +1,149 @@
+package semscholar
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how the client retries throttled and temporarily
+// unavailable responses (429, 502, 503 and 504). The zero value disables retries.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// BaseDelay is the first backoff when the API gives no Retry-After; it
+	// doubles on each retry, with jitter, and defaults to 1s.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff and defaults to 30s. A Retry-After longer than
+	// MaxDelay is not waited out: the response is returned at once, and its
+	// APIError carries the requested delay for the caller to act on.
+	MaxDelay time.Duration
+	// RetryPOST decides, by method name such as "GetPapersBatch", whether the
+	// POST requests of a method are retried. Every POST the API offers, the
+	// batch lookups and recommendations, is a read that is safe to repeat,
+	// so when RetryPOST is nil they are retried like GETs. Users wary of
+	// repeating a request the API may already have counted can opt out:
+	//
+	//	policy.RetryPOST = func(op string) bool { return false }
+	RetryPOST func(op string) bool
+}
+
+// retries reports whether req may be retried under p.
+func (p RetryPolicy) retries(req *http.Request) bool {
+	if req.Method != http.MethodPost || p.RetryPOST == nil {
+		return true
+	}
+	return p.RetryPOST(opFromContext(req.Context()))
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.BaseDelay == 0 {
+		p.BaseDelay = time.Second
+	}
+	if p.MaxDelay == 0 {
+		p.MaxDelay = 30 * time.Second
+	}
+	return p
+}
+
+// retryable reports whether a response with this status is worth retrying.
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// send passes req to the HTTP client, retrying retryable responses according
+// to c.Retry. When the API sends Retry-After, exactly that long is waited;
+// otherwise the delay backs off exponentially. A wait that would outlast the
+// context's deadline is not started, and the last response is returned for the
+// caller to turn into an APIError, as it is once retries are exhausted.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	policy := c.Retry.withDefaults()
+	clock := clockOr(c.Clock)
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+		resp, err := c.sendOnce(req)
+		if err == nil && resp.StatusCode == http.StatusTooManyRequests && c.Hooks.OnRateLimited != nil {
+			c.Hooks.OnRateLimited(req, retryAfter(resp, clock.Now()))
+		}
+		if err != nil || !policy.retries(req) {
+			return resp, err
+		}
+		delay, ok := policy.Delay(attempt+1, resp, clock.Now())
+		if !ok {
+			return resp, nil
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			// The retry could not complete in time; report the response now
+			// rather than sleeping into the deadline.
+			return resp, nil
+		}
+		closeBody(resp.Body)
+		if c.Hooks.OnRetry != nil {
+			c.Hooks.OnRetry(req, attempt+1, resp.StatusCode, delay)
+		}
+		if err := clock.Sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Delay returns how long to wait before retry n, 1 for the first, of a
+// request answered with resp, and whether to retry it at all: not if the
+// status of resp is not retried, n is past MaxRetries or the response asks
+// for a wait longer than MaxDelay. The wait is that of Retry-After, if the
+// response sends it, or else a backoff doubling from BaseDelay with jitter.
+// A nil resp stands for a failure without a response, such as a reset
+// connection, which callers retrying those wait out like a throttled
+// response.
+func (p RetryPolicy) Delay(n int, resp *http.Response, now time.Time) (time.Duration, bool) {
+	p = p.withDefaults()
+	if n > p.MaxRetries || resp != nil && !retryable(resp.StatusCode) {
+		return 0, false
+	}
+	if resp != nil {
+		if delay := retryAfter(resp, now); delay > p.MaxDelay {
+			return 0, false
+		} else if delay > 0 {
+			return delay, true
+		}
+	}
+	backoff := p.BaseDelay
+	for i := 1; i < n && backoff < p.MaxDelay; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, p.MaxDelay)
+	return backoff/2 + rand.N(backoff/2+1), true
+}
+
+// retryAfter parses the Retry-After header of resp, given either in seconds or
+// as an HTTP date, which is measured from now. It returns zero when the header
+// is absent or malformed.
+func retryAfter(resp *http.Response, now time.Time) time.Duration {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(0, at.Sub(now))
+	}
+	return 0
+}
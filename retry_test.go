@@ -0,0 +1,102 @@
+package semscholar_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestRetryPolicyDelay checks which responses Delay retries, that it waits
+// as Retry-After asks, and that its backoff doubles within MaxDelay.
+func TestRetryPolicyDelay(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	respond := func(status int, retryAfter string) *http.Response {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return resp
+	}
+	p := semscholar.RetryPolicy{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	for _, tt := range []struct {
+		name string
+		n    int
+		resp *http.Response
+		ok   bool
+		min  time.Duration
+		max  time.Duration
+	}{
+		{"throttled", 1, respond(http.StatusTooManyRequests, ""), true, 500 * time.Millisecond, time.Second},
+		{"second retry", 2, respond(http.StatusServiceUnavailable, ""), true, time.Second, 2 * time.Second},
+		{"no response", 3, nil, true, 2 * time.Second, 4 * time.Second},
+		{"retry-after", 1, respond(http.StatusTooManyRequests, "3"), true, 3 * time.Second, 3 * time.Second},
+		{"retry-after date", 1, respond(http.StatusTooManyRequests, now.Add(5*time.Second).Format(http.TimeFormat)), true, 5 * time.Second, 5 * time.Second},
+		{"retry-after too long", 1, respond(http.StatusTooManyRequests, "60"), false, 0, 0},
+		{"past max retries", 4, respond(http.StatusTooManyRequests, ""), false, 0, 0},
+		{"not retried", 1, respond(http.StatusNotFound, ""), false, 0, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, ok := p.Delay(tt.n, tt.resp, now)
+			if ok != tt.ok || delay < tt.min || delay > tt.max {
+				t.Errorf("Delay = %v, %v; want %v between %v and %v", delay, ok, tt.ok, tt.min, tt.max)
+			}
+		})
+	}
+
+	long := semscholar.RetryPolicy{MaxRetries: 20}
+	if delay, ok := long.Delay(20, nil, now); !ok || delay > 30*time.Second {
+		t.Errorf("twentieth retry waits %v, %v; want at most the default MaxDelay of 30s", delay, ok)
+	}
+}
+
+// TestRetryPOST checks that POST requests are retried like GETs unless
+// RetryPOST declines their method, and that GETs are retried regardless.
+func TestRetryPOST(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		retryPOST func(op string) bool
+		posts     int
+	}{
+		{"default", nil, 2},
+		{"allowed", func(op string) bool { return op == "GetPapersBatch" }, 2},
+		{"declined", func(op string) bool { return false }, 1},
+		{"declined for another method", func(op string) bool { return op != "GetRecommendations" }, 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := semscholartest.NewServer(nil)
+			defer srv.Close()
+			client := srv.Client()
+			client.Clock = semscholartest.NewFakeClock(time.Time{})
+			client.Retry = semscholar.RetryPolicy{MaxRetries: 1, RetryPOST: tt.retryPOST}
+
+			srv.Inject(semscholartest.Fault{Path: "/paper/batch", Status: http.StatusServiceUnavailable, Times: 1})
+			papers, err := client.GetPapersBatchContext(context.Background(), []string{semscholartest.BERTPaperID}, "title")
+			if retried := tt.posts > 1; retried != (err == nil) {
+				t.Errorf("GetPapersBatch = %v, %v, want it retried: %v", papers, err, retried)
+			}
+			srv.Inject(semscholartest.Fault{Path: "/paper/" + semscholartest.BERTPaperID, Status: http.StatusServiceUnavailable, Times: 1})
+			if _, err := client.GetPaper(semscholartest.BERTPaperID, "title"); err != nil {
+				t.Errorf("GetPaper = %v, want it retried", err)
+			}
+
+			var posts, gets int
+			for _, r := range srv.Requests() {
+				switch {
+				case strings.HasPrefix(r, "POST /paper/batch"):
+					posts++
+				case strings.HasPrefix(r, "GET /paper/"):
+					gets++
+				}
+			}
+			if posts != tt.posts || gets != 2 {
+				t.Errorf("%d POSTs and %d GETs, want %d and 2", posts, gets, tt.posts)
+			}
+		})
+	}
+}
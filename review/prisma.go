@@ -0,0 +1,124 @@
+package review
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// Actions of the events of a review's log.
+const (
+	// ActionQuery is a query run, with the number of records it found.
+	ActionQuery = "query"
+	// ActionMerge is a set of records merged as duplicates into one paper,
+	// with the reason the canonical record was chosen.
+	ActionMerge = "merge"
+	// ActionInclude and ActionExclude are the screening decisions on a
+	// paper, the latter with its reason.
+	ActionInclude = "include"
+	ActionExclude = "exclude"
+	// ActionCluster is a cluster formed, with the number of its papers.
+	ActionCluster = "cluster"
+)
+
+// Event is an entry in the log of a review, recording a step in the
+// derivation of its candidate pool, for the documentation a systematic
+// review needs.
+type Event struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	Query  string    `json:"query,omitempty"`
+	// PaperID is the paper decided on: for a merge, the canonical record.
+	PaperID string `json:"paperId,omitempty"`
+	Title   string `json:"title,omitempty"`
+	// Merged are the paper IDs of the records merged into the paper,
+	// itself included, as often as they were found.
+	Merged []string `json:"merged,omitempty"`
+	Count  int      `json:"count,omitempty"`
+	Reason string   `json:"reason,omitempty"`
+}
+
+// WriteLog writes the events of the log to w as JSON Lines.
+func (p *Pool) WriteLog(w io.Writer) error {
+	jw := semscholar.NewJSONLWriter(w, false)
+	for _, e := range p.Log {
+		if err := jw.Write(e); err != nil {
+			return err
+		}
+	}
+	return jw.Close()
+}
+
+// Flow is the summary of a review in the stages of a PRISMA flow diagram:
+// records identified, duplicates removed, records screened and excluded,
+// with the reasons, and papers included.
+type Flow struct {
+	Identified int          `json:"identified"`
+	Queries    []QueryCount `json:"queries"`
+	// Duplicates is the number of records removed as duplicates.
+	Duplicates int           `json:"duplicates"`
+	Screened   int           `json:"screened"`
+	Excluded   int           `json:"excluded"`
+	Reasons    []ReasonCount `json:"reasons,omitempty"`
+	Included   int           `json:"included"`
+}
+
+// ReasonCount is the number of papers excluded for a reason.
+type ReasonCount struct {
+	Reason string `json:"reason"`
+	Papers int    `json:"papers"`
+}
+
+// Flow returns the PRISMA flow of the pool, reasons for exclusion most
+// common first.
+func (p *Pool) Flow() Flow {
+	f := Flow{
+		Identified: p.Found,
+		Queries:    p.Queries,
+		Duplicates: p.Found - p.Unique,
+		Screened:   p.Unique,
+		Excluded:   len(p.Excluded),
+		Included:   len(p.Included),
+	}
+	reasons := p.Reasons()
+	for _, r := range slices.Sorted(maps.Keys(reasons)) {
+		f.Reasons = append(f.Reasons, ReasonCount{r, reasons[r]})
+	}
+	slices.SortStableFunc(f.Reasons, func(a, b ReasonCount) int { return cmp.Compare(b.Papers, a.Papers) })
+	return f
+}
+
+// WriteMarkdown writes the flow to w as Markdown, one section per stage,
+// ready for the methods section of a review.
+func (f Flow) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("## Identification\n\n")
+	fmt.Fprintf(&b, "Records identified from Semantic Scholar (n = %d)\n\n", f.Identified)
+	for _, q := range f.Queries {
+		truncated := ""
+		if q.Truncated {
+			truncated = ", truncated"
+		}
+		fmt.Fprintf(&b, "- %q (n = %d%s)\n", q.Query, q.Papers, truncated)
+	}
+	fmt.Fprintf(&b, "\nDuplicate records removed (n = %d)\n\n", f.Duplicates)
+	b.WriteString("## Screening\n\n")
+	fmt.Fprintf(&b, "Records screened (n = %d)\n\n", f.Screened)
+	fmt.Fprintf(&b, "Records excluded (n = %d)\n", f.Excluded)
+	if len(f.Reasons) > 0 {
+		b.WriteString("\n")
+		for _, r := range f.Reasons {
+			fmt.Fprintf(&b, "- %s (n = %d)\n", r.Reason, r.Papers)
+		}
+	}
+	b.WriteString("\n## Included\n\n")
+	fmt.Fprintf(&b, "Studies included (n = %d)\n", f.Included)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
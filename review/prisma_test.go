@@ -0,0 +1,98 @@
+package review_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/review"
+)
+
+// TestFlow checks the stages of the flow of a review, reasons for exclusion
+// most common first, and its Markdown.
+func TestFlow(t *testing.T) {
+	r := newReview(corpus(t))
+	r.MaxPerQuery = 4
+	r.Exclude = append(r.Exclude, review.Mentions("biology"))
+	pool, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := pool.Flow()
+	if f.Identified != 7 || f.Duplicates != 3 || f.Screened != 4 || f.Excluded != 3 || f.Included != 1 {
+		t.Errorf("flow %+v", f)
+	}
+	want := []review.ReasonCount{{Reason: "mentions biology", Papers: 1}, {Reason: "not has an abstract", Papers: 1}, {Reason: "not published in or after 2015", Papers: 1}}
+	if !slices.Equal(f.Reasons, want) {
+		t.Errorf("reasons %+v, want %+v", f.Reasons, want)
+	}
+
+	var b strings.Builder
+	if err := f.WriteMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+	wantMarkdown := `## Identification
+
+Records identified from Semantic Scholar (n = 7)
+
+- "graph" (n = 3)
+- "neural" (n = 4, truncated)
+
+Duplicate records removed (n = 3)
+
+## Screening
+
+Records screened (n = 4)
+
+Records excluded (n = 3)
+
+- mentions biology (n = 1)
+- not has an abstract (n = 1)
+- not published in or after 2015 (n = 1)
+
+## Included
+
+Studies included (n = 1)
+`
+	if got := b.String(); got != wantMarkdown {
+		t.Errorf("Markdown:\n%s\nwant:\n%s", got, wantMarkdown)
+	}
+}
+
+// TestFlowEmpty checks the Markdown of a flow with nothing excluded, which
+// lists no reasons.
+func TestFlowEmpty(t *testing.T) {
+	var b strings.Builder
+	if err := (review.Flow{}).WriteMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "Records excluded (n = 0)\n\n## Included") {
+		t.Errorf("Markdown:\n%s", b.String())
+	}
+}
+
+// TestWriteLog checks that the log is written one event per line, in order.
+func TestWriteLog(t *testing.T) {
+	pool, err := newReview(corpus(t)).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := pool.WriteLog(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(pool.Log) {
+		t.Fatalf("%d lines for %d events", len(lines), len(pool.Log))
+	}
+	var e review.Event
+	if err := json.Unmarshal([]byte(lines[0]), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Action != review.ActionQuery || e.Query != "graph" || e.Count != 3 || !e.Time.Equal(start) {
+		t.Errorf("first event %+v, want the query for graph", e)
+	}
+}
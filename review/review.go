@@ -0,0 +1,364 @@
+// Package review runs the search and screening stages of a literature
+// review: several searches, merged and deduplicated, screened by inclusion
+// and exclusion criteria, and the papers kept grouped into clusters. Each
+// step is logged, and summarized as a PRISMA flow.
+package review
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/cluster"
+)
+
+// DefaultFields are the paper fields a Review fetches without Fields.
+const DefaultFields = "title,abstract,venue,year,publicationDate,citationCount,influentialCitationCount,externalIds,authors,fieldsOfStudy,isOpenAccess"
+
+// Review is a literature review to run. Each of Queries is bulk-searched
+// with Filters, such as {"year": "2018-"}, and the results are merged and
+// deduplicated with semscholar.DedupeGroups. Papers meeting every
+// criterion of Include and none of Exclude are kept, and clustered.
+type Review struct {
+	Client  *semscholar.Client
+	Queries []string
+	Filters map[string]string
+	// Fields are the paper fields fetched; empty means DefaultFields. The
+	// criteria and the Clusterer can only look at the fields fetched.
+	Fields string
+	// MaxPerQuery, if positive, bounds the papers taken from each query.
+	MaxPerQuery int
+	Include     []Criterion
+	Exclude     []Criterion
+	// Cluster groups the papers kept; nil means ByField.
+	Cluster Clusterer
+	// Summarize, if set, digests the papers kept, as Pool.Summary, and each
+	// cluster, as Cluster.Summary.
+	Summarize semscholar.Summarizer
+	// Clock, if set, replaces the system clock in the times of the log.
+	Clock semscholar.Clock
+}
+
+// Criterion is a named test of a paper for screening.
+type Criterion struct {
+	Name  string
+	Match func(semscholar.Paper) bool
+}
+
+// Candidate is a paper found by a review.
+type Candidate struct {
+	Paper semscholar.Paper `json:"paper"`
+	// Queries are the queries that found the paper or one of its
+	// duplicates.
+	Queries []string `json:"queries"`
+	// Duplicates is the number of records merged into the paper.
+	Duplicates int `json:"duplicates,omitempty"`
+}
+
+// Exclusion is a paper a review left out, and the criterion why: the name
+// of an exclusion criterion it met, or "not " and the name of an inclusion
+// criterion it did not.
+type Exclusion struct {
+	Candidate
+	Reason string `json:"reason"`
+}
+
+// QueryCount is the number of papers a query found.
+type QueryCount struct {
+	Query  string `json:"query"`
+	Papers int    `json:"papers"`
+	// Truncated is set if the query found more than MaxPerQuery papers.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Pool is the outcome of a review: the candidate pool for its later,
+// manual stages, with the counts a review reports.
+type Pool struct {
+	Queries []QueryCount `json:"queries"`
+	// Found is the number of records the queries found, and Unique the
+	// number of papers left after deduplication.
+	Found    int         `json:"found"`
+	Unique   int         `json:"unique"`
+	Included []Candidate `json:"included"`
+	Excluded []Exclusion `json:"excluded"`
+	Clusters []Cluster   `json:"clusters"`
+	// Summary is the digest of the included papers by Review.Summarize.
+	Summary string `json:"summary,omitempty"`
+	// Log records every query, merge of duplicates, screening decision and
+	// cluster, in order; Flow summarizes it.
+	Log []Event `json:"log"`
+}
+
+// Run searches, deduplicates, screens, clusters and, with Summarize,
+// summarizes. A failed query or summary fails the review.
+func (r *Review) Run(ctx context.Context) (*Pool, error) {
+	if len(r.Queries) == 0 {
+		return nil, fmt.Errorf("review: no queries")
+	}
+	fields := cmp.Or(r.Fields, DefaultFields)
+	pool := &Pool{}
+	clock := r.Clock
+	if clock == nil {
+		clock = semscholar.SystemClock
+	}
+	record := func(e Event) {
+		e.Time = clock.Now()
+		pool.Log = append(pool.Log, e)
+	}
+	var records []semscholar.Paper
+	found := make(map[string][]string) // paper ID to the queries finding it
+	for _, q := range r.Queries {
+		qc := QueryCount{Query: q}
+		for p, err := range r.Client.BulkSearchPapersSeq(ctx, q, fields, "", "", r.Filters) {
+			if err != nil {
+				return nil, fmt.Errorf("review: query %q: %w", q, err)
+			}
+			if r.MaxPerQuery > 0 && qc.Papers == r.MaxPerQuery {
+				qc.Truncated = true
+				break
+			}
+			qc.Papers++
+			records = append(records, p)
+			if !slices.Contains(found[p.PaperID], q) {
+				found[p.PaperID] = append(found[p.PaperID], q)
+			}
+		}
+		pool.Queries = append(pool.Queries, qc)
+		e := Event{Action: ActionQuery, Query: q, Count: qc.Papers}
+		if qc.Truncated {
+			e.Reason = fmt.Sprintf("truncated at %d", r.MaxPerQuery)
+		}
+		record(e)
+	}
+	pool.Found = len(records)
+
+	groups := semscholar.DedupeGroups(records)
+	pool.Unique = len(groups)
+	var kept []semscholar.Paper
+	for _, g := range groups {
+		c := Candidate{Paper: g.Canonical, Duplicates: len(g.Members) - 1}
+		if len(g.Members) > 1 {
+			e := Event{Action: ActionMerge, PaperID: c.Paper.PaperID, Title: c.Paper.Title, Count: len(g.Members), Reason: g.Choice.Reason}
+			for _, m := range g.Members {
+				e.Merged = append(e.Merged, m.PaperID)
+			}
+			record(e)
+		}
+		for _, m := range g.Members {
+			for _, q := range found[m.PaperID] {
+				if !slices.Contains(c.Queries, q) {
+					c.Queries = append(c.Queries, q)
+				}
+			}
+		}
+		if reason, ok := r.screen(c.Paper); !ok {
+			pool.Excluded = append(pool.Excluded, Exclusion{Candidate: c, Reason: reason})
+			record(Event{Action: ActionExclude, PaperID: c.Paper.PaperID, Title: c.Paper.Title, Reason: reason})
+			continue
+		}
+		pool.Included = append(pool.Included, c)
+		record(Event{Action: ActionInclude, PaperID: c.Paper.PaperID, Title: c.Paper.Title})
+		kept = append(kept, c.Paper)
+	}
+
+	clusterer := r.Cluster
+	if clusterer == nil {
+		clusterer = ByField
+	}
+	clusters, err := clusterer.Cluster(ctx, kept)
+	if err != nil {
+		return nil, fmt.Errorf("review: clustering: %w", err)
+	}
+	pool.Clusters = clusters
+	for _, c := range clusters {
+		record(Event{Action: ActionCluster, Title: c.Label, Count: len(c.Papers)})
+	}
+
+	if r.Summarize != nil && len(kept) > 0 {
+		if pool.Summary, err = r.Summarize.Summarize(ctx, kept); err != nil {
+			return nil, fmt.Errorf("review: summarizing: %w", err)
+		}
+		for i, c := range pool.Clusters {
+			if pool.Clusters[i].Summary, err = r.Summarize.Summarize(ctx, c.Papers); err != nil {
+				return nil, fmt.Errorf("review: summarizing cluster %q: %w", c.Label, err)
+			}
+		}
+	}
+	return pool, nil
+}
+
+// screen applies the criteria to p, returning the reason it is excluded.
+func (r *Review) screen(p semscholar.Paper) (string, bool) {
+	for _, c := range r.Include {
+		if !c.Match(p) {
+			return "not " + c.Name, false
+		}
+	}
+	for _, c := range r.Exclude {
+		if c.Match(p) {
+			return c.Name, false
+		}
+	}
+	return "", true
+}
+
+// Reasons counts the exclusions of the pool by reason, as for the flow
+// diagram of a systematic review.
+func (p *Pool) Reasons() map[string]int {
+	reasons := make(map[string]int)
+	for _, e := range p.Excluded {
+		reasons[e.Reason]++
+	}
+	return reasons
+}
+
+// Cluster is a group of papers on a theme.
+type Cluster struct {
+	Label  string             `json:"label"`
+	Papers []semscholar.Paper `json:"papers"`
+	// Summary is the digest of the papers by Review.Summarize.
+	Summary string `json:"summary,omitempty"`
+}
+
+// Clusterer groups papers into clusters.
+type Clusterer interface {
+	Cluster(ctx context.Context, papers []semscholar.Paper) ([]Cluster, error)
+}
+
+// ClusterFunc adapts a function to a Clusterer.
+type ClusterFunc func(ctx context.Context, papers []semscholar.Paper) ([]Cluster, error)
+
+// Cluster implements Clusterer.
+func (f ClusterFunc) Cluster(ctx context.Context, papers []semscholar.Paper) ([]Cluster, error) {
+	return f(ctx, papers)
+}
+
+// ByField clusters papers by their first field of study, largest cluster
+// first. Papers with no field form a cluster labeled "Unknown", last.
+var ByField Clusterer = ClusterFunc(func(_ context.Context, papers []semscholar.Paper) ([]Cluster, error) {
+	const unknown = "Unknown"
+	groups := make(map[string][]semscholar.Paper)
+	for _, p := range papers {
+		field := unknown
+		if len(p.FieldsOfStudy) > 0 {
+			field = p.FieldsOfStudy[0]
+		}
+		groups[field] = append(groups[field], p)
+	}
+	var clusters []Cluster
+	for _, field := range slices.Sorted(maps.Keys(groups)) {
+		clusters = append(clusters, Cluster{Label: field, Papers: groups[field]})
+	}
+	slices.SortStableFunc(clusters, func(a, b Cluster) int {
+		if (a.Label == unknown) != (b.Label == unknown) {
+			if a.Label == unknown {
+				return 1
+			}
+			return -1
+		}
+		return len(b.Papers) - len(a.Papers)
+	})
+	return clusters, nil
+})
+
+// YearBetween is the criterion of papers published from one year to
+// another, counting both; a zero bound is open. Papers of unknown year do
+// not meet it.
+func YearBetween(from, to int) Criterion {
+	name := fmt.Sprintf("published %d-%d", from, to)
+	switch {
+	case to == 0:
+		name = fmt.Sprintf("published in or after %d", from)
+	case from == 0:
+		name = fmt.Sprintf("published in or before %d", to)
+	}
+	return Criterion{name, func(p semscholar.Paper) bool {
+		y := p.PublishedYear()
+		return y != 0 && (from == 0 || y >= from) && (to == 0 || y <= to)
+	}}
+}
+
+// MinCitations is the criterion of papers cited at least n times.
+func MinCitations(n int) Criterion {
+	return Criterion{fmt.Sprintf("cited at least %d times", n), func(p semscholar.Paper) bool { return p.CitationCount.Int >= n }}
+}
+
+// MinInfluentialCitations is the criterion of papers cited influentially at
+// least n times, which ranks by impact better than MinCitations does.
+func MinInfluentialCitations(n int) Criterion {
+	return Criterion{fmt.Sprintf("cited influentially at least %d times", n), func(p semscholar.Paper) bool { return p.InfluentialCitationCount.Int >= n }}
+}
+
+// HasAbstract is the criterion of papers with an abstract.
+var HasAbstract = Criterion{"has an abstract", func(p semscholar.Paper) bool { return strings.TrimSpace(p.Abstract) != "" }}
+
+// OpenAccess is the criterion of open-access papers.
+var OpenAccess = Criterion{"open access", func(p semscholar.Paper) bool { return p.IsOpenAccess }}
+
+// InField is the criterion of papers in any of fields of study.
+func InField(fields ...string) Criterion {
+	return Criterion{"in " + strings.Join(fields, " or "), func(p semscholar.Paper) bool {
+		return slices.ContainsFunc(p.FieldsOfStudy, func(f string) bool {
+			return slices.ContainsFunc(fields, func(g string) bool { return strings.EqualFold(f, g) })
+		})
+	}}
+}
+
+// Mentions is the criterion of papers whose title or abstract contains any
+// of terms, ignoring case.
+func Mentions(terms ...string) Criterion {
+	return Criterion{"mentions " + strings.Join(terms, " or "), func(p semscholar.Paper) bool {
+		text := strings.ToLower(p.Title + "\n" + p.Abstract)
+		return slices.ContainsFunc(terms, func(t string) bool { return strings.Contains(text, strings.ToLower(t)) })
+	}}
+}
+
+// ByEmbedding clusters papers by their SPECTER embeddings with opts, as
+// cluster.Papers does, labeling clusters with the terms of their titles.
+// Embeddings the papers lack are fetched with c in batches. Papers that
+// have none even then form a cluster labeled "No embedding", last.
+func ByEmbedding(c *semscholar.Client, opts cluster.Options) Clusterer {
+	return ClusterFunc(func(ctx context.Context, papers []semscholar.Paper) ([]Cluster, error) {
+		var missing []string
+		for _, p := range papers {
+			if p.Embedding == nil {
+				missing = append(missing, p.PaperID)
+			}
+		}
+		if len(missing) > 0 {
+			// Papers in batches that failed are left without embeddings.
+			fetched, err := c.GetPapersBatchAll(ctx, missing, "embedding.specter_v2")
+			var partial *semscholar.PartialError
+			if err != nil && !errors.As(err, &partial) {
+				return nil, err
+			}
+			embeddings := make(map[string]*semscholar.Embedding, len(fetched))
+			for i, p := range fetched {
+				embeddings[missing[i]] = p.Embedding
+			}
+			papers = slices.Clone(papers)
+			for i, p := range papers {
+				if p.Embedding == nil {
+					papers[i].Embedding = embeddings[p.PaperID]
+				}
+			}
+		}
+		found, unembedded, err := cluster.Papers(papers, opts)
+		if err != nil {
+			return nil, err
+		}
+		clusters := make([]Cluster, 0, len(found)+1)
+		for _, f := range found {
+			clusters = append(clusters, Cluster{Label: f.Label, Papers: f.Papers})
+		}
+		if len(unembedded) > 0 {
+			clusters = append(clusters, Cluster{Label: "No embedding", Papers: unembedded})
+		}
+		return clusters, nil
+	})
+}
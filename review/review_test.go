@@ -0,0 +1,181 @@
+package review_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/review"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// start is the time of the fake clock of the reviews tested.
+var start = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// corpus returns a server holding a paper and its preprint, which share a
+// DOI, and four other papers on graphs or neural networks.
+func corpus(t *testing.T) *semscholartest.Server {
+	t.Helper()
+	d := semscholartest.NewDataset()
+	for _, p := range []semscholar.Paper{
+		{PaperID: "p1", Title: "Graph neural networks", Abstract: "Learning on graphs.", Year: 2020, FieldsOfStudy: []string{"Computer Science"}, CitationCount: semscholar.NewNullInt(10)},
+		{PaperID: "p2", Title: "Graph Neural Networks", Abstract: "Learning on graphs.", Year: 2019, FieldsOfStudy: []string{"Computer Science"}, CitationCount: semscholar.NewNullInt(2)},
+		{PaperID: "p3", Title: "Graph kernels", Abstract: "Kernels.", Year: 2010},
+		{PaperID: "p4", Title: "Neural networks: a survey", Year: 2021},
+		{PaperID: "p5", Title: "Neural networks in biology", Abstract: "Cells.", Year: 2022},
+		{PaperID: "p6", Title: "Neural networks again", Abstract: "This paper was retracted.", Year: 2022},
+	} {
+		if p.PaperID == "p1" || p.PaperID == "p2" {
+			p.ExternalIDs.DOI = "10.1000/GNN"
+		}
+		d.AddPaper(p)
+	}
+	srv := semscholartest.NewServer(d)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newReview returns a review of the corpus of srv that keeps papers with an
+// abstract published since 2015 that do not mention retraction.
+func newReview(srv *semscholartest.Server) *review.Review {
+	return &review.Review{
+		Client:  srv.Client(),
+		Queries: []string{"graph", "neural"},
+		Include: []review.Criterion{review.YearBetween(2015, 0), review.HasAbstract},
+		Exclude: []review.Criterion{review.Mentions("retracted")},
+		Clock:   semscholartest.NewFakeClock(start),
+	}
+}
+
+// TestRun checks the counts, duplicates, screening decisions, clusters and
+// log of a review.
+func TestRun(t *testing.T) {
+	pool, err := newReview(corpus(t)).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pool.Found != 8 || pool.Unique != 5 {
+		t.Errorf("found %d records of %d papers, want 8 of 5", pool.Found, pool.Unique)
+	}
+	if len(pool.Included) != 2 {
+		t.Fatalf("included %+v, want the merged paper and p5", pool.Included)
+	}
+	merged := pool.Included[0]
+	if merged.Paper.PaperID != "p1" || merged.Duplicates != 3 || !slices.Equal(merged.Queries, []string{"graph", "neural"}) {
+		t.Errorf("merged candidate %s has %d duplicates, found by %v", merged.Paper.PaperID, merged.Duplicates, merged.Queries)
+	}
+	if id := pool.Included[1].Paper.PaperID; id != "p5" {
+		t.Errorf("second paper included is %s, want p5", id)
+	}
+	wantReasons := map[string]int{"not published in or after 2015": 1, "not has an abstract": 1, "mentions retracted": 1}
+	if got := pool.Reasons(); !maps.Equal(got, wantReasons) {
+		t.Errorf("Reasons = %v, want %v", got, wantReasons)
+	}
+	var labels []string
+	for _, c := range pool.Clusters {
+		labels = append(labels, fmt.Sprintf("%s:%d", c.Label, len(c.Papers)))
+	}
+	if want := []string{"Computer Science:1", "Unknown:1"}; !slices.Equal(labels, want) {
+		t.Errorf("clusters %v, want %v", labels, want)
+	}
+
+	actions := make(map[string]int)
+	for _, e := range pool.Log {
+		actions[e.Action]++
+		if !e.Time.Equal(start) {
+			t.Errorf("event %+v not timed by the review's clock", e)
+		}
+	}
+	wantActions := map[string]int{review.ActionQuery: 2, review.ActionMerge: 1, review.ActionInclude: 2, review.ActionExclude: 3, review.ActionCluster: 2}
+	if !maps.Equal(actions, wantActions) {
+		t.Errorf("logged actions %v, want %v", actions, wantActions)
+	}
+}
+
+// TestRunTruncated checks that MaxPerQuery bounds each query and is noted
+// in the counts and the log.
+func TestRunTruncated(t *testing.T) {
+	r := newReview(corpus(t))
+	r.MaxPerQuery = 2
+	pool, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []review.QueryCount{{Query: "graph", Papers: 2, Truncated: true}, {Query: "neural", Papers: 2, Truncated: true}}
+	if !slices.Equal(pool.Queries, want) {
+		t.Errorf("queries %+v, want %+v", pool.Queries, want)
+	}
+	if e := pool.Log[0]; e.Reason != "truncated at 2" {
+		t.Errorf("first event %+v, want a truncated query", e)
+	}
+}
+
+// TestRunSummarize checks that the pool and each cluster are summarized,
+// and that a failed summary fails the review.
+func TestRunSummarize(t *testing.T) {
+	srv := corpus(t)
+	r := newReview(srv)
+	r.Summarize = semscholar.SummarizerFunc(func(_ context.Context, papers []semscholar.Paper) (string, error) {
+		return fmt.Sprintf("%d papers", len(papers)), nil
+	})
+	pool, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pool.Summary != "2 papers" || pool.Clusters[0].Summary != "1 papers" || pool.Clusters[1].Summary != "1 papers" {
+		t.Errorf("summaries %q, %q and %q", pool.Summary, pool.Clusters[0].Summary, pool.Clusters[1].Summary)
+	}
+
+	errDown := errors.New("model down")
+	r = newReview(srv)
+	r.Summarize = semscholar.SummarizerFunc(func(context.Context, []semscholar.Paper) (string, error) { return "", errDown })
+	if _, err := r.Run(context.Background()); !errors.Is(err, errDown) {
+		t.Errorf("Run = %v, want the summarizer's error", err)
+	}
+}
+
+// TestRunErrors checks that a review needs queries and fails with a query.
+func TestRunErrors(t *testing.T) {
+	srv := corpus(t)
+	r := newReview(srv)
+	r.Queries = nil
+	if _, err := r.Run(context.Background()); err == nil {
+		t.Error("Run without queries succeeded")
+	}
+	srv.Inject(semscholartest.Fault{Path: "/paper/search/bulk", Status: http.StatusBadRequest})
+	if _, err := newReview(srv).Run(context.Background()); err == nil {
+		t.Error("Run with a failing query succeeded")
+	}
+}
+
+// TestCriteria checks the built-in criteria at their bounds.
+func TestCriteria(t *testing.T) {
+	paper := semscholar.Paper{Title: "On GRAPHS", Year: 2015, FieldsOfStudy: []string{"Computer Science"}, CitationCount: semscholar.NewNullInt(5), InfluentialCitationCount: semscholar.NewNullInt(1)}
+	for _, tc := range []struct {
+		c    review.Criterion
+		p    semscholar.Paper
+		want bool
+	}{
+		{review.YearBetween(2015, 2015), paper, true},
+		{review.YearBetween(2016, 0), paper, false},
+		{review.YearBetween(0, 2014), paper, false},
+		{review.YearBetween(0, 0), semscholar.Paper{}, false},
+		{review.MinCitations(5), paper, true},
+		{review.MinCitations(6), paper, false},
+		{review.MinInfluentialCitations(2), paper, false},
+		{review.HasAbstract, semscholar.Paper{Abstract: " \n"}, false},
+		{review.OpenAccess, paper, false},
+		{review.InField("Biology", "computer science"), paper, true},
+		{review.Mentions("graph"), paper, true},
+	} {
+		if got := tc.c.Match(tc.p); got != tc.want {
+			t.Errorf("%s: Match = %v, want %v", tc.c.Name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+// Package s2orc reads the full text of papers from S2ORC, the s2orc dataset
+// of Semantic Scholar's Datasets API, and slices it into titled sections and
+// paragraph chunks with their offsets, ready for indexing or for a language
+// model.
+//
+// The Graph API serves no full text, so it comes from the dataset files,
+// downloaded with semscholar dataset download or from the URLs
+// Client.GetDataset gives, and searched by corpus ID with Find.
+package s2orc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// DatasetName is the name of the dataset in a release.
+const DatasetName = "s2orc"
+
+// Record is a paper of the dataset.
+type Record struct {
+	CorpusID    int         `json:"corpusid"`
+	ExternalIDs ExternalIDs `json:"externalids"`
+	Content     Content     `json:"content"`
+}
+
+// ExternalIDs are the identifiers of a record in other systems.
+type ExternalIDs struct {
+	DOI           string `json:"doi,omitempty"`
+	ArXiv         string `json:"arxiv,omitempty"`
+	ACL           string `json:"acl,omitempty"`
+	PubMed        string `json:"pubmed,omitempty"`
+	PubMedCentral string `json:"pubmedcentral,omitempty"`
+	MAG           string `json:"mag,omitempty"`
+	DBLP          string `json:"dblp,omitempty"`
+}
+
+// Content is the text of a paper and its annotations.
+type Content struct {
+	Source Source `json:"source"`
+	Text   string `json:"text"`
+	// Annotations are the spans of the text of each kind, such as
+	// "paragraph" and "sectionheader", each a JSON array encoded as a
+	// string, or null. Spans decodes them.
+	Annotations map[string]*string `json:"annotations"`
+}
+
+// Source is where the text was extracted from.
+type Source struct {
+	PDFURLs []string `json:"pdfurls,omitempty"`
+	PDFSha  string   `json:"pdfsha,omitempty"`
+	OAInfo  *OAInfo  `json:"oainfo,omitempty"`
+}
+
+// OAInfo is the open-access status and license of the source.
+type OAInfo struct {
+	License       string `json:"license,omitempty"`
+	OpenAccessURL string `json:"openaccessurl,omitempty"`
+	Status        string `json:"status,omitempty"`
+}
+
+// Span is an annotated span of the text. Start and End are offsets in
+// characters, Unicode code points, as in the dataset.
+type Span struct {
+	Start      int               `json:"start"`
+	End        int               `json:"end"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Kinds of annotation used by Sections.
+const (
+	KindAbstract      = "abstract"
+	KindParagraph     = "paragraph"
+	KindSectionHeader = "sectionheader"
+)
+
+// Spans returns the annotations of kind, sorted by start, or none if the
+// record has none.
+func (r *Record) Spans(kind string) ([]Span, error) {
+	raw := r.Content.Annotations[kind]
+	if raw == nil || *raw == "" {
+		return nil, nil
+	}
+	var spans []Span
+	if err := json.Unmarshal([]byte(*raw), &spans); err != nil {
+		return nil, fmt.Errorf("s2orc: corpus ID %d: %s annotations: %w", r.CorpusID, kind, err)
+	}
+	slices.SortStableFunc(spans, func(a, b Span) int { return a.Start - b.Start })
+	return spans, nil
+}
+
+// Slice returns the text of span.
+func (r *Record) Slice(s Span) string {
+	return newCharIndex(r.Content.Text).slice(s.Start, s.End)
+}
+
+// charIndex slices a text by character offsets.
+type charIndex struct {
+	text string
+	// bytes are the byte offsets of the characters of a text that is not
+	// all ASCII, and one past the end; nil if it is.
+	bytes []int
+}
+
+func newCharIndex(text string) charIndex {
+	ix := charIndex{text: text}
+	if utf8.RuneCountInString(text) != len(text) {
+		ix.bytes = make([]int, 0, len(text)+1)
+		for i := range text {
+			ix.bytes = append(ix.bytes, i)
+		}
+		ix.bytes = append(ix.bytes, len(text))
+	}
+	return ix
+}
+
+// slice returns the characters from start to end, clamped to the text.
+func (ix charIndex) slice(start, end int) string {
+	n := len(ix.text)
+	if ix.bytes != nil {
+		n = len(ix.bytes) - 1
+	}
+	start, end = max(start, 0), min(end, n)
+	if end <= start {
+		return ""
+	}
+	if ix.bytes == nil {
+		return ix.text[start:end]
+	}
+	return ix.text[ix.bytes[start]:ix.bytes[end]]
+}
+
+// paragraph returns the paragraph of the characters from start to end,
+// trimmed of space.
+func (ix charIndex) paragraph(start, end int) Paragraph {
+	text := ix.slice(start, end)
+	trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+	start += utf8.RuneCountInString(text) - utf8.RuneCountInString(trimmed)
+	text = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+	return Paragraph{text, start, start + utf8.RuneCountInString(text)}
+}
+
+// Read yields the records of a dataset file, gzip-compressed or not.
+func Read(r io.Reader) iter.Seq2[*Record, error] {
+	return func(yield func(*Record, error) bool) {
+		jr, err := semscholar.NewJSONLReader(r)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer jr.Close()
+		for {
+			rec := new(Record)
+			if err := jr.Read(rec); err == io.EOF {
+				return
+			} else if err != nil {
+				yield(nil, fmt.Errorf("s2orc: %w", err))
+				return
+			}
+			if !yield(rec, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ErrNotFound is returned by Find when none of the papers is in the files.
+var ErrNotFound = errors.New("s2orc: no full text found")
+
+// Find reads the dataset files at paths for the records of the papers with
+// corpusIDs, stopping once it has found them all, and returns those found by
+// corpus ID. A release's dataset is hundreds of gigabytes in hundreds of
+// files, so a search of it all takes long; ctx bounds it.
+func Find(ctx context.Context, paths []string, corpusIDs ...int) (map[int]*Record, error) {
+	want := make(map[int]bool, len(corpusIDs))
+	for _, id := range corpusIDs {
+		want[id] = true
+	}
+	found := make(map[int]*Record)
+	for _, path := range paths {
+		if len(found) == len(want) {
+			break
+		}
+		if err := findIn(ctx, path, want, found); err != nil {
+			return found, err
+		}
+	}
+	if len(found) == 0 {
+		return nil, ErrNotFound
+	}
+	return found, nil
+}
+
+func findIn(ctx context.Context, path string, want map[int]bool, found map[int]*Record) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	for rec, err := range Read(f) {
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if want[rec.CorpusID] {
+			found[rec.CorpusID] = rec
+			if len(found) == len(want) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// Section is a titled section of a paper's text.
+type Section struct {
+	// Title is the heading of the section, "Abstract" for the abstract,
+	// or empty for text before the first heading.
+	Title string `json:"title"`
+	// Number is the section's number as in the paper, such as "3.1", if
+	// it has one.
+	Number     string      `json:"number,omitempty"`
+	Paragraphs []Paragraph `json:"paragraphs"`
+}
+
+// Paragraph is a paragraph of a section, and its offsets in characters in
+// the text of the record.
+type Paragraph struct {
+	Text  string `json:"text"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// Sections slices the text into sections by its section headers, with the
+// abstract first, and each into its paragraphs. Sections with no
+// paragraphs are left out.
+func (r *Record) Sections() ([]Section, error) {
+	headers, err := r.Spans(KindSectionHeader)
+	if err != nil {
+		return nil, err
+	}
+	paragraphs, err := r.Spans(KindParagraph)
+	if err != nil {
+		return nil, err
+	}
+	abstracts, err := r.Spans(KindAbstract)
+	if err != nil {
+		return nil, err
+	}
+
+	ix := newCharIndex(r.Content.Text)
+	abs := Section{Title: "Abstract"}
+	for _, a := range abstracts {
+		abs.Paragraphs = appendParagraph(abs.Paragraphs, ix.paragraph(a.Start, a.End))
+	}
+	sections := []Section{abs}
+	current := Section{}
+	h := 0
+	for _, p := range paragraphs {
+		if slices.ContainsFunc(abstracts, func(a Span) bool { return p.Start >= a.Start && p.End <= a.End }) {
+			continue
+		}
+		for h < len(headers) && headers[h].Start <= p.Start {
+			sections = append(sections, current)
+			current = Section{
+				Title:  ix.paragraph(headers[h].Start, headers[h].End).Text,
+				Number: headers[h].Attributes["n"],
+			}
+			h++
+		}
+		current.Paragraphs = appendParagraph(current.Paragraphs, ix.paragraph(p.Start, p.End))
+	}
+	sections = append(sections, current)
+	return slices.DeleteFunc(sections, func(s Section) bool { return len(s.Paragraphs) == 0 }), nil
+}
+
+// appendParagraph appends p to paragraphs unless it is empty.
+func appendParagraph(paragraphs []Paragraph, p Paragraph) []Paragraph {
+	if p.Text == "" {
+		return paragraphs
+	}
+	return append(paragraphs, p)
+}
+
+// Chunk is a piece of a section's text of bounded length, for indexing or
+// a language model's context.
+type Chunk struct {
+	Section string `json:"section"`
+	Text    string `json:"text"`
+	// Start and End are the offsets in characters of the chunk in the text
+	// of the record. Text joins paragraphs with blank lines, so it is not
+	// always the text between them.
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Chunks splits sections into chunks of at most maxChars characters, each
+// within a section. Paragraphs are joined while they fit, and a paragraph
+// too long for a chunk is split between sentences, or failing that words.
+func Chunks(sections []Section, maxChars int) []Chunk {
+	var chunks []Chunk
+	for _, s := range sections {
+		var cur *Chunk
+		for _, p := range s.Paragraphs {
+			for _, piece := range splitParagraph(p, maxChars) {
+				if cur != nil && utf8.RuneCountInString(cur.Text)+2+utf8.RuneCountInString(piece.Text) <= maxChars {
+					cur.Text += "\n\n" + piece.Text
+					cur.End = piece.End
+					continue
+				}
+				chunks = append(chunks, Chunk{Section: s.Title, Text: piece.Text, Start: piece.Start, End: piece.End})
+				cur = &chunks[len(chunks)-1]
+			}
+		}
+	}
+	return chunks
+}
+
+// splitParagraph splits p into pieces of at most maxChars characters.
+func splitParagraph(p Paragraph, maxChars int) []Paragraph {
+	runes := []rune(p.Text)
+	if maxChars <= 0 || len(runes) <= maxChars {
+		return []Paragraph{p}
+	}
+	var pieces []Paragraph
+	start := 0
+	for start < len(runes) {
+		end := min(start+maxChars, len(runes))
+		if end < len(runes) {
+			end = breakBefore(runes, start, end)
+		}
+		s, e := start, end
+		for s < e && unicode.IsSpace(runes[s]) {
+			s++
+		}
+		for e > s && unicode.IsSpace(runes[e-1]) {
+			e--
+		}
+		if s < e {
+			pieces = append(pieces, Paragraph{string(runes[s:e]), p.Start + s, p.Start + e})
+		}
+		start = end
+	}
+	return pieces
+}
+
+// breakBefore returns where to end a piece of runes starting at start and
+// ending by end: after the last sentence end, else the last space, in its
+// second half, else at end.
+func breakBefore(runes []rune, start, end int) int {
+	half := start + (end-start)/2
+	for i := end - 1; i > half; i-- {
+		if runes[i] == ' ' && strings.ContainsRune(".?!", runes[i-1]) {
+			return i + 1
+		}
+	}
+	for i := end - 1; i > half; i-- {
+		if runes[i] == ' ' {
+			return i + 1
+		}
+	}
+	return end
+}
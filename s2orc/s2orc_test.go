@@ -0,0 +1,206 @@
+package s2orc_test
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/jmwalsh91/semscholar-go/s2orc"
+)
+
+// text builds the text of a record and its annotations, with offsets in
+// characters as in the dataset.
+type text struct {
+	b     strings.Builder
+	spans map[string][]s2orc.Span
+}
+
+// add appends s to the text, annotated as each of kinds, and returns its
+// span.
+func (t *text) add(s string, kinds ...string) s2orc.Span {
+	start := utf8.RuneCountInString(t.b.String())
+	t.b.WriteString(s)
+	span := s2orc.Span{Start: start, End: start + utf8.RuneCountInString(s)}
+	for _, k := range kinds {
+		t.spans[k] = append(t.spans[k], span)
+	}
+	return span
+}
+
+func (t *text) record(id int) *s2orc.Record {
+	r := &s2orc.Record{CorpusID: id, Content: s2orc.Content{Text: t.b.String(), Annotations: make(map[string]*string)}}
+	for k, spans := range t.spans {
+		data, _ := json.Marshal(spans)
+		s := string(data)
+		r.Content.Annotations[k] = &s
+	}
+	return r
+}
+
+// paper returns a record with an abstract, a paragraph before the first
+// heading, and two numbered sections, one empty, in text that is not all
+// ASCII.
+func paper(id int) *s2orc.Record {
+	t := &text{spans: make(map[string][]s2orc.Span)}
+	t.add("Résumé of the work.", s2orc.KindAbstract, s2orc.KindParagraph)
+	t.add("\n")
+	t.add("  Preface paragraph.  ", s2orc.KindParagraph)
+	t.add("\n")
+	t.add("Introduction", s2orc.KindSectionHeader)
+	t.add("\n")
+	t.add("Naïve methods fail. Ours work.", s2orc.KindParagraph)
+	t.add("\n")
+	t.add("Empty", s2orc.KindSectionHeader)
+	t.add("\n")
+	t.add("Results", s2orc.KindSectionHeader)
+	t.add("\n")
+	t.add("Accuracy is high.", s2orc.KindParagraph)
+	r := t.record(id)
+	headers, _ := r.Spans(s2orc.KindSectionHeader)
+	headers[0].Attributes = map[string]string{"n": "1"}
+	headers[2].Attributes = map[string]string{"n": "2"}
+	data, _ := json.Marshal(headers)
+	s := string(data)
+	r.Content.Annotations[s2orc.KindSectionHeader] = &s
+	return r
+}
+
+// TestSections checks the abstract first, text before the first heading,
+// section numbers, sections without paragraphs left out, and paragraph
+// offsets in characters, trimmed of space.
+func TestSections(t *testing.T) {
+	r := paper(1)
+	sections, err := r.Sections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []s2orc.Section{
+		{Title: "Abstract", Paragraphs: []s2orc.Paragraph{{Text: "Résumé of the work.", Start: 0, End: 19}}},
+		{Title: "", Paragraphs: []s2orc.Paragraph{{Text: "Preface paragraph.", Start: 22, End: 40}}},
+		{Title: "Introduction", Number: "1", Paragraphs: []s2orc.Paragraph{{Text: "Naïve methods fail. Ours work.", Start: 56, End: 86}}},
+		{Title: "Results", Number: "2", Paragraphs: []s2orc.Paragraph{{Text: "Accuracy is high.", Start: 101, End: 118}}},
+	}
+	if !reflect.DeepEqual(sections, want) {
+		t.Errorf("Sections =\n%+v\nwant\n%+v", sections, want)
+	}
+	for _, s := range sections {
+		for _, p := range s.Paragraphs {
+			if got := r.Slice(s2orc.Span{Start: p.Start, End: p.End}); got != p.Text {
+				t.Errorf("text at %d-%d is %q, want %q", p.Start, p.End, got, p.Text)
+			}
+		}
+	}
+	if got := r.Slice(s2orc.Span{Start: 113, End: 500}); got != "high." {
+		t.Errorf("Slice past the end = %q, want it clamped", got)
+	}
+
+	bad := "[{"
+	r.Content.Annotations[s2orc.KindParagraph] = &bad
+	if _, err := r.Sections(); err == nil {
+		t.Error("Sections accepted malformed annotations")
+	}
+	if spans, err := (&s2orc.Record{}).Spans(s2orc.KindParagraph); spans != nil || err != nil {
+		t.Errorf("Spans of a record without annotations = %v, %v", spans, err)
+	}
+}
+
+// TestChunks checks that paragraphs are joined while they fit, never across
+// sections, and that long paragraphs are split between sentences, then
+// words, then anywhere.
+func TestChunks(t *testing.T) {
+	sections := []s2orc.Section{
+		{Title: "A", Paragraphs: []s2orc.Paragraph{{Text: "One.", Start: 0, End: 4}, {Text: "Two.", Start: 5, End: 9}, {Text: "Three is long.", Start: 10, End: 24}}},
+		{Title: "B", Paragraphs: []s2orc.Paragraph{{Text: "First sentence. Second one here", Start: 30, End: 61}}},
+		{Title: "C", Paragraphs: []s2orc.Paragraph{{Text: "abcdefghij", Start: 70, End: 80}}},
+	}
+	got := s2orc.Chunks(sections, 16)
+	want := []s2orc.Chunk{
+		{Section: "A", Text: "One.\n\nTwo.", Start: 0, End: 9},
+		{Section: "A", Text: "Three is long.", Start: 10, End: 24},
+		{Section: "B", Text: "First sentence.", Start: 30, End: 45},
+		{Section: "B", Text: "Second one here", Start: 46, End: 61},
+		{Section: "C", Text: "abcdefghij", Start: 70, End: 80},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Chunks =\n%+v\nwant\n%+v", got, want)
+	}
+
+	got = s2orc.Chunks(sections[2:], 4)
+	want = []s2orc.Chunk{
+		{Section: "C", Text: "abcd", Start: 70, End: 74},
+		{Section: "C", Text: "efgh", Start: 74, End: 78},
+		{Section: "C", Text: "ij", Start: 78, End: 80},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Chunks of a word too long =\n%+v\nwant\n%+v", got, want)
+	}
+	got = s2orc.Chunks([]s2orc.Section{{Title: "B", Paragraphs: []s2orc.Paragraph{{Text: "aa bb cc dd", Start: 0, End: 11}}}}, 7)
+	if len(got) != 2 || got[0].Text != "aa bb" || got[1].Text != "cc dd" || got[1].Start != 6 {
+		t.Errorf("Chunks split between words = %+v", got)
+	}
+}
+
+// writeRecords writes records to a dataset file at path, compressed if it
+// ends in ".gz".
+func writeRecords(t *testing.T, path string, records ...*s2orc.Record) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var w io.Writer = f
+	if strings.HasSuffix(path, ".gz") {
+		zw := gzip.NewWriter(f)
+		defer zw.Close()
+		w = zw
+	}
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestFind checks that records are found across plain and compressed files,
+// that the search stops once all are found, and that finding none is
+// ErrNotFound.
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "0.jsonl"), filepath.Join(dir, "1.jsonl.gz")
+	writeRecords(t, first, paper(1), paper(2))
+	writeRecords(t, second, paper(3))
+	missing := filepath.Join(dir, "missing.jsonl")
+	ctx := context.Background()
+
+	found, err := s2orc.Find(ctx, []string{first, second}, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || found[1].CorpusID != 1 || found[3].CorpusID != 3 {
+		t.Errorf("found %v, want 1 and 3", found)
+	}
+	if sections, err := found[3].Sections(); err != nil || len(sections) != 4 {
+		t.Errorf("sections of a record read back: %d, %v", len(sections), err)
+	}
+	if _, err := s2orc.Find(ctx, []string{first, missing}, 2); err != nil {
+		t.Errorf("Find read past the last record wanted: %v", err)
+	}
+	if _, err := s2orc.Find(ctx, []string{first}, 9); !errors.Is(err, s2orc.ErrNotFound) {
+		t.Errorf("Find of a missing record: %v, want ErrNotFound", err)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := s2orc.Find(cancelled, []string{first}, 9); !errors.Is(err, context.Canceled) {
+		t.Errorf("Find with a cancelled context: %v", err)
+	}
+}
@@ -2,12 +2,14 @@ package semscholar
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 // HTTPClient abstracts the Do method so that any client (e.g., http.Client) can be used.
@@ -19,19 +21,62 @@ type HTTPClient interface {
 type Client struct {
 	BaseURL    string
 	HTTPClient HTTPClient
-}
-
-// NewClient creates a new Semantic Scholar API client.
+	// Cache, if set, stores successful responses so repeated calls are served locally.
+	Cache Cache
+	// CacheTTLs controls how long cached responses stay fresh; zero fields use DefaultCacheTTLs.
+	CacheTTLs CacheTTLs
+	// DisableCompression stops the client from requesting gzip-compressed responses.
+	DisableCompression bool
+	// Unmarshaler, if set, decodes response bodies in place of encoding/json.
+	Unmarshaler Unmarshaler
+	// StrictDecoding rejects response fields the target types do not declare,
+	// to catch schema drift in tests. It uses encoding/json even when an
+	// Unmarshaler is set and disables the coercion of mistyped values.
+	StrictDecoding bool
+	// Validate checks decoded responses against the API's invariants (see
+	// ValidateResponse). Violations are reported as a *ValidationError returned
+	// together with the result.
+	Validate bool
+	// Retry controls retries of throttled and unavailable responses; the zero value disables them.
+	Retry RetryPolicy
+	// Hooks are called as calls fail, are retried or are rate limited.
+	Hooks Hooks
+	// Clock, if set, replaces the system clock for retry backoff and cache
+	// freshness, so tests can run them without waiting.
+	Clock Clock
+	// Timeout, if positive, bounds each call, retries included. It does not
+	// apply to iterators, which are bounded by their context.
+	Timeout time.Duration
+
+	// inflight coalesces identical requests; it is shared by clients derived
+	// with WithTimeout and nil for clients not built by NewClient.
+	inflight *singleflight.Group
+}
+
+// NewClient creates a new Semantic Scholar API client. A nil client is replaced
+// by NewHTTPClient(DefaultTimeout, TransportOptions{}).
 func NewClient(baseURL string, client HTTPClient) *Client {
 	if client == nil {
-		client = &http.Client{Timeout: 10 * time.Second}
+		client = NewHTTPClient(DefaultTimeout, TransportOptions{})
 	}
 	return &Client{
 		BaseURL:    baseURL,
 		HTTPClient: client,
+		inflight:   new(singleflight.Group),
 	}
 }
 
+// WithTimeout returns a copy of c whose calls are each bounded by d, retries
+// included. The copy shares c's HTTP client, cache and in-flight requests, so
+// it is cheap to derive one for a single call:
+//
+//	paper, err := client.WithTimeout(2 * time.Second).GetPaper(id, fields)
+func (c *Client) WithTimeout(d time.Duration) *Client {
+	cp := *c
+	cp.Timeout = d
+	return &cp
+}
+
 /***************************************
  *          Graph API Endpoints        *
  ***************************************/
@@ -40,38 +85,36 @@ func NewClient(baseURL string, client HTTPClient) *Client {
 
 // Author represents an author's details returned by the API.
 type Author struct {
-	AuthorID     string   `json:"authorId"`
-	Name         string   `json:"name"`
-	URL          string   `json:"url,omitempty"`
-	Affiliations []string `json:"affiliations,omitempty"`
-	HIndex       int      `json:"hIndex,omitempty"`
-	PaperCount   int      `json:"paperCount,omitempty"`
-	Papers       []Paper  `json:"papers,omitempty"`
+	AuthorID                 string   `json:"authorId"`
+	Name                     string   `json:"name"`
+	URL                      string   `json:"url,omitempty"`
+	Affiliations             []string `json:"affiliations,omitempty"`
+	HIndex                   NullInt  `json:"hIndex,omitzero"`
+	PaperCount               NullInt  `json:"paperCount,omitzero"`
+	InfluentialCitationCount NullInt  `json:"influentialCitationCount,omitzero"`
+	Papers                   []Paper  `json:"papers,omitempty"`
 }
 
 // GetAuthor retrieves details for a single author using their author ID.
 func (c *Client) GetAuthor(authorID, fields string) (*Author, error) {
-	endpoint := fmt.Sprintf("%s/author/%s", c.BaseURL, authorID)
+	return c.GetAuthorContext(context.Background(), authorID, fields)
+}
+
+// GetAuthorContext is like GetAuthor but carries ctx on the request.
+func (c *Client) GetAuthorContext(ctx context.Context, authorID, fields string) (*Author, error) {
+	endpoint := fmt.Sprintf("%s/author/%s", c.BaseURL, url.PathEscape(authorID))
 	if fields != "" {
 		endpoint = fmt.Sprintf("%s?fields=%s", endpoint, url.QueryEscape(fields))
 	}
-	req, err := http.NewRequest("GET", endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := c.HTTPClient.Do(req)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GetAuthor: unexpected status code %d", resp.StatusCode)
-	}
 	var author Author
-	if err := json.NewDecoder(resp.Body).Decode(&author); err != nil {
+	if err := c.doJSON("GetAuthor", req, &author); err != nil {
 		return nil, err
 	}
-	return &author, nil
+	return &author, c.validate("GetAuthor", req, &author)
 }
 
 // AuthorBatchRequest represents the payload for batch retrieval of authors.
@@ -81,6 +124,11 @@ type AuthorBatchRequest struct {
 
 // GetAuthorsBatch retrieves details for multiple authors at once.
 func (c *Client) GetAuthorsBatch(ids []string, fields string) ([]Author, error) {
+	return c.GetAuthorsBatchContext(context.Background(), ids, fields)
+}
+
+// GetAuthorsBatchContext is like GetAuthorsBatch but carries ctx on the request.
+func (c *Client) GetAuthorsBatchContext(ctx context.Context, ids []string, fields string) ([]Author, error) {
 	endpoint := fmt.Sprintf("%s/author/batch", c.BaseURL)
 	if fields != "" {
 		endpoint = fmt.Sprintf("%s?fields=%s", endpoint, url.QueryEscape(fields))
@@ -89,25 +137,16 @@ func (c *Client) GetAuthorsBatch(ids []string, fields string) ([]Author, error)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("GetAuthorsBatch: unexpected status code %d, body: %s", resp.StatusCode, string(body))
-	}
 	var authors []Author
-	if err := json.NewDecoder(resp.Body).Decode(&authors); err != nil {
+	if err := c.doJSON("GetAuthorsBatch", req, &authors); err != nil {
 		return nil, err
 	}
-	return authors, nil
+	return authors, c.validate("GetAuthorsBatch", req, &authors)
 }
 
 // AuthorSearchResponse represents the response from searching for authors.
@@ -120,27 +159,24 @@ type AuthorSearchResponse struct {
 
 // SearchAuthors searches for authors by name.
 func (c *Client) SearchAuthors(query string, offset, limit int, fields string) (*AuthorSearchResponse, error) {
+	return c.SearchAuthorsContext(context.Background(), query, offset, limit, fields)
+}
+
+// SearchAuthorsContext is like SearchAuthors but carries ctx on the request.
+func (c *Client) SearchAuthorsContext(ctx context.Context, query string, offset, limit int, fields string) (*AuthorSearchResponse, error) {
 	endpoint := fmt.Sprintf("%s/author/search?query=%s&offset=%d&limit=%d", c.BaseURL, url.QueryEscape(query), offset, limit)
 	if fields != "" {
 		endpoint = fmt.Sprintf("%s&fields=%s", endpoint, url.QueryEscape(fields))
 	}
-	req, err := http.NewRequest("GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("SearchAuthors: unexpected status code %d", resp.StatusCode)
-	}
 	var result AuthorSearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.doJSON("SearchAuthors", req, &result); err != nil {
 		return nil, err
 	}
-	return &result, nil
+	return &result, c.validate("SearchAuthors", req, &result)
 }
 
 // AuthorPapersResponse represents the response when fetching an author's papers.
@@ -153,69 +189,127 @@ type AuthorPapersResponse struct {
 
 // GetAuthorPapers retrieves papers associated with a specific author.
 func (c *Client) GetAuthorPapers(authorID string, offset, limit int, fields string) (*AuthorPapersResponse, error) {
-	endpoint := fmt.Sprintf("%s/author/%s/papers?offset=%d&limit=%d", c.BaseURL, authorID, offset, limit)
-	if fields != "" {
-		endpoint = fmt.Sprintf("%s&fields=%s", endpoint, url.QueryEscape(fields))
-	}
-	req, err := http.NewRequest("GET", endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := c.HTTPClient.Do(req)
+	return c.GetAuthorPapersContext(context.Background(), authorID, offset, limit, fields)
+}
+
+// GetAuthorPapersContext is like GetAuthorPapers but carries ctx on the request.
+func (c *Client) GetAuthorPapersContext(ctx context.Context, authorID string, offset, limit int, fields string) (*AuthorPapersResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.authorPapersEndpoint(authorID, offset, limit, fields), nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GetAuthorPapers: unexpected status code %d", resp.StatusCode)
-	}
 	var result AuthorPapersResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.doJSON("GetAuthorPapers", req, &result); err != nil {
 		return nil, err
 	}
-	return &result, nil
+	return &result, c.validate("GetAuthorPapers", req, &result)
+}
+
+func (c *Client) authorPapersEndpoint(authorID string, offset, limit int, fields string) string {
+	endpoint := fmt.Sprintf("%s/author/%s/papers?offset=%d&limit=%d", c.BaseURL, url.PathEscape(authorID), offset, limit)
+	if fields != "" {
+		endpoint = fmt.Sprintf("%s&fields=%s", endpoint, url.QueryEscape(fields))
+	}
+	return endpoint
 }
 
 // ----- Paper Endpoints -----
 
 // Paper represents the details of a research paper.
 type Paper struct {
-	PaperID         string                 `json:"paperId"`
-	CorpusID        int                    `json:"corpusId,omitempty"`
-	Title           string                 `json:"title"`
-	Abstract        string                 `json:"abstract,omitempty"`
-	URL             string                 `json:"url,omitempty"`
-	Venue           string                 `json:"venue,omitempty"`
-	PublicationDate string                 `json:"publicationDate,omitempty"`
-	CitationCount   int                    `json:"citationCount,omitempty"`
-	ReferenceCount  int                    `json:"referenceCount,omitempty"`
-	Authors         []Author               `json:"authors,omitempty"`
-	FieldsOfStudy   []string               `json:"fieldsOfStudy,omitempty"`
-	IsOpenAccess    bool                   `json:"isOpenAccess,omitempty"`
-	OpenAccessPdf   map[string]interface{} `json:"openAccessPdf,omitempty"`
+	PaperID                  string                 `json:"paperId"`
+	CorpusID                 int                    `json:"corpusId,omitempty"`
+	ExternalIDs              ExternalIDs            `json:"externalIds,omitzero"`
+	Title                    string                 `json:"title"`
+	Abstract                 string                 `json:"abstract,omitempty"`
+	URL                      string                 `json:"url,omitempty"`
+	Venue                    string                 `json:"venue,omitempty"`
+	Year                     int                    `json:"year,omitempty"`
+	PublicationDate          Date                   `json:"publicationDate,omitzero"`
+	CitationCount            NullInt                `json:"citationCount,omitzero"`
+	InfluentialCitationCount NullInt                `json:"influentialCitationCount,omitzero"`
+	ReferenceCount           NullInt                `json:"referenceCount,omitzero"`
+	Authors                  []Author               `json:"authors,omitempty"`
+	FieldsOfStudy            []string               `json:"fieldsOfStudy,omitempty"`
+	IsOpenAccess             bool                   `json:"isOpenAccess,omitempty"`
+	OpenAccessPdf            map[string]interface{} `json:"openAccessPdf,omitempty"`
+	Tldr                     *Tldr                  `json:"tldr,omitempty"`
+	Embedding                *Embedding             `json:"embedding,omitempty"`
 	// Additional fields can be added as needed.
 }
 
-// AutocompletePaper returns minimal paper information for autocomplete purposes.
-func (c *Client) AutocompletePaper(query string) ([]Paper, error) {
-	endpoint := fmt.Sprintf("%s/paper/autocomplete?query=%s", c.BaseURL, url.QueryEscape(query))
-	req, err := http.NewRequest("GET", endpoint, nil)
+// Tldr is the one-sentence summary of a paper generated by Semantic
+// Scholar's TLDR model. Most papers outside computer science and biomedicine
+// have none.
+type Tldr struct {
+	Model string `json:"model"`
+	Text  string `json:"text"`
+}
+
+// Embedding is a paper's vector embedding, such as that of the SPECTER
+// model. Ask for the embedding field, or "embedding.specter_v2" for the
+// second version of the model.
+type Embedding struct {
+	Model  string    `json:"model"`
+	Vector []float64 `json:"vector"`
+}
+
+// ExternalIDs holds the identifiers a paper is known by in other systems.
+type ExternalIDs struct {
+	DOI           string `json:"DOI,omitempty"`
+	ArXiv         string `json:"ArXiv,omitempty"`
+	MAG           string `json:"MAG,omitempty"`
+	ACL           string `json:"ACL,omitempty"`
+	PubMed        string `json:"PubMed,omitempty"`
+	PubMedCentral string `json:"PubMedCentral,omitempty"`
+	DBLP          string `json:"DBLP,omitempty"`
+	CorpusID      int    `json:"CorpusId,omitempty"`
+}
+
+// GetPaper retrieves details for a single paper by any supported ID, such as an
+// S2 paper ID or a prefixed external ID like "DOI:10.1000/xyz" or "ARXIV:2106.15928".
+func (c *Client) GetPaper(paperID, fields string) (*Paper, error) {
+	return c.GetPaperContext(context.Background(), paperID, fields)
+}
+
+// GetPaperContext is like GetPaper but carries ctx on the request.
+func (c *Client) GetPaperContext(ctx context.Context, paperID, fields string) (*Paper, error) {
+	req, err := c.newPaperRequest(ctx, paperID, fields)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
+	var paper Paper
+	if err := c.doJSON("GetPaper", req, &paper); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("AutocompletePaper: unexpected status code %d", resp.StatusCode)
+	return &paper, c.validate("GetPaper", req, &paper)
+}
+
+func (c *Client) newPaperRequest(ctx context.Context, paperID, fields string) (*http.Request, error) {
+	endpoint := fmt.Sprintf("%s/paper/%s", c.BaseURL, url.PathEscape(paperID))
+	if fields != "" {
+		endpoint = fmt.Sprintf("%s?fields=%s", endpoint, url.QueryEscape(fields))
+	}
+	return http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+}
+
+// AutocompletePaper returns minimal paper information for autocomplete purposes.
+func (c *Client) AutocompletePaper(query string) ([]Paper, error) {
+	return c.AutocompletePaperContext(context.Background(), query)
+}
+
+// AutocompletePaperContext is like AutocompletePaper but carries ctx on the request.
+func (c *Client) AutocompletePaperContext(ctx context.Context, query string) ([]Paper, error) {
+	endpoint := fmt.Sprintf("%s/paper/autocomplete?query=%s", c.BaseURL, url.QueryEscape(query))
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
 	}
 	var papers []Paper
-	if err := json.NewDecoder(resp.Body).Decode(&papers); err != nil {
+	if err := c.doJSON("AutocompletePaper", req, &papers); err != nil {
 		return nil, err
 	}
-	return papers, nil
+	return papers, c.validate("AutocompletePaper", req, &papers)
 }
 
 // PaperBatchRequest represents the request payload for batch paper retrieval.
@@ -225,6 +319,25 @@ type PaperBatchRequest struct {
 
 // GetPapersBatch retrieves details for multiple papers in a single call.
 func (c *Client) GetPapersBatch(ids []string, fields string) ([]Paper, error) {
+	return c.GetPapersBatchContext(context.Background(), ids, fields)
+}
+
+// GetPapersBatchContext is like GetPapersBatch but carries ctx on the request.
+// IDs that could not be resolved come back as zero-valued entries, so the
+// result always lines up index-for-index with ids.
+func (c *Client) GetPapersBatchContext(ctx context.Context, ids []string, fields string) ([]Paper, error) {
+	req, err := c.newPapersBatchRequest(ctx, ids, fields)
+	if err != nil {
+		return nil, err
+	}
+	var papers []Paper
+	if err := c.doJSON("GetPapersBatch", req, &papers); err != nil {
+		return nil, err
+	}
+	return papers, c.validate("GetPapersBatch", req, &papers)
+}
+
+func (c *Client) newPapersBatchRequest(ctx context.Context, ids []string, fields string) (*http.Request, error) {
 	endpoint := fmt.Sprintf("%s/paper/batch", c.BaseURL)
 	if fields != "" {
 		endpoint = fmt.Sprintf("%s?fields=%s", endpoint, url.QueryEscape(fields))
@@ -233,36 +346,42 @@ func (c *Client) GetPapersBatch(ids []string, fields string) ([]Paper, error) {
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GetPapersBatch: unexpected status code %d", resp.StatusCode)
-	}
-	var papers []Paper
-	if err := json.NewDecoder(resp.Body).Decode(&papers); err != nil {
-		return nil, err
-	}
-	return papers, nil
+	return req, nil
 }
 
 // PaperSearchResponse represents the response structure for paper search endpoints.
 type PaperSearchResponse struct {
-	Total  int     `json:"total"`
-	Offset int     `json:"offset"`
-	Next   int     `json:"next,omitempty"`
-	Data   []Paper `json:"data"`
+	Total  int `json:"total"`
+	Offset int `json:"offset"`
+	Next   int `json:"next,omitempty"`
+	// Token continues a bulk search; it is empty on the last page.
+	Token string  `json:"token,omitempty"`
+	Data  []Paper `json:"data"`
+}
+
+// addFilters adds search filters to params. A filter named like a parameter
+// already set from the method's arguments, such as fields, is dropped rather
+// than sent twice.
+func addFilters(params url.Values, filters map[string]string) {
+	for k, v := range filters {
+		if !params.Has(k) {
+			params.Set(k, v)
+		}
+	}
 }
 
 // SearchPapers performs a relevance-ranked search for papers.
 func (c *Client) SearchPapers(query string, offset, limit int, fields string, filters map[string]string) (*PaperSearchResponse, error) {
+	return c.SearchPapersContext(context.Background(), query, offset, limit, fields, filters)
+}
+
+// SearchPapersContext is like SearchPapers but carries ctx on the request.
+func (c *Client) SearchPapersContext(ctx context.Context, query string, offset, limit int, fields string, filters map[string]string) (*PaperSearchResponse, error) {
 	params := url.Values{}
 	params.Add("query", query)
 	params.Add("offset", fmt.Sprintf("%d", offset))
@@ -270,31 +389,39 @@ func (c *Client) SearchPapers(query string, offset, limit int, fields string, fi
 	if fields != "" {
 		params.Add("fields", fields)
 	}
-	for k, v := range filters {
-		params.Add(k, v)
-	}
+	addFilters(params, filters)
 	endpoint := fmt.Sprintf("%s/paper/search?%s", c.BaseURL, params.Encode())
-	req, err := http.NewRequest("GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
+	var result PaperSearchResponse
+	if err := c.doJSON("SearchPapers", req, &result); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("SearchPapers: unexpected status code %d", resp.StatusCode)
+	return &result, c.validate("SearchPapers", req, &result)
+}
+
+// BulkSearchPapers performs a bulk search for papers without full relevance ranking.
+func (c *Client) BulkSearchPapers(query, token, fields, sort, publicationTypes string, additionalFilters map[string]string) (*PaperSearchResponse, error) {
+	return c.BulkSearchPapersContext(context.Background(), query, token, fields, sort, publicationTypes, additionalFilters)
+}
+
+// BulkSearchPapersContext is like BulkSearchPapers but carries ctx on the request.
+func (c *Client) BulkSearchPapersContext(ctx context.Context, query, token, fields, sort, publicationTypes string, additionalFilters map[string]string) (*PaperSearchResponse, error) {
+	endpoint := c.bulkSearchEndpoint(query, token, fields, sort, publicationTypes, additionalFilters)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
 	}
 	var result PaperSearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.doJSON("BulkSearchPapers", req, &result); err != nil {
 		return nil, err
 	}
-	return &result, nil
+	return &result, c.validate("BulkSearchPapers", req, &result)
 }
 
-// BulkSearchPapers performs a bulk search for papers without full relevance ranking.
-func (c *Client) BulkSearchPapers(query, token, fields, sort, publicationTypes string, additionalFilters map[string]string) (*PaperSearchResponse, error) {
+func (c *Client) bulkSearchEndpoint(query, token, fields, sort, publicationTypes string, additionalFilters map[string]string) string {
 	params := url.Values{}
 	if query != "" {
 		params.Add("query", query)
@@ -311,31 +438,18 @@ func (c *Client) BulkSearchPapers(query, token, fields, sort, publicationTypes s
 	if publicationTypes != "" {
 		params.Add("publicationTypes", publicationTypes)
 	}
-	for k, v := range additionalFilters {
-		params.Add(k, v)
-	}
-	endpoint := fmt.Sprintf("%s/paper/search/bulk?%s", c.BaseURL, params.Encode())
-	req, err := http.NewRequest("GET", endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("BulkSearchPapers: unexpected status code %d", resp.StatusCode)
-	}
-	var result PaperSearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	addFilters(params, additionalFilters)
+	return fmt.Sprintf("%s/paper/search/bulk?%s", c.BaseURL, params.Encode())
 }
 
 // MatchSearchPapers performs a minimal match search for papers.
 func (c *Client) MatchSearchPapers(query, fields, publicationTypes string, additionalFilters map[string]string) (*PaperSearchResponse, error) {
+	return c.MatchSearchPapersContext(context.Background(), query, fields, publicationTypes, additionalFilters)
+}
+
+// MatchSearchPapersContext is like MatchSearchPapers but carries ctx on the request.
+// When no paper matches the query, the returned error matches ErrNotFound.
+func (c *Client) MatchSearchPapersContext(ctx context.Context, query, fields, publicationTypes string, additionalFilters map[string]string) (*PaperSearchResponse, error) {
 	params := url.Values{}
 	params.Add("query", query)
 	if fields != "" {
@@ -344,27 +458,82 @@ func (c *Client) MatchSearchPapers(query, fields, publicationTypes string, addit
 	if publicationTypes != "" {
 		params.Add("publicationTypes", publicationTypes)
 	}
-	for k, v := range additionalFilters {
-		params.Add(k, v)
-	}
+	addFilters(params, additionalFilters)
 	endpoint := fmt.Sprintf("%s/paper/search/match?%s", c.BaseURL, params.Encode())
-	req, err := http.NewRequest("GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
+	var result PaperSearchResponse
+	if err := c.doJSON("MatchSearchPapers", req, &result); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("MatchSearchPapers: unexpected status code %d", resp.StatusCode)
+	return &result, c.validate("MatchSearchPapers", req, &result)
+}
+
+// Citation is an edge in the citation graph as returned by the citations and
+// references endpoints. CitingPaper is set for citations and CitedPaper for references.
+type Citation struct {
+	Contexts      []string `json:"contexts,omitempty"`
+	Intents       []string `json:"intents,omitempty"`
+	IsInfluential bool     `json:"isInfluential,omitempty"`
+	CitingPaper   Paper    `json:"citingPaper,omitempty"`
+	CitedPaper    Paper    `json:"citedPaper,omitempty"`
+}
+
+// Citation intents, the purposes Semantic Scholar classifies the contexts
+// of a citation by, as in Citation.Intents.
+const (
+	IntentBackground  = "background"
+	IntentMethodology = "methodology"
+	IntentResult      = "result"
+)
+
+// CitationsResponse represents a page of citations or references for a paper.
+type CitationsResponse struct {
+	Offset int        `json:"offset"`
+	Next   int        `json:"next,omitempty"`
+	Data   []Citation `json:"data"`
+}
+
+// GetPaperCitations retrieves papers that cite the given paper.
+func (c *Client) GetPaperCitations(paperID string, offset, limit int, fields string) (*CitationsResponse, error) {
+	return c.GetPaperCitationsContext(context.Background(), paperID, offset, limit, fields)
+}
+
+// GetPaperCitationsContext is like GetPaperCitations but carries ctx on the request.
+func (c *Client) GetPaperCitationsContext(ctx context.Context, paperID string, offset, limit int, fields string) (*CitationsResponse, error) {
+	return c.getCitationEdges(ctx, "GetPaperCitations", "citations", paperID, offset, limit, fields)
+}
+
+// GetPaperReferences retrieves papers cited by the given paper.
+func (c *Client) GetPaperReferences(paperID string, offset, limit int, fields string) (*CitationsResponse, error) {
+	return c.GetPaperReferencesContext(context.Background(), paperID, offset, limit, fields)
+}
+
+// GetPaperReferencesContext is like GetPaperReferences but carries ctx on the request.
+func (c *Client) GetPaperReferencesContext(ctx context.Context, paperID string, offset, limit int, fields string) (*CitationsResponse, error) {
+	return c.getCitationEdges(ctx, "GetPaperReferences", "references", paperID, offset, limit, fields)
+}
+
+func (c *Client) getCitationEdges(ctx context.Context, op, kind, paperID string, offset, limit int, fields string) (*CitationsResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.citationEdgesEndpoint(kind, paperID, offset, limit, fields), nil)
+	if err != nil {
+		return nil, err
 	}
-	var result PaperSearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	var result CitationsResponse
+	if err := c.doJSON(op, req, &result); err != nil {
 		return nil, err
 	}
-	return &result, nil
+	return &result, c.validate(op, req, &result)
+}
+
+func (c *Client) citationEdgesEndpoint(kind, paperID string, offset, limit int, fields string) string {
+	endpoint := fmt.Sprintf("%s/paper/%s/%s?offset=%d&limit=%d", c.BaseURL, url.PathEscape(paperID), kind, offset, limit)
+	if fields != "" {
+		endpoint = fmt.Sprintf("%s&fields=%s", endpoint, url.QueryEscape(fields))
+	}
+	return endpoint
 }
 
 /***************************************
@@ -384,6 +553,11 @@ type RecommendationResponse struct {
 
 // GetRecommendations retrieves recommended papers given positive (and optionally negative) paper IDs.
 func (c *Client) GetRecommendations(reqData RecommendationRequest, limit int, fields string) (*RecommendationResponse, error) {
+	return c.GetRecommendationsContext(context.Background(), reqData, limit, fields)
+}
+
+// GetRecommendationsContext is like GetRecommendations but carries ctx on the request.
+func (c *Client) GetRecommendationsContext(ctx context.Context, reqData RecommendationRequest, limit int, fields string) (*RecommendationResponse, error) {
 	endpoint := fmt.Sprintf("%s/papers?limit=%d", c.BaseURL, limit)
 	if fields != "" {
 		endpoint = fmt.Sprintf("%s&fields=%s", endpoint, url.QueryEscape(fields))
@@ -392,52 +566,41 @@ func (c *Client) GetRecommendations(reqData RecommendationRequest, limit int, fi
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GetRecommendations: unexpected status code %d", resp.StatusCode)
-	}
 	var result RecommendationResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.doJSON("GetRecommendations", req, &result); err != nil {
 		return nil, err
 	}
-	return &result, nil
+	return &result, c.validate("GetRecommendations", req, &result)
 }
 
 // GetRecommendationsForPaper retrieves recommended papers based on a single positive paper.
 func (c *Client) GetRecommendationsForPaper(paperID, from string, limit int, fields string) (*RecommendationResponse, error) {
-	endpoint := fmt.Sprintf("%s/papers/forpaper/%s?limit=%d", c.BaseURL, paperID, limit)
+	return c.GetRecommendationsForPaperContext(context.Background(), paperID, from, limit, fields)
+}
+
+// GetRecommendationsForPaperContext is like GetRecommendationsForPaper but carries ctx on the request.
+func (c *Client) GetRecommendationsForPaperContext(ctx context.Context, paperID, from string, limit int, fields string) (*RecommendationResponse, error) {
+	endpoint := fmt.Sprintf("%s/papers/forpaper/%s?limit=%d", c.BaseURL, url.PathEscape(paperID), limit)
 	if from != "" {
 		endpoint = fmt.Sprintf("%s&from=%s", endpoint, url.QueryEscape(from))
 	}
 	if fields != "" {
 		endpoint = fmt.Sprintf("%s&fields=%s", endpoint, url.QueryEscape(fields))
 	}
-	req, err := http.NewRequest("GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GetRecommendationsForPaper: unexpected status code %d", resp.StatusCode)
-	}
 	var result RecommendationResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.doJSON("GetRecommendationsForPaper", req, &result); err != nil {
 		return nil, err
 	}
-	return &result, nil
+	return &result, c.validate("GetRecommendationsForPaper", req, &result)
 }
 
 /***************************************
@@ -484,88 +647,76 @@ type DatasetDiffList struct {
 
 // GetDatasetDiffs retrieves the incremental diff links for updating a dataset between releases.
 func (c *Client) GetDatasetDiffs(startReleaseID, endReleaseID, datasetName string) (*DatasetDiffList, error) {
+	return c.GetDatasetDiffsContext(context.Background(), startReleaseID, endReleaseID, datasetName)
+}
+
+// GetDatasetDiffsContext is like GetDatasetDiffs but carries ctx on the request.
+func (c *Client) GetDatasetDiffsContext(ctx context.Context, startReleaseID, endReleaseID, datasetName string) (*DatasetDiffList, error) {
 	endpoint := fmt.Sprintf("%s/diffs/%s/to/%s/%s", c.BaseURL, url.PathEscape(startReleaseID), url.PathEscape(endReleaseID), url.PathEscape(datasetName))
-	req, err := http.NewRequest("GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GetDatasetDiffs: unexpected status code %d", resp.StatusCode)
-	}
 	var diffList DatasetDiffList
-	if err := json.NewDecoder(resp.Body).Decode(&diffList); err != nil {
+	if err := c.doJSON("GetDatasetDiffs", req, &diffList); err != nil {
 		return nil, err
 	}
-	return &diffList, nil
+	return &diffList, c.validate("GetDatasetDiffs", req, &diffList)
 }
 
 // GetReleases retrieves a list of available release IDs.
 func (c *Client) GetReleases() ([]string, error) {
+	return c.GetReleasesContext(context.Background())
+}
+
+// GetReleasesContext is like GetReleases but carries ctx on the request.
+func (c *Client) GetReleasesContext(ctx context.Context) ([]string, error) {
 	endpoint := fmt.Sprintf("%s/release/", c.BaseURL)
-	req, err := http.NewRequest("GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GetReleases: unexpected status code %d", resp.StatusCode)
-	}
 	var releases []string
-	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+	if err := c.doJSON("GetReleases", req, &releases); err != nil {
 		return nil, err
 	}
-	return releases, nil
+	return releases, c.validate("GetReleases", req, &releases)
 }
 
 // GetRelease retrieves metadata for a specific release.
 func (c *Client) GetRelease(releaseID string) (*ReleaseMetadata, error) {
+	return c.GetReleaseContext(context.Background(), releaseID)
+}
+
+// GetReleaseContext is like GetRelease but carries ctx on the request.
+func (c *Client) GetReleaseContext(ctx context.Context, releaseID string) (*ReleaseMetadata, error) {
 	endpoint := fmt.Sprintf("%s/release/%s", c.BaseURL, url.PathEscape(releaseID))
-	req, err := http.NewRequest("GET", endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := c.HTTPClient.Do(req)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GetRelease: unexpected status code %d", resp.StatusCode)
-	}
 	var releaseMeta ReleaseMetadata
-	if err := json.NewDecoder(resp.Body).Decode(&releaseMeta); err != nil {
+	if err := c.doJSON("GetRelease", req, &releaseMeta); err != nil {
 		return nil, err
 	}
-	return &releaseMeta, nil
+	return &releaseMeta, c.validate("GetRelease", req, &releaseMeta)
 }
 
 // GetDataset retrieves metadata and download links for a specific dataset within a release.
 func (c *Client) GetDataset(releaseID, datasetName string) (*DatasetMetadata, error) {
+	return c.GetDatasetContext(context.Background(), releaseID, datasetName)
+}
+
+// GetDatasetContext is like GetDataset but carries ctx on the request.
+func (c *Client) GetDatasetContext(ctx context.Context, releaseID, datasetName string) (*DatasetMetadata, error) {
 	endpoint := fmt.Sprintf("%s/release/%s/dataset/%s", c.BaseURL, url.PathEscape(releaseID), url.PathEscape(datasetName))
-	req, err := http.NewRequest("GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GetDataset: unexpected status code %d", resp.StatusCode)
-	}
 	var datasetMeta DatasetMetadata
-	if err := json.NewDecoder(resp.Body).Decode(&datasetMeta); err != nil {
+	if err := c.doJSON("GetDataset", req, &datasetMeta); err != nil {
 		return nil, err
 	}
-	return &datasetMeta, nil
+	return &datasetMeta, c.validate("GetDataset", req, &datasetMeta)
 }
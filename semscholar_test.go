@@ -0,0 +1,78 @@
+package semscholar_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestExternalIDsOmitted checks that a Paper leaves empty external IDs out of
+// its JSON and keeps them once any is set.
+func TestExternalIDsOmitted(t *testing.T) {
+	data, err := json.Marshal(semscholar.Paper{PaperID: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"externalIds"`) {
+		t.Errorf("encoded %s, want no externalIds", data)
+	}
+	p := semscholar.Paper{PaperID: "a"}
+	p.ExternalIDs.DOI = "10.1000/abc"
+	data, err = json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"externalIds":{"DOI":"10.1000/abc"}`) {
+		t.Errorf("encoded %s, want externalIds with the DOI", data)
+	}
+}
+
+// TestContextCanceled checks that the Context variants of the client's calls
+// send their requests with ctx, failing once it is canceled.
+func TestContextCanceled(t *testing.T) {
+	srv := semscholartest.NewServer(nil)
+	defer srv.Close()
+	c := srv.Client()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := map[string]func() error{
+		"GetAuthorContext": func() error { _, err := c.GetAuthorContext(ctx, "1", ""); return err },
+		"SearchAuthorsContext": func() error {
+			_, err := c.SearchAuthorsContext(ctx, "q", 0, 10, "")
+			return err
+		},
+		"AutocompletePaperContext": func() error { _, err := c.AutocompletePaperContext(ctx, "q"); return err },
+		"BulkSearchPapersContext": func() error {
+			_, err := c.BulkSearchPapersContext(ctx, "q", "", "", "", "", nil)
+			return err
+		},
+		"GetRecommendationsContext": func() error {
+			_, err := c.GetRecommendationsContext(ctx, semscholar.RecommendationRequest{Positive: []string{"a"}}, 10, "")
+			return err
+		},
+		"GetRecommendationsForPaperContext": func() error {
+			_, err := c.GetRecommendationsForPaperContext(ctx, "a", "", 10, "")
+			return err
+		},
+		"GetReleasesContext": func() error { _, err := c.GetReleasesContext(ctx); return err },
+		"GetReleaseContext":  func() error { _, err := c.GetReleaseContext(ctx, "latest"); return err },
+		"GetDatasetContext": func() error {
+			_, err := c.GetDatasetContext(ctx, "latest", "papers")
+			return err
+		},
+		"GetDatasetDiffsContext": func() error {
+			_, err := c.GetDatasetDiffsContext(ctx, "r1", "r2", "papers")
+			return err
+		},
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s = %v, want context.Canceled", name, err)
+		}
+	}
+}
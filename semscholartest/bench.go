@@ -0,0 +1,124 @@
+// Package semscholartest provides helpers for testing and benchmarking code
+// built on the semscholar client.
+package semscholartest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// BenchmarkUnmarshal measures decoding representative response bodies with u,
+// or with encoding/json when u is nil. Call it from a benchmark in your own
+// package to compare JSON implementations before wiring one into a Client:
+//
+//	func BenchmarkSonic(b *testing.B) {
+//		semscholartest.BenchmarkUnmarshal(b, semscholar.UnmarshalFunc(sonic.Unmarshal))
+//	}
+//
+// The sub-benchmarks decode a 500-paper batch response, a 1000-paper bulk
+// search page, and the same page streamed through Client.BulkSearchPapersSeq.
+func BenchmarkUnmarshal(b *testing.B, u semscholar.Unmarshaler) {
+	papers := SyntheticPapers(1000)
+	batch, err := json.Marshal(papers[:500])
+	if err != nil {
+		b.Fatal(err)
+	}
+	page, err := json.Marshal(semscholar.PaperSearchResponse{Total: len(papers), Data: papers})
+	if err != nil {
+		b.Fatal(err)
+	}
+	unmarshal := u
+	if unmarshal == nil {
+		unmarshal = semscholar.UnmarshalFunc(json.Unmarshal)
+	}
+
+	b.Run("batch", func(b *testing.B) {
+		b.SetBytes(int64(len(batch)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var out []semscholar.Paper
+			if err := unmarshal.Unmarshal(batch, &out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("bulkPage", func(b *testing.B) {
+		b.SetBytes(int64(len(page)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var out semscholar.PaperSearchResponse
+			if err := unmarshal.Unmarshal(page, &out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("bulkStream", func(b *testing.B) {
+		client := semscholar.NewClient("http://bench.invalid", staticClient(page))
+		client.Unmarshaler = u
+		b.SetBytes(int64(len(page)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, err := range client.BulkSearchPapersSeq(context.Background(), "q", "", "", "", nil) {
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+// staticClient answers every request with body.
+type staticClient []byte
+
+func (s staticClient) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(s)),
+		ContentLength: int64(len(s)),
+		Request:       req,
+	}, nil
+}
+
+// SyntheticPapers returns n deterministic papers with every commonly requested
+// field populated, for use as benchmark and test payloads.
+func SyntheticPapers(n int) []semscholar.Paper {
+	papers := make([]semscholar.Paper, n)
+	for i := range papers {
+		id := fmt.Sprintf("%040x", i+1)
+		authors := make([]semscholar.Author, 1+i%6)
+		for j := range authors {
+			authors[j] = semscholar.Author{AuthorID: fmt.Sprint(1000 + i*7 + j), Name: fmt.Sprintf("Author %d-%d", i, j)}
+		}
+		papers[i] = semscholar.Paper{
+			PaperID:                  id,
+			CorpusID:                 100000 + i,
+			ExternalIDs:              semscholar.ExternalIDs{DOI: fmt.Sprintf("10.1234/synthetic.%d", i), CorpusID: 100000 + i},
+			Title:                    fmt.Sprintf("A synthetic study of topic %d", i%97),
+			Abstract:                 strings.Repeat(fmt.Sprintf("Sentence %d of a synthetic abstract. ", i), 12),
+			URL:                      "https://www.semanticscholar.org/paper/" + id,
+			Venue:                    []string{"NeurIPS", "ACL", "Nature", "arXiv.org"}[i%4],
+			Year:                     1990 + i%35,
+			PublicationDate:          semscholar.NewDate(1990+i%35, time.Month(1+i%12), 1+i%28),
+			CitationCount:            semscholar.NewNullInt((i * 37) % 5000),
+			InfluentialCitationCount: semscholar.NewNullInt((i * 37) % 5000 / 10),
+			ReferenceCount:           semscholar.NewNullInt((i * 11) % 120),
+			Authors:                  authors,
+			FieldsOfStudy:            []string{"Computer Science", "Biology", "Physics"}[:1+i%3],
+			IsOpenAccess:             i%2 == 0,
+		}
+		if papers[i].IsOpenAccess {
+			papers[i].OpenAccessPdf = map[string]interface{}{"url": "https://example.org/" + id + ".pdf", "status": "GREEN"}
+		}
+	}
+	return papers
+}
@@ -0,0 +1,210 @@
+package semscholartest
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// Chaos is an http.RoundTripper that makes the transport it wraps misbehave
+// the way the API does under load: throttling, server errors, dropped
+// connections, bodies cut off mid-stream and slow responses. Use it to check
+// that a pipeline built on the client survives them:
+//
+//	chaos := &semscholartest.Chaos{ThrottleEvery: 5, ErrorRate: 0.1, TruncateRate: 0.05, Seed: 1}
+//	client := semscholar.NewClient(baseURL, &http.Client{Transport: chaos})
+//
+// The zero value passes every request through unchanged. Random faults are
+// drawn from a source seeded with Seed, so a failing run can be replayed
+// when requests are made in the same order. Set the fields before first use;
+// it is then safe for concurrent use.
+type Chaos struct {
+	// Next is the transport wrapped; nil means http.DefaultTransport.
+	Next http.RoundTripper
+	// ThrottleEvery, if positive, answers every Nth request with 429 instead
+	// of sending it.
+	ThrottleEvery int
+	// RetryAfter, if positive, is sent in a Retry-After header with the 429s.
+	RetryAfter time.Duration
+	// ErrorRate is the probability of answering a request with a 500 instead
+	// of sending it.
+	ErrorRate float64
+	// DropRate is the probability of failing a request with a connection
+	// reset instead of sending it.
+	DropRate float64
+	// TruncateRate is the probability of cutting a response body off halfway,
+	// after which reading it fails with io.ErrUnexpectedEOF.
+	TruncateRate float64
+	// Latency delays every request, plus a random part of up to Jitter.
+	Latency, Jitter time.Duration
+	// Clock, if set, is slept on for the delays instead of the system clock;
+	// with a FakeClock, slow responses cost no time.
+	Clock semscholar.Clock
+	// Seed seeds the random faults.
+	Seed uint64
+
+	mu    sync.Mutex
+	rng   *rand.Rand
+	stats ChaosStats
+}
+
+// ChaosStats counts the requests a Chaos has seen and the faults it injected.
+type ChaosStats struct {
+	Requests  int
+	Throttled int
+	Errors    int
+	Dropped   int
+	Truncated int
+}
+
+// Stats returns the counts so far.
+func (c *Chaos) Stats() ChaosStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// chaosFault is what Chaos does to one request.
+type chaosFault int
+
+const (
+	chaosNone chaosFault = iota
+	chaosThrottle
+	chaosError
+	chaosDrop
+	chaosTruncate
+)
+
+// draw decides the fault for the next request and the delay before it.
+func (c *Chaos) draw() (chaosFault, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rng == nil {
+		c.rng = rand.New(rand.NewPCG(c.Seed, c.Seed))
+	}
+	c.stats.Requests++
+	delay := c.Latency
+	if c.Jitter > 0 {
+		delay += time.Duration(c.rng.Int64N(int64(c.Jitter) + 1))
+	}
+	// Draw every probability on every request, so the faults of one request
+	// do not depend on which rates are set.
+	errRoll, dropRoll, truncRoll := c.rng.Float64(), c.rng.Float64(), c.rng.Float64()
+	switch {
+	case c.ThrottleEvery > 0 && c.stats.Requests%c.ThrottleEvery == 0:
+		c.stats.Throttled++
+		return chaosThrottle, delay
+	case dropRoll < c.DropRate:
+		c.stats.Dropped++
+		return chaosDrop, delay
+	case errRoll < c.ErrorRate:
+		c.stats.Errors++
+		return chaosError, delay
+	case truncRoll < c.TruncateRate:
+		c.stats.Truncated++
+		return chaosTruncate, delay
+	}
+	return chaosNone, delay
+}
+
+// RoundTrip implements http.RoundTripper.
+func (c *Chaos) RoundTrip(req *http.Request) (*http.Response, error) {
+	fault, delay := c.draw()
+	if delay > 0 {
+		clock := c.Clock
+		if clock == nil {
+			clock = semscholar.SystemClock
+		}
+		if err := clock.Sleep(req.Context(), delay); err != nil {
+			closeRequest(req)
+			return nil, err
+		}
+	}
+	switch fault {
+	case chaosThrottle:
+		closeRequest(req)
+		resp := chaosResponse(req, http.StatusTooManyRequests, `{"message":"Too Many Requests. Please wait and try again or apply for a key for higher rate limits.","code":"429"}`)
+		if c.RetryAfter > 0 {
+			resp.Header.Set("Retry-After", strconv.Itoa(int((c.RetryAfter+time.Second-1)/time.Second)))
+		}
+		return resp, nil
+	case chaosError:
+		closeRequest(req)
+		return chaosResponse(req, http.StatusInternalServerError, `{"message":"Internal Server Error"}`), nil
+	case chaosDrop:
+		closeRequest(req)
+		return nil, errConnectionReset
+	}
+	next := c.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil || fault != chaosTruncate {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &truncatedBody{r: bytes.NewReader(body[:len(body)/2])}
+	resp.ContentLength = -1
+	resp.Header.Del("Content-Length")
+	return resp, nil
+}
+
+// closeRequest closes the body of a request that will not be sent, as a
+// RoundTripper must.
+func closeRequest(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+func chaosResponse(req *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// truncatedBody yields the first part of a body and then fails as a
+// connection closed mid-response does.
+type truncatedBody struct {
+	r *bytes.Reader
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (b *truncatedBody) Close() error { return nil }
+
+// connectionReset is the error of a dropped request. It is a net.Error, so
+// the client classifies it as retryable.
+type connectionReset struct{}
+
+func (connectionReset) Error() string   { return "semscholartest: connection reset by chaos" }
+func (connectionReset) Timeout() bool   { return false }
+func (connectionReset) Temporary() bool { return true }
+
+var errConnectionReset error = connectionReset{}
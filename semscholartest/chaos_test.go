@@ -0,0 +1,130 @@
+package semscholartest_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// okTransport answers every request with a paper, without a network.
+type okTransport struct{}
+
+func (okTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"paperId":"a","title":"A paper"}`)),
+		Request:    req,
+	}, nil
+}
+
+// outcome sends one request through chaos and names what came of it.
+func outcome(t *testing.T, chaos *semscholartest.Chaos) string {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, "http://api.test/paper/a", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := chaos.RoundTrip(req)
+	if err != nil {
+		if !semscholar.IsRetryable(err) {
+			t.Errorf("dropped request failed with %v, want a retryable error", err)
+		}
+		return "dropped"
+	}
+	defer resp.Body.Close()
+	_, err = io.ReadAll(resp.Body)
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return "throttled"
+	case resp.StatusCode == http.StatusInternalServerError:
+		return "error"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "truncated"
+	case err != nil:
+		t.Fatal(err)
+	}
+	return "ok"
+}
+
+// TestChaosRates checks that each fault is injected at about its rate, that
+// every Nth request is throttled, and that Stats counts what happened.
+func TestChaosRates(t *testing.T) {
+	const n = 2000
+	for _, tt := range []struct {
+		name     string
+		set      func(*semscholartest.Chaos)
+		fault    string
+		min, max int
+		stat     func(semscholartest.ChaosStats) int
+	}{
+		{"throttle", func(c *semscholartest.Chaos) { c.ThrottleEvery = 10 }, "throttled", 200, 200, func(s semscholartest.ChaosStats) int { return s.Throttled }},
+		{"errors", func(c *semscholartest.Chaos) { c.ErrorRate = 0.1 }, "error", 150, 250, func(s semscholartest.ChaosStats) int { return s.Errors }},
+		{"drops", func(c *semscholartest.Chaos) { c.DropRate = 0.05 }, "dropped", 60, 140, func(s semscholartest.ChaosStats) int { return s.Dropped }},
+		{"truncation", func(c *semscholartest.Chaos) { c.TruncateRate = 0.2 }, "truncated", 330, 470, func(s semscholartest.ChaosStats) int { return s.Truncated }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			chaos := &semscholartest.Chaos{Next: okTransport{}, Seed: 1}
+			tt.set(chaos)
+			counts := make(map[string]int)
+			for range n {
+				counts[outcome(t, chaos)]++
+			}
+			if got := counts[tt.fault]; got < tt.min || got > tt.max {
+				t.Errorf("%d of %d requests %s, want %d to %d", got, n, tt.fault, tt.min, tt.max)
+			}
+			if counts[tt.fault]+counts["ok"] != n {
+				t.Errorf("outcomes %v, want only %s or ok", counts, tt.fault)
+			}
+			stats := chaos.Stats()
+			if stats.Requests != n || tt.stat(stats) != counts[tt.fault] {
+				t.Errorf("Stats = %+v, want %d requests and %d %s", stats, n, counts[tt.fault], tt.fault)
+			}
+		})
+	}
+}
+
+// TestChaosSeed checks that the same seed injects the same faults into the
+// same requests, and another seed different ones.
+func TestChaosSeed(t *testing.T) {
+	run := func(seed uint64) []string {
+		chaos := &semscholartest.Chaos{Next: okTransport{}, ErrorRate: 0.2, DropRate: 0.1, TruncateRate: 0.1, Seed: seed}
+		var outcomes []string
+		for range 200 {
+			outcomes = append(outcomes, outcome(t, chaos))
+		}
+		return outcomes
+	}
+	first := run(7)
+	if again := run(7); !slices.Equal(first, again) {
+		t.Error("the same seed injected different faults")
+	}
+	if other := run(8); slices.Equal(first, other) {
+		t.Error("another seed injected the same faults")
+	}
+}
+
+// TestChaosLatency checks that delays are slept on the Clock.
+func TestChaosLatency(t *testing.T) {
+	clock := semscholartest.NewFakeClock(time.Time{})
+	chaos := &semscholartest.Chaos{Next: okTransport{}, Latency: time.Second, Jitter: time.Second, Clock: clock, Seed: 1}
+	for range 10 {
+		outcome(t, chaos)
+	}
+	sleeps := clock.Sleeps()
+	if len(sleeps) != 10 {
+		t.Fatalf("slept %d times, want 10", len(sleeps))
+	}
+	for _, d := range sleeps {
+		if d < time.Second || d > 2*time.Second {
+			t.Errorf("slept %v, want 1s to 2s", d)
+		}
+	}
+}
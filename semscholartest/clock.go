@@ -0,0 +1,75 @@
+package semscholartest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// FakeClock is a semscholar.Clock whose time moves only when told to. Sleep
+// advances it by the duration at once instead of waiting, so retry backoff
+// and rate limiting under a FakeClock finish instantly, and the delays they
+// asked for can be inspected afterwards. Every goroutine's sleeps advance the
+// same clock, so it suits tests that make calls one at a time. It is safe for
+// concurrent use.
+//
+//	clock := semscholartest.NewFakeClock(time.Time{})
+//	client.Clock = clock
+//	client.Retry = semscholar.RetryPolicy{MaxRetries: 3}
+//	// ... a call answered with 429 three times ...
+//	clock.Sleeps() // the three backoff delays
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+var _ semscholar.Clock = (*FakeClock)(nil)
+
+// NewFakeClock returns a FakeClock reading start, or 2024-01-01 00:00 UTC if
+// start is zero.
+func NewFakeClock(start time.Time) *FakeClock {
+	if start.IsZero() {
+		start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return &FakeClock{now: start}
+}
+
+// Now implements semscholar.Clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep implements semscholar.Clock. It records d and advances the clock by
+// it, unless ctx is already done.
+func (c *FakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sleeps = append(c.sleeps, d)
+	if d > 0 {
+		c.now = c.now.Add(d)
+	}
+	return nil
+}
+
+// Advance moves the clock forward by d without recording a sleep, as time
+// passing between calls would, for instance to expire cache entries.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Sleeps returns the durations slept so far, in order.
+func (c *FakeClock) Sleeps() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.sleeps...)
+}
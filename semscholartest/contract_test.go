@@ -0,0 +1,16 @@
+//go:build contract
+
+package semscholartest_test
+
+import (
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestContract runs the live contract suite. It needs an API key:
+//
+//	SEMSCHOLAR_API_KEY=... go test -tags contract -run TestContract -v ./semscholartest
+func TestContract(t *testing.T) {
+	semscholartest.TestContract(t)
+}
@@ -0,0 +1,380 @@
+package semscholartest
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// Dataset is the data a Server serves: papers, authors, the citations between
+// the papers, and dataset releases. Tests can build one from scratch or start
+// from Fixtures and add to it.
+type Dataset struct {
+	// Papers are keyed by paper ID. Authors listed on a paper need only their
+	// ID and name.
+	Papers map[string]semscholar.Paper
+	// Authors are keyed by author ID. An author's papers are the papers that
+	// list them.
+	Authors map[string]semscholar.Author
+	// References maps a paper ID to the IDs of the papers it cites. Citations
+	// are derived from it.
+	References map[string][]string
+	// Releases are keyed by release ID, such as "2024-11-19".
+	Releases map[string]semscholar.ReleaseMetadata
+	// Files maps "release/dataset" to the file names the dataset consists
+	// of. Each file holds the dataset's papers as gzip-compressed JSON Lines.
+	Files map[string][]string
+}
+
+// NewDataset returns an empty Dataset.
+func NewDataset() *Dataset {
+	return &Dataset{
+		Papers:     make(map[string]semscholar.Paper),
+		Authors:    make(map[string]semscholar.Author),
+		References: make(map[string][]string),
+		Releases:   make(map[string]semscholar.ReleaseMetadata),
+		Files:      make(map[string][]string),
+	}
+}
+
+// AddPaper adds p, and the authors it lists that are not yet known.
+func (d *Dataset) AddPaper(p semscholar.Paper) {
+	d.Papers[p.PaperID] = p
+	for _, a := range p.Authors {
+		if _, ok := d.Authors[a.AuthorID]; !ok && a.AuthorID != "" {
+			d.Authors[a.AuthorID] = semscholar.Author{AuthorID: a.AuthorID, Name: a.Name}
+		}
+	}
+}
+
+// AddCitation records that the paper citing cites the paper cited.
+func (d *Dataset) AddCitation(citing, cited string) {
+	d.References[citing] = append(d.References[citing], cited)
+}
+
+// paper resolves id as the API does: a paper ID, or an external ID with its
+// prefix, such as "DOI:10.1000/xyz", "ARXIV:1706.03762" or "CorpusId:215416146".
+func (d *Dataset) paper(id string) (semscholar.Paper, bool) {
+	if p, ok := d.Papers[id]; ok {
+		return p, true
+	}
+	prefix, value, ok := strings.Cut(id, ":")
+	if !ok {
+		return semscholar.Paper{}, false
+	}
+	for _, p := range d.Papers {
+		ids := p.ExternalIDs
+		var match bool
+		switch strings.ToUpper(prefix) {
+		case "DOI":
+			match = strings.EqualFold(ids.DOI, value)
+		case "ARXIV":
+			match = ids.ArXiv == value
+		case "MAG":
+			match = ids.MAG == value
+		case "ACL":
+			match = ids.ACL == value
+		case "PMID":
+			match = ids.PubMed == value
+		case "PMCID":
+			match = ids.PubMedCentral == value
+		case "CORPUSID":
+			match = strconv.Itoa(p.CorpusID) == value
+		}
+		if match {
+			return p, true
+		}
+	}
+	return semscholar.Paper{}, false
+}
+
+// sortedPapers returns the papers accepted by keep, most cited first, which
+// stands in for relevance.
+func (d *Dataset) sortedPapers(keep func(semscholar.Paper) bool) []semscholar.Paper {
+	var papers []semscholar.Paper
+	for _, p := range d.Papers {
+		if keep(p) {
+			papers = append(papers, p)
+		}
+	}
+	slices.SortFunc(papers, func(a, b semscholar.Paper) int {
+		if c := cmp.Compare(b.CitationCount.Int, a.CitationCount.Int); c != 0 {
+			return c
+		}
+		return strings.Compare(a.PaperID, b.PaperID)
+	})
+	return papers
+}
+
+// searchPapers returns the papers whose title or abstract contains every word
+// of query, ignoring case, that pass filters.
+func (d *Dataset) searchPapers(query string, filters map[string]string) []semscholar.Paper {
+	words := strings.Fields(strings.ToLower(query))
+	return d.sortedPapers(func(p semscholar.Paper) bool {
+		text := strings.ToLower(p.Title + " " + p.Abstract)
+		for _, w := range words {
+			if !strings.Contains(text, w) {
+				return false
+			}
+		}
+		return matchesFilters(p, filters)
+	})
+}
+
+// searchSnippets returns the sentences of the titles and abstracts of the
+// papers passing filters that contain any word of query, ignoring case,
+// scored by the share of the words they contain, best first. The paperIds
+// filter restricts them to the papers it lists, by paper ID.
+func (d *Dataset) searchSnippets(query string, filters map[string]string) []semscholar.SnippetMatch {
+	words := strings.Fields(strings.ToLower(query))
+	var ids []string
+	if v, ok := filters["paperIds"]; ok {
+		ids = strings.Split(v, ",")
+		filters = maps.Clone(filters)
+		delete(filters, "paperIds")
+	}
+	var matches []semscholar.SnippetMatch
+	for _, p := range d.sortedPapers(func(p semscholar.Paper) bool {
+		return (ids == nil || slices.Contains(ids, p.PaperID)) && matchesFilters(p, filters)
+	}) {
+		paper := semscholar.SnippetPaper{CorpusID: strconv.Itoa(p.CorpusID), Title: p.Title}
+		for _, a := range p.Authors {
+			paper.Authors = append(paper.Authors, a.Name)
+		}
+		passages := []semscholar.Snippet{{Text: p.Title, SnippetKind: "title"}}
+		for _, sentence := range strings.SplitAfter(p.Abstract, ". ") {
+			if sentence = strings.TrimSpace(sentence); sentence != "" {
+				passages = append(passages, semscholar.Snippet{Text: sentence, SnippetKind: "abstract"})
+			}
+		}
+		for _, s := range passages {
+			text := strings.ToLower(s.Text)
+			n := 0
+			for _, w := range words {
+				if strings.Contains(text, w) {
+					n++
+				}
+			}
+			if n > 0 {
+				matches = append(matches, semscholar.SnippetMatch{Snippet: s, Score: float64(n) / float64(len(words)), Paper: paper})
+			}
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b semscholar.SnippetMatch) int { return cmp.Compare(b.Score, a.Score) })
+	return matches
+}
+
+// matchesFilters applies the search filters the API supports that can be
+// evaluated on a Paper: year, publicationDateOrYear, fieldsOfStudy, venue,
+// minCitationCount and openAccessPdf.
+func matchesFilters(p semscholar.Paper, filters map[string]string) bool {
+	for name, value := range filters {
+		switch name {
+		case "year", "publicationDateOrYear":
+			if !inRange(p.PublishedDate().String(), value, name == "year") {
+				return false
+			}
+		case "fieldsOfStudy":
+			if !slices.ContainsFunc(strings.Split(value, ","), func(f string) bool { return slices.Contains(p.FieldsOfStudy, f) }) {
+				return false
+			}
+		case "venue":
+			if !slices.ContainsFunc(strings.Split(value, ","), func(v string) bool { return strings.EqualFold(v, p.Venue) }) {
+				return false
+			}
+		case "minCitationCount":
+			if n, err := strconv.Atoi(value); err == nil && p.CitationCount.Int < n {
+				return false
+			}
+		case "openAccessPdf":
+			if !p.IsOpenAccess {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// inRange reports whether date, as "YYYY-MM-DD", falls in rng. Year ranges
+// such as "2019", "2016-2020", "2010-" or "-2015" use a dash; date ranges such
+// as "2019-03-05:2020-06" or "2019-03:" a colon.
+func inRange(date, rng string, years bool) bool {
+	if date == "" {
+		return false
+	}
+	sep := ":"
+	if years {
+		sep = "-"
+	}
+	lo, hi, isRange := strings.Cut(rng, sep)
+	if !isRange {
+		hi = lo
+	}
+	if lo != "" && date[:min(len(lo), len(date))] < lo {
+		return false
+	}
+	return hi == "" || date[:min(len(hi), len(date))] <= hi
+}
+
+// match returns the paper whose title best matches title: one equal to it,
+// ignoring case, or else the most cited one containing it.
+func (d *Dataset) match(title string, filters map[string]string) (semscholar.Paper, bool) {
+	title = strings.ToLower(strings.TrimSpace(title))
+	var best semscholar.Paper
+	found := false
+	for _, p := range d.searchPapers("", filters) {
+		t := strings.ToLower(p.Title)
+		if t == title {
+			return p, true
+		}
+		if !found && title != "" && strings.Contains(t, title) {
+			best, found = p, true
+		}
+	}
+	return best, found
+}
+
+// authorPapers returns the papers listing authorID, most cited first.
+func (d *Dataset) authorPapers(authorID string) []semscholar.Paper {
+	return d.sortedPapers(func(p semscholar.Paper) bool {
+		return slices.ContainsFunc(p.Authors, func(a semscholar.Author) bool { return a.AuthorID == authorID })
+	})
+}
+
+// searchAuthors returns the authors whose name contains query, ignoring case.
+func (d *Dataset) searchAuthors(query string) []semscholar.Author {
+	query = strings.ToLower(query)
+	var authors []semscholar.Author
+	for _, a := range d.Authors {
+		if strings.Contains(strings.ToLower(a.Name), query) {
+			authors = append(authors, a)
+		}
+	}
+	slices.SortFunc(authors, func(a, b semscholar.Author) int { return strings.Compare(a.AuthorID, b.AuthorID) })
+	return authors
+}
+
+// author returns the author with id, with the paper count, influential
+// citation count and h-index computed from the dataset.
+func (d *Dataset) author(id string) (semscholar.Author, bool) {
+	a, ok := d.Authors[id]
+	if !ok {
+		return a, false
+	}
+	papers := d.authorPapers(id)
+	if !a.PaperCount.Valid {
+		a.PaperCount = semscholar.NewNullInt(len(papers))
+	}
+	if !a.InfluentialCitationCount.Valid {
+		a.InfluentialCitationCount = semscholar.NewNullInt(0)
+		for _, p := range papers {
+			a.InfluentialCitationCount.Int += p.InfluentialCitationCount.Int
+		}
+	}
+	if !a.HIndex.Valid {
+		a.HIndex = semscholar.NewNullInt(0)
+		for i, p := range papers {
+			if p.CitationCount.Int > i {
+				a.HIndex.Int = i + 1
+			}
+		}
+	}
+	return a, true
+}
+
+// citations returns the edges from the papers citing id.
+func (d *Dataset) citations(id string) []semscholar.Citation {
+	var edges []semscholar.Citation
+	for _, citing := range d.sortedPapers(func(semscholar.Paper) bool { return true }) {
+		if slices.Contains(d.References[citing.PaperID], id) {
+			edges = append(edges, semscholar.Citation{CitingPaper: citing})
+		}
+	}
+	return edges
+}
+
+// references returns the edges to the papers id cites, in the order given.
+// Cited papers missing from the dataset appear without an ID, as papers the
+// API cannot resolve do.
+func (d *Dataset) references(id string) []semscholar.Citation {
+	var edges []semscholar.Citation
+	for _, cited := range d.References[id] {
+		p, ok := d.Papers[cited]
+		if !ok {
+			p = semscholar.Paper{Title: cited}
+		}
+		edges = append(edges, semscholar.Citation{CitedPaper: p})
+	}
+	return edges
+}
+
+// recommend returns papers sharing a field of study with the positive papers,
+// excluding the seeds themselves and any paper sharing a field with the
+// negative ones only.
+func (d *Dataset) recommend(positive, negative []string) []semscholar.Paper {
+	fields := make(map[string]bool)
+	for _, id := range positive {
+		if p, ok := d.paper(id); ok {
+			for _, f := range p.FieldsOfStudy {
+				fields[f] = true
+			}
+		}
+	}
+	excluded := make(map[string]bool)
+	for _, id := range slices.Concat(positive, negative) {
+		if p, ok := d.paper(id); ok {
+			excluded[p.PaperID] = true
+		}
+	}
+	return d.sortedPapers(func(p semscholar.Paper) bool {
+		return !excluded[p.PaperID] && slices.ContainsFunc(p.FieldsOfStudy, func(f string) bool { return fields[f] })
+	})
+}
+
+// releaseIDs returns the release IDs in ascending order.
+func (d *Dataset) releaseIDs() []string {
+	ids := make([]string, 0, len(d.Releases))
+	for id := range d.Releases {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// resolveRelease returns id, or the latest release ID if id is "latest".
+func (d *Dataset) resolveRelease(id string) string {
+	if ids := d.releaseIDs(); id == "latest" && len(ids) > 0 {
+		return ids[len(ids)-1]
+	}
+	return id
+}
+
+// releaseRange returns the release IDs from start to end inclusive, in
+// ascending order.
+func (d *Dataset) releaseRange(start, end string) ([]string, bool) {
+	ids := d.releaseIDs()
+	from := slices.Index(ids, d.resolveRelease(start))
+	to := slices.Index(ids, d.resolveRelease(end))
+	if from < 0 || to < from {
+		return nil, false
+	}
+	return ids[from : to+1], true
+}
+
+// page returns items[offset:offset+limit] and the offset of the next page,
+// zero if this page is the last.
+func page[T any](items []T, offset, limit int) ([]T, int) {
+	if offset >= len(items) || offset < 0 {
+		return []T{}, 0
+	}
+	end := min(len(items), offset+limit)
+	next := 0
+	if end < len(items) {
+		next = end
+	}
+	return items[offset:end], next
+}
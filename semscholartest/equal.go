@@ -0,0 +1,153 @@
+package semscholartest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// volatile are the fields EqualPaper and EqualAuthor ignore by default, by
+// the type declaring them: counts and URLs that change between calls to the
+// live API without the record changing.
+var volatile = map[string]bool{
+	"Paper.CitationCount":             true,
+	"Paper.ReferenceCount":            true,
+	"Paper.InfluentialCitationCount":  true,
+	"Paper.URL":                       true,
+	"Paper.OpenAccessPdf":             true,
+	"Author.URL":                      true,
+	"Author.HIndex":                   true,
+	"Author.PaperCount":               true,
+	"Author.InfluentialCitationCount": true,
+}
+
+// EqualPaper reports whether got and want are equal apart from volatile
+// fields (citation and reference counts, URLs and the open-access PDF, and
+// the h-index, paper count and URL of authors) and the fields in ignore,
+// named by type and field such as "Paper.Abstract" or "Author.Affiliations".
+// If they differ it fails t with a diff listing each differing field, so
+// snapshot tests of enrichment pipelines fail readably and only on real
+// changes.
+func EqualPaper(t testing.TB, got, want semscholar.Paper, ignore ...string) bool {
+	t.Helper()
+	if diff := DiffPaper(got, want, ignore...); diff != "" {
+		t.Errorf("paper %s differs:\n%s", cmpID(got.PaperID, want.PaperID), diff)
+		return false
+	}
+	return true
+}
+
+// EqualAuthor is EqualPaper for authors.
+func EqualAuthor(t testing.TB, got, want semscholar.Author, ignore ...string) bool {
+	t.Helper()
+	if diff := DiffAuthor(got, want, ignore...); diff != "" {
+		t.Errorf("author %s differs:\n%s", cmpID(got.AuthorID, want.AuthorID), diff)
+		return false
+	}
+	return true
+}
+
+// DiffPaper returns the differences EqualPaper reports, one field per line,
+// or "" if there are none.
+func DiffPaper(got, want semscholar.Paper, ignore ...string) string {
+	return diff(got, want, ignore)
+}
+
+// DiffAuthor returns the differences EqualAuthor reports, one field per line,
+// or "" if there are none.
+func DiffAuthor(got, want semscholar.Author, ignore ...string) string {
+	return diff(got, want, ignore)
+}
+
+func cmpID(got, want string) string {
+	if got == want {
+		return got
+	}
+	return got + " (want " + want + ")"
+}
+
+func diff(got, want any, ignore []string) string {
+	d := differ{ignore: make(map[string]bool)}
+	for k := range volatile {
+		d.ignore[k] = true
+	}
+	for _, k := range ignore {
+		d.ignore[k] = true
+	}
+	d.walk("", reflect.ValueOf(got), reflect.ValueOf(want))
+	return strings.TrimSuffix(d.b.String(), "\n")
+}
+
+// differ collects the differences between two values, field by field.
+type differ struct {
+	ignore map[string]bool
+	b      strings.Builder
+}
+
+// leaves are the struct types compared whole, as values, rather than field
+// by field.
+var leaves = map[reflect.Type]bool{
+	reflect.TypeFor[semscholar.Date]():    true,
+	reflect.TypeFor[semscholar.NullInt](): true,
+}
+
+func (d *differ) walk(path string, got, want reflect.Value) {
+	switch kind := got.Kind(); {
+	case kind == reflect.Struct && !leaves[got.Type()]:
+		t := got.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() || d.ignore[t.Name()+"."+f.Name] {
+				continue
+			}
+			d.walk(join(path, f.Name), got.Field(i), want.Field(i))
+		}
+	case kind == reflect.Slice:
+		n := min(got.Len(), want.Len())
+		for i := 0; i < n; i++ {
+			d.walk(fmt.Sprintf("%s[%d]", path, i), got.Index(i), want.Index(i))
+		}
+		for i := n; i < got.Len(); i++ {
+			fmt.Fprintf(&d.b, "  %s[%d]: got %s, want none\n", path, i, show(got.Index(i)))
+		}
+		for i := n; i < want.Len(); i++ {
+			fmt.Fprintf(&d.b, "  %s[%d]: got none, want %s\n", path, i, show(want.Index(i)))
+		}
+	default:
+		if !reflect.DeepEqual(got.Interface(), want.Interface()) {
+			fmt.Fprintf(&d.b, "  %s: got %s, want %s\n", path, show(got), show(want))
+		}
+	}
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// show formats v compactly: strings quoted, authors and papers by ID and
+// name, null dates and numbers as null, anything else with %v.
+func show(v reflect.Value) string {
+	switch x := v.Interface().(type) {
+	case string:
+		return fmt.Sprintf("%q", x)
+	case semscholar.Author:
+		return fmt.Sprintf("author %s %q", x.AuthorID, x.Name)
+	case semscholar.Paper:
+		return fmt.Sprintf("paper %s %q", x.PaperID, x.Title)
+	case semscholar.Date:
+		if x.IsZero() {
+			return "null"
+		}
+	case semscholar.NullInt:
+		if !x.Valid {
+			return "null"
+		}
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
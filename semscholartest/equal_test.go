@@ -0,0 +1,88 @@
+package semscholartest_test
+
+import (
+	"fmt"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestDiffPaper checks that DiffPaper ignores volatile fields and those it
+// is told to, at any depth, and names each other difference.
+func TestDiffPaper(t *testing.T) {
+	want := semscholar.Paper{
+		PaperID:       "p1",
+		Title:         "Graph neural networks",
+		Abstract:      "We study graphs.",
+		CitationCount: semscholar.NewNullInt(10),
+		URL:           "https://example.org/p1",
+		Authors:       []semscholar.Author{{AuthorID: "a1", Name: "Ada Lovelace", HIndex: semscholar.NewNullInt(3)}},
+	}
+	for _, tt := range []struct {
+		name   string
+		change func(*semscholar.Paper)
+		ignore []string
+		want   string
+	}{
+		{"equal", func(p *semscholar.Paper) {}, nil, ""},
+		{"volatile fields", func(p *semscholar.Paper) {
+			p.CitationCount = semscholar.NewNullInt(12)
+			p.URL = "https://example.org/moved"
+			p.Authors = []semscholar.Author{{AuthorID: "a1", Name: "Ada Lovelace", HIndex: semscholar.NewNullInt(4)}}
+		}, nil, ""},
+		{"title", func(p *semscholar.Paper) { p.Title = "Graph networks" }, nil, `  Title: got "Graph networks", want "Graph neural networks"`},
+		{"ignored abstract", func(p *semscholar.Paper) { p.Abstract = "" }, []string{"Paper.Abstract"}, ""},
+		{"abstract", func(p *semscholar.Paper) { p.Abstract = "" }, []string{"Author.Name"}, `  Abstract: got "", want "We study graphs."`},
+		{"author name", func(p *semscholar.Paper) {
+			p.Authors = []semscholar.Author{{AuthorID: "a1", Name: "A. Lovelace"}}
+		}, nil, `  Authors[0].Name: got "A. Lovelace", want "Ada Lovelace"`},
+		{"ignored author name", func(p *semscholar.Paper) {
+			p.Authors = []semscholar.Author{{AuthorID: "a1", Name: "A. Lovelace"}}
+		}, []string{"Author.Name"}, ""},
+		{"extra author", func(p *semscholar.Paper) {
+			p.Authors = append(p.Authors, semscholar.Author{AuthorID: "a2", Name: "Alan Turing"})
+		}, nil, `  Authors[1]: got author a2 "Alan Turing", want none`},
+		{"two fields", func(p *semscholar.Paper) { p.Title = ""; p.Year = 2020 }, nil, "  Title: got \"\", want \"Graph neural networks\"\n  Year: got 2020, want 0"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := want
+			got.Authors = append([]semscholar.Author(nil), want.Authors...)
+			tt.change(&got)
+			if d := semscholartest.DiffPaper(got, want, tt.ignore...); d != tt.want {
+				t.Errorf("DiffPaper =\n%s\nwant\n%s", d, tt.want)
+			}
+		})
+	}
+}
+
+// recordingTB records the failures reported to it.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// TestEqualAuthor checks that EqualAuthor passes authors differing only in
+// volatile fields and fails others with a diff.
+func TestEqualAuthor(t *testing.T) {
+	want := semscholar.Author{AuthorID: "a1", Name: "Ada Lovelace", PaperCount: semscholar.NewNullInt(3)}
+	got := want
+	got.PaperCount = semscholar.NewNullInt(4)
+	tb := &recordingTB{TB: t}
+	if !semscholartest.EqualAuthor(tb, got, want) || len(tb.errors) != 0 {
+		t.Errorf("EqualAuthor failed on a volatile field: %q", tb.errors)
+	}
+	got.Name = "Ada King"
+	if semscholartest.EqualAuthor(tb, got, want) {
+		t.Error("EqualAuthor passed authors with different names")
+	}
+	if want := "author a1 differs:\n  Name: got \"Ada King\", want \"Ada Lovelace\""; len(tb.errors) != 1 || tb.errors[0] != want {
+		t.Errorf("EqualAuthor reported %q, want %q", tb.errors, want)
+	}
+}
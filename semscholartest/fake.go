@@ -0,0 +1,549 @@
+package semscholartest
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// Fake implements PaperService, AuthorService, RecommendationService and
+// DatasetService in memory over a Dataset, for unit tests of code built on
+// the client that need neither HTTP nor a Server. It searches, filters, pages
+// and resolves external IDs as a Server does, but returns whole records,
+// ignoring fields. Unknown IDs fail with a 404 *APIError. It is safe for
+// concurrent use.
+//
+//	d := semscholartest.NewDataset()
+//	d.AddPaper(semscholar.Paper{PaperID: "p1", Title: "Graph neural networks"})
+//	var papers semscholar.PaperService = semscholartest.NewFake(d)
+type Fake struct {
+	mu           sync.RWMutex
+	data         *Dataset
+	bulkPageSize int
+}
+
+var (
+	_ semscholar.PaperService          = (*Fake)(nil)
+	_ semscholar.AuthorService         = (*Fake)(nil)
+	_ semscholar.RecommendationService = (*Fake)(nil)
+	_ semscholar.DatasetService        = (*Fake)(nil)
+)
+
+// NewFake returns a Fake over data, or over Fixtures if data is nil. The
+// dataset must not be modified while the fake is in use; use Update.
+func NewFake(data *Dataset) *Fake {
+	if data == nil {
+		data = Fixtures()
+	}
+	return &Fake{data: data, bulkPageSize: 1000}
+}
+
+// Update calls fn with the dataset, excluding calls while it runs.
+func (f *Fake) Update(fn func(*Dataset)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fn(f.data)
+}
+
+// SetBulkPageSize sets how many papers a bulk search page holds; the API
+// sends 1000.
+func (f *Fake) SetBulkPageSize(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bulkPageSize = max(n, 1)
+}
+
+// notFound returns the error the client returns for a 404.
+func notFound(op, format string, args ...any) error {
+	return &semscholar.APIError{Op: op, StatusCode: http.StatusNotFound, Body: fmt.Sprintf(`{"error":%q}`, fmt.Sprintf(format, args...))}
+}
+
+// GetPaper returns the paper with paperID, which may be an external ID.
+func (f *Fake) GetPaper(paperID, fields string) (*semscholar.Paper, error) {
+	return f.GetPaperContext(context.Background(), paperID, fields)
+}
+
+// GetPaperContext is like GetPaper but fails once ctx is done.
+func (f *Fake) GetPaperContext(ctx context.Context, paperID, fields string) (*semscholar.Paper, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	p, ok := f.data.paper(paperID)
+	if !ok {
+		return nil, notFound("GetPaper", "Paper with id %s not found", paperID)
+	}
+	return &p, nil
+}
+
+// GetPapersBatch returns the papers with ids, with zero-valued papers for
+// unknown IDs.
+func (f *Fake) GetPapersBatch(ids []string, fields string) ([]semscholar.Paper, error) {
+	return f.GetPapersBatchContext(context.Background(), ids, fields)
+}
+
+// GetPapersBatchContext is like GetPapersBatch but fails once ctx is done.
+func (f *Fake) GetPapersBatchContext(ctx context.Context, ids []string, fields string) ([]semscholar.Paper, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	papers := make([]semscholar.Paper, len(ids))
+	for i, id := range ids {
+		papers[i], _ = f.data.paper(id)
+	}
+	return papers, nil
+}
+
+// GetPapersBatchSeq is like GetPapersBatchContext but yields the papers one by one.
+func (f *Fake) GetPapersBatchSeq(ctx context.Context, ids []string, fields string) iter.Seq2[semscholar.Paper, error] {
+	return func(yield func(semscholar.Paper, error) bool) {
+		papers, err := f.GetPapersBatchContext(ctx, ids, fields)
+		if err != nil {
+			yield(semscholar.Paper{}, err)
+			return
+		}
+		for _, p := range papers {
+			if !yield(p, nil) {
+				return
+			}
+		}
+	}
+}
+
+// AutocompletePaper returns the papers whose title contains query, ignoring
+// case, most cited first.
+func (f *Fake) AutocompletePaper(query string) ([]semscholar.Paper, error) {
+	return f.AutocompletePaperContext(context.Background(), query)
+}
+
+// AutocompletePaperContext is like AutocompletePaper but fails once ctx is done.
+func (f *Fake) AutocompletePaperContext(ctx context.Context, query string) ([]semscholar.Paper, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	query = strings.ToLower(query)
+	return f.data.sortedPapers(func(p semscholar.Paper) bool {
+		return query != "" && strings.Contains(strings.ToLower(p.Title), query)
+	}), nil
+}
+
+// SnippetSearch returns up to limit sentences of the titles and abstracts
+// of the papers passing filters that contain words of query, those with the
+// most first.
+func (f *Fake) SnippetSearch(ctx context.Context, query string, limit int, filters map[string]string) (*semscholar.SnippetSearchResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	matches := f.data.searchSnippets(query, filters)
+	return &semscholar.SnippetSearchResponse{Data: matches[:min(max(limit, 0), len(matches))]}, nil
+}
+
+// SearchPapers returns a page of the papers whose title or abstract contains
+// every word of query, ignoring case, that pass filters, most cited first.
+func (f *Fake) SearchPapers(query string, offset, limit int, fields string, filters map[string]string) (*semscholar.PaperSearchResponse, error) {
+	return f.SearchPapersContext(context.Background(), query, offset, limit, fields, filters)
+}
+
+// SearchPapersContext is like SearchPapers but fails once ctx is done.
+func (f *Fake) SearchPapersContext(ctx context.Context, query string, offset, limit int, fields string, filters map[string]string) (*semscholar.PaperSearchResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	papers := f.data.searchPapers(query, filters)
+	data, next := page(papers, offset, limit)
+	return &semscholar.PaperSearchResponse{Total: len(papers), Offset: offset, Next: next, Data: data}, nil
+}
+
+// BulkSearchPapers returns the page of matching papers at token, as
+// SearchPapers matches them, sorted by sort if given.
+func (f *Fake) BulkSearchPapers(query, token, fields, sort, publicationTypes string, additionalFilters map[string]string) (*semscholar.PaperSearchResponse, error) {
+	return f.BulkSearchPapersContext(context.Background(), query, token, fields, sort, publicationTypes, additionalFilters)
+}
+
+// BulkSearchPapersContext is like BulkSearchPapers but fails once ctx is done.
+func (f *Fake) BulkSearchPapersContext(ctx context.Context, query, token, fields, sort, publicationTypes string, additionalFilters map[string]string) (*semscholar.PaperSearchResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	offset := 0
+	if token != "" {
+		n, err := strconv.Atoi(token)
+		if err != nil {
+			return nil, &semscholar.APIError{Op: "BulkSearchPapers", StatusCode: http.StatusBadRequest, Body: `{"error":"Invalid token"}`}
+		}
+		offset = n
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	papers := f.data.searchPapers(query, additionalFilters)
+	if field, order, _ := strings.Cut(sort, ":"); field != "" {
+		sortPapers(papers, field, order == "desc")
+	}
+	data, next := page(papers, offset, f.bulkPageSize)
+	resp := &semscholar.PaperSearchResponse{Total: len(papers), Data: data}
+	if next != 0 {
+		resp.Token = strconv.Itoa(next)
+	}
+	return resp, nil
+}
+
+// BulkSearchPapersSeq iterates over all papers BulkSearchPapers matches.
+func (f *Fake) BulkSearchPapersSeq(ctx context.Context, query, fields, sort, publicationTypes string, additionalFilters map[string]string) iter.Seq2[semscholar.Paper, error] {
+	return func(yield func(semscholar.Paper, error) bool) {
+		token := ""
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(semscholar.Paper{}, err)
+				return
+			}
+			page, err := f.BulkSearchPapersContext(ctx, query, token, fields, sort, publicationTypes, additionalFilters)
+			if err != nil {
+				yield(semscholar.Paper{}, err)
+				return
+			}
+			for _, p := range page.Data {
+				if !yield(p, nil) {
+					return
+				}
+			}
+			if page.Token == "" {
+				return
+			}
+			token = page.Token
+		}
+	}
+}
+
+// MatchSearchPapers returns the paper whose title best matches query, failing
+// with a 404 if none does.
+func (f *Fake) MatchSearchPapers(query, fields, publicationTypes string, additionalFilters map[string]string) (*semscholar.PaperSearchResponse, error) {
+	return f.MatchSearchPapersContext(context.Background(), query, fields, publicationTypes, additionalFilters)
+}
+
+// MatchSearchPapersContext is like MatchSearchPapers but fails once ctx is done.
+func (f *Fake) MatchSearchPapersContext(ctx context.Context, query, fields, publicationTypes string, additionalFilters map[string]string) (*semscholar.PaperSearchResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	p, ok := f.data.match(query, additionalFilters)
+	if !ok {
+		return nil, notFound("MatchSearchPapers", "Title match not found")
+	}
+	return &semscholar.PaperSearchResponse{Data: []semscholar.Paper{p}}, nil
+}
+
+// GetPaperCitations returns a page of the papers citing paperID.
+func (f *Fake) GetPaperCitations(paperID string, offset, limit int, fields string) (*semscholar.CitationsResponse, error) {
+	return f.GetPaperCitationsContext(context.Background(), paperID, offset, limit, fields)
+}
+
+// GetPaperCitationsContext is like GetPaperCitations but fails once ctx is done.
+func (f *Fake) GetPaperCitationsContext(ctx context.Context, paperID string, offset, limit int, fields string) (*semscholar.CitationsResponse, error) {
+	return f.citationEdges(ctx, "GetPaperCitations", (*Dataset).citations, paperID, offset, limit)
+}
+
+// GetPaperCitationsSeq iterates over all papers citing paperID.
+func (f *Fake) GetPaperCitationsSeq(ctx context.Context, paperID, fields string) iter.Seq2[semscholar.Citation, error] {
+	return f.citationEdgesSeq(ctx, "GetPaperCitations", (*Dataset).citations, paperID)
+}
+
+// GetPaperReferences returns a page of the papers paperID cites.
+func (f *Fake) GetPaperReferences(paperID string, offset, limit int, fields string) (*semscholar.CitationsResponse, error) {
+	return f.GetPaperReferencesContext(context.Background(), paperID, offset, limit, fields)
+}
+
+// GetPaperReferencesContext is like GetPaperReferences but fails once ctx is done.
+func (f *Fake) GetPaperReferencesContext(ctx context.Context, paperID string, offset, limit int, fields string) (*semscholar.CitationsResponse, error) {
+	return f.citationEdges(ctx, "GetPaperReferences", (*Dataset).references, paperID, offset, limit)
+}
+
+// GetPaperReferencesSeq iterates over all papers paperID cites.
+func (f *Fake) GetPaperReferencesSeq(ctx context.Context, paperID, fields string) iter.Seq2[semscholar.Citation, error] {
+	return f.citationEdgesSeq(ctx, "GetPaperReferences", (*Dataset).references, paperID)
+}
+
+// allEdges returns all edges of paperID, as listed by edges.
+func (f *Fake) allEdges(ctx context.Context, op string, edges func(*Dataset, string) []semscholar.Citation, paperID string) ([]semscholar.Citation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	p, ok := f.data.paper(paperID)
+	if !ok {
+		return nil, notFound(op, "Paper with id %s not found", paperID)
+	}
+	return edges(f.data, p.PaperID), nil
+}
+
+func (f *Fake) citationEdges(ctx context.Context, op string, edges func(*Dataset, string) []semscholar.Citation, paperID string, offset, limit int) (*semscholar.CitationsResponse, error) {
+	all, err := f.allEdges(ctx, op, edges, paperID)
+	if err != nil {
+		return nil, err
+	}
+	data, next := page(all, offset, limit)
+	return &semscholar.CitationsResponse{Offset: offset, Next: next, Data: data}, nil
+}
+
+func (f *Fake) citationEdgesSeq(ctx context.Context, op string, edges func(*Dataset, string) []semscholar.Citation, paperID string) iter.Seq2[semscholar.Citation, error] {
+	return func(yield func(semscholar.Citation, error) bool) {
+		all, err := f.allEdges(ctx, op, edges, paperID)
+		if err != nil {
+			yield(semscholar.Citation{}, err)
+			return
+		}
+		for _, e := range all {
+			if !yield(e, nil) {
+				return
+			}
+		}
+	}
+}
+
+// GetAuthor returns the author with authorID, with their paper count and
+// h-index computed from the dataset unless set.
+func (f *Fake) GetAuthor(authorID, fields string) (*semscholar.Author, error) {
+	return f.GetAuthorContext(context.Background(), authorID, fields)
+}
+
+// GetAuthorContext is like GetAuthor but fails once ctx is done.
+func (f *Fake) GetAuthorContext(ctx context.Context, authorID, fields string) (*semscholar.Author, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	a, ok := f.data.author(authorID)
+	if !ok {
+		return nil, notFound("GetAuthor", "Author with id %s not found", authorID)
+	}
+	return &a, nil
+}
+
+// GetAuthorsBatch returns the authors with ids, with zero-valued authors for
+// unknown IDs.
+func (f *Fake) GetAuthorsBatch(ids []string, fields string) ([]semscholar.Author, error) {
+	return f.GetAuthorsBatchContext(context.Background(), ids, fields)
+}
+
+// GetAuthorsBatchContext is like GetAuthorsBatch but fails once ctx is done.
+func (f *Fake) GetAuthorsBatchContext(ctx context.Context, ids []string, fields string) ([]semscholar.Author, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	authors := make([]semscholar.Author, len(ids))
+	for i, id := range ids {
+		authors[i], _ = f.data.author(id)
+	}
+	return authors, nil
+}
+
+// SearchAuthors returns a page of the authors whose name contains query,
+// ignoring case.
+func (f *Fake) SearchAuthors(query string, offset, limit int, fields string) (*semscholar.AuthorSearchResponse, error) {
+	return f.SearchAuthorsContext(context.Background(), query, offset, limit, fields)
+}
+
+// SearchAuthorsContext is like SearchAuthors but fails once ctx is done.
+func (f *Fake) SearchAuthorsContext(ctx context.Context, query string, offset, limit int, fields string) (*semscholar.AuthorSearchResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var authors []semscholar.Author
+	for _, a := range f.data.searchAuthors(query) {
+		a, _ = f.data.author(a.AuthorID)
+		authors = append(authors, a)
+	}
+	data, next := page(authors, offset, limit)
+	return &semscholar.AuthorSearchResponse{Total: len(authors), Offset: offset, Next: next, Data: data}, nil
+}
+
+// GetAuthorPapers returns a page of the papers listing authorID, most cited first.
+func (f *Fake) GetAuthorPapers(authorID string, offset, limit int, fields string) (*semscholar.AuthorPapersResponse, error) {
+	return f.GetAuthorPapersContext(context.Background(), authorID, offset, limit, fields)
+}
+
+// GetAuthorPapersContext is like GetAuthorPapers but fails once ctx is done.
+func (f *Fake) GetAuthorPapersContext(ctx context.Context, authorID string, offset, limit int, fields string) (*semscholar.AuthorPapersResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if _, ok := f.data.Authors[authorID]; !ok {
+		return nil, notFound("GetAuthorPapers", "Author with id %s not found", authorID)
+	}
+	data, next := page(f.data.authorPapers(authorID), offset, limit)
+	return &semscholar.AuthorPapersResponse{Offset: offset, Next: next, Data: data}, nil
+}
+
+// GetAuthorPapersSeq iterates over all papers of authorID.
+func (f *Fake) GetAuthorPapersSeq(ctx context.Context, authorID, fields string) iter.Seq2[semscholar.Paper, error] {
+	return func(yield func(semscholar.Paper, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(semscholar.Paper{}, err)
+			return
+		}
+		f.mu.RLock()
+		_, ok := f.data.Authors[authorID]
+		papers := f.data.authorPapers(authorID)
+		f.mu.RUnlock()
+		if !ok {
+			yield(semscholar.Paper{}, notFound("GetAuthorPapers", "Author with id %s not found", authorID))
+			return
+		}
+		for _, p := range papers {
+			if !yield(p, nil) {
+				return
+			}
+		}
+	}
+}
+
+// GetRecommendations returns up to limit papers sharing a field of study with
+// the positive papers, excluding the seeds.
+func (f *Fake) GetRecommendations(reqData semscholar.RecommendationRequest, limit int, fields string) (*semscholar.RecommendationResponse, error) {
+	return f.GetRecommendationsContext(context.Background(), reqData, limit, fields)
+}
+
+// GetRecommendationsContext is like GetRecommendations but fails once ctx is done.
+func (f *Fake) GetRecommendationsContext(ctx context.Context, reqData semscholar.RecommendationRequest, limit int, fields string) (*semscholar.RecommendationResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	papers, _ := page(f.data.recommend(reqData.Positive, reqData.Negative), 0, limit)
+	return &semscholar.RecommendationResponse{RecommendedPapers: papers}, nil
+}
+
+// GetRecommendationsForPaper is like GetRecommendations with paperID as the
+// only positive paper. The from pool is ignored.
+func (f *Fake) GetRecommendationsForPaper(paperID, from string, limit int, fields string) (*semscholar.RecommendationResponse, error) {
+	return f.GetRecommendationsForPaperContext(context.Background(), paperID, from, limit, fields)
+}
+
+// GetRecommendationsForPaperContext is like GetRecommendationsForPaper but fails once ctx is done.
+func (f *Fake) GetRecommendationsForPaperContext(ctx context.Context, paperID, from string, limit int, fields string) (*semscholar.RecommendationResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	_, ok := f.data.paper(paperID)
+	f.mu.RUnlock()
+	if !ok {
+		return nil, notFound("GetRecommendationsForPaper", "Paper with id %s not found", paperID)
+	}
+	return f.GetRecommendationsContext(ctx, semscholar.RecommendationRequest{Positive: []string{paperID}}, limit, fields)
+}
+
+// GetReleases returns the release IDs in ascending order.
+func (f *Fake) GetReleases() ([]string, error) {
+	return f.GetReleasesContext(context.Background())
+}
+
+// GetReleasesContext is like GetReleases but fails once ctx is done.
+func (f *Fake) GetReleasesContext(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.data.releaseIDs(), nil
+}
+
+// GetRelease returns the release with releaseID, which may be "latest".
+func (f *Fake) GetRelease(releaseID string) (*semscholar.ReleaseMetadata, error) {
+	return f.GetReleaseContext(context.Background(), releaseID)
+}
+
+// GetReleaseContext is like GetRelease but fails once ctx is done.
+func (f *Fake) GetReleaseContext(ctx context.Context, releaseID string) (*semscholar.ReleaseMetadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	rel, ok := f.data.Releases[f.data.resolveRelease(releaseID)]
+	if !ok {
+		return nil, notFound("GetRelease", "Release %s not found", releaseID)
+	}
+	return &rel, nil
+}
+
+// GetDataset returns a dataset of a release. Its files are the bare file
+// names from Dataset.Files rather than download URLs.
+func (f *Fake) GetDataset(releaseID, datasetName string) (*semscholar.DatasetMetadata, error) {
+	return f.GetDatasetContext(context.Background(), releaseID, datasetName)
+}
+
+// GetDatasetContext is like GetDataset but fails once ctx is done.
+func (f *Fake) GetDatasetContext(ctx context.Context, releaseID, datasetName string) (*semscholar.DatasetMetadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	id := f.data.resolveRelease(releaseID)
+	rel, ok := f.data.Releases[id]
+	if !ok {
+		return nil, notFound("GetDataset", "Release %s not found", releaseID)
+	}
+	for _, ds := range rel.Datasets {
+		if ds.Name == datasetName {
+			files := append([]string(nil), f.data.Files[id+"/"+ds.Name]...)
+			return &semscholar.DatasetMetadata{Name: ds.Name, Description: ds.Description, README: ds.README, Files: files}, nil
+		}
+	}
+	return nil, notFound("GetDataset", "Dataset %s not found in release %s", datasetName, id)
+}
+
+// GetDatasetDiffs lists one diff per pair of consecutive releases from
+// startReleaseID to endReleaseID, each with one update and one delete file.
+func (f *Fake) GetDatasetDiffs(startReleaseID, endReleaseID, datasetName string) (*semscholar.DatasetDiffList, error) {
+	return f.GetDatasetDiffsContext(context.Background(), startReleaseID, endReleaseID, datasetName)
+}
+
+// GetDatasetDiffsContext is like GetDatasetDiffs but fails once ctx is done.
+func (f *Fake) GetDatasetDiffsContext(ctx context.Context, startReleaseID, endReleaseID, datasetName string) (*semscholar.DatasetDiffList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	ids, ok := f.data.releaseRange(startReleaseID, endReleaseID)
+	if !ok {
+		return nil, notFound("GetDatasetDiffs", "No diffs from %s to %s", startReleaseID, endReleaseID)
+	}
+	list := &semscholar.DatasetDiffList{Dataset: datasetName, StartRelease: ids[0], EndRelease: ids[len(ids)-1], Diffs: []semscholar.DatasetDiff{}}
+	for i := 1; i < len(ids); i++ {
+		list.Diffs = append(list.Diffs, semscholar.DatasetDiff{
+			FromRelease: ids[i-1],
+			ToRelease:   ids[i],
+			UpdateFiles: []string{"diff-updates.jsonl.gz"},
+			DeleteFiles: []string{"diff-deletes.jsonl.gz"},
+		})
+	}
+	return list, nil
+}
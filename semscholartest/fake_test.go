@@ -0,0 +1,102 @@
+package semscholartest_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// seededFake returns a Fake over three papers on graphs, the last citing
+// the first two.
+func seededFake() *semscholartest.Fake {
+	d := semscholartest.NewDataset()
+	ada := semscholar.Author{AuthorID: "a1", Name: "Ada Lovelace"}
+	d.AddPaper(semscholar.Paper{PaperID: "p1", Title: "Graph neural networks", CitationCount: semscholar.NewNullInt(30), Authors: []semscholar.Author{ada}, ExternalIDs: semscholar.ExternalIDs{DOI: "10.1000/gnn"}})
+	d.AddPaper(semscholar.Paper{PaperID: "p2", Title: "Random graphs", CitationCount: semscholar.NewNullInt(20), Authors: []semscholar.Author{ada}})
+	d.AddPaper(semscholar.Paper{PaperID: "p3", Title: "Graph attention", CitationCount: semscholar.NewNullInt(10)})
+	d.AddCitation("p3", "p1")
+	d.AddCitation("p3", "p2")
+	return semscholartest.NewFake(d)
+}
+
+// TestFakePapers checks that a Fake serves the papers it was seeded with,
+// by ID and by external ID, and fails for unknown IDs as the client does.
+func TestFakePapers(t *testing.T) {
+	f := seededFake()
+	for _, id := range []string{"p1", "DOI:10.1000/GNN"} {
+		p, err := f.GetPaper(id, "")
+		if err != nil || p.PaperID != "p1" {
+			t.Errorf("GetPaper(%s) = %+v, %v, want p1", id, p, err)
+		}
+	}
+	_, err := f.GetPaper("p9", "")
+	var apiErr *semscholar.APIError
+	if !errors.Is(err, semscholar.ErrNotFound) || !errors.As(err, &apiErr) || apiErr.Op != "GetPaper" {
+		t.Errorf("GetPaper(p9) = %v, want a 404 APIError from GetPaper", err)
+	}
+	if _, err := f.GetAuthor("a9", ""); !errors.Is(err, semscholar.ErrNotFound) {
+		t.Errorf("GetAuthor(a9) = %v, want ErrNotFound", err)
+	}
+
+	papers, err := f.GetPapersBatch([]string{"p2", "p9", "p1"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := []string{papers[0].PaperID, papers[1].PaperID, papers[2].PaperID}; !slices.Equal(got, []string{"p2", "", "p1"}) {
+		t.Errorf("GetPapersBatch = %q, want p2, a zero paper and p1", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.GetPaperContext(ctx, "p1", ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetPaperContext with a canceled context = %v, want Canceled", err)
+	}
+}
+
+// TestFakeSearch checks that a Fake searches titles by substring, most cited
+// first, and pages the results.
+func TestFakeSearch(t *testing.T) {
+	f := seededFake()
+	resp, err := f.SearchPapers("graph", 1, 1, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 3 || resp.Offset != 1 || resp.Next != 2 || len(resp.Data) != 1 || resp.Data[0].PaperID != "p2" {
+		t.Errorf("SearchPapers = %+v, want p2 as the second of 3", resp)
+	}
+	resp, err = f.SearchPapers("attention", 0, 10, "", nil)
+	if err != nil || resp.Total != 1 || resp.Next != 0 {
+		t.Errorf("SearchPapers(attention) = %+v, %v, want p3 alone", resp, err)
+	}
+}
+
+// TestFakeGraph checks that a Fake serves the citations and authors its
+// papers imply.
+func TestFakeGraph(t *testing.T) {
+	f := seededFake()
+	refs, err := f.GetPaperReferences("p3", 0, 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs.Data) != 2 || refs.Data[0].CitedPaper.PaperID != "p1" || refs.Data[1].CitedPaper.PaperID != "p2" {
+		t.Errorf("references of p3 = %+v, want p1 and p2", refs.Data)
+	}
+	cites, err := f.GetPaperCitations("p1", 0, 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cites.Data) != 1 || cites.Data[0].CitingPaper.PaperID != "p3" {
+		t.Errorf("citations of p1 = %+v, want p3", cites.Data)
+	}
+	a, err := f.GetAuthor("a1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Name != "Ada Lovelace" || a.PaperCount.Int != 2 || a.HIndex.Int != 2 {
+		t.Errorf("GetAuthor(a1) = %+v, want Ada Lovelace with 2 papers and an h-index of 2", a)
+	}
+}
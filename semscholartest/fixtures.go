@@ -0,0 +1,184 @@
+package semscholartest
+
+import (
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// Fixture paper IDs, for use in tests against Fixtures.
+const (
+	AttentionPaperID = "204e3073870fae3d05bcbc2f6a8e263d9b72e776"
+	BERTPaperID      = "df2b0e26d0599ce3e70df8a9da02e51594e0e992"
+	GPT3PaperID      = "90abbc2cf38462b954ae1b772fac9532e2ccd8b0"
+	ResNetPaperID    = "2c03df8b48bf3fa39054345bafabfeff15bfd11d"
+	AdamPaperID      = "a6cb366736791bcccc5c8639de5a8f9636bf87e8"
+	AlphaFoldPaperID = "dc32a984b651256a8ec282be52310e6bd33d9815"
+)
+
+// Fixture author IDs.
+const (
+	VaswaniAuthorID = "40348417"
+	DevlinAuthorID  = "39172707"
+	HeAuthorID      = "39353098"
+	KingmaAuthorID  = "1726807"
+)
+
+// Fixture release IDs, oldest first.
+var FixtureReleases = []string{"2024-11-05", "2024-11-12", "2024-11-19"}
+
+// Fixtures returns a new Dataset of a handful of well-known papers modeled on
+// their Semantic Scholar records, the citations among them, and three weekly
+// releases of the papers, abstracts and citations datasets. Each call returns
+// a fresh copy that the caller may modify.
+func Fixtures() *Dataset {
+	d := NewDataset()
+	author := func(id, name string) semscholar.Author { return semscholar.Author{AuthorID: id, Name: name} }
+	vaswani := author(VaswaniAuthorID, "Ashish Vaswani")
+	shazeer := author("1846258", "Noam M. Shazeer")
+	parmar := author("3877127", "Niki Parmar")
+	devlin := author(DevlinAuthorID, "Jacob Devlin")
+	chang := author("1744179", "Ming-Wei Chang")
+	toutanova := author("3259253", "Kristina Toutanova")
+	brown := author("31035595", "Tom B. Brown")
+	amodei := author("2698777", "Dario Amodei")
+	he := author(HeAuthorID, "Kaiming He")
+	sun := author("144049426", "Jian Sun")
+	kingma := author(KingmaAuthorID, "Diederik P. Kingma")
+	ba := author("2503659", "Jimmy Ba")
+	jumper := author("40556395", "J. Jumper")
+	hassabis := author("48987704", "D. Hassabis")
+
+	for _, p := range []semscholar.Paper{
+		{
+			PaperID:                  AttentionPaperID,
+			CorpusID:                 13756489,
+			ExternalIDs:              semscholar.ExternalIDs{ArXiv: "1706.03762", DBLP: "conf/nips/VaswaniSPUJGKP17", MAG: "2963403868", CorpusID: 13756489},
+			Title:                    "Attention is All you Need",
+			Abstract:                 "The dominant sequence transduction models are based on complex recurrent or convolutional neural networks in an encoder-decoder configuration. We propose a new simple network architecture, the Transformer, based solely on attention mechanisms, dispensing with recurrence and convolutions entirely.",
+			URL:                      "https://www.semanticscholar.org/paper/" + AttentionPaperID,
+			Venue:                    "Neural Information Processing Systems",
+			Year:                     2017,
+			PublicationDate:          semscholar.NewDate(2017, 6, 12),
+			CitationCount:            semscholar.NewNullInt(105432),
+			InfluentialCitationCount: semscholar.NewNullInt(13179),
+			ReferenceCount:           semscholar.NewNullInt(41),
+			Authors:                  []semscholar.Author{vaswani, shazeer, parmar},
+			FieldsOfStudy:            []string{"Computer Science"},
+			Tldr:                     &semscholar.Tldr{Model: "tldr@v2.0.0", Text: "A new simple network architecture, the Transformer, based solely on attention mechanisms, dispensing with recurrence and convolutions entirely is proposed."},
+		},
+		{
+			PaperID:                  BERTPaperID,
+			CorpusID:                 52967399,
+			ExternalIDs:              semscholar.ExternalIDs{DOI: "10.18653/v1/N19-1423", ArXiv: "1810.04805", ACL: "N19-1423", DBLP: "conf/naacl/DevlinCLT19", MAG: "2963341956", CorpusID: 52967399},
+			Title:                    "BERT: Pre-training of Deep Bidirectional Transformers for Language Understanding",
+			Abstract:                 "We introduce a new language representation model called BERT, which stands for Bidirectional Encoder Representations from Transformers.",
+			URL:                      "https://www.semanticscholar.org/paper/" + BERTPaperID,
+			Venue:                    "North American Chapter of the Association for Computational Linguistics",
+			Year:                     2019,
+			PublicationDate:          semscholar.NewDate(2019, 6, 1),
+			CitationCount:            semscholar.NewNullInt(83213),
+			InfluentialCitationCount: semscholar.NewNullInt(10401),
+			ReferenceCount:           semscholar.NewNullInt(63),
+			Authors:                  []semscholar.Author{devlin, chang, toutanova},
+			FieldsOfStudy:            []string{"Computer Science"},
+			IsOpenAccess:             true,
+			OpenAccessPdf:            map[string]interface{}{"url": "https://aclanthology.org/N19-1423.pdf", "status": "HYBRID"},
+		},
+		{
+			PaperID:                  GPT3PaperID,
+			CorpusID:                 218971783,
+			ExternalIDs:              semscholar.ExternalIDs{ArXiv: "2005.14165", DBLP: "conf/nips/BrownMRSKDNSSAA20", MAG: "3030163527", CorpusID: 218971783},
+			Title:                    "Language Models are Few-Shot Learners",
+			Abstract:                 "Recent work has demonstrated substantial gains on many NLP tasks and benchmarks by pre-training on a large corpus of text followed by fine-tuning on a specific task.",
+			URL:                      "https://www.semanticscholar.org/paper/" + GPT3PaperID,
+			Venue:                    "Neural Information Processing Systems",
+			Year:                     2020,
+			PublicationDate:          semscholar.NewDate(2020, 5, 28),
+			CitationCount:            semscholar.NewNullInt(31876),
+			InfluentialCitationCount: semscholar.NewNullInt(3984),
+			ReferenceCount:           semscholar.NewNullInt(146),
+			Authors:                  []semscholar.Author{brown, amodei},
+			FieldsOfStudy:            []string{"Computer Science"},
+		},
+		{
+			PaperID:                  ResNetPaperID,
+			CorpusID:                 206594692,
+			ExternalIDs:              semscholar.ExternalIDs{DOI: "10.1109/CVPR.2016.90", ArXiv: "1512.03385", DBLP: "conf/cvpr/HeZRS16", MAG: "2194775991", CorpusID: 206594692},
+			Title:                    "Deep Residual Learning for Image Recognition",
+			Abstract:                 "Deeper neural networks are more difficult to train. We present a residual learning framework to ease the training of networks that are substantially deeper than those used previously.",
+			URL:                      "https://www.semanticscholar.org/paper/" + ResNetPaperID,
+			Venue:                    "Computer Vision and Pattern Recognition",
+			Year:                     2015,
+			PublicationDate:          semscholar.NewDate(2015, 12, 10),
+			CitationCount:            semscholar.NewNullInt(168944),
+			InfluentialCitationCount: semscholar.NewNullInt(21118),
+			ReferenceCount:           semscholar.NewNullInt(53),
+			Authors:                  []semscholar.Author{he, sun},
+			FieldsOfStudy:            []string{"Computer Science"},
+			IsOpenAccess:             true,
+			OpenAccessPdf:            map[string]interface{}{"url": "https://arxiv.org/pdf/1512.03385", "status": "GREEN"},
+		},
+		{
+			PaperID:                  AdamPaperID,
+			CorpusID:                 6628106,
+			ExternalIDs:              semscholar.ExternalIDs{ArXiv: "1412.6980", DBLP: "journals/corr/KingmaB14", MAG: "1522301498", CorpusID: 6628106},
+			Title:                    "Adam: A Method for Stochastic Optimization",
+			Abstract:                 "We introduce Adam, an algorithm for first-order gradient-based optimization of stochastic objective functions, based on adaptive estimates of lower-order moments.",
+			URL:                      "https://www.semanticscholar.org/paper/" + AdamPaperID,
+			Venue:                    "International Conference on Learning Representations",
+			Year:                     2014,
+			PublicationDate:          semscholar.NewDate(2014, 12, 22),
+			CitationCount:            semscholar.NewNullInt(142510),
+			InfluentialCitationCount: semscholar.NewNullInt(17813),
+			ReferenceCount:           semscholar.NewNullInt(32),
+			Authors:                  []semscholar.Author{kingma, ba},
+			FieldsOfStudy:            []string{"Computer Science", "Mathematics"},
+			IsOpenAccess:             true,
+			OpenAccessPdf:            map[string]interface{}{"url": "https://arxiv.org/pdf/1412.6980", "status": "GREEN"},
+		},
+		{
+			PaperID:                  AlphaFoldPaperID,
+			CorpusID:                 235959867,
+			ExternalIDs:              semscholar.ExternalIDs{DOI: "10.1038/s41586-021-03819-2", PubMed: "34265844", PubMedCentral: "8371605", MAG: "3177828909", CorpusID: 235959867},
+			Title:                    "Highly accurate protein structure prediction with AlphaFold",
+			Abstract:                 "Proteins are essential to life, and understanding their structure can facilitate a mechanistic understanding of their function.",
+			URL:                      "https://www.semanticscholar.org/paper/" + AlphaFoldPaperID,
+			Venue:                    "Nature",
+			Year:                     2021,
+			PublicationDate:          semscholar.NewDate(2021, 7, 15),
+			CitationCount:            semscholar.NewNullInt(21987),
+			InfluentialCitationCount: semscholar.NewNullInt(2748),
+			ReferenceCount:           semscholar.NewNullInt(84),
+			Authors:                  []semscholar.Author{jumper, hassabis},
+			FieldsOfStudy:            []string{"Biology", "Computer Science", "Medicine"},
+			IsOpenAccess:             true,
+			OpenAccessPdf:            map[string]interface{}{"url": "https://www.nature.com/articles/s41586-021-03819-2.pdf", "status": "HYBRID"},
+		},
+	} {
+		d.AddPaper(p)
+	}
+	d.Authors[VaswaniAuthorID] = semscholar.Author{AuthorID: VaswaniAuthorID, Name: "Ashish Vaswani", Affiliations: []string{"Google Brain"}, URL: "https://www.semanticscholar.org/author/" + VaswaniAuthorID}
+	d.Authors[HeAuthorID] = semscholar.Author{AuthorID: HeAuthorID, Name: "Kaiming He", Affiliations: []string{"MIT"}, URL: "https://www.semanticscholar.org/author/" + HeAuthorID}
+
+	d.AddCitation(AttentionPaperID, ResNetPaperID)
+	d.AddCitation(AttentionPaperID, AdamPaperID)
+	d.AddCitation(BERTPaperID, AttentionPaperID)
+	d.AddCitation(BERTPaperID, AdamPaperID)
+	d.AddCitation(GPT3PaperID, AttentionPaperID)
+	d.AddCitation(GPT3PaperID, BERTPaperID)
+	d.AddCitation(GPT3PaperID, AdamPaperID)
+	d.AddCitation(AlphaFoldPaperID, AttentionPaperID)
+	d.AddCitation(AlphaFoldPaperID, ResNetPaperID)
+
+	datasets := []semscholar.DatasetSummary{
+		{Name: "papers", Description: "Core paper metadata"},
+		{Name: "abstracts", Description: "Paper abstract text, where allowed by publisher"},
+		{Name: "citations", Description: "Instances where the bibliography of one paper references another"},
+	}
+	for _, r := range FixtureReleases {
+		d.Releases[r] = semscholar.ReleaseMetadata{ReleaseID: r, README: "Semantic Scholar Academic Graph release " + r, Datasets: datasets}
+		for _, ds := range datasets {
+			d.Files[r+"/"+ds.Name] = []string{ds.Name + "-part0.jsonl.gz", ds.Name + "-part1.jsonl.gz"}
+		}
+	}
+	return d
+}
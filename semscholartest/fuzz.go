@@ -0,0 +1,334 @@
+package semscholartest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// fuzzStreamLimit bounds how many items FuzzDecoders takes from an iterator,
+// since a fuzzed body can describe an endless sequence of pages.
+const fuzzStreamLimit = 1000
+
+// FuzzDecoders feeds arbitrary response bodies to every endpoint of a Client,
+// with lenient, strict and validating decoding, and to the streaming
+// iterators. A body may make a call fail but must never make it panic or hang.
+// Run it from a fuzz test in your own package, optionally with a custom
+// Unmarshaler:
+//
+//	func FuzzDecoders(f *testing.F) {
+//		semscholartest.FuzzDecoders(f, nil)
+//	}
+//
+// The corpus is seeded with well-formed responses, the golden ones among
+// them, and with the malformed shapes seen from the API in the wild: mistyped
+// values, huge numbers, invalid UTF-8 and deeply nested payloads. Minimizing the larger seeds is
+// slow; -fuzzminimizetime=0 keeps the fuzzer exploring instead.
+func FuzzDecoders(f *testing.F, u semscholar.Unmarshaler) {
+	papers := SyntheticPapers(3)
+	for _, v := range []any{
+		papers,
+		papers[0],
+		papers[0].Authors,
+		semscholar.PaperSearchResponse{Total: 3, Next: 3, Token: "t", Data: papers},
+		semscholar.CitationsResponse{Next: 1, Data: []semscholar.Citation{{CitingPaper: papers[0]}}},
+		semscholar.RecommendationResponse{RecommendedPapers: papers},
+		semscholar.ReleaseMetadata{ReleaseID: "2024-01-01", Datasets: []semscholar.DatasetSummary{{Name: "papers"}}},
+	} {
+		seed, err := json.Marshal(v)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(seed)
+	}
+	for _, name := range GoldenNames() {
+		f.Add(Golden(name))
+	}
+	for _, seed := range []string{
+		``,
+		`null`,
+		`[null,{}]`,
+		`{"data":null,"next":"12","total":"3"}`,
+		`{"paperId":12,"citationCount":"4.0","isOpenAccess":"true","authors":[{"authorId":7,"hIndex":"x"}]}`,
+		`{"paperId":"a","citationCount":1e400,"corpusId":-99999999999999999999999}`,
+		`{"total":18446744073709551616,"offset":-1,"next":-5,"data":[{"paperId":"a"}]}`,
+		"{\"paperId\":\"\xff\xfe\",\"title\":\"\xc3\x28\"}",
+		`{"paperId":"a","openAccessPdf":` + strings.Repeat(`{"a":`, 1000) + `1` + strings.Repeat(`}`, 1000) + `}`,
+		`{"data":[` + strings.Repeat(`[`, 1000) + strings.Repeat(`]`, 1000) + `]}`,
+		`{"token":"t","data":[]}`,
+		`{"offset":0,"next":0,"data":[{}]}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		for _, configure := range []func(*semscholar.Client){
+			func(c *semscholar.Client) {},
+			func(c *semscholar.Client) { c.StrictDecoding = true },
+			func(c *semscholar.Client) { c.Validate = true },
+		} {
+			c := semscholar.NewClient("http://fuzz.invalid", staticClient(body))
+			c.Unmarshaler = u
+			configure(c)
+			callEndpoints(c)
+		}
+		// Each iterator gets one page, after which its context is cancelled,
+		// so a truncated body ends the iteration instead of being retried
+		// with backoff.
+		stream := func() (*semscholar.Client, context.Context) {
+			ctx, cancel := context.WithCancel(context.Background())
+			c := semscholar.NewClient("http://fuzz.invalid", &onceClient{body: body, cancel: cancel})
+			c.Unmarshaler = u
+			return c, ctx
+		}
+		c, ctx := stream()
+		drain(c.BulkSearchPapersSeq(ctx, "q", "", "", "", nil))
+		c, ctx = stream()
+		drain(c.GetPapersBatchSeq(ctx, []string{"a", "b"}, ""))
+		c, ctx = stream()
+		drain(c.GetPaperCitationsSeq(ctx, "a", ""))
+		c, ctx = stream()
+		drain(c.GetPaperReferencesSeq(ctx, "a", ""))
+		c, ctx = stream()
+		drain(c.GetAuthorPapersSeq(ctx, "1", ""))
+	})
+}
+
+// onceClient answers a request with body and then cancels the context of
+// any further ones.
+type onceClient struct {
+	body   []byte
+	cancel context.CancelFunc
+}
+
+func (o *onceClient) Do(req *http.Request) (*http.Response, error) {
+	defer o.cancel()
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	return staticClient(o.body).Do(req)
+}
+
+// callEndpoints calls every endpoint of c, ignoring results and errors.
+func callEndpoints(c *semscholar.Client) {
+	c.GetAuthor("1", "")
+	c.GetAuthorsBatch([]string{"1", "2"}, "")
+	c.SearchAuthors("q", 0, 10, "")
+	c.GetAuthorPapers("1", 0, 10, "")
+	c.GetPaper("a", "")
+	c.AutocompletePaper("q")
+	c.GetPapersBatch([]string{"a", "b"}, "")
+	c.SearchPapers("q", 0, 10, "", nil)
+	c.BulkSearchPapers("q", "", "", "", "", nil)
+	c.MatchSearchPapers("q", "", "", nil)
+	c.GetPaperCitations("a", 0, 10, "")
+	c.GetPaperReferences("a", 0, 10, "")
+	c.GetRecommendations(semscholar.RecommendationRequest{Positive: []string{"a"}}, 10, "")
+	c.GetRecommendationsForPaper("a", "", 10, "")
+	c.GetDatasetDiffs("r1", "r2", "papers")
+	c.GetReleases()
+	c.GetRelease("r1")
+	c.GetDataset("r1", "papers")
+}
+
+// drain consumes up to fuzzStreamLimit items of seq.
+func drain[T any](seq func(func(T, error) bool)) {
+	n := 0
+	for range seq {
+		if n++; n >= fuzzStreamLimit {
+			return
+		}
+	}
+}
+
+// FuzzRequests feeds arbitrary IDs, queries, fields and filters to every
+// endpoint of a Client and checks the requests it builds: each must be sent,
+// and each value must come back unchanged from the path segment or query
+// parameter it was put in, whatever quotes, operators, separators or unicode
+// it holds. Run it from a fuzz test in your own package:
+//
+//	func FuzzRequests(f *testing.F) {
+//		semscholartest.FuzzRequests(f)
+//	}
+//
+// As with FuzzDecoders, -fuzzminimizetime=0 keeps the fuzzer exploring.
+func FuzzRequests(f *testing.F) {
+	for _, seed := range [][5]string{
+		{"649def34f8be52c8b66281af98ae884c09aef38b", "deep learning", "title,authors", "year", "2019-2023"},
+		{"DOI:10.1145/3292500.3330701", `"generative adversarial" + (network | net) -survey`, "authors.name,citations.title", "venue", "Nature,Science"},
+		{"ARXIV:2106.15928", "Schrödinger's cat 量子 🐈", "title", "fieldsOfStudy", "Physics,Computer Science"},
+		{"10.1000/x?y=1#frag", "a&b=c;d", "a, b ,c", "publicationDateOrYear", "2019-03-05:2020-06"},
+		{"%zz/../.", "100% done\\", "", "minCitationCount", "1e3"},
+		{"a", "q", "title", "fields", "authors"},
+		{"", "", "", "", ""},
+		{" ", "\x00\t\n", ",,", "openAccessPdf", ""},
+		{"\xff\xfe", "\xc3\x28", "\xe2\x82", "\xff", "\xfe"},
+	} {
+		f.Add(seed[0], seed[1], seed[2], seed[3], seed[4])
+	}
+
+	f.Fuzz(func(t *testing.T, id, query, fields, key, value string) {
+		for _, msg := range checkRequests(id, query, fields, key, value) {
+			t.Error(msg)
+		}
+	})
+}
+
+// requestCase is an endpoint called with fuzzed or generated arguments, and
+// the path segments, query parameters and body IDs its request must carry.
+type requestCase struct {
+	name  string
+	call  func(c *semscholar.Client) error
+	path  []string
+	query map[string]string
+	ids   []string
+}
+
+// requestCases returns a requestCase for every endpoint that puts caller
+// values into its request.
+func requestCases(id, query, fields, key, value string) []requestCase {
+	filters := map[string]string{key: value}
+	withFilter := func(m map[string]string) map[string]string {
+		switch {
+		case key == "":
+		case !reservedParams[key]:
+			m[key] = value
+		case m[key] == "":
+			// The client may leave the parameter to the filter.
+			delete(m, key)
+		}
+		return m
+	}
+	return []requestCase{
+		{"GetPaper", func(c *semscholar.Client) error { _, err := c.GetPaper(id, fields); return err },
+			[]string{"paper", id}, map[string]string{"fields": fields}, nil},
+		{"GetPaperCitations", func(c *semscholar.Client) error { _, err := c.GetPaperCitations(id, 0, 10, fields); return err },
+			[]string{"paper", id, "citations"}, map[string]string{"fields": fields}, nil},
+		{"GetPaperReferences", func(c *semscholar.Client) error { _, err := c.GetPaperReferences(id, 0, 10, fields); return err },
+			[]string{"paper", id, "references"}, map[string]string{"fields": fields}, nil},
+		{"GetPapersBatch", func(c *semscholar.Client) error { _, err := c.GetPapersBatch([]string{id}, fields); return err },
+			[]string{"paper", "batch"}, map[string]string{"fields": fields}, []string{id}},
+		{"AutocompletePaper", func(c *semscholar.Client) error { _, err := c.AutocompletePaper(query); return err },
+			[]string{"paper", "autocomplete"}, map[string]string{"query": query}, nil},
+		{"SearchPapers", func(c *semscholar.Client) error { _, err := c.SearchPapers(query, 0, 10, fields, filters); return err },
+			[]string{"paper", "search"}, withFilter(map[string]string{"query": query, "fields": fields}), nil},
+		{"BulkSearchPapers", func(c *semscholar.Client) error {
+			_, err := c.BulkSearchPapers(query, id, fields, "", "", filters)
+			return err
+		}, []string{"paper", "search", "bulk"}, withFilter(map[string]string{"query": query, "fields": fields, "token": id}), nil},
+		{"MatchSearchPapers", func(c *semscholar.Client) error {
+			_, err := c.MatchSearchPapers(query, fields, "", filters)
+			return err
+		},
+			[]string{"paper", "search", "match"}, withFilter(map[string]string{"query": query, "fields": fields}), nil},
+		{"GetAuthor", func(c *semscholar.Client) error { _, err := c.GetAuthor(id, fields); return err },
+			[]string{"author", id}, map[string]string{"fields": fields}, nil},
+		{"GetAuthorPapers", func(c *semscholar.Client) error { _, err := c.GetAuthorPapers(id, 0, 10, fields); return err },
+			[]string{"author", id, "papers"}, map[string]string{"fields": fields}, nil},
+		{"GetAuthorsBatch", func(c *semscholar.Client) error { _, err := c.GetAuthorsBatch([]string{id}, fields); return err },
+			[]string{"author", "batch"}, map[string]string{"fields": fields}, []string{id}},
+		{"SearchAuthors", func(c *semscholar.Client) error { _, err := c.SearchAuthors(query, 0, 10, fields); return err },
+			[]string{"author", "search"}, map[string]string{"query": query, "fields": fields}, nil},
+		{"GetRecommendationsForPaper", func(c *semscholar.Client) error {
+			_, err := c.GetRecommendationsForPaper(id, query, 10, fields)
+			return err
+		}, []string{"papers", "forpaper", id}, map[string]string{"from": query, "fields": fields}, nil},
+		{"GetRelease", func(c *semscholar.Client) error { _, err := c.GetRelease(id); return err },
+			[]string{"release", id}, nil, nil},
+		{"GetDataset", func(c *semscholar.Client) error { _, err := c.GetDataset(id, query); return err },
+			[]string{"release", id, "dataset", query}, nil, nil},
+		{"GetDatasetDiffs", func(c *semscholar.Client) error { _, err := c.GetDatasetDiffs(id, key, query); return err },
+			[]string{"diffs", id, "to", key, query}, nil, nil},
+	}
+}
+
+// checkRequests calls every endpoint with the arguments and returns a
+// description of each request that does not carry them faithfully.
+func checkRequests(id, query, fields, key, value string) []string {
+	var failures []string
+	for _, tc := range requestCases(id, query, fields, key, value) {
+		rec := new(recordingClient)
+		err := tc.call(semscholar.NewClient("http://fuzz.invalid", rec))
+		if rec.req == nil {
+			failures = append(failures, fmt.Sprintf("%s: request not sent: %v", tc.name, err))
+			continue
+		}
+		if msg := checkRequest(rec.req, rec.body, tc.path, tc.query, tc.ids); msg != "" {
+			failures = append(failures, fmt.Sprintf("%s: %s: %s", tc.name, rec.req.URL, msg))
+		}
+	}
+	return failures
+}
+
+// reservedParams are the query parameters the client sets itself, which a
+// filter of the same name must not override or duplicate.
+var reservedParams = map[string]bool{"query": true, "offset": true, "limit": true, "fields": true, "token": true, "sort": true, "publicationTypes": true}
+
+// recordingClient records the request it is sent and answers it with a 404.
+type recordingClient struct {
+	req  *http.Request
+	body []byte
+}
+
+func (r *recordingClient) Do(req *http.Request) (*http.Response, error) {
+	r.req = req
+	if req.Body != nil {
+		r.body, _ = io.ReadAll(req.Body)
+	}
+	return &http.Response{
+		StatusCode: http.StatusNotFound,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(`{"error":"not found"}`)),
+		Request:    req,
+	}, nil
+}
+
+// checkRequest reports how req differs from the path segments, query
+// parameters and body IDs it should carry, or returns "". The URL is checked
+// as it goes over the wire, by parsing its string form.
+func checkRequest(req *http.Request, body []byte, path []string, query map[string]string, ids []string) string {
+	u, err := url.Parse(req.URL.String())
+	if err != nil {
+		return fmt.Sprintf("URL does not parse: %v", err)
+	}
+	var segs []string
+	for _, s := range strings.Split(strings.TrimPrefix(u.EscapedPath(), "/"), "/") {
+		u, err := url.PathUnescape(s)
+		if err != nil {
+			return fmt.Sprintf("segment %q: %v", s, err)
+		}
+		segs = append(segs, u)
+	}
+	if !slices.Equal(segs, path) {
+		return fmt.Sprintf("path segments %q, want %q", segs, path)
+	}
+	got, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return fmt.Sprintf("query: %v", err)
+	}
+	for k, want := range query {
+		if v := got[k]; want == "" && len(v) == 0 {
+			continue
+		} else if len(v) != 1 || v[0] != want {
+			return fmt.Sprintf("parameter %q = %q, want %q", k, v, want)
+		}
+	}
+	if ids != nil && utf8.Valid(body) && !slices.ContainsFunc(ids, func(id string) bool { return !utf8.ValidString(id) }) {
+		var sent struct {
+			IDs []string `json:"ids"`
+		}
+		if err := json.Unmarshal(body, &sent); err != nil || !slices.Equal(sent.IDs, ids) {
+			return fmt.Sprintf("body %s, want ids %q", body, ids)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,784 @@
+package semscholartest
+
+import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// Server is an httptest server mocking the Graph, Recommendations and
+// Datasets APIs over a Dataset, so integration tests of code built on the
+// client run without the live API. It answers every endpoint the client
+// calls, honoring fields, offsets, limits, filters and external IDs the way
+// the API does, and can be made slow or failing. It is safe for concurrent
+// use.
+//
+//	srv := semscholartest.NewServer(nil)
+//	defer srv.Close()
+//	paper, err := srv.Client().GetPaper(semscholartest.BERTPaperID, "title,authors")
+type Server struct {
+	// URL is the base URL of the mock API, for use as Client.BaseURL.
+	URL string
+
+	srv          *httptest.Server
+	mu           sync.Mutex
+	data         *Dataset
+	latency      time.Duration
+	faults       []*Fault
+	requests     []string
+	bulkPageSize int
+}
+
+// Fault makes the Server fail requests.
+type Fault struct {
+	// Path selects the requests to fail by prefix of their path below the
+	// base URL, such as "/paper/batch" or "/author/"; empty selects all.
+	Path string
+	// Status is the status to answer with. Zero closes the connection without
+	// answering, as a failing proxy might.
+	Status int
+	// RetryAfter, if positive, is sent in a Retry-After header.
+	RetryAfter time.Duration
+	// Times is how many requests fail before the fault clears; zero fails
+	// every matching request until ClearFaults.
+	Times int
+}
+
+// NewServer starts a Server over data, or over Fixtures if data is nil. The
+// dataset must not be modified while the server runs; use Update.
+func NewServer(data *Dataset) *Server {
+	if data == nil {
+		data = Fixtures()
+	}
+	s := &Server{data: data, bulkPageSize: 1000}
+	s.srv = httptest.NewServer(s)
+	s.URL = s.srv.URL
+	return s
+}
+
+// Listen starts a Server over data on addr, such as "localhost:8080", so that
+// frontends, notebooks and scripts can be developed against a stable local
+// API with no rate limits. data is as for NewServer.
+func Listen(addr string, data *Dataset) (*Server, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = Fixtures()
+	}
+	s := &Server{data: data, bulkPageSize: 1000}
+	s.srv = httptest.NewUnstartedServer(s)
+	s.srv.Listener.Close()
+	s.srv.Listener = l
+	s.srv.Start()
+	s.URL = s.srv.URL
+	return s, nil
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns a client of the server.
+func (s *Server) Client() *semscholar.Client {
+	return semscholar.NewClient(s.URL, s.srv.Client())
+}
+
+// Update calls fn with the dataset, excluding requests while it runs.
+func (s *Server) Update(fn func(*Dataset)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.data)
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// SetBulkPageSize sets how many papers a bulk search page holds; the API
+// sends 1000. Small pages let tests exercise continuation tokens.
+func (s *Server) SetBulkPageSize(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bulkPageSize = max(n, 1)
+}
+
+// Inject adds a fault. Faults are checked in the order added.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the requests received so far, as "METHOD /path?query".
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+	latency := s.latency
+	fault := s.fault(r.URL.Path)
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if fault != nil {
+		serveFault(w, fault)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status, body := s.route(r)
+	switch b := body.(type) {
+	case []byte:
+		w.Header().Set("Content-Type", "application/octet-stream")
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			w.Write(b)
+			return
+		}
+		// Files are served as S3 serves them: with the MD5 of the content as
+		// ETag, and ranges, so downloads can be checked and resumed.
+		w.Header().Set("ETag", fmt.Sprintf(`"%x"`, md5.Sum(b)))
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(b))
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}
+}
+
+// fault returns the fault to apply to a request for path, if any. s.mu is held.
+func (s *Server) fault(path string) *Fault {
+	for i, f := range s.faults {
+		if !strings.HasPrefix(path, f.Path) {
+			continue
+		}
+		if f.Times > 0 {
+			if f.Times--; f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		cp := *f
+		return &cp
+	}
+	return nil
+}
+
+func serveFault(w http.ResponseWriter, f *Fault) {
+	if f.Status == 0 {
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+		panic(http.ErrAbortHandler)
+	}
+	if f.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int((f.RetryAfter+time.Second-1)/time.Second)))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(f.Status)
+	msg := http.StatusText(f.Status)
+	if f.Status == http.StatusTooManyRequests {
+		msg = "Too Many Requests. Please wait and try again or apply for a key for higher rate limits."
+	}
+	json.NewEncoder(w).Encode(map[string]string{"message": msg, "code": strconv.Itoa(f.Status)})
+}
+
+// apiError is the body the API sends with 4xx responses.
+func apiError(format string, args ...any) map[string]string {
+	return map[string]string{"error": fmt.Sprintf(format, args...)}
+}
+
+// route answers r, returning the status and a value to encode as JSON, or
+// raw bytes. s.mu is held.
+func (s *Server) route(r *http.Request) (int, any) {
+	var seg []string
+	for _, part := range strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/") {
+		p, err := url.PathUnescape(part)
+		if err != nil {
+			return http.StatusBadRequest, apiError("malformed path")
+		}
+		seg = append(seg, p)
+	}
+	q := r.URL.Query()
+	post := r.Method == http.MethodPost
+	switch {
+	case len(seg) == 2 && seg[0] == "paper" && seg[1] == "batch" && post:
+		return s.paperBatch(r, q)
+	case len(seg) == 2 && seg[0] == "paper" && seg[1] == "search":
+		return s.paperSearch(q)
+	case len(seg) == 3 && seg[0] == "paper" && seg[1] == "search" && seg[2] == "bulk":
+		return s.bulkSearch(q)
+	case len(seg) == 3 && seg[0] == "paper" && seg[1] == "search" && seg[2] == "match":
+		return s.matchSearch(q)
+	case len(seg) == 2 && seg[0] == "snippet" && seg[1] == "search":
+		return s.snippetSearch(q)
+	case len(seg) == 2 && seg[0] == "paper" && seg[1] == "autocomplete":
+		return s.autocomplete(q)
+	case len(seg) == 2 && seg[0] == "paper":
+		p, ok := s.data.paper(seg[1])
+		if !ok {
+			return http.StatusNotFound, apiError("Paper with id %s not found", seg[1])
+		}
+		return project(p, q.Get("fields"), paperFields, "paperId", "title")
+	case len(seg) == 3 && seg[0] == "paper" && (seg[2] == "citations" || seg[2] == "references"):
+		return s.citationEdges(seg[1], seg[2], q)
+	case len(seg) == 2 && seg[0] == "author" && seg[1] == "batch" && post:
+		return s.authorBatch(r, q)
+	case len(seg) == 2 && seg[0] == "author" && seg[1] == "search":
+		return s.authorSearch(q)
+	case len(seg) == 2 && seg[0] == "author":
+		a, ok := s.data.author(seg[1])
+		if !ok {
+			return http.StatusNotFound, apiError("Author with id %s not found", seg[1])
+		}
+		if wants(q.Get("fields"), "papers") {
+			a.Papers = s.data.authorPapers(a.AuthorID)
+		}
+		return project(a, q.Get("fields"), authorFields, "authorId", "name")
+	case len(seg) == 3 && seg[0] == "author" && seg[2] == "papers":
+		return s.authorPapers(seg[1], q)
+	case len(seg) == 1 && seg[0] == "papers" && post:
+		return s.recommendations(r, q)
+	case len(seg) == 3 && seg[0] == "papers" && seg[1] == "forpaper":
+		return s.recommendationsForPaper(seg[2], q)
+	case len(seg) >= 1 && seg[0] == "release":
+		return s.release(seg[1:])
+	case len(seg) == 5 && seg[0] == "diffs" && seg[2] == "to":
+		return s.diffs(seg[1], seg[3], seg[4])
+	case len(seg) == 4 && seg[0] == "files":
+		return s.file(seg[1], seg[2], seg[3])
+	}
+	return http.StatusNotFound, map[string]string{"message": "Missing Authentication Token"}
+}
+
+var (
+	paperFields  = jsonNames(reflect.TypeFor[semscholar.Paper]())
+	authorFields = jsonNames(reflect.TypeFor[semscholar.Author]())
+	edgeFields   = jsonNames(reflect.TypeFor[semscholar.Citation]())
+)
+
+// jsonNames returns the JSON names of the fields of struct type t.
+func jsonNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// requested returns the top-level names in a fields parameter: "authors.name"
+// requests "authors".
+func requested(fields string) []string {
+	var names []string
+	for _, f := range strings.Split(fields, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			name, _, _ := strings.Cut(f, ".")
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func wants(fields, name string) bool {
+	for _, f := range requested(fields) {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// project returns v reduced to its ID field, defaults[0], and the requested
+// fields, or defaults if none are requested. Unknown fields are rejected with
+// 400, as the API does.
+func project(v any, fields string, known map[string]bool, defaults ...string) (int, any) {
+	keep, status, msg := keepFields(fields, known, defaults)
+	if status != 0 {
+		return status, msg
+	}
+	return http.StatusOK, reduce(v, keep)
+}
+
+func keepFields(fields string, known map[string]bool, defaults []string) (map[string]bool, int, any) {
+	keep := map[string]bool{defaults[0]: true}
+	names := requested(fields)
+	if len(names) == 0 {
+		names = defaults
+	}
+	var unknown []string
+	for _, n := range names {
+		if !known[n] {
+			unknown = append(unknown, n)
+		}
+		keep[n] = true
+	}
+	if len(unknown) > 0 {
+		return nil, http.StatusBadRequest, apiError("Unrecognized or unsupported fields: [%s]", strings.Join(unknown, ", "))
+	}
+	return keep, 0, nil
+}
+
+// reduce returns the JSON object of v with only the keys in keep.
+func reduce(v any, keep map[string]bool) map[string]any {
+	b, _ := json.Marshal(v)
+	var m map[string]any
+	json.Unmarshal(b, &m)
+	for k := range m {
+		if !keep[k] {
+			delete(m, k)
+		}
+	}
+	for k := range keep {
+		if _, ok := m[k]; !ok {
+			m[k] = nil
+		}
+	}
+	return m
+}
+
+// pageParams parses offset and limit, with limit defaulting to def and
+// bounded by max.
+func pageParams(q url.Values, def, max int) (offset, limit int, status int, msg any) {
+	offset, limit = 0, def
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, http.StatusBadRequest, apiError("Unacceptable query params: [offset=%s]", v)
+		}
+		offset = n
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > max {
+			return 0, 0, http.StatusBadRequest, apiError("Unacceptable query params: [limit=%s]", v)
+		}
+		limit = n
+	}
+	return offset, limit, 0, nil
+}
+
+// filters returns the query parameters other than those listed.
+func filters(q url.Values, except ...string) map[string]string {
+	f := make(map[string]string)
+	for k := range q {
+		f[k] = q.Get(k)
+	}
+	for _, k := range except {
+		delete(f, k)
+	}
+	return f
+}
+
+func (s *Server) paperSearch(q url.Values) (int, any) {
+	offset, limit, status, msg := pageParams(q, 10, 100)
+	if status != 0 {
+		return status, msg
+	}
+	if offset+limit > 1000 {
+		return http.StatusBadRequest, apiError("offset + limit must be < 1000")
+	}
+	keep, status, msg := keepFields(q.Get("fields"), paperFields, []string{"paperId", "title"})
+	if status != 0 {
+		return status, msg
+	}
+	papers := s.data.searchPapers(q.Get("query"), filters(q, "query", "offset", "limit", "fields"))
+	data, next := page(papers, offset, limit)
+	resp := map[string]any{"total": len(papers), "offset": offset, "data": reduceAll(data, keep)}
+	if next != 0 {
+		resp["next"] = next
+	}
+	return http.StatusOK, resp
+}
+
+func reduceAll[T any](items []T, keep map[string]bool) []map[string]any {
+	out := make([]map[string]any, len(items))
+	for i, v := range items {
+		out[i] = reduce(v, keep)
+	}
+	return out
+}
+
+func (s *Server) bulkSearch(q url.Values) (int, any) {
+	keep, status, msg := keepFields(q.Get("fields"), paperFields, []string{"paperId", "title"})
+	if status != 0 {
+		return status, msg
+	}
+	offset := 0
+	if t := q.Get("token"); t != "" {
+		n, err := strconv.Atoi(t)
+		if err != nil {
+			return http.StatusBadRequest, apiError("Invalid token")
+		}
+		offset = n
+	}
+	papers := s.data.searchPapers(q.Get("query"), filters(q, "query", "token", "fields", "sort", "publicationTypes"))
+	if field, order, _ := strings.Cut(q.Get("sort"), ":"); field != "" {
+		sortPapers(papers, field, order == "desc")
+	}
+	data, next := page(papers, offset, s.bulkPageSize)
+	resp := map[string]any{"total": len(papers), "data": reduceAll(data, keep)}
+	if next != 0 {
+		resp["token"] = strconv.Itoa(next)
+	}
+	return http.StatusOK, resp
+}
+
+// sortPapers sorts papers by paperId, publicationDate or citationCount.
+func sortPapers(papers []semscholar.Paper, field string, desc bool) {
+	slices.SortStableFunc(papers, func(a, b semscholar.Paper) int {
+		if desc {
+			a, b = b, a
+		}
+		switch field {
+		case "publicationDate":
+			return a.PublishedDate().Compare(b.PublishedDate())
+		case "citationCount":
+			return cmp.Compare(a.CitationCount.Int, b.CitationCount.Int)
+		}
+		return strings.Compare(a.PaperID, b.PaperID)
+	})
+}
+
+func (s *Server) matchSearch(q url.Values) (int, any) {
+	keep, status, msg := keepFields(q.Get("fields"), paperFields, []string{"paperId", "title"})
+	if status != 0 {
+		return status, msg
+	}
+	best, ok := s.data.match(q.Get("query"), filters(q, "query", "fields", "publicationTypes"))
+	if !ok {
+		return http.StatusNotFound, apiError("Title match not found")
+	}
+	return http.StatusOK, map[string]any{"data": []map[string]any{reduce(best, keep)}}
+}
+
+func (s *Server) snippetSearch(q url.Values) (int, any) {
+	if q.Get("query") == "" {
+		return http.StatusBadRequest, apiError("Missing required parameter: query")
+	}
+	_, limit, status, msg := pageParams(q, 10, semscholar.MaxSnippetLimit)
+	if status != 0 {
+		return status, msg
+	}
+	matches := s.data.searchSnippets(q.Get("query"), filters(q, "query", "limit"))
+	return http.StatusOK, semscholar.SnippetSearchResponse{Data: matches[:min(limit, len(matches))], RetrievalVersion: "mock"}
+}
+
+func (s *Server) autocomplete(q url.Values) (int, any) {
+	query := strings.ToLower(q.Get("query"))
+	keep := map[string]bool{"paperId": true, "title": true}
+	var matches []map[string]any
+	for _, p := range s.data.sortedPapers(func(p semscholar.Paper) bool {
+		return query != "" && strings.Contains(strings.ToLower(p.Title), query)
+	}) {
+		matches = append(matches, reduce(p, keep))
+	}
+	if matches == nil {
+		matches = []map[string]any{}
+	}
+	return http.StatusOK, matches
+}
+
+func (s *Server) paperBatch(r *http.Request, q url.Values) (int, any) {
+	var body semscholar.PaperBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return http.StatusBadRequest, apiError("Body must be a JSON object with an ids array")
+	}
+	if len(body.IDs) > 500 {
+		return http.StatusBadRequest, apiError("Cannot process more than 500 ids")
+	}
+	keep, status, msg := keepFields(q.Get("fields"), paperFields, []string{"paperId", "title"})
+	if status != 0 {
+		return status, msg
+	}
+	out := make([]any, len(body.IDs))
+	for i, id := range body.IDs {
+		if p, ok := s.data.paper(id); ok {
+			out[i] = reduce(p, keep)
+		}
+	}
+	return http.StatusOK, out
+}
+
+func (s *Server) citationEdges(id, kind string, q url.Values) (int, any) {
+	p, ok := s.data.paper(id)
+	if !ok {
+		return http.StatusNotFound, apiError("Paper with id %s not found", id)
+	}
+	offset, limit, status, msg := pageParams(q, 100, 1000)
+	if status != 0 {
+		return status, msg
+	}
+	edges := s.data.citations(p.PaperID)
+	nested := "citingPaper"
+	if kind == "references" {
+		edges = s.data.references(p.PaperID)
+		nested = "citedPaper"
+	}
+	known := make(map[string]bool)
+	for k := range paperFields {
+		known[k] = true
+	}
+	for k := range edgeFields {
+		known[k] = true
+	}
+	keep, status, msg := keepFields(q.Get("fields"), known, []string{"paperId", "title"})
+	if status != 0 {
+		return status, msg
+	}
+	// Edge fields apply to the edge, the rest to the paper nested in it.
+	edgeKeep, paperKeep := make(map[string]bool), make(map[string]bool)
+	for k := range keep {
+		if edgeFields[k] {
+			edgeKeep[k] = true
+		} else {
+			paperKeep[k] = true
+		}
+	}
+	data, next := page(edges, offset, limit)
+	out := make([]map[string]any, len(data))
+	for i, e := range data {
+		paper := e.CitingPaper
+		if kind == "references" {
+			paper = e.CitedPaper
+		}
+		edge := reduce(e, edgeKeep)
+		reduced := reduce(paper, paperKeep)
+		if paper.PaperID == "" {
+			reduced["paperId"] = nil
+		}
+		edge[nested] = reduced
+		out[i] = edge
+	}
+	resp := map[string]any{"offset": offset, "data": out}
+	if next != 0 {
+		resp["next"] = next
+	}
+	return http.StatusOK, resp
+}
+
+func (s *Server) authorBatch(r *http.Request, q url.Values) (int, any) {
+	var body semscholar.AuthorBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return http.StatusBadRequest, apiError("Body must be a JSON object with an ids array")
+	}
+	if len(body.IDs) > 1000 {
+		return http.StatusBadRequest, apiError("Cannot process more than 1000 ids")
+	}
+	keep, status, msg := keepFields(q.Get("fields"), authorFields, []string{"authorId", "name"})
+	if status != 0 {
+		return status, msg
+	}
+	out := make([]any, len(body.IDs))
+	for i, id := range body.IDs {
+		if a, ok := s.data.author(id); ok {
+			out[i] = reduce(a, keep)
+		}
+	}
+	return http.StatusOK, out
+}
+
+func (s *Server) authorSearch(q url.Values) (int, any) {
+	offset, limit, status, msg := pageParams(q, 100, 1000)
+	if status != 0 {
+		return status, msg
+	}
+	keep, status, msg := keepFields(q.Get("fields"), authorFields, []string{"authorId", "name"})
+	if status != 0 {
+		return status, msg
+	}
+	var authors []semscholar.Author
+	for _, a := range s.data.searchAuthors(q.Get("query")) {
+		a, _ = s.data.author(a.AuthorID)
+		authors = append(authors, a)
+	}
+	data, next := page(authors, offset, limit)
+	resp := map[string]any{"total": len(authors), "offset": offset, "data": reduceAll(data, keep)}
+	if next != 0 {
+		resp["next"] = next
+	}
+	return http.StatusOK, resp
+}
+
+func (s *Server) authorPapers(id string, q url.Values) (int, any) {
+	if _, ok := s.data.Authors[id]; !ok {
+		return http.StatusNotFound, apiError("Author with id %s not found", id)
+	}
+	offset, limit, status, msg := pageParams(q, 100, 1000)
+	if status != 0 {
+		return status, msg
+	}
+	keep, status, msg := keepFields(q.Get("fields"), paperFields, []string{"paperId", "title"})
+	if status != 0 {
+		return status, msg
+	}
+	papers := s.data.authorPapers(id)
+	data, next := page(papers, offset, limit)
+	resp := map[string]any{"offset": offset, "data": reduceAll(data, keep)}
+	if next != 0 {
+		resp["next"] = next
+	}
+	return http.StatusOK, resp
+}
+
+func (s *Server) recommendations(r *http.Request, q url.Values) (int, any) {
+	var body semscholar.RecommendationRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Positive) == 0 {
+		return http.StatusBadRequest, apiError("Body must be a JSON object with a non-empty positivePaperIds array")
+	}
+	return s.recommend(body.Positive, body.Negative, q)
+}
+
+func (s *Server) recommendationsForPaper(id string, q url.Values) (int, any) {
+	if _, ok := s.data.paper(id); !ok {
+		return http.StatusNotFound, apiError("Paper with id %s not found", id)
+	}
+	return s.recommend([]string{id}, nil, q)
+}
+
+func (s *Server) recommend(positive, negative []string, q url.Values) (int, any) {
+	_, limit, status, msg := pageParams(q, 100, 500)
+	if status != 0 {
+		return status, msg
+	}
+	keep, status, msg := keepFields(q.Get("fields"), paperFields, []string{"paperId", "title"})
+	if status != 0 {
+		return status, msg
+	}
+	papers, _ := page(s.data.recommend(positive, negative), 0, limit)
+	return http.StatusOK, map[string]any{"recommendedPapers": reduceAll(papers, keep)}
+}
+
+func (s *Server) release(seg []string) (int, any) {
+	if len(seg) == 0 || seg[0] == "" {
+		return http.StatusOK, s.data.releaseIDs()
+	}
+	id := s.data.resolveRelease(seg[0])
+	rel, ok := s.data.Releases[id]
+	if !ok {
+		return http.StatusNotFound, apiError("Release %s not found", seg[0])
+	}
+	switch {
+	case len(seg) == 1:
+		return http.StatusOK, rel
+	case len(seg) == 3 && seg[1] == "dataset":
+		for _, ds := range rel.Datasets {
+			if ds.Name != seg[2] {
+				continue
+			}
+			meta := semscholar.DatasetMetadata{Name: ds.Name, Description: ds.Description, README: ds.README}
+			for _, f := range s.data.Files[id+"/"+ds.Name] {
+				meta.Files = append(meta.Files, s.fileURL(id, ds.Name, f))
+			}
+			return http.StatusOK, meta
+		}
+		return http.StatusNotFound, apiError("Dataset %s not found in release %s", seg[2], id)
+	}
+	return http.StatusNotFound, map[string]string{"message": "Missing Authentication Token"}
+}
+
+func (s *Server) fileURL(release, dataset, file string) string {
+	return fmt.Sprintf("%s/files/%s/%s/%s", s.URL, url.PathEscape(release), url.PathEscape(dataset), url.PathEscape(file))
+}
+
+// diffs lists one diff per pair of consecutive releases from start to end.
+func (s *Server) diffs(start, end, dataset string) (int, any) {
+	ids, ok := s.data.releaseRange(start, end)
+	if !ok {
+		return http.StatusNotFound, apiError("No diffs from %s to %s", start, end)
+	}
+	list := semscholar.DatasetDiffList{Dataset: dataset, StartRelease: ids[0], EndRelease: ids[len(ids)-1], Diffs: []semscholar.DatasetDiff{}}
+	for i := 1; i < len(ids); i++ {
+		list.Diffs = append(list.Diffs, semscholar.DatasetDiff{
+			FromRelease: ids[i-1],
+			ToRelease:   ids[i],
+			UpdateFiles: []string{s.fileURL(ids[i], dataset, "diff-updates.jsonl.gz")},
+			DeleteFiles: []string{s.fileURL(ids[i], dataset, "diff-deletes.jsonl.gz")},
+		})
+	}
+	return http.StatusOK, list
+}
+
+// file returns a gzip-compressed JSON Lines file of a dataset: the papers,
+// split between its parts by position, or for an updates diff all of them and
+// for a deletes diff none.
+func (s *Server) file(release, dataset, name string) (int, any) {
+	if _, ok := s.data.Releases[release]; !ok {
+		return http.StatusNotFound, apiError("Release %s not found", release)
+	}
+	files := s.data.Files[release+"/"+dataset]
+	part := -1
+	for i, f := range files {
+		if f == name {
+			part = i
+		}
+	}
+	if part < 0 && !strings.HasPrefix(name, "diff-") {
+		return http.StatusNotFound, apiError("File %s not found", name)
+	}
+	var buf strings.Builder
+	zw := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(zw)
+	for i, p := range s.data.sortedPapers(func(semscholar.Paper) bool { return name != "diff-deletes.jsonl.gz" }) {
+		if part >= 0 && i%len(files) != part {
+			continue
+		}
+		switch dataset {
+		case "abstracts":
+			enc.Encode(map[string]any{"corpusid": p.CorpusID, "abstract": p.Abstract})
+		case "citations":
+			for _, cited := range s.data.References[p.PaperID] {
+				if c, ok := s.data.Papers[cited]; ok {
+					enc.Encode(map[string]any{"citingcorpusid": p.CorpusID, "citedcorpusid": c.CorpusID})
+				}
+			}
+		default:
+			enc.Encode(p)
+		}
+	}
+	zw.Close()
+	return http.StatusOK, []byte(buf.String())
+}
@@ -0,0 +1,248 @@
+package semscholartest
+
+import (
+	"cmp"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// ShardOptions configures the synthetic dataset shards of WriteShard and
+// GenerateShards. The zero value describes one shard of 10,000 papers.
+type ShardOptions struct {
+	// Dataset is the kind of record: "papers" (the default), "abstracts",
+	// "citations" or "embeddings", shaped as the Server serves them.
+	Dataset string
+	// Papers is the size of the corpus the shards cover; zero means 10,000.
+	Papers int
+	// Shards is the number of files the corpus is split into; zero means 1.
+	Shards int
+	// Skew is the exponent of the Zipf distributions that make a few papers
+	// draw most citations, a few authors write most papers and a few venues
+	// publish most of them, as in the real corpus. It must exceed 1 to have
+	// an effect; zero means 1.1, and larger values concentrate more.
+	Skew float64
+	// References is the mean number of citations a paper makes in the
+	// citations dataset; zero means 20.
+	References int
+	// AbstractSentences is the number of sentences in each abstract, the main
+	// lever on the size of the papers and abstracts datasets; zero means 8.
+	AbstractSentences int
+	// Dimensions is the length of each embedding vector; zero means 768.
+	Dimensions int
+	// Seed seeds the generator. Every record derives from the seed and its
+	// corpus ID alone, so shards can be generated separately and in parallel
+	// and come out the same each time.
+	Seed uint64
+}
+
+func (o ShardOptions) withDefaults() ShardOptions {
+	o.Dataset = cmp.Or(o.Dataset, "papers")
+	o.Papers = cmp.Or(o.Papers, 10000)
+	o.Shards = cmp.Or(o.Shards, 1)
+	o.Skew = cmp.Or(o.Skew, 1.1)
+	o.References = cmp.Or(o.References, 20)
+	o.AbstractSentences = cmp.Or(o.AbstractSentences, 8)
+	o.Dimensions = cmp.Or(o.Dimensions, 768)
+	return o
+}
+
+// shardFirstCorpusID is the corpus ID of the first synthetic paper.
+const shardFirstCorpusID = 1_000_000
+
+// ShardName returns the file name GenerateShards gives shard i of a dataset,
+// such as "papers-0003.jsonl.gz".
+func ShardName(dataset string, i int) string {
+	return fmt.Sprintf("%s-%04d.jsonl.gz", dataset, i)
+}
+
+// GenerateShards writes the shards opts describes to dir, creating it if
+// needed, and returns their paths in order. A million papers with default
+// options come to about 1.3 GB of JSON, 200 MB compressed.
+func GenerateShards(dir string, opts ShardOptions) ([]string, error) {
+	opts = opts.withDefaults()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	paths := make([]string, opts.Shards)
+	for i := range paths {
+		paths[i] = filepath.Join(dir, ShardName(opts.Dataset, i))
+		f, err := os.Create(paths[i])
+		if err != nil {
+			return nil, err
+		}
+		err = WriteShard(f, opts, i)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", paths[i], err)
+		}
+	}
+	return paths, nil
+}
+
+// WriteShard writes shard i of the dataset opts describes to w as gzip
+// JSON Lines. Shard i holds the papers in the i-th contiguous range of
+// corpus IDs, or the records about them.
+func WriteShard(w io.Writer, opts ShardOptions, i int) error {
+	opts = opts.withDefaults()
+	if i < 0 || i >= opts.Shards {
+		return fmt.Errorf("semscholartest: shard %d of %d", i, opts.Shards)
+	}
+	gen := &shardGen{opts: opts}
+	switch opts.Dataset {
+	case "papers", "abstracts", "citations", "embeddings":
+	default:
+		return fmt.Errorf("semscholartest: unknown dataset %q", opts.Dataset)
+	}
+	// Shards are compressed for speed rather than size, since generating
+	// large corpora quickly is the point.
+	zw, _ := gzip.NewWriterLevel(w, gzip.BestSpeed)
+	enc := json.NewEncoder(zw)
+	start, end := opts.Papers*i/opts.Shards, opts.Papers*(i+1)/opts.Shards
+	for n := start; n < end; n++ {
+		if err := gen.write(zw, enc, n); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// shardGen generates the records of the paper at each position of a
+// synthetic corpus.
+type shardGen struct {
+	opts ShardOptions
+}
+
+// rng returns the source of the paper at position n, for one purpose, so
+// that each kind of record can be generated without the others.
+func (g *shardGen) rng(n int, purpose uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(g.opts.Seed^purpose, uint64(n)))
+}
+
+// zipf draws from [0, n) with the corpus's skew, small values being the
+// most likely. The head is flattened as in the real corpus, where even the
+// most cited paper draws a small share of all citations.
+func (g *shardGen) zipf(r *rand.Rand, n int) int {
+	if g.opts.Skew <= 1 || n <= 1 {
+		return r.IntN(max(n, 1))
+	}
+	return int(rand.NewZipf(r, g.opts.Skew, shardZipfOffset, uint64(n-1)).Uint64())
+}
+
+// shardZipfOffset is the v of the Zipf distributions, P(k) ∝ (v+k)^-s.
+const shardZipfOffset = 20
+
+func (g *shardGen) write(w io.Writer, enc *json.Encoder, n int) error {
+	corpusID := shardFirstCorpusID + n
+	switch g.opts.Dataset {
+	case "abstracts":
+		return enc.Encode(map[string]any{"corpusid": corpusID, "abstract": g.abstract(n)})
+	case "citations":
+		r := g.rng(n, 'c')
+		refs := int(r.ExpFloat64() * float64(g.opts.References))
+		for range refs {
+			// Cited papers are drawn by rank, so low positions are cited
+			// most; a paper does not cite itself.
+			cited := g.zipf(r, g.opts.Papers)
+			if cited == n {
+				continue
+			}
+			if err := enc.Encode(map[string]any{"citingcorpusid": corpusID, "citedcorpusid": shardFirstCorpusID + cited}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "embeddings":
+		// Vectors are written by hand: encoding hundreds of floats per line
+		// through encoding/json dominates the cost of the dataset.
+		r := g.rng(n, 'e')
+		vec := make([]float64, g.opts.Dimensions)
+		var norm float64
+		for i := range vec {
+			vec[i] = r.NormFloat64()
+			norm += vec[i] * vec[i]
+		}
+		norm = math.Sqrt(norm)
+		b := fmt.Appendf(nil, `{"corpusid":%d,"model":"synthetic","vector":[`, corpusID)
+		for i, v := range vec {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = strconv.AppendFloat(b, v/norm, 'g', -1, 32)
+		}
+		_, err := w.Write(append(b, "]}\n"...))
+		return err
+	}
+	return enc.Encode(g.paper(n))
+}
+
+var (
+	shardTopics = []string{"graph neural networks", "protein folding", "language models", "reinforcement learning", "quantum error correction",
+		"climate modeling", "single-cell sequencing", "causal inference", "federated learning", "dark matter", "image segmentation",
+		"drug discovery", "speech recognition", "materials design", "epidemic forecasting", "robot manipulation"}
+	shardVenues = []string{"NeurIPS", "Nature", "ICML", "ACL", "Science", "CVPR", "Cell", "Physical Review Letters", "ICLR",
+		"PLOS ONE", "EMNLP", "Bioinformatics", "AAAI", "The Lancet", "arXiv.org", "Scientific Reports"}
+	shardFields = []string{"Computer Science", "Biology", "Medicine", "Physics", "Mathematics", "Chemistry", "Environmental Science", "Materials Science"}
+	shardNames  = []string{"Chen", "Smith", "Kumar", "Garcia", "Müller", "Tanaka", "Okafor", "Rossi", "Kim", "Silva", "Novak", "Haddad", "Nguyen", "Cohen"}
+)
+
+func (g *shardGen) paper(n int) semscholar.Paper {
+	r := g.rng(n, 'p')
+	corpusID := shardFirstCorpusID + n
+	id := fmt.Sprintf("%040x", uint64(corpusID)*0x9e3779b97f4a7c15)
+	topic := shardTopics[g.zipf(r, len(shardTopics))]
+	authors := make([]semscholar.Author, 1+g.zipf(r, 12))
+	for i := range authors {
+		// Authors are drawn from a pool a tenth the size of the corpus, so
+		// prolific authors appear on many papers.
+		a := g.zipf(r, max(g.opts.Papers/10, 1))
+		authors[i] = semscholar.Author{AuthorID: fmt.Sprint(5_000_000 + a), Name: fmt.Sprintf("%c. %s", 'A'+rune(a%26), shardNames[a%len(shardNames)])}
+	}
+	// Publication years lean recent, as the corpus does.
+	year := 2024 - g.zipf(r, 45)
+	p := semscholar.Paper{
+		PaperID:         id,
+		CorpusID:        corpusID,
+		ExternalIDs:     semscholar.ExternalIDs{DOI: fmt.Sprintf("10.5555/synthetic.%d", corpusID), CorpusID: corpusID},
+		Title:           fmt.Sprintf("On %s: study %d", topic, n),
+		Abstract:        g.abstract(n),
+		URL:             "https://www.semanticscholar.org/paper/" + id,
+		Venue:           shardVenues[g.zipf(r, len(shardVenues))],
+		Year:            year,
+		PublicationDate: semscholar.NewDate(year, time.Month(1+r.IntN(12)), 1+r.IntN(28)),
+		CitationCount:   semscholar.NewNullInt(g.zipf(r, 100000)),
+		ReferenceCount:  semscholar.NewNullInt(int(r.ExpFloat64() * float64(g.opts.References))),
+		Authors:         authors,
+		FieldsOfStudy:   []string{shardFields[g.zipf(r, len(shardFields))]},
+		IsOpenAccess:    r.IntN(3) == 0,
+	}
+	if p.IsOpenAccess {
+		p.OpenAccessPdf = map[string]interface{}{"url": "https://example.org/pdf/" + id + ".pdf", "status": "GREEN"}
+	}
+	return p
+}
+
+func (g *shardGen) abstract(n int) string {
+	r := g.rng(n, 'a')
+	var b strings.Builder
+	for i := range g.opts.AbstractSentences {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "We study %s and %s, reporting result %d of %d.",
+			shardTopics[g.zipf(r, len(shardTopics))], shardTopics[r.IntN(len(shardTopics))], i+1, r.IntN(1000))
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package semscholartest_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// shardLines returns the lines of shard i of opts.
+func shardLines(t *testing.T, opts semscholartest.ShardOptions, i int) []string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := semscholartest.WriteShard(&buf, opts, i); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	sc := bufio.NewScanner(zr)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		if !json.Valid(sc.Bytes()) {
+			t.Fatalf("shard %d line %d is not JSON: %s", i, len(lines), sc.Bytes())
+		}
+		lines = append(lines, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return lines
+}
+
+// TestWriteShardDeterministic checks that a seed always yields the same
+// records, however the corpus is split into shards, and that another seed
+// yields others.
+func TestWriteShardDeterministic(t *testing.T) {
+	for _, dataset := range []string{"papers", "abstracts", "citations", "embeddings"} {
+		t.Run(dataset, func(t *testing.T) {
+			opts := semscholartest.ShardOptions{Dataset: dataset, Papers: 120, References: 3, AbstractSentences: 2, Dimensions: 4, Seed: 42}
+			whole := shardLines(t, opts, 0)
+			if again := shardLines(t, opts, 0); !slices.Equal(whole, again) {
+				t.Error("the same options generated different records")
+			}
+			if dataset != "citations" && len(whole) != opts.Papers {
+				t.Errorf("%d records, want %d", len(whole), opts.Papers)
+			}
+
+			opts.Shards = 3
+			var split []string
+			for i := range opts.Shards {
+				split = append(split, shardLines(t, opts, i)...)
+			}
+			if !slices.Equal(whole, split) {
+				t.Error("three shards hold other records than one shard of the same corpus")
+			}
+
+			opts.Shards, opts.Seed = 1, 43
+			if other := shardLines(t, opts, 0); slices.Equal(whole, other) {
+				t.Error("another seed generated the same records")
+			}
+		})
+	}
+}
+
+// TestGenerateShards checks that GenerateShards writes each shard under its
+// name, with the same content as WriteShard.
+func TestGenerateShards(t *testing.T) {
+	dir := t.TempDir()
+	opts := semscholartest.ShardOptions{Papers: 50, Shards: 2, AbstractSentences: 1, Seed: 1}
+	paths, err := semscholartest.GenerateShards(filepath.Join(dir, "papers"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "papers", "papers-0000.jsonl.gz"), filepath.Join(dir, "papers", "papers-0001.jsonl.gz")}
+	if !slices.Equal(paths, want) {
+		t.Errorf("paths %q, want %q", paths, want)
+	}
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := semscholartest.WriteShard(&buf, opts, i); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, buf.Bytes()) {
+			t.Errorf("%s differs from WriteShard's shard %d", path, i)
+		}
+	}
+}
+
+// TestWriteShardErrors checks that unknown datasets and shards out of range
+// are rejected.
+func TestWriteShardErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := semscholartest.WriteShard(&buf, semscholartest.ShardOptions{Dataset: "tldrs"}, 0); err == nil {
+		t.Error("WriteShard of an unknown dataset succeeded")
+	}
+	if err := semscholartest.WriteShard(&buf, semscholartest.ShardOptions{Shards: 2}, 2); err == nil {
+		t.Error("WriteShard of shard 2 of 2 succeeded")
+	}
+}
@@ -0,0 +1,300 @@
+package semscholartest
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"runtime/pprof"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// StressOptions configures TestStress. The zero value is a reasonable run.
+type StressOptions struct {
+	// Goroutines is how many workers run at once; zero means 16.
+	Goroutines int
+	// Iterations is how many workloads each worker runs; zero means 10.
+	Iterations int
+	// Papers is the number of synthetic papers served, which should exceed
+	// semscholar.MaxBatchSize so batches are chunked; zero means 700.
+	Papers int
+	// RPS is the rate of the limiter shared by all workers; zero means 2000.
+	RPS float64
+	// Cache is the cache shared by all workers; nil means a small LRUCache,
+	// so entries are evicted while others read them.
+	Cache semscholar.Cache
+	// Chaos, if set, is put between the client and the server, and calls
+	// failing with retryable or server errors are tolerated. Its Next is set
+	// by TestStress.
+	Chaos *Chaos
+	// Seed seeds the choice of workloads and their arguments.
+	Seed uint64
+	// Timeout bounds the run; if it passes, the run is taken to be
+	// deadlocked and the stacks of all goroutines are dumped. Zero means two
+	// minutes.
+	Timeout time.Duration
+	// Workloads are run alongside the built-in ones, by name, with the
+	// shared client; use them to stress a new concurrent feature.
+	Workloads map[string]func(ctx context.Context, c *semscholar.Client) error
+}
+
+// stressEnv is what the workloads of a TestStress run share.
+type stressEnv struct {
+	client *semscholar.Client
+	papers []semscholar.Paper
+	hub    string
+	chaos  bool
+}
+
+// stressWorkload runs one workload with arguments drawn from rng and returns
+// what went wrong.
+type stressWorkload func(ctx context.Context, env *stressEnv, rng *rand.Rand) error
+
+var stressWorkloads = map[string]stressWorkload{
+	"GetPaper": func(ctx context.Context, env *stressEnv, rng *rand.Rand) error {
+		want := env.papers[rng.IntN(len(env.papers))]
+		p, err := env.client.GetPaperContext(ctx, want.PaperID, "title")
+		if err != nil {
+			return err
+		}
+		if p.PaperID != want.PaperID || p.Title != want.Title {
+			return fmt.Errorf("got %s %q, want %s %q", p.PaperID, p.Title, want.PaperID, want.Title)
+		}
+		return nil
+	},
+	"GetPapersBatchAll": func(ctx context.Context, env *stressEnv, rng *rand.Rand) error {
+		ids := stressSample(env.papers, rng, len(env.papers))
+		papers, err := env.client.GetPapersBatchAll(ctx, ids, "title")
+		if err != nil {
+			return err
+		}
+		for i, p := range papers {
+			if p.PaperID != ids[i] {
+				return fmt.Errorf("position %d holds %q, want %q", i, p.PaperID, ids[i])
+			}
+		}
+		return nil
+	},
+	"GetPapersBatchSeq": func(ctx context.Context, env *stressEnv, rng *rand.Rand) error {
+		ids := stressSample(env.papers, rng, semscholar.MaxBatchSize)
+		i := 0
+		for p, err := range env.client.GetPapersBatchSeq(ctx, ids, "title") {
+			if err != nil {
+				return err
+			}
+			if p.PaperID != ids[i] {
+				return fmt.Errorf("item %d is %q, want %q", i, p.PaperID, ids[i])
+			}
+			i++
+		}
+		if i != len(ids) {
+			return fmt.Errorf("yielded %d papers, want %d", i, len(ids))
+		}
+		return nil
+	},
+	"BulkSearchPapersSeq": func(ctx context.Context, env *stressEnv, rng *rand.Rand) error {
+		seen := make(map[string]bool)
+		for p, err := range env.client.BulkSearchPapersSeq(ctx, "synthetic", "title", "", "", nil) {
+			if err != nil {
+				return err
+			}
+			if seen[p.PaperID] {
+				return fmt.Errorf("yielded %s twice", p.PaperID)
+			}
+			seen[p.PaperID] = true
+		}
+		if len(seen) != len(env.papers) {
+			return fmt.Errorf("yielded %d papers, want %d", len(seen), len(env.papers))
+		}
+		return nil
+	},
+	"GetPaperCitationsSeq": func(ctx context.Context, env *stressEnv, rng *rand.Rand) error {
+		n := 0
+		for c, err := range env.client.GetPaperCitationsSeq(ctx, env.hub, "title") {
+			if err != nil {
+				return err
+			}
+			if c.CitingPaper.PaperID == "" {
+				return fmt.Errorf("citation %d has no citing paper", n)
+			}
+			n++
+		}
+		if n != len(env.papers)-1 {
+			return fmt.Errorf("yielded %d citations, want %d", n, len(env.papers)-1)
+		}
+		return nil
+	},
+	"Prime": func(ctx context.Context, env *stressEnv, rng *rand.Rand) error {
+		return env.client.Prime(ctx, stressSample(env.papers, rng, len(env.papers)), "title")
+	},
+	"ResolveDOIs": func(ctx context.Context, env *stressEnv, rng *rand.Rand) error {
+		var dois []string
+		for _, p := range env.papers[:1+rng.IntN(len(env.papers))] {
+			dois = append(dois, p.ExternalIDs.DOI)
+		}
+		papers, errs := env.client.ResolveDOIs(ctx, dois, "title")
+		for _, err := range errs {
+			return err
+		}
+		if len(papers) != len(dois) {
+			return fmt.Errorf("resolved %d of %d DOIs", len(papers), len(dois))
+		}
+		return nil
+	},
+	"Invalidate": func(ctx context.Context, env *stressEnv, rng *rand.Rand) error {
+		_, err := env.client.Invalidate(ctx, env.papers[rng.IntN(len(env.papers))].PaperID)
+		return err
+	},
+}
+
+// stressSample returns the IDs of a random run of up to max papers, in random
+// order.
+func stressSample(papers []semscholar.Paper, rng *rand.Rand, max int) []string {
+	start := rng.IntN(len(papers))
+	n := 1 + rng.IntN(min(len(papers)-start, max))
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = papers[start+i].PaperID
+	}
+	rng.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	return ids
+}
+
+// TestStress runs the client's concurrent machinery under heavy contention:
+// workers call the pagers, the batch worker pools (GetPapersBatchAll, Prime,
+// ResolveDOIs), cache invalidation and single lookups at random, all through
+// one client sharing a cache, a rate limiter and in-flight requests, against
+// a Server. Every result is checked, the limiter's pace is checked against
+// the requests the server saw, and a run that does not finish in time is
+// reported as deadlocked with the stacks of all goroutines. Run it with the
+// race detector from a test in your own package:
+//
+//	func TestStress(t *testing.T) {
+//		semscholartest.TestStress(t, semscholartest.StressOptions{})
+//	}
+//
+//	go test -race -run Stress ./...
+//
+// Contributors adding a concurrent feature can stress it alongside the rest
+// through Workloads.
+func TestStress(t *testing.T, opts StressOptions) {
+	goroutines := cmp.Or(opts.Goroutines, 16)
+	iterations := cmp.Or(opts.Iterations, 10)
+	rps := cmp.Or(opts.RPS, 2000)
+	timeout := cmp.Or(opts.Timeout, 2*time.Minute)
+
+	papers := SyntheticPapers(cmp.Or(opts.Papers, 700))
+	data := NewDataset()
+	for _, p := range papers {
+		data.AddPaper(p)
+	}
+	hub := papers[0].PaperID
+	for _, p := range papers[1:] {
+		data.AddCitation(p.PaperID, hub)
+	}
+	srv := NewServer(data)
+	defer srv.Close()
+	srv.SetBulkPageSize(100)
+
+	var hc semscholar.HTTPClient = srv.srv.Client()
+	if opts.Chaos != nil {
+		opts.Chaos.Next = srv.srv.Client().Transport
+		hc = &http.Client{Transport: opts.Chaos}
+	}
+	limiter := semscholar.NewRateLimiter(rps)
+	client := semscholar.NewClient(srv.URL, semscholar.NewRateLimitedClient(hc, limiter))
+	client.Cache = opts.Cache
+	if client.Cache == nil {
+		client.Cache = semscholar.NewLRUCache(256)
+	}
+	if opts.Chaos != nil {
+		client.Retry = semscholar.RetryPolicy{MaxRetries: 3}
+		client.Clock = NewFakeClock(time.Time{})
+	}
+	env := &stressEnv{client: client, papers: papers, hub: hub, chaos: opts.Chaos != nil}
+
+	workloads := make(map[string]stressWorkload, len(stressWorkloads)+len(opts.Workloads))
+	for name, w := range stressWorkloads {
+		workloads[name] = w
+	}
+	for name, w := range opts.Workloads {
+		workloads[name] = func(ctx context.Context, env *stressEnv, _ *rand.Rand) error { return w(ctx, env.client) }
+	}
+	names := make([]string, 0, len(workloads))
+	for name := range workloads {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	start := time.Now()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures []string
+	)
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewPCG(opts.Seed, uint64(g)))
+			for range iterations {
+				name := names[rng.IntN(len(names))]
+				err := workloads[name](ctx, env, rng)
+				if err == nil || env.chaos && tolerable(err) || ctx.Err() != nil {
+					continue
+				}
+				mu.Lock()
+				failures = append(failures, fmt.Sprintf("worker %d: %s: %v", g, name, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		pprof.Lookup("goroutine").WriteTo(os.Stderr, 2)
+		cancel()
+		t.Fatalf("stress run did not finish within %v; goroutine stacks are above", timeout)
+	}
+	elapsed := time.Since(start)
+
+	for _, f := range failures {
+		t.Error(f)
+	}
+	n := len(srv.Requests())
+	if floor := time.Duration(float64(n-1) / rps * float64(time.Second)); n > 1 && elapsed < floor {
+		t.Errorf("%d requests at %v/s took %v; the limiter allows no less than %v", n, rps, elapsed, floor)
+	}
+	t.Logf("%d workers ran %d workloads each in %v, sending %d requests", goroutines, iterations, elapsed, n)
+}
+
+// tolerable reports whether err is what a Chaos fault may leave behind once
+// retries are exhausted: a retryable error or a server error, for every chunk
+// of a partial failure.
+func tolerable(err error) bool {
+	var partial *semscholar.PartialError
+	if errors.As(err, &partial) {
+		for _, f := range partial.Failures {
+			if !tolerable(f.Err) {
+				return false
+			}
+		}
+		return true
+	}
+	return semscholar.IsRetryable(err) || errors.Is(err, semscholar.ErrServerError)
+}
@@ -0,0 +1,72 @@
+package semscholar
+
+import (
+	"context"
+	"iter"
+)
+
+// PaperService covers the paper endpoints of the Graph API. Code that accepts
+// a PaperService instead of a *Client can be tested against a fake.
+type PaperService interface {
+	GetPaper(paperID, fields string) (*Paper, error)
+	GetPaperContext(ctx context.Context, paperID, fields string) (*Paper, error)
+	GetPapersBatch(ids []string, fields string) ([]Paper, error)
+	GetPapersBatchContext(ctx context.Context, ids []string, fields string) ([]Paper, error)
+	GetPapersBatchSeq(ctx context.Context, ids []string, fields string) iter.Seq2[Paper, error]
+	AutocompletePaper(query string) ([]Paper, error)
+	AutocompletePaperContext(ctx context.Context, query string) ([]Paper, error)
+	SearchPapers(query string, offset, limit int, fields string, filters map[string]string) (*PaperSearchResponse, error)
+	SearchPapersContext(ctx context.Context, query string, offset, limit int, fields string, filters map[string]string) (*PaperSearchResponse, error)
+	BulkSearchPapers(query, token, fields, sort, publicationTypes string, additionalFilters map[string]string) (*PaperSearchResponse, error)
+	BulkSearchPapersContext(ctx context.Context, query, token, fields, sort, publicationTypes string, additionalFilters map[string]string) (*PaperSearchResponse, error)
+	BulkSearchPapersSeq(ctx context.Context, query, fields, sort, publicationTypes string, additionalFilters map[string]string) iter.Seq2[Paper, error]
+	MatchSearchPapers(query, fields, publicationTypes string, additionalFilters map[string]string) (*PaperSearchResponse, error)
+	MatchSearchPapersContext(ctx context.Context, query, fields, publicationTypes string, additionalFilters map[string]string) (*PaperSearchResponse, error)
+	GetPaperCitations(paperID string, offset, limit int, fields string) (*CitationsResponse, error)
+	GetPaperCitationsContext(ctx context.Context, paperID string, offset, limit int, fields string) (*CitationsResponse, error)
+	GetPaperCitationsSeq(ctx context.Context, paperID, fields string) iter.Seq2[Citation, error]
+	GetPaperReferences(paperID string, offset, limit int, fields string) (*CitationsResponse, error)
+	GetPaperReferencesContext(ctx context.Context, paperID string, offset, limit int, fields string) (*CitationsResponse, error)
+	GetPaperReferencesSeq(ctx context.Context, paperID, fields string) iter.Seq2[Citation, error]
+	SnippetSearch(ctx context.Context, query string, limit int, filters map[string]string) (*SnippetSearchResponse, error)
+}
+
+// AuthorService covers the author endpoints of the Graph API.
+type AuthorService interface {
+	GetAuthor(authorID, fields string) (*Author, error)
+	GetAuthorContext(ctx context.Context, authorID, fields string) (*Author, error)
+	GetAuthorsBatch(ids []string, fields string) ([]Author, error)
+	GetAuthorsBatchContext(ctx context.Context, ids []string, fields string) ([]Author, error)
+	SearchAuthors(query string, offset, limit int, fields string) (*AuthorSearchResponse, error)
+	SearchAuthorsContext(ctx context.Context, query string, offset, limit int, fields string) (*AuthorSearchResponse, error)
+	GetAuthorPapers(authorID string, offset, limit int, fields string) (*AuthorPapersResponse, error)
+	GetAuthorPapersContext(ctx context.Context, authorID string, offset, limit int, fields string) (*AuthorPapersResponse, error)
+	GetAuthorPapersSeq(ctx context.Context, authorID, fields string) iter.Seq2[Paper, error]
+}
+
+// RecommendationService covers the Recommendations API.
+type RecommendationService interface {
+	GetRecommendations(reqData RecommendationRequest, limit int, fields string) (*RecommendationResponse, error)
+	GetRecommendationsContext(ctx context.Context, reqData RecommendationRequest, limit int, fields string) (*RecommendationResponse, error)
+	GetRecommendationsForPaper(paperID, from string, limit int, fields string) (*RecommendationResponse, error)
+	GetRecommendationsForPaperContext(ctx context.Context, paperID, from string, limit int, fields string) (*RecommendationResponse, error)
+}
+
+// DatasetService covers the Datasets API.
+type DatasetService interface {
+	GetReleases() ([]string, error)
+	GetReleasesContext(ctx context.Context) ([]string, error)
+	GetRelease(releaseID string) (*ReleaseMetadata, error)
+	GetReleaseContext(ctx context.Context, releaseID string) (*ReleaseMetadata, error)
+	GetDataset(releaseID, datasetName string) (*DatasetMetadata, error)
+	GetDatasetContext(ctx context.Context, releaseID, datasetName string) (*DatasetMetadata, error)
+	GetDatasetDiffs(startReleaseID, endReleaseID, datasetName string) (*DatasetDiffList, error)
+	GetDatasetDiffsContext(ctx context.Context, startReleaseID, endReleaseID, datasetName string) (*DatasetDiffList, error)
+}
+
+var (
+	_ PaperService          = (*Client)(nil)
+	_ AuthorService         = (*Client)(nil)
+	_ RecommendationService = (*Client)(nil)
+	_ DatasetService        = (*Client)(nil)
+)
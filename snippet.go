@@ -0,0 +1,64 @@
+package semscholar
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// MaxSnippetLimit is the most snippets a snippet search returns.
+const MaxSnippetLimit = 1000
+
+// Snippet is a passage of the text of a paper, as snippet search finds it.
+type Snippet struct {
+	Text string `json:"text"`
+	// SnippetKind is where the passage comes from: "title", "abstract" or
+	// "body".
+	SnippetKind string `json:"snippetKind"`
+	// Section is the heading of the section of a body passage.
+	Section string `json:"section,omitempty"`
+}
+
+// SnippetPaper is the paper a snippet comes from. The corpus ID is a string
+// in the snippet search API, unlike in the paper endpoints.
+type SnippetPaper struct {
+	CorpusID string   `json:"corpusId"`
+	Title    string   `json:"title"`
+	Authors  []string `json:"authors,omitempty"`
+}
+
+// SnippetMatch is a snippet found for a query and its relevance score.
+type SnippetMatch struct {
+	Snippet Snippet      `json:"snippet"`
+	Score   float64      `json:"score"`
+	Paper   SnippetPaper `json:"paper"`
+}
+
+// SnippetSearchResponse is the result of a snippet search, most relevant
+// first.
+type SnippetSearchResponse struct {
+	Data             []SnippetMatch `json:"data"`
+	RetrievalVersion string         `json:"retrievalVersion,omitempty"`
+}
+
+// SnippetSearch finds passages of the text of papers relevant to query,
+// returning up to limit of them, at most MaxSnippetLimit. The filters are
+// those of paper search, such as year, venue and fieldsOfStudy, and also
+// paperIds, restricting the search to some papers.
+func (c *Client) SnippetSearch(ctx context.Context, query string, limit int, filters map[string]string) (*SnippetSearchResponse, error) {
+	params := url.Values{}
+	params.Add("query", query)
+	params.Add("limit", fmt.Sprintf("%d", limit))
+	addFilters(params, filters)
+	endpoint := fmt.Sprintf("%s/snippet/search?%s", c.BaseURL, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result SnippetSearchResponse
+	if err := c.doJSON("SnippetSearch", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, c.validate("SnippetSearch", req, &result)
+}
@@ -0,0 +1,319 @@
+package semscholar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"time"
+)
+
+// citationPageSize is the largest page the citations and references endpoints return.
+const citationPageSize = 1000
+
+// authorPapersPageSize is the largest page the author papers endpoint returns.
+const authorPapersPageSize = 1000
+
+// bulkResumeAttempts is how many times BulkSearchPapersSeq retries a page that
+// fails once the search is under way.
+const bulkResumeAttempts = 3
+
+// BulkSearchPapersSeq iterates over every paper matching a bulk search,
+// following continuation tokens from page to page. Papers are decoded and
+// yielded one at a time as the response is read, so a page of tens of
+// megabytes is never held in memory at once.
+//
+// Bulk search occasionally fails mid-crawl with a gateway timeout. Once at
+// least one paper has been yielded, a page failing with a server error, a 429
+// or a network error is fetched again from the last good continuation token,
+// skipping papers already yielded, up to three times with growing delays. If
+// it still fails, a *BulkSearchError carrying that token is yielded and the
+// iteration ends.
+func (c *Client) BulkSearchPapersSeq(ctx context.Context, query, fields, sort, publicationTypes string, additionalFilters map[string]string) iter.Seq2[Paper, error] {
+	return func(yield func(Paper, error) bool) {
+		token := ""
+		total := 0
+		for {
+			var page PaperSearchResponse
+			delivered := 0
+			for attempt := 0; ; attempt++ {
+				req, err := http.NewRequestWithContext(ctx, "GET", c.bulkSearchEndpoint(query, token, fields, sort, publicationTypes, additionalFilters), nil)
+				if err != nil {
+					yield(Paper{}, err)
+					return
+				}
+				page = PaperSearchResponse{}
+				seen := 0
+				stopped, err := streamPage(c, req, "BulkSearchPapers", "data", &page, func(p Paper) bool {
+					seen++
+					if seen <= delivered {
+						return true
+					}
+					delivered++
+					total++
+					return yield(p, nil)
+				})
+				if stopped {
+					return
+				}
+				if err == nil {
+					break
+				}
+				if total == 0 {
+					yield(Paper{}, c.report("BulkSearchPapers", err))
+					return
+				}
+				if attempt >= bulkResumeAttempts || !resumable(ctx, err) {
+					yield(Paper{}, c.report("BulkSearchPapers", &BulkSearchError{Token: token, Delivered: delivered, Err: err}))
+					return
+				}
+				if err := clockOr(c.Clock).Sleep(ctx, time.Duration(1<<attempt)*time.Second); err != nil {
+					yield(Paper{}, c.report("BulkSearchPapers", &BulkSearchError{Token: token, Delivered: delivered, Err: err}))
+					return
+				}
+			}
+			if page.Token == "" {
+				return
+			}
+			if page.Token == token {
+				yield(Paper{}, c.report("BulkSearchPapers", errStalledPaging))
+				return
+			}
+			token = page.Token
+		}
+	}
+}
+
+// BulkSearchError is yielded by BulkSearchPapersSeq when a page could not be
+// fetched even after resuming. Passing Token to BulkSearchPapers, or starting
+// a new iteration from it, continues the search at the failed page, of which
+// the first Delivered papers were already yielded.
+type BulkSearchError struct {
+	Token     string
+	Delivered int
+	Err       error
+}
+
+// Error implements the error interface.
+func (e *BulkSearchError) Error() string {
+	return fmt.Sprintf("BulkSearchPapers: interrupted (resume from token %q): %v", e.Token, e.Err)
+}
+
+// Unwrap returns the cause.
+func (e *BulkSearchError) Unwrap() error {
+	return e.Err
+}
+
+// errStalledPaging is yielded when a page points back at itself, as a
+// malformed response could, instead of requesting the same page forever.
+var errStalledPaging = errors.New("semscholar: paging did not advance")
+
+// resumable reports whether a failed page is worth fetching again: any
+// failure but a permanent one, so a 500 is resumed as well as a gateway
+// timeout, and so is a response cut off mid-body.
+func resumable(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && !IsPermanent(err)
+}
+
+// GetPapersBatchSeq is like GetPapersBatchContext but yields papers as they are
+// decoded. Unresolved IDs yield zero-valued papers, keeping positions aligned with ids.
+func (c *Client) GetPapersBatchSeq(ctx context.Context, ids []string, fields string) iter.Seq2[Paper, error] {
+	return func(yield func(Paper, error) bool) {
+		req, err := c.newPapersBatchRequest(ctx, ids, fields)
+		if err != nil {
+			yield(Paper{}, err)
+			return
+		}
+		resp, err := c.doStream(withOp(req, "GetPapersBatch"))
+		if err != nil {
+			yield(Paper{}, c.report("GetPapersBatch", err))
+			return
+		}
+		defer closeBody(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			yield(Paper{}, c.report("GetPapersBatch", c.newAPIError("GetPapersBatch", resp)))
+			return
+		}
+		if _, err := streamArray(c, json.NewDecoder(resp.Body), func(p Paper) bool { return yield(p, nil) }); err != nil {
+			yield(Paper{}, c.report("GetPapersBatch", err))
+		}
+	}
+}
+
+// GetPaperCitationsSeq iterates over all papers citing paperID, paging as needed.
+func (c *Client) GetPaperCitationsSeq(ctx context.Context, paperID, fields string) iter.Seq2[Citation, error] {
+	return c.citationEdgesSeq(ctx, "GetPaperCitations", "citations", paperID, fields)
+}
+
+// GetPaperReferencesSeq iterates over all papers cited by paperID, paging as needed.
+func (c *Client) GetPaperReferencesSeq(ctx context.Context, paperID, fields string) iter.Seq2[Citation, error] {
+	return c.citationEdgesSeq(ctx, "GetPaperReferences", "references", paperID, fields)
+}
+
+func (c *Client) citationEdgesSeq(ctx context.Context, op, kind, paperID, fields string) iter.Seq2[Citation, error] {
+	return func(yield func(Citation, error) bool) {
+		offset := 0
+		for {
+			req, err := http.NewRequestWithContext(ctx, "GET", c.citationEdgesEndpoint(kind, paperID, offset, citationPageSize, fields), nil)
+			if err != nil {
+				yield(Citation{}, err)
+				return
+			}
+			var page CitationsResponse
+			n := 0
+			stopped, err := streamPage(c, req, op, "data", &page, func(e Citation) bool {
+				n++
+				return yield(e, nil)
+			})
+			if err != nil {
+				yield(Citation{}, c.report(op, err))
+				return
+			}
+			if stopped || page.Next == 0 || n == 0 {
+				return
+			}
+			if page.Next <= offset {
+				yield(Citation{}, c.report(op, errStalledPaging))
+				return
+			}
+			offset = page.Next
+		}
+	}
+}
+
+// GetAuthorPapersSeq iterates over all papers of authorID, paging as needed.
+func (c *Client) GetAuthorPapersSeq(ctx context.Context, authorID, fields string) iter.Seq2[Paper, error] {
+	const op = "GetAuthorPapers"
+	return func(yield func(Paper, error) bool) {
+		offset := 0
+		for {
+			req, err := http.NewRequestWithContext(ctx, "GET", c.authorPapersEndpoint(authorID, offset, authorPapersPageSize, fields), nil)
+			if err != nil {
+				yield(Paper{}, err)
+				return
+			}
+			var page AuthorPapersResponse
+			n := 0
+			stopped, err := streamPage(c, req, op, "data", &page, func(p Paper) bool {
+				n++
+				return yield(p, nil)
+			})
+			if err != nil {
+				yield(Paper{}, c.report(op, err))
+				return
+			}
+			if stopped || page.Next == 0 || n == 0 {
+				return
+			}
+			if page.Next <= offset {
+				yield(Paper{}, c.report(op, errStalledPaging))
+				return
+			}
+			offset = page.Next
+		}
+	}
+}
+
+// doStream sends a request whose response is decoded incrementally. With a
+// cache configured it goes through do, which buffers the body in order to
+// store it; otherwise the body is streamed straight from the connection,
+// without coalescing.
+func (c *Client) doStream(req *http.Request) (*http.Response, error) {
+	if c.Cache != nil {
+		return c.do(req)
+	}
+	return c.send(withRequestID(req))
+}
+
+// streamPage sends req and decodes a paged response object, yielding each
+// element of its arrayKey member to each and decoding the other members into meta.
+func streamPage[T any](c *Client, req *http.Request, op, arrayKey string, meta any, each func(T) bool) (bool, error) {
+	resp, err := c.doStream(withOp(req, op))
+	if err != nil {
+		return false, err
+	}
+	defer closeBody(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return false, c.newAPIError(op, resp)
+	}
+	return streamObject(c, json.NewDecoder(resp.Body), arrayKey, meta, each)
+}
+
+// streamObject decodes a JSON object from dec. Elements of the arrayKey member
+// are decoded one by one with c.unmarshal and passed to each; the remaining
+// members are decoded into meta once the object ends. It reports whether each
+// asked to stop.
+func streamObject[T any](c *Client, dec *json.Decoder, arrayKey string, meta any, each func(T) bool) (bool, error) {
+	if err := expectDelim(dec, '{'); err != nil {
+		return false, err
+	}
+	rest := make(map[string]json.RawMessage)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return false, err
+		}
+		key, _ := tok.(string)
+		if key != arrayKey {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return false, err
+			}
+			rest[key] = raw
+			continue
+		}
+		if stopped, err := streamArray(c, dec, each); err != nil || stopped {
+			return stopped, err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return false, err
+	}
+	if meta == nil || len(rest) == 0 {
+		return false, nil
+	}
+	b, err := json.Marshal(rest)
+	if err != nil {
+		return false, err
+	}
+	return false, c.unmarshal(b, meta)
+}
+
+// streamArray decodes a JSON array from dec element by element with
+// c.unmarshal, passing each to each. A null array is treated as empty.
+func streamArray[T any](c *Client, dec *json.Decoder, each func(T) bool) (bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+	if tok == nil {
+		return false, nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return false, fmt.Errorf("semscholar: expected JSON array, got %v", tok)
+	}
+	for dec.More() {
+		var v T
+		if err := c.decodeNext(dec, &v); err != nil {
+			return false, err
+		}
+		if !each(v) {
+			return true, nil
+		}
+	}
+	_, err = dec.Token()
+	return false, err
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("semscholar: expected %q in JSON, got %v", want, tok)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package semscholar_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// bulkRequests returns the bulk search requests srv received.
+func bulkRequests(srv *semscholartest.Server) []string {
+	var reqs []string
+	for _, r := range srv.Requests() {
+		if strings.HasPrefix(r, "GET /paper/search/bulk") {
+			reqs = append(reqs, r)
+		}
+	}
+	return reqs
+}
+
+// TestBulkSearchResume checks that a bulk search page failing once the
+// search is under way is fetched again from the last good token, and the
+// iteration carries on without repeating or missing papers.
+func TestBulkSearchResume(t *testing.T) {
+	srv := semscholartest.NewServer(nil)
+	defer srv.Close()
+	srv.SetBulkPageSize(2)
+	clock := semscholartest.NewFakeClock(time.Time{})
+	client := srv.Client()
+	client.Clock = clock
+
+	var ids []string
+	for p, err := range client.BulkSearchPapersSeq(context.Background(), "", "title", "", "", nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) == 0 {
+			srv.Inject(semscholartest.Fault{Path: "/paper/search/bulk", Status: http.StatusGatewayTimeout, Times: 1})
+		}
+		ids = append(ids, p.PaperID)
+	}
+	if len(ids) != 6 {
+		t.Errorf("%d papers, want the 6 fixtures", len(ids))
+	}
+	slices.Sort(ids)
+	if len(slices.Compact(ids)) != len(ids) {
+		t.Errorf("papers %v repeat", ids)
+	}
+	reqs := bulkRequests(srv)
+	if len(reqs) != 4 || !strings.Contains(reqs[1], "token=2") || reqs[1] != reqs[2] {
+		t.Errorf("requests %q, want the second page twice", reqs)
+	}
+	if got := clock.Sleeps(); !slices.Equal(got, []time.Duration{time.Second}) {
+		t.Errorf("slept %v, want [1s]", got)
+	}
+}
+
+// TestBulkSearchResumeFails checks that a page still failing after the
+// resume attempts ends the iteration with a BulkSearchError carrying the
+// token to continue from.
+func TestBulkSearchResumeFails(t *testing.T) {
+	srv := semscholartest.NewServer(nil)
+	defer srv.Close()
+	srv.SetBulkPageSize(2)
+	client := srv.Client()
+	client.Clock = semscholartest.NewFakeClock(time.Time{})
+
+	var ids []string
+	var last error
+	for p, err := range client.BulkSearchPapersSeq(context.Background(), "", "title", "", "", nil) {
+		if err != nil {
+			last = err
+			continue
+		}
+		if len(ids) == 0 {
+			srv.Inject(semscholartest.Fault{Path: "/paper/search/bulk", Status: http.StatusGatewayTimeout})
+		}
+		ids = append(ids, p.PaperID)
+	}
+	var bulkErr *semscholar.BulkSearchError
+	if !errors.As(last, &bulkErr) {
+		t.Fatalf("iteration ended with %v, want a BulkSearchError", last)
+	}
+	if bulkErr.Token != "2" || bulkErr.Delivered != 0 || !errors.Is(last, semscholar.ErrServerError) {
+		t.Errorf("BulkSearchError = %+v, want token 2 with nothing delivered, failing with a 504", bulkErr)
+	}
+	if len(ids) != 2 {
+		t.Errorf("%d papers, want the first page's 2", len(ids))
+	}
+	if reqs := bulkRequests(srv); len(reqs) != 5 {
+		t.Errorf("%d requests, want the first page and 4 attempts at the second", len(reqs))
+	}
+}
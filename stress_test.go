@@ -0,0 +1,26 @@
+package semscholar_test
+
+import (
+	"testing"
+
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestStress runs the pagers, batch pools, cache and limiter of one client
+// from many goroutines at once, without and with injected faults. It is meant
+// for the race detector:
+//
+//	go test -race -run TestStress .
+func TestStress(t *testing.T) {
+	if testing.Short() {
+		t.Skip("stress test skipped in short mode")
+	}
+	t.Run("clean", func(t *testing.T) {
+		semscholartest.TestStress(t, semscholartest.StressOptions{})
+	})
+	t.Run("chaos", func(t *testing.T) {
+		semscholartest.TestStress(t, semscholartest.StressOptions{
+			Chaos: &semscholartest.Chaos{ThrottleEvery: 9, ErrorRate: 0.02, DropRate: 0.02, TruncateRate: 0.02},
+		})
+	})
+}
@@ -0,0 +1,66 @@
+package semscholar
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Summarizer synthesizes text, such as a survey-style digest, from papers.
+// The module bundles none: it is the point at which an application attaches
+// a language model, calling OpenAI, Ollama or the like, to the stages that
+// take one, such as those of the review package. SummaryInput renders papers
+// as text for a prompt.
+type Summarizer interface {
+	Summarize(ctx context.Context, papers []Paper) (string, error)
+}
+
+// SummarizerFunc adapts a function to a Summarizer.
+type SummarizerFunc func(ctx context.Context, papers []Paper) (string, error)
+
+// Summarize implements Summarizer.
+func (f SummarizerFunc) Summarize(ctx context.Context, papers []Paper) (string, error) {
+	return f(ctx, papers)
+}
+
+// SummaryInput renders papers as a numbered list for a summarization
+// prompt: the title, authors, year and venue of each, then its TLDR and
+// abstract, those it has. The numbers, from 1, let a digest cite papers as
+// "[2]".
+func SummaryInput(papers []Paper) string {
+	var b strings.Builder
+	for i, p := range papers {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "[%d] %s\n", i+1, p.Title)
+		var about []string
+		if len(p.Authors) > 0 {
+			names := make([]string, 0, 3)
+			for _, a := range p.Authors[:min(3, len(p.Authors))] {
+				names = append(names, a.Name)
+			}
+			if len(p.Authors) > 3 {
+				names = append(names, "et al.")
+			}
+			about = append(about, strings.Join(names, ", "))
+		}
+		if y := p.PublishedYear(); y != 0 {
+			about = append(about, strconv.Itoa(y))
+		}
+		if p.Venue != "" {
+			about = append(about, p.Venue)
+		}
+		if len(about) > 0 {
+			b.WriteString(strings.Join(about, "; ") + "\n")
+		}
+		if p.Tldr != nil && p.Tldr.Text != "" {
+			b.WriteString("TLDR: " + p.Tldr.Text + "\n")
+		}
+		if p.Abstract != "" {
+			b.WriteString("Abstract: " + p.Abstract + "\n")
+		}
+	}
+	return b.String()
+}
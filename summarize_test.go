@@ -0,0 +1,58 @@
+package semscholar_test
+
+import (
+	"context"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// TestSummaryInput checks the numbered list SummaryInput renders, with the
+// parts each paper has and at most three authors.
+func TestSummaryInput(t *testing.T) {
+	papers := []semscholar.Paper{
+		{
+			Title:    "Attention is All you Need",
+			Year:     2017,
+			Venue:    "NeurIPS",
+			Authors:  []semscholar.Author{{Name: "Ashish Vaswani"}, {Name: "Noam Shazeer"}, {Name: "Niki Parmar"}, {Name: "Jakob Uszkoreit"}},
+			Tldr:     &semscholar.Tldr{Text: "Transformers."},
+			Abstract: "The dominant sequence transduction models...",
+		},
+		{Title: "Untitled draft"},
+		{Title: "Deep Residual Learning", Authors: []semscholar.Author{{Name: "Kaiming He"}}, Tldr: &semscholar.Tldr{}},
+	}
+	want := `[1] Attention is All you Need
+Ashish Vaswani, Noam Shazeer, Niki Parmar, et al.; 2017; NeurIPS
+TLDR: Transformers.
+Abstract: The dominant sequence transduction models...
+
+[2] Untitled draft
+
+[3] Deep Residual Learning
+Kaiming He
+`
+	if got := semscholar.SummaryInput(papers); got != want {
+		t.Errorf("SummaryInput =\n%s\nwant\n%s", got, want)
+	}
+	if got := semscholar.SummaryInput(nil); got != "" {
+		t.Errorf("SummaryInput(nil) = %q, want empty", got)
+	}
+}
+
+// TestSummarizerFunc checks that a SummarizerFunc is called with the
+// context and papers it is given.
+func TestSummarizerFunc(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "v")
+	var s semscholar.Summarizer = semscholar.SummarizerFunc(func(ctx context.Context, papers []semscholar.Paper) (string, error) {
+		if ctx.Value(key{}) != "v" {
+			t.Error("SummarizerFunc called without the caller's context")
+		}
+		return semscholar.SummaryInput(papers), nil
+	})
+	got, err := s.Summarize(ctx, []semscholar.Paper{{Title: "A"}})
+	if err != nil || got != "[1] A\n" {
+		t.Errorf("Summarize = %q, %v, want the summary input", got, err)
+	}
+}
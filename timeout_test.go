@@ -0,0 +1,66 @@
+package semscholar_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestWithTimeout checks that a call through WithTimeout fails once its
+// timeout passes, while the client it was derived from keeps none.
+func TestWithTimeout(t *testing.T) {
+	srv := semscholartest.NewServer(nil)
+	defer srv.Close()
+	srv.SetLatency(500 * time.Millisecond)
+	client := srv.Client()
+
+	start := time.Now()
+	_, err := client.WithTimeout(20*time.Millisecond).GetPaper(semscholartest.BERTPaperID, "title")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetPaper = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("GetPaper returned after %v, want about 20ms", elapsed)
+	}
+	if client.Timeout != 0 {
+		t.Errorf("Timeout of the original client = %v, want 0", client.Timeout)
+	}
+}
+
+// TestRetryDeadline checks that a retry whose wait would outlast the call's
+// deadline is not started, the throttled response being returned instead.
+func TestRetryDeadline(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		timeout time.Duration
+		sleeps  int
+	}{
+		{"no timeout", 0, 2},
+		{"short timeout", time.Second, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := semscholartest.NewServer(nil)
+			defer srv.Close()
+			srv.Inject(semscholartest.Fault{Status: http.StatusServiceUnavailable, RetryAfter: 5 * time.Second})
+			clock := semscholartest.NewFakeClock(time.Time{})
+			client := srv.Client()
+			client.Clock = clock
+			client.Retry = semscholar.RetryPolicy{MaxRetries: 2}
+			_, err := client.WithTimeout(tt.timeout).GetPaper(semscholartest.BERTPaperID, "title")
+			if !errors.Is(err, semscholar.ErrServerError) {
+				t.Errorf("GetPaper = %v, want the 503", err)
+			}
+			if got := clock.Sleeps(); len(got) != tt.sleeps {
+				t.Errorf("slept %v, want %d waits", got, tt.sleeps)
+			}
+			if got := len(srv.Requests()); got != tt.sleeps+1 {
+				t.Errorf("%d requests, want %d", got, tt.sleeps+1)
+			}
+		})
+	}
+}
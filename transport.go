@@ -0,0 +1,105 @@
+package semscholar
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportOptions tunes the transport built by NewTransport. Zero fields use
+// defaults suited to high-volume harvesting against a single API host.
+type TransportOptions struct {
+	// MaxIdleConns bounds idle connections across all hosts; it defaults to 100.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost bounds idle connections kept per host; it defaults to 32,
+	// well above net/http's 2, so concurrent workers reuse connections.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost bounds all connections per host; zero means no limit.
+	MaxConnsPerHost int
+	// IdleConnTimeout closes connections idle for longer; it defaults to 90s.
+	IdleConnTimeout time.Duration
+	// DialTimeout bounds establishing a TCP connection; it defaults to 30s.
+	DialTimeout time.Duration
+	// KeepAlive is the TCP keep-alive period; it defaults to 30s.
+	KeepAlive time.Duration
+	// TLSHandshakeTimeout bounds the TLS handshake; it defaults to 10s.
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout bounds the wait for response headers once a request
+	// is written; zero means no limit beyond the client's own timeout.
+	ResponseHeaderTimeout time.Duration
+	// ForceHTTP2 requires HTTP/2 on direct TLS connections, failing them instead
+	// of falling back to HTTP/1.1. By default HTTP/2 is attempted with fallback.
+	ForceHTTP2 bool
+}
+
+// DefaultTimeout is the overall request timeout of the client NewClient builds
+// when none is supplied.
+const DefaultTimeout = 10 * time.Second
+
+// NewTransport builds an http.Transport configured by opts, honoring proxy
+// settings from the environment.
+func NewTransport(opts TransportOptions) *http.Transport {
+	if opts.MaxIdleConns == 0 {
+		opts.MaxIdleConns = 100
+	}
+	if opts.MaxIdleConnsPerHost == 0 {
+		opts.MaxIdleConnsPerHost = 32
+	}
+	if opts.IdleConnTimeout == 0 {
+		opts.IdleConnTimeout = 90 * time.Second
+	}
+	if opts.DialTimeout == 0 {
+		opts.DialTimeout = 30 * time.Second
+	}
+	if opts.KeepAlive == 0 {
+		opts.KeepAlive = 30 * time.Second
+	}
+	if opts.TLSHandshakeTimeout == 0 {
+		opts.TLSHandshakeTimeout = 10 * time.Second
+	}
+	dialer := &net.Dialer{Timeout: opts.DialTimeout, KeepAlive: opts.KeepAlive}
+	t := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          opts.MaxIdleConns,
+		MaxIdleConnsPerHost:   opts.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       opts.MaxConnsPerHost,
+		IdleConnTimeout:       opts.IdleConnTimeout,
+		TLSHandshakeTimeout:   opts.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: opts.ResponseHeaderTimeout,
+		ExpectContinueTimeout: time.Second,
+		ForceAttemptHTTP2:     true,
+	}
+	if opts.ForceHTTP2 {
+		t.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			ctx, cancel := context.WithTimeout(ctx, opts.DialTimeout+opts.TLSHandshakeTimeout)
+			defer cancel()
+			d := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host, NextProtos: []string{"h2"}}}
+			conn, err := d.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			if conn.(*tls.Conn).ConnectionState().NegotiatedProtocol != "h2" {
+				conn.Close()
+				return nil, errHTTP2Required
+			}
+			return conn, nil
+		}
+	}
+	return t
+}
+
+var errHTTP2Required = errors.New("semscholar: server did not negotiate HTTP/2")
+
+// NewHTTPClient returns an http.Client with the given overall timeout that
+// sends requests through NewTransport(opts).
+func NewHTTPClient(timeout time.Duration, opts TransportOptions) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: NewTransport(opts)}
+}
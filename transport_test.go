@@ -0,0 +1,64 @@
+package semscholar_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestNewTransport checks that NewTransport fills in the defaults of zero
+// options and keeps the ones given.
+func TestNewTransport(t *testing.T) {
+	tr := semscholar.NewTransport(semscholar.TransportOptions{})
+	if tr.MaxIdleConns != 100 || tr.MaxIdleConnsPerHost != 32 || tr.MaxConnsPerHost != 0 {
+		t.Errorf("connection limits = %d, %d, %d, want 100, 32, 0", tr.MaxIdleConns, tr.MaxIdleConnsPerHost, tr.MaxConnsPerHost)
+	}
+	if tr.IdleConnTimeout != 90*time.Second || tr.TLSHandshakeTimeout != 10*time.Second {
+		t.Errorf("timeouts = %v, %v, want 1m30s, 10s", tr.IdleConnTimeout, tr.TLSHandshakeTimeout)
+	}
+	if tr.Proxy == nil {
+		t.Error("Proxy is nil, want the environment's")
+	}
+
+	tr = semscholar.NewTransport(semscholar.TransportOptions{MaxIdleConnsPerHost: 4, MaxConnsPerHost: 8, ResponseHeaderTimeout: time.Second})
+	if tr.MaxIdleConnsPerHost != 4 || tr.MaxConnsPerHost != 8 || tr.ResponseHeaderTimeout != time.Second {
+		t.Errorf("options = %d, %d, %v, want 4, 8, 1s", tr.MaxIdleConnsPerHost, tr.MaxConnsPerHost, tr.ResponseHeaderTimeout)
+	}
+}
+
+// TestNewClientTransport checks that NewClient without an HTTP client sends
+// through a transport from NewTransport.
+func TestNewClientTransport(t *testing.T) {
+	c := semscholar.NewClient("", nil)
+	hc, ok := c.HTTPClient.(*http.Client)
+	if !ok {
+		t.Fatalf("HTTPClient is %T, want *http.Client", c.HTTPClient)
+	}
+	if hc.Timeout != semscholar.DefaultTimeout {
+		t.Errorf("Timeout = %v, want %v", hc.Timeout, semscholar.DefaultTimeout)
+	}
+	if tr, ok := hc.Transport.(*http.Transport); !ok || tr.MaxIdleConnsPerHost != 32 {
+		t.Errorf("Transport = %T, want NewTransport's", hc.Transport)
+	}
+}
+
+// TestTransportOptionsUsed checks that a client built with NewHTTPClient
+// sends its requests through the transport it was given, by a response
+// header timeout failing a slow server that the default tolerates.
+func TestTransportOptionsUsed(t *testing.T) {
+	srv := semscholartest.NewServer(nil)
+	defer srv.Close()
+	srv.SetLatency(200 * time.Millisecond)
+
+	hc := semscholar.NewHTTPClient(0, semscholar.TransportOptions{ResponseHeaderTimeout: 20 * time.Millisecond})
+	if _, err := semscholar.NewClient(srv.URL, hc).GetPaper(semscholartest.BERTPaperID, "title"); err == nil {
+		t.Error("GetPaper through a 20ms response header timeout succeeded, want a timeout")
+	}
+	hc = semscholar.NewHTTPClient(0, semscholar.TransportOptions{})
+	if _, err := semscholar.NewClient(srv.URL, hc).GetPaper(semscholartest.BERTPaperID, "title"); err != nil {
+		t.Errorf("GetPaper without a response header timeout = %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package semscholar
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ErrInvalidResponse is matched by a *ValidationError.
+var ErrInvalidResponse = errors.New("semscholar: invalid response")
+
+// Diagnostic describes one invariant a decoded response breaks.
+type Diagnostic struct {
+	// Path locates the offending value, such as "data[3].paperId".
+	Path    string `json:"path"`
+	Problem string `json:"problem"`
+}
+
+// String formats d as "path: problem".
+func (d Diagnostic) String() string {
+	return d.Path + ": " + d.Problem
+}
+
+// ValidationError is returned together with the decoded result when
+// Client.Validate is set and the response breaks an invariant, so data
+// problems surface at the API boundary instead of deep in analytics code.
+type ValidationError struct {
+	Op          string
+	Diagnostics []Diagnostic
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	msg := fmt.Sprintf("%s: invalid response: %s", e.Op, e.Diagnostics[0])
+	if n := len(e.Diagnostics) - 1; n > 0 {
+		msg += fmt.Sprintf(" (and %d more)", n)
+	}
+	return msg
+}
+
+// Is reports whether target is ErrInvalidResponse.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidResponse
+}
+
+// validate checks v, decoded from the response to req, when c.Validate is set.
+func (c *Client) validate(op string, req *http.Request, v any) error {
+	if !c.Validate {
+		return nil
+	}
+	if diags := ValidateResponse(v, req.URL.Query()); len(diags) > 0 {
+		return c.report(op, &ValidationError{Op: op, Diagnostics: diags})
+	}
+	return nil
+}
+
+// ValidateResponse checks a decoded response v, as returned by the client's
+// methods, against the invariants of the API: papers and authors have IDs, the
+// page offset matches the requested one, pages hold no more than the requested
+// limit, next follows on from the page, and a total, when present, is at least
+// the number of items on the page. query is the request's query, which may be
+// nil. Entries of batch results that are entirely empty stand for unresolved
+// IDs and are accepted; so are citation edges without a paper ID, which the
+// API returns for papers it cannot link.
+func ValidateResponse(v any, query url.Values) []Diagnostic {
+	var diags []Diagnostic
+	add := func(path, format string, args ...any) {
+		diags = append(diags, Diagnostic{Path: path, Problem: fmt.Sprintf(format, args...)})
+	}
+	checkPapers := func(path string, papers []Paper, allowEmpty bool) {
+		for i, p := range papers {
+			if p.PaperID == "" && !(allowEmpty && isZeroPaper(p)) {
+				add(fmt.Sprintf("%s[%d].paperId", path, i), "missing")
+			}
+		}
+	}
+	checkAuthors := func(path string, authors []Author, allowEmpty bool) {
+		for i, a := range authors {
+			if a.AuthorID == "" && !(allowEmpty && isZeroAuthor(a)) {
+				add(fmt.Sprintf("%s[%d].authorId", path, i), "missing")
+			}
+		}
+	}
+	checkPage := func(total, offset, next, n int) {
+		if want, ok := queryInt(query, "offset"); ok && offset != want {
+			add("offset", "is %d, requested %d", offset, want)
+		}
+		if limit, ok := queryInt(query, "limit"); ok && n > limit {
+			add("data", "holds %d items, limit was %d", n, limit)
+		}
+		if next != 0 && next != offset+n {
+			add("next", "is %d, expected offset %d + %d items", next, offset, n)
+		}
+		if total != 0 && total < n {
+			add("total", "is %d, less than the %d items returned", total, n)
+		}
+	}
+
+	switch r := v.(type) {
+	case *Paper:
+		if r.PaperID == "" {
+			add("paperId", "missing")
+		}
+	case *[]Paper:
+		checkPapers("", *r, true)
+	case *Author:
+		if r.AuthorID == "" {
+			add("authorId", "missing")
+		}
+	case *[]Author:
+		checkAuthors("", *r, true)
+	case *PaperSearchResponse:
+		checkPage(r.Total, r.Offset, r.Next, len(r.Data))
+		checkPapers("data", r.Data, false)
+	case *AuthorPapersResponse:
+		checkPage(r.Total, r.Offset, r.Next, len(r.Data))
+		checkPapers("data", r.Data, false)
+	case *AuthorSearchResponse:
+		checkPage(r.Total, r.Offset, r.Next, len(r.Data))
+		checkAuthors("data", r.Data, false)
+	case *CitationsResponse:
+		checkPage(0, r.Offset, r.Next, len(r.Data))
+	case *RecommendationResponse:
+		checkPapers("recommendedPapers", r.RecommendedPapers, false)
+	case *SnippetSearchResponse:
+		for i, m := range r.Data {
+			if m.Paper.CorpusID == "" {
+				add(fmt.Sprintf("data[%d].paper.corpusId", i), "missing")
+			}
+		}
+	}
+	return diags
+}
+
+func queryInt(query url.Values, name string) (int, bool) {
+	if !query.Has(name) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(query.Get(name))
+	return n, err == nil
+}
+
+func isZeroPaper(p Paper) bool {
+	return p.PaperID == "" && p.Title == "" && p.CorpusID == 0 && p.URL == "" && len(p.Authors) == 0
+}
+
+func isZeroAuthor(a Author) bool {
+	return a.AuthorID == "" && a.Name == "" && a.URL == "" && len(a.Papers) == 0
+}
@@ -0,0 +1,74 @@
+package semscholar_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// TestValidateResponse checks the diagnostics ValidateResponse gives for
+// each invariant, and none for responses keeping them.
+func TestValidateResponse(t *testing.T) {
+	query := url.Values{"offset": {"10"}, "limit": {"2"}}
+	for _, tc := range []struct {
+		name  string
+		v     any
+		query url.Values
+		want  []string
+	}{
+		{"paper", &semscholar.Paper{PaperID: "a"}, nil, nil},
+		{"paper without ID", &semscholar.Paper{Title: "T"}, nil, []string{"paperId: missing"}},
+		{"batch with unresolved ID", &[]semscholar.Paper{{PaperID: "a"}, {}}, nil, nil},
+		{"batch entry without ID", &[]semscholar.Paper{{PaperID: "a"}, {Title: "T"}}, nil, []string{"[1].paperId: missing"}},
+		{"page", &semscholar.PaperSearchResponse{Total: 20, Offset: 10, Next: 12, Data: []semscholar.Paper{{PaperID: "a"}, {PaperID: "b"}}}, query, nil},
+		{"page breaking everything", &semscholar.PaperSearchResponse{Total: 1, Offset: 0, Next: 5, Data: []semscholar.Paper{{PaperID: "a"}, {}, {PaperID: "c"}}}, query, []string{
+			"offset: is 0, requested 10",
+			"data: holds 3 items, limit was 2",
+			"next: is 5, expected offset 0 + 3 items",
+			"total: is 1, less than the 3 items returned",
+			"data[1].paperId: missing",
+		}},
+		{"authors", &semscholar.AuthorSearchResponse{Data: []semscholar.Author{{Name: "A"}}}, nil, []string{"data[0].authorId: missing"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, d := range semscholar.ValidateResponse(tc.v, tc.query) {
+				got = append(got, d.String())
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("diagnostics = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestClientValidate checks that with Validate set, a response breaking an
+// invariant is returned with a ValidationError, and without it, as is.
+func TestClientValidate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"title":"No ID"}`))
+	}))
+	defer srv.Close()
+	client := semscholar.NewClient(srv.URL, srv.Client())
+	if _, err := client.GetPaper("a", "title"); err != nil {
+		t.Errorf("GetPaper without Validate = %v", err)
+	}
+	client.Validate = true
+	p, err := client.GetPaper("a", "title")
+	var verr *semscholar.ValidationError
+	if !errors.As(err, &verr) || !errors.Is(err, semscholar.ErrInvalidResponse) {
+		t.Fatalf("GetPaper with Validate = %v, want a ValidationError", err)
+	}
+	if verr.Op != "GetPaper" || len(verr.Diagnostics) != 1 || verr.Diagnostics[0].Path != "paperId" {
+		t.Errorf("ValidationError = %+v, want one on paperId from GetPaper", verr)
+	}
+	if p == nil || p.Title != "No ID" {
+		t.Errorf("paper = %+v, want the decoded paper alongside the error", p)
+	}
+}
@@ -0,0 +1,292 @@
+// Package vecindex is an in-memory index of paper embeddings answering
+// cosine-similarity queries, so that "more like this" works offline against
+// papers fetched before. An index can be saved to and loaded from a JSON
+// Lines file.
+package vecindex
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+)
+
+// DefaultEmbeddingField is the field AddFetched asks for.
+const DefaultEmbeddingField = "embedding.specter_v2"
+
+// ErrNotFound is returned by Similar for papers not in the index.
+var ErrNotFound = errors.New("vecindex: paper not in index")
+
+// Index holds papers and their embeddings, normalized to unit length and
+// kept as float32 to halve their memory. It is safe for concurrent use.
+type Index struct {
+	mu      sync.RWMutex
+	dim     int
+	papers  []semscholar.Paper // without their embeddings
+	vectors [][]float32
+	byID    map[string]int
+}
+
+// New returns an empty index.
+func New() *Index {
+	return &Index{byID: make(map[string]int)}
+}
+
+// Hit is a paper found by a query, and its cosine similarity to the query.
+type Hit struct {
+	Paper semscholar.Paper `json:"paper"`
+	Score float64          `json:"score"`
+}
+
+// Len returns the number of papers in the index.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.papers)
+}
+
+// Add adds the papers that have embeddings, replacing those of the same
+// paper IDs, and returns how many it added. The embeddings must all be of
+// the length of those already in the index.
+func (ix *Index) Add(papers ...semscholar.Paper) (int, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	n := 0
+	for _, p := range papers {
+		if p.PaperID == "" || p.Embedding == nil || len(p.Embedding.Vector) == 0 {
+			continue
+		}
+		v := p.Embedding.Vector
+		if ix.dim == 0 {
+			ix.dim = len(v)
+		} else if len(v) != ix.dim {
+			return n, fmt.Errorf("vecindex: paper %s has an embedding of length %d, not %d", p.PaperID, len(v), ix.dim)
+		}
+		p.Embedding = &semscholar.Embedding{Model: p.Embedding.Model}
+		vec := normalize(v)
+		if i, ok := ix.byID[p.PaperID]; ok {
+			ix.papers[i], ix.vectors[i] = p, vec
+		} else {
+			ix.byID[p.PaperID] = len(ix.papers)
+			ix.papers = append(ix.papers, p)
+			ix.vectors = append(ix.vectors, vec)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// AddFetched fetches the papers ids name with fields and their embeddings,
+// in batches, and adds them. Papers not found, and those in batches that
+// failed, are skipped; the error then is a *semscholar.PartialError.
+func (ix *Index) AddFetched(ctx context.Context, c *semscholar.Client, ids []string, fields string) (int, error) {
+	if fields == "" {
+		fields = DefaultEmbeddingField
+	} else {
+		fields += "," + DefaultEmbeddingField
+	}
+	papers, err := c.GetPapersBatchAll(ctx, ids, fields)
+	var partial *semscholar.PartialError
+	if err != nil && !errors.As(err, &partial) {
+		return 0, err
+	}
+	n, err := ix.Add(papers...)
+	if err != nil {
+		return n, err
+	}
+	if partial != nil {
+		return n, partial
+	}
+	return n, nil
+}
+
+// Remove removes the paper with the paper ID id, reporting whether it was
+// in the index.
+func (ix *Index) Remove(id string) bool {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	i, ok := ix.byID[id]
+	if !ok {
+		return false
+	}
+	last := len(ix.papers) - 1
+	ix.papers[i], ix.vectors[i] = ix.papers[last], ix.vectors[last]
+	ix.byID[ix.papers[i].PaperID] = i
+	ix.papers, ix.vectors = ix.papers[:last], ix.vectors[:last]
+	delete(ix.byID, id)
+	return true
+}
+
+// Search returns the k papers whose embeddings are most similar to v, most
+// similar first.
+func (ix *Index) Search(v []float64, k int) ([]Hit, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if ix.dim != 0 && len(v) != ix.dim {
+		return nil, fmt.Errorf("vecindex: query of length %d, not %d", len(v), ix.dim)
+	}
+	return ix.topK(normalize(v), k, -1), nil
+}
+
+// Similar returns the k papers most similar to the one with the paper ID
+// id, leaving it out.
+func (ix *Index) Similar(id string, k int) ([]Hit, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	i, ok := ix.byID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return ix.topK(ix.vectors[i], k, i), nil
+}
+
+// topK returns the k best hits for q, skipping the paper at index skip.
+func (ix *Index) topK(q []float32, k, skip int) []Hit {
+	if k <= 0 {
+		return nil
+	}
+	h := make(hits, 0, k+1)
+	for i, v := range ix.vectors {
+		if i == skip {
+			continue
+		}
+		s := dot(q, v)
+		if len(h) < k {
+			heap.Push(&h, scored{i, s})
+		} else if s > h[0].score {
+			h[0] = scored{i, s}
+			heap.Fix(&h, 0)
+		}
+	}
+	out := make([]Hit, len(h))
+	for j := len(h) - 1; j >= 0; j-- {
+		s := heap.Pop(&h).(scored)
+		out[j] = Hit{Paper: ix.papers[s.index], Score: s.score}
+	}
+	return out
+}
+
+type scored struct {
+	index int
+	score float64
+}
+
+// hits is a min-heap of scored papers, the least similar on top.
+type hits []scored
+
+func (h hits) Len() int           { return len(h) }
+func (h hits) Less(i, j int) bool { return h[i].score < h[j].score }
+func (h hits) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *hits) Push(x any)        { *h = append(*h, x.(scored)) }
+func (h *hits) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// Write writes the index to w as JSON Lines, one paper with its
+// normalized embedding per line.
+func (ix *Index) Write(w io.Writer, compress bool) error {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	jw := semscholar.NewJSONLWriter(w, compress)
+	for i, p := range ix.papers {
+		vec := make([]float64, len(ix.vectors[i]))
+		for j, x := range ix.vectors[i] {
+			vec[j] = float64(x)
+		}
+		p.Embedding = &semscholar.Embedding{Model: p.Embedding.Model, Vector: vec}
+		if err := jw.Write(p); err != nil {
+			return err
+		}
+	}
+	return jw.Close()
+}
+
+// Read adds the papers in r, JSON Lines of papers with embeddings such as
+// Write writes, to the index.
+func (ix *Index) Read(r io.Reader) error {
+	jr, err := semscholar.NewJSONLReader(r)
+	if err != nil {
+		return err
+	}
+	defer jr.Close()
+	for line := 1; ; line++ {
+		var p semscholar.Paper
+		if err := jr.Read(&p); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("vecindex: line %d: %w", line, err)
+		}
+		if _, err := ix.Add(p); err != nil {
+			return err
+		}
+	}
+}
+
+// Save writes the index to the file at path, replacing it atomically. The
+// file is gzip-compressed when path ends in ".gz".
+func (ix *Index) Save(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if err := ix.Write(tmp, strings.HasSuffix(path, ".gz")); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load returns the index saved in the file at path.
+func Load(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ix := New()
+	if err := ix.Read(f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return ix, nil
+}
+
+func dot(a, b []float32) float64 {
+	var s float32
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return float64(s)
+}
+
+// normalize returns v scaled to unit length as float32, or zeros if v is
+// zero.
+func normalize(v []float64) []float32 {
+	norm := 0.0
+	for _, x := range v {
+		norm += x * x
+	}
+	norm = math.Sqrt(norm)
+	u := make([]float32, len(v))
+	if norm > 0 {
+		for i, x := range v {
+			u[i] = float32(x / norm)
+		}
+	}
+	return u
+}
@@ -0,0 +1,169 @@
+package vecindex_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+	"github.com/jmwalsh91/semscholar-go/vecindex"
+)
+
+// paper returns a paper with the embedding vector, or none if vector is
+// empty.
+func paper(id string, vector ...float64) semscholar.Paper {
+	p := semscholar.Paper{PaperID: id, Title: "Paper " + id}
+	if len(vector) > 0 {
+		p.Embedding = &semscholar.Embedding{Model: "specter_v2", Vector: vector}
+	}
+	return p
+}
+
+// compass returns an index of papers at the points of the compass, whose
+// embeddings are not of unit length.
+func compass(t *testing.T) *vecindex.Index {
+	t.Helper()
+	ix := vecindex.New()
+	n, err := ix.Add(paper("n", 0, 2), paper("ne", 3, 3), paper("e", 5, 0), paper("s", 0, -1), paper("none"), semscholar.Paper{Embedding: &semscholar.Embedding{Vector: []float64{1, 0}}})
+	if err != nil || n != 4 {
+		t.Fatalf("Add = %d, %v, want 4 papers", n, err)
+	}
+	return ix
+}
+
+func ids(hits []vecindex.Hit) []string {
+	var ids []string
+	for _, h := range hits {
+		ids = append(ids, h.Paper.PaperID)
+	}
+	return ids
+}
+
+// TestSearch checks the order and scores of hits, and k at and beyond the
+// size of the index.
+func TestSearch(t *testing.T) {
+	ix := compass(t)
+	for _, tc := range []struct {
+		name string
+		k    int
+		want []string
+	}{
+		{"top two", 2, []string{"n", "ne"}},
+		{"all", 4, []string{"n", "ne", "e", "s"}},
+		{"more than all", 10, []string{"n", "ne", "e", "s"}},
+		{"none", 0, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			hits, err := ix.Search([]float64{0, 7}, tc.k)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(hits); !slices.Equal(got, tc.want) {
+				t.Errorf("hits %v, want %v", got, tc.want)
+			}
+			if len(hits) == 4 {
+				for i, want := range []float64{1, math.Sqrt2 / 2, 0, -1} {
+					if math.Abs(hits[i].Score-want) > 1e-6 {
+						t.Errorf("score of %s = %v, want %v", hits[i].Paper.PaperID, hits[i].Score, want)
+					}
+				}
+			}
+		})
+	}
+	if hits, _ := ix.Search([]float64{0, 1}, 1); hits[0].Paper.Embedding.Vector != nil {
+		t.Error("hit carries its embedding vector")
+	}
+	if _, err := ix.Search([]float64{1, 0, 0}, 1); err == nil {
+		t.Error("Search accepted a query of the wrong length")
+	}
+}
+
+// TestSimilar checks that the paper itself is left out, and that papers
+// replaced or moved by a removal are found where they now are.
+func TestSimilar(t *testing.T) {
+	ix := compass(t)
+	hits, err := ix.Similar("n", 1)
+	if err != nil || !slices.Equal(ids(hits), []string{"ne"}) {
+		t.Errorf("Similar(n) = %v, %v, want [ne]", ids(hits), err)
+	}
+	if _, err := ix.Similar("w", 1); !errors.Is(err, vecindex.ErrNotFound) {
+		t.Errorf("Similar of a missing paper: %v, want ErrNotFound", err)
+	}
+
+	if n, err := ix.Add(paper("ne", 1, -1)); err != nil || n != 1 {
+		t.Fatalf("replacing ne: %d, %v", n, err)
+	}
+	if !ix.Remove("n") || ix.Remove("n") {
+		t.Error("Remove did not report n removed once")
+	}
+	if ix.Len() != 3 {
+		t.Errorf("Len = %d, want 3", ix.Len())
+	}
+	hits, err = ix.Similar("s", 3)
+	if err != nil || !slices.Equal(ids(hits), []string{"ne", "e"}) {
+		t.Errorf("Similar(s) = %v, %v, want [ne e]", ids(hits), err)
+	}
+}
+
+// TestAddLength checks that a paper whose embedding is of another length
+// stops Add, keeping the papers before it.
+func TestAddLength(t *testing.T) {
+	ix := vecindex.New()
+	n, err := ix.Add(paper("a", 1, 0), paper("b", 1, 0, 0), paper("c", 0, 1))
+	if err == nil || n != 1 || ix.Len() != 1 {
+		t.Errorf("Add = %d, %v with %d papers, want an error after 1", n, err, ix.Len())
+	}
+}
+
+// TestSaveLoad checks that an index saved, plain or compressed, loads with
+// its papers and embeddings.
+func TestSaveLoad(t *testing.T) {
+	ix := compass(t)
+	for _, name := range []string{"index.jsonl", "index.jsonl.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := ix.Save(path); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := vecindex.Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if loaded.Len() != 4 {
+				t.Errorf("loaded %d papers, want 4", loaded.Len())
+			}
+			hits, err := loaded.Similar("e", 1)
+			if err != nil || !slices.Equal(ids(hits), []string{"ne"}) || hits[0].Paper.Embedding.Model != "specter_v2" {
+				t.Errorf("Similar(e) after loading = %+v, %v", hits, err)
+			}
+		})
+	}
+	if _, err := vecindex.Load(filepath.Join(t.TempDir(), "missing.jsonl")); err == nil {
+		t.Error("Load of a missing file succeeded")
+	}
+}
+
+// TestAddFetched checks that papers are fetched with their embeddings and
+// that those not found are skipped.
+func TestAddFetched(t *testing.T) {
+	d := semscholartest.NewDataset()
+	d.AddPaper(paper("a", 1, 0))
+	d.AddPaper(paper("b", 0, 1))
+	d.AddPaper(paper("c"))
+	srv := semscholartest.NewServer(d)
+	defer srv.Close()
+
+	ix := vecindex.New()
+	n, err := ix.AddFetched(context.Background(), srv.Client(), []string{"a", "b", "c", "missing"}, "title")
+	if err != nil || n != 2 {
+		t.Fatalf("AddFetched = %d, %v, want 2 papers", n, err)
+	}
+	hits, err := ix.Similar("a", 1)
+	if err != nil || hits[0].Paper.Title != "Paper b" {
+		t.Errorf("Similar(a) = %+v, %v, want b with its title", hits, err)
+	}
+}
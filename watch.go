@@ -0,0 +1,331 @@
+package semscholar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// WatchKind says what a WatchEvent reports.
+type WatchKind int
+
+const (
+	// NewCitation is a paper newly citing a watched paper.
+	NewCitation WatchKind = iota
+	// NewAuthorPaper is a paper newly listed for a watched author.
+	NewAuthorPaper
+)
+
+func (k WatchKind) String() string {
+	switch k {
+	case NewCitation:
+		return "citation"
+	case NewAuthorPaper:
+		return "paper"
+	}
+	return fmt.Sprintf("WatchKind(%d)", int(k))
+}
+
+// WatchEvent is a change a Watcher found.
+type WatchEvent struct {
+	Kind WatchKind
+	// Watched is the ID of the watched paper or author, as it was given to
+	// the Watcher.
+	Watched string
+	// Paper is the citing paper or the author's new paper.
+	Paper Paper
+	// Citation is the citation of a NewCitation event, with its contexts,
+	// intents and influence when the Watcher's fields ask for them.
+	Citation Citation
+}
+
+// WatchState is what a Watcher remembers between checks: for each watched
+// paper or author, keyed "paper:" or "author:" and the ID, the papers already
+// reported.
+type WatchState struct {
+	Targets map[string]*WatchTarget `json:"targets"`
+}
+
+// WatchTarget is the state of one watched paper or author.
+type WatchTarget struct {
+	// Count is the citation count of the paper or the paper count of the
+	// author at the last check; an unchanged count skips listing them again.
+	Count   int       `json:"count"`
+	Checked time.Time `json:"checked"`
+	Seen    []string  `json:"seen"`
+}
+
+// WatchStore persists a WatchState, so that a Watcher restarted later does
+// not report again what it already has.
+type WatchStore interface {
+	// LoadWatchState returns the stored state, or an empty one if there is
+	// none yet.
+	LoadWatchState(ctx context.Context) (*WatchState, error)
+	SaveWatchState(ctx context.Context, s *WatchState) error
+}
+
+// FileWatchStore is a WatchStore keeping the state as JSON in the file it
+// names, which it replaces atomically on every save.
+type FileWatchStore string
+
+// LoadWatchState implements WatchStore.
+func (f FileWatchStore) LoadWatchState(ctx context.Context) (*WatchState, error) {
+	s := &WatchState{Targets: make(map[string]*WatchTarget)}
+	data, err := os.ReadFile(string(f))
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("semscholar: watch state %s: %w", f, err)
+	}
+	if s.Targets == nil {
+		s.Targets = make(map[string]*WatchTarget)
+	}
+	return s, nil
+}
+
+// SaveWatchState implements WatchStore.
+func (f FileWatchStore) SaveWatchState(ctx context.Context, s *WatchState) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	path := string(f)
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// DefaultWatchInterval is how often Watcher.Run checks without an Interval.
+const DefaultWatchInterval = time.Hour
+
+// Watcher reports new citations of a set of papers and new papers of a set
+// of authors, as for "your paper was cited" notifications. Each check first
+// fetches the citation counts of the papers and the paper counts of the
+// authors in batches, then lists the citations or papers of only those whose
+// count changed, reporting the ones not seen before.
+//
+// The first check of a paper or author records what is already there
+// without reporting it, unless ReportExisting is set.
+type Watcher struct {
+	Client  *Client
+	Papers  []string
+	Authors []string
+	// Fields are the fields of the papers events carry, and for citations
+	// may include contexts, intents and isInfluential. Paper IDs are always
+	// fetched.
+	Fields string
+	// Interval is the time between the checks of Run; zero means
+	// DefaultWatchInterval.
+	Interval time.Duration
+	// Store, if set, persists what has been seen across restarts; without it
+	// the state lives only as long as the Watcher.
+	Store          WatchStore
+	ReportExisting bool
+	// OnError, if set, is called by Run with the error of each check that
+	// failed; Run carries on regardless.
+	OnError func(error)
+	// Clock, if set, replaces the system clock.
+	Clock Clock
+
+	mu    sync.Mutex
+	state *WatchState
+}
+
+// Check checks every watched paper and author once and returns the events
+// found, in the order of Papers and Authors. A failure to check some of them
+// does not stop the others: their events are returned with the joined
+// errors, and what was seen of the others is saved.
+func (w *Watcher) Check(ctx context.Context) ([]WatchEvent, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.state == nil {
+		s := &WatchState{Targets: make(map[string]*WatchTarget)}
+		if w.Store != nil {
+			var err error
+			if s, err = w.Store.LoadWatchState(ctx); err != nil {
+				return nil, err
+			}
+		}
+		w.state = s
+	}
+	now := clockOr(w.Clock).Now()
+
+	var events []WatchEvent
+	var errs []error
+	if len(w.Papers) > 0 {
+		papers, err := w.Client.GetPapersBatchAll(ctx, w.Papers, "citationCount")
+		var partial *PartialError
+		if err != nil && !errors.As(err, &partial) {
+			// Every batch failed alike; the authors are still checked.
+			errs = append(errs, err)
+			papers = nil
+		}
+		for i := range papers {
+			id := w.Papers[i]
+			if partial != nil && failedChunk(partial, i) {
+				continue
+			}
+			if papers[i].PaperID == "" {
+				errs = append(errs, fmt.Errorf("watch paper %s: %w", id, ErrNotFound))
+				continue
+			}
+			seq := func(yield func(WatchEvent, error) bool) {
+				for c, err := range w.Client.GetPaperCitationsSeq(ctx, id, w.Fields) {
+					if !yield(WatchEvent{Kind: NewCitation, Watched: id, Paper: c.CitingPaper, Citation: c}, err) {
+						return
+					}
+				}
+			}
+			found, err := w.update("paper:"+id, papers[i].CitationCount.Int, now, seq)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("watch paper %s: %w", id, err))
+			}
+			events = append(events, found...)
+		}
+		if partial != nil {
+			errs = append(errs, partial)
+		}
+	}
+	for start := 0; start < len(w.Authors); start += MaxBatchSize {
+		ids := w.Authors[start:min(start+MaxBatchSize, len(w.Authors))]
+		authors, err := w.Client.GetAuthorsBatchContext(ctx, ids, "paperCount")
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for i, id := range ids {
+			if i >= len(authors) || authors[i].AuthorID == "" {
+				errs = append(errs, fmt.Errorf("watch author %s: %w", id, ErrNotFound))
+				continue
+			}
+			seq := func(yield func(WatchEvent, error) bool) {
+				for p, err := range w.Client.GetAuthorPapersSeq(ctx, id, w.Fields) {
+					if !yield(WatchEvent{Kind: NewAuthorPaper, Watched: id, Paper: p}, err) {
+						return
+					}
+				}
+			}
+			found, err := w.update("author:"+id, authors[i].PaperCount.Int, now, seq)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("watch author %s: %w", id, err))
+			}
+			events = append(events, found...)
+		}
+	}
+
+	if w.Store != nil {
+		if err := w.Store.SaveWatchState(ctx, w.state); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return events, errors.Join(errs...)
+}
+
+// update lists the papers of the target keyed key, if its count is not
+// what it was, and returns the events of those not seen before. The
+// target's state is only changed if the whole list was read.
+func (w *Watcher) update(key string, count int, now time.Time, seq func(yield func(WatchEvent, error) bool)) ([]WatchEvent, error) {
+	t, known := w.state.Targets[key]
+	if known && t.Count == count {
+		t.Checked = now
+		return nil, nil
+	}
+	seen := make(map[string]bool)
+	if known {
+		for _, id := range t.Seen {
+			seen[id] = true
+		}
+	}
+	var events []WatchEvent
+	for e, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		id := e.Paper.PaperID
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if known || w.ReportExisting {
+			events = append(events, e)
+		}
+	}
+	// Papers listed before but not now, as when a citation is withdrawn,
+	// stay seen, so that they are not reported again if they come back.
+	ids := slices.Sorted(maps.Keys(seen))
+	w.state.Targets[key] = &WatchTarget{Count: count, Checked: now, Seen: ids}
+	return events, nil
+}
+
+// failedChunk reports whether the i'th ID was in a chunk that partial says
+// failed.
+func failedChunk(partial *PartialError, i int) bool {
+	for _, f := range partial.Failures {
+		if i >= f.Start && i < f.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Run checks every Interval until ctx is done, calling fn with each event
+// found, and returns ctx.Err(). The first check is made at once.
+func (w *Watcher) Run(ctx context.Context, fn func(WatchEvent)) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	clock := clockOr(w.Clock)
+	for {
+		events, err := w.Check(ctx)
+		for _, e := range events {
+			fn(e)
+		}
+		if err != nil && w.OnError != nil && ctx.Err() == nil {
+			w.OnError(err)
+		}
+		if err := clock.Sleep(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+// Events runs the Watcher as Run does and delivers its events on the
+// returned channel, which is closed once ctx is done. A slow receiver holds
+// up the next check rather than losing events.
+func (w *Watcher) Events(ctx context.Context) <-chan WatchEvent {
+	ch := make(chan WatchEvent)
+	go func() {
+		defer close(ch)
+		w.Run(ctx, func(e WatchEvent) {
+			select {
+			case ch <- e:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return ch
+}
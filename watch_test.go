@@ -0,0 +1,160 @@
+package semscholar_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	semscholar "github.com/jmwalsh91/semscholar-go"
+	"github.com/jmwalsh91/semscholar-go/semscholartest"
+)
+
+// TestWatchAuthorsCanceled checks that the author pass of a check carries its
+// context, so a canceled check sends no author batch.
+func TestWatchAuthorsCanceled(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"authorId":"1","paperCount":3}]`))
+	}))
+	defer srv.Close()
+	w := &semscholar.Watcher{Client: semscholar.NewClient(srv.URL, srv.Client()), Authors: []string{"1"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := w.Check(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Check = %v, want context.Canceled", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("server saw %d requests, want none", n)
+	}
+}
+
+// watchData returns a dataset in which the papers citing cite the paper "s",
+// whose citation count is theirs, and are written by the author "1".
+func watchData(citing ...string) *semscholartest.Dataset {
+	d := semscholartest.NewDataset()
+	d.AddPaper(semscholar.Paper{PaperID: "s", Title: "Seed", CitationCount: semscholar.NewNullInt(len(citing))})
+	for _, id := range citing {
+		d.AddPaper(semscholar.Paper{PaperID: id, Title: "Paper " + id, Authors: []semscholar.Author{{AuthorID: "1", Name: "Ada"}}})
+		d.AddCitation(id, "s")
+	}
+	return d
+}
+
+// eventPapers returns the kind and paper ID of each event.
+func eventPapers(events []semscholar.WatchEvent) []string {
+	var out []string
+	for _, e := range events {
+		out = append(out, e.Kind.String()+" "+e.Paper.PaperID)
+	}
+	return out
+}
+
+// listings counts the requests that listed citations or author papers.
+func listings(srv *semscholartest.Server) int {
+	n := 0
+	for _, r := range srv.Requests() {
+		if strings.Contains(r, "/citations") || strings.Contains(r, "/papers") {
+			n++
+		}
+	}
+	return n
+}
+
+// TestWatchCheck checks that a first check records what is there without
+// reporting it, that later checks report only what is new, and that an
+// unchanged count skips listing the target again.
+func TestWatchCheck(t *testing.T) {
+	srv := semscholartest.NewServer(watchData("a"))
+	defer srv.Close()
+	w := &semscholar.Watcher{Client: srv.Client(), Papers: []string{"s"}, Authors: []string{"1"}}
+	ctx := context.Background()
+
+	events, err := w.Check(ctx)
+	if err != nil || len(events) != 0 {
+		t.Fatalf("first Check = %v, %v, want no events", eventPapers(events), err)
+	}
+	if n := listings(srv); n != 2 {
+		t.Fatalf("first Check listed %d targets, want 2", n)
+	}
+
+	srv.Update(func(d *semscholartest.Dataset) { *d = *watchData("a", "b") })
+	events, err = w.Check(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := eventPapers(events), []string{"citation b", "paper b"}; !slices.Equal(got, want) {
+		t.Errorf("second Check reported %v, want %v", got, want)
+	}
+
+	before := listings(srv)
+	events, err = w.Check(ctx)
+	if err != nil || len(events) != 0 {
+		t.Errorf("unchanged Check = %v, %v, want no events", eventPapers(events), err)
+	}
+	if n := listings(srv) - before; n != 0 {
+		t.Errorf("unchanged Check listed %d targets, want none", n)
+	}
+}
+
+// TestWatchStore checks that a Watcher restarted over a FileWatchStore
+// reports only what its predecessor had not seen.
+func TestWatchStore(t *testing.T) {
+	srv := semscholartest.NewServer(watchData("a"))
+	defer srv.Close()
+	store := semscholar.FileWatchStore(filepath.Join(t.TempDir(), "watch.json"))
+	ctx := context.Background()
+
+	w := &semscholar.Watcher{Client: srv.Client(), Papers: []string{"s"}, Store: store}
+	if _, err := w.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	state, err := store.LoadWatchState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := state.Targets["paper:s"]; got == nil || got.Count != 1 || !slices.Equal(got.Seen, []string{"a"}) {
+		t.Fatalf("saved target %+v, want count 1 and a seen", got)
+	}
+
+	srv.Update(func(d *semscholartest.Dataset) { *d = *watchData("a", "b") })
+	w = &semscholar.Watcher{Client: srv.Client(), Papers: []string{"s"}, Store: store}
+	events, err := w.Check(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := eventPapers(events), []string{"citation b"}; !slices.Equal(got, want) {
+		t.Errorf("restarted Check reported %v, want %v", got, want)
+	}
+}
+
+// TestWatchPaperBatchFailed checks that a failed paper batch leaves the
+// authors checked and the state saved.
+func TestWatchPaperBatchFailed(t *testing.T) {
+	srv := semscholartest.NewServer(watchData("a"))
+	defer srv.Close()
+	srv.Inject(semscholartest.Fault{Path: "/paper/batch", Status: http.StatusInternalServerError})
+	store := semscholar.FileWatchStore(filepath.Join(t.TempDir(), "watch.json"))
+	w := &semscholar.Watcher{Client: srv.Client(), Papers: []string{"s"}, Authors: []string{"1"}, Store: store}
+	ctx := context.Background()
+
+	_, err := w.Check(ctx)
+	var apiErr *semscholar.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Check = %v, want the batch's 500", err)
+	}
+	state, err := store.LoadWatchState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Targets["author:1"] == nil || state.Targets["paper:s"] != nil {
+		t.Errorf("saved targets %v, want the author only", state.Targets)
+	}
+}